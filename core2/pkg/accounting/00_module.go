@@ -18,8 +18,12 @@ func Init() {
 	initAccounting()
 	times["Accounting"] = t.Mark()
 
+	initBudgetAlerts()
+	times["BudgetAlerts"] = t.Mark()
+
 	initGrantSearchIndex()
 	initGrants()
+	initGrantReviews()
 	times["Grants"] = t.Mark()
 
 	initGifts()
@@ -43,6 +47,9 @@ func Init() {
 	initGrantsExport()
 	times["GrantsExport"] = t.Mark()
 
+	initUsageExport()
+	times["UsageExport"] = t.Mark()
+
 	coreutil.PrintStartupTimes("Accounting", times)
 
 	if util.DevelopmentModeEnabled() {
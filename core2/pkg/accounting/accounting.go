@@ -44,6 +44,14 @@ func initAccounting() {
 		return UpdateAllocation(info.Actor, request.Items)
 	})
 
+	accapi.TransferQuota.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[accapi.TransferQuotaRequest]) (util.Empty, *util.HttpError) {
+		return TransferQuota(info.Actor, request.Items)
+	})
+
+	accapi.RetrieveAllocationHistory.Handler(func(info rpc.RequestInfo, request accapi.RetrieveAllocationHistoryRequest) (accapi.RetrieveAllocationHistoryResponse, *util.HttpError) {
+		return RetrieveAllocationHistory(info.Actor, request)
+	})
+
 	accapi.ReportUsage.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[accapi.ReportUsageRequest]) (fndapi.BulkResponse[bool], *util.HttpError) {
 		var result []bool
 		for _, reqItem := range request.Items {
@@ -285,6 +293,138 @@ func UpdateAllocation(actor rpc.Actor, requests []accapi.UpdateAllocationRequest
 	return util.Empty{}, nil
 }
 
+func TransferQuota(actor rpc.Actor, requests []accapi.TransferQuotaRequest) (util.Empty, *util.HttpError) {
+	if !actor.Membership[actor.Project.Value].Satisfies(rpc.ProjectRoleAdmin) {
+		return util.Empty{}, util.HttpErr(http.StatusForbidden, "You need admin privileges in your project to perform this action")
+	}
+
+	reference := actor.Username
+	if actor.Project.Present && actor.Project.Value != "" {
+		reference = string(actor.Project.Value)
+	}
+	iOwner := internalOwnerByReference(reference)
+
+	if len(requests) == 0 {
+		return util.Empty{}, nil
+	}
+
+	// All transfers must be applied under the same bucket lock, otherwise a failing transfer would leave the
+	// preceding transfers in place.
+	var bucket *internalBucket
+	var transfers []internalQuotaTransfer
+	for _, request := range requests {
+		if len(request.Reason) > 1024*4 {
+			return util.Empty{}, util.HttpErr(http.StatusBadRequest, "reason is too long")
+		}
+
+		requestBucket, _, ok := internalWalletByAllocationId(accAllocId(request.SourceAllocationId))
+		if !ok {
+			return util.Empty{}, util.HttpErr(http.StatusNotFound, "Unknown allocation")
+		}
+
+		if bucket == nil {
+			bucket = requestBucket
+		} else if bucket != requestBucket {
+			return util.Empty{}, util.HttpErr(http.StatusBadRequest, "All transfers must belong to the same product category")
+		}
+
+		transfers = append(transfers, internalQuotaTransfer{
+			Source: accAllocId(request.SourceAllocationId),
+			Target: accAllocId(request.TargetAllocationId),
+			Amount: request.Amount,
+			Reason: request.Reason,
+		})
+	}
+
+	err := internalTransferQuota(iOwner, time.Now(), bucket, transfers, actor.Username)
+	return util.Empty{}, err
+}
+
+func RetrieveAllocationHistory(actor rpc.Actor, request accapi.RetrieveAllocationHistoryRequest) (accapi.RetrieveAllocationHistoryResponse, *util.HttpError) {
+	allocId := accAllocId(request.AllocationId)
+	bucket, _, ok := internalWalletByAllocationId(allocId)
+	if !ok {
+		return accapi.RetrieveAllocationHistoryResponse{}, util.HttpErr(http.StatusNotFound, "Unknown allocation")
+	}
+
+	reference := actor.Username
+	if actor.Project.Present && actor.Project.Value != "" {
+		reference = string(actor.Project.Value)
+	}
+	iOwner := internalOwnerByReference(reference)
+
+	// Both the owner of the allocation and the owner of the parent wallet can view the history
+	bucket.Mu.RLock()
+	hasPermission := false
+	alloc, ok := bucket.AllocationsById[allocId]
+	if ok {
+		for _, walletId := range []AccWalletId{alloc.BelongsTo, alloc.Parent} {
+			w, ok := bucket.WalletsById[walletId]
+			if ok && w.OwnedBy == iOwner.Id {
+				hasPermission = true
+			}
+		}
+	}
+	bucket.Mu.RUnlock()
+
+	if !hasPermission {
+		return accapi.RetrieveAllocationHistoryResponse{}, util.HttpErr(http.StatusNotFound, "Unknown allocation")
+	}
+
+	var entries []accapi.AllocationHistoryEntry
+	if !accGlobals.TestingEnabled {
+		type rowType struct {
+			AllocationId        int64
+			CreatedAt           time.Time
+			ChangedBy           string
+			ChangeType          string
+			QuotaBefore         int64
+			QuotaAfter          int64
+			RelatedAllocationId sql.Null[int64]
+			Reason              string
+		}
+
+		rows := db.NewTx(func(tx *db.Transaction) []rowType {
+			return db.Select[rowType](
+				tx,
+				`
+					select
+						allocation_id, created_at, changed_by, change_type, quota_before, quota_after,
+						related_allocation_id, reason
+					from accounting.allocation_history
+					where allocation_id = :allocation
+					order by created_at, id
+			    `,
+				db.Params{
+					"allocation": request.AllocationId,
+				},
+			)
+		})
+
+		for _, row := range rows {
+			entry := internalAllocationHistoryEntry{
+				Allocation:  accAllocId(row.AllocationId),
+				Timestamp:   row.CreatedAt,
+				ChangedBy:   row.ChangedBy,
+				Type:        accapi.AllocationHistoryChangeType(row.ChangeType),
+				QuotaBefore: row.QuotaBefore,
+				QuotaAfter:  row.QuotaAfter,
+				Reason:      row.Reason,
+			}
+			if row.RelatedAllocationId.Valid {
+				entry.RelatedAllocation.Set(accAllocId(row.RelatedAllocationId.V))
+			}
+			entries = append(entries, entry.ToApi())
+		}
+	}
+
+	for _, entry := range internalPendingAllocationHistory(bucket, allocId) {
+		entries = append(entries, entry.ToApi())
+	}
+
+	return accapi.RetrieveAllocationHistoryResponse{Entries: util.NonNilSlice(entries)}, nil
+}
+
 func ReportUsage(actor rpc.Actor, request accapi.ReportUsageRequest) (bool, *util.HttpError) {
 	if !actor.IsSystem() {
 		providerId, ok := strings.CutPrefix(actor.Username, fndapi.ProviderSubjectPrefix)
@@ -735,6 +875,30 @@ func accountingProcessTasksNow(now time.Time, filter func(b *internalBucket) boo
 			TreeUsage []int64
 		}{}
 
+		historyRequests := struct {
+			Allocation        []int64
+			CreatedAt         []int64
+			ChangedBy         []string
+			ChangeType        []string
+			QuotaBefore       []int64
+			QuotaAfter        []int64
+			RelatedAllocation []int64 // 0 -> null
+			Reason            []string
+		}{}
+
+		type chargeRequest struct {
+			Wallet      []int64
+			PeriodStart []int64
+			Scope       []string
+			Description []string
+			Product     []string
+			Usage       []int64
+		}
+
+		// Charges to capacity-based products store the usage level while other charges are accumulated
+		deltaCharges := chargeRequest{}
+		levelCharges := chargeRequest{}
+
 		handlersToTrigger := map[accGrantId]internalOnPersistHandler{}
 
 		for _, owner := range accGlobals.OwnersById {
@@ -801,6 +965,33 @@ func accountingProcessTasksNow(now time.Time, filter func(b *internalBucket) boo
 					alloc.Dirty = false
 				}
 			}
+
+			for _, entry := range b.PendingHistory {
+				historyRequests.Allocation = append(historyRequests.Allocation, int64(entry.Allocation))
+				historyRequests.CreatedAt = append(historyRequests.CreatedAt, entry.Timestamp.UnixMilli())
+				historyRequests.ChangedBy = append(historyRequests.ChangedBy, entry.ChangedBy)
+				historyRequests.ChangeType = append(historyRequests.ChangeType, string(entry.Type))
+				historyRequests.QuotaBefore = append(historyRequests.QuotaBefore, entry.QuotaBefore)
+				historyRequests.QuotaAfter = append(historyRequests.QuotaAfter, entry.QuotaAfter)
+				historyRequests.RelatedAllocation = append(historyRequests.RelatedAllocation, int64(entry.RelatedAllocation.GetOrDefault(0)))
+				historyRequests.Reason = append(historyRequests.Reason, entry.Reason)
+			}
+			b.PendingHistory = nil
+
+			charges := &deltaCharges
+			if b.IsCapacityBased() {
+				charges = &levelCharges
+			}
+
+			for key, usage := range b.PendingCharges {
+				charges.Wallet = append(charges.Wallet, int64(key.Wallet))
+				charges.PeriodStart = append(charges.PeriodStart, key.Day.UnixMilli())
+				charges.Scope = append(charges.Scope, key.Scope)
+				charges.Description = append(charges.Description, key.Description)
+				charges.Product = append(charges.Product, key.Product)
+				charges.Usage = append(charges.Usage, usage)
+			}
+			b.PendingCharges = nil
 		}
 
 		for _, scope := range scopes {
@@ -992,6 +1183,98 @@ func accountingProcessTasksNow(now time.Time, filter func(b *internalBucket) boo
 				)
 			}
 
+			if len(historyRequests.Allocation) > 0 {
+				db.Exec(
+					tx,
+					`
+						with data as (
+							select
+								unnest(cast(:allocation as int8[])) as allocation,
+								unnest(cast(:created_at as int8[])) as created_at,
+								unnest(cast(:changed_by as text[])) as changed_by,
+								unnest(cast(:change_type as text[])) as change_type,
+								unnest(cast(:quota_before as int8[])) as quota_before,
+								unnest(cast(:quota_after as int8[])) as quota_after,
+								unnest(cast(:related_allocation as int8[])) as related_allocation,
+								unnest(cast(:reason as text[])) as reason
+						)
+						insert into accounting.allocation_history(allocation_id, created_at, changed_by, change_type, 
+							quota_before, quota_after, related_allocation_id, reason) 
+						select
+							d.allocation,
+							to_timestamp(d.created_at / 1000.0),
+							d.changed_by,
+							d.change_type,
+							d.quota_before,
+							d.quota_after,
+							case
+								when d.related_allocation = 0 then null
+								else d.related_allocation
+							end,
+							d.reason
+						from
+							data d
+					`,
+					db.Params{
+						"allocation":         historyRequests.Allocation,
+						"created_at":         historyRequests.CreatedAt,
+						"changed_by":         historyRequests.ChangedBy,
+						"change_type":        historyRequests.ChangeType,
+						"quota_before":       historyRequests.QuotaBefore,
+						"quota_after":        historyRequests.QuotaAfter,
+						"related_allocation": historyRequests.RelatedAllocation,
+						"reason":             historyRequests.Reason,
+					},
+				)
+			}
+
+			chargeStatements := []util.Tuple2[chargeRequest, string]{
+				{First: deltaCharges, Second: "usage = usage_charges.usage + excluded.usage"},
+				{First: levelCharges, Second: "usage = excluded.usage"},
+			}
+
+			for _, statement := range chargeStatements {
+				charges, onConflict := statement.First, statement.Second
+				if len(charges.Wallet) == 0 {
+					continue
+				}
+
+				db.Exec(
+					tx,
+					`
+						with data as (
+							select
+								unnest(cast(:wallet as int8[])) as wallet,
+								unnest(cast(:period_start as int8[])) as period_start,
+								unnest(cast(:scope as text[])) as scope,
+								unnest(cast(:description as text[])) as description,
+								unnest(cast(:product as text[])) as product,
+								unnest(cast(:usage as int8[])) as usage
+						)
+						insert into accounting.usage_charges(wallet_id, period_start, scope, description, product, usage) 
+						select
+							d.wallet,
+							to_timestamp(d.period_start / 1000.0),
+							d.scope,
+							d.description,
+							d.product,
+							d.usage
+						from
+							data d
+						on conflict (wallet_id, period_start, scope, description, product) do update set
+							`+onConflict+`
+					`,
+					db.Params{
+						"wallet":       charges.Wallet,
+						"period_start": charges.PeriodStart,
+						"scope":        charges.Scope,
+						"description":  charges.Description,
+						"product":      charges.Product,
+						"usage":        charges.Usage,
+					},
+				)
+			}
+
 			if len(usageRequests.Key) > 0 {
 				db.Exec(
 					tx,
@@ -1034,6 +1317,8 @@ func accountingProcessTasksNow(now time.Time, filter func(b *internalBucket) boo
 	accountingProcessMutex.Unlock()
 	accountingScansDuration.Observe(timer.Mark().Seconds())
 
+	budgetAlertsDeliver(budgetAlertsEvaluate(now))
+
 	// NOTE(Dan): This is a very simple version of a reliable cron-job which runs in our code and does not require
 	// anything special at all. This only works because it is perfectly safe to sample too many times. This code does
 	// reasonable protection against sampling too many times, but if the Core ends up crashing at the right time, then
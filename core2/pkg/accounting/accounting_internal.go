@@ -123,6 +123,12 @@ type internalBucket struct {
 
 	AllocationsById map[accAllocId]*internalAllocation
 
+	// PendingHistory contains allocation history entries which have not yet been persisted
+	PendingHistory []internalAllocationHistoryEntry
+
+	// PendingCharges contains the charges made since the charge ledger was last persisted (see lInternalRecordCharge)
+	PendingCharges map[internalChargeKey]int64
+
 	disableEvaluation bool
 }
 
@@ -189,6 +195,41 @@ type internalAllocation struct {
 	Committed bool
 }
 
+type internalAllocationHistoryEntry struct {
+	Allocation        accAllocId
+	Timestamp         time.Time
+	ChangedBy         string
+	Type              accapi.AllocationHistoryChangeType
+	QuotaBefore       int64
+	QuotaAfter        int64
+	RelatedAllocation util.Option[accAllocId]
+	Reason            string
+}
+
+func (e *internalAllocationHistoryEntry) ToApi() accapi.AllocationHistoryEntry {
+	result := accapi.AllocationHistoryEntry{
+		AllocationId: int64(e.Allocation),
+		Timestamp:    fndapi.Timestamp(e.Timestamp),
+		ChangedBy:    e.ChangedBy,
+		Type:         e.Type,
+		QuotaBefore:  e.QuotaBefore,
+		QuotaAfter:   e.QuotaAfter,
+		Reason:       e.Reason,
+	}
+	if e.RelatedAllocation.Present {
+		result.RelatedAllocationId.Set(int64(e.RelatedAllocation.Value))
+	}
+	return result
+}
+
+type internalChargeKey struct {
+	Wallet      AccWalletId
+	Day         time.Time
+	Scope       string
+	Description string
+	Product     string
+}
+
 type scopedUsage struct {
 	Mu sync.RWMutex
 
@@ -285,6 +326,12 @@ func internalReportUsage(now time.Time, request accapi.ReportUsageRequest) (bool
 	w.LocalUsage += delta
 	w.Dirty = true
 
+	if scope != nil {
+		lInternalRecordCharge(b, now, w, request.Description, delta, scope.Usage)
+	} else {
+		lInternalRecordCharge(b, now, w, request.Description, delta, w.LocalUsage)
+	}
+
 	for visitedId, _ := range visitedWallets {
 		visited := b.WalletsById[visitedId]
 		lInternalReevaluate(b, now, visited, false)
@@ -293,6 +340,29 @@ func internalReportUsage(now time.Time, request accapi.ReportUsageRequest) (bool
 	return !w.WasLocked, nil
 }
 
+// lInternalRecordCharge records a charge in the charge ledger, which is used for exporting usage. Charges are recorded
+// per wallet, day and charge description. For capacity-based products, the ledger stores the latest usage level of
+// the day instead of the sum of charges.
+func lInternalRecordCharge(b *internalBucket, now time.Time, w *internalWallet, description accapi.ChargeDescription, delta int64, level int64) {
+	if b.PendingCharges == nil {
+		b.PendingCharges = map[internalChargeKey]int64{}
+	}
+
+	key := internalChargeKey{
+		Wallet:      w.Id,
+		Day:         util.StartOfDayUTC(now),
+		Scope:       description.Scope.GetOrDefault(""),
+		Description: description.Description.GetOrDefault(""),
+		Product:     description.Product.GetOrDefault(""),
+	}
+
+	if b.IsCapacityBased() {
+		b.PendingCharges[key] = level
+	} else {
+		b.PendingCharges[key] += delta
+	}
+}
+
 // If grantedIn is specified, then the allocations will not be committed to the database before
 // internalCommitGrantAllocations is invoked with the same ID.
 func internalAllocateNoCommit(
@@ -524,6 +594,157 @@ func internalUpdateAllocation(
 	return grantedIn, changelog, nil
 }
 
+// internalQuotaTransfer describes a single transfer of quota from one allocation to a sibling allocation.
+type internalQuotaTransfer struct {
+	Source accAllocId
+	Target accAllocId
+	Amount int64
+	Reason string
+}
+
+// internalTransferQuota moves quota from one allocation to a sibling allocation. Sibling allocations are allocations
+// which have been made by the same parent wallet. All transfers are validated before any of them are applied, such
+// that either every transfer is applied or none of them are. Each transfer is recorded in the allocation history of
+// both allocations.
+func internalTransferQuota(
+	parentOwner *internalOwner,
+	now time.Time,
+	b *internalBucket,
+	transfers []internalQuotaTransfer,
+	changedBy string,
+) *util.HttpError {
+	b.Mu.Lock()
+	defer b.Mu.Unlock()
+
+	// Quotas as they will be once the transfers validated so far have been applied
+	projectedQuotas := map[accAllocId]int64{}
+	projectedQuota := func(alloc *internalAllocation) int64 {
+		quota, ok := projectedQuotas[alloc.Id]
+		if !ok {
+			return alloc.Quota
+		}
+		return quota
+	}
+
+	retiredAllocationsContribute := !b.IsCapacityBased()
+	projectedGroupQuota := func(group *internalGroup) int64 {
+		sum := int64(0)
+		for allocId, _ := range group.Allocations {
+			alloc := b.AllocationsById[allocId]
+			if alloc.Active && (retiredAllocationsContribute || !alloc.Retired) {
+				sum += projectedQuota(alloc)
+			}
+		}
+		return sum
+	}
+
+	for _, transfer := range transfers {
+		source := b.AllocationsById[transfer.Source]
+		target := b.AllocationsById[transfer.Target]
+		if source == nil || target == nil || !source.Committed || !target.Committed {
+			return util.HttpErr(http.StatusNotFound, "Unknown allocation")
+		}
+
+		if transfer.Source == transfer.Target {
+			return util.HttpErr(http.StatusBadRequest, "You cannot transfer quota from an allocation to itself")
+		}
+
+		if source.Parent == internalGraphRoot || source.Parent != target.Parent {
+			return util.HttpErr(http.StatusBadRequest, "Quota can only be transferred between allocations with the same parent")
+		}
+
+		parent := b.WalletsById[source.Parent]
+		if parent == nil || parent.OwnedBy != parentOwner.Id {
+			return util.HttpErr(http.StatusForbidden, "You are not allowed to modify these allocations")
+		}
+
+		if transfer.Amount <= 0 {
+			return util.HttpErr(http.StatusBadRequest, "The amount transferred must be positive")
+		}
+
+		sourceQuota := util.OptValue(projectedQuota(source) - transfer.Amount)
+		targetQuota := util.OptValue(projectedQuota(target) + transfer.Amount)
+
+		if _, err := lValidateUpdate(now, source, sourceQuota, source.Start, source.End); err != nil {
+			return err
+		}
+
+		if _, err := lValidateUpdate(now, target, targetQuota, target.Start, target.End); err != nil {
+			return err
+		}
+
+		if !source.Start.After(now) {
+			sourceGroup := b.WalletsById[source.BelongsTo].AllocationsByParent[source.Parent]
+			if projectedGroupQuota(sourceGroup)-transfer.Amount < sourceGroup.TreeUsage {
+				return util.HttpErr(http.StatusForbidden, "You cannot transfer quota which has already been used")
+			}
+		}
+
+		projectedQuotas[source.Id] = sourceQuota.Value
+		projectedQuotas[target.Id] = targetQuota.Value
+	}
+
+	var walletsToReevaluate []*internalWallet
+	for _, transfer := range transfers {
+		source := b.AllocationsById[transfer.Source]
+		target := b.AllocationsById[transfer.Target]
+
+		b.PendingHistory = append(b.PendingHistory,
+			internalAllocationHistoryEntry{
+				Allocation:        transfer.Source,
+				Timestamp:         now,
+				ChangedBy:         changedBy,
+				Type:              accapi.AllocationHistoryTransferOut,
+				QuotaBefore:       source.Quota,
+				QuotaAfter:        source.Quota - transfer.Amount,
+				RelatedAllocation: util.OptValue(transfer.Target),
+				Reason:            transfer.Reason,
+			},
+			internalAllocationHistoryEntry{
+				Allocation:        transfer.Target,
+				Timestamp:         now,
+				ChangedBy:         changedBy,
+				Type:              accapi.AllocationHistoryTransferIn,
+				QuotaBefore:       target.Quota,
+				QuotaAfter:        target.Quota + transfer.Amount,
+				RelatedAllocation: util.OptValue(transfer.Source),
+				Reason:            transfer.Reason,
+			},
+		)
+
+		source.Quota -= transfer.Amount
+		source.Dirty = true
+		target.Quota += transfer.Amount
+		target.Dirty = true
+
+		for _, wallet := range []*internalWallet{b.WalletsById[source.BelongsTo], b.WalletsById[target.BelongsTo]} {
+			if !slices.Contains(walletsToReevaluate, wallet) {
+				walletsToReevaluate = append(walletsToReevaluate, wallet)
+			}
+		}
+	}
+
+	for _, wallet := range walletsToReevaluate {
+		lInternalReevaluate(b, now, wallet, true)
+		lInternalMarkSignificantUpdate(b, now, wallet)
+	}
+	return nil
+}
+
+// internalPendingAllocationHistory returns the history entries of an allocation which have not yet been persisted.
+func internalPendingAllocationHistory(b *internalBucket, allocId accAllocId) []internalAllocationHistoryEntry {
+	b.Mu.RLock()
+	defer b.Mu.RUnlock()
+
+	var result []internalAllocationHistoryEntry
+	for _, entry := range b.PendingHistory {
+		if entry.Allocation == allocId {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
 func internalCompleteScan(now time.Time, persistence func(buckets []*internalBucket, scopes []*scopedUsage, onPersistHandlers []internalOnPersistHandler)) {
 	var buckets []*internalBucket
 	var scopes []*scopedUsage
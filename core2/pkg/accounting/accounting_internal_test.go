@@ -782,3 +782,114 @@ func TestUpdateAllocation(t *testing.T) {
 	assert.Equal(t, util.HttpErr(http.StatusForbidden, "You are not allowed to modify this allocation"), err)
 
 }
+
+func TestTransferQuota(t *testing.T) {
+	e := newEnv(t, capacityCategory)
+	e.AllocateEx(0, 0, 1_000, 1_000, "provider", "")
+	e.AllocateEx(0, 0, 1_000, 100, "project", "provider")
+	done := e.AllocateEx(0, 0, 1_000, 60, "done", "project")
+	busy := e.AllocateEx(0, 0, 1_000, 40, "busy", "project")
+	for _, id := range []accAllocId{done, busy} {
+		internalCommitAllocation(e.Bucket, id)
+	}
+
+	e.ReportDelta(0, "done", 20)
+	e.ReportDelta(0, "busy", 40)
+	assertEqualMaxUsable(t, e, "busy", 0)
+
+	parent := e.Owner("project")
+
+	// Only unused quota can be transferred
+	err := e.TransferQuota(parent, 0, done, busy, 41)
+	assert.Equal(t, http.StatusForbidden, err.StatusCode)
+
+	err = e.TransferQuota(e.Owner("done"), 0, done, busy, 10)
+	assert.Equal(t, http.StatusForbidden, err.StatusCode)
+
+	err = e.TransferQuota(parent, 0, done, busy, 0)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+
+	err = e.TransferQuota(parent, 0, done, busy, 40)
+	assert.Nil(t, err)
+
+	e.ExpectAllocationValues(t, e.Bucket.AllocationsById[done], 20, 0, 1_000)
+	e.ExpectAllocationValues(t, e.Bucket.AllocationsById[busy], 80, 0, 1_000)
+	assertEqualMaxUsable(t, e, "busy", 40)
+	assertEqualMaxUsable(t, e, "done", 0)
+
+	history := internalPendingAllocationHistory(e.Bucket, busy)
+	assert.Equal(t, 1, len(history))
+	assert.Equal(t, accapi.AllocationHistoryTransferIn, history[0].Type)
+	assert.Equal(t, int64(40), history[0].QuotaBefore)
+	assert.Equal(t, int64(80), history[0].QuotaAfter)
+	assert.Equal(t, done, history[0].RelatedAllocation.Value)
+
+	history = internalPendingAllocationHistory(e.Bucket, done)
+	assert.Equal(t, 1, len(history))
+	assert.Equal(t, accapi.AllocationHistoryTransferOut, history[0].Type)
+}
+
+func TestTransferQuotaBulkIsAtomic(t *testing.T) {
+	e := newEnv(t, capacityCategory)
+	e.AllocateEx(0, 0, 1_000, 1_000, "provider", "")
+	e.AllocateEx(0, 0, 1_000, 100, "project", "provider")
+	a := e.AllocateEx(0, 0, 1_000, 50, "a", "project")
+	b := e.AllocateEx(0, 0, 1_000, 50, "b", "project")
+	for _, id := range []accAllocId{a, b} {
+		internalCommitAllocation(e.Bucket, id)
+	}
+
+	parent := e.Owner("project")
+
+	// The second transfer is invalid, which must also prevent the first from being applied
+	err := e.TransferQuotaBulk(
+		parent,
+		0,
+		internalQuotaTransfer{Source: a, Target: b, Amount: 10},
+		internalQuotaTransfer{Source: a, Target: a, Amount: 10},
+	)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+	e.ExpectAllocationValues(t, e.Bucket.AllocationsById[a], 50, 0, 1_000)
+	e.ExpectAllocationValues(t, e.Bucket.AllocationsById[b], 50, 0, 1_000)
+	assert.Equal(t, 0, len(e.Bucket.PendingHistory))
+
+	// Transfers are validated against the quota left by the preceding transfers
+	err = e.TransferQuotaBulk(
+		parent,
+		0,
+		internalQuotaTransfer{Source: a, Target: b, Amount: 30},
+		internalQuotaTransfer{Source: a, Target: b, Amount: 30},
+	)
+	assert.Equal(t, http.StatusForbidden, err.StatusCode)
+	e.ExpectAllocationValues(t, e.Bucket.AllocationsById[a], 50, 0, 1_000)
+	assert.Equal(t, 0, len(e.Bucket.PendingHistory))
+
+	err = e.TransferQuotaBulk(
+		parent,
+		0,
+		internalQuotaTransfer{Source: a, Target: b, Amount: 30},
+		internalQuotaTransfer{Source: b, Target: a, Amount: 10},
+	)
+	assert.Nil(t, err)
+	e.ExpectAllocationValues(t, e.Bucket.AllocationsById[a], 30, 0, 1_000)
+	e.ExpectAllocationValues(t, e.Bucket.AllocationsById[b], 70, 0, 1_000)
+	assert.Equal(t, 4, len(e.Bucket.PendingHistory))
+}
+
+func TestTransferQuotaRequiresSiblings(t *testing.T) {
+	e := newEnv(t, timeCategory)
+	e.AllocateEx(0, 0, 1_000, 100, "project", "provider")
+	e.AllocateEx(0, 0, 1_000, 100, "other", "provider")
+	child := e.AllocateEx(0, 0, 1_000, 50, "child", "project")
+	stranger := e.AllocateEx(0, 0, 1_000, 50, "stranger", "other")
+	for _, id := range []accAllocId{child, stranger} {
+		internalCommitAllocation(e.Bucket, id)
+	}
+
+	err := e.TransferQuota(e.Owner("project"), 0, child, stranger, 10)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+
+	err = e.TransferQuota(e.Owner("project"), 0, child, child, 10)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+	assert.Equal(t, 0, len(e.Bucket.PendingHistory))
+}
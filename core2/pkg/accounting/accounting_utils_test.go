@@ -215,6 +215,14 @@ func (e *env) UpdateAllocation(t *testing.T, owner *internalOwner, now int, allo
 	return err
 }
 
+func (e *env) TransferQuota(owner *internalOwner, now int, source accAllocId, target accAllocId, amount int64) *util.HttpError {
+	return e.TransferQuotaBulk(owner, now, internalQuotaTransfer{Source: source, Target: target, Amount: amount, Reason: "testing"})
+}
+
+func (e *env) TransferQuotaBulk(owner *internalOwner, now int, transfers ...internalQuotaTransfer) *util.HttpError {
+	return internalTransferQuota(owner, e.Tm(now), e.Bucket, transfers, owner.Reference)
+}
+
 func (e *env) ExpectAllocationValues(t *testing.T, allocation *internalAllocation, expectedQuota int64, expectedStartTime int, expectedEndTime int) {
 	t.Helper()
 	assert.Equal(t, expectedQuota, allocation.Quota)
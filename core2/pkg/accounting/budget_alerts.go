@@ -0,0 +1,655 @@
+package accounting
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ucloud.dk/core/pkg/coreutil"
+	accapi "ucloud.dk/shared/pkg/accounting"
+	db "ucloud.dk/shared/pkg/database"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Budget alerts
+// =====================================================================================================================
+// Budget alerts are user-configured thresholds on a single wallet. Unlike the low funds scan, which uses a single
+// system-wide limit, a budget alert is created by a workspace administrator and delivered to the user who created it.
+//
+// Alerts are evaluated after every scan of the accounting system and fire once per crossing of their threshold:
+//
+// - Usage percent alerts fire when the active usage reaches the threshold (in percent) of the active quota. The alert
+//   is re-armed once usage drops below the threshold again, for example because a new allocation was added.
+// - Daily burn rate alerts fire when more than the threshold (in the accounting unit of the category) has been used
+//   within the current 24-hour window. The alert is re-armed when a new window starts.
+//
+// All alerts are kept in memory and the state of each alert is persisted whenever it changes.
+//
+// Mutex lock order: budgetAlertGlobals.Mu -> accGlobals -> bucket
+
+type budgetAlertId int64
+
+const (
+	budgetAlertMaxPerWorkspace = 64
+	budgetAlertBurnRateWindow  = 24 * time.Hour
+)
+
+var budgetAlertGlobals struct {
+	Mu     sync.RWMutex
+	IdAcc  atomic.Int64
+	Alerts map[budgetAlertId]*internalBudgetAlert
+}
+
+type internalBudgetAlert struct {
+	Id             budgetAlertId
+	OwnerReference string
+	CreatedBy      string
+	CreatedAt      time.Time
+	Specification  accapi.BudgetAlertSpecification
+
+	Triggered        bool
+	LastTriggeredAt  time.Time
+	WindowStart      time.Time
+	WindowStartUsage int64
+}
+
+// budgetAlertUsage contains the numbers from a wallet which are needed to evaluate an alert.
+type budgetAlertUsage struct {
+	ActiveUsage int64
+	ActiveQuota int64
+	TotalUsage  int64
+}
+
+func (a *internalBudgetAlert) ToApi() accapi.BudgetAlert {
+	result := accapi.BudgetAlert{
+		Id:            fmt.Sprint(a.Id),
+		Owner:         accapi.WalletOwnerFromReference(a.OwnerReference),
+		CreatedBy:     a.CreatedBy,
+		CreatedAt:     fndapi.Timestamp(a.CreatedAt),
+		Specification: a.Specification,
+		Status: accapi.BudgetAlertStatus{
+			Triggered: a.Triggered,
+		},
+	}
+
+	if !a.LastTriggeredAt.IsZero() {
+		result.Status.LastTriggeredAt.Set(fndapi.Timestamp(a.LastTriggeredAt))
+	}
+	return result
+}
+
+// Public API
+// =====================================================================================================================
+
+func budgetAlertWorkspace(actor rpc.Actor) string {
+	if actor.Project.Present {
+		return string(actor.Project.Value)
+	} else {
+		return actor.Username
+	}
+}
+
+func budgetAlertCanManage(actor rpc.Actor) bool {
+	if !actor.Project.Present {
+		return true
+	} else {
+		return actor.Membership[actor.Project.Value].Satisfies(rpc.ProjectRoleAdmin)
+	}
+}
+
+func BudgetAlertsBrowse(actor rpc.Actor) fndapi.PageV2[accapi.BudgetAlert] {
+	workspace := budgetAlertWorkspace(actor)
+
+	var result []accapi.BudgetAlert
+	budgetAlertGlobals.Mu.RLock()
+	for _, alert := range budgetAlertGlobals.Alerts {
+		if alert.OwnerReference == workspace {
+			result = append(result, alert.ToApi())
+		}
+	}
+	budgetAlertGlobals.Mu.RUnlock()
+
+	slices.SortFunc(result, func(a, b accapi.BudgetAlert) int {
+		aId, _ := strconv.ParseInt(a.Id, 10, 64)
+		bId, _ := strconv.ParseInt(b.Id, 10, 64)
+		return int(aId - bId)
+	})
+
+	return fndapi.PageV2[accapi.BudgetAlert]{Items: util.NonNilSlice(result), ItemsPerPage: len(result)}
+}
+
+func BudgetAlertsCreate(now time.Time, actor rpc.Actor, specs []accapi.BudgetAlertSpecification) ([]fndapi.FindByStringId, *util.HttpError) {
+	if !budgetAlertCanManage(actor) {
+		return nil, util.HttpErr(http.StatusForbidden, "only project administrators can configure budget alerts")
+	}
+
+	workspace := budgetAlertWorkspace(actor)
+
+	for _, spec := range specs {
+		if err := budgetAlertValidate(workspace, spec); err != nil {
+			return nil, err
+		}
+	}
+
+	var result []fndapi.FindByStringId
+	var created []internalBudgetAlert
+
+	budgetAlertGlobals.Mu.Lock()
+	existing := 0
+	for _, alert := range budgetAlertGlobals.Alerts {
+		if alert.OwnerReference == workspace {
+			existing++
+		}
+	}
+
+	if existing+len(specs) > budgetAlertMaxPerWorkspace {
+		budgetAlertGlobals.Mu.Unlock()
+		return nil, util.HttpErr(
+			http.StatusBadRequest,
+			"a workspace cannot have more than %d budget alerts",
+			budgetAlertMaxPerWorkspace,
+		)
+	}
+
+	for _, spec := range specs {
+		alert := &internalBudgetAlert{
+			Id:             budgetAlertId(budgetAlertGlobals.IdAcc.Add(1)),
+			OwnerReference: workspace,
+			CreatedBy:      actor.Username,
+			CreatedAt:      now,
+			Specification:  spec,
+		}
+
+		budgetAlertGlobals.Alerts[alert.Id] = alert
+		created = append(created, *alert)
+		result = append(result, fndapi.FindByStringId{Id: fmt.Sprint(alert.Id)})
+	}
+	budgetAlertGlobals.Mu.Unlock()
+
+	budgetAlertsPersist(created)
+	return result, nil
+}
+
+func BudgetAlertsDelete(actor rpc.Actor, ids []string) *util.HttpError {
+	if !budgetAlertCanManage(actor) {
+		return util.HttpErr(http.StatusForbidden, "only project administrators can configure budget alerts")
+	}
+
+	workspace := budgetAlertWorkspace(actor)
+	var toDelete []budgetAlertId
+
+	for _, rawId := range ids {
+		id, err := strconv.ParseInt(rawId, 10, 64)
+		if err != nil {
+			return util.HttpErr(http.StatusNotFound, "unknown budget alert")
+		}
+		toDelete = append(toDelete, budgetAlertId(id))
+	}
+
+	budgetAlertGlobals.Mu.Lock()
+	for _, id := range toDelete {
+		alert, ok := budgetAlertGlobals.Alerts[id]
+		if !ok || alert.OwnerReference != workspace {
+			budgetAlertGlobals.Mu.Unlock()
+			return util.HttpErr(http.StatusNotFound, "unknown budget alert")
+		}
+	}
+
+	for _, id := range toDelete {
+		delete(budgetAlertGlobals.Alerts, id)
+	}
+	budgetAlertGlobals.Mu.Unlock()
+
+	budgetAlertsPersistDeletion(toDelete)
+	return nil
+}
+
+func budgetAlertValidate(workspace string, spec accapi.BudgetAlertSpecification) *util.HttpError {
+	if !slices.Contains(accapi.BudgetAlertTypeOptions, spec.Type) {
+		return util.HttpErr(http.StatusBadRequest, "unknown budget alert type")
+	}
+
+	switch spec.Type {
+	case accapi.BudgetAlertUsagePercent:
+		if spec.Threshold < 1 || spec.Threshold > 100 {
+			return util.HttpErr(http.StatusBadRequest, "threshold must be a percentage between 1 and 100")
+		}
+
+	case accapi.BudgetAlertDailyBurnRate:
+		if spec.Threshold < 1 {
+			return util.HttpErr(http.StatusBadRequest, "threshold must be positive")
+		}
+	}
+
+	b, _, ok := budgetAlertWallet(workspace, spec.Category)
+	if !ok {
+		return util.HttpErr(http.StatusNotFound, "this workspace has no allocation in %s/%s", spec.Category.Name,
+			spec.Category.Provider)
+	}
+
+	if spec.Type == accapi.BudgetAlertDailyBurnRate && b.IsCapacityBased() {
+		return util.HttpErr(http.StatusBadRequest, "a burn rate cannot be computed for %s", spec.Category.Name)
+	}
+
+	return nil
+}
+
+// budgetAlertWallet finds the wallet an alert applies to. Unlike most lookup functions in the accounting system, this
+// function does not create a wallet if one does not exist.
+func budgetAlertWallet(reference string, category accapi.ProductCategoryIdV2) (*internalBucket, AccWalletId, bool) {
+	accGlobals.Mu.RLock()
+	owner, hasOwner := accGlobals.OwnersByReference[reference]
+	b, hasBucket := accGlobals.BucketsByCategory[category]
+	accGlobals.Mu.RUnlock()
+
+	if !hasOwner || !hasBucket {
+		return nil, 0, false
+	}
+
+	b.Mu.RLock()
+	w, ok := b.WalletsByOwner[owner.Id]
+	ok = ok && len(w.AllocationsByParent) > 0
+	var walletId AccWalletId
+	if ok {
+		walletId = w.Id
+	}
+	b.Mu.RUnlock()
+
+	return b, walletId, ok
+}
+
+func budgetAlertRetrieveUsage(reference string, category accapi.ProductCategoryIdV2) (budgetAlertUsage, bool) {
+	b, walletId, ok := budgetAlertWallet(reference, category)
+	if !ok {
+		return budgetAlertUsage{}, false
+	}
+
+	b.Mu.RLock()
+	w, ok := b.WalletsById[walletId]
+	var result budgetAlertUsage
+	if ok {
+		result = budgetAlertUsage{
+			ActiveUsage: lInternalWalletTotalUsageFromActiveAllocationsUiOnly(b, w),
+			ActiveQuota: lInternalWalletTotalQuotaFromActiveAllocations(b, w),
+			TotalUsage:  lInternalWalletTotalUsageInNode(b, w),
+		}
+	}
+	b.Mu.RUnlock()
+	return result, ok
+}
+
+// Evaluation
+// =====================================================================================================================
+
+type budgetAlertFired struct {
+	Alert   internalBudgetAlert
+	Usage   budgetAlertUsage
+	Message string
+}
+
+// lBudgetAlertEvaluate updates the state of an alert based on the current usage of the wallet. It returns true in
+// fired if the alert crossed its threshold and in dirty if the state of the alert must be persisted.
+func lBudgetAlertEvaluate(now time.Time, alert *internalBudgetAlert, usage budgetAlertUsage) (fired bool, dirty bool) {
+	threshold := alert.Specification.Threshold
+
+	switch alert.Specification.Type {
+	case accapi.BudgetAlertUsagePercent:
+		if usage.ActiveQuota <= 0 {
+			return false, false
+		}
+
+		reached := usage.ActiveUsage*100 >= threshold*usage.ActiveQuota
+		if reached && !alert.Triggered {
+			alert.Triggered = true
+			alert.LastTriggeredAt = now
+			return true, true
+		} else if !reached && alert.Triggered {
+			alert.Triggered = false
+			return false, true
+		}
+
+	case accapi.BudgetAlertDailyBurnRate:
+		windowExpired := alert.WindowStart.IsZero() || !now.Before(alert.WindowStart.Add(budgetAlertBurnRateWindow))
+
+		// Usage can decrease when allocations are retired. In that case the window is restarted since it is no longer
+		// possible to tell how much has been used in the current window.
+		if windowExpired || usage.TotalUsage < alert.WindowStartUsage {
+			alert.WindowStart = now
+			alert.WindowStartUsage = usage.TotalUsage
+			alert.Triggered = false
+			dirty = true
+		}
+
+		if !alert.Triggered && usage.TotalUsage-alert.WindowStartUsage >= threshold {
+			alert.Triggered = true
+			alert.LastTriggeredAt = now
+			return true, true
+		}
+	}
+
+	return false, dirty
+}
+
+func budgetAlertMessage(alert *internalBudgetAlert, usage budgetAlertUsage, unit string) string {
+	switch alert.Specification.Type {
+	case accapi.BudgetAlertUsagePercent:
+		return fmt.Sprintf(
+			"Usage has reached %d%% of the quota (%d of %d %s)",
+			alert.Specification.Threshold,
+			usage.ActiveUsage,
+			usage.ActiveQuota,
+			unit,
+		)
+
+	case accapi.BudgetAlertDailyBurnRate:
+		return fmt.Sprintf(
+			"More than %d %s has been used within 24 hours",
+			alert.Specification.Threshold,
+			unit,
+		)
+
+	default:
+		return ""
+	}
+}
+
+// budgetAlertsEvaluate evaluates all budget alerts and returns the alerts which fired. This is invoked after every
+// scan of the accounting system. Wallets which no longer exist, or no longer have any allocations, are skipped.
+func budgetAlertsEvaluate(now time.Time) []budgetAlertFired {
+	var fired []budgetAlertFired
+	var dirty []internalBudgetAlert
+
+	budgetAlertGlobals.Mu.Lock()
+	for _, alert := range budgetAlertGlobals.Alerts {
+		usage, ok := budgetAlertRetrieveUsage(alert.OwnerReference, alert.Specification.Category)
+		if !ok {
+			continue
+		}
+
+		didFire, isDirty := lBudgetAlertEvaluate(now, alert, usage)
+		if isDirty {
+			dirty = append(dirty, *alert)
+		}
+
+		if didFire {
+			unit := ""
+			accGlobals.Mu.RLock()
+			b, hasBucket := accGlobals.BucketsByCategory[alert.Specification.Category]
+			accGlobals.Mu.RUnlock()
+			if hasBucket {
+				unit = b.Category.AccountingUnit.NamePlural
+			}
+
+			fired = append(fired, budgetAlertFired{
+				Alert:   *alert,
+				Usage:   usage,
+				Message: budgetAlertMessage(alert, usage, unit),
+			})
+		}
+	}
+	budgetAlertGlobals.Mu.Unlock()
+
+	budgetAlertsPersist(dirty)
+	return fired
+}
+
+// budgetAlertsDeliver sends a notification and an email for every alert which fired. Alerts are only delivered to
+// their creator if they still have permissions to manage the alerts of the workspace.
+func budgetAlertsDeliver(fired []budgetAlertFired) {
+	if accGlobals.TestingEnabled || len(fired) == 0 {
+		return
+	}
+
+	type recipient struct {
+		Allowed bool
+		Title   string
+	}
+
+	recipients := map[budgetAlertId]recipient{}
+	db.NewTx0(func(tx *db.Transaction) {
+		for _, item := range fired {
+			alert := &item.Alert
+			owner := accapi.WalletOwnerFromReference(alert.OwnerReference)
+			if owner.Type == accapi.WalletOwnerTypeUser {
+				recipients[alert.Id] = recipient{
+					Allowed: owner.Username == alert.CreatedBy,
+					Title:   fmt.Sprintf("Personal workspace of %s", owner.Username),
+				}
+			} else {
+				project, ok := coreutil.ProjectRetrieveFromDatabase(tx, owner.ProjectId)
+				if ok {
+					isAdmin := false
+					for _, member := range project.Status.Members {
+						if member.Username == alert.CreatedBy && member.Role.Satisfies(fndapi.ProjectRoleAdmin) {
+							isAdmin = true
+							break
+						}
+					}
+
+					recipients[alert.Id] = recipient{
+						Allowed: isAdmin,
+						Title:   project.Specification.Title,
+					}
+				}
+			}
+		}
+	})
+
+	var mails []fndapi.MailSendToUserRequest
+	for _, item := range fired {
+		alert := &item.Alert
+		rcpt, ok := recipients[alert.Id]
+		if !ok || !rcpt.Allowed {
+			continue
+		}
+
+		category := alert.Specification.Category
+
+		notification := fndapi.Notification{
+			Type:    "BUDGET_ALERT",
+			Message: fmt.Sprintf("%s: %s", category.Name, item.Message),
+		}
+
+		meta, _ := json.Marshal(map[string]any{
+			"title":    fmt.Sprintf("Budget alert in %s", rcpt.Title),
+			"alertId":  fmt.Sprint(alert.Id),
+			"category": category,
+			"owner":    accapi.WalletOwnerFromReference(alert.OwnerReference),
+		})
+		notification.Meta.Set(meta)
+
+		_, err := fndapi.NotificationsCreate.Invoke(fndapi.NotificationsCreateRequest{
+			User:         alert.CreatedBy,
+			Notification: notification,
+		})
+		if err != nil {
+			log.Warn("Failed to send budget alert notification: %s", err)
+		}
+
+		mailData, _ := json.Marshal(map[string]any{
+			"type":           fndapi.MailTypeBudgetAlert,
+			"workspaceTitle": rcpt.Title,
+			"category":       category.Name,
+			"provider":       category.Provider,
+			"description":    item.Message,
+		})
+
+		mails = append(mails, fndapi.MailSendToUserRequest{
+			Receiver: alert.CreatedBy,
+			Mail:     mailData,
+		})
+	}
+
+	if len(mails) > 0 {
+		_, err := fndapi.MailSendToUser.Invoke(fndapi.BulkRequest[fndapi.MailSendToUserRequest]{Items: mails})
+		if err != nil {
+			log.Warn("Failed to send budget alert emails: %s", err)
+		}
+	}
+}
+
+// Initialization and persistence
+// =====================================================================================================================
+
+func initBudgetAlerts() {
+	budgetAlertGlobals.Mu.Lock()
+	budgetAlertGlobals.Alerts = map[budgetAlertId]*internalBudgetAlert{}
+	budgetAlertGlobals.Mu.Unlock()
+
+	if accGlobals.TestingEnabled {
+		return
+	}
+
+	budgetAlertsLoad()
+
+	accapi.BudgetAlertsBrowse.Handler(func(info rpc.RequestInfo, request accapi.BudgetAlertsBrowseRequest) (fndapi.PageV2[accapi.BudgetAlert], *util.HttpError) {
+		return BudgetAlertsBrowse(info.Actor), nil
+	})
+
+	accapi.BudgetAlertsCreate.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[accapi.BudgetAlertSpecification]) (fndapi.BulkResponse[fndapi.FindByStringId], *util.HttpError) {
+		ids, err := BudgetAlertsCreate(time.Now(), info.Actor, request.Items)
+		return fndapi.BulkResponse[fndapi.FindByStringId]{Responses: ids}, err
+	})
+
+	accapi.BudgetAlertsDelete.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[fndapi.FindByStringId]) (util.Empty, *util.HttpError) {
+		var ids []string
+		for _, item := range request.Items {
+			ids = append(ids, item.Id)
+		}
+		return util.Empty{}, BudgetAlertsDelete(info.Actor, ids)
+	})
+}
+
+func budgetAlertsLoad() {
+	type rowType struct {
+		Id               int64
+		OwnerReference   string
+		Category         string
+		Provider         string
+		AlertType        string
+		Threshold        int64
+		CreatedBy        string
+		CreatedAt        time.Time
+		Triggered        bool
+		LastTriggeredAt  sql.Null[time.Time]
+		WindowStart      sql.Null[time.Time]
+		WindowStartUsage int64
+	}
+
+	rows := db.NewTx(func(tx *db.Transaction) []rowType {
+		return db.Select[rowType](
+			tx,
+			`
+				select
+					id, owner_reference, category, provider, alert_type, threshold, created_by, created_at,
+					triggered, last_triggered_at, window_start, window_start_usage
+				from accounting.budget_alerts
+		    `,
+			db.Params{},
+		)
+	})
+
+	maxId := int64(0)
+	alerts := map[budgetAlertId]*internalBudgetAlert{}
+	for _, row := range rows {
+		alerts[budgetAlertId(row.Id)] = &internalBudgetAlert{
+			Id:             budgetAlertId(row.Id),
+			OwnerReference: row.OwnerReference,
+			CreatedBy:      row.CreatedBy,
+			CreatedAt:      row.CreatedAt,
+			Specification: accapi.BudgetAlertSpecification{
+				Category:  accapi.ProductCategoryIdV2{Name: row.Category, Provider: row.Provider},
+				Type:      accapi.BudgetAlertType(row.AlertType),
+				Threshold: row.Threshold,
+			},
+			Triggered:        row.Triggered,
+			LastTriggeredAt:  row.LastTriggeredAt.V,
+			WindowStart:      row.WindowStart.V,
+			WindowStartUsage: row.WindowStartUsage,
+		}
+
+		maxId = max(maxId, row.Id)
+	}
+
+	budgetAlertGlobals.IdAcc.Store(maxId)
+	budgetAlertGlobals.Mu.Lock()
+	budgetAlertGlobals.Alerts = alerts
+	budgetAlertGlobals.Mu.Unlock()
+}
+
+func budgetAlertsPersist(alerts []internalBudgetAlert) {
+	if accGlobals.TestingEnabled || len(alerts) == 0 {
+		return
+	}
+
+	db.NewTx0(func(tx *db.Transaction) {
+		batch := db.BatchNew(tx)
+		for _, alert := range alerts {
+			lastTriggeredAt := sql.Null[time.Time]{V: alert.LastTriggeredAt, Valid: !alert.LastTriggeredAt.IsZero()}
+			windowStart := sql.Null[time.Time]{V: alert.WindowStart, Valid: !alert.WindowStart.IsZero()}
+
+			db.BatchExec(
+				batch,
+				`
+					insert into accounting.budget_alerts(id, owner_reference, category, provider, alert_type,
+						threshold, created_by, created_at, triggered, last_triggered_at, window_start, window_start_usage)
+					values (:id, :owner_reference, :category, :provider, :alert_type, :threshold, :created_by,
+						:created_at, :triggered, :last_triggered_at, :window_start, :window_start_usage)
+					on conflict (id) do update set
+						triggered = excluded.triggered,
+						last_triggered_at = excluded.last_triggered_at,
+						window_start = excluded.window_start,
+						window_start_usage = excluded.window_start_usage
+				`,
+				db.Params{
+					"id":                 int64(alert.Id),
+					"owner_reference":    alert.OwnerReference,
+					"category":           alert.Specification.Category.Name,
+					"provider":           alert.Specification.Category.Provider,
+					"alert_type":         string(alert.Specification.Type),
+					"threshold":          alert.Specification.Threshold,
+					"created_by":         alert.CreatedBy,
+					"created_at":         alert.CreatedAt,
+					"triggered":          alert.Triggered,
+					"last_triggered_at":  lastTriggeredAt,
+					"window_start":       windowStart,
+					"window_start_usage": alert.WindowStartUsage,
+				},
+			)
+		}
+		db.BatchSend(batch)
+	})
+}
+
+func budgetAlertsPersistDeletion(ids []budgetAlertId) {
+	if accGlobals.TestingEnabled || len(ids) == 0 {
+		return
+	}
+
+	var rawIds []int64
+	for _, id := range ids {
+		rawIds = append(rawIds, int64(id))
+	}
+
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				delete from accounting.budget_alerts
+				where id = some(:ids)
+		    `,
+			db.Params{
+				"ids": rawIds,
+			},
+		)
+	})
+}
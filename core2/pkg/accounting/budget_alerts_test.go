@@ -0,0 +1,148 @@
+package accounting
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	accapi "ucloud.dk/shared/pkg/accounting"
+	"ucloud.dk/shared/pkg/assert"
+	"ucloud.dk/shared/pkg/rpc"
+)
+
+func percentAlert(threshold int64) *internalBudgetAlert {
+	return &internalBudgetAlert{
+		Specification: accapi.BudgetAlertSpecification{Type: accapi.BudgetAlertUsagePercent, Threshold: threshold},
+	}
+}
+
+func TestBudgetAlertPercentFiresOncePerCrossing(t *testing.T) {
+	alert := percentAlert(80)
+	now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	usage := func(u, q int64) budgetAlertUsage { return budgetAlertUsage{ActiveUsage: u, ActiveQuota: q} }
+
+	fired, dirty := lBudgetAlertEvaluate(now, alert, usage(79, 100))
+	assert.False(t, fired)
+	assert.False(t, dirty)
+
+	fired, _ = lBudgetAlertEvaluate(now, alert, usage(80, 100))
+	assert.True(t, fired)
+	assert.True(t, alert.LastTriggeredAt.Equal(now))
+
+	fired, dirty = lBudgetAlertEvaluate(now, alert, usage(95, 100))
+	assert.False(t, fired)
+	assert.False(t, dirty)
+
+	// A new allocation brings usage below the threshold, which re-arms the alert
+	fired, dirty = lBudgetAlertEvaluate(now, alert, usage(95, 200))
+	assert.False(t, fired)
+	assert.True(t, dirty)
+	assert.False(t, alert.Triggered)
+
+	fired, _ = lBudgetAlertEvaluate(now, alert, usage(160, 200))
+	assert.True(t, fired)
+
+	// Wallets without an active quota never trigger an alert
+	fired, _ = lBudgetAlertEvaluate(now, percentAlert(1), usage(10, 0))
+	assert.False(t, fired)
+}
+
+func TestBudgetAlertBurnRateWindow(t *testing.T) {
+	alert := &internalBudgetAlert{
+		Specification: accapi.BudgetAlertSpecification{Type: accapi.BudgetAlertDailyBurnRate, Threshold: 100},
+	}
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	usage := func(total int64) budgetAlertUsage { return budgetAlertUsage{TotalUsage: total} }
+
+	fired, dirty := lBudgetAlertEvaluate(start, alert, usage(1000))
+	assert.False(t, fired)
+	assert.True(t, dirty)
+	assert.Equal(t, int64(1000), alert.WindowStartUsage)
+
+	fired, _ = lBudgetAlertEvaluate(start.Add(time.Hour), alert, usage(1099))
+	assert.False(t, fired)
+
+	fired, _ = lBudgetAlertEvaluate(start.Add(2*time.Hour), alert, usage(1100))
+	assert.True(t, fired)
+
+	fired, _ = lBudgetAlertEvaluate(start.Add(3*time.Hour), alert, usage(1500))
+	assert.False(t, fired)
+
+	// A new window starts from the usage at the start of the window
+	fired, _ = lBudgetAlertEvaluate(start.Add(24*time.Hour), alert, usage(1550))
+	assert.False(t, fired)
+	assert.False(t, alert.Triggered)
+	assert.Equal(t, int64(1550), alert.WindowStartUsage)
+
+	fired, _ = lBudgetAlertEvaluate(start.Add(25*time.Hour), alert, usage(1650))
+	assert.True(t, fired)
+}
+
+func TestBudgetAlertsCreateAndEvaluate(t *testing.T) {
+	e := newEnv(t, timeCategory)
+	initBudgetAlerts()
+
+	e.AllocateEx(0, 0, 10, 1000, "user", "")
+	e.Scan(0)
+
+	user := actor("user", "")
+	category := timeCategory.ToId()
+
+	_, err := BudgetAlertsCreate(e.Tm(0), *user, []accapi.BudgetAlertSpecification{
+		{Category: category, Type: accapi.BudgetAlertUsagePercent, Threshold: 101},
+	})
+	assert.NotNil(t, err)
+
+	_, err = BudgetAlertsCreate(e.Tm(0), *user, []accapi.BudgetAlertSpecification{
+		{Category: capacityCategory.ToId(), Type: accapi.BudgetAlertUsagePercent, Threshold: 50},
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	ids, err := BudgetAlertsCreate(e.Tm(0), *user, []accapi.BudgetAlertSpecification{
+		{Category: category, Type: accapi.BudgetAlertUsagePercent, Threshold: 50},
+		{Category: category, Type: accapi.BudgetAlertUsagePercent, Threshold: 80},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ids))
+
+	e.ReportDelta(0, "user", 600)
+	fired := budgetAlertsEvaluate(e.Tm(0))
+	assert.Equal(t, 1, len(fired))
+	assert.Equal(t, int64(50), fired[0].Alert.Specification.Threshold)
+	assert.Equal(t, 0, len(budgetAlertsEvaluate(e.Tm(0))))
+
+	e.ReportDelta(0, "user", 300)
+	fired = budgetAlertsEvaluate(e.Tm(0))
+	assert.Equal(t, 1, len(fired))
+	assert.Equal(t, int64(80), fired[0].Alert.Specification.Threshold)
+
+	page := BudgetAlertsBrowse(*user)
+	assert.Equal(t, 2, len(page.Items))
+	assert.True(t, page.Items[0].Status.Triggered)
+	assert.True(t, page.Items[1].Status.Triggered)
+
+	assert.NotNil(t, BudgetAlertsDelete(*actor("other", ""), []string{ids[0].Id}))
+	assert.Nil(t, BudgetAlertsDelete(*user, []string{ids[0].Id}))
+	assert.Equal(t, 1, len(BudgetAlertsBrowse(*user).Items))
+}
+
+func TestBudgetAlertsRequireProjectAdmin(t *testing.T) {
+	e := newEnv(t, timeCategory)
+	initBudgetAlerts()
+
+	const project = "3f1cfc0e-8b6c-4b8e-9d1e-6a2f9c0d7b11"
+	e.AllocateEx(0, 0, 10, 1000, project, "")
+
+	member := actor("member", project)
+	member.Membership[member.Project.Value] = rpc.ProjectRoleUser
+
+	spec := accapi.BudgetAlertSpecification{Category: timeCategory.ToId(), Type: accapi.BudgetAlertUsagePercent, Threshold: 90}
+	_, err := BudgetAlertsCreate(e.Tm(0), *member, []accapi.BudgetAlertSpecification{spec})
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusForbidden, err.StatusCode)
+
+	_, err = BudgetAlertsCreate(e.Tm(0), *actor("pi", project), []accapi.BudgetAlertSpecification{spec})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(BudgetAlertsBrowse(*member).Items))
+}
@@ -182,6 +182,7 @@ func GrantApplicationProcess(actor rpc.Actor, app accapi.GrantApplication) accap
 	}
 
 	grantAttachUnreadCommentStatus(&app, actor.Username)
+	grantFilterReviews(actor, &app)
 
 	return app
 }
@@ -208,6 +209,14 @@ func grantNormalizeApplication(application *accapi.GrantApplication) {
 	application.Status.Comments = util.NonNilSlice(application.Status.Comments)
 	application.Status.Revisions = util.NonNilSlice(application.Status.Revisions)
 	application.Status.ApplicationHistory = util.NonNilSlice(application.Status.ApplicationHistory)
+	application.Status.Reviews = util.NonNilSlice(application.Status.Reviews)
+
+	for i := range application.Status.Reviews {
+		review := &application.Status.Reviews[i].Review
+		if review.Present {
+			review.Value.Scores = util.NonNilSlice(review.Value.Scores)
+		}
+	}
 
 	for i := range application.Status.Revisions {
 		grantNormalizeRevision(&application.Status.Revisions[i])
@@ -247,6 +256,8 @@ type grantAuthType int
 const (
 	grantAuthReadWrite grantAuthType = iota // generic read/write action
 	grantAuthApprover                       // approver only action
+	grantAuthRead                           // read-only action, also allowed for reviewers
+	grantAuthReviewer                       // reviewer only action
 )
 
 type grantActorRole int
@@ -254,6 +265,7 @@ type grantActorRole int
 const (
 	grantActorRoleSubmitter grantActorRole = iota
 	grantActorRoleApprover
+	grantActorRoleReviewer
 )
 
 func grantGetUserBucket(username string) *grantUserBucket {
@@ -366,6 +378,14 @@ func grantsReadEx(actor rpc.Actor, action grantAuthType, b *grantAppBucket, id a
 			roles = append(roles, grantActorRoleSubmitter)
 		}
 
+		for _, assignment := range app.Application.Status.Reviews {
+			if assignment.Reviewer == actor.Username &&
+				actor.Membership[rpc.ProjectId(assignment.GrantGiver)].Satisfies(rpc.ProjectRoleUser) {
+				roles = append(roles, grantActorRoleReviewer)
+				break
+			}
+		}
+
 		//This is the case in gifts. Grant is createdBy the system, but the user should be able to open it as their own.
 		if recipient.Type == accapi.RecipientTypePersonalWorkspace && recipient.Username.Value == actor.Username {
 			roles = append(roles, grantActorRoleSubmitter)
@@ -378,13 +398,22 @@ func grantsReadEx(actor rpc.Actor, action grantAuthType, b *grantAppBucket, id a
 	roles = slices.Compact(roles)
 
 	switch action {
-	case grantAuthReadWrite:
+	case grantAuthRead:
 		// Nothing special
 
+	case grantAuthReadWrite:
+		// Reviewers can read the application but they cannot otherwise change it
+		roles = slices.DeleteFunc(roles, func(role grantActorRole) bool { return role == grantActorRoleReviewer })
+
 	case grantAuthApprover:
 		if !slices.Contains(roles, grantActorRoleApprover) {
 			app, roles = nil, nil
 		}
+
+	case grantAuthReviewer:
+		if !slices.Contains(roles, grantActorRoleReviewer) {
+			app, roles = nil, nil
+		}
 	}
 
 	if len(roles) == 0 {
@@ -564,6 +593,15 @@ func GrantsSubmitRevisionEx(actor rpc.Actor, req accapi.GrantsSubmitRevisionRequ
 		return 0, util.HttpErr(http.StatusBadRequest, "form type is invalid")
 	}
 
+	var call util.Option[accapi.GrantCall]
+	if revision.CallId.Present {
+		callInfo, err := grantCallPrepareRevision(revision)
+		if err != nil {
+			return 0, err
+		}
+		call.Set(callInfo)
+	}
+
 	period := revision.AllocationPeriod
 
 	requestsSeen := map[util.Tuple3[string, string, string]]util.Empty{}
@@ -739,6 +777,19 @@ func GrantsSubmitRevisionEx(actor rpc.Actor, req accapi.GrantsSubmitRevisionRequ
 		}
 	}
 
+	if err == nil && wasExistingApplication {
+		if app.Application.CurrentRevision.Document.CallId.GetOrDefault("") != revision.CallId.GetOrDefault("") {
+			err = util.HttpErr(http.StatusBadRequest, "the call of an application cannot be changed")
+		}
+	}
+
+	if err == nil && call.Present && !slices.Contains(roles, grantActorRoleApprover) {
+		// Approvers are allowed to adjust an application after the call has closed
+		if !grantCallIsOpen(call.Value, now) {
+			err = util.HttpErr(http.StatusBadRequest, "the call '%s' is not open for submissions", call.Value.Specification.Title)
+		}
+	}
+
 	if err == nil {
 		allocationStart := now
 		if period.Present && period.Value.Start.Present {
@@ -921,6 +972,10 @@ func GrantsSubmitRevisionEx(actor rpc.Actor, req accapi.GrantsSubmitRevisionRequ
 				idxB.Mu.Unlock()
 			}
 		}
+
+		if call.Present {
+			grantCallTrackApplication(call.Value.Id, id)
+		}
 	}
 
 	revToInsert := accapi.GrantRevision{
@@ -1021,6 +1076,10 @@ func GrantsTransfer(actor rpc.Actor, req accapi.GrantsTransferRequest) *util.Htt
 		}
 	}
 
+	if err == nil && app.Application.CurrentRevision.Document.CallId.Present {
+		err = util.HttpErr(http.StatusBadRequest, "applications submitted to a call cannot be transferred")
+	}
+
 	if err == nil {
 		// Locate the part that we are transferring and determine the new transfer set.
 
@@ -1162,6 +1221,18 @@ func GrantsUpdateState(actor rpc.Actor, req accapi.GrantsUpdateStateRequest) *ut
 		return util.HttpErr(http.StatusBadRequest, "you cannot update an application to be in progress")
 	}
 
+	var call util.Option[accapi.GrantCall]
+	var callApproved map[accapi.ProductCategoryIdV2]int64
+	if req.NewState == accapi.GrantApplicationStateApproved {
+		callInfo, approved, unlockBudget := grantCallLockBudget(actor, app)
+		defer unlockBudget()
+
+		if callInfo.Id != "" {
+			call.Set(callInfo)
+			callApproved = approved
+		}
+	}
+
 	app.Mu.Lock()
 
 	prevState := app.Application.Status.OverallState
@@ -1170,6 +1241,14 @@ func GrantsUpdateState(actor rpc.Actor, req accapi.GrantsUpdateStateRequest) *ut
 		err = util.HttpErr(http.StatusBadRequest, "application is no longer active")
 	}
 
+	if err == nil && req.NewState == accapi.GrantApplicationStateApproved {
+		err = lGrantsCheckReviewQuorum(app, string(actor.Project.Value))
+	}
+
+	if err == nil && call.Present && call.Value.GrantGiver == string(actor.Project.Value) {
+		err = lGrantCallCheckBudget(app, call.Value, callApproved)
+	}
+
 	if err == nil {
 		// Operation is now expected to succeed in most cases
 
@@ -1344,7 +1423,9 @@ func GrantsBrowse(actor rpc.Actor, req accapi.GrantsBrowseRequest) fndapi.PageV2
 	}
 	idxB.Mu.RUnlock()
 
-	if actor.Project.Present && actor.Membership[actor.Project.Value].Satisfies(rpc.ProjectRoleAdmin) {
+	// Members who are not administrators can only see the applications they have been assigned to review. This is
+	// checked by grantsRead below.
+	if actor.Project.Present && actor.Membership[actor.Project.Value].Satisfies(rpc.ProjectRoleUser) {
 		idxB = grantGetIdxBucket(string(actor.Project.Value), true)
 		idxB.Mu.RLock()
 		for _, id := range idxB.ApplicationsByEntity[string(actor.Project.Value)] {
@@ -1401,7 +1482,7 @@ func GrantsBrowse(actor rpc.Actor, req accapi.GrantsBrowseRequest) fndapi.PageV2
 			}
 		}
 
-		a, roles := grantsRead(actor, grantAuthReadWrite, id, ids)
+		a, roles := grantsRead(actor, grantAuthRead, id, ids)
 		if a != nil {
 			a.Mu.RLock()
 
@@ -1419,16 +1500,21 @@ func GrantsBrowse(actor rpc.Actor, req accapi.GrantsBrowseRequest) fndapi.PageV2
 				allowOutgoing := req.IncludeOutgoingApplications.GetOrDefault(false)
 				isSubmitter := slices.Contains(roles, grantActorRoleSubmitter) && lGrantApplicationIsSubmitterInActiveProjectNoAuth(actor, a)
 				isApprover := slices.Contains(roles, grantActorRoleApprover) && lGrantApplicationIsApproverInActiveProjectNoAuth(actor, a)
+				isReviewer := slices.Contains(roles, grantActorRoleReviewer) && lGrantApplicationIsReviewerInActiveProjectNoAuth(actor, a)
 
 				relevant = false
 
-				if allowIngoing && isApprover {
+				if allowIngoing && (isApprover || isReviewer) {
 					relevant = true
 				} else if allowOutgoing && isSubmitter {
 					relevant = true
 				}
 			}
 
+			if relevant && req.CallId.Present {
+				relevant = a.Application.CurrentRevision.Document.CallId.GetOrDefault("") == req.CallId.Value
+			}
+
 			if relevant {
 				if len(items) >= itemsPerPage {
 					hasMore = true
@@ -1551,7 +1637,7 @@ func GrantsRetrieve(actor rpc.Actor, id string) (accapi.GrantApplication, *util.
 	idRaw, _ := strconv.ParseInt(id, 10, 64)
 	idActual := accGrantId(idRaw)
 
-	app, roles := grantsRead(actor, grantAuthReadWrite, idActual, nil)
+	app, roles := grantsRead(actor, grantAuthRead, idActual, nil)
 
 	if app == nil {
 		result := accapi.GrantApplication{}
@@ -1807,6 +1893,10 @@ func GrantsUpdateSettings(actor rpc.Actor, id string, s accapi.GrantRequestSetti
 		}
 	}
 
+	if s.ReviewQuorum < 0 || s.ReviewQuorum > grantMaxReviewersPerGiver {
+		return util.HttpErr(http.StatusBadRequest, "review quorum must be between 0 and %d", grantMaxReviewersPerGiver)
+	}
+
 	b.Mu.Lock()
 	w, ok := b.Settings[id]
 	if !ok {
@@ -2234,6 +2324,7 @@ func initGrants() {
 
 	grantsLoadUnawarded()
 	grantsLoadSettings()
+	initGrantCalls()
 	go grantsAwardLoop()
 
 	if !grantGlobals.Testing.Enabled {
@@ -2350,6 +2441,7 @@ const (
 	grantEvApplicationRejected
 	grantEvApplicationSubmitted
 	grantEvRevisionSubmitted
+	grantEvReviewerAssigned
 )
 
 type grantEvent struct {
@@ -2357,17 +2449,84 @@ type grantEvent struct {
 	EventSourceIsApplicant bool
 	Actor                  rpc.Actor
 	Application            accapi.GrantApplication
+	Recipients             []string // overrides the recipients derived from EventSourceIsApplicant
 }
 
 func grantHandleEvent(event grantEvent) {
 	err1 := grantSendNotification(event)
 	err2 := grantSendEmail(event)
+	err3 := grantSendWebhooks(event)
 	if err1 != nil {
 		log.Warn("Failed to send notification: %s", err1)
 	}
 	if err2 != nil {
 		log.Warn("Failed to send email: %s", err2)
 	}
+	if err3 != nil {
+		log.Warn("Failed to emit webhook events: %s", err3)
+	}
+}
+
+// grantSendWebhooks emits webhook events to the applicant, the recipient project (if it already exists) and, for
+// submissions, to the grant givers.
+func grantSendWebhooks(event grantEvent) *util.HttpError {
+	if grantGlobals.Testing.Enabled {
+		return nil
+	}
+
+	var eventType fndapi.WebhookEventType
+	switch event.Type {
+	case grantEvApplicationSubmitted:
+		eventType = fndapi.WebhookEventGrantSubmitted
+	case grantEvGrantAwarded:
+		eventType = fndapi.WebhookEventGrantApproved
+	case grantEvApplicationRejected:
+		eventType = fndapi.WebhookEventGrantRejected
+	default:
+		return nil
+	}
+
+	// Webhooks are delivered to systems outside UCloud, these must never see the reviews
+	data, _ := json.Marshal(map[string]any{
+		"actor":       event.Actor.Username,
+		"application": grantWithoutReviews(event.Application),
+	})
+
+	app := &event.Application
+	events := []fndapi.WebhookEvent{
+		{
+			Type:     eventType,
+			Username: util.OptValue(app.CreatedBy),
+			Data:     data,
+		},
+	}
+
+	recipient := &app.CurrentRevision.Document.Recipient
+	if recipient.Type == accapi.RecipientTypeExistingProject && recipient.Id.Present {
+		events = append(events, fndapi.WebhookEvent{
+			Type:    eventType,
+			Project: util.OptValue(recipient.Id.Value),
+			Data:    data,
+		})
+	}
+
+	if event.Type == grantEvApplicationSubmitted {
+		grantGivers := map[string]util.Empty{}
+		for _, req := range app.CurrentRevision.Document.AllocationRequests {
+			grantGivers[req.GrantGiver] = util.Empty{}
+		}
+
+		for grantGiver := range grantGivers {
+			events = append(events, fndapi.WebhookEvent{
+				Type:    eventType,
+				Project: util.OptValue(grantGiver),
+				Data:    data,
+			})
+		}
+	}
+
+	_, err := fndapi.WebhooksEmit.Invoke(fndapi.BulkRequest[fndapi.WebhookEvent]{Items: events})
+	return err
 }
 
 func grantGetRecipientTitleFromEvent(event grantEvent) string {
@@ -2410,7 +2569,9 @@ func grantSendNotification(event grantEvent) *util.HttpError {
 
 	var recipients []string
 
-	if event.EventSourceIsApplicant {
+	if len(event.Recipients) > 0 {
+		recipients = event.Recipients
+	} else if event.EventSourceIsApplicant {
 		app := &event.Application
 		reqs := app.CurrentRevision.Document.AllocationRequests
 		reviewerSet := map[string]util.Empty{}
@@ -2471,6 +2632,11 @@ func grantSendNotification(event grantEvent) *util.HttpError {
 			meta["title"] = fmt.Sprintf("Application updated: \"%s\"", truncateRecipientTitle(event))
 			meta["avatar"] = event.Actor.Username
 			// TODO make grantEv for grantTransfer and insert here
+		case grantEvReviewerAssigned:
+			notification.Type = "GRANT_REVIEW_ASSIGNED"
+			notification.Message = fmt.Sprintf("You have been asked by %s to review \"%s\"", event.Actor.Username, truncateRecipientTitle(event))
+			meta["title"] = "New grant application to review"
+			meta["avatar"] = event.Actor.Username
 		}
 
 		metaJson, _ := json.Marshal(meta)
@@ -2493,6 +2659,11 @@ func grantSendEmail(event grantEvent) *util.HttpError {
 		return nil
 	}
 
+	if event.Type == grantEvReviewerAssigned {
+		// There is no mail template for review assignments, reviewers are only notified through notifications.
+		return nil
+	}
+
 	var recipients []string
 
 	if event.EventSourceIsApplicant {
@@ -0,0 +1,804 @@
+package accounting
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	accapi "ucloud.dk/shared/pkg/accounting"
+	db "ucloud.dk/shared/pkg/database"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Calls
+// =====================================================================================================================
+// A call is a round of applications run by a grant giver, for example "Spring 2027". Applications submitted to a call
+// can only be submitted while the call is open and they can only request resources from the grant giver running the
+// call. A call can optionally override the templates of the grant giver, put a cap on the total amount approved per
+// product category and force a fixed allocation period on all of its applications.
+//
+// All calls are kept in memory. Calls are few and small compared to applications, which is why they do not use the
+// bucket system used by the rest of the grant system.
+//
+// Mutex lock order: grantCall.BudgetMu -> grantApplication -> grantCallGlobals.Mu
+
+type grantCallId int64
+
+var grantCallGlobals struct {
+	Mu    sync.RWMutex
+	IdAcc atomic.Int64
+	Calls map[grantCallId]*grantCall
+}
+
+type grantCall struct {
+	// BudgetMu is held while an application in the call is being approved. This ensures that concurrent approvals
+	// cannot exceed the budget caps of the call.
+	BudgetMu sync.Mutex
+
+	// The remaining fields are protected by grantCallGlobals.Mu
+	Call         accapi.GrantCall
+	Applications []accGrantId
+}
+
+func grantCallParseId(id string) grantCallId {
+	parsed, _ := strconv.ParseInt(id, 10, 64)
+	return grantCallId(parsed)
+}
+
+// grantCallRetrieve returns a copy of a call. The returned pointer can be used to access the BudgetMu of the call.
+func grantCallRetrieve(id string) (*grantCall, accapi.GrantCall, bool) {
+	grantCallGlobals.Mu.RLock()
+	defer grantCallGlobals.Mu.RUnlock()
+
+	call, ok := grantCallGlobals.Calls[grantCallParseId(id)]
+	if !ok {
+		return nil, accapi.GrantCall{}, false
+	}
+	return call, grantCallCopy(call.Call), true
+}
+
+func grantCallCopy(call accapi.GrantCall) accapi.GrantCall {
+	result := call
+	result.Specification.BudgetCaps = slices.Clone(call.Specification.BudgetCaps)
+	if call.Specification.Templates.Present {
+		templates := call.Specification.Templates.Value
+		templates.Structured.PersonalProject = slices.Clone(templates.Structured.PersonalProject)
+		templates.Structured.NewProject = slices.Clone(templates.Structured.NewProject)
+		templates.Structured.ExistingProject = slices.Clone(templates.Structured.ExistingProject)
+		result.Specification.Templates.Set(templates)
+	}
+	return result
+}
+
+func grantCallIsOpen(call accapi.GrantCall, now time.Time) bool {
+	spec := &call.Specification
+	return !now.Before(spec.OpensAt.Time()) && now.Before(spec.ClosesAt.Time())
+}
+
+func grantCallBudgetCap(call accapi.GrantCall, category accapi.ProductCategoryIdV2) util.Option[int64] {
+	for _, budget := range call.Specification.BudgetCaps {
+		if budget.Category == category {
+			return util.OptValue(budget.Cap)
+		}
+	}
+	return util.OptNone[int64]()
+}
+
+func grantCallCanView(actor rpc.Actor, call accapi.GrantCall, now time.Time) bool {
+	if actor.Membership[rpc.ProjectId(call.GrantGiver)].Satisfies(rpc.ProjectRoleAdmin) {
+		return true
+	}
+
+	if !now.Before(call.Specification.ClosesAt.Time()) {
+		return false
+	}
+
+	settings, ok := grantsRetrieveSettings(call.GrantGiver)
+	if !ok {
+		return false
+	}
+
+	settings.Mu.RLock()
+	enabled := settings.Settings != nil && settings.Settings.Enabled
+	settings.Mu.RUnlock()
+	return enabled
+}
+
+// grantCallTemplates returns the templates which apply to an application. These are the templates of the call, if the
+// call has any, otherwise the templates of the grant giver.
+func grantCallTemplates(grantGiver string, callId util.Option[string]) (accapi.Templates, bool) {
+	if callId.Present {
+		_, call, ok := grantCallRetrieve(callId.Value)
+		if ok && call.GrantGiver == grantGiver && call.Specification.Templates.Present {
+			return call.Specification.Templates.Value, true
+		}
+	}
+
+	settings, ok := grantsRetrieveSettings(grantGiver)
+	if !ok {
+		return accapi.Templates{}, false
+	}
+
+	settings.Mu.RLock()
+	defer settings.Mu.RUnlock()
+
+	if settings.Settings == nil {
+		return accapi.Templates{}, false
+	}
+	return settings.Settings.Templates, true
+}
+
+// Public API
+// =====================================================================================================================
+
+func GrantCallsBrowse(actor rpc.Actor, req accapi.GrantCallsBrowseRequest) fndapi.PageV2[accapi.GrantCall] {
+	now := time.Now()
+	includeClosed := req.IncludeClosed.GetOrDefault(false)
+
+	var candidates []accapi.GrantCall
+	grantCallGlobals.Mu.RLock()
+	for _, call := range grantCallGlobals.Calls {
+		if req.GrantGiver.Present && call.Call.GrantGiver != req.GrantGiver.Value {
+			continue
+		}
+
+		if !includeClosed && !now.Before(call.Call.Specification.ClosesAt.Time()) {
+			continue
+		}
+
+		candidates = append(candidates, grantCallCopy(call.Call))
+	}
+	grantCallGlobals.Mu.RUnlock()
+
+	var result []accapi.GrantCall
+	for _, call := range candidates {
+		if grantCallCanView(actor, call, now) {
+			call.Status.IsOpen = grantCallIsOpen(call, now)
+			result = append(result, call)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b accapi.GrantCall) int {
+		if c := b.Specification.OpensAt.Time().Compare(a.Specification.OpensAt.Time()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+
+	return fndapi.PageV2[accapi.GrantCall]{
+		Items:        util.NonNilSlice(result),
+		ItemsPerPage: len(result),
+	}
+}
+
+func GrantCallsRetrieve(actor rpc.Actor, id string) (accapi.GrantCall, *util.HttpError) {
+	now := time.Now()
+	_, call, ok := grantCallRetrieve(id)
+	if !ok || !grantCallCanView(actor, call, now) {
+		return accapi.GrantCall{}, util.HttpErr(http.StatusNotFound, "unknown call")
+	}
+
+	call.Status.IsOpen = grantCallIsOpen(call, now)
+	return call, nil
+}
+
+func GrantCallsCreate(actor rpc.Actor, spec accapi.GrantCallSpecification) (string, *util.HttpError) {
+	if !actor.Project.Present || !actor.Membership[actor.Project.Value].Satisfies(rpc.ProjectRoleAdmin) {
+		return "", util.HttpErr(http.StatusForbidden, "you cannot create a call from this project")
+	}
+
+	if err := grantCallValidateSpecification(&spec); err != nil {
+		return "", err
+	}
+
+	id := grantCallId(grantCallGlobals.IdAcc.Add(1))
+	call := accapi.GrantCall{
+		Id:            fmt.Sprint(id),
+		GrantGiver:    string(actor.Project.Value),
+		CreatedBy:     actor.Username,
+		CreatedAt:     fndapi.Timestamp(time.Now()),
+		Specification: spec,
+	}
+
+	grantCallGlobals.Mu.Lock()
+	grantCallGlobals.Calls[id] = &grantCall{Call: call}
+	grantCallPersist(call)
+	grantCallGlobals.Mu.Unlock()
+
+	return call.Id, nil
+}
+
+func GrantCallsUpdate(actor rpc.Actor, req accapi.GrantCallsUpdateRequest) *util.HttpError {
+	if err := grantCallValidateSpecification(&req.Specification); err != nil {
+		return err
+	}
+
+	grantCallGlobals.Mu.Lock()
+	defer grantCallGlobals.Mu.Unlock()
+
+	call, ok := grantCallGlobals.Calls[grantCallParseId(req.Id)]
+	if !ok || !actor.Membership[rpc.ProjectId(call.Call.GrantGiver)].Satisfies(rpc.ProjectRoleAdmin) {
+		return util.HttpErr(http.StatusNotFound, "unknown call")
+	}
+
+	call.Call.Specification = req.Specification
+	grantCallPersist(call.Call)
+	return nil
+}
+
+func GrantCallsDelete(actor rpc.Actor, id string) *util.HttpError {
+	grantCallGlobals.Mu.Lock()
+	defer grantCallGlobals.Mu.Unlock()
+
+	callId := grantCallParseId(id)
+	call, ok := grantCallGlobals.Calls[callId]
+	if !ok || !actor.Membership[rpc.ProjectId(call.Call.GrantGiver)].Satisfies(rpc.ProjectRoleAdmin) {
+		return util.HttpErr(http.StatusNotFound, "unknown call")
+	}
+
+	if len(call.Applications) > 0 {
+		return util.HttpErr(http.StatusBadRequest, "a call cannot be deleted once applications have been submitted to it")
+	}
+
+	delete(grantCallGlobals.Calls, callId)
+	grantCallPersistDeletion(callId)
+	return nil
+}
+
+func GrantCallsRetrieveSummary(actor rpc.Actor, id string) (accapi.GrantCallSummary, *util.HttpError) {
+	now := time.Now()
+
+	grantCallGlobals.Mu.RLock()
+	internalCall, ok := grantCallGlobals.Calls[grantCallParseId(id)]
+	var call accapi.GrantCall
+	var appIds []accGrantId
+	if ok {
+		call = grantCallCopy(internalCall.Call)
+		appIds = slices.Clone(internalCall.Applications)
+	}
+	grantCallGlobals.Mu.RUnlock()
+
+	if !ok || !actor.Membership[rpc.ProjectId(call.GrantGiver)].Satisfies(rpc.ProjectRoleAdmin) {
+		return accapi.GrantCallSummary{}, util.HttpErr(http.StatusNotFound, "unknown call")
+	}
+
+	requested := map[accapi.ProductCategoryIdV2]int64{}
+	approved := map[accapi.ProductCategoryIdV2]int64{}
+	applications := 0
+
+	for _, appId := range appIds {
+		app, _ := grantsRead(actor, grantAuthApprover, appId, appIds)
+		if app == nil {
+			continue
+		}
+
+		app.Mu.RLock()
+		state := app.Application.Status.OverallState
+		if state == accapi.GrantApplicationStateInProgress || state == accapi.GrantApplicationStateApproved {
+			applications++
+			for category, amount := range lGrantCallRequestedResources(app, call.GrantGiver) {
+				requested[category] += amount
+				if state == accapi.GrantApplicationStateApproved {
+					approved[category] += amount
+				}
+			}
+		}
+		app.Mu.RUnlock()
+	}
+
+	var categories []accapi.ProductCategoryIdV2
+	for _, budget := range call.Specification.BudgetCaps {
+		categories = append(categories, budget.Category)
+	}
+	for category := range requested {
+		if !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+
+	slices.SortFunc(categories, func(a, b accapi.ProductCategoryIdV2) int {
+		if c := strings.Compare(a.Provider, b.Provider); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	var summaries []accapi.GrantCallCategorySummary
+	for _, category := range categories {
+		summary := accapi.GrantCallCategorySummary{
+			Category:  category,
+			Requested: requested[category],
+			Approved:  approved[category],
+		}
+
+		if budget := grantCallBudgetCap(call, category); budget.Present {
+			summary.Available = budget.Value
+			summary.HasCap = true
+		} else if cat, err := ProductCategoryRetrieve(rpc.ActorSystem, category.Name, category.Provider); err == nil {
+			aBucket := internalBucketOrInit(cat)
+			w := internalWalletByOwner(aBucket, now, internalOwnerByReference(call.GrantGiver).Id)
+			summary.Available, _ = internalWalletTotalQuotaContributingAt(aBucket, w, now)
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	call.Status.IsOpen = grantCallIsOpen(call, now)
+	return accapi.GrantCallSummary{
+		Call:         call,
+		Applications: applications,
+		Categories:   util.NonNilSlice(summaries),
+	}, nil
+}
+
+// Integration with applications
+// =====================================================================================================================
+
+func grantCallValidateSpecification(spec *accapi.GrantCallSpecification) *util.HttpError {
+	var err *util.HttpError
+	util.ValidateString(&spec.Title, "title", 0, &err)
+	if err != nil {
+		return err
+	}
+
+	if len(spec.Description) > 1024*64 {
+		return util.HttpErr(http.StatusBadRequest, "description is too long")
+	}
+
+	if !spec.OpensAt.Time().Before(spec.ClosesAt.Time()) {
+		return util.HttpErr(http.StatusBadRequest, "a call must open before it closes")
+	}
+
+	seen := map[accapi.ProductCategoryIdV2]util.Empty{}
+	for _, budget := range spec.BudgetCaps {
+		if budget.Category.Name == "" || budget.Category.Provider == "" {
+			return util.HttpErr(http.StatusBadRequest, "budget cap is missing a product category")
+		}
+
+		if budget.Cap < 0 {
+			return util.HttpErr(http.StatusBadRequest, "budget cap cannot be negative")
+		}
+
+		if _, duplicate := seen[budget.Category]; duplicate {
+			return util.HttpErr(http.StatusBadRequest, "duplicate budget cap for %s/%s",
+				budget.Category.Name, budget.Category.Provider)
+		}
+		seen[budget.Category] = util.Empty{}
+	}
+	spec.BudgetCaps = util.NonNilSlice(spec.BudgetCaps)
+
+	if spec.AllocationPeriod.Present {
+		period := spec.AllocationPeriod.Value
+		if !period.End.Present {
+			return util.HttpErr(http.StatusBadRequest, "the allocation period of a call must have an end-date")
+		}
+
+		if period.Start.Present && !period.Start.Value.Time().Before(period.End.Value.Time()) {
+			return util.HttpErr(http.StatusBadRequest, "the allocation period of the call is invalid")
+		}
+	}
+
+	if spec.Templates.Present {
+		grantNormalizeTemplates(&spec.Templates.Value)
+	}
+
+	return nil
+}
+
+// grantCallPrepareRevision validates a revision which is submitted to a call. If the call has a fixed allocation period,
+// then the period is applied to the revision. Whether the call is open is checked later, since approvers are allowed
+// to change an application after the call has closed.
+func grantCallPrepareRevision(revision *accapi.GrantDocument) (accapi.GrantCall, *util.HttpError) {
+	_, call, ok := grantCallRetrieve(revision.CallId.Value)
+	if !ok {
+		return accapi.GrantCall{}, util.HttpErr(http.StatusNotFound, "unknown call")
+	}
+
+	if revision.Form.Type == accapi.FormTypeGrantGiverInitiated {
+		return accapi.GrantCall{}, util.HttpErr(http.StatusBadRequest, "grant giver initiated applications cannot be part of a call")
+	}
+
+	for _, allocReq := range revision.AllocationRequests {
+		if allocReq.GrantGiver != call.GrantGiver {
+			return accapi.GrantCall{}, util.HttpErr(
+				http.StatusBadRequest,
+				"applications in this call can only request resources from %s",
+				call.GrantGiver,
+			)
+		}
+
+		budget := grantCallBudgetCap(call, accapi.ProductCategoryIdV2{Name: allocReq.Category, Provider: allocReq.Provider})
+		if budget.Present && allocReq.BalanceRequested.GetOrDefault(0) > budget.Value {
+			return accapi.GrantCall{}, util.HttpErr(
+				http.StatusBadRequest,
+				"the request for %s/%s exceeds the budget of the call",
+				allocReq.Category,
+				allocReq.Provider,
+			)
+		}
+	}
+
+	if call.Specification.AllocationPeriod.Present {
+		period := call.Specification.AllocationPeriod.Value
+		revision.AllocationPeriod.Set(period)
+		for i := range revision.AllocationRequests {
+			revision.AllocationRequests[i].Period = period
+		}
+	}
+
+	return call, nil
+}
+
+func grantCallTrackApplication(callId string, appId accGrantId) {
+	grantCallGlobals.Mu.Lock()
+	call, ok := grantCallGlobals.Calls[grantCallParseId(callId)]
+	if ok {
+		call.Applications = append(call.Applications, appId)
+	}
+	grantCallGlobals.Mu.Unlock()
+}
+
+// lGrantCallRequestedResources returns the resources requested from the grant giver in the current revision of the
+// application. The application must be locked by the caller.
+func lGrantCallRequestedResources(app *grantApplication, grantGiver string) map[accapi.ProductCategoryIdV2]int64 {
+	result := map[accapi.ProductCategoryIdV2]int64{}
+	for _, allocReq := range app.Application.CurrentRevision.Document.AllocationRequests {
+		if allocReq.GrantGiver == grantGiver {
+			category := accapi.ProductCategoryIdV2{Name: allocReq.Category, Provider: allocReq.Provider}
+			result[category] += allocReq.BalanceRequested.GetOrDefault(0)
+		}
+	}
+	return result
+}
+
+// grantCallLockBudget locks the budget of the call which the application belongs to and returns the resources
+// already approved in the call, not counting the application itself. The returned function must be called to release
+// the budget. This function must be called before the application is locked.
+func grantCallLockBudget(actor rpc.Actor, app *grantApplication) (accapi.GrantCall, map[accapi.ProductCategoryIdV2]int64, func()) {
+	app.Mu.RLock()
+	callId := app.Application.CurrentRevision.Document.CallId
+	appId := app.lId()
+	app.Mu.RUnlock()
+
+	if !callId.Present {
+		return accapi.GrantCall{}, nil, func() {}
+	}
+
+	internalCall, call, ok := grantCallRetrieve(callId.Value)
+	if !ok {
+		return accapi.GrantCall{}, nil, func() {}
+	}
+
+	internalCall.BudgetMu.Lock()
+
+	grantCallGlobals.Mu.RLock()
+	appIds := slices.Clone(internalCall.Applications)
+	grantCallGlobals.Mu.RUnlock()
+
+	approved := map[accapi.ProductCategoryIdV2]int64{}
+	for _, otherId := range appIds {
+		if otherId == appId {
+			continue
+		}
+
+		other, _ := grantsRead(actor, grantAuthApprover, otherId, appIds)
+		if other == nil {
+			continue
+		}
+
+		other.Mu.RLock()
+		if other.Application.Status.OverallState == accapi.GrantApplicationStateApproved {
+			for category, amount := range lGrantCallRequestedResources(other, call.GrantGiver) {
+				approved[category] += amount
+			}
+		}
+		other.Mu.RUnlock()
+	}
+
+	return call, approved, internalCall.BudgetMu.Unlock
+}
+
+// lGrantCallCheckBudget returns an error if approving the application would exceed the budget caps of the call. The
+// application must be locked by the caller.
+func lGrantCallCheckBudget(app *grantApplication, call accapi.GrantCall, approved map[accapi.ProductCategoryIdV2]int64) *util.HttpError {
+	for category, amount := range lGrantCallRequestedResources(app, call.GrantGiver) {
+		budget := grantCallBudgetCap(call, category)
+		if budget.Present && approved[category]+amount > budget.Value {
+			return util.HttpErr(
+				http.StatusBadRequest,
+				"approving this application would exceed the budget of the call for %s/%s (%d of %d already approved)",
+				category.Name,
+				category.Provider,
+				approved[category],
+				budget.Value,
+			)
+		}
+	}
+	return nil
+}
+
+// Initialization and persistence
+// =====================================================================================================================
+
+func initGrantCalls() {
+	grantCallGlobals.Mu.Lock()
+	grantCallGlobals.Calls = map[grantCallId]*grantCall{}
+	grantCallGlobals.Mu.Unlock()
+
+	if grantGlobals.Testing.Enabled {
+		return
+	}
+
+	grantCallsLoad()
+
+	accapi.GrantCallsBrowse.Handler(func(info rpc.RequestInfo, request accapi.GrantCallsBrowseRequest) (fndapi.PageV2[accapi.GrantCall], *util.HttpError) {
+		return GrantCallsBrowse(info.Actor, request), nil
+	})
+
+	accapi.GrantCallsRetrieve.Handler(func(info rpc.RequestInfo, request fndapi.FindByStringId) (accapi.GrantCall, *util.HttpError) {
+		return GrantCallsRetrieve(info.Actor, request.Id)
+	})
+
+	accapi.GrantCallsCreate.Handler(func(info rpc.RequestInfo, request accapi.GrantCallSpecification) (fndapi.FindByStringId, *util.HttpError) {
+		id, err := GrantCallsCreate(info.Actor, request)
+		return fndapi.FindByStringId{Id: id}, err
+	})
+
+	accapi.GrantCallsUpdate.Handler(func(info rpc.RequestInfo, request accapi.GrantCallsUpdateRequest) (util.Empty, *util.HttpError) {
+		return util.Empty{}, GrantCallsUpdate(info.Actor, request)
+	})
+
+	accapi.GrantCallsDelete.Handler(func(info rpc.RequestInfo, request fndapi.FindByStringId) (util.Empty, *util.HttpError) {
+		return util.Empty{}, GrantCallsDelete(info.Actor, request.Id)
+	})
+
+	accapi.GrantCallsRetrieveSummary.Handler(func(info rpc.RequestInfo, request fndapi.FindByStringId) (accapi.GrantCallSummary, *util.HttpError) {
+		return GrantCallsRetrieveSummary(info.Actor, request.Id)
+	})
+}
+
+func grantCallsLoad() {
+	db.NewTx0(func(tx *db.Transaction) {
+		rows := db.Select[struct {
+			Id              int64
+			GrantGiver      string
+			CreatedBy       string
+			CreatedAt       time.Time
+			Title           string
+			Description     string
+			OpensAt         time.Time
+			ClosesAt        time.Time
+			Templates       sql.Null[string]
+			AllocationStart sql.Null[time.Time]
+			AllocationEnd   sql.Null[time.Time]
+		}](
+			tx,
+			`
+				select
+					id, grant_giver, created_by, created_at, title, description, opens_at, closes_at,
+					cast(templates as text) as templates, allocation_start, allocation_end
+				from "grant".calls
+		    `,
+			db.Params{},
+		)
+
+		budgetRows := db.Select[struct {
+			CallId   int64
+			Category string
+			Provider string
+			Cap      int64
+		}](
+			tx,
+			`
+				select call_id, category, provider, cap
+				from "grant".call_budget_caps
+				order by call_id, provider, category
+		    `,
+			db.Params{},
+		)
+
+		appRows := db.Select[struct {
+			Id     int64
+			CallId int64
+		}](
+			tx,
+			`
+				select id, call_id
+				from "grant".applications
+				where call_id is not null
+				order by id
+		    `,
+			db.Params{},
+		)
+
+		maxId := int64(0)
+		calls := map[grantCallId]*grantCall{}
+		for _, row := range rows {
+			maxId = max(maxId, row.Id)
+
+			spec := accapi.GrantCallSpecification{
+				Title:       row.Title,
+				Description: row.Description,
+				OpensAt:     fndapi.Timestamp(row.OpensAt),
+				ClosesAt:    fndapi.Timestamp(row.ClosesAt),
+				BudgetCaps:  []accapi.GrantCallBudgetCap{},
+			}
+
+			if row.Templates.Valid {
+				var templates accapi.Templates
+				if err := json.Unmarshal([]byte(row.Templates.V), &templates); err != nil {
+					log.Warn("Failed to parse templates of grant call %v: %s", row.Id, err)
+				} else {
+					grantNormalizeTemplates(&templates)
+					spec.Templates.Set(templates)
+				}
+			}
+
+			if row.AllocationEnd.Valid {
+				period := accapi.Period{End: util.OptValue(fndapi.Timestamp(row.AllocationEnd.V))}
+				if row.AllocationStart.Valid {
+					period.Start.Set(fndapi.Timestamp(row.AllocationStart.V))
+				}
+				spec.AllocationPeriod.Set(period)
+			}
+
+			calls[grantCallId(row.Id)] = &grantCall{
+				Call: accapi.GrantCall{
+					Id:            fmt.Sprint(row.Id),
+					GrantGiver:    row.GrantGiver,
+					CreatedBy:     row.CreatedBy,
+					CreatedAt:     fndapi.Timestamp(row.CreatedAt),
+					Specification: spec,
+				},
+			}
+		}
+
+		for _, row := range budgetRows {
+			call, ok := calls[grantCallId(row.CallId)]
+			if ok {
+				call.Call.Specification.BudgetCaps = append(call.Call.Specification.BudgetCaps, accapi.GrantCallBudgetCap{
+					Category: accapi.ProductCategoryIdV2{Name: row.Category, Provider: row.Provider},
+					Cap:      row.Cap,
+				})
+			}
+		}
+
+		for _, row := range appRows {
+			call, ok := calls[grantCallId(row.CallId)]
+			if ok {
+				call.Applications = append(call.Applications, accGrantId(row.Id))
+			}
+		}
+
+		grantCallGlobals.IdAcc.Store(maxId)
+		grantCallGlobals.Mu.Lock()
+		grantCallGlobals.Calls = calls
+		grantCallGlobals.Mu.Unlock()
+	})
+}
+
+func grantCallPersist(call accapi.GrantCall) {
+	if grantGlobals.Testing.Enabled {
+		return
+	}
+
+	spec := &call.Specification
+
+	templates := sql.Null[string]{}
+	if spec.Templates.Present {
+		data, _ := json.Marshal(spec.Templates.Value)
+		templates.V = string(data)
+		templates.Valid = true
+	}
+
+	allocationStart := sql.Null[time.Time]{}
+	allocationEnd := sql.Null[time.Time]{}
+	if spec.AllocationPeriod.Present {
+		period := spec.AllocationPeriod.Value
+		if period.Start.Present {
+			allocationStart = sql.Null[time.Time]{V: period.Start.Value.Time(), Valid: true}
+		}
+		if period.End.Present {
+			allocationEnd = sql.Null[time.Time]{V: period.End.Value.Time(), Valid: true}
+		}
+	}
+
+	var budgetCategories []string
+	var budgetProviders []string
+	var budgetCaps []int64
+	for _, budget := range spec.BudgetCaps {
+		budgetCategories = append(budgetCategories, budget.Category.Name)
+		budgetProviders = append(budgetProviders, budget.Category.Provider)
+		budgetCaps = append(budgetCaps, budget.Cap)
+	}
+
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				insert into "grant".calls(id, grant_giver, created_by, created_at, title, description, opens_at,
+					closes_at, templates, allocation_start, allocation_end)
+				values (:id, :grant_giver, :created_by, :created_at, :title, :description, :opens_at, :closes_at,
+					cast(:templates as jsonb), :allocation_start, :allocation_end)
+				on conflict (id) do update set
+					title = excluded.title,
+					description = excluded.description,
+					opens_at = excluded.opens_at,
+					closes_at = excluded.closes_at,
+					templates = excluded.templates,
+					allocation_start = excluded.allocation_start,
+					allocation_end = excluded.allocation_end
+		    `,
+			db.Params{
+				"id":               int64(grantCallParseId(call.Id)),
+				"grant_giver":      call.GrantGiver,
+				"created_by":       call.CreatedBy,
+				"created_at":       call.CreatedAt.Time(),
+				"title":            spec.Title,
+				"description":      spec.Description,
+				"opens_at":         spec.OpensAt.Time(),
+				"closes_at":        spec.ClosesAt.Time(),
+				"templates":        templates,
+				"allocation_start": allocationStart,
+				"allocation_end":   allocationEnd,
+			},
+		)
+
+		db.Exec(
+			tx,
+			`
+				delete from "grant".call_budget_caps
+				where call_id = :id
+		    `,
+			db.Params{
+				"id": int64(grantCallParseId(call.Id)),
+			},
+		)
+
+		if len(budgetCaps) > 0 {
+			db.Exec(
+				tx,
+				`
+					insert into "grant".call_budget_caps(call_id, category, provider, cap)
+					select :id, unnest(cast(:categories as text[])), unnest(cast(:providers as text[])),
+						unnest(cast(:caps as int8[]))
+			    `,
+				db.Params{
+					"id":         int64(grantCallParseId(call.Id)),
+					"categories": budgetCategories,
+					"providers":  budgetProviders,
+					"caps":       budgetCaps,
+				},
+			)
+		}
+	})
+}
+
+func grantCallPersistDeletion(id grantCallId) {
+	if grantGlobals.Testing.Enabled {
+		return
+	}
+
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				delete from "grant".calls
+				where id = :id
+		    `,
+			db.Params{
+				"id": int64(id),
+			},
+		)
+	})
+}
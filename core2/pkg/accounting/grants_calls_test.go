@@ -0,0 +1,214 @@
+package accounting
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	accapi "ucloud.dk/shared/pkg/accounting"
+	"ucloud.dk/shared/pkg/assert"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/util"
+)
+
+func callSpec(opensAt, closesAt time.Time) accapi.GrantCallSpecification {
+	return accapi.GrantCallSpecification{
+		Title:    "Spring call",
+		OpensAt:  fndapi.Timestamp(opensAt),
+		ClosesAt: fndapi.Timestamp(closesAt),
+	}
+}
+
+func openCallSpec() accapi.GrantCallSpecification {
+	return callSpec(time.Now().Add(-1*time.Hour), time.Now().Add(24*time.Hour))
+}
+
+func revInCall(user, giver, callId string, quota int64) accapi.GrantsSubmitRevisionRequest {
+	req := rev(user, giver, quota)
+	req.Revision.CallId.Set(callId)
+	return req
+}
+
+func TestCallSubmissionWindow(t *testing.T) {
+	initGrantsTest(t)
+	const giver = "call-g1"
+	addGrantGiver(t, giver)
+	applicant := actor("applicant", "")
+	admin := actor("admin", giver)
+
+	openId, err := GrantCallsCreate(*admin, openCallSpec())
+	assert.Nil(t, err)
+	upcomingId, err := GrantCallsCreate(*admin, callSpec(time.Now().Add(time.Hour), time.Now().Add(2*time.Hour)))
+	assert.Nil(t, err)
+	closedId, err := GrantCallsCreate(*admin, callSpec(time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)))
+	assert.Nil(t, err)
+
+	_, err = GrantsSubmitRevision(*applicant, revInCall(applicant.Username, giver, upcomingId, 10))
+	assert.NotNil(t, err)
+	_, err = GrantsSubmitRevision(*applicant, revInCall(applicant.Username, giver, closedId, 10))
+	assert.NotNil(t, err)
+	_, err = GrantsSubmitRevision(*applicant, revInCall(applicant.Username, giver, "9999", 10))
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	id64, err := GrantsSubmitRevision(*applicant, revInCall(applicant.Username, giver, openId, 10))
+	assert.Nil(t, err)
+	appId := strconv.FormatInt(id64, 10)
+
+	// The call of an application cannot be changed by a later revision
+	req := rev(applicant.Username, giver, 20)
+	req.ApplicationId.Set(appId)
+	_, err = GrantsSubmitRevision(*applicant, req)
+	assert.NotNil(t, err)
+
+	req = revInCall(applicant.Username, giver, openId, 20)
+	req.ApplicationId.Set(appId)
+	_, err = GrantsSubmitRevision(*applicant, req)
+	assert.Nil(t, err)
+
+	app, err := GrantsRetrieve(*applicant, appId)
+	assert.Nil(t, err)
+	assert.Equal(t, openId, app.CurrentRevision.Document.CallId.Value)
+
+	// Applications to a call cannot be sent elsewhere
+	err = GrantsTransfer(*admin, accapi.GrantsTransferRequest{ApplicationId: appId, Target: "call-other"})
+	assert.NotNil(t, err)
+
+	err = GrantCallsDelete(*admin, openId)
+	assert.NotNil(t, err)
+	assert.Nil(t, GrantCallsDelete(*admin, upcomingId))
+}
+
+func TestCallRequiresGrantGiverOfCall(t *testing.T) {
+	initGrantsTest(t)
+	g1, g2 := "call-g2a", "call-g2b"
+	addGrantGiver(t, g1)
+	addGrantGiver(t, g2)
+	applicant := actor("applicant", "")
+	admin := actor("admin", g1)
+
+	callId, err := GrantCallsCreate(*admin, openCallSpec())
+	assert.Nil(t, err)
+
+	_, err = GrantsSubmitRevision(*applicant, revInCall(applicant.Username, g2, callId, 10))
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+}
+
+func TestCallFixedAllocationPeriod(t *testing.T) {
+	initGrantsTest(t)
+	const giver = "call-g3"
+	addGrantGiver(t, giver)
+	applicant := actor("applicant", "")
+	admin := actor("admin", giver)
+
+	start := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	end := time.Now().AddDate(0, 6, 0).Truncate(time.Millisecond)
+	spec := openCallSpec()
+	spec.AllocationPeriod.Set(accapi.Period{
+		Start: util.OptValue(fndapi.Timestamp(start)),
+		End:   util.OptValue(fndapi.Timestamp(end)),
+	})
+
+	callId, err := GrantCallsCreate(*admin, spec)
+	assert.Nil(t, err)
+
+	id64, err := GrantsSubmitRevision(*applicant, revInCall(applicant.Username, giver, callId, 10))
+	assert.Nil(t, err)
+
+	app, _ := GrantsRetrieve(*applicant, strconv.FormatInt(id64, 10))
+	doc := app.CurrentRevision.Document
+	assert.True(t, doc.AllocationPeriod.Value.End.Value.Time().Equal(end))
+	assert.True(t, doc.AllocationRequests[0].Period.Start.Value.Time().Equal(start))
+}
+
+func TestCallBudgetCapAndSummary(t *testing.T) {
+	initGrantsTest(t)
+	const giver = "call-g4"
+	addGrantGiver(t, giver)
+	alice := actor("alice", "")
+	bob := actor("bob", "")
+	admin := actor("admin", giver)
+
+	spec := openCallSpec()
+	spec.BudgetCaps = []accapi.GrantCallBudgetCap{
+		{Category: accapi.ProductCategoryIdV2{Name: cpuCategory.Name, Provider: cpuCategory.Provider}, Cap: 100},
+	}
+	callId, err := GrantCallsCreate(*admin, spec)
+	assert.Nil(t, err)
+
+	_, err = GrantsSubmitRevision(*alice, revInCall(alice.Username, giver, callId, 101))
+	assert.NotNil(t, err)
+
+	aliceId, err := GrantsSubmitRevision(*alice, revInCall(alice.Username, giver, callId, 70))
+	assert.Nil(t, err)
+	bobId, err := GrantsSubmitRevision(*bob, revInCall(bob.Username, giver, callId, 40))
+	assert.Nil(t, err)
+	_, err = GrantsSubmitRevision(*bob, rev(bob.Username, giver, 1000))
+	assert.Nil(t, err)
+
+	approve := func(id int64) *util.HttpError {
+		return GrantsUpdateState(*admin, accapi.GrantsUpdateStateRequest{
+			ApplicationId: strconv.FormatInt(id, 10),
+			NewState:      accapi.GrantApplicationStateApproved,
+		})
+	}
+
+	assert.Nil(t, approve(aliceId))
+	err = approve(bobId)
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+
+	summary, err := GrantCallsRetrieveSummary(*admin, callId)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, summary.Applications)
+	assert.Equal(t, 1, len(summary.Categories))
+	assert.Equal(t, int64(110), summary.Categories[0].Requested)
+	assert.Equal(t, int64(70), summary.Categories[0].Approved)
+	assert.Equal(t, int64(100), summary.Categories[0].Available)
+	assert.True(t, summary.Categories[0].HasCap)
+
+	_, err = GrantCallsRetrieveSummary(*bob, callId)
+	assert.NotNil(t, err)
+
+	page := GrantsBrowse(*admin, accapi.GrantsBrowseRequest{
+		ItemsPerPage:               50,
+		IncludeIngoingApplications: util.OptValue(true),
+		Filter:                     util.OptValue(accapi.GrantApplicationFilterShowAll),
+		CallId:                     util.OptValue(callId),
+	})
+	assert.Equal(t, 2, len(page.Items))
+}
+
+func TestCallVisibility(t *testing.T) {
+	initGrantsTest(t)
+	const giver = "call-g5"
+	addGrantGiver(t, giver)
+	applicant := actor("applicant", "")
+	admin := actor("admin", giver)
+
+	openId, err := GrantCallsCreate(*admin, openCallSpec())
+	assert.Nil(t, err)
+	closedId, err := GrantCallsCreate(*admin, callSpec(time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)))
+	assert.Nil(t, err)
+
+	_, err = GrantCallsCreate(*applicant, openCallSpec())
+	assert.NotNil(t, err)
+	_, err = GrantCallsCreate(*admin, callSpec(time.Now(), time.Now().Add(-time.Hour)))
+	assert.NotNil(t, err)
+
+	page := GrantCallsBrowse(*applicant, accapi.GrantCallsBrowseRequest{IncludeClosed: util.OptValue(true)})
+	assert.Equal(t, 1, len(page.Items))
+	assert.Equal(t, openId, page.Items[0].Id)
+	assert.True(t, page.Items[0].Status.IsOpen)
+
+	page = GrantCallsBrowse(*admin, accapi.GrantCallsBrowseRequest{IncludeClosed: util.OptValue(true)})
+	assert.Equal(t, 2, len(page.Items))
+
+	_, err = GrantCallsRetrieve(*applicant, closedId)
+	assert.NotNil(t, err)
+	call, err := GrantCallsRetrieve(*admin, closedId)
+	assert.Nil(t, err)
+	assert.False(t, call.Status.IsOpen)
+}
@@ -75,6 +75,13 @@ func GrantsExportBrowse(actor rpc.Actor) []accapi.GrantsExportResponse {
 				}
 			}
 
+			var reviews []accapi.GrantReviewAssignment
+			for _, assignment := range item.Status.Reviews {
+				if assignment.GrantGiver == string(actor.Project.Value) {
+					reviews = append(reviews, assignment)
+				}
+			}
+
 			result = append(result, accapi.GrantsExportResponse{
 				Id:               item.Id.Value,
 				Title:            title,
@@ -88,6 +95,7 @@ func GrantsExportBrowse(actor rpc.Actor) []accapi.GrantsExportResponse {
 				LastUpdatedAt:    item.UpdatedAt,
 				Resources:        resources,
 				AnswerFields:     answerFields,
+				Reviews:          util.NonNilSlice(reviews),
 			})
 		}
 
@@ -120,7 +128,8 @@ func GrantsExportBrowseToCsv(lines []accapi.GrantsExportResponse) string {
 	}
 
 	s.WriteString("id,title,submitted_by,submitted_at,start,duration_months,state,grant_giver,last_updated_at,")
-	s.WriteString("organization_full_name,department,research_field,position,gender,unit")
+	s.WriteString("organization_full_name,department,research_field,position,gender,unit,")
+	s.WriteString("reviewers,reviews_submitted,average_score")
 
 	for f := range fieldsColumns {
 		s.WriteString(",")
@@ -192,6 +201,23 @@ func GrantsExportBrowseToCsv(lines []accapi.GrantsExportResponse) string {
 		grantsWriteQuotedString(s, item.OptionalUserInfo.Gender.GetOrDefault("Unknown"))
 		s.WriteRune(',')
 		grantsWriteQuotedString(s, item.OptionalUserInfo.Unit.GetOrDefault("Unknown"))
+
+		var reviewers []string
+		submitted := 0
+		for _, assignment := range item.Reviews {
+			reviewers = append(reviewers, grantReviewSummary(assignment))
+			if assignment.Review.Present {
+				submitted++
+			}
+		}
+		s.WriteRune(',')
+		grantsWriteQuotedString(s, strings.Join(reviewers, "; "))
+		s.WriteRune(',')
+		grantsWriteQuotedString(s, fmt.Sprint(submitted))
+		s.WriteRune(',')
+		if avg := grantReviewAverageScore(item.Reviews); avg.Present {
+			grantsWriteQuotedString(s, fmt.Sprintf("%.2f", avg.Value))
+		}
 		for name := range fieldsColumns {
 			s.WriteRune(',')
 			grantsWriteQuotedString(s, item.AnswerFields[name].Answer)
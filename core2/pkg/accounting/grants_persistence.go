@@ -52,10 +52,11 @@ func grantsLoad(id accGrantId, prefetchHint []accGrantId) {
 			UpdatedAt    time.Time
 			Synchronized bool
 			ProjectId    sql.Null[string]
+			CallId       sql.Null[int64]
 		}](
 			b,
 			`
-				select id, overall_state, requested_by, created_at, updated_at, synchronized, project_id
+				select id, overall_state, requested_by, created_at, updated_at, synchronized, project_id, call_id
 				from "grant".applications app
 				where id = some(:ids)
 		    `,
@@ -178,6 +179,31 @@ func grantsLoad(id accGrantId, prefetchHint []accGrantId) {
 			},
 		)
 
+		reviewsPromise := db.BatchSelect[struct {
+			ApplicationId  int
+			GrantGiver     string
+			Reviewer       string
+			AssignedBy     string
+			AssignedAt     time.Time
+			SubmittedAt    sql.Null[time.Time]
+			RevisionNumber sql.Null[int]
+			Scores         sql.Null[string]
+			Comment        sql.Null[string]
+		}](
+			b,
+			`
+				select
+					application_id, grant_giver, reviewer, assigned_by, assigned_at, submitted_at, revision_number,
+					cast(scores as text) as scores, comment
+				from "grant".reviews
+				where application_id = some(:ids)
+				order by assigned_at, reviewer
+		    `,
+			db.Params{
+				"ids": prefetchList,
+			},
+		)
+
 		db.BatchSend(b)
 
 		apps := *appsPromise
@@ -185,9 +211,15 @@ func grantsLoad(id accGrantId, prefetchHint []accGrantId) {
 		revisions := *revisionsPromise
 		approvals := *approvalsPromise
 		resources := *resourcesPromise
+		reviews := *reviewsPromise
 
 		appsById := map[int]*accapi.GrantApplication{}
+		callsById := map[int]util.Option[string]{}
 		for _, app := range apps {
+			if app.CallId.Valid {
+				callsById[app.Id] = util.OptValue(fmt.Sprint(app.CallId.V))
+			}
+
 			appsById[app.Id] = &accapi.GrantApplication{
 				Id:              util.IntOrString{Value: fmt.Sprint(app.Id)},
 				CreatedBy:       app.RequestedBy,
@@ -288,6 +320,38 @@ func grantsLoad(id accGrantId, prefetchHint []accGrantId) {
 			})
 		}
 
+		for _, review := range reviews {
+			app, ok := appsById[review.ApplicationId]
+			if !ok {
+				continue
+			}
+
+			assignment := accapi.GrantReviewAssignment{
+				GrantGiver: review.GrantGiver,
+				Reviewer:   review.Reviewer,
+				AssignedBy: review.AssignedBy,
+				AssignedAt: fndapi.Timestamp(review.AssignedAt),
+			}
+
+			if review.SubmittedAt.Valid {
+				var scores []accapi.GrantReviewScore
+				if review.Scores.Valid {
+					if err := json.Unmarshal([]byte(review.Scores.V), &scores); err != nil {
+						log.Warn("Failed to parse review scores: %s", err)
+					}
+				}
+
+				assignment.Review.Set(accapi.GrantReview{
+					SubmittedAt:    fndapi.Timestamp(review.SubmittedAt.V),
+					RevisionNumber: review.RevisionNumber.V,
+					Scores:         util.NonNilSlice(scores),
+					Comment:        review.Comment.V,
+				})
+			}
+
+			app.Status.Reviews = append(app.Status.Reviews, assignment)
+		}
+
 		for _, resource := range resources {
 			app, ok := appsById[resource.ApplicationId]
 			if !ok {
@@ -325,11 +389,16 @@ func grantsLoad(id accGrantId, prefetchHint []accGrantId) {
 		}
 
 		var result []accapi.GrantApplication
-		for _, app := range appsById {
+		for appId, app := range appsById {
 			slices.SortFunc(app.Status.Revisions, func(a, b accapi.GrantRevision) int {
 				return cmp.Compare(a.RevisionNumber, b.RevisionNumber)
 			})
 
+			// The call is stored on the application since it cannot change between revisions
+			for i := range app.Status.Revisions {
+				app.Status.Revisions[i].Document.CallId = callsById[appId]
+			}
+
 			if len(app.Status.Revisions) > 0 {
 				app.CurrentRevision = app.Status.Revisions[len(app.Status.Revisions)-1]
 			}
@@ -524,6 +593,18 @@ func grantsLoadSettings() {
 			db.Params{},
 		)
 
+		reviewSettings := db.Select[struct {
+			ProjectId string
+			Quorum    int
+		}](
+			tx,
+			`
+				select project_id, quorum
+				from "grant".review_settings
+		    `,
+			db.Params{},
+		)
+
 		result := map[string]accapi.GrantRequestSettings{}
 		newProjectFields := make([]accapi.FormField, 0)
 		existingProjectFields := make([]accapi.FormField, 0)
@@ -620,6 +701,12 @@ func grantsLoadSettings() {
 			result[description.ProjectId] = existing
 		}
 
+		for _, review := range reviewSettings {
+			existing := result[review.ProjectId]
+			existing.ReviewQuorum = review.Quorum
+			result[review.ProjectId] = existing
+		}
+
 		// Insert defaults
 		// -------------------------------------------------------------------------------------------------------------
 
@@ -703,6 +790,20 @@ func lGrantsPersistSettings(settings *grantSettings, templateHasChanges bool) {
 			},
 		)
 
+		db.Exec(
+			tx,
+			`
+				insert into "grant".review_settings(project_id, quorum)
+				values (:project, :quorum)
+				on conflict (project_id) do update set
+					quorum = excluded.quorum
+		    `,
+			db.Params{
+				"project": settings.ProjectId,
+				"quorum":  s.ReviewQuorum,
+			},
+		)
+
 		var personalProject string
 		var existingProject string
 		var newProject string
@@ -869,8 +970,8 @@ func lGrantsPersist(app *grantApplication) {
 			db.Exec(
 				tx,
 				`
-					insert into "grant".applications(id, overall_state, requested_by, created_at, updated_at, synchronized, project_id) 
-					values (:id, :state, :requested_by, now(), now(), false, :project_id)
+					insert into "grant".applications(id, overall_state, requested_by, created_at, updated_at, synchronized, project_id, call_id) 
+					values (:id, :state, :requested_by, now(), now(), false, :project_id, cast(nullif(:call_id, '') as bigint))
 					on conflict (id) do update set
 						overall_state = excluded.overall_state,
 						requested_by = excluded.requested_by,
@@ -882,6 +983,7 @@ func lGrantsPersist(app *grantApplication) {
 					"state":        appl.Status.OverallState,
 					"requested_by": appl.CreatedBy,
 					"project_id":   appl.ProjectId.Sql(),
+					"call_id":      appl.CurrentRevision.Document.CallId.GetOrDefault(""),
 				},
 			)
 
@@ -927,6 +1029,104 @@ func lGrantsPersist(app *grantApplication) {
 				},
 			)
 
+			var reviewGivers []string
+			var reviewReviewers []string
+			var reviewAssignedBy []string
+			var reviewAssignedAt []int64
+			var reviewSubmittedAt []int64
+			var reviewRevisions []int64
+			var reviewScores []string
+			var reviewComments []string
+
+			for _, assignment := range appl.Status.Reviews {
+				reviewGivers = append(reviewGivers, assignment.GrantGiver)
+				reviewReviewers = append(reviewReviewers, assignment.Reviewer)
+				reviewAssignedBy = append(reviewAssignedBy, assignment.AssignedBy)
+				reviewAssignedAt = append(reviewAssignedAt, assignment.AssignedAt.UnixMilli())
+
+				if assignment.Review.Present {
+					review := assignment.Review.Value
+					scores, _ := json.Marshal(util.NonNilSlice(review.Scores))
+					reviewSubmittedAt = append(reviewSubmittedAt, review.SubmittedAt.UnixMilli())
+					reviewRevisions = append(reviewRevisions, int64(review.RevisionNumber))
+					reviewScores = append(reviewScores, string(scores))
+					reviewComments = append(reviewComments, review.Comment)
+				} else {
+					reviewSubmittedAt = append(reviewSubmittedAt, 0)
+					reviewRevisions = append(reviewRevisions, 0)
+					reviewScores = append(reviewScores, "")
+					reviewComments = append(reviewComments, "")
+				}
+			}
+
+			if len(reviewReviewers) > 0 {
+				db.Exec(
+					tx,
+					`
+						with data as (
+							select
+								unnest(cast(:grant_givers as text[])) as grant_giver,
+								unnest(cast(:reviewers as text[])) as reviewer,
+								unnest(cast(:assigned_by as text[])) as assigned_by,
+								unnest(cast(:assigned_at as int8[])) as assigned_at,
+								unnest(cast(:submitted_at as int8[])) as submitted_at,
+								unnest(cast(:revisions as int8[])) as revision_number,
+								unnest(cast(:scores as text[])) as scores,
+								unnest(cast(:comments as text[])) as comment
+						)
+						insert into "grant".reviews(application_id, grant_giver, reviewer, assigned_by, assigned_at,
+							submitted_at, revision_number, scores, comment)
+						select
+							:app_id, grant_giver, reviewer, assigned_by, to_timestamp(assigned_at / 1000.0),
+							case when submitted_at = 0 then null else to_timestamp(submitted_at / 1000.0) end,
+							case when submitted_at = 0 then null else revision_number end,
+							case when submitted_at = 0 then null else cast(scores as jsonb) end,
+							case when submitted_at = 0 then null else comment end
+						from data
+						on conflict (application_id, grant_giver, reviewer) do update set
+							submitted_at = excluded.submitted_at,
+							revision_number = excluded.revision_number,
+							scores = excluded.scores,
+							comment = excluded.comment
+					`,
+					db.Params{
+						"app_id":       app.lId(),
+						"grant_givers": reviewGivers,
+						"reviewers":    reviewReviewers,
+						"assigned_by":  reviewAssignedBy,
+						"assigned_at":  reviewAssignedAt,
+						"submitted_at": reviewSubmittedAt,
+						"revisions":    reviewRevisions,
+						"scores":       reviewScores,
+						"comments":     reviewComments,
+					},
+				)
+			}
+
+			db.Exec(
+				tx,
+				`
+					with data as (
+						select
+							unnest(cast(:grant_givers as text[])) as grant_giver,
+							unnest(cast(:reviewers as text[])) as reviewer
+					)
+					delete from "grant".reviews r
+					where
+						r.application_id = :app_id
+						and not exists (
+							select 1
+							from data d
+							where d.grant_giver = r.grant_giver and d.reviewer = r.reviewer
+						)
+			    `,
+				db.Params{
+					"app_id":       app.lId(),
+					"grant_givers": reviewGivers,
+					"reviewers":    reviewReviewers,
+				},
+			)
+
 			var revisionIds []int64
 			var revisionUpdatedBy []string
 			var revisionComments []string
@@ -0,0 +1,357 @@
+package accounting
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	accapi "ucloud.dk/shared/pkg/accounting"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Reviews
+// =====================================================================================================================
+// This file implements the review workflow of the grant system. Administrators of a grant giver can assign reviewers
+// to an application. A reviewer must be a member of the grant giver project, but they do not need to be an
+// administrator. Reviewers score the application against the template fields of the grant giver and can leave a
+// comment for the administrators.
+//
+// Reviews are internal to the grant giver. They are never shown to the applicant and a reviewer can only see their
+// own review. Grant givers can require a quorum of submitted reviews before an application can be approved, see
+// lGrantsCheckReviewQuorum.
+//
+// Review assignments are stored as part of the application and follow the same locking and persistence rules as the
+// rest of the application.
+
+const grantMaxReviewersPerGiver = 64
+
+func initGrantReviews() {
+	accapi.GrantsUpdateReviewers.Handler(func(info rpc.RequestInfo, request accapi.GrantsUpdateReviewersRequest) (util.Empty, *util.HttpError) {
+		return util.Empty{}, GrantsUpdateReviewers(info.Actor, request)
+	})
+
+	accapi.GrantsSubmitReview.Handler(func(info rpc.RequestInfo, request accapi.GrantsSubmitReviewRequest) (util.Empty, *util.HttpError) {
+		return util.Empty{}, GrantsSubmitReview(info.Actor, request)
+	})
+}
+
+func GrantsUpdateReviewers(actor rpc.Actor, req accapi.GrantsUpdateReviewersRequest) *util.HttpError {
+	if !actor.Project.Present {
+		return util.HttpErr(http.StatusBadRequest, "no project given, who do you represent?")
+	}
+
+	grantGiver := string(actor.Project.Value)
+	if !actor.Membership[actor.Project.Value].Satisfies(rpc.ProjectRoleAdmin) {
+		return util.HttpErr(http.StatusForbidden, "you cannot assign reviewers")
+	}
+
+	var reviewers []string
+	for _, reviewer := range req.Reviewers {
+		if reviewer == "" {
+			return util.HttpErr(http.StatusBadRequest, "reviewer must not be empty")
+		}
+		if !slices.Contains(reviewers, reviewer) {
+			reviewers = append(reviewers, reviewer)
+		}
+	}
+
+	if len(reviewers) > grantMaxReviewersPerGiver {
+		return util.HttpErr(http.StatusBadRequest, "too many reviewers (max %d)", grantMaxReviewersPerGiver)
+	}
+
+	for _, reviewer := range reviewers {
+		reviewerActor, ok := rpc.LookupActor(reviewer)
+		if !ok || !reviewerActor.Membership[actor.Project.Value].Satisfies(rpc.ProjectRoleUser) {
+			return util.HttpErr(http.StatusBadRequest, "%s is not a member of this project", reviewer)
+		}
+	}
+
+	idRaw, _ := strconv.ParseInt(req.ApplicationId, 10, 64)
+	app, _ := grantsRead(actor, grantAuthApprover, accGrantId(idRaw), nil)
+	if app == nil {
+		return util.HttpErr(http.StatusNotFound, "unknown application")
+	}
+
+	var err *util.HttpError
+	var newReviewers []string
+
+	app.Mu.Lock()
+	if !lGrantApplicationIsApproverInActiveProjectNoAuth(actor, app) {
+		err = util.HttpErr(http.StatusBadRequest, "which project do you represent?")
+	} else if app.Application.Status.OverallState != accapi.GrantApplicationStateInProgress {
+		err = util.HttpErr(http.StatusBadRequest, "application is no longer active")
+	} else if slices.Contains(reviewers, app.Application.CreatedBy) {
+		err = util.HttpErr(http.StatusBadRequest, "the applicant cannot review their own application")
+	}
+
+	if err == nil {
+		now := fndapi.Timestamp(time.Now())
+		existing := app.Application.Status.Reviews
+
+		var updated []accapi.GrantReviewAssignment
+		for _, assignment := range existing {
+			if assignment.GrantGiver != grantGiver || slices.Contains(reviewers, assignment.Reviewer) {
+				updated = append(updated, assignment)
+			}
+		}
+
+		for _, reviewer := range reviewers {
+			alreadyAssigned := slices.ContainsFunc(existing, func(assignment accapi.GrantReviewAssignment) bool {
+				return assignment.GrantGiver == grantGiver && assignment.Reviewer == reviewer
+			})
+
+			if !alreadyAssigned {
+				updated = append(updated, accapi.GrantReviewAssignment{
+					GrantGiver: grantGiver,
+					Reviewer:   reviewer,
+					AssignedBy: actor.Username,
+					AssignedAt: now,
+				})
+				newReviewers = append(newReviewers, reviewer)
+			}
+		}
+
+		app.Application.Status.Reviews = util.NonNilSlice(updated)
+		lGrantsPersist(app)
+	}
+
+	appCopy := *app.Application
+	app.Mu.Unlock()
+
+	if err == nil && len(newReviewers) > 0 {
+		grantHandleEvent(grantEvent{
+			Type:        grantEvReviewerAssigned,
+			Actor:       actor,
+			Application: appCopy,
+			Recipients:  newReviewers,
+		})
+	}
+
+	return err
+}
+
+func GrantsSubmitReview(actor rpc.Actor, req accapi.GrantsSubmitReviewRequest) *util.HttpError {
+	if !actor.Project.Present {
+		return util.HttpErr(http.StatusBadRequest, "no project given, who do you represent?")
+	}
+
+	if len(req.Comment) > 1024*1024 {
+		return util.HttpErr(http.StatusBadRequest, "your comment is too long")
+	}
+
+	grantGiver := string(actor.Project.Value)
+
+	idRaw, _ := strconv.ParseInt(req.ApplicationId, 10, 64)
+	app, _ := grantsRead(actor, grantAuthReviewer, accGrantId(idRaw), nil)
+	if app == nil {
+		return util.HttpErr(http.StatusNotFound, "unknown application")
+	}
+
+	var err *util.HttpError
+
+	app.Mu.Lock()
+	assignmentIdx := slices.IndexFunc(app.Application.Status.Reviews, func(assignment accapi.GrantReviewAssignment) bool {
+		return assignment.GrantGiver == grantGiver && assignment.Reviewer == actor.Username
+	})
+
+	if assignmentIdx == -1 {
+		err = util.HttpErr(http.StatusForbidden, "you have not been asked to review this application")
+	} else if app.Application.Status.OverallState != accapi.GrantApplicationStateInProgress {
+		err = util.HttpErr(http.StatusBadRequest, "application is no longer active")
+	}
+
+	if err == nil {
+		document := app.Application.CurrentRevision.Document
+		err = grantReviewValidateScores(grantReviewTemplateFields(grantGiver, document), req.Scores)
+	}
+
+	if err == nil {
+		app.Application.Status.Reviews[assignmentIdx].Review.Set(accapi.GrantReview{
+			SubmittedAt:    fndapi.Timestamp(time.Now()),
+			RevisionNumber: app.Application.CurrentRevision.RevisionNumber,
+			Scores:         req.Scores,
+			Comment:        req.Comment,
+		})
+		lGrantsPersist(app)
+	}
+	app.Mu.Unlock()
+
+	return err
+}
+
+// grantReviewTemplateFields returns the template fields of the grant giver which apply to the application. If the
+// application was submitted to a call with its own templates, then the templates of the call are used.
+func grantReviewTemplateFields(grantGiver string, document accapi.GrantDocument) []accapi.FormField {
+	templates, ok := grantCallTemplates(grantGiver, document.CallId)
+	if !ok {
+		return nil
+	}
+
+	structured := &templates.Structured
+	switch document.Recipient.Type {
+	case accapi.RecipientTypePersonalWorkspace:
+		return slices.Clone(structured.PersonalProject)
+	case accapi.RecipientTypeNewProject:
+		return slices.Clone(structured.NewProject)
+	case accapi.RecipientTypeExistingProject:
+		return slices.Clone(structured.ExistingProject)
+	default:
+		return nil
+	}
+}
+
+// grantReviewValidateScores checks that a review scores the application overall and every mandatory field of the
+// template. Optional fields can be scored, but this is not required.
+func grantReviewValidateScores(fields []accapi.FormField, scores []accapi.GrantReviewScore) *util.HttpError {
+	seen := map[string]util.Empty{}
+	for _, score := range scores {
+		if _, duplicate := seen[score.Field]; duplicate {
+			return util.HttpErr(http.StatusBadRequest, "field '%s' was scored more than once", score.Field)
+		}
+		seen[score.Field] = util.Empty{}
+
+		known := score.Field == accapi.GrantReviewOverallField || slices.ContainsFunc(fields, func(field accapi.FormField) bool {
+			return field.Name == score.Field
+		})
+
+		if !known {
+			return util.HttpErr(http.StatusBadRequest, "unknown field '%s'", score.Field)
+		}
+
+		if score.Score < accapi.GrantReviewMinScore || score.Score > accapi.GrantReviewMaxScore {
+			return util.HttpErr(http.StatusBadRequest, "scores must be between %d and %d",
+				accapi.GrantReviewMinScore, accapi.GrantReviewMaxScore)
+		}
+	}
+
+	if _, ok := seen[accapi.GrantReviewOverallField]; !ok {
+		return util.HttpErr(http.StatusBadRequest, "an overall score is required")
+	}
+
+	for _, field := range fields {
+		if _, ok := seen[field.Name]; !ok && !field.Optional {
+			return util.HttpErr(http.StatusBadRequest, "field '%s' must be scored", field.Title)
+		}
+	}
+
+	return nil
+}
+
+// lGrantsCheckReviewQuorum returns an error if the grant giver requires more submitted reviews than the application
+// currently has. The application must be locked by the caller.
+func lGrantsCheckReviewQuorum(app *grantApplication, grantGiver string) *util.HttpError {
+	quorum := 0
+	if settings, ok := grantsRetrieveSettings(grantGiver); ok {
+		settings.Mu.RLock()
+		if settings.Settings != nil {
+			quorum = settings.Settings.ReviewQuorum
+		}
+		settings.Mu.RUnlock()
+	}
+
+	if quorum <= 0 {
+		return nil
+	}
+
+	submitted := 0
+	for _, assignment := range app.Application.Status.Reviews {
+		if assignment.GrantGiver == grantGiver && assignment.Review.Present {
+			submitted++
+		}
+	}
+
+	if submitted < quorum {
+		return util.HttpErr(
+			http.StatusBadRequest,
+			"this application needs %d submitted reviews before it can be approved (currently %d)",
+			quorum,
+			submitted,
+		)
+	}
+	return nil
+}
+
+// lGrantApplicationIsReviewerInActiveProjectNoAuth assumes that auth has already taken place.
+func lGrantApplicationIsReviewerInActiveProjectNoAuth(actor rpc.Actor, a *grantApplication) bool {
+	if !actor.Project.Present {
+		return false
+	}
+
+	for _, assignment := range a.Application.Status.Reviews {
+		if assignment.GrantGiver == string(actor.Project.Value) && assignment.Reviewer == actor.Username {
+			return true
+		}
+	}
+	return false
+}
+
+// grantFilterReviews removes the reviews which the actor is not allowed to see. Administrators of a grant giver can
+// see all the reviews of their project. Reviewers can only see their own review. The applicant never sees any reviews.
+func grantFilterReviews(actor rpc.Actor, app *accapi.GrantApplication) {
+	var visible []accapi.GrantReviewAssignment
+	if app.CreatedBy != actor.Username {
+		for _, assignment := range app.Status.Reviews {
+			isAdmin := actor.Membership[rpc.ProjectId(assignment.GrantGiver)].Satisfies(rpc.ProjectRoleAdmin)
+			if isAdmin || assignment.Reviewer == actor.Username {
+				visible = append(visible, assignment)
+			}
+		}
+	}
+	app.Status.Reviews = util.NonNilSlice(visible)
+
+	for i := range app.Status.ApplicationHistory {
+		grantFilterReviews(actor, &app.Status.ApplicationHistory[i])
+	}
+}
+
+// grantWithoutReviews returns a copy of the application (and its history) without any reviews.
+func grantWithoutReviews(app accapi.GrantApplication) accapi.GrantApplication {
+	app.Status.Reviews = []accapi.GrantReviewAssignment{}
+
+	history := make([]accapi.GrantApplication, len(app.Status.ApplicationHistory))
+	for i, revision := range app.Status.ApplicationHistory {
+		history[i] = grantWithoutReviews(revision)
+	}
+	app.Status.ApplicationHistory = history
+	return app
+}
+
+// grantReviewAverageScore returns the average overall score of the submitted reviews.
+func grantReviewAverageScore(assignments []accapi.GrantReviewAssignment) util.Option[float64] {
+	sum := 0
+	count := 0
+	for _, assignment := range assignments {
+		if !assignment.Review.Present {
+			continue
+		}
+
+		for _, score := range assignment.Review.Value.Scores {
+			if score.Field == accapi.GrantReviewOverallField {
+				sum += score.Score
+				count++
+			}
+		}
+	}
+
+	if count == 0 {
+		return util.OptNone[float64]()
+	}
+	return util.OptValue(float64(sum) / float64(count))
+}
+
+func grantReviewSummary(assignment accapi.GrantReviewAssignment) string {
+	if !assignment.Review.Present {
+		return fmt.Sprintf("%s (pending)", assignment.Reviewer)
+	}
+
+	for _, score := range assignment.Review.Value.Scores {
+		if score.Field == accapi.GrantReviewOverallField {
+			return fmt.Sprintf("%s (%d)", assignment.Reviewer, score.Score)
+		}
+	}
+	return assignment.Reviewer
+}
@@ -0,0 +1,341 @@
+package accounting
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	accapi "ucloud.dk/shared/pkg/accounting"
+	"ucloud.dk/shared/pkg/assert"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+func reviewer(username, giver string) *rpc.Actor {
+	result := actor(username, giver)
+	result.Membership[rpc.ProjectId(giver)] = rpc.ProjectRoleUser
+	return result
+}
+
+func overallScore(score int) []accapi.GrantReviewScore {
+	return []accapi.GrantReviewScore{{Field: accapi.GrantReviewOverallField, Score: score}}
+}
+
+func setReviewQuorum(giver string, quorum int) {
+	settings, _ := grantsRetrieveSettings(giver)
+	settings.Mu.Lock()
+	settings.Settings.ReviewQuorum = quorum
+	settings.Mu.Unlock()
+}
+
+func TestReviewHappyPath(t *testing.T) {
+	initGrantsTest(t)
+	const giver = "review-g1"
+	addGrantGiver(t, giver)
+	applicant := actor("applicant", "")
+	admin := actor("admin", giver)
+	bob := reviewer("bob", giver)
+
+	id64, err := GrantsSubmitRevision(*applicant, rev(applicant.Username, giver, 10))
+	assert.Nil(t, err)
+	appID := strconv.FormatInt(id64, 10)
+
+	assert.Nil(t, GrantsUpdateReviewers(*admin, accapi.GrantsUpdateReviewersRequest{
+		ApplicationId: appID,
+		Reviewers:     []string{bob.Username},
+	}))
+
+	// The reviewer can see the application even though they are not an administrator
+	app, err := GrantsRetrieve(*bob, appID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(app.Status.Reviews))
+	assert.False(t, app.Status.Reviews[0].Review.Present)
+
+	assert.Nil(t, GrantsSubmitReview(*bob, accapi.GrantsSubmitReviewRequest{
+		ApplicationId: appID,
+		Scores:        overallScore(4),
+		Comment:       "solid proposal",
+	}))
+
+	app, err = GrantsRetrieve(*admin, appID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(app.Status.Reviews))
+	assert.True(t, app.Status.Reviews[0].Review.Present)
+	assert.Equal(t, "solid proposal", app.Status.Reviews[0].Review.Value.Comment)
+	assert.Equal(t, "bob (4)", grantReviewSummary(app.Status.Reviews[0]))
+
+	// Reviewers are not allowed to act on behalf of the grant giver
+	_, err = GrantsPostComment(*bob, accapi.GrantsPostCommentRequest{ApplicationId: appID, Comment: "hi"})
+	assert.NotNil(t, err)
+	err = GrantsUpdateState(*bob, accapi.GrantsUpdateStateRequest{ApplicationId: appID, NewState: accapi.GrantApplicationStateApproved})
+	assert.NotNil(t, err)
+
+	browse := GrantsBrowse(*bob, accapi.GrantsBrowseRequest{
+		ItemsPerPage:               50,
+		IncludeIngoingApplications: util.OptValue(true),
+		Filter:                     util.OptValue(accapi.GrantApplicationFilterShowAll),
+	})
+	assert.Equal(t, 1, len(browse.Items))
+}
+
+func TestReviewsHiddenFromApplicant(t *testing.T) {
+	initGrantsTest(t)
+	const giver = "review-g2"
+	addGrantGiver(t, giver)
+	applicant := actor("applicant", "")
+	admin := actor("admin", giver)
+	bob := reviewer("bob", giver)
+	carol := reviewer("carol", giver)
+
+	id64, _ := GrantsSubmitRevision(*applicant, rev(applicant.Username, giver, 10))
+	appID := strconv.FormatInt(id64, 10)
+
+	assert.Nil(t, GrantsUpdateReviewers(*admin, accapi.GrantsUpdateReviewersRequest{
+		ApplicationId: appID,
+		Reviewers:     []string{bob.Username, carol.Username},
+	}))
+	assert.Nil(t, GrantsSubmitReview(*bob, accapi.GrantsSubmitReviewRequest{ApplicationId: appID, Scores: overallScore(2)}))
+
+	app, err := GrantsRetrieve(*applicant, appID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(app.Status.Reviews))
+
+	app, err = GrantsRetrieve(*carol, appID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(app.Status.Reviews))
+	assert.Equal(t, carol.Username, app.Status.Reviews[0].Reviewer)
+
+	app, err = GrantsRetrieve(*admin, appID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(app.Status.Reviews))
+
+	// Removing a reviewer drops their review
+	assert.Nil(t, GrantsUpdateReviewers(*admin, accapi.GrantsUpdateReviewersRequest{
+		ApplicationId: appID,
+		Reviewers:     []string{carol.Username},
+	}))
+	app, _ = GrantsRetrieve(*admin, appID)
+	assert.Equal(t, 1, len(app.Status.Reviews))
+
+	_, err = GrantsRetrieve(*bob, appID)
+	assert.NotNil(t, err)
+}
+
+func TestReviewsAreNotSentInWebhooks(t *testing.T) {
+	initGrantsTest(t)
+	const giver = "review-webhooks"
+	addGrantGiver(t, giver)
+	applicant := actor("applicant", "")
+	admin := actor("admin", giver)
+	bob := reviewer("bob", giver)
+
+	id64, _ := GrantsSubmitRevision(*applicant, rev(applicant.Username, giver, 10))
+	appID := strconv.FormatInt(id64, 10)
+
+	assert.Nil(t, GrantsUpdateReviewers(*admin, accapi.GrantsUpdateReviewersRequest{
+		ApplicationId: appID,
+		Reviewers:     []string{bob.Username},
+	}))
+	assert.Nil(t, GrantsSubmitReview(*bob, accapi.GrantsSubmitReviewRequest{
+		ApplicationId: appID,
+		Scores:        overallScore(1),
+		Comment:       "confidential remark",
+	}))
+
+	app, err := GrantsRetrieve(*admin, appID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(app.Status.Reviews))
+	app.Status.ApplicationHistory = []accapi.GrantApplication{app}
+
+	var mu sync.Mutex
+	var bodies [][]byte
+	var events []fndapi.WebhookEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req fndapi.BulkRequest[fndapi.WebhookEvent]
+		if !strings.HasSuffix(r.URL.Path, "/emit") || json.NewDecoder(r.Body).Decode(&req) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		for _, ev := range req.Items {
+			bodies = append(bodies, ev.Data)
+			events = append(events, ev)
+		}
+		mu.Unlock()
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	previousClient := rpc.DefaultClient
+	t.Cleanup(func() {
+		rpc.DefaultClient = previousClient
+		grantGlobals.Testing.Enabled = true
+	})
+	rpc.DefaultClient = &rpc.Client{BasePath: server.URL, Client: server.Client()}
+	grantGlobals.Testing.Enabled = false
+
+	assert.Nil(t, grantSendWebhooks(grantEvent{
+		Type:        grantEvApplicationSubmitted,
+		Actor:       *admin,
+		Application: app,
+	}))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Delivered to the applicant and to the grant giver
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, util.OptValue(applicant.Username), events[0].Username)
+	assert.Equal(t, util.OptValue(giver), events[1].Project)
+
+	for _, body := range bodies {
+		assert.False(t, strings.Contains(string(body), "confidential remark"))
+
+		var payload struct {
+			Actor       string                  `json:"actor"`
+			Application accapi.GrantApplication `json:"application"`
+		}
+		assert.Nil(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, admin.Username, payload.Actor)
+		assert.Equal(t, app.Id, payload.Application.Id)
+		assert.Equal(t, 0, len(payload.Application.Status.Reviews))
+		assert.Equal(t, 1, len(payload.Application.Status.ApplicationHistory))
+		assert.Equal(t, 0, len(payload.Application.Status.ApplicationHistory[0].Status.Reviews))
+	}
+
+	// The event itself is not modified
+	assert.Equal(t, 1, len(app.Status.Reviews))
+	assert.Equal(t, 1, len(app.Status.ApplicationHistory[0].Status.Reviews))
+}
+
+func TestReviewQuorumBlocksApproval(t *testing.T) {
+	initGrantsTest(t)
+	const giver = "review-g3"
+	addGrantGiver(t, giver)
+	setReviewQuorum(giver, 2)
+	applicant := actor("applicant", "")
+	admin := actor("admin", giver)
+	bob := reviewer("bob", giver)
+	carol := reviewer("carol", giver)
+
+	id64, _ := GrantsSubmitRevision(*applicant, rev(applicant.Username, giver, 10))
+	appID := strconv.FormatInt(id64, 10)
+	approve := accapi.GrantsUpdateStateRequest{ApplicationId: appID, NewState: accapi.GrantApplicationStateApproved}
+
+	err := GrantsUpdateState(*admin, approve)
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+
+	assert.Nil(t, GrantsUpdateReviewers(*admin, accapi.GrantsUpdateReviewersRequest{
+		ApplicationId: appID,
+		Reviewers:     []string{bob.Username, carol.Username},
+	}))
+	assert.Nil(t, GrantsSubmitReview(*bob, accapi.GrantsSubmitReviewRequest{ApplicationId: appID, Scores: overallScore(5)}))
+	assert.NotNil(t, GrantsUpdateState(*admin, approve))
+
+	assert.Nil(t, GrantsSubmitReview(*carol, accapi.GrantsSubmitReviewRequest{ApplicationId: appID, Scores: overallScore(3)}))
+	assert.Nil(t, GrantsUpdateState(*admin, approve))
+
+	app, _ := GrantsRetrieve(*admin, appID)
+	assert.Equal(t, accapi.GrantApplicationStateApproved, app.Status.OverallState)
+
+	// Reviews cannot be changed once the application has been closed
+	assert.NotNil(t, GrantsSubmitReview(*carol, accapi.GrantsSubmitReviewRequest{ApplicationId: appID, Scores: overallScore(1)}))
+}
+
+func TestReviewQuorumDoesNotBlockRejection(t *testing.T) {
+	initGrantsTest(t)
+	const giver = "review-g4"
+	addGrantGiver(t, giver)
+	setReviewQuorum(giver, 1)
+	applicant := actor("applicant", "")
+	admin := actor("admin", giver)
+
+	id64, _ := GrantsSubmitRevision(*applicant, rev(applicant.Username, giver, 10))
+	appID := strconv.FormatInt(id64, 10)
+	assert.Nil(t, GrantsUpdateState(*admin, accapi.GrantsUpdateStateRequest{ApplicationId: appID, NewState: accapi.GrantApplicationStateRejected}))
+}
+
+func TestUpdateReviewersErrorCases(t *testing.T) {
+	initGrantsTest(t)
+	const giver = "review-g5"
+	addGrantGiver(t, giver)
+	applicant := actor("applicant", "")
+	admin := actor("admin", giver)
+	bob := reviewer("bob", giver)
+	outsider := actor("outsider", "")
+
+	id64, _ := GrantsSubmitRevision(*applicant, rev(applicant.Username, giver, 10))
+	appID := strconv.FormatInt(id64, 10)
+
+	err := GrantsUpdateReviewers(*admin, accapi.GrantsUpdateReviewersRequest{ApplicationId: appID, Reviewers: []string{outsider.Username}})
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+
+	err = GrantsUpdateReviewers(*admin, accapi.GrantsUpdateReviewersRequest{ApplicationId: appID, Reviewers: []string{"does-not-exist"}})
+	assert.NotNil(t, err)
+
+	err = GrantsUpdateReviewers(*bob, accapi.GrantsUpdateReviewersRequest{ApplicationId: appID, Reviewers: []string{bob.Username}})
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusForbidden, err.StatusCode)
+
+	err = GrantsSubmitReview(*bob, accapi.GrantsSubmitReviewRequest{ApplicationId: appID, Scores: overallScore(3)})
+	assert.NotNil(t, err)
+}
+
+func TestReviewScoreValidation(t *testing.T) {
+	fields := []accapi.FormField{
+		{Name: "impact", Title: "Impact"},
+		{Name: "notes", Title: "Notes", Optional: true},
+	}
+
+	valid := []accapi.GrantReviewScore{{Field: "overall", Score: 3}, {Field: "impact", Score: 5}}
+	assert.Nil(t, grantReviewValidateScores(fields, valid))
+
+	invalid := [][]accapi.GrantReviewScore{
+		{{Field: "impact", Score: 5}},
+		{{Field: "overall", Score: 3}},
+		{{Field: "overall", Score: 0}, {Field: "impact", Score: 5}},
+		{{Field: "overall", Score: 6}, {Field: "impact", Score: 5}},
+		{{Field: "overall", Score: 3}, {Field: "impact", Score: 5}, {Field: "impact", Score: 4}},
+		{{Field: "overall", Score: 3}, {Field: "impact", Score: 5}, {Field: "unknown", Score: 4}},
+	}
+
+	for _, scores := range invalid {
+		assert.NotNil(t, grantReviewValidateScores(fields, scores))
+	}
+}
+
+func TestReviewExportCsv(t *testing.T) {
+	line := accapi.GrantsExportResponse{
+		Id:    "1",
+		Title: "title",
+		Reviews: []accapi.GrantReviewAssignment{
+			{
+				Reviewer: "bob",
+				Review: util.OptValue(accapi.GrantReview{
+					Scores: []accapi.GrantReviewScore{{Field: accapi.GrantReviewOverallField, Score: 4}},
+				}),
+			},
+			{
+				Reviewer: "carol",
+				Review: util.OptValue(accapi.GrantReview{
+					Scores: []accapi.GrantReviewScore{{Field: accapi.GrantReviewOverallField, Score: 1}},
+				}),
+			},
+			{Reviewer: "dave"},
+		},
+	}
+
+	csv := GrantsExportBrowseToCsv([]accapi.GrantsExportResponse{line})
+	rows := strings.Split(strings.TrimSpace(csv), "\n")
+	assert.Equal(t, 2, len(rows))
+	assert.True(t, strings.Contains(rows[0], "reviewers,reviews_submitted,average_score"))
+	assert.True(t, strings.HasSuffix(rows[1], "bob (4); carol (1); dave (pending),2,2.50"))
+}
@@ -9,6 +9,7 @@ import (
 	db "ucloud.dk/shared/pkg/database"
 	fndapi "ucloud.dk/shared/pkg/foundation"
 	"ucloud.dk/shared/pkg/log"
+	"ucloud.dk/shared/pkg/util"
 )
 
 func initLowFundsScan() {
@@ -40,6 +41,8 @@ func lowFundsScan(lowFundsLimitInPercent float32) {
 		Provider       string `json:"provider"`
 	}
 
+	var webhookEvents []fndapi.WebhookEvent
+
 	WalletsToNotify, WalletsToResetNotification, UsersToNotifications := db.NewTx3(func(tx *db.Transaction) ([]int, []int, map[string][]Resource) {
 		relevantWallets := db.Select[struct {
 			WalletId            int
@@ -53,6 +56,8 @@ func lowFundsScan(lowFundsLimitInPercent float32) {
 			Username            string
 			IsProject           bool
 			ProjectTitle        string
+			OwnerUsername       string
+			OwnerProject        string
 		}](
 			tx,
 			`
@@ -97,7 +102,9 @@ func lowFundsScan(lowFundsLimitInPercent float32) {
 					pc.provider,
 					coalesce(pm.username, wo.username) username,
 					wo.project_id is not null is_project,
-					coalesce(p.title, concat('Personal workspace of ', wo.username)) project_title
+					coalesce(p.title, concat('Personal workspace of ', wo.username)) project_title,
+					coalesce(wo.username, '') owner_username,
+					coalesce(wo.project_id, '') owner_project
 				from
 					accounting.wallets_v2 w join
 					active_usage au on w.id = au.wallet_id join
@@ -135,6 +142,22 @@ func lowFundsScan(lowFundsLimitInPercent float32) {
 				})
 				usersToNotifications[row.Username] = notifications
 				walletsToNotify = append(walletsToNotify, row.WalletId)
+
+				data, _ := json.Marshal(map[string]any{
+					"workspaceTitle":      row.ProjectTitle,
+					"category":            row.CategoryName,
+					"provider":            row.Provider,
+					"activeQuota":         row.ActiveQuota,
+					"activeUsage":         row.ActiveUsage,
+					"percentageRemaining": percentageRemaining,
+				})
+
+				webhookEvents = append(webhookEvents, fndapi.WebhookEvent{
+					Type:     fndapi.WebhookEventAllocationLowFunds,
+					Username: util.OptStringIfNotEmpty(row.OwnerUsername),
+					Project:  util.OptStringIfNotEmpty(row.OwnerProject),
+					Data:     data,
+				})
 			}
 			if percentageRemaining > lowFundsLimitInPercent && lowBalanceNotified {
 				walletsToResetNotification = append(walletsToResetNotification, row.WalletId)
@@ -171,6 +194,13 @@ func lowFundsScan(lowFundsLimitInPercent float32) {
 		}
 	}
 
+	if len(webhookEvents) > 0 {
+		_, err := fndapi.WebhooksEmit.Invoke(fndapi.BulkRequest[fndapi.WebhookEvent]{Items: webhookEvents})
+		if err != nil {
+			log.Warn("Failed to emit low-funds webhook events: %s", err)
+		}
+	}
+
 	if len(WalletsToNotify) > 0 || len(WalletsToResetNotification) > 0 {
 		db.NewTx0(func(tx *db.Transaction) {
 			// Updating notification tracker for wallets below limit
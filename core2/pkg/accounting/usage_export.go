@@ -0,0 +1,577 @@
+package accounting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"ucloud.dk/core/pkg/coreutil"
+	accapi "ucloud.dk/shared/pkg/accounting"
+	db "ucloud.dk/shared/pkg/database"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Usage export
+// =====================================================================================================================
+// The usage export lists the usage charged to wallets, intended for invoicing. The data comes from the charge ledger
+// (see lInternalRecordCharge) which is persisted during the accounting scan. As a result, the export does not include
+// charges made since the last scan.
+//
+// Usage is reported per category and already includes the price of the product. If the provider attributes a charge
+// to a single product, then the price of that product is used to derive the quantity used and the unit price. The
+// current price is used, so a price change affects previous periods as well.
+//
+// The ledger was introduced after accounting started. Usage of consumption-based products charged before this is
+// backfilled (as a single daily entry without scope) from the usage reports by the accountingV8 migration. Usage of
+// capacity-based products from before the ledger is not part of the export.
+//
+// UCloud admins can export the usage of all wallets. Other users can export the usage of their own workspace and all
+// workspaces which have received an allocation from it (directly or indirectly). In projects, this requires the admin
+// role.
+
+func initUsageExport() {
+	accapi.UsageExport.Handler(func(info rpc.RequestInfo, request accapi.UsageExportRequest) (accapi.UsageExportResponse, *util.HttpError) {
+		return UsageExport(info.Actor, request)
+	})
+}
+
+type usageExportWallet struct {
+	Id          AccWalletId
+	Owner       string
+	Category    accapi.ProductCategory
+	Allocations []usageExportAllocation
+}
+
+type usageExportAllocation struct {
+	Id    accAllocId
+	Start time.Time
+	End   time.Time
+}
+
+type usageChargeRecord struct {
+	Wallet      AccWalletId
+	PeriodStart time.Time
+	Scope       string
+	Description string
+	Product     string
+	Usage       int64
+}
+
+type usageExportProduct struct {
+	Category accapi.ProductCategoryIdV2
+	Name     string
+}
+
+type usageExportWorkspace struct {
+	Title      string
+	CostCentre util.Option[string]
+}
+
+func UsageExport(actor rpc.Actor, request accapi.UsageExportRequest) (accapi.UsageExportResponse, *util.HttpError) {
+	start := request.Start.Time()
+	end := request.End.Time()
+	if !start.Before(end) {
+		return accapi.UsageExportResponse{}, util.HttpErr(http.StatusBadRequest, "start must occur before end")
+	}
+
+	if end.Sub(start) > 5*365*24*time.Hour {
+		return accapi.UsageExportResponse{}, util.HttpErr(http.StatusBadRequest, "the period is too long")
+	}
+
+	if request.Granularity == "" {
+		request.Granularity = accapi.UsageExportGranularityPeriod
+	}
+
+	if request.Format == "" {
+		request.Format = accapi.UsageExportFormatCsv
+	}
+
+	if !slices.Contains([]accapi.UsageExportGranularity{accapi.UsageExportGranularityDay,
+		accapi.UsageExportGranularityMonth, accapi.UsageExportGranularityPeriod}, request.Granularity) {
+		return accapi.UsageExportResponse{}, util.HttpErr(http.StatusBadRequest, "invalid granularity")
+	}
+
+	var wallets map[AccWalletId]usageExportWallet
+	if actor.Role == rpc.RoleAdmin {
+		wallets = usageExportWallets(util.OptNone[string]())
+	} else {
+		reference := actor.Username
+		if actor.Project.Present && actor.Project.Value != "" {
+			if !actor.Membership[actor.Project.Value].Satisfies(rpc.ProjectRoleAdmin) {
+				return accapi.UsageExportResponse{}, util.HttpErr(http.StatusForbidden, "You need admin privileges in your project to perform this action")
+			}
+			reference = string(actor.Project.Value)
+		}
+		wallets = usageExportWallets(util.OptValue(reference))
+	}
+
+	var walletIds []int64
+	for id := range wallets {
+		walletIds = append(walletIds, int64(id))
+	}
+
+	var records []usageChargeRecord
+	if len(walletIds) > 0 && !accGlobals.TestingEnabled {
+		records = usageExportLoadCharges(walletIds, start, end)
+	}
+
+	workspaces := usageExportLoadWorkspaces(wallets)
+	prices := usageExportLoadPrices(wallets)
+	rows := usageExportBuildRows(records, wallets, workspaces, prices, request.Granularity, start, end)
+
+	switch request.Format {
+	case accapi.UsageExportFormatCsv:
+		return accapi.UsageExportResponse{FileName: "usage.csv", Data: usageExportToCsv(rows)}, nil
+	case accapi.UsageExportFormatJsonl:
+		return accapi.UsageExportResponse{FileName: "usage.jsonl", Data: usageExportToJsonl(rows)}, nil
+	default:
+		return accapi.UsageExportResponse{}, util.HttpErr(http.StatusBadRequest, "invalid format")
+	}
+}
+
+// usageExportWallets returns the wallets visible to a workspace. If no workspace is specified then all wallets are
+// returned.
+func usageExportWallets(reference util.Option[string]) map[AccWalletId]usageExportWallet {
+	result := map[AccWalletId]usageExportWallet{}
+
+	var owner *internalOwner
+	if reference.Present {
+		owner = internalOwnerByReference(reference.Value)
+	}
+
+	accGlobals.Mu.RLock()
+	var buckets []*internalBucket
+	for _, b := range accGlobals.BucketsByCategory {
+		buckets = append(buckets, b)
+	}
+
+	ownerReferences := map[accOwnerId]string{}
+	for id, o := range accGlobals.OwnersById {
+		ownerReferences[id] = o.Reference
+	}
+	accGlobals.Mu.RUnlock()
+
+	for _, b := range buckets {
+		b.Mu.RLock()
+
+		var queue []AccWalletId
+		if owner == nil {
+			for id := range b.WalletsById {
+				queue = append(queue, id)
+			}
+		} else if w, ok := b.WalletsByOwner[owner.Id]; ok {
+			queue = append(queue, w.Id)
+
+			children := map[AccWalletId][]AccWalletId{}
+			for _, child := range b.WalletsById {
+				for parent := range child.AllocationsByParent {
+					children[parent] = append(children[parent], child.Id)
+				}
+			}
+
+			visited := map[AccWalletId]util.Empty{w.Id: {}}
+			for i := 0; i < len(queue); i++ {
+				for _, child := range children[queue[i]] {
+					if _, seen := visited[child]; !seen {
+						visited[child] = util.Empty{}
+						queue = append(queue, child)
+					}
+				}
+			}
+		}
+
+		for _, id := range queue {
+			w := b.WalletsById[id]
+			ownerReference, ok := ownerReferences[w.OwnedBy]
+			if !ok {
+				continue
+			}
+
+			exported := usageExportWallet{
+				Id:       w.Id,
+				Owner:    ownerReference,
+				Category: b.Category,
+			}
+
+			for _, group := range w.AllocationsByParent {
+				for allocId := range group.Allocations {
+					alloc := b.AllocationsById[allocId]
+					exported.Allocations = append(exported.Allocations, usageExportAllocation{
+						Id:    alloc.Id,
+						Start: alloc.Start,
+						End:   alloc.End,
+					})
+				}
+			}
+
+			slices.SortFunc(exported.Allocations, func(a, b usageExportAllocation) int {
+				return int(a.Id - b.Id)
+			})
+
+			result[w.Id] = exported
+		}
+
+		b.Mu.RUnlock()
+	}
+
+	return result
+}
+
+func usageExportLoadCharges(walletIds []int64, start time.Time, end time.Time) []usageChargeRecord {
+	type rowType struct {
+		WalletId    int64
+		PeriodStart time.Time
+		Scope       string
+		Description string
+		Product     string
+		Usage       int64
+	}
+
+	rows := db.NewTx(func(tx *db.Transaction) []rowType {
+		return db.Select[rowType](
+			tx,
+			`
+				select wallet_id, period_start, scope, description, product, usage
+				from accounting.usage_charges
+				where
+					wallet_id = some(cast(:wallets as int8[]))
+					and period_start >= :start
+					and period_start < :end
+		    `,
+			db.Params{
+				"wallets": walletIds,
+				"start":   util.StartOfDayUTC(start),
+				"end":     end,
+			},
+		)
+	})
+
+	var result []usageChargeRecord
+	for _, row := range rows {
+		result = append(result, usageChargeRecord{
+			Wallet:      AccWalletId(row.WalletId),
+			PeriodStart: row.PeriodStart,
+			Scope:       row.Scope,
+			Description: row.Description,
+			Product:     row.Product,
+			Usage:       row.Usage,
+		})
+	}
+	return result
+}
+
+func usageExportLoadWorkspaces(wallets map[AccWalletId]usageExportWallet) map[string]usageExportWorkspace {
+	result := map[string]usageExportWorkspace{}
+	var projects []string
+	for _, w := range wallets {
+		if _, ok := result[w.Owner]; ok {
+			continue
+		}
+
+		result[w.Owner] = usageExportWorkspace{Title: w.Owner}
+		if projectRegex.MatchString(w.Owner) {
+			projects = append(projects, w.Owner)
+		}
+	}
+
+	if len(projects) > 0 && !accGlobals.TestingEnabled {
+		db.NewTx0(func(tx *db.Transaction) {
+			for _, projectId := range projects {
+				project, ok := coreutil.ProjectRetrieveFromDatabase(tx, projectId)
+				if ok {
+					result[projectId] = usageExportWorkspace{
+						Title:      project.Specification.Title,
+						CostCentre: project.Status.Settings.CostCentre,
+					}
+				}
+			}
+		})
+	}
+
+	return result
+}
+
+// usageExportLoadPrices returns the current price of all products in the categories of the wallets.
+func usageExportLoadPrices(wallets map[AccWalletId]usageExportWallet) map[usageExportProduct]int64 {
+	categories := map[accapi.ProductCategoryIdV2]util.Empty{}
+	for _, w := range wallets {
+		categories[w.Category.ToId()] = util.Empty{}
+	}
+
+	result := map[usageExportProduct]int64{}
+	for category := range categories {
+		productsByProvider.Mu.RLock()
+		bucket, ok := productsByProvider.Buckets[category.Provider]
+		productsByProvider.Mu.RUnlock()
+
+		if !ok {
+			continue
+		}
+
+		bucket.Mu.RLock()
+		for _, p := range bucket.Products {
+			if p.Category.Name == category.Name {
+				result[usageExportProduct{Category: category, Name: p.Name}] = p.Price
+			}
+		}
+		bucket.Mu.RUnlock()
+	}
+	return result
+}
+
+func usageExportPeriod(granularity accapi.UsageExportGranularity, t time.Time, start time.Time, end time.Time) (time.Time, time.Time) {
+	var periodStart, periodEnd time.Time
+	switch granularity {
+	case accapi.UsageExportGranularityDay:
+		periodStart = util.StartOfDayUTC(t)
+		periodEnd = periodStart.AddDate(0, 0, 1)
+	case accapi.UsageExportGranularityMonth:
+		y, m, _ := t.UTC().Date()
+		periodStart = time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+		periodEnd = periodStart.AddDate(0, 1, 0)
+	default:
+		periodStart = start
+		periodEnd = end
+	}
+
+	if periodStart.Before(start) {
+		periodStart = start
+	}
+	if periodEnd.After(end) {
+		periodEnd = end
+	}
+	return periodStart, periodEnd
+}
+
+func usageExportBuildRows(
+	records []usageChargeRecord,
+	wallets map[AccWalletId]usageExportWallet,
+	workspaces map[string]usageExportWorkspace,
+	prices map[usageExportProduct]int64,
+	granularity accapi.UsageExportGranularity,
+	start time.Time,
+	end time.Time,
+) []accapi.UsageExportRow {
+	type rowKey struct {
+		Wallet      AccWalletId
+		PeriodStart time.Time
+		Scope       string
+		Description string
+		Product     string
+	}
+
+	rowsByKey := map[rowKey]*accapi.UsageExportRow{}
+	for _, record := range records {
+		w, ok := wallets[record.Wallet]
+		if !ok {
+			continue
+		}
+
+		periodStart, periodEnd := usageExportPeriod(granularity, record.PeriodStart, start, end)
+		key := rowKey{
+			Wallet:      record.Wallet,
+			PeriodStart: periodStart,
+			Scope:       record.Scope,
+			Description: record.Description,
+			Product:     record.Product,
+		}
+
+		row, ok := rowsByKey[key]
+		if !ok {
+			workspace := workspaces[w.Owner]
+			var owner accapi.WalletOwner
+			if projectRegex.MatchString(w.Owner) {
+				owner = accapi.WalletOwnerProject(w.Owner)
+			} else {
+				owner = accapi.WalletOwnerUser(w.Owner)
+			}
+
+			var allocations []string
+			for _, alloc := range w.Allocations {
+				if alloc.Start.Before(periodEnd) && alloc.End.After(periodStart) {
+					allocations = append(allocations, fmt.Sprint(alloc.Id))
+				}
+			}
+
+			row = &accapi.UsageExportRow{
+				PeriodStart:    fndapi.Timestamp(periodStart),
+				PeriodEnd:      fndapi.Timestamp(periodEnd),
+				Owner:          owner,
+				WorkspaceTitle: workspace.Title,
+				CostCentre:     workspace.CostCentre,
+				Category:       w.Category.ToId(),
+				Allocations:    util.NonNilSlice(allocations),
+				Scope:          util.OptStringIfNotEmpty(record.Scope),
+				Description:    util.OptStringIfNotEmpty(record.Description),
+				Product:        util.OptStringIfNotEmpty(record.Product),
+			}
+			rowsByKey[key] = row
+		}
+
+		row.Usage += record.Usage
+	}
+
+	var result []accapi.UsageExportRow
+	for key, row := range rowsByKey {
+		category := wallets[key.Wallet].Category
+		unit, factor := usageExportUnit(category)
+		row.Unit = unit
+		row.Amount = float64(row.Usage) * factor
+
+		if key.Product != "" {
+			price, ok := prices[usageExportProduct{Category: category.ToId(), Name: key.Product}]
+			if ok && price > 0 {
+				quantity, unitPrice := usageExportPricing(category, price, row.Usage)
+				row.Quantity.Set(quantity)
+				row.UnitPrice.Set(unitPrice)
+			}
+		}
+
+		result = append(result, *row)
+	}
+
+	slices.SortFunc(result, func(a, b accapi.UsageExportRow) int {
+		if c := strings.Compare(a.CostCentre.Value, b.CostCentre.Value); c != 0 {
+			return c
+		} else if c = strings.Compare(a.Owner.Reference(), b.Owner.Reference()); c != 0 {
+			return c
+		} else if c = strings.Compare(a.Category.Provider, b.Category.Provider); c != 0 {
+			return c
+		} else if c = strings.Compare(a.Category.Name, b.Category.Name); c != 0 {
+			return c
+		} else if c = a.PeriodStart.Time().Compare(b.PeriodStart.Time()); c != 0 {
+			return c
+		} else if c = strings.Compare(a.Scope.Value, b.Scope.Value); c != 0 {
+			return c
+		} else if c = strings.Compare(a.Description.Value, b.Description.Value); c != 0 {
+			return c
+		} else {
+			return strings.Compare(a.Product.Value, b.Product.Value)
+		}
+	})
+
+	return util.NonNilSlice(result)
+}
+
+// usageExportUnit returns the unit and conversion factor for a raw balance in a category. The conversion matches the
+// one used to display balances and product prices in the frontend, for example core-minutes are displayed as
+// core-hours.
+func usageExportUnit(category accapi.ProductCategory) (string, float64) {
+	unit := category.AccountingUnit
+	name := unit.NamePlural
+	factor := 1.0
+
+	if category.AccountingFrequency.IsPeriodic() {
+		desiredFrequency := usageExportDisplayFrequency(category)
+		if unit.DisplayFrequencySuffix {
+			factor = float64(category.AccountingFrequency.ToMinutes()) / float64(desiredFrequency.ToMinutes())
+			name = unit.Name
+			switch desiredFrequency {
+			case accapi.AccountingFrequencyPeriodicHour:
+				name += "-hours"
+			case accapi.AccountingFrequencyPeriodicDay:
+				name += "-days"
+			}
+		}
+	}
+
+	if unit.FloatingPoint {
+		factor /= 1000000
+	}
+
+	return name, factor
+}
+
+// usageExportDisplayFrequency returns the frequency used to display periodic usage. Compute is displayed per hour while
+// everything else is displayed per day.
+func usageExportDisplayFrequency(category accapi.ProductCategory) accapi.AccountingFrequency {
+	if category.ProductType == accapi.ProductTypeCompute {
+		return accapi.AccountingFrequencyPeriodicHour
+	}
+	return accapi.AccountingFrequencyPeriodicDay
+}
+
+// usageExportPricing derives the quantity of a product from the usage charged for it. The price of a product is
+// charged once per accounting period, the quantity is instead given per display period (see
+// usageExportDisplayFrequency). The unit price is given in the unit returned by usageExportUnit.
+func usageExportPricing(category accapi.ProductCategory, price int64, usage int64) (quantity float64, unitPrice float64) {
+	_, factor := usageExportUnit(category)
+
+	periodsPerDisplayPeriod := 1.0
+	if category.AccountingFrequency.IsPeriodic() {
+		periodsPerDisplayPeriod = float64(usageExportDisplayFrequency(category).ToMinutes()) /
+			float64(category.AccountingFrequency.ToMinutes())
+	}
+
+	quantity = float64(usage) / float64(price) / periodsPerDisplayPeriod
+	unitPrice = float64(price) * factor * periodsPerDisplayPeriod
+	return quantity, unitPrice
+}
+
+func usageExportToCsv(rows []accapi.UsageExportRow) string {
+	s := &strings.Builder{}
+	s.WriteString("period_start,period_end,workspace,workspace_title,cost_centre,provider,category,allocations,")
+	s.WriteString("scope,description,usage,amount,unit,product,quantity,unit_price\n")
+
+	for _, row := range rows {
+		columns := []string{
+			row.PeriodStart.Time().Format(time.RFC3339),
+			row.PeriodEnd.Time().Format(time.RFC3339),
+			usageExportEscapeFormula(row.Owner.Reference()),
+			usageExportEscapeFormula(row.WorkspaceTitle),
+			usageExportEscapeFormula(row.CostCentre.Value),
+			usageExportEscapeFormula(row.Category.Provider),
+			usageExportEscapeFormula(row.Category.Name),
+			strings.Join(row.Allocations, ";"),
+			usageExportEscapeFormula(row.Scope.Value),
+			usageExportEscapeFormula(row.Description.Value),
+			fmt.Sprint(row.Usage),
+			fmt.Sprintf("%.6f", row.Amount),
+			usageExportEscapeFormula(row.Unit),
+			usageExportEscapeFormula(row.Product.Value),
+			usageExportFormatOptional(row.Quantity),
+			usageExportFormatOptional(row.UnitPrice),
+		}
+
+		for i, column := range columns {
+			if i > 0 {
+				s.WriteRune(',')
+			}
+			grantsWriteQuotedString(s, column)
+		}
+		s.WriteRune('\n')
+	}
+
+	return s.String()
+}
+
+// usageExportEscapeFormula prevents spreadsheet applications from interpreting user-controlled text, such as
+// project titles, as a formula. Only text columns are escaped, numeric columns can legitimately start with a minus.
+func usageExportEscapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func usageExportToJsonl(rows []accapi.UsageExportRow) string {
+	s := &strings.Builder{}
+	for _, row := range rows {
+		data, _ := json.Marshal(row)
+		s.Write(data)
+		s.WriteRune('\n')
+	}
+	return s.String()
+}
+
+func usageExportFormatOptional(value util.Option[float64]) string {
+	if !value.Present {
+		return ""
+	}
+	return fmt.Sprintf("%.6f", value.Value)
+}
@@ -0,0 +1,230 @@
+package accounting
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	accapi "ucloud.dk/shared/pkg/accounting"
+	"ucloud.dk/shared/pkg/assert"
+	"ucloud.dk/shared/pkg/util"
+)
+
+func TestUsageExportChargeLedger(t *testing.T) {
+	e := newEnv(t, timeCategory)
+	e.AllocateEx(0, 0, 10, 1000, "user", "")
+
+	e.ReportDelta(0, "user", 10, "job-1")
+	e.ReportDelta(0, "user", 15, "job-1")
+	e.ReportDelta(0, "user", 5, "job-2")
+
+	w := e.Wallet(e.Owner("user"), e.Tm(0))
+	day := util.StartOfDayUTC(e.Tm(0))
+	assert.Equal(t, int64(25), e.Bucket.PendingCharges[internalChargeKey{Wallet: w, Day: day, Scope: "job-1"}])
+	assert.Equal(t, int64(5), e.Bucket.PendingCharges[internalChargeKey{Wallet: w, Day: day, Scope: "job-2"}])
+
+	// Capacity-based products record the latest usage level of the day
+	c := newEnv(t, capacityCategory)
+	c.AllocateEx(0, 0, 10, 1000, "user", "")
+	c.ReportAbs(0, "user", 100)
+	c.ReportAbs(0, "user", 40)
+
+	w = c.Wallet(c.Owner("user"), c.Tm(0))
+	assert.Equal(t, int64(40), c.Bucket.PendingCharges[internalChargeKey{Wallet: w, Day: day}])
+}
+
+func TestUsageExportWalletsIncludeDescendants(t *testing.T) {
+	e := newEnv(t, timeCategory)
+	e.AllocateEx(0, 0, 10, 1000, "root", "")
+	e.AllocateEx(0, 0, 10, 100, "department", "root")
+	e.AllocateEx(0, 0, 10, 10, "group", "department")
+	e.AllocateEx(0, 0, 10, 100, "other", "root")
+
+	wallets := usageExportWallets(util.OptValue("department"))
+
+	var owners []string
+	for _, w := range wallets {
+		owners = append(owners, w.Owner)
+	}
+	assert.Equal(t, 2, len(owners))
+	assert.True(t, slices.Contains(owners, "department"))
+	assert.True(t, slices.Contains(owners, "group"))
+
+	assert.Equal(t, 4, len(usageExportWallets(util.OptNone[string]())))
+}
+
+func TestUsageExportRows(t *testing.T) {
+	jan := time.Date(2024, time.January, 30, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC)
+
+	wallets := map[AccWalletId]usageExportWallet{
+		1: {
+			Id:       1,
+			Owner:    "user",
+			Category: timeCategory,
+			Allocations: []usageExportAllocation{
+				{Id: 10, Start: jan.AddDate(0, -1, 0), End: jan.AddDate(0, 0, 1)},
+				{Id: 11, Start: jan.AddDate(0, 0, 1), End: feb.AddDate(0, 1, 0)},
+			},
+		},
+	}
+
+	workspaces := map[string]usageExportWorkspace{
+		"user": {Title: "user", CostCentre: util.OptValue("Dept, of Physics")},
+	}
+
+	records := []usageChargeRecord{
+		{Wallet: 1, PeriodStart: jan, Scope: "job", Usage: 24},
+		{Wallet: 1, PeriodStart: jan.AddDate(0, 0, 1), Scope: "job", Usage: 24},
+		{Wallet: 1, PeriodStart: feb, Scope: "job", Usage: 48},
+		{Wallet: 2, PeriodStart: feb, Scope: "job", Usage: 1000},
+	}
+
+	start := jan.AddDate(0, 0, -10)
+	end := feb.AddDate(0, 0, 10)
+
+	rows := usageExportBuildRows(records, wallets, workspaces, nil, accapi.UsageExportGranularityMonth, start, end)
+	assert.Equal(t, 2, len(rows))
+	assert.Equal(t, int64(48), rows[0].Usage)
+	assert.True(t, rows[0].PeriodStart.Time().Equal(start))
+	assert.Equal(t, "10;11", strings.Join(rows[0].Allocations, ";"))
+	assert.Equal(t, "11", strings.Join(rows[1].Allocations, ";"))
+
+	// Core-hours are displayed as core-days for non-compute products
+	assert.Equal(t, "Core-days", rows[0].Unit)
+	assert.Equal(t, 2.0, rows[0].Amount)
+
+	rows = usageExportBuildRows(records, wallets, workspaces, nil, accapi.UsageExportGranularityPeriod, start, end)
+	assert.Equal(t, 1, len(rows))
+	assert.Equal(t, int64(96), rows[0].Usage)
+
+	csv := usageExportToCsv(rows)
+	lines := strings.Split(strings.TrimSpace(csv), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.Contains(lines[1], ",\"Dept, of Physics\",provider,compute,10;11,job,,96,4.000000,Core-days"))
+}
+
+func TestUsageExportCsvEscapesFormulas(t *testing.T) {
+	rows := []accapi.UsageExportRow{{
+		Owner:          accapi.WalletOwnerProject("project"),
+		WorkspaceTitle: "=HYPERLINK(\"http://example.com\")",
+		CostCentre:     util.OptValue("@SUM(A1:A2)"),
+		Category:       accapi.ProductCategoryIdV2{Name: "compute", Provider: "provider"},
+		Scope:          util.OptValue("+job"),
+		Description:    util.OptValue("-1+1"),
+		Usage:          -5,
+		Amount:         -0.5,
+		Unit:           "Core-hours",
+	}}
+
+	csv := usageExportToCsv(rows)
+	lines := strings.Split(strings.TrimSpace(csv), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.Contains(lines[1], ",project,\"'=HYPERLINK(\"\"http://example.com\"\")\",'@SUM(A1:A2),"))
+	assert.True(t, strings.Contains(lines[1], ",'+job,'-1+1,-5,-0.500000,Core-hours,"))
+}
+
+func TestUsageExportChargeLedgerRecordsProduct(t *testing.T) {
+	e := newEnv(t, timeCategory)
+	e.AllocateEx(0, 0, 10, 1000, "user", "")
+
+	for _, product := range []string{"small", "small", "large"} {
+		_, err := internalReportUsage(e.Tm(0), accapi.ReportUsageRequest{
+			IsDeltaCharge: true,
+			Owner:         e.Owner("user").WalletOwner(),
+			CategoryIdV2:  e.Bucket.Category.ToId(),
+			Usage:         10,
+			Description: accapi.ChargeDescription{
+				Scope:   util.OptValue("job-1"),
+				Product: util.OptValue(product),
+			},
+		})
+		assert.Nil(t, err)
+	}
+
+	w := e.Wallet(e.Owner("user"), e.Tm(0))
+	day := util.StartOfDayUTC(e.Tm(0))
+	assert.Equal(t, int64(20), e.Bucket.PendingCharges[internalChargeKey{Wallet: w, Day: day, Scope: "job-1", Product: "small"}])
+	assert.Equal(t, int64(10), e.Bucket.PendingCharges[internalChargeKey{Wallet: w, Day: day, Scope: "job-1", Product: "large"}])
+}
+
+func TestUsageExportLoadPrices(t *testing.T) {
+	resetProducts()
+	createTestProduct(accapi.ProductV2{Category: timeCategory, Name: "small", Price: 1})
+	createTestProduct(accapi.ProductV2{Category: timeCategory, Name: "large", Price: 4})
+	createTestProduct(accapi.ProductV2{Category: capacityCategory, Name: "storage", Price: 1})
+
+	prices := usageExportLoadPrices(map[AccWalletId]usageExportWallet{1: {Id: 1, Category: timeCategory}})
+	assert.Equal(t, 2, len(prices))
+	assert.Equal(t, int64(4), prices[usageExportProduct{Category: timeCategory.ToId(), Name: "large"}])
+}
+
+func TestUsageExportProductPricing(t *testing.T) {
+	jan := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	moneyCategory := accapi.ProductCategory{
+		Name:        "u1",
+		Provider:    "provider",
+		ProductType: accapi.ProductTypeCompute,
+		AccountingUnit: accapi.AccountingUnit{
+			Name:          "DKK",
+			NamePlural:    "DKK",
+			FloatingPoint: true,
+		},
+		AccountingFrequency: accapi.AccountingFrequencyPeriodicMinute,
+	}
+
+	wallets := map[AccWalletId]usageExportWallet{
+		1: {Id: 1, Owner: "user", Category: moneyCategory},
+		2: {Id: 2, Owner: "user", Category: timeCategory},
+	}
+
+	workspaces := map[string]usageExportWorkspace{"user": {Title: "user"}}
+
+	prices := map[usageExportProduct]int64{
+		{Category: moneyCategory.ToId(), Name: "u1-4"}: 2_000_000, // 2 DKK per minute
+		{Category: timeCategory.ToId(), Name: "large"}: 4,         // 4 core-hours per hour
+		{Category: timeCategory.ToId(), Name: "free"}:  0,
+	}
+
+	records := []usageChargeRecord{
+		{Wallet: 1, PeriodStart: jan, Scope: "job-1", Product: "u1-4", Usage: 200_000_000},
+		{Wallet: 1, PeriodStart: jan, Scope: "job-1", Product: "u1-4", Usage: 40_000_000},
+		{Wallet: 2, PeriodStart: jan, Product: "large", Usage: 96},
+		{Wallet: 2, PeriodStart: jan, Product: "free", Usage: 1},
+		{Wallet: 2, PeriodStart: jan, Product: "unknown", Usage: 2},
+		{Wallet: 2, PeriodStart: jan, Usage: 3},
+	}
+
+	rows := usageExportBuildRows(records, wallets, workspaces, prices, accapi.UsageExportGranularityPeriod, jan, feb)
+	rowsByProduct := map[string]accapi.UsageExportRow{}
+	for _, row := range rows {
+		rowsByProduct[row.Product.Value] = row
+	}
+	assert.Equal(t, 5, len(rowsByProduct))
+
+	// 240 DKK is 2 hours of a product which costs 120 DKK per hour
+	money := rowsByProduct["u1-4"]
+	assert.Equal(t, 240.0, money.Amount)
+	assert.Equal(t, "DKK", money.Unit)
+	assert.Equal(t, util.OptValue(2.0), money.Quantity)
+	assert.Equal(t, util.OptValue(120.0), money.UnitPrice)
+
+	// 4 core-days is 1 day of a product which costs 4 core-days per day
+	core := rowsByProduct["large"]
+	assert.Equal(t, 4.0, core.Amount)
+	assert.Equal(t, util.OptValue(1.0), core.Quantity)
+	assert.Equal(t, util.OptValue(4.0), core.UnitPrice)
+
+	// Quantities cannot be derived for free, unknown or missing products
+	for _, product := range []string{"free", "unknown", ""} {
+		assert.False(t, rowsByProduct[product].Quantity.Present)
+		assert.False(t, rowsByProduct[product].UnitPrice.Present)
+	}
+
+	csv := usageExportToCsv(rows)
+	assert.True(t, strings.Contains(csv, ",240.000000,DKK,u1-4,2.000000,120.000000\n"))
+	assert.True(t, strings.Contains(csv, ",3,0.125000,Core-days,,,\n"))
+}
@@ -0,0 +1,197 @@
+package accounting
+
+import (
+	"math"
+	"time"
+
+	accapi "ucloud.dk/shared/pkg/accounting"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Usage forecasting
+// =====================================================================================================================
+// Forecasts are made by fitting a straight line (least squares) through the most recent absolute usage samples. The
+// slope of the line is the projected usage per day. The confidence band is two standard errors of the slope on either
+// side of the projection, which corresponds roughly to a 95% interval when the residuals are normally distributed.
+//
+// Projections are always made from the latest known usage. This means that the forecast only uses the samples to
+// determine the rate of use and not the starting point.
+//
+// The same projection is used by usage reports and by the sub-project health computed in lSnapshotWallet.
+
+const (
+	usageForecastWindow     = 30 * 24 * time.Hour
+	usageForecastMinSamples = 3
+
+	// usageForecastHistoryLength is the number of daily samples kept per allocation group for sub-project health.
+	usageForecastHistoryLength = 42
+
+	usageForecastMaxHorizon = 100 * 365 * 24 * time.Hour
+)
+
+type internalUsageForecast struct {
+	SampleCount int
+
+	UsagePerDay     float64
+	UsagePerDayLow  float64
+	UsagePerDayHigh float64
+
+	DepletionAt         util.Option[time.Time]
+	DepletionAtEarliest util.Option[time.Time]
+	DepletionAtLatest   util.Option[time.Time]
+
+	End          util.Option[time.Time]
+	UsageAtEnd   int64
+	UsageAtEndLo int64
+	UsageAtEndHi int64
+}
+
+func (f *internalUsageForecast) ToApi() accapi.UsageReportForecast {
+	toTimestamp := func(t util.Option[time.Time]) util.Option[fndapi.Timestamp] {
+		if t.Present {
+			return util.OptValue(fndapi.Timestamp(t.Value))
+		} else {
+			return util.OptNone[fndapi.Timestamp]()
+		}
+	}
+
+	result := accapi.UsageReportForecast{
+		SampleCount:                f.SampleCount,
+		UsagePerDay:                f.UsagePerDay,
+		UsagePerDayLow:             f.UsagePerDayLow,
+		UsagePerDayHigh:            f.UsagePerDayHigh,
+		ProjectedDepletion:         toTimestamp(f.DepletionAt),
+		ProjectedDepletionEarliest: toTimestamp(f.DepletionAtEarliest),
+		ProjectedDepletionLatest:   toTimestamp(f.DepletionAtLatest),
+		AllocationEnd:              toTimestamp(f.End),
+	}
+
+	if f.End.Present {
+		result.ProjectedUsageAtEnd.Set(f.UsageAtEnd)
+		result.ProjectedUsageAtEndLow.Set(f.UsageAtEndLo)
+		result.ProjectedUsageAtEndHigh.Set(f.UsageAtEndHi)
+	}
+	return result
+}
+
+// usageForecast projects usage forward from now. The samples must be sorted by timestamp. Only samples within the
+// forecast window before now are used. No forecast is made if there are too few samples to determine a rate.
+func usageForecast(
+	samples []internalUsageOverTimeAbsoluteDataPoint,
+	now time.Time,
+	usage int64,
+	quota int64,
+	end util.Option[time.Time],
+) util.Option[internalUsageForecast] {
+	windowStart := now.Add(-usageForecastWindow)
+
+	var xs []float64
+	var ys []float64
+	var origin time.Time
+	for _, sample := range samples {
+		if sample.Timestamp.Before(windowStart) || sample.Timestamp.After(now) {
+			continue
+		}
+
+		if len(xs) == 0 {
+			origin = sample.Timestamp
+		}
+
+		xs = append(xs, sample.Timestamp.Sub(origin).Hours()/24)
+		ys = append(ys, float64(sample.Usage))
+	}
+
+	n := float64(len(xs))
+	if len(xs) < usageForecastMinSamples {
+		return util.OptNone[internalUsageForecast]()
+	}
+
+	meanX, meanY := 0.0, 0.0
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	sxx, sxy := 0.0, 0.0
+	for i := range xs {
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+		sxy += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+
+	if sxx == 0 {
+		return util.OptNone[internalUsageForecast]()
+	}
+
+	slope := sxy / sxx
+	intercept := meanY - slope*meanX
+
+	residuals := 0.0
+	for i := range xs {
+		r := ys[i] - (intercept + slope*xs[i])
+		residuals += r * r
+	}
+	standardError := math.Sqrt(residuals / (n - 2) / sxx)
+
+	result := internalUsageForecast{
+		SampleCount:     len(xs),
+		UsagePerDay:     slope,
+		UsagePerDayLow:  slope - 2*standardError,
+		UsagePerDayHigh: slope + 2*standardError,
+		End:             end,
+	}
+
+	result.DepletionAt = usageForecastDepletion(now, usage, quota, result.UsagePerDay)
+	result.DepletionAtEarliest = usageForecastDepletion(now, usage, quota, result.UsagePerDayHigh)
+	result.DepletionAtLatest = usageForecastDepletion(now, usage, quota, result.UsagePerDayLow)
+
+	if end.Present {
+		days := max(0, end.Value.Sub(now).Hours()/24)
+		project := func(rate float64) int64 {
+			return max(0, usage+int64(rate*days))
+		}
+
+		result.UsageAtEnd = project(result.UsagePerDay)
+		result.UsageAtEndLo = project(result.UsagePerDayLow)
+		result.UsageAtEndHi = project(result.UsagePerDayHigh)
+	}
+
+	return util.OptValue(result)
+}
+
+func usageForecastDepletion(now time.Time, usage int64, quota int64, usagePerDay float64) util.Option[time.Time] {
+	if quota <= 0 {
+		return util.OptNone[time.Time]()
+	} else if usage >= quota {
+		return util.OptValue(now)
+	} else if usagePerDay <= 0 {
+		return util.OptNone[time.Time]()
+	}
+
+	remaining := float64(quota-usage) / usagePerDay * float64(24*time.Hour)
+	if remaining >= float64(usageForecastMaxHorizon) {
+		return util.OptNone[time.Time]()
+	}
+
+	return util.OptValue(now.Add(time.Duration(remaining)))
+}
+
+// usageReportForecast makes a forecast for a (collapsed) report. The forecast is made from the end of the report.
+func usageReportForecast(report *internalUsageReport) util.Option[accapi.UsageReportForecast] {
+	now := report.ValidUntil.GetOrDefault(time.Now())
+	forecast := usageForecast(
+		report.UsageOverTime.Absolute,
+		now,
+		report.Kpis.TotalUsageAtEnd,
+		report.Kpis.QuotaAtEnd,
+		report.Kpis.NextMeaningfulExpiration,
+	)
+
+	if forecast.Present {
+		return util.OptValue(forecast.Value.ToApi())
+	} else {
+		return util.OptNone[accapi.UsageReportForecast]()
+	}
+}
@@ -0,0 +1,117 @@
+package accounting
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"ucloud.dk/shared/pkg/assert"
+	"ucloud.dk/shared/pkg/util"
+)
+
+func forecastSamples(start time.Time, usage ...int64) []internalUsageOverTimeAbsoluteDataPoint {
+	var result []internalUsageOverTimeAbsoluteDataPoint
+	for i, u := range usage {
+		result = append(result, internalUsageOverTimeAbsoluteDataPoint{
+			Timestamp: start.AddDate(0, 0, i),
+			Usage:     u,
+		})
+	}
+	return result
+}
+
+func forecastNear(expected, actual float64) bool {
+	return math.Abs(expected-actual) < 0.0001
+}
+
+func TestUsageForecastLinear(t *testing.T) {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	samples := forecastSamples(start, 0, 10, 20, 30, 40)
+	now := start.AddDate(0, 0, 4)
+	end := now.AddDate(0, 0, 10)
+
+	forecast := usageForecast(samples, now, 40, 100, util.OptValue(end))
+	assert.True(t, forecast.Present)
+
+	f := forecast.Value
+	assert.Equal(t, 5, f.SampleCount)
+	assert.True(t, forecastNear(10.0, f.UsagePerDay))
+	assert.True(t, forecastNear(10.0, f.UsagePerDayLow))
+	assert.True(t, forecastNear(10.0, f.UsagePerDayHigh))
+	assert.True(t, f.DepletionAt.Present)
+	assert.True(t, f.DepletionAt.Value.Equal(now.AddDate(0, 0, 6)))
+	assert.Equal(t, int64(140), f.UsageAtEnd)
+}
+
+func TestUsageForecastBand(t *testing.T) {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	samples := forecastSamples(start, 0, 15, 18, 35, 38, 52)
+	now := start.AddDate(0, 0, 5)
+	end := now.AddDate(0, 0, 30)
+
+	forecast := usageForecast(samples, now, 52, 200, util.OptValue(end))
+	assert.True(t, forecast.Present)
+
+	f := forecast.Value
+	assert.True(t, f.UsagePerDayLow < f.UsagePerDay)
+	assert.True(t, f.UsagePerDay < f.UsagePerDayHigh)
+	assert.True(t, f.DepletionAtEarliest.Value.Before(f.DepletionAt.Value))
+	assert.True(t, f.DepletionAt.Value.Before(f.DepletionAtLatest.Value))
+	assert.True(t, f.UsageAtEndLo < f.UsageAtEnd)
+	assert.True(t, f.UsageAtEnd < f.UsageAtEndHi)
+}
+
+func TestUsageForecastRequiresSamples(t *testing.T) {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	now := start.AddDate(0, 0, 1)
+	assert.False(t, usageForecast(forecastSamples(start, 0, 10), now, 10, 100, util.OptNone[time.Time]()).Present)
+
+	// Samples outside the window are not used
+	now = start.AddDate(0, 0, 60)
+	assert.False(t, usageForecast(forecastSamples(start, 0, 10, 20), now, 20, 100, util.OptNone[time.Time]()).Present)
+}
+
+func TestUsageForecastDepletion(t *testing.T) {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	now := start.AddDate(0, 0, 2)
+
+	depleted := usageForecast(forecastSamples(start, 50, 100, 150), now, 150, 100, util.OptNone[time.Time]())
+	assert.True(t, depleted.Value.DepletionAt.Present)
+	assert.True(t, depleted.Value.DepletionAt.Value.Equal(now))
+	assert.False(t, depleted.Value.End.Present)
+
+	idle := usageForecast(forecastSamples(start, 50, 50, 50), now, 50, 100, util.OptNone[time.Time]())
+	assert.True(t, idle.Present)
+	assert.False(t, idle.Value.DepletionAt.Present)
+}
+
+func TestUsageForecastSubProjectHealth(t *testing.T) {
+	e := newEnv(t, timeCategory)
+	initUsageReports()
+
+	e.AllocateEx(0, 0, 10, 1000, "root", "")
+	e.AllocateEx(0, 0, 10, 100, "fast", "root")
+	e.AllocateEx(0, 0, 10, 100, "slow", "root")
+
+	rootWallet := e.Wallet(e.Owner("root"), e.Tm(0))
+	health := func(ref string) internalGroupHealth {
+		w := e.Wallet(e.Owner(ref), e.Tm(0))
+		return reportGlobals.Snapshots[w].HealthByParent[rootWallet]
+	}
+
+	// Samples are taken daily. Usage in "fast" is on track to run out long before the allocation expires, while
+	// "slow" has stopped using its allocation.
+	day := 24 * time.Hour
+	for i := 0; i < 5; i++ {
+		now := e.Tm(0).Add(time.Duration(i) * day)
+		e.ReportDelta(0, "fast", 10)
+		if i == 0 {
+			e.ReportDelta(0, "slow", 10)
+		}
+		e.Scan(0)
+		usageSample(now)
+	}
+
+	assert.Equal(t, internalGroupHealthAtRisk, health("fast"))
+	assert.Equal(t, internalGroupHealthUnderUtilized, health("slow"))
+}
@@ -207,6 +207,7 @@ type internalWalletSnapshot struct {
 	QuotaByParentActive       map[AccWalletId]int64
 	QuotaByParentContributing map[AccWalletId]int64
 	HealthByParent            map[AccWalletId]internalGroupHealth
+	UsageHistoryByParent      map[AccWalletId][]internalUsageOverTimeAbsoluteDataPoint
 
 	NextMeaningfulExpiration util.Option[time.Time]
 }
@@ -313,6 +314,7 @@ func initUsageReports() {
 					// -----------------------------------------------------------------------------------------------------
 					report := usageCollapseReports(historicReports)
 					apiReport := report.ToApi()
+					apiReport.Forecast = usageReportForecast(&report)
 					apiReport.Title = w.PaysFor.Name
 					apiReport.ProductsCovered = []accapi.ProductCategoryIdV2{w.PaysFor.ToId()}
 					apiReport.UnitAndFrequency = accapi.AccountingUnitAndFrequency{
@@ -428,6 +430,7 @@ func initUsageReports() {
 
 				collapsed := usageCollapseReports(report.Reports)
 				apiReport := collapsed.ToApi()
+				apiReport.Forecast = usageReportForecast(&collapsed)
 				apiReport.Title = report.Title
 				apiReport.ProductsCovered = report.Products
 				apiReport.UnitAndFrequency = report.UnitAndFrequency
@@ -978,6 +981,7 @@ func lSnapshotWallet(now time.Time, b *internalBucket, w *internalWallet) intern
 			QuotaByParentActive:       map[AccWalletId]int64{},
 			QuotaByParentContributing: map[AccWalletId]int64{},
 			HealthByParent:            map[AccWalletId]internalGroupHealth{},
+			UsageHistoryByParent:      map[AccWalletId][]internalUsageOverTimeAbsoluteDataPoint{},
 			Category:                  b.Category,
 			NextMeaningfulExpiration:  util.OptNone[time.Time](),
 		}
@@ -996,6 +1000,7 @@ func lSnapshotWallet(now time.Time, b *internalBucket, w *internalWallet) intern
 		QuotaByParentActive:       map[AccWalletId]int64{},
 		QuotaByParentContributing: map[AccWalletId]int64{},
 		HealthByParent:            map[AccWalletId]internalGroupHealth{},
+		UsageHistoryByParent:      map[AccWalletId][]internalUsageOverTimeAbsoluteDataPoint{},
 		Category:                  b.Category,
 	}
 
@@ -1009,6 +1014,7 @@ func lSnapshotWallet(now time.Time, b *internalBucket, w *internalWallet) intern
 		current.QuotaByParentContributing[parent] = contributingQuota
 		current.QuotaByParentActive[parent] = activeQuota
 
+		groupEnd := util.OptNone[time.Time]()
 		for allocId := range group.Allocations {
 			alloc := b.AllocationsById[allocId]
 			if alloc.Active && alloc.Quota > minimumMeaningfulQuota {
@@ -1016,6 +1022,10 @@ func lSnapshotWallet(now time.Time, b *internalBucket, w *internalWallet) intern
 					earliestExpiration.Set(alloc.End)
 				}
 			}
+
+			if alloc.Active && (!groupEnd.Present || alloc.End.Before(groupEnd.Value)) {
+				groupEnd.Set(alloc.End)
+			}
 		}
 
 		// Determined expected usage (linear usage assumption)
@@ -1049,9 +1059,33 @@ func lSnapshotWallet(now time.Time, b *internalBucket, w *internalWallet) intern
 			}
 		}
 
-		if float64(activeUsage) >= float64(quotaIn30Days)*0.8 && quotaIn30Days < activeQuota {
+		// Snapshots are timestamped with the start of the day. Only the latest sample of a day is kept.
+		history := slices.Clone(prev.UsageHistoryByParent[parent])
+		if len(history) > 0 && !history[len(history)-1].Timestamp.Before(now) {
+			history = history[:len(history)-1]
+		}
+		history = append(history, internalUsageOverTimeAbsoluteDataPoint{
+			Timestamp: now,
+			Usage:     activeUsage,
+		})
+		if len(history) > usageForecastHistoryLength {
+			history = history[len(history)-usageForecastHistoryLength:]
+		}
+		current.UsageHistoryByParent[parent] = history
+
+		forecast := usageForecast(history, now, activeUsage, activeQuota, groupEnd)
+
+		if float64(activeUsage) >= float64(activeQuota)*0.9 {
 			health = internalGroupHealthAtRisk
-		} else if float64(activeUsage) >= float64(activeQuota)*0.9 {
+		} else if forecast.Present {
+			// Prefer the projection from recent usage over the linear usage assumption when enough samples exist
+			f := &forecast.Value
+			if f.DepletionAt.Present && groupEnd.Present && f.DepletionAt.Value.Before(groupEnd.Value) {
+				health = internalGroupHealthAtRisk
+			} else if groupEnd.Present && float64(f.UsageAtEnd) < float64(activeQuota)*0.5 {
+				health = internalGroupHealthUnderUtilized
+			}
+		} else if float64(activeUsage) >= float64(quotaIn30Days)*0.8 && quotaIn30Days < activeQuota {
 			health = internalGroupHealthAtRisk
 		} else if float64(activeUsage) < float64(totalExpectedUsage)*0.5 {
 			health = internalGroupHealthUnderUtilized
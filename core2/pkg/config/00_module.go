@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -45,7 +46,7 @@ type ConfigurationFormat struct {
 		PrivateKey        string
 	}
 
-	OpenIdConnect util.Option[OidcAuthentication]
+	OpenIdConnect []OidcAuthentication
 
 	Logs struct {
 		LogToConsole bool
@@ -91,12 +92,57 @@ type Database struct {
 }
 
 type OidcAuthentication struct {
-	IdpTitle     string
-	Profile      string
-	Issuer       string
-	ClientId     string
-	ClientSecret string
-	Scopes       []string
+	Name                string // stable identifier of the provider, identities are tied to this name
+	IdpTitle            string
+	LogoUrl             util.Option[string]
+	Profile             string
+	Issuer              string
+	ClientId            string
+	ClientSecret        string
+	Scopes              []string
+	Claims              OidcClaimsMapping
+	OrgId               util.Option[string] // overrides the organization claim if present
+	LinkByVerifiedEmail bool
+}
+
+// OidcClaimsMapping contains the names of the claims in the ID token which are mapped to the corresponding fields of
+// a principal. An empty name means that the field is not mapped.
+type OidcClaimsMapping struct {
+	Identity   string
+	FirstNames string
+	LastName   string
+	Email      string
+	OrgId      string
+}
+
+const (
+	OidcProfileWayf    = "WAYF"
+	OidcProfileEntra   = "Entra"
+	OidcProfileGeneric = "Generic"
+)
+
+var OidcProfileOptions = []string{OidcProfileWayf, OidcProfileEntra, OidcProfileGeneric}
+
+var oidcDefaultClaims = map[string]OidcClaimsMapping{
+	OidcProfileWayf: {
+		Identity:   "preferred_username",
+		FirstNames: "gn",
+		LastName:   "sn",
+		Email:      "email",
+		OrgId:      "homeOrganization",
+	},
+	OidcProfileEntra: {
+		// The identity is derived from the tid and oid claims unless a claim is explicitly configured
+		FirstNames: "given_name",
+		LastName:   "family_name",
+		Email:      "email",
+	},
+	OidcProfileGeneric: {
+		Identity:   "sub",
+		FirstNames: "given_name",
+		LastName:   "family_name",
+		Email:      "email",
+	},
 }
 
 type HostInfo struct {
@@ -334,11 +380,31 @@ func Parse(configDir string) bool {
 
 	oidc, _ := cfgutil.GetChildOrNil(filePath, document, "openIdConnect")
 	if oidc != nil {
-		ok, oidcConfig := parseOidcAuthentication(filePath, oidc)
-		if !ok {
-			success = false
+		if oidc.Kind == yaml.SequenceNode {
+			for _, providerNode := range oidc.Content {
+				ok, oidcConfig := parseOidcAuthentication(cfg, filePath, providerNode, "")
+				if !ok {
+					success = false
+					continue
+				}
+
+				for _, existing := range cfg.OpenIdConnect {
+					if existing.Name == oidcConfig.Name {
+						cfgutil.ReportError(filePath, providerNode, "An OIDC provider named '%s' already exists", oidcConfig.Name)
+						success = false
+					}
+				}
+
+				cfg.OpenIdConnect = append(cfg.OpenIdConnect, oidcConfig)
+			}
 		} else {
-			cfg.OpenIdConnect.Set(oidcConfig)
+			// Older configurations contain a single provider. This provider has always been stored as "wayf".
+			ok, oidcConfig := parseOidcAuthentication(cfg, filePath, oidc, "wayf")
+			if !ok {
+				success = false
+			} else {
+				cfg.OpenIdConnect = append(cfg.OpenIdConnect, oidcConfig)
+			}
 		}
 	}
 
@@ -359,25 +425,70 @@ func Parse(configDir string) bool {
 	return success
 }
 
-func parseOidcAuthentication(filePath string, node *yaml.Node) (bool, OidcAuthentication) {
+func parseOidcAuthentication(cfg *ConfigurationFormat, filePath string, node *yaml.Node, defaultName string) (bool, OidcAuthentication) {
 	var result OidcAuthentication
 	success := true
 
+	if defaultName != "" {
+		result.Name = defaultName
+	} else {
+		result.Name = cfgutil.RequireChildText(filePath, node, "name", &success)
+	}
+
 	result.Issuer = cfgutil.RequireChildText(filePath, node, "issuer", &success)
 	result.ClientId = cfgutil.RequireChildText(filePath, node, "clientId", &success)
 	result.ClientSecret = cfgutil.RequireChildText(filePath, node, "clientSecret", &success)
 	result.Profile = cfgutil.OptionalChildText(filePath, node, "profile", &success)
 	if result.Profile == "" {
-		result.Profile = "WAYF"
+		result.Profile = OidcProfileWayf
+	} else if !slices.Contains(OidcProfileOptions, result.Profile) {
+		cfgutil.ReportError(filePath, node, "Unknown OIDC profile '%s', expected one of %v", result.Profile, OidcProfileOptions)
+		success = false
 	}
 	result.IdpTitle = cfgutil.OptionalChildText(filePath, node, "title", &success)
 	if result.IdpTitle == "" {
-		result.IdpTitle = "WAYF"
+		if defaultName != "" {
+			result.IdpTitle = "WAYF"
+		} else {
+			result.IdpTitle = result.Name
+		}
+	}
+
+	logo := cfgutil.OptionalChildText(filePath, node, "logoImage", &success)
+	if logo != "" {
+		result.LogoUrl.Set(storeAndGenerateBrandingImageURI(cfg, logo))
 	}
 
 	if child, err := cfgutil.GetChildOrNil(filePath, node, "scopes"); child != nil && err == nil {
 		cfgutil.Decode(filePath, child, &result.Scopes, &success)
 	}
 
+	result.Claims = oidcDefaultClaims[result.Profile]
+	if claims, _ := cfgutil.GetChildOrNil(filePath, node, "claims"); claims != nil {
+		override := func(field *string, name string) {
+			if cfgutil.HasChild(claims, name) {
+				*field = cfgutil.OptionalChildText(filePath, claims, name, &success)
+			}
+		}
+
+		override(&result.Claims.Identity, "identity")
+		override(&result.Claims.FirstNames, "firstNames")
+		override(&result.Claims.LastName, "lastName")
+		override(&result.Claims.Email, "email")
+		override(&result.Claims.OrgId, "orgId")
+	}
+
+	if result.Claims.Identity == "" && result.Profile != OidcProfileEntra {
+		cfgutil.ReportError(filePath, node, "The OIDC provider '%s' must specify an identity claim", result.Name)
+		success = false
+	}
+
+	orgId := cfgutil.OptionalChildText(filePath, node, "orgId", &success)
+	if orgId != "" {
+		result.OrgId.Set(orgId)
+	}
+
+	result.LinkByVerifiedEmail, _ = cfgutil.OptionalChildBool(filePath, node, "linkByVerifiedEmail")
+
 	return success, result
 }
@@ -41,12 +41,13 @@ func ProjectRetrieveFromDatabase(tx *db.Transaction, id string) (fndapi.Project,
 		Pid                  int
 		ProviderProjectFor   sql.NullString
 		CanConsumeResources  bool
+		CostCentre           sql.NullString
 	}](
 		tx,
 		`
 			select
 				id, created_at, modified_at, title, archived, parent, subprojects_renameable as sub_projects_can_rename,
-				pid, provider_project_for, can_consume_resources
+				pid, provider_project_for, can_consume_resources, cost_centre
 			from
 				project.projects
 			where
@@ -79,6 +80,7 @@ func ProjectRetrieveFromDatabase(tx *db.Transaction, id string) (fndapi.Project,
 	}
 
 	p.Status.Settings.SubProjects.AllowRenaming = projectInfo.SubProjectsCanRename
+	p.Status.Settings.CostCentre = util.OptStringIfNotEmpty(projectInfo.CostCentre.String)
 
 	projectMembers := db.Select[struct {
 		Username string
@@ -26,6 +26,9 @@ func Init() {
 	initNotifications()
 	times["Notifications"] = t.Mark()
 
+	initWebhooks()
+	times["Webhooks"] = t.Mark()
+
 	initAuth()
 	times["Auth"] = t.Mark()
 
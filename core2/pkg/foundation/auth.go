@@ -192,10 +192,30 @@ func initAuth() {
 	})
 
 	fndapi.AuthMfaAnswerChallenge.Handler(func(info rpc.RequestInfo, request fndapi.MfaChallengeAnswer) (util.Empty, *util.HttpError) {
-		err := MfaAnswerChallenge(info.HttpRequest, info.HttpWriter, request.ChallengeId, request.VerificationCode)
+		err := MfaAnswerChallenge(info.HttpRequest, info.HttpWriter, request)
 		return util.Empty{}, err
 	})
 
+	fndapi.AuthMfaWebAuthnChallenge.Handler(func(info rpc.RequestInfo, request fndapi.MfaWebAuthnChallengeRequest) (fndapi.MfaWebAuthnChallengeResponse, *util.HttpError) {
+		return MfaWebAuthnChallenge(request.ChallengeId)
+	})
+
+	fndapi.AuthMfaBrowseWebAuthnCredentials.Handler(func(info rpc.RequestInfo, request util.Empty) (fndapi.BulkResponse[fndapi.MfaWebAuthnCredential], *util.HttpError) {
+		return fndapi.BulkResponse[fndapi.MfaWebAuthnCredential]{Responses: MfaWebAuthnBrowse(info.Actor)}, nil
+	})
+
+	fndapi.AuthMfaDeleteWebAuthnCredential.Handler(func(info rpc.RequestInfo, request fndapi.FindByStringId) (util.Empty, *util.HttpError) {
+		return util.Empty{}, MfaWebAuthnDelete(info.Actor, request.Id)
+	})
+
+	fndapi.AuthMfaBeginWebAuthnRegistration.Handler(func(info rpc.RequestInfo, request fndapi.MfaWebAuthnBeginRegistrationRequest) (fndapi.MfaWebAuthnBeginRegistrationResponse, *util.HttpError) {
+		return MfaWebAuthnBeginRegistration(info.Actor, request.Name)
+	})
+
+	fndapi.AuthMfaFinishWebAuthnRegistration.Handler(func(info rpc.RequestInfo, request fndapi.MfaWebAuthnFinishRegistrationRequest) (fndapi.FindByStringId, *util.HttpError) {
+		return MfaWebAuthnFinishRegistration(info.Actor, request)
+	})
+
 	fndapi.AuthMfaCreateCredentials.Handler(func(info rpc.RequestInfo, request util.Empty) (fndapi.MfaCredentials, *util.HttpError) {
 		return MfaCreateCredentials(info.Actor)
 	})
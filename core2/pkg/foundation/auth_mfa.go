@@ -155,10 +155,19 @@ func MfaIsConnectedEx(username string) bool {
 			tx,
 			`
 				select 1 as number
-				from auth.two_factor_credentials
 				where
-					principal_id = :username
-					and enforced = true
+					exists (
+						select 1
+						from auth.two_factor_credentials
+						where
+							principal_id = :username
+							and enforced = true
+					)
+					or exists (
+						select 1
+						from auth.webauthn_credentials
+						where principal_id = :username
+					)
 		    `,
 			db.Params{
 				"username": username,
@@ -175,7 +184,9 @@ type mfaAnswerResult struct {
 	Service authLoginService
 }
 
-func MfaAnswerChallenge(r *http.Request, w http.ResponseWriter, challengeId string, answer string) *util.HttpError {
+func MfaAnswerChallenge(r *http.Request, w http.ResponseWriter, request fndapi.MfaChallengeAnswer) *util.HttpError {
+	challengeId := request.ChallengeId
+
 	// TODO Might want another layer of rate limiting here just to be absolutely sure that this function is
 	//   rate-limited.
 	result, err := db.NewTx2(func(tx *db.Transaction) (mfaAnswerResult, *util.HttpError) {
@@ -195,25 +206,27 @@ func MfaAnswerChallenge(r *http.Request, w http.ResponseWriter, challengeId stri
 		row, ok := db.Get[struct {
 			Id               int
 			Enforced         bool
-			SharedSecret     string
+			SharedSecret     sql.NullString
 			PrincipalId      string
 			HasEnforcedCreds bool
 			Service          sql.NullString
+			WebauthnSession  sql.NullString
 		}](
 			tx,
 			`
 				select
-					cred.id,
-					cred.enforced,
+					coalesce(cred.id, -1) as id,
+					coalesce(cred.enforced, true) as enforced,
 					cred.shared_secret,
-					cred.principal_id,
+					coalesce(cred.principal_id, challenge.principal_id) as principal_id,
 					enforced_creds.id is not null has_enforced_creds,
-					challenge.service
+					challenge.service,
+					cast(challenge.webauthn_session as text) as webauthn_session
 				from
 					auth.two_factor_challenges challenge
-					join auth.two_factor_credentials cred on challenge.credentials_id = cred.id
+					left join auth.two_factor_credentials cred on challenge.credentials_id = cred.id
 					left join auth.two_factor_credentials enforced_creds on 
-						cred.principal_id = enforced_creds.principal_id 
+						coalesce(cred.principal_id, challenge.principal_id) = enforced_creds.principal_id 
 						and enforced_creds.enforced = true
 				where
 					challenge.challenge_id = :challenge_id
@@ -241,9 +254,23 @@ func MfaAnswerChallenge(r *http.Request, w http.ResponseWriter, challengeId stri
 			return result, util.HttpErr(http.StatusInternalServerError, "Internal error.")
 		}
 
-		ok = totp.Validate(answer, row.SharedSecret)
-		if !ok {
-			return result, util.HttpErr(http.StatusForbidden, "Invalid 2FA code. Try again.")
+		if len(request.WebAuthnResponse) > 0 {
+			if !row.Enforced {
+				return result, util.HttpErr(http.StatusBadRequest, "Enter the code from your authenticator app.")
+			}
+
+			if err := mfaWebAuthnVerify(tx, row.PrincipalId, row.WebauthnSession, request.WebAuthnResponse); err != nil {
+				return result, err
+			}
+		} else {
+			if !row.SharedSecret.Valid {
+				return result, util.HttpErr(http.StatusBadRequest, "No authenticator app is connected. Use your security key instead.")
+			}
+
+			ok = totp.Validate(request.VerificationCode, row.SharedSecret.String)
+			if !ok {
+				return result, util.HttpErr(http.StatusForbidden, "Invalid 2FA code. Try again.")
+			}
 		}
 
 		db.Exec(
@@ -307,19 +334,34 @@ func mfaCreateInternalChallenge(tx *db.Transaction, username string, credentials
 			with requested_credentials as (
 				select cast(:credentials_id as bigint) as id
 			)
-			insert into auth.two_factor_challenges(dtype, challenge_id, expires_at, credentials_id, service)
+			insert into auth.two_factor_challenges(dtype, challenge_id, expires_at, credentials_id, service, principal_id)
 			select
 				'LOGIN',
 				:challenge_id,
 				now() + cast('10 minutes' as interval),
 				cred.id,
-				nullif(cast(:service as text), '')
+				nullif(cast(:service as text), ''),
+				p.id
 			from
 				requested_credentials req
-				join auth.two_factor_credentials cred on cred.principal_id = :username
+				join auth.principals p on p.id = :username
+				left join auth.two_factor_credentials cred on
+					cred.principal_id = p.id
+					and (
+						(req.id >= 0 and req.id = cred.id)
+						or (cred.enforced = true)
+					)
 			where
-				(req.id >= 0 and req.id = cred.id)
-				or (cred.enforced = true)
+				cred.id is not null
+				or (
+					-- users with only WebAuthn credentials answer the challenge with one of those
+					req.id < 0
+					and exists (
+						select 1
+						from auth.webauthn_credentials wa
+						where wa.principal_id = p.id
+					)
+				)
 			returning challenge_id
 	    `,
 		db.Params{
@@ -20,57 +20,227 @@ import (
 )
 
 var oidcGlobals struct {
-	Provider *oidc.Provider
-	Config   oauth2.Config
-	Verifier *oidc.IDTokenVerifier
+	Providers []*oidcProvider
 
 	Mu       sync.Mutex
 	Sessions map[string]oidcAuthSession
 }
 
+type oidcProvider struct {
+	Id       int
+	Config   cfg.OidcAuthentication
+	Provider *oidc.Provider
+	OAuth    oauth2.Config
+	Verifier *oidc.IDTokenVerifier
+}
+
 type oidcAuthSession struct {
-	State   string
-	Nonce   string
-	Service authLoginService
+	State    string
+	Nonce    string
+	Service  authLoginService
+	Provider *oidcProvider
 }
 
-func initAuthOidc() {
-	configOpt := cfg.Configuration.OpenIdConnect
+// oidcRegisterProvider returns the ID of the identity provider, creating it if needed. Identities are tied to this ID,
+// which is why providers are looked up by their name and not by their position in the configuration.
+func oidcRegisterProvider(config cfg.OidcAuthentication) int {
+	return db.NewTx(func(tx *db.Transaction) int {
+		row, _ := db.Get[struct{ Id int }](
+			tx,
+			`
+				insert into auth.identity_providers(title, configuration) 
+				values (:name, jsonb_build_object('type', 'oidc', 'issuer', cast(:issuer as text)))
+				on conflict (title) do update set title = excluded.title
+				returning id
+		    `,
+			db.Params{
+				"name":   config.Name,
+				"issuer": config.Issuer,
+			},
+		)
+		return row.Id
+	})
+}
+
+// oidcDefaultProvider returns the provider used when a login is started without selecting a provider. This is the
+// case for the legacy login endpoint, which has always been used for WAYF.
+func oidcDefaultProvider() *oidcProvider {
+	g := &oidcGlobals
+	for _, p := range g.Providers {
+		if p.Config.Name == "wayf" {
+			return p
+		}
+	}
+	return g.Providers[0]
+}
+
+func oidcFindProvider(id int) (*oidcProvider, bool) {
+	if id == 0 {
+		return oidcDefaultProvider(), true
+	}
+
+	for _, p := range oidcGlobals.Providers {
+		if p.Id == id {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// oidcStringClaim returns the value of a string claim. An empty string is returned if the claim is not mapped, missing
+// or not a string.
+func oidcStringClaim(claims map[string]any, name string) string {
+	if name == "" {
+		return ""
+	}
+
+	value, _ := claims[name].(string)
+	return value
+}
+
+// oidcSessionCreate starts a new login session. The state is used to find the session again when the identity
+// provider redirects the user back, and the nonce binds the ID token to this session.
+func oidcSessionCreate(service authLoginService, provider *oidcProvider) oidcAuthSession {
+	g := &oidcGlobals
+	session := oidcAuthSession{
+		State:    util.RandomTokenNoTs(16),
+		Nonce:    util.RandomTokenNoTs(16),
+		Service:  service,
+		Provider: provider,
+	}
+
+	g.Mu.Lock()
+	g.Sessions[session.State] = session
+	g.Mu.Unlock()
+	return session
+}
+
+// oidcSessionConsume returns the session associated with a state. A session can only be consumed once.
+func oidcSessionConsume(state string) (oidcAuthSession, bool) {
+	g := &oidcGlobals
+	g.Mu.Lock()
+	session, ok := g.Sessions[state]
+	delete(g.Sessions, state)
+	g.Mu.Unlock()
+	return session, ok
+}
+
+// oidcVerifyIdToken verifies the signature, issuer, audience and expiry of an ID token and checks that it was issued
+// for the session. The claims of the token are returned.
+func oidcVerifyIdToken(ctx context.Context, session oidcAuthSession, rawIdToken string) (map[string]any, error) {
+	idToken, err := session.Provider.Verifier.Verify(ctx, rawIdToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Nonce == "" || session.Nonce != idToken.Nonce {
+		return nil, fmt.Errorf("nonce does not match the session")
+	}
 
-	if configOpt.Present {
-		config := configOpt.Value
+	var claims map[string]any
+	err = idToken.Claims(&claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve claims: %w", err)
+	}
+	return claims, nil
+}
+
+// oidcMapClaims maps the claims of a verified ID token to an IdpResponse according to the configuration of the
+// provider.
+func oidcMapClaims(provider *oidcProvider, claims map[string]any) IdpResponse {
+	config := provider.Config
+	mapping := config.Claims
+	response := IdpResponse{
+		Idp:        provider.Id,
+		Identity:   oidcStringClaim(claims, mapping.Identity),
+		FirstNames: util.OptStringIfNotEmpty(oidcStringClaim(claims, mapping.FirstNames)),
+		LastName:   util.OptStringIfNotEmpty(oidcStringClaim(claims, mapping.LastName)),
+		OrgId:      util.OptStringIfNotEmpty(oidcStringClaim(claims, mapping.OrgId)),
+		Email:      util.OptStringIfNotEmpty(oidcStringClaim(claims, mapping.Email)),
+	}
+
+	if config.Profile == cfg.OidcProfileEntra && mapping.Identity == "" {
+		// NOTE(Dan): For this to work, the client needs to be configured with optional claims (in Entra's UI)
+		// which allow these to be returned on the ID token (given_name, family_name and email).
+		response.Identity = fmt.Sprintf("%v-%v", oidcStringClaim(claims, "tid"), oidcStringClaim(claims, "oid"))
+	}
+
+	givenName := oidcStringClaim(claims, "given_name")
+	if !response.FirstNames.Present && givenName != "" {
+		response.FirstNames.Set(strings.Split(givenName, " ")[0])
+	}
+	if !response.LastName.Present && givenName != "" {
+		names := strings.Split(givenName, " ")
+		if len(names) > 1 {
+			response.LastName.Set(names[len(names)-1])
+		}
+	}
 
+	if !response.FirstNames.Present && response.Email.Present {
+		atIdx := strings.Index(response.Email.Value, "@")
+		if atIdx > 0 {
+			response.FirstNames.Set(response.Email.Value[:atIdx])
+		}
+	}
+
+	if config.OrgId.Present {
+		response.OrgId = config.OrgId
+	}
+
+	emailVerified, _ := claims["email_verified"].(bool)
+	response.LinkByEmail = config.LinkByVerifiedEmail && emailVerified && response.Email.Present
+	return response
+}
+
+func initAuthOidc() {
+	configs := cfg.Configuration.OpenIdConnect
+
+	if len(configs) > 0 {
 		g := &oidcGlobals
 		g.Sessions = map[string]oidcAuthSession{}
 		selfUrl := cfg.Configuration.SelfPublic.ToURL()
-		oidcProvider, err := oidc.NewProvider(context.Background(), config.Issuer)
-		if err != nil {
-			panic("Failed to connect to OIDC provider: " + err.Error())
-		}
 
-		g.Provider = oidcProvider
+		for _, config := range configs {
+			oidcProviderInfo, err := oidc.NewProvider(context.Background(), config.Issuer)
+			if err != nil {
+				panic(fmt.Sprintf("Failed to connect to OIDC provider (%s): %s", config.Name, err))
+			}
 
-		g.Config = oauth2.Config{
-			ClientID:     config.ClientId,
-			ClientSecret: config.ClientSecret,
-			Endpoint:     g.Provider.Endpoint(),
-			RedirectURL:  selfUrl + "/auth/oidc",
-			Scopes:       append([]string{oidc.ScopeOpenID, "profile", "email"}, config.Scopes...),
-		}
+			p := &oidcProvider{
+				Id:       oidcRegisterProvider(config),
+				Config:   config,
+				Provider: oidcProviderInfo,
+			}
+
+			p.OAuth = oauth2.Config{
+				ClientID:     config.ClientId,
+				ClientSecret: config.ClientSecret,
+				Endpoint:     p.Provider.Endpoint(),
+				RedirectURL:  selfUrl + "/auth/oidc",
+				Scopes:       append([]string{oidc.ScopeOpenID, "profile", "email"}, config.Scopes...),
+			}
 
-		g.Verifier = g.Provider.Verifier(&oidc.Config{ClientID: config.ClientId})
+			p.Verifier = p.Provider.Verifier(&oidc.Config{ClientID: config.ClientId})
+			g.Providers = append(g.Providers, p)
+		}
 
 		// -------------------------------------------------------------------------------------------------------------
 
 		fndapi.AuthBrowseIdentityProviders.Handler(func(info rpc.RequestInfo, request util.Empty) (fndapi.BulkResponse[fndapi.IdentityProvider], *util.HttpError) {
-			return fndapi.BulkResponse[fndapi.IdentityProvider]{
-				Responses: []fndapi.IdentityProvider{
-					{Id: 1, Title: config.IdpTitle},
-				},
-			}, nil
+			var result []fndapi.IdentityProvider
+			for _, p := range g.Providers {
+				result = append(result, fndapi.IdentityProvider{
+					Id:      p.Id,
+					Title:   p.Config.IdpTitle,
+					LogoUrl: p.Config.LogoUrl,
+				})
+			}
+
+			return fndapi.BulkResponse[fndapi.IdentityProvider]{Responses: result}, nil
 		})
 
-		startLogin := func(info rpc.RequestInfo, serviceName string) (util.Empty, *util.HttpError) {
+		startLogin := func(info rpc.RequestInfo, request fndapi.AuthStartLoginRequest) (util.Empty, *util.HttpError) {
+			serviceName := request.Service
 			if serviceName == "" {
 				serviceName = "web"
 			}
@@ -79,56 +249,51 @@ func initAuthOidc() {
 				return util.Empty{}, util.HttpErr(http.StatusBadRequest, "Unknown login service")
 			}
 
-			stateToken := util.RandomTokenNoTs(16)
-			nonce := util.RandomTokenNoTs(16)
-
-			g.Mu.Lock()
-			g.Sessions[stateToken] = oidcAuthSession{
-				State:   stateToken,
-				Nonce:   nonce,
-				Service: service,
+			provider, ok := oidcFindProvider(request.Id)
+			if !ok {
+				return util.Empty{}, util.HttpErr(http.StatusNotFound, "Unknown identity provider")
 			}
-			g.Mu.Unlock()
 
-			authCodeOptions := []oauth2.AuthCodeOption{oidc.Nonce(nonce)}
+			session := oidcSessionCreate(service, provider)
+
+			authCodeOptions := []oauth2.AuthCodeOption{oidc.Nonce(session.Nonce)}
 			if service.External {
 				authCodeOptions = append(authCodeOptions, oauth2.SetAuthURLParam("prompt", "login"))
 			}
-			redirectTo := g.Config.AuthCodeURL(stateToken, authCodeOptions...)
+			redirectTo := provider.OAuth.AuthCodeURL(session.State, authCodeOptions...)
 			http.Redirect(info.HttpWriter, info.HttpRequest, redirectTo, http.StatusFound)
 			return util.Empty{}, nil
 		}
 
 		fndapi.AuthStartLoginSamlLegacy.Handler(func(info rpc.RequestInfo, request fndapi.AuthStartLoginRequest) (util.Empty, *util.HttpError) {
-			return startLogin(info, request.Service)
+			return startLogin(info, request)
 		})
 
 		fndapi.AuthStartLogin.Handler(func(info rpc.RequestInfo, request fndapi.AuthStartLoginRequest) (util.Empty, *util.HttpError) {
-			return startLogin(info, request.Service)
+			return startLogin(info, request)
 		})
 
 		fndapi.AuthOidcCallback.Handler(func(info rpc.RequestInfo, request fndapi.AuthOidcCallbackRequest) (util.Empty, *util.HttpError) {
-			g.Mu.Lock()
-			session, ok := g.Sessions[request.State]
-			delete(g.Sessions, request.State)
-			g.Mu.Unlock()
-
+			session, ok := oidcSessionConsume(request.State)
 			if !ok {
 				return util.Empty{}, util.HttpErr(http.StatusBadRequest, "Failed to authenticate you")
 			}
 
+			provider := session.Provider
+			config := provider.Config
+
 			timeout, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
 
-			oauthToken, err := g.Config.Exchange(timeout, request.Code)
+			oauthToken, err := provider.OAuth.Exchange(timeout, request.Code)
 			if err != nil {
-				log.Warn("Failed to exchange token: %v", err)
+				log.Warn("Failed to exchange token (%s): %v", config.Name, err)
 				return util.Empty{}, util.HttpErr(http.StatusBadGateway, "Failed to authenticate you")
 			}
 
-			userInfo, err := g.Provider.UserInfo(timeout, oauth2.StaticTokenSource(oauthToken))
+			userInfo, err := provider.Provider.UserInfo(timeout, oauth2.StaticTokenSource(oauthToken))
 			if err != nil {
-				log.Warn("Failed to retrieve userinfo: %v", err)
+				log.Warn("Failed to retrieve userinfo (%s): %v", config.Name, err)
 				return util.Empty{}, util.HttpErr(http.StatusBadGateway, "Failed to authenticate you")
 			}
 
@@ -140,70 +305,13 @@ func initAuthOidc() {
 				return util.Empty{}, util.HttpErr(http.StatusBadGateway, "Failed to authenticate you")
 			}
 
-			idToken, err := g.Verifier.Verify(timeout, rawIdToken)
-			if err != nil {
-				log.Warn("Failed to verify id_token: %v", err)
-				return util.Empty{}, util.HttpErr(http.StatusBadGateway, "Failed to authenticate you")
-			}
-
-			if session.Nonce != idToken.Nonce {
-				return util.Empty{}, util.HttpErr(http.StatusBadGateway, "Failed to authenticate you")
-			}
-
-			var claimJson struct {
-				Email             string `json:"email"`
-				PreferredUsername string `json:"preferred_username"`
-				FirstNames        string `json:"gn"`
-				LastName          string `json:"sn"`
-				HomeOrganization  string `json:"homeOrganization"`
-				GivenName         string `json:"given_name"`
-				FamilyName        string `json:"family_name"`
-				Oid               string `json:"oid"`
-				Tid               string `json:"tid"`
-			}
-			err = idToken.Claims(&claimJson)
+			claims, err := oidcVerifyIdToken(timeout, session, rawIdToken)
 			if err != nil {
-				log.Warn("Failed to retrieve claims: %v", err)
+				log.Warn("Failed to verify id_token (%s): %v", config.Name, err)
 				return util.Empty{}, util.HttpErr(http.StatusBadGateway, "Failed to authenticate you")
 			}
 
-			response := IdpResponse{
-				Idp:        1,
-				Identity:   claimJson.PreferredUsername,
-				FirstNames: util.OptStringIfNotEmpty(claimJson.FirstNames),
-				LastName:   util.OptStringIfNotEmpty(claimJson.LastName),
-				OrgId:      util.OptStringIfNotEmpty(claimJson.HomeOrganization),
-				Email:      util.OptStringIfNotEmpty(claimJson.Email),
-			}
-
-			if !response.FirstNames.Present && claimJson.GivenName != "" {
-				response.FirstNames.Set(strings.Split(claimJson.GivenName, " ")[0])
-			}
-			if !response.LastName.Present && claimJson.GivenName != "" {
-				names := strings.Split(claimJson.GivenName, " ")
-				if len(names) > 1 {
-					response.LastName.Set(names[len(names)-1])
-				}
-			}
-
-			if !response.FirstNames.Present && response.Email.Present {
-				atIdx := strings.Index(response.Email.Value, "@")
-				if atIdx > 0 {
-					response.FirstNames.Set(response.Email.Value[:atIdx])
-				}
-			}
-
-			// TODO Clean this up
-			switch config.Profile {
-			case "Entra":
-				// NOTE(Dan): For this to work, the client needs to be configured with optional claims (in Entra's UI)
-				// which allow these to be returned on the ID token (given_name, family_name and email).
-				response.Identity = fmt.Sprintf("%v-%v", claimJson.Tid, claimJson.Oid)
-				response.FirstNames = util.OptValue(claimJson.GivenName)
-				response.LastName = util.OptValue(claimJson.FamilyName)
-
-			case "WAYF": // Nothing to do
-			}
+			response := oidcMapClaims(provider, claims)
 
 			principal, httpErr := PrincipalRetrieveOrCreateFromIdpResponse(response)
 
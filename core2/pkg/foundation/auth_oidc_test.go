@@ -0,0 +1,216 @@
+package foundation
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	cfg "ucloud.dk/core/pkg/config"
+	"ucloud.dk/shared/pkg/assert"
+	"ucloud.dk/shared/pkg/util"
+)
+
+const (
+	oidcTestIssuer   = "https://idp.example.com"
+	oidcTestClientId = "ucloud"
+)
+
+func oidcTestKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	return key
+}
+
+func oidcTestProvider(key *rsa.PrivateKey, config cfg.OidcAuthentication) *oidcProvider {
+	keySet := &oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{&key.PublicKey}}
+	return &oidcProvider{
+		Id:       42,
+		Config:   config,
+		Verifier: oidc.NewVerifier(oidcTestIssuer, keySet, &oidc.Config{ClientID: oidcTestClientId}),
+	}
+}
+
+// oidcTestToken creates an RS256 signed ID token. The standard claims are valid unless they are overridden.
+func oidcTestToken(t *testing.T, key *rsa.PrivateKey, nonce string, overrides map[string]any) string {
+	now := time.Now()
+	claims := map[string]any{
+		"iss":   oidcTestIssuer,
+		"aud":   oidcTestClientId,
+		"sub":   "subject",
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": nonce,
+	}
+	for k, v := range overrides {
+		claims[k] = v
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.Nil(t, err)
+	return signingInput + "." + enc.EncodeToString(signature)
+}
+
+func TestOidcSessionIsConsumedOnce(t *testing.T) {
+	oidcGlobals.Sessions = map[string]oidcAuthSession{}
+	provider := &oidcProvider{Id: 1}
+
+	session := oidcSessionCreate(authLoginService{Name: "web"}, provider)
+	other := oidcSessionCreate(authLoginService{Name: "web"}, provider)
+	assert.NotEqual(t, "", session.State)
+	assert.NotEqual(t, "", session.Nonce)
+	assert.NotEqual(t, session.State, other.State)
+	assert.NotEqual(t, session.Nonce, other.Nonce)
+
+	found, ok := oidcSessionConsume(session.State)
+	assert.True(t, ok)
+	assert.Equal(t, session.Nonce, found.Nonce)
+
+	_, ok = oidcSessionConsume(session.State)
+	assert.False(t, ok)
+
+	_, ok = oidcSessionConsume("unknown")
+	assert.False(t, ok)
+
+	_, ok = oidcSessionConsume(other.State)
+	assert.True(t, ok)
+}
+
+func TestOidcVerifyIdToken(t *testing.T) {
+	key := oidcTestKey(t)
+	provider := oidcTestProvider(key, cfg.OidcAuthentication{Name: "test"})
+	session := oidcAuthSession{State: "state", Nonce: "nonce", Provider: provider}
+	ctx := context.Background()
+
+	claims, err := oidcVerifyIdToken(ctx, session, oidcTestToken(t, key, "nonce", map[string]any{"email": "a@b.dk"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "a@b.dk", claims["email"])
+
+	rejected := map[string]string{
+		"wrong nonce":     oidcTestToken(t, key, "other", nil),
+		"missing nonce":   oidcTestToken(t, key, "", nil),
+		"wrong issuer":    oidcTestToken(t, key, "nonce", map[string]any{"iss": "https://evil.example.com"}),
+		"wrong audience":  oidcTestToken(t, key, "nonce", map[string]any{"aud": "someone-else"}),
+		"expired":         oidcTestToken(t, key, "nonce", map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}),
+		"wrong key":       oidcTestToken(t, oidcTestKey(t), "nonce", nil),
+		"not a jwt":       "garbage",
+		"unsigned header": base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + ".e30.",
+	}
+
+	for name, token := range rejected {
+		_, err := oidcVerifyIdToken(ctx, session, token)
+		if err == nil {
+			t.Errorf("%s: token was accepted", name)
+		}
+	}
+
+	// A session without a nonce must never match a token without a nonce
+	_, err = oidcVerifyIdToken(ctx, oidcAuthSession{Provider: provider}, oidcTestToken(t, key, "", nil))
+	assert.NotNil(t, err)
+}
+
+func TestOidcMapClaimsWayf(t *testing.T) {
+	provider := &oidcProvider{Id: 7, Config: cfg.OidcAuthentication{
+		Profile: cfg.OidcProfileWayf,
+		Claims: cfg.OidcClaimsMapping{
+			Identity:   "preferred_username",
+			FirstNames: "gn",
+			LastName:   "sn",
+			Email:      "email",
+			OrgId:      "homeOrganization",
+		},
+	}}
+
+	response := oidcMapClaims(provider, map[string]any{
+		"preferred_username": "alice@sdu.dk",
+		"gn":                 "Alice Marie",
+		"sn":                 "Jensen",
+		"email":              "alice@sdu.dk",
+		"homeOrganization":   "sdu.dk",
+		"email_verified":     true,
+	})
+
+	assert.Equal(t, 7, response.Idp)
+	assert.Equal(t, "alice@sdu.dk", response.Identity)
+	assert.Equal(t, util.OptValue("Alice Marie"), response.FirstNames)
+	assert.Equal(t, util.OptValue("Jensen"), response.LastName)
+	assert.Equal(t, util.OptValue("alice@sdu.dk"), response.Email)
+	assert.Equal(t, util.OptValue("sdu.dk"), response.OrgId)
+
+	// Linking by e-mail must be explicitly enabled
+	assert.False(t, response.LinkByEmail)
+}
+
+func TestOidcMapClaimsEntra(t *testing.T) {
+	provider := &oidcProvider{Id: 3, Config: cfg.OidcAuthentication{
+		Profile: cfg.OidcProfileEntra,
+		Claims: cfg.OidcClaimsMapping{
+			FirstNames: "given_name",
+			LastName:   "family_name",
+			Email:      "email",
+		},
+		OrgId: util.OptValue("example.com"),
+	}}
+
+	response := oidcMapClaims(provider, map[string]any{
+		"tid":              "tenant",
+		"oid":              "object",
+		"email":            "bob@example.com",
+		"homeOrganization": "ignored.dk",
+	})
+
+	assert.Equal(t, "tenant-object", response.Identity)
+	assert.Equal(t, util.OptValue("example.com"), response.OrgId)
+
+	// Without any name claims, the first name is derived from the e-mail
+	assert.Equal(t, util.OptValue("bob"), response.FirstNames)
+	assert.False(t, response.LastName.Present)
+}
+
+func TestOidcMapClaimsFallbacks(t *testing.T) {
+	provider := &oidcProvider{Id: 1, Config: cfg.OidcAuthentication{
+		Profile:             cfg.OidcProfileGeneric,
+		LinkByVerifiedEmail: true,
+		Claims: cfg.OidcClaimsMapping{
+			Identity:   "sub",
+			FirstNames: "first",
+			LastName:   "last",
+			Email:      "email",
+		},
+	}}
+
+	response := oidcMapClaims(provider, map[string]any{
+		"sub":            "subject",
+		"given_name":     "Carol Ann Smith",
+		"email":          "carol@example.com",
+		"email_verified": true,
+	})
+
+	assert.Equal(t, "subject", response.Identity)
+	assert.Equal(t, util.OptValue("Carol"), response.FirstNames)
+	assert.Equal(t, util.OptValue("Smith"), response.LastName)
+	assert.True(t, response.LinkByEmail)
+
+	// Claims of the wrong type are treated as missing and unverified e-mails are never linked
+	response = oidcMapClaims(provider, map[string]any{
+		"sub":            123,
+		"email":          "carol@example.com",
+		"email_verified": "true",
+	})
+
+	assert.Equal(t, "", response.Identity)
+	assert.Equal(t, util.OptValue("carol"), response.FirstNames)
+	assert.False(t, response.LinkByEmail)
+}
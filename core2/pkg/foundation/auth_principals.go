@@ -60,6 +60,10 @@ type IdpResponse struct {
 	LastName   util.Option[string]
 	OrgId      util.Option[string]
 	Email      util.Option[string]
+
+	// LinkByEmail allows the identity to be linked to an existing federated user with the same e-mail address. This
+	// must only be set if the identity provider has verified the e-mail address.
+	LinkByEmail bool
 }
 
 // Principal read API
@@ -84,7 +88,13 @@ func PrincipalRetrieve(tx *db.Transaction, username string) (Principal, bool) {
 	}](
 		tx,
 		`
-			select p.*, cred.id is not null as mfa_enabled
+			select
+				p.*,
+				cred.id is not null or exists (
+					select 1
+					from auth.webauthn_credentials wa
+					where wa.principal_id = p.id
+				) as mfa_enabled
 			from
 				auth.principals p
 				left join auth.two_factor_credentials cred on p.id = cred.principal_id and cred.enforced = true
@@ -294,6 +304,35 @@ func PrincipalRetrieveOrCreateFromIdpResponse(resp IdpResponse) (Principal, *uti
 
 		userId := userRow.AssociatedUser
 
+		if !ok && resp.LinkByEmail {
+			// The identity is not known, but the user might have logged in through a different identity provider.
+			// Only users who have previously authenticated through an identity provider are considered. This prevents
+			// an identity provider from taking over password-based accounts (e.g. service and admin accounts).
+			candidates := db.Select[struct{ Uid int }](
+				tx,
+				`
+					select p.uid
+					from auth.principals p
+					where
+						lower(p.email) = lower(:email)
+						and exists (
+							select 1
+							from auth.idp_auth_responses resp
+							where resp.associated_user = p.uid
+						)
+			    `,
+				db.Params{
+					"email": resp.Email.Value,
+				},
+			)
+
+			if len(candidates) == 1 {
+				userId = candidates[0].Uid
+				ok = true
+				principalRecordIdpResponse(tx, userId, resp)
+			}
+		}
+
 		if !ok {
 			caser := cases.Title(language.English)
 			normalizeName := func(s string) string {
@@ -360,22 +399,12 @@ func PrincipalRetrieveOrCreateFromIdpResponse(resp IdpResponse) (Principal, *uti
 				return Principal{}, err
 			}
 
-			db.Exec(
-				tx,
-				`
-					insert into auth.idp_auth_responses(associated_user, idp, idp_identity, first_names, last_name, organization_id, email) 
-					values (:uid, :idp, :username, :first_names, :last_name, :org_id, :email)
-			    `,
-				db.Params{
-					"uid":         uid,
-					"idp":         resp.Idp,
-					"username":    resp.Identity,
-					"first_names": spec.FirstNames.Sql(),
-					"last_name":   spec.LastName.Sql(),
-					"org_id":      spec.OrgId.Sql(),
-					"email":       spec.Email.Sql(),
-				},
-			)
+			// Record the information as it was stored on the principal
+			resp.FirstNames = spec.FirstNames
+			resp.LastName = spec.LastName
+			resp.OrgId = spec.OrgId
+			resp.Email = spec.Email
+			principalRecordIdpResponse(tx, uid, resp)
 
 			principal, _ := PrincipalRetrieve(tx, spec.Id)
 			return principal, nil
@@ -401,6 +430,25 @@ func PrincipalRetrieveOrCreateFromIdpResponse(resp IdpResponse) (Principal, *uti
 	})
 }
 
+func principalRecordIdpResponse(tx *db.Transaction, uid int, resp IdpResponse) {
+	db.Exec(
+		tx,
+		`
+			insert into auth.idp_auth_responses(associated_user, idp, idp_identity, first_names, last_name, organization_id, email) 
+			values (:uid, :idp, :username, :first_names, :last_name, :org_id, :email)
+	    `,
+		db.Params{
+			"uid":         uid,
+			"idp":         resp.Idp,
+			"username":    resp.Identity,
+			"first_names": resp.FirstNames.Sql(),
+			"last_name":   resp.LastName.Sql(),
+			"org_id":      resp.OrgId.Sql(),
+			"email":       resp.Email.Sql(),
+		},
+	)
+}
+
 func PrincipalUpdate(spec PrincipalSpecification) (int, *util.HttpError) {
 	return db.NewTx2(func(tx *db.Transaction) (int, *util.HttpError) {
 		return PrincipalCreateOrUpdate(tx, &spec, true, false)
@@ -0,0 +1,516 @@
+package foundation
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	cfg "ucloud.dk/core/pkg/config"
+	db "ucloud.dk/shared/pkg/database"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// WebAuthn credentials (security keys and passkeys) can be used as a second factor instead of, or in addition to, TOTP
+// credentials. A user can register multiple named credentials. Once a user has at least one credential, the MFA
+// challenge created during login can be answered using any of them.
+//
+// Registration ceremonies are short-lived and kept in memory. Authentication ceremonies are tied to the MFA challenge
+// and are stored along with it.
+
+const (
+	mfaWebAuthnMaxCredentials = 16
+	mfaWebAuthnMaxNameLength  = 64
+	mfaWebAuthnSessionTimeout = 10 * time.Minute
+)
+
+var webAuthnGlobals struct {
+	Once     sync.Once
+	Instance *webauthn.WebAuthn
+	Err      error
+
+	Mu       sync.Mutex
+	Sessions map[string]webAuthnRegistrationSession
+}
+
+type webAuthnRegistrationSession struct {
+	Username  string
+	Name      string
+	Session   webauthn.SessionData
+	ExpiresAt time.Time
+}
+
+type webAuthnUser struct {
+	Uid         int
+	Username    string
+	DisplayName string
+	Credentials []webauthn.Credential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(u.Uid))
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.Username
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.DisplayName
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.Credentials
+}
+
+func webAuthnInstance() (*webauthn.WebAuthn, *util.HttpError) {
+	g := &webAuthnGlobals
+	g.Once.Do(func() {
+		g.Sessions = map[string]webAuthnRegistrationSession{}
+
+		displayName := cfg.Configuration.Branding.DeploymentName
+		if displayName == "" {
+			displayName = "UCloud"
+		}
+
+		g.Instance, g.Err = webAuthnNewInstance(cfg.Configuration.SelfPublic.ToURL(), displayName)
+	})
+
+	if g.Err != nil {
+		log.Warn("WebAuthn is not available: %s", g.Err)
+		return nil, util.HttpErr(http.StatusInternalServerError, "Security keys are not available")
+	}
+	return g.Instance, nil
+}
+
+// webAuthnNewInstance creates a relying party which only accepts ceremonies performed on the given origin.
+func webAuthnNewInstance(origin string, displayName string) (*webauthn.WebAuthn, error) {
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return nil, err
+	}
+
+	return webauthn.New(&webauthn.Config{
+		RPID:          parsed.Hostname(),
+		RPDisplayName: displayName,
+		RPOrigins:     []string{origin},
+	})
+}
+
+// webAuthnSessionPut stores a registration ceremony and returns its ID. Expired ceremonies are removed at the same
+// time.
+func webAuthnSessionPut(username string, name string, session webauthn.SessionData) string {
+	sessionId := util.RandomTokenNoTs(32)
+	now := time.Now()
+
+	g := &webAuthnGlobals
+	g.Mu.Lock()
+	for id, s := range g.Sessions {
+		if now.After(s.ExpiresAt) {
+			delete(g.Sessions, id)
+		}
+	}
+
+	g.Sessions[sessionId] = webAuthnRegistrationSession{
+		Username:  username,
+		Name:      name,
+		Session:   session,
+		ExpiresAt: now.Add(mfaWebAuthnSessionTimeout),
+	}
+	g.Mu.Unlock()
+	return sessionId
+}
+
+// webAuthnSessionTake removes a registration ceremony and returns it if it belongs to the user and has not expired.
+func webAuthnSessionTake(username string, sessionId string) (webAuthnRegistrationSession, bool) {
+	g := &webAuthnGlobals
+	g.Mu.Lock()
+	session, ok := g.Sessions[sessionId]
+	delete(g.Sessions, sessionId)
+	g.Mu.Unlock()
+
+	if !ok || session.Username != username || time.Now().After(session.ExpiresAt) {
+		return webAuthnRegistrationSession{}, false
+	}
+	return session, true
+}
+
+// webAuthnCreateCredential verifies the response to a registration ceremony and returns the new credential.
+func webAuthnCreateCredential(
+	wa *webauthn.WebAuthn,
+	user *webAuthnUser,
+	session webauthn.SessionData,
+	response json.RawMessage,
+) (*webauthn.Credential, *util.HttpError) {
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, util.HttpErr(http.StatusBadRequest, "Invalid response from security key")
+	}
+
+	credential, err := wa.CreateCredential(user, session, parsed)
+	if err != nil {
+		log.Info("WebAuthn registration failed for %s: %s", user.Username, err)
+		return nil, util.HttpErr(http.StatusBadRequest, "The security key could not be verified")
+	}
+	return credential, nil
+}
+
+// webAuthnBeginAssertion starts an authentication ceremony. The options are sent to the browser while the session is
+// stored with the MFA challenge.
+func webAuthnBeginAssertion(wa *webauthn.WebAuthn, user *webAuthnUser) (json.RawMessage, string, *util.HttpError) {
+	assertion, session, err := wa.BeginLogin(user)
+	if err != nil {
+		log.Warn("Failed to begin WebAuthn login: %s", err)
+		return nil, "", util.HttpErr(http.StatusInternalServerError, "Internal error")
+	}
+
+	options, _ := json.Marshal(assertion)
+	sessionJson, _ := json.Marshal(session)
+	return options, string(sessionJson), nil
+}
+
+// webAuthnValidateAssertion verifies an assertion against the session stored with the MFA challenge. Credentials which
+// appear to have been cloned are rejected.
+func webAuthnValidateAssertion(
+	wa *webauthn.WebAuthn,
+	user *webAuthnUser,
+	sessionJson sql.NullString,
+	response json.RawMessage,
+) (*webauthn.Credential, *util.HttpError) {
+	if !sessionJson.Valid {
+		return nil, util.HttpErr(http.StatusBadRequest, "Challenge expired. Try reloading the page.")
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal([]byte(sessionJson.String), &session); err != nil {
+		return nil, util.HttpErr(http.StatusInternalServerError, "Internal error.")
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, util.HttpErr(http.StatusBadRequest, "Invalid response from security key")
+	}
+
+	credential, err := wa.ValidateLogin(user, session, parsed)
+	if err != nil {
+		log.Info("WebAuthn login failed for %s: %s", user.Username, err)
+		return nil, util.HttpErr(http.StatusForbidden, "Invalid security key. Try again.")
+	}
+
+	if credential.Authenticator.CloneWarning {
+		log.Warn("WebAuthn credential of %s might have been cloned, rejecting login", user.Username)
+		return nil, util.HttpErr(http.StatusForbidden, "Invalid security key. Try again.")
+	}
+
+	return credential, nil
+}
+
+func webAuthnLoadUser(tx *db.Transaction, username string) (webAuthnUser, bool) {
+	principal, ok := PrincipalRetrieve(tx, username)
+	if !ok {
+		return webAuthnUser{}, false
+	}
+
+	displayName := strings.TrimSpace(principal.FirstNames.GetOrDefault("") + " " + principal.LastName.GetOrDefault(""))
+	if displayName == "" {
+		displayName = username
+	}
+
+	result := webAuthnUser{
+		Uid:         principal.Uid,
+		Username:    principal.Id,
+		DisplayName: displayName,
+	}
+
+	rows := db.Select[struct{ Credential string }](
+		tx,
+		`
+			select cast(credential as text) as credential
+			from auth.webauthn_credentials
+			where principal_id = :username
+			order by id
+	    `,
+		db.Params{
+			"username": username,
+		},
+	)
+
+	for _, row := range rows {
+		var credential webauthn.Credential
+		if err := json.Unmarshal([]byte(row.Credential), &credential); err != nil {
+			log.Warn("Could not parse WebAuthn credential of %s: %s", username, err)
+			continue
+		}
+		result.Credentials = append(result.Credentials, credential)
+	}
+
+	return result, true
+}
+
+func MfaWebAuthnBrowse(actor rpc.Actor) []fndapi.MfaWebAuthnCredential {
+	return db.NewTx(func(tx *db.Transaction) []fndapi.MfaWebAuthnCredential {
+		rows := db.Select[struct {
+			Id         int
+			Name       string
+			CreatedAt  time.Time
+			LastUsedAt sql.NullTime
+		}](
+			tx,
+			`
+				select id, name, created_at, last_used_at
+				from auth.webauthn_credentials
+				where principal_id = :username
+				order by id
+		    `,
+			db.Params{
+				"username": actor.Username,
+			},
+		)
+
+		var result []fndapi.MfaWebAuthnCredential
+		for _, row := range rows {
+			item := fndapi.MfaWebAuthnCredential{
+				Id:        fmt.Sprint(row.Id),
+				Name:      row.Name,
+				CreatedAt: fndapi.Timestamp(row.CreatedAt),
+			}
+
+			if row.LastUsedAt.Valid {
+				item.LastUsedAt.Set(fndapi.Timestamp(row.LastUsedAt.Time))
+			}
+
+			result = append(result, item)
+		}
+		return result
+	})
+}
+
+func MfaWebAuthnDelete(actor rpc.Actor, id string) *util.HttpError {
+	deleted := db.NewTx(func(tx *db.Transaction) bool {
+		_, ok := db.Get[struct{ Id int }](
+			tx,
+			`
+				delete from auth.webauthn_credentials
+				where
+					principal_id = :username
+					and cast(id as text) = :id
+				returning id
+		    `,
+			db.Params{
+				"username": actor.Username,
+				"id":       id,
+			},
+		)
+		return ok
+	})
+
+	if !deleted {
+		return util.HttpErr(http.StatusNotFound, "Unknown security key")
+	}
+	return nil
+}
+
+func MfaWebAuthnBeginRegistration(actor rpc.Actor, name string) (fndapi.MfaWebAuthnBeginRegistrationResponse, *util.HttpError) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fndapi.MfaWebAuthnBeginRegistrationResponse{}, util.HttpErr(http.StatusBadRequest, "The security key must have a name")
+	} else if len(name) > mfaWebAuthnMaxNameLength {
+		return fndapi.MfaWebAuthnBeginRegistrationResponse{}, util.HttpErr(http.StatusBadRequest, "The name of the security key is too long")
+	}
+
+	wa, httpErr := webAuthnInstance()
+	if httpErr != nil {
+		return fndapi.MfaWebAuthnBeginRegistrationResponse{}, httpErr
+	}
+
+	user, ok := db.NewTx2(func(tx *db.Transaction) (webAuthnUser, bool) {
+		return webAuthnLoadUser(tx, actor.Username)
+	})
+
+	if !ok {
+		return fndapi.MfaWebAuthnBeginRegistrationResponse{}, util.HttpErr(http.StatusForbidden, "Forbidden")
+	} else if len(user.Credentials) >= mfaWebAuthnMaxCredentials {
+		return fndapi.MfaWebAuthnBeginRegistrationResponse{}, util.HttpErr(
+			http.StatusBadRequest,
+			"You cannot have more than %d security keys",
+			mfaWebAuthnMaxCredentials,
+		)
+	}
+
+	creation, session, err := wa.BeginRegistration(
+		&user,
+		webauthn.WithExclusions(webauthn.Credentials(user.Credentials).CredentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+
+	if err != nil {
+		log.Warn("Failed to begin WebAuthn registration: %s", err)
+		return fndapi.MfaWebAuthnBeginRegistrationResponse{}, util.HttpErr(http.StatusInternalServerError, "Internal error")
+	}
+
+	options, err := json.Marshal(creation)
+	if err != nil {
+		return fndapi.MfaWebAuthnBeginRegistrationResponse{}, util.HttpErr(http.StatusInternalServerError, "Internal error")
+	}
+
+	sessionId := webAuthnSessionPut(actor.Username, name, *session)
+
+	return fndapi.MfaWebAuthnBeginRegistrationResponse{
+		SessionId: sessionId,
+		Options:   options,
+	}, nil
+}
+
+func MfaWebAuthnFinishRegistration(actor rpc.Actor, request fndapi.MfaWebAuthnFinishRegistrationRequest) (fndapi.FindByStringId, *util.HttpError) {
+	wa, httpErr := webAuthnInstance()
+	if httpErr != nil {
+		return fndapi.FindByStringId{}, httpErr
+	}
+
+	session, ok := webAuthnSessionTake(actor.Username, request.SessionId)
+	if !ok {
+		return fndapi.FindByStringId{}, util.HttpErr(http.StatusNotFound, "Registration expired. Try again.")
+	}
+
+	return db.NewTx2(func(tx *db.Transaction) (fndapi.FindByStringId, *util.HttpError) {
+		user, ok := webAuthnLoadUser(tx, actor.Username)
+		if !ok {
+			return fndapi.FindByStringId{}, util.HttpErr(http.StatusForbidden, "Forbidden")
+		}
+
+		credential, httpErr := webAuthnCreateCredential(wa, &user, session.Session, request.Credential)
+		if httpErr != nil {
+			return fndapi.FindByStringId{}, httpErr
+		}
+
+		credentialJson, _ := json.Marshal(credential)
+		row, ok := db.Get[struct{ Id int }](
+			tx,
+			`
+				insert into auth.webauthn_credentials(principal_id, name, credential_id, credential)
+				values (:username, :name, :credential_id, cast(:credential as jsonb))
+				on conflict (credential_id) do nothing
+				returning id
+		    `,
+			db.Params{
+				"username":      actor.Username,
+				"name":          session.Name,
+				"credential_id": credential.ID,
+				"credential":    string(credentialJson),
+			},
+		)
+
+		if !ok {
+			return fndapi.FindByStringId{}, util.HttpErr(http.StatusConflict, "This security key has already been registered")
+		}
+
+		return fndapi.FindByStringId{Id: fmt.Sprint(row.Id)}, nil
+	})
+}
+
+// MfaWebAuthnChallenge starts the authentication ceremony for an existing MFA challenge. The session data is stored
+// with the challenge, such that the assertion can be verified when the challenge is answered.
+func MfaWebAuthnChallenge(challengeId string) (fndapi.MfaWebAuthnChallengeResponse, *util.HttpError) {
+	wa, httpErr := webAuthnInstance()
+	if httpErr != nil {
+		return fndapi.MfaWebAuthnChallengeResponse{}, httpErr
+	}
+
+	return db.NewTx2(func(tx *db.Transaction) (fndapi.MfaWebAuthnChallengeResponse, *util.HttpError) {
+		row, ok := db.Get[struct{ PrincipalId string }](
+			tx,
+			`
+				select coalesce(cred.principal_id, challenge.principal_id) as principal_id
+				from
+					auth.two_factor_challenges challenge
+					left join auth.two_factor_credentials cred on challenge.credentials_id = cred.id
+				where
+					challenge.challenge_id = :challenge_id
+					and now() < challenge.expires_at
+					and (cred.id is null or cred.enforced = true)
+		    `,
+			db.Params{
+				"challenge_id": challengeId,
+			},
+		)
+
+		if !ok {
+			return fndapi.MfaWebAuthnChallengeResponse{}, util.HttpErr(http.StatusNotFound, "Challenge expired. Try reloading the page.")
+		}
+
+		user, ok := webAuthnLoadUser(tx, row.PrincipalId)
+		if !ok || len(user.Credentials) == 0 {
+			return fndapi.MfaWebAuthnChallengeResponse{}, util.HttpErr(http.StatusNotFound, "No security keys have been registered on this account.")
+		}
+
+		options, sessionJson, httpErr := webAuthnBeginAssertion(wa, &user)
+		if httpErr != nil {
+			return fndapi.MfaWebAuthnChallengeResponse{}, httpErr
+		}
+
+		db.Exec(
+			tx,
+			`
+				update auth.two_factor_challenges
+				set webauthn_session = cast(:session as jsonb)
+				where challenge_id = :challenge_id
+		    `,
+			db.Params{
+				"challenge_id": challengeId,
+				"session":      sessionJson,
+			},
+		)
+
+		return fndapi.MfaWebAuthnChallengeResponse{Options: options}, nil
+	})
+}
+
+// mfaWebAuthnVerify verifies an assertion which was produced in response to MfaWebAuthnChallenge. The counters of the
+// credential are updated on success.
+func mfaWebAuthnVerify(tx *db.Transaction, username string, sessionJson sql.NullString, response json.RawMessage) *util.HttpError {
+	wa, httpErr := webAuthnInstance()
+	if httpErr != nil {
+		return httpErr
+	}
+
+	user, ok := webAuthnLoadUser(tx, username)
+	if !ok {
+		return util.HttpErr(http.StatusInternalServerError, "Internal error.")
+	}
+
+	credential, httpErr := webAuthnValidateAssertion(wa, &user, sessionJson, response)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	credentialJson, _ := json.Marshal(credential)
+	db.Exec(
+		tx,
+		`
+			update auth.webauthn_credentials
+			set
+				credential = cast(:credential as jsonb),
+				last_used_at = now()
+			where
+				principal_id = :username
+				and credential_id = :credential_id
+	    `,
+		db.Params{
+			"username":      username,
+			"credential_id": credential.ID,
+			"credential":    string(credentialJson),
+		},
+	)
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package foundation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"ucloud.dk/shared/pkg/assert"
+)
+
+const webAuthnTestOrigin = "https://cloud.example.com"
+
+// webAuthnTestAuthenticator is a software security key which produces "none" attestations and ES256 assertions.
+type webAuthnTestAuthenticator struct {
+	Key          *ecdsa.PrivateKey
+	CredentialId []byte
+	RpId         string
+	Origin       string
+	Counter      uint32
+}
+
+func newWebAuthnTestAuthenticator(t *testing.T) *webAuthnTestAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	credentialId := make([]byte, 16)
+	_, _ = rand.Read(credentialId)
+
+	return &webAuthnTestAuthenticator{
+		Key:          key,
+		CredentialId: credentialId,
+		RpId:         "cloud.example.com",
+		Origin:       webAuthnTestOrigin,
+	}
+}
+
+func (a *webAuthnTestAuthenticator) authData(attested bool) []byte {
+	rpIdHash := sha256.Sum256([]byte(a.RpId))
+	result := append([]byte{}, rpIdHash[:]...)
+
+	flags := byte(0x01) // user present
+	if attested {
+		flags |= 0x40
+	}
+	result = append(result, flags)
+	result = binary.BigEndian.AppendUint32(result, a.Counter)
+
+	if attested {
+		publicKey, _ := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+			PublicKeyData: webauthncose.PublicKeyData{
+				KeyType:   int64(webauthncose.EllipticKey),
+				Algorithm: int64(webauthncose.AlgES256),
+			},
+			Curve:  int64(webauthncose.P256),
+			XCoord: a.Key.PublicKey.X.FillBytes(make([]byte, 32)),
+			YCoord: a.Key.PublicKey.Y.FillBytes(make([]byte, 32)),
+		})
+
+		result = append(result, make([]byte, 16)...) // AAGUID
+		result = binary.BigEndian.AppendUint16(result, uint16(len(a.CredentialId)))
+		result = append(result, a.CredentialId...)
+		result = append(result, publicKey...)
+	}
+	return result
+}
+
+func (a *webAuthnTestAuthenticator) clientData(ceremony string, challenge string) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge,
+		"origin":    a.Origin,
+	})
+	return data
+}
+
+func (a *webAuthnTestAuthenticator) Register(t *testing.T, challenge string) json.RawMessage {
+	attestation, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": a.authData(true),
+	})
+	assert.Nil(t, err)
+
+	enc := base64.RawURLEncoding
+	response, _ := json.Marshal(map[string]any{
+		"id":    enc.EncodeToString(a.CredentialId),
+		"rawId": enc.EncodeToString(a.CredentialId),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    enc.EncodeToString(a.clientData("webauthn.create", challenge)),
+			"attestationObject": enc.EncodeToString(attestation),
+		},
+	})
+	return response
+}
+
+func (a *webAuthnTestAuthenticator) Assert(t *testing.T, challenge string, userHandle []byte) json.RawMessage {
+	authData := a.authData(false)
+	clientData := a.clientData("webauthn.get", challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, a.Key, digest[:])
+	assert.Nil(t, err)
+
+	enc := base64.RawURLEncoding
+	response, _ := json.Marshal(map[string]any{
+		"id":    enc.EncodeToString(a.CredentialId),
+		"rawId": enc.EncodeToString(a.CredentialId),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    enc.EncodeToString(clientData),
+			"authenticatorData": enc.EncodeToString(authData),
+			"signature":         enc.EncodeToString(signature),
+			"userHandle":        enc.EncodeToString(userHandle),
+		},
+	})
+	return response
+}
+
+func webAuthnTestInstance(t *testing.T) *webauthn.WebAuthn {
+	wa, err := webAuthnNewInstance(webAuthnTestOrigin, "UCloud")
+	assert.Nil(t, err)
+	return wa
+}
+
+// webAuthnTestRegister performs a full registration ceremony and returns the user with the new credential.
+func webAuthnTestRegister(t *testing.T, wa *webauthn.WebAuthn, key *webAuthnTestAuthenticator) webAuthnUser {
+	user := webAuthnUser{Uid: 17, Username: "alice", DisplayName: "Alice"}
+	_, session, err := wa.BeginRegistration(&user)
+	assert.Nil(t, err)
+
+	credential, httpErr := webAuthnCreateCredential(wa, &user, *session, key.Register(t, session.Challenge))
+	assert.Nil(t, httpErr)
+	user.Credentials = append(user.Credentials, *credential)
+	return user
+}
+
+func TestWebAuthnRegistrationSessions(t *testing.T) {
+	webAuthnGlobals.Sessions = map[string]webAuthnRegistrationSession{}
+
+	id := webAuthnSessionPut("alice", "Key", webauthn.SessionData{Challenge: "c"})
+	other := webAuthnSessionPut("alice", "Key", webauthn.SessionData{Challenge: "c"})
+	assert.NotEqual(t, id, other)
+
+	// Another user cannot finish the ceremony, and doing so consumes it
+	_, ok := webAuthnSessionTake("mallory", id)
+	assert.False(t, ok)
+	_, ok = webAuthnSessionTake("alice", id)
+	assert.False(t, ok)
+
+	session, ok := webAuthnSessionTake("alice", other)
+	assert.True(t, ok)
+	assert.Equal(t, "Key", session.Name)
+	_, ok = webAuthnSessionTake("alice", other)
+	assert.False(t, ok)
+
+	// Expired ceremonies are rejected and pruned when a new one is started
+	expired := webAuthnSessionPut("alice", "Key", webauthn.SessionData{})
+	webAuthnGlobals.Sessions[expired] = webAuthnRegistrationSession{Username: "alice", ExpiresAt: time.Now().Add(-time.Second)}
+	_, ok = webAuthnSessionTake("alice", expired)
+	assert.False(t, ok)
+
+	stale := webAuthnSessionPut("alice", "Key", webauthn.SessionData{})
+	webAuthnGlobals.Sessions[stale] = webAuthnRegistrationSession{Username: "alice", ExpiresAt: time.Now().Add(-time.Second)}
+	webAuthnSessionPut("alice", "Key", webauthn.SessionData{})
+	_, exists := webAuthnGlobals.Sessions[stale]
+	assert.False(t, exists)
+}
+
+func TestWebAuthnRegistration(t *testing.T) {
+	wa := webAuthnTestInstance(t)
+	key := newWebAuthnTestAuthenticator(t)
+
+	user := webAuthnTestRegister(t, wa, key)
+	assert.Equal(t, 1, len(user.Credentials))
+	assert.Equal(t, string(key.CredentialId), string(user.Credentials[0].ID))
+
+	_, session, err := wa.BeginRegistration(&user)
+	assert.Nil(t, err)
+
+	// The response must answer the challenge of this ceremony
+	_, httpErr := webAuthnCreateCredential(wa, &user, *session, key.Register(t, "another-challenge"))
+	assert.NotNil(t, httpErr)
+
+	// The ceremony must be performed on the UCloud origin
+	phishing := newWebAuthnTestAuthenticator(t)
+	phishing.Origin = "https://cloud.example.com.evil.dk"
+	_, httpErr = webAuthnCreateCredential(wa, &user, *session, phishing.Register(t, session.Challenge))
+	assert.NotNil(t, httpErr)
+
+	_, httpErr = webAuthnCreateCredential(wa, &user, *session, json.RawMessage(`{}`))
+	assert.NotNil(t, httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+}
+
+func TestWebAuthnAssertion(t *testing.T) {
+	wa := webAuthnTestInstance(t)
+	key := newWebAuthnTestAuthenticator(t)
+	user := webAuthnTestRegister(t, wa, key)
+
+	options, sessionJson, httpErr := webAuthnBeginAssertion(wa, &user)
+	assert.Nil(t, httpErr)
+
+	var session webauthn.SessionData
+	assert.Nil(t, json.Unmarshal([]byte(sessionJson), &session))
+	assert.True(t, len(options) > 0)
+
+	key.Counter = 1
+	stored := sql.NullString{String: sessionJson, Valid: true}
+	credential, httpErr := webAuthnValidateAssertion(wa, &user, stored, key.Assert(t, session.Challenge, user.WebAuthnID()))
+	assert.Nil(t, httpErr)
+	assert.Equal(t, uint32(1), credential.Authenticator.SignCount)
+}
+
+func TestWebAuthnAssertionIsRejected(t *testing.T) {
+	wa := webAuthnTestInstance(t)
+	key := newWebAuthnTestAuthenticator(t)
+	user := webAuthnTestRegister(t, wa, key)
+
+	_, sessionJson, httpErr := webAuthnBeginAssertion(wa, &user)
+	assert.Nil(t, httpErr)
+
+	var session webauthn.SessionData
+	assert.Nil(t, json.Unmarshal([]byte(sessionJson), &session))
+	stored := sql.NullString{String: sessionJson, Valid: true}
+
+	// A challenge without a WebAuthn session cannot be answered with a security key
+	_, httpErr = webAuthnValidateAssertion(wa, &user, sql.NullString{}, key.Assert(t, session.Challenge, user.WebAuthnID()))
+	assert.NotNil(t, httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+
+	// An assertion produced for another challenge
+	_, httpErr = webAuthnValidateAssertion(wa, &user, stored, key.Assert(t, "another-challenge", user.WebAuthnID()))
+	assert.NotNil(t, httpErr)
+	assert.Equal(t, http.StatusForbidden, httpErr.StatusCode)
+
+	// A key which is not registered on the account
+	unknown := newWebAuthnTestAuthenticator(t)
+	_, httpErr = webAuthnValidateAssertion(wa, &user, stored, unknown.Assert(t, session.Challenge, user.WebAuthnID()))
+	assert.NotNil(t, httpErr)
+
+	// A registered credential ID signed by a different key
+	forged := newWebAuthnTestAuthenticator(t)
+	forged.CredentialId = key.CredentialId
+	_, httpErr = webAuthnValidateAssertion(wa, &user, stored, forged.Assert(t, session.Challenge, user.WebAuthnID()))
+	assert.NotNil(t, httpErr)
+
+	// The credential belongs to another user
+	other := webAuthnUser{Uid: 99, Username: "bob", Credentials: user.Credentials}
+	_, httpErr = webAuthnValidateAssertion(wa, &other, stored, key.Assert(t, session.Challenge, user.WebAuthnID()))
+	assert.NotNil(t, httpErr)
+
+	// The counter went backwards, which indicates that the key has been cloned
+	user.Credentials[0].Authenticator.SignCount = 10
+	key.Counter = 5
+	_, httpErr = webAuthnValidateAssertion(wa, &user, stored, key.Assert(t, session.Challenge, user.WebAuthnID()))
+	assert.NotNil(t, httpErr)
+	assert.Equal(t, http.StatusForbidden, httpErr.StatusCode)
+}
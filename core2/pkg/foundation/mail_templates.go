@@ -10,6 +10,9 @@ var tplAccountingLowResources []byte
 //go:embed mailtpl/accounting/low_resources_reminder.j2
 var tplAccountingLowResourcesReminder []byte
 
+//go:embed mailtpl/accounting/budget_alert.j2
+var tplAccountingBudgetAlert []byte
+
 //go:embed mailtpl/grants/transfer.j2
 var tplGrantsTransfer []byte
 
@@ -63,3 +66,9 @@ var tplSupportSupport []byte
 
 //go:embed mailtpl/jobs/events.j2
 var tplJobsEvents []byte
+
+//go:embed mailtpl/notifications/digest.j2
+var tplNotificationsDigest []byte
+
+//go:embed mailtpl/files/retention_warning.j2
+var tplFilesRetentionWarning []byte
@@ -198,7 +198,8 @@ func MailRetrieveSettings(username string) fndapi.EmailSettings {
 		if !ok {
 			return fndapi.EmailSettings{}, false
 		} else {
-			var result fndapi.EmailSettings
+			// Settings added after the row was stored keep their default value
+			result := fndapi.DefaultEmailSettings
 			err := json.Unmarshal([]byte(row.Settings), &result)
 			if err != nil {
 				return fndapi.EmailSettings{}, false
@@ -216,6 +217,13 @@ func MailRetrieveSettings(username string) fndapi.EmailSettings {
 }
 
 func MailUserWantsToReceive(username string, mailType fndapi.MailType) bool {
+	if category, ok := mailType.Category(); ok {
+		preferences := NotificationsRetrievePreferences(username)
+		if !preferences.Wants(category, fndapi.NotificationChannelEmail) {
+			return false
+		}
+	}
+
 	settings := MailRetrieveSettings(username)
 	switch mailType {
 	case fndapi.MailTypeTransferApplication:
@@ -224,6 +232,8 @@ func MailUserWantsToReceive(username string, mailType fndapi.MailType) bool {
 		return settings.LowFunds
 	case fndapi.MailTypeStillLowFunds:
 		return settings.LowFunds
+	case fndapi.MailTypeBudgetAlert:
+		return settings.BudgetAlerts
 	case fndapi.MailTypeUserRoleChange:
 		return settings.UserRoleChange
 	case fndapi.MailTypeUserLeft:
@@ -258,6 +268,10 @@ func MailUserWantsToReceive(username string, mailType fndapi.MailType) bool {
 		return true
 	case fndapi.MailTypeJobEvents:
 		return settings.JobStarted || settings.JobStopped
+	case fndapi.MailTypeNotificationDigest:
+		return true
+	case fndapi.MailTypeFilesRetention:
+		return true
 	default:
 		return false
 	}
@@ -370,6 +384,7 @@ func (tpl *mailTemplate) Template() *gonjaexec.Template {
 var mailTemplates = map[fndapi.MailType]mailTemplate{
 	fndapi.MailTypeLowFunds:      mailTpl(tplAccountingLowResources),
 	fndapi.MailTypeStillLowFunds: mailTpl(tplAccountingLowResourcesReminder),
+	fndapi.MailTypeBudgetAlert:   mailTpl(tplAccountingBudgetAlert),
 
 	fndapi.MailTypeResetPassword:      mailTpl(tplAuthReset),
 	fndapi.MailTypeVerifyEmailAddress: mailTpl(tplAuthVerify),
@@ -391,6 +406,10 @@ var mailTemplates = map[fndapi.MailType]mailTemplate{
 	fndapi.MailTypeUserRemoved:       mailTpl(tplProjectsUserRemovedToAdmin),
 
 	fndapi.MailTypeSupport: mailTpl(tplSupportSupport),
+
+	fndapi.MailTypeNotificationDigest: mailTpl(tplNotificationsDigest),
+
+	fndapi.MailTypeFilesRetention: mailTpl(tplFilesRetentionWarning),
 }
 
 type mailToSend struct {
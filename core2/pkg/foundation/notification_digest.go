@@ -0,0 +1,247 @@
+package foundation
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	db "ucloud.dk/shared/pkg/database"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
+)
+
+// Notification digest
+// =====================================================================================================================
+// Users can have the notifications of a category summarized in a daily email. This is controlled by the digest channel
+// of their notification preferences and is independent of whether the notifications are also shown in-app.
+//
+// Notifications are queued in notification.digest_entries when they are created (see NotificationsCreate). A digest is
+// sent once the oldest queued entry of a user is a day old, which results in at most one digest per user per day.
+
+const (
+	notificationDigestInterval              = 24 * time.Hour
+	notificationDigestMaxEntriesPerCategory = 50
+)
+
+var notificationDigestCategoryTitles = map[fndapi.NotificationCategory]string{
+	fndapi.NotificationCategoryJobs:       "Jobs",
+	fndapi.NotificationCategoryGrants:     "Grant applications",
+	fndapi.NotificationCategoryProjects:   "Projects",
+	fndapi.NotificationCategoryAccounting: "Accounting",
+	fndapi.NotificationCategoryShares:     "Shares",
+	fndapi.NotificationCategorySupport:    "Support",
+}
+
+type notificationDigestEntry struct {
+	Id        int64
+	Username  string
+	Category  string
+	Summary   string
+	CreatedAt time.Time
+}
+
+func notificationDigestLoop() {
+	for {
+		notificationDigestSendDue(time.Now())
+		time.Sleep(15 * time.Minute)
+	}
+}
+
+func notificationDigestQueue(b *db.Batch, username string, category fndapi.NotificationCategory, notification fndapi.Notification) {
+	db.BatchExec(
+		b,
+		`
+			insert into notification.digest_entries(username, category, notification_type, summary)
+			values (:username, :category, :type, :summary)
+		`,
+		db.Params{
+			"username": username,
+			"category": string(category),
+			"type":     notification.Type,
+			"summary":  notificationDigestSummary(notification),
+		},
+	)
+}
+
+// notificationDigestSummary produces a single line describing the notification. Most notifications carry a message,
+// job notifications only carry the affected jobs in their metadata.
+func notificationDigestSummary(notification fndapi.Notification) string {
+	if message := strings.TrimSpace(notification.Message); message != "" {
+		return message
+	}
+
+	var meta struct {
+		Title    string   `json:"title"`
+		JobIds   []string `json:"jobIds"`
+		JobNames []string `json:"jobNames"`
+	}
+
+	if notification.Meta.Present {
+		_ = json.Unmarshal(notification.Meta.Value, &meta)
+	}
+
+	if len(meta.JobIds) > 0 {
+		var names []string
+		for i, id := range meta.JobIds {
+			if i < len(meta.JobNames) && meta.JobNames[i] != "" {
+				names = append(names, meta.JobNames[i])
+			} else {
+				names = append(names, id)
+			}
+		}
+
+		var verb string
+		switch notification.Type {
+		case "JOB_STARTED":
+			verb = "started"
+		case "JOB_COMPLETED":
+			verb = "completed"
+		case "JOB_FAILED":
+			verb = "failed"
+		case "JOB_EXPIRED":
+			verb = "expired"
+		default:
+			verb = "changed state"
+		}
+
+		return fmt.Sprintf("Jobs %s: %s", verb, strings.Join(names, ", "))
+	}
+
+	if meta.Title != "" {
+		return meta.Title
+	}
+
+	description := strings.ToLower(strings.ReplaceAll(notification.Type, "_", " "))
+	if description == "" {
+		return "Notification"
+	}
+	return strings.ToUpper(description[:1]) + description[1:]
+}
+
+// notificationDigestSendDue sends a digest to every user with entries older than the digest interval. Entries are
+// only deleted once the digest containing them has been sent, such that they are retried on the next iteration if
+// sending fails. Entries queued while the digest is being sent are kept for the next digest.
+func notificationDigestSendDue(now time.Time) {
+	entries := db.NewTx(func(tx *db.Transaction) []notificationDigestEntry {
+		return db.Select[notificationDigestEntry](
+			tx,
+			`
+				with due as (
+					select username
+					from notification.digest_entries
+					group by username
+					having min(created_at) <= :cutoff
+				)
+				select e.id, e.username, e.category, e.summary, e.created_at
+				from
+					notification.digest_entries e
+					join due on e.username = due.username
+			`,
+			db.Params{
+				"cutoff": now.Add(-notificationDigestInterval),
+			},
+		)
+	})
+
+	idsByUser := map[string][]int64{}
+	for _, entry := range entries {
+		idsByUser[entry.Username] = append(idsByUser[entry.Username], entry.Id)
+	}
+
+	for _, mail := range notificationDigestBuildMails(entries) {
+		if err := MailSend(mail); err != nil {
+			log.Warn("Failed to send notification digest to %s: %s", mail.Receiver, err)
+			continue
+		}
+
+		db.NewTx0(func(tx *db.Transaction) {
+			db.Exec(
+				tx,
+				`
+					delete from notification.digest_entries
+					where id = some(:ids)
+				`,
+				db.Params{
+					"ids": idsByUser[mail.Receiver],
+				},
+			)
+		})
+	}
+}
+
+func notificationDigestBuildMails(entries []notificationDigestEntry) []fndapi.MailSendToUserRequest {
+	type digestEntry struct {
+		Summary string `json:"summary"`
+		Time    string `json:"time"`
+	}
+
+	type digestGroup struct {
+		Title   string        `json:"title"`
+		Entries []digestEntry `json:"entries"`
+	}
+
+	byUser := map[string]map[fndapi.NotificationCategory][]notificationDigestEntry{}
+	for _, entry := range entries {
+		categories, ok := byUser[entry.Username]
+		if !ok {
+			categories = map[fndapi.NotificationCategory][]notificationDigestEntry{}
+			byUser[entry.Username] = categories
+		}
+
+		category := fndapi.NotificationCategory(entry.Category)
+		categories[category] = append(categories[category], entry)
+	}
+
+	var result []fndapi.MailSendToUserRequest
+	for username, categories := range byUser {
+		var groups []digestGroup
+		for _, category := range fndapi.NotificationCategories {
+			categoryEntries := categories[category]
+			if len(categoryEntries) == 0 {
+				continue
+			}
+
+			slices.SortFunc(categoryEntries, func(a, b notificationDigestEntry) int {
+				return a.CreatedAt.Compare(b.CreatedAt)
+			})
+
+			group := digestGroup{Title: notificationDigestCategoryTitles[category]}
+			for i, entry := range categoryEntries {
+				if i == notificationDigestMaxEntriesPerCategory {
+					group.Entries = append(group.Entries, digestEntry{
+						Summary: fmt.Sprintf("... and %d more", len(categoryEntries)-i),
+						Time:    categoryEntries[len(categoryEntries)-1].CreatedAt.UTC().Format("2006-01-02 15:04 UTC"),
+					})
+					break
+				}
+
+				group.Entries = append(group.Entries, digestEntry{
+					Summary: entry.Summary,
+					Time:    entry.CreatedAt.UTC().Format("2006-01-02 15:04 UTC"),
+				})
+			}
+			groups = append(groups, group)
+		}
+
+		if len(groups) == 0 {
+			continue
+		}
+
+		mailData, _ := json.Marshal(map[string]any{
+			"type":   fndapi.MailTypeNotificationDigest,
+			"groups": groups,
+		})
+
+		result = append(result, fndapi.MailSendToUserRequest{
+			Receiver: username,
+			Mail:     mailData,
+		})
+	}
+
+	slices.SortFunc(result, func(a, b fndapi.MailSendToUserRequest) int {
+		return strings.Compare(a.Receiver, b.Receiver)
+	})
+	return result
+}
@@ -0,0 +1,127 @@
+package foundation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	gonjaexec "ucloud.dk/gonja/v2/exec"
+	"ucloud.dk/shared/pkg/assert"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/util"
+)
+
+func TestNotificationPreferences(t *testing.T) {
+	prefs := fndapi.DefaultNotificationPreferences()
+	assert.True(t, prefs.Wants(fndapi.NotificationCategoryJobs, fndapi.NotificationChannelInApp))
+	assert.True(t, prefs.Wants(fndapi.NotificationCategoryJobs, fndapi.NotificationChannelEmail))
+	assert.False(t, prefs.Wants(fndapi.NotificationCategoryJobs, fndapi.NotificationChannelDigest))
+	assert.True(t, prefs.Wants(fndapi.NotificationCategoryGrants, fndapi.NotificationChannelEmail))
+	assert.False(t, prefs.Wants(fndapi.NotificationCategoryGrants, fndapi.NotificationChannelDigest))
+
+	// Stored preferences only override the categories they contain
+	stored := fndapi.DefaultNotificationPreferences()
+	err := json.Unmarshal([]byte(`{"categories":{"JOBS":{"inApp":false,"email":false,"digest":true}}}`), &stored)
+	assert.Nil(t, err)
+	assert.False(t, stored.Wants(fndapi.NotificationCategoryJobs, fndapi.NotificationChannelInApp))
+	assert.True(t, stored.Wants(fndapi.NotificationCategoryJobs, fndapi.NotificationChannelDigest))
+	assert.True(t, stored.Wants(fndapi.NotificationCategoryGrants, fndapi.NotificationChannelEmail))
+
+	category, ok := fndapi.NotificationCategoryOf("NEW_GRANT_COMMENT")
+	assert.True(t, ok)
+	assert.Equal(t, fndapi.NotificationCategoryGrants, category)
+
+	category, ok = fndapi.NotificationCategoryOf("SHARE_REQUEST")
+	assert.True(t, ok)
+	assert.Equal(t, fndapi.NotificationCategoryShares, category)
+
+	_, ok = fndapi.NotificationCategoryOf("TEST_NOTIFICATION")
+	assert.False(t, ok)
+
+	category, ok = fndapi.MailTypeNewComment.Category()
+	assert.True(t, ok)
+	assert.Equal(t, fndapi.NotificationCategoryGrants, category)
+
+	_, ok = fndapi.MailTypeResetPassword.Category()
+	assert.False(t, ok)
+}
+
+func TestNotificationDigestSummary(t *testing.T) {
+	assert.Equal(t, "A message", notificationDigestSummary(fndapi.Notification{Type: "SHARE_REQUEST", Message: "A message"}))
+
+	meta, _ := json.Marshal(map[string]any{
+		"jobIds":   []string{"1", "2"},
+		"jobNames": []string{"My job", ""},
+	})
+
+	summary := notificationDigestSummary(fndapi.Notification{
+		Type: "JOB_COMPLETED",
+		Meta: util.OptValue(json.RawMessage(meta)),
+	})
+	assert.Equal(t, "Jobs completed: My job, 2", summary)
+
+	assert.Equal(t, "Project invite", notificationDigestSummary(fndapi.Notification{Type: "PROJECT_INVITE"}))
+}
+
+func TestNotificationDigestMails(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	var entries []notificationDigestEntry
+	for i := 0; i < notificationDigestMaxEntriesPerCategory+5; i++ {
+		entries = append(entries, notificationDigestEntry{
+			Username:  "alice",
+			Category:  string(fndapi.NotificationCategoryJobs),
+			Summary:   "Jobs started: job",
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		})
+	}
+
+	entries = append(entries, notificationDigestEntry{
+		Username:  "alice",
+		Category:  string(fndapi.NotificationCategoryGrants),
+		Summary:   "Grant awarded",
+		CreatedAt: now,
+	})
+
+	entries = append(entries, notificationDigestEntry{
+		Username:  "bob",
+		Category:  string(fndapi.NotificationCategoryShares),
+		Summary:   "alice wants to share a folder with you",
+		CreatedAt: now,
+	})
+
+	mails := notificationDigestBuildMails(entries)
+	assert.Equal(t, 2, len(mails))
+	assert.Equal(t, "alice", mails[0].Receiver)
+	assert.Equal(t, fndapi.MailTypeNotificationDigest, mails[0].Mail.Type())
+
+	var parsed struct {
+		Groups []struct {
+			Title   string `json:"title"`
+			Entries []struct {
+				Summary string `json:"summary"`
+			} `json:"entries"`
+		} `json:"groups"`
+	}
+	assert.Nil(t, json.Unmarshal(mails[0].Mail, &parsed))
+	assert.Equal(t, 2, len(parsed.Groups))
+	assert.Equal(t, "Jobs", parsed.Groups[0].Title)
+	assert.Equal(t, notificationDigestMaxEntriesPerCategory+1, len(parsed.Groups[0].Entries))
+	assert.Equal(t, "... and 5 more", parsed.Groups[0].Entries[notificationDigestMaxEntriesPerCategory].Summary)
+	assert.Equal(t, "Grant applications", parsed.Groups[1].Title)
+
+	var params map[string]any
+	assert.Nil(t, json.Unmarshal(mails[0].Mail, &params))
+	params["recipient"] = "alice"
+	params["bodyStart"] = "BODY"
+
+	tpl, err := emailPrepareTemplate(tplNotificationsDigest)
+	assert.Nil(t, err)
+
+	rendered, err := emailExecutePreparedTemplate(tpl, gonjaexec.NewContext(params))
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(rendered, "Your daily summary from UCloud"))
+	assert.True(t, strings.Contains(rendered, "Grant awarded"))
+	assert.True(t, strings.Contains(rendered, "... and 5 more"))
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"net/http"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 
@@ -23,6 +25,8 @@ import (
 // The subsystem provides:
 // - Persistent user notifications stored in the database.
 // - Per-user notification settings persisted in the database.
+// - Per-user notification preferences, keyed by category and channel, which also control the daily digest (see
+//   notification_digest.go).
 // - Push delivery to active subscribers via WebSockets
 
 // Core types and globals
@@ -97,6 +101,14 @@ func initNotifications() {
 		return util.Empty{}, nil
 	})
 
+	fndapi.NotificationsRetrievePreferences.Handler(func(info rpc.RequestInfo, request util.Empty) (fndapi.NotificationPreferences, *util.HttpError) {
+		return NotificationsRetrievePreferences(info.Actor.Username), nil
+	})
+
+	fndapi.NotificationsUpdatePreferences.Handler(func(info rpc.RequestInfo, request fndapi.NotificationPreferences) (util.Empty, *util.HttpError) {
+		return util.Empty{}, NotificationsUpdatePreferences(info.Actor, request)
+	})
+
 	followCall := rpc.Call[util.Empty, util.Empty]{
 		BaseContext: "notifications",
 		Convention:  rpc.ConventionWebSocket,
@@ -114,6 +126,8 @@ func initNotifications() {
 
 		return util.Empty{}, nil
 	})
+
+	go notificationDigestLoop()
 }
 
 // Read API
@@ -200,13 +214,27 @@ func NotificationsCreate(notifications []fndapi.NotificationsCreateRequest) {
 		var idPromises []*util.Option[struct{ Id int64 }]
 		b := db.BatchNew(tx)
 		for _, reqItem := range notifications {
-			if reqItem.Notification.Type == "JOB_STARTED" || reqItem.Notification.Type == "JOB_COMPLETED" {
-				settings := notificationsRetrieveSettings(tx, reqItem.User)
-				if !settings.JobStartedOrStopped {
-					continue
+			category, hasCategory := fndapi.NotificationCategoryOf(reqItem.Notification.Type)
+			wantsInApp := true
+			if hasCategory {
+				prefs := notificationsRetrievePreferences(tx, reqItem.User)
+				wantsInApp = prefs.Wants(category, fndapi.NotificationChannelInApp)
+
+				if prefs.Wants(category, fndapi.NotificationChannelDigest) {
+					notificationDigestQueue(b, reqItem.User, category, reqItem.Notification)
 				}
 			}
 
+			if wantsInApp && (reqItem.Notification.Type == "JOB_STARTED" || reqItem.Notification.Type == "JOB_COMPLETED") {
+				settings := notificationsRetrieveSettings(tx, reqItem.User)
+				wantsInApp = settings.JobStartedOrStopped
+			}
+
+			if !wantsInApp {
+				idPromises = append(idPromises, nil)
+				continue
+			}
+
 			meta := util.OptNone[string]()
 			if reqItem.Notification.Meta.Present {
 				meta.Set(string(reqItem.Notification.Meta.Value))
@@ -241,13 +269,23 @@ func NotificationsCreate(notifications []fndapi.NotificationsCreateRequest) {
 		return ids
 	})
 
+	var webhookEvents []fndapi.WebhookEvent
 	for i, notification := range notifications {
 		if ids[i] != 0 {
 			notification.Notification.Id.Set(ids[i])
 			notification.Notification.Ts = fndapi.Timestamp(time.Now())
 			notificationNotify(notification.User, notification.Notification)
+
+			data, _ := json.Marshal(notification.Notification)
+			webhookEvents = append(webhookEvents, fndapi.WebhookEvent{
+				Type:     fndapi.WebhookEventNotification,
+				Username: util.OptValue(notification.User),
+				Data:     data,
+			})
 		}
 	}
+
+	WebhooksEmit(webhookEvents)
 }
 
 // Read state
@@ -402,3 +440,61 @@ func NotificationsUpdateSettings(actor rpc.Actor, settings fndapi.NotificationSe
 		)
 	})
 }
+
+// Notification preferences
+// =====================================================================================================================
+// Preferences are stored per user. Users who never changed their preferences use fndapi.DefaultNotificationPreferences.
+
+func notificationsRetrievePreferences(tx *db.Transaction, username string) fndapi.NotificationPreferences {
+	row, ok := db.Get[struct{ Preferences string }](
+		tx,
+		`select preferences::text as preferences from notification.notification_preferences where username = :username`,
+		db.Params{
+			"username": username,
+		},
+	)
+
+	if !ok {
+		return fndapi.DefaultNotificationPreferences()
+	}
+
+	// Categories added after the preferences were stored keep their default value
+	result := fndapi.DefaultNotificationPreferences()
+	err := json.Unmarshal([]byte(row.Preferences), &result)
+	if err != nil {
+		return fndapi.DefaultNotificationPreferences()
+	} else {
+		return result
+	}
+}
+
+func NotificationsRetrievePreferences(username string) fndapi.NotificationPreferences {
+	return db.NewTx(func(tx *db.Transaction) fndapi.NotificationPreferences {
+		return notificationsRetrievePreferences(tx, username)
+	})
+}
+
+func NotificationsUpdatePreferences(actor rpc.Actor, preferences fndapi.NotificationPreferences) *util.HttpError {
+	for category := range preferences.Categories {
+		if !slices.Contains(fndapi.NotificationCategories, category) {
+			return util.HttpErr(http.StatusBadRequest, "unknown notification category: %s", category)
+		}
+	}
+
+	preferencesJson, _ := json.Marshal(preferences)
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				insert into notification.notification_preferences(username, preferences)
+				values (:username, cast(:preferences as jsonb))
+				on conflict (username) do update set preferences = excluded.preferences
+		    `,
+			db.Params{
+				"username":    actor.Username,
+				"preferences": string(preferencesJson),
+			},
+		)
+	})
+	return nil
+}
@@ -214,6 +214,16 @@ func initProjects() {
 		return ProjectRetrieveSubProjectRenaming(info.Actor)
 	})
 
+	fndapi.ProjectUpdateCostCentre.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[fndapi.ProjectUpdateCostCentreRequest]) (util.Empty, *util.HttpError) {
+		for _, reqItem := range request.Items {
+			err := ProjectUpdateCostCentre(info.Actor, reqItem)
+			if err != nil {
+				return util.Empty{}, err
+			}
+		}
+		return util.Empty{}, nil
+	})
+
 	fndapi.ProjectMemberChangeRole.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[fndapi.ProjectMemberChangeRoleRequest]) (util.Empty, *util.HttpError) {
 		for _, reqItem := range request.Items {
 			err := ProjectChangeRole(info.Actor, reqItem)
@@ -1890,6 +1900,57 @@ func ProjectRetrieveSubProjectRenaming(actor rpc.Actor) (fndapi.ProjectRetrieveS
 	return fndapi.ProjectRetrieveSubProjectRenamingResponse{Allowed: canRename}, nil
 }
 
+// ProjectUpdateCostCentre sets the cost centre of a project. The cost centre is used for grouping usage when invoicing
+// and is thus controlled by the admins of the parent project. Root projects can only be changed by UCloud admins.
+func ProjectUpdateCostCentre(actor rpc.Actor, request fndapi.ProjectUpdateCostCentreRequest) *util.HttpError {
+	if request.CostCentre.Present {
+		request.CostCentre.Value = strings.TrimSpace(request.CostCentre.Value)
+		if request.CostCentre.Value == "" {
+			request.CostCentre = util.OptNone[string]()
+		} else if len(request.CostCentre.Value) > 128 {
+			return util.HttpErr(http.StatusBadRequest, "The cost centre is too long")
+		}
+	}
+
+	project, iProject, err := projectRetrieve(rpc.ActorSystem, request.ProjectId, projectFlagsAll, fndapi.ProjectRoleUser)
+	if err != nil {
+		return util.HttpErr(http.StatusNotFound, "Unknown project")
+	}
+
+	if actor.Role != rpc.RoleAdmin {
+		if !project.Specification.Parent.Present {
+			return util.HttpErr(http.StatusForbidden, "Only the parent project can change the cost centre")
+		}
+
+		_, _, err = projectRetrieve(actor, project.Specification.Parent.Value, projectFlagsAll, fndapi.ProjectRoleAdmin)
+		if err != nil {
+			return util.HttpErr(http.StatusForbidden, "Only the parent project can change the cost centre")
+		}
+	}
+
+	iProject.Mu.Lock()
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				update project.projects
+				set
+					cost_centre = :cost_centre,
+					modified_at = now()
+				where
+					id = :project
+		    `,
+			db.Params{
+				"project":     request.ProjectId,
+				"cost_centre": request.CostCentre.Sql(),
+			},
+		)
+	})
+	iProject.Project.Status.Settings.CostCentre = request.CostCentre
+	iProject.Mu.Unlock()
+	return nil
+}
+
 // Project user preference system and user-level info
 // =====================================================================================================================
 // Users can customize their project preferences (e.g., favorite, hidden) at a per-project level. These preferences
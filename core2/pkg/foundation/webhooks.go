@@ -0,0 +1,851 @@
+package foundation
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	db "ucloud.dk/shared/pkg/database"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Introduction
+// =====================================================================================================================
+// This file implements outgoing webhooks. A webhook is a subscription, owned by either a user or a project, which
+// receives a signed HTTP POST request whenever one of its events occur. Events are emitted by the rest of UCloud
+// through WebhooksEmit from the same places which trigger notifications (grant events, job state changes, accepted
+// shares and the low funds scan). In addition, every notification created through NotificationsCreate is forwarded to
+// the webhooks of the receiving user.
+//
+// Every event results in a delivery per matching webhook. Deliveries are persisted before they are attempted and
+// serve as the delivery log of the webhook. A delivery is retried with exponential backoff until the endpoint responds
+// with a 2xx status code or webhookMaxAttempts have been made.
+
+const (
+	webhookMaxPerWorkspace    = 32
+	webhookMaxAttempts        = 8
+	webhookInitialBackoff     = 30 * time.Second
+	webhookMaxBackoff         = 6 * time.Hour
+	webhookRequestTimeout     = 10 * time.Second
+	webhookDeliveryBatchSize  = 64
+	webhookDeliveryRetention  = 30 * 24 * time.Hour
+	webhookMaxErrorLength     = 512
+	webhookMaxDescriptionSize = 256
+)
+
+var webhookGlobals struct {
+	Wake   chan util.Empty
+	Client *http.Client
+}
+
+// Initialization and RPC
+// =====================================================================================================================
+
+func initWebhooks() {
+	webhookGlobals.Wake = make(chan util.Empty, 1)
+	webhookGlobals.Client = webhookHttpClient()
+
+	fndapi.WebhooksCreate.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[fndapi.WebhookSpecification]) (fndapi.BulkResponse[fndapi.WebhooksCreateResponse], *util.HttpError) {
+		responses, err := WebhooksCreate(info.Actor, request.Items)
+		return fndapi.BulkResponse[fndapi.WebhooksCreateResponse]{Responses: responses}, err
+	})
+
+	fndapi.WebhooksBrowse.Handler(func(info rpc.RequestInfo, request util.Empty) (fndapi.PageV2[fndapi.Webhook], *util.HttpError) {
+		return WebhooksBrowse(info.Actor), nil
+	})
+
+	fndapi.WebhooksDelete.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[fndapi.FindByStringId]) (util.Empty, *util.HttpError) {
+		return util.Empty{}, WebhooksDelete(info.Actor, request.Items)
+	})
+
+	fndapi.WebhooksBrowseDeliveries.Handler(func(info rpc.RequestInfo, request fndapi.WebhooksBrowseDeliveriesRequest) (fndapi.PageV2[fndapi.WebhookDelivery], *util.HttpError) {
+		return WebhooksBrowseDeliveries(info.Actor, request)
+	})
+
+	fndapi.WebhooksRedeliver.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[fndapi.FindByStringId]) (util.Empty, *util.HttpError) {
+		return util.Empty{}, WebhooksRedeliver(info.Actor, request.Items)
+	})
+
+	fndapi.WebhooksEmit.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[fndapi.WebhookEvent]) (util.Empty, *util.HttpError) {
+		WebhooksEmit(request.Items)
+		return util.Empty{}, nil
+	})
+
+	go webhooksDeliveryLoop()
+}
+
+// Management of webhooks
+// =====================================================================================================================
+// Webhooks belong to the workspace in which they were created. Webhooks of a personal workspace are managed by the
+// user, while project webhooks can be managed by any administrator of the project.
+
+type webhookRow struct {
+	Id          int64
+	CreatedBy   string
+	CreatedAt   time.Time
+	ProjectId   sql.NullString
+	Url         string
+	Events      []string
+	Description string
+}
+
+func (row *webhookRow) ToApi() fndapi.Webhook {
+	result := fndapi.Webhook{
+		Id:        fmt.Sprint(row.Id),
+		CreatedBy: row.CreatedBy,
+		CreatedAt: fndapi.Timestamp(row.CreatedAt),
+		Specification: fndapi.WebhookSpecification{
+			Url:         row.Url,
+			Description: row.Description,
+		},
+	}
+
+	if row.ProjectId.Valid {
+		result.Project.Set(row.ProjectId.String)
+	}
+
+	for _, ev := range row.Events {
+		result.Specification.Events = append(result.Specification.Events, fndapi.WebhookEventType(ev))
+	}
+	return result
+}
+
+func webhookCanManage(actor rpc.Actor) bool {
+	if !actor.Project.Present {
+		return true
+	} else {
+		return actor.Membership[actor.Project.Value].Satisfies(rpc.ProjectRoleAdmin)
+	}
+}
+
+// webhookWorkspaceParams returns the parameters used by webhookWorkspaceFilter to select the webhooks of the actor's
+// active workspace.
+func webhookWorkspaceParams(actor rpc.Actor) db.Params {
+	return db.Params{
+		"username": actor.Username,
+		"project":  string(actor.Project.GetOrDefault("")),
+	}
+}
+
+const webhookWorkspaceFilter = `
+	(
+		(:project = '' and w.project_id is null and w.created_by = :username)
+		or (:project != '' and w.project_id = :project)
+	)
+`
+
+func webhookValidate(spec *fndapi.WebhookSpecification) *util.HttpError {
+	spec.Url = strings.TrimSpace(spec.Url)
+	spec.Description = strings.TrimSpace(spec.Description)
+
+	parsed, err := url.Parse(spec.Url)
+	if err != nil || parsed.Host == "" {
+		return util.HttpErr(http.StatusBadRequest, "invalid webhook url")
+	}
+
+	if parsed.Scheme != "https" && !(parsed.Scheme == "http" && util.DevelopmentModeEnabled()) {
+		return util.HttpErr(http.StatusBadRequest, "webhook url must use https")
+	}
+
+	if parsed.User != nil {
+		return util.HttpErr(http.StatusBadRequest, "webhook url must not contain credentials")
+	}
+
+	if len(spec.Description) > webhookMaxDescriptionSize {
+		return util.HttpErr(http.StatusBadRequest, "description is too long")
+	}
+
+	if len(spec.Events) == 0 {
+		return util.HttpErr(http.StatusBadRequest, "a webhook must subscribe to at least one event")
+	}
+
+	for _, ev := range spec.Events {
+		if !slices.Contains(fndapi.WebhookEventTypes, ev) {
+			return util.HttpErr(http.StatusBadRequest, "unknown event type: %s", ev)
+		}
+	}
+
+	slices.Sort(spec.Events)
+	spec.Events = slices.Compact(spec.Events)
+	return nil
+}
+
+func WebhooksCreate(actor rpc.Actor, specs []fndapi.WebhookSpecification) ([]fndapi.WebhooksCreateResponse, *util.HttpError) {
+	if !webhookCanManage(actor) {
+		return nil, util.HttpErr(http.StatusForbidden, "only project administrators can configure webhooks")
+	}
+
+	for i := range specs {
+		if err := webhookValidate(&specs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return db.NewTx2(func(tx *db.Transaction) ([]fndapi.WebhooksCreateResponse, *util.HttpError) {
+		count, _ := db.Get[struct{ Count int }](
+			tx,
+			`
+				select count(*) as count
+				from notification.webhooks w
+				where
+			`+webhookWorkspaceFilter,
+			webhookWorkspaceParams(actor),
+		)
+
+		if count.Count+len(specs) > webhookMaxPerWorkspace {
+			return nil, util.HttpErr(
+				http.StatusBadRequest,
+				"a workspace cannot have more than %d webhooks",
+				webhookMaxPerWorkspace,
+			)
+		}
+
+		var result []fndapi.WebhooksCreateResponse
+		for _, spec := range specs {
+			var events []string
+			for _, ev := range spec.Events {
+				events = append(events, string(ev))
+			}
+
+			secret := util.SecureToken()
+			row, _ := db.Get[struct{ Id int64 }](
+				tx,
+				`
+					insert into notification.webhooks(created_by, project_id, url, events, description, secret)
+					values (:username, nullif(:project, ''), :url, :events, :description, :secret)
+					returning id
+				`,
+				db.Params{
+					"username":    actor.Username,
+					"project":     string(actor.Project.GetOrDefault("")),
+					"url":         spec.Url,
+					"events":      events,
+					"description": spec.Description,
+					"secret":      secret,
+				},
+			)
+
+			result = append(result, fndapi.WebhooksCreateResponse{
+				Id:     fmt.Sprint(row.Id),
+				Secret: secret,
+			})
+		}
+
+		return result, nil
+	})
+}
+
+func WebhooksBrowse(actor rpc.Actor) fndapi.PageV2[fndapi.Webhook] {
+	rows := db.NewTx(func(tx *db.Transaction) []webhookRow {
+		return db.Select[webhookRow](
+			tx,
+			`
+				select w.id, w.created_by, w.created_at, w.project_id, w.url, w.events, w.description
+				from notification.webhooks w
+				where
+			`+webhookWorkspaceFilter+`
+				order by w.id
+			`,
+			webhookWorkspaceParams(actor),
+		)
+	})
+
+	result := fndapi.PageV2[fndapi.Webhook]{Items: []fndapi.Webhook{}}
+	for _, row := range rows {
+		result.Items = append(result.Items, row.ToApi())
+	}
+	result.ItemsPerPage = len(result.Items)
+	return result
+}
+
+func webhookParseIds(items []fndapi.FindByStringId) ([]int64, *util.HttpError) {
+	var ids []int64
+	for _, item := range items {
+		id, err := strconv.ParseInt(item.Id, 10, 64)
+		if err != nil {
+			return nil, util.HttpErr(http.StatusBadRequest, "invalid id: %s", item.Id)
+		}
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+func WebhooksDelete(actor rpc.Actor, items []fndapi.FindByStringId) *util.HttpError {
+	if !webhookCanManage(actor) {
+		return util.HttpErr(http.StatusForbidden, "only project administrators can configure webhooks")
+	}
+
+	ids, err := webhookParseIds(items)
+	if err != nil {
+		return err
+	}
+
+	return db.NewTx(func(tx *db.Transaction) *util.HttpError {
+		params := webhookWorkspaceParams(actor)
+		params["ids"] = ids
+
+		deleted := db.Select[struct{ Id int64 }](
+			tx,
+			`
+				delete from notification.webhooks w
+				where
+					w.id = some(:ids)
+					and
+			`+webhookWorkspaceFilter+`
+				returning w.id
+			`,
+			params,
+		)
+
+		if len(deleted) != len(ids) {
+			db.RequestRollback(tx)
+			return util.HttpErr(http.StatusNotFound, "unknown webhook or permission denied")
+		}
+		return nil
+	})
+}
+
+// Delivery log
+// =====================================================================================================================
+
+type webhookDeliveryRow struct {
+	Id             int64
+	WebhookId      int64
+	EventType      string
+	CreatedAt      time.Time
+	State          string
+	Attempts       int
+	LastAttemptAt  sql.Null[time.Time]
+	NextAttemptAt  sql.Null[time.Time]
+	ResponseStatus sql.NullInt32
+	Error          sql.NullString
+	Payload        string
+}
+
+func (row *webhookDeliveryRow) ToApi() fndapi.WebhookDelivery {
+	result := fndapi.WebhookDelivery{
+		Id:        fmt.Sprint(row.Id),
+		WebhookId: fmt.Sprint(row.WebhookId),
+		EventType: fndapi.WebhookEventType(row.EventType),
+		CreatedAt: fndapi.Timestamp(row.CreatedAt),
+		State:     fndapi.WebhookDeliveryState(row.State),
+		Attempts:  row.Attempts,
+		Payload:   webhookPayloadForDelivery(row.Id, row.Payload),
+	}
+
+	if row.LastAttemptAt.Valid {
+		result.LastAttemptAt.Set(fndapi.Timestamp(row.LastAttemptAt.V))
+	}
+	if row.NextAttemptAt.Valid && result.State == fndapi.WebhookDeliveryPending {
+		result.NextAttemptAt.Set(fndapi.Timestamp(row.NextAttemptAt.V))
+	}
+	if row.ResponseStatus.Valid {
+		result.ResponseStatus.Set(int(row.ResponseStatus.Int32))
+	}
+	if row.Error.Valid {
+		result.Error.Set(row.Error.String)
+	}
+	return result
+}
+
+func WebhooksBrowseDeliveries(actor rpc.Actor, request fndapi.WebhooksBrowseDeliveriesRequest) (fndapi.PageV2[fndapi.WebhookDelivery], *util.HttpError) {
+	webhookId, err := strconv.ParseInt(request.WebhookId, 10, 64)
+	if err != nil {
+		return fndapi.PageV2[fndapi.WebhookDelivery]{}, util.HttpErr(http.StatusBadRequest, "invalid webhook id")
+	}
+
+	itemsPerPage := fndapi.ItemsPerPage(request.ItemsPerPage)
+
+	var next int64 = 0
+	if request.Next.Present {
+		next, _ = strconv.ParseInt(request.Next.Value, 10, 64)
+	}
+
+	rows, ok := db.NewTx2(func(tx *db.Transaction) ([]webhookDeliveryRow, bool) {
+		params := webhookWorkspaceParams(actor)
+		params["webhook_id"] = webhookId
+
+		_, ok := db.Get[struct{ Id int64 }](
+			tx,
+			`
+				select w.id
+				from notification.webhooks w
+				where
+					w.id = :webhook_id
+					and
+			`+webhookWorkspaceFilter,
+			params,
+		)
+
+		if !ok {
+			return nil, false
+		}
+
+		return db.Select[webhookDeliveryRow](
+			tx,
+			`
+				select
+					d.id, d.webhook_id, d.event_type, d.created_at, d.state, d.attempts, d.last_attempt_at,
+					d.next_attempt_at, d.response_status, d.error, d.payload::text as payload
+				from notification.webhook_deliveries d
+				where
+					d.webhook_id = :webhook_id
+					and (:next = 0 or d.id < :next)
+				order by d.id desc
+				limit :limit
+			`,
+			db.Params{
+				"webhook_id": webhookId,
+				"next":       next,
+				"limit":      itemsPerPage,
+			},
+		), true
+	})
+
+	if !ok {
+		return fndapi.PageV2[fndapi.WebhookDelivery]{}, util.HttpErr(http.StatusNotFound, "unknown webhook or permission denied")
+	}
+
+	result := fndapi.PageV2[fndapi.WebhookDelivery]{Items: []fndapi.WebhookDelivery{}, ItemsPerPage: itemsPerPage}
+	for _, row := range rows {
+		result.Items = append(result.Items, row.ToApi())
+	}
+
+	if len(rows) == itemsPerPage {
+		result.Next.Set(fmt.Sprint(rows[len(rows)-1].Id))
+	}
+	return result, nil
+}
+
+// WebhooksRedeliver schedules deliveries for another round of attempts. The attempt counter is reset such that the
+// delivery is retried with the normal backoff.
+func WebhooksRedeliver(actor rpc.Actor, items []fndapi.FindByStringId) *util.HttpError {
+	if !webhookCanManage(actor) {
+		return util.HttpErr(http.StatusForbidden, "only project administrators can configure webhooks")
+	}
+
+	ids, err := webhookParseIds(items)
+	if err != nil {
+		return err
+	}
+
+	err = db.NewTx(func(tx *db.Transaction) *util.HttpError {
+		params := webhookWorkspaceParams(actor)
+		params["ids"] = ids
+
+		updated := db.Select[struct{ Id int64 }](
+			tx,
+			`
+				update notification.webhook_deliveries d
+				set
+					state = 'PENDING',
+					attempts = 0,
+					next_attempt_at = now()
+				from notification.webhooks w
+				where
+					d.webhook_id = w.id
+					and d.id = some(:ids)
+					and
+			`+webhookWorkspaceFilter+`
+				returning d.id
+			`,
+			params,
+		)
+
+		if len(updated) != len(ids) {
+			db.RequestRollback(tx)
+			return util.HttpErr(http.StatusNotFound, "unknown delivery or permission denied")
+		}
+		return nil
+	})
+
+	if err == nil {
+		webhooksWake()
+	}
+	return err
+}
+
+// Emitting events
+// =====================================================================================================================
+// Events are turned into deliveries immediately. The payload is stored without the delivery ID, which is added when
+// the delivery is sent (see webhookPayloadForDelivery).
+
+func WebhooksEmit(events []fndapi.WebhookEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	now := time.Now()
+
+	db.NewTx0(func(tx *db.Transaction) {
+		b := db.BatchNew(tx)
+		for _, ev := range events {
+			if !ev.Username.Present && !ev.Project.Present {
+				continue
+			}
+
+			payload, _ := json.Marshal(fndapi.WebhookPayload{
+				Type:      ev.Type,
+				CreatedAt: fndapi.Timestamp(now),
+				Username:  ev.Username,
+				Project:   ev.Project,
+				Data:      ev.Data,
+			})
+
+			db.BatchExec(
+				b,
+				`
+					insert into notification.webhook_deliveries(webhook_id, event_type, payload)
+					select w.id, :type, cast(:payload as jsonb)
+					from notification.webhooks w
+					where
+						:type = some(w.events)
+						and (
+							(:username != '' and w.project_id is null and w.created_by = :username)
+							or (:project != '' and w.project_id = :project)
+						)
+				`,
+				db.Params{
+					"type":     string(ev.Type),
+					"payload":  string(payload),
+					"username": ev.Username.GetOrDefault(""),
+					"project":  ev.Project.GetOrDefault(""),
+				},
+			)
+		}
+		db.BatchSend(b)
+	})
+
+	webhooksWake()
+}
+
+func webhooksWake() {
+	select {
+	case webhookGlobals.Wake <- util.Empty{}:
+	default:
+	}
+}
+
+func webhookPayloadForDelivery(deliveryId int64, storedPayload string) json.RawMessage {
+	var payload fndapi.WebhookPayload
+	if err := json.Unmarshal([]byte(storedPayload), &payload); err != nil {
+		return json.RawMessage(storedPayload)
+	}
+
+	payload.DeliveryId = fmt.Sprint(deliveryId)
+	data, _ := json.Marshal(payload)
+	return data
+}
+
+// Delivery
+// =====================================================================================================================
+// The delivery loop claims a batch of due deliveries by pushing their next attempt into the future. This prevents the
+// same delivery from being sent twice if an attempt is slow. The outcome of each attempt is written back once the
+// entire batch has been attempted.
+
+// webhookSignature computes the value of the UCloud-Signature header.
+func webhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff returns the delay before the next attempt of a delivery which has failed the given number of times.
+// The second return value is false when the delivery should not be retried.
+func webhookBackoff(attempts int) (time.Duration, bool) {
+	if attempts >= webhookMaxAttempts {
+		return 0, false
+	}
+
+	delay := webhookInitialBackoff
+	for i := 1; i < attempts && delay < webhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxBackoff), true
+}
+
+// webhookDeniedPrefixes lists special-purpose ranges which are not covered by the checks in the net package but can
+// still route to infrastructure inside the deployment.
+var webhookDeniedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"), // Shared address space (carrier-grade NAT)
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // Reserved
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, embeds an IPv4 address
+}
+
+// webhookAddressAllowed rejects addresses which are internal to the deployment. Webhook URLs are supplied by end-users
+// and must not be usable for reaching internal services.
+func webhookAddressAllowed(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range webhookDeniedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func webhookHttpClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookRequestTimeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			if util.DevelopmentModeEnabled() {
+				return nil
+			}
+
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+			if ip == nil || !webhookAddressAllowed(ip) {
+				return fmt.Errorf("refusing to connect to %s", host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: webhookRequestTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookRequestTimeout,
+			MaxIdleConnsPerHost: 4,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+type webhookPendingDelivery struct {
+	Id        int64
+	WebhookId int64
+	EventType string
+	Attempts  int
+	Payload   string
+	Url       string
+	Secret    string
+}
+
+type webhookAttemptResult struct {
+	Id             int64
+	Success        bool
+	Attempts       int
+	ResponseStatus util.Option[int]
+	Error          util.Option[string]
+}
+
+func webhooksDeliveryLoop() {
+	nextCleanup := time.Now()
+
+	for {
+		now := time.Now()
+		if now.After(nextCleanup) {
+			webhooksCleanup(now)
+			nextCleanup = now.Add(1 * time.Hour)
+		}
+
+		for webhooksDeliverPending() == webhookDeliveryBatchSize {
+			// Keep going while there are more deliveries due
+		}
+
+		select {
+		case <-webhookGlobals.Wake:
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+func webhooksDeliverPending() int {
+	pending := db.NewTx(func(tx *db.Transaction) []webhookPendingDelivery {
+		return db.Select[webhookPendingDelivery](
+			tx,
+			`
+				with claimed as (
+					select d.id
+					from notification.webhook_deliveries d
+					where
+						d.state = 'PENDING'
+						and d.next_attempt_at <= now()
+					order by d.next_attempt_at
+					limit :limit
+					for update skip locked
+				)
+				update notification.webhook_deliveries d
+				set next_attempt_at = now() + interval '5 minutes'
+				from claimed c, notification.webhooks w
+				where
+					d.id = c.id
+					and w.id = d.webhook_id
+				returning d.id, d.webhook_id, d.event_type, d.attempts, d.payload::text as payload, w.url, w.secret
+			`,
+			db.Params{
+				"limit": webhookDeliveryBatchSize,
+			},
+		)
+	})
+
+	if len(pending) == 0 {
+		return 0
+	}
+
+	results := make([]webhookAttemptResult, len(pending))
+	wg := sync.WaitGroup{}
+	limiter := make(chan util.Empty, 8)
+	for i, delivery := range pending {
+		wg.Add(1)
+		limiter <- util.Empty{}
+		go func() {
+			defer func() {
+				<-limiter
+				wg.Done()
+			}()
+			results[i] = webhookAttempt(delivery)
+		}()
+	}
+	wg.Wait()
+
+	db.NewTx0(func(tx *db.Transaction) {
+		b := db.BatchNew(tx)
+		for _, result := range results {
+			state := fndapi.WebhookDeliverySucceeded
+			nextAttempt := util.OptNone[time.Time]()
+			if !result.Success {
+				delay, retry := webhookBackoff(result.Attempts)
+				if retry {
+					state = fndapi.WebhookDeliveryPending
+					nextAttempt.Set(time.Now().Add(delay))
+				} else {
+					state = fndapi.WebhookDeliveryFailed
+				}
+			}
+
+			db.BatchExec(
+				b,
+				`
+					update notification.webhook_deliveries
+					set
+						state = :state,
+						attempts = :attempts,
+						last_attempt_at = now(),
+						next_attempt_at = :next_attempt_at,
+						response_status = :response_status,
+						error = :error
+					where id = :id
+				`,
+				db.Params{
+					"id":              result.Id,
+					"state":           string(state),
+					"attempts":        result.Attempts,
+					"next_attempt_at": nextAttempt.Sql(),
+					"response_status": result.ResponseStatus.Sql(),
+					"error":           result.Error.Sql(),
+				},
+			)
+		}
+		db.BatchSend(b)
+	})
+
+	return len(pending)
+}
+
+func webhookAttempt(delivery webhookPendingDelivery) webhookAttemptResult {
+	result := webhookAttemptResult{Id: delivery.Id, Attempts: delivery.Attempts + 1}
+	body := webhookPayloadForDelivery(delivery.Id, delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(body))
+	if err != nil {
+		result.Error.Set(webhookTruncateError(err.Error()))
+		return result
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "UCloud-Webhooks")
+	req.Header.Set("UCloud-Webhook-Id", fmt.Sprint(delivery.WebhookId))
+	req.Header.Set("UCloud-Delivery-Id", fmt.Sprint(delivery.Id))
+	req.Header.Set("UCloud-Event", delivery.EventType)
+	req.Header.Set("UCloud-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("UCloud-Signature", webhookSignature(delivery.Secret, timestamp, body))
+
+	resp, err := webhookGlobals.Client.Do(req)
+	if err != nil {
+		result.Error.Set(webhookTruncateError(err.Error()))
+		return result
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	util.SilentClose(resp.Body)
+
+	result.ResponseStatus.Set(resp.StatusCode)
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		result.Success = true
+	} else {
+		result.Error.Set(fmt.Sprintf("endpoint responded with status %d", resp.StatusCode))
+		log.Debug("Webhook delivery %v to %s failed: %s", delivery.Id, delivery.Url, result.Error.Value)
+	}
+	return result
+}
+
+func webhookTruncateError(message string) string {
+	if len(message) > webhookMaxErrorLength {
+		return message[:webhookMaxErrorLength]
+	}
+	return message
+}
+
+func webhooksCleanup(now time.Time) {
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				delete from notification.webhook_deliveries
+				where
+					created_at < :cutoff
+					and state != 'PENDING'
+			`,
+			db.Params{
+				"cutoff": now.Add(-webhookDeliveryRetention),
+			},
+		)
+	})
+}
@@ -38,4 +38,18 @@ func Init() {
 	db.AddMigration(grantV3())
 	db.AddMigration(grantV4())
 	db.AddMigration(projectsV5())
+	db.AddMigration(jobsV2())
+	db.AddMigration(jobsV3())
+	db.AddMigration(authV4())
+	db.AddMigration(authV5())
+	db.AddMigration(grantV5())
+	db.AddMigration(grantV6())
+	db.AddMigration(accountingV6())
+	db.AddMigration(accountingV7())
+	db.AddMigration(projectsV6())
+	db.AddMigration(accountingV8())
+	db.AddMigration(webhooksV1())
+	db.AddMigration(notificationsV1())
+	db.AddMigration(ingressesV1())
+	db.AddMigration(ingressesV2())
 }
@@ -220,3 +220,130 @@ func accountingV5() db.MigrationScript {
 		},
 	}
 }
+
+func accountingV6() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "accountingV6",
+		Execute: func(tx *db.Transaction) {
+			statements := []string{
+				`
+					create table accounting.budget_alerts(
+						id                 bigint not null primary key,
+						owner_reference    text not null,
+						category           text not null,
+						provider           text not null,
+						alert_type         text not null,
+						threshold          int8 not null,
+						created_by         text not null references auth.principals,
+						created_at         timestamp not null default now(),
+						triggered          bool not null default false,
+						last_triggered_at  timestamp,
+						window_start       timestamp,
+						window_start_usage int8 not null default 0
+					);
+				`,
+				`
+					create index budget_alerts_owner on accounting.budget_alerts(owner_reference);
+				`,
+			}
+			for _, statement := range statements {
+				db.Exec(tx, statement, db.Params{})
+			}
+		},
+	}
+}
+
+func accountingV7() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "accountingV7",
+		Execute: func(tx *db.Transaction) {
+			statements := []string{
+				`
+					create table accounting.allocation_history(
+						id                    bigserial primary key,
+						allocation_id         int8 not null references accounting.wallet_allocations_v2(id),
+						created_at            timestamp not null default now(),
+						changed_by            text not null,
+						change_type           text not null,
+						quota_before          int8 not null,
+						quota_after           int8 not null,
+						related_allocation_id int8 references accounting.wallet_allocations_v2(id),
+						reason                text not null default ''
+					);
+				`,
+				`
+					create index allocation_history_allocation on accounting.allocation_history(allocation_id);
+				`,
+			}
+			for _, statement := range statements {
+				db.Exec(tx, statement, db.Params{})
+			}
+		},
+	}
+}
+
+func accountingV8() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "accountingV8",
+		Execute: func(tx *db.Transaction) {
+			db.Exec(
+				tx,
+				`
+					create table accounting.usage_charges(
+						wallet_id    int8 not null references accounting.wallets_v2(id),
+						period_start timestamp not null,
+						scope        text not null default '',
+						description  text not null default '',
+						product      text not null default '',
+						usage        int8 not null default 0,
+						primary key (wallet_id, period_start, scope, description, product)
+					)
+				`,
+				db.Params{},
+			)
+
+			// Usage which was charged before the ledger existed is recovered from the local usage recorded in the
+			// usage reports. This is not possible for capacity-based products since the reports only contain changes
+			// to the usage level.
+			db.Exec(
+				tx,
+				`
+					insert into accounting.usage_charges(wallet_id, period_start, scope, description, usage)
+					with
+						local_deltas as (
+							select
+								r.wallet_id,
+								date_trunc('day', cast(d->>'Timestamp' as timestamptz) at time zone 'utc') as period_start,
+								cast(d->>'Change' as int8) as change
+							from
+								accounting.usage_report r,
+								jsonb_array_elements(
+									case
+										when jsonb_typeof(r.report_data->'UsageOverTime'->'Delta') = 'array'
+										then r.report_data->'UsageOverTime'->'Delta'
+										else '[]'::jsonb
+									end
+								) d
+							where
+								coalesce(jsonb_typeof(d->'Child'), 'null') = 'null'
+						)
+					select
+						ld.wallet_id,
+						ld.period_start,
+						'',
+						'Usage recorded before itemized charges were available',
+						sum(ld.change)
+					from
+						local_deltas ld
+						join accounting.wallets_v2 w on ld.wallet_id = w.id
+						join accounting.product_categories pc on w.product_category = pc.id
+					where
+						pc.accounting_frequency != 'ONCE' or pc.product_type = 'INFERENCE'
+					group by ld.wallet_id, ld.period_start
+					having sum(ld.change) != 0
+				`,
+				db.Params{},
+			)
+		},
+	}
+}
@@ -44,3 +44,73 @@ func authV3() db.MigrationScript {
 		},
 	}
 }
+
+func authV4() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "authV4",
+		Execute: func(tx *db.Transaction) {
+			// authV1 inserted the WAYF provider with an explicit id, which the sequence does not know about
+			db.Exec(
+				tx,
+				`
+					select setval(
+						pg_get_serial_sequence('auth.identity_providers', 'id'),
+						greatest((select max(id) from auth.identity_providers), 1)
+					)
+			    `,
+				db.Params{},
+			)
+		},
+	}
+}
+
+func authV5() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "authV5",
+		Execute: func(tx *db.Transaction) {
+			db.Exec(
+				tx,
+				`
+					create table if not exists auth.webauthn_credentials(
+						id bigserial primary key,
+						principal_id varchar(255) not null references auth.principals(id),
+						name text not null,
+						credential_id bytea not null unique,
+						credential jsonb not null,
+						created_at timestamptz not null default now(),
+						last_used_at timestamptz default null
+					)
+			    `,
+				db.Params{},
+			)
+
+			db.Exec(
+				tx,
+				`create index if not exists webauthn_credentials_principal_idx on auth.webauthn_credentials(principal_id)`,
+				db.Params{},
+			)
+
+			// Challenges for users with only WebAuthn credentials are not tied to a set of TOTP credentials
+			db.Exec(
+				tx,
+				`alter table auth.two_factor_challenges alter column credentials_id drop not null`,
+				db.Params{},
+			)
+
+			db.Exec(
+				tx,
+				`
+					alter table auth.two_factor_challenges
+					add column if not exists principal_id varchar(255) default null references auth.principals(id)
+			    `,
+				db.Params{},
+			)
+
+			db.Exec(
+				tx,
+				`alter table auth.two_factor_challenges add column if not exists webauthn_session jsonb default null`,
+				db.Params{},
+			)
+		},
+	}
+}
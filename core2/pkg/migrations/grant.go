@@ -81,3 +81,86 @@ func grantV4() db.MigrationScript {
 		},
 	}
 }
+
+func grantV5() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "grantsV5",
+		Execute: func(tx *db.Transaction) {
+			statements := []string{
+				`
+					create table "grant".reviews(
+						application_id  bigint not null references "grant".applications on delete cascade,
+						grant_giver     text not null references project.projects on delete cascade,
+						reviewer        text not null references auth.principals,
+						assigned_by     text not null references auth.principals,
+						assigned_at     timestamp not null,
+						submitted_at    timestamp,
+						revision_number int,
+						scores          jsonb,
+						comment         text,
+						primary key (application_id, grant_giver, reviewer)
+					);
+				`,
+				`
+					create index reviews_reviewer on "grant".reviews(reviewer);
+				`,
+				`
+					create table "grant".review_settings(
+						project_id text not null primary key references project.projects on delete cascade,
+						quorum     int not null default 0
+					);
+				`,
+			}
+			for _, statement := range statements {
+				db.Exec(tx, statement, db.Params{})
+			}
+		},
+	}
+}
+
+func grantV6() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "grantsV6",
+		Execute: func(tx *db.Transaction) {
+			statements := []string{
+				`
+					create table "grant".calls(
+						id               bigint not null primary key,
+						grant_giver      text not null references project.projects on delete cascade,
+						created_by       text not null references auth.principals,
+						created_at       timestamp not null default now(),
+						title            text not null,
+						description      text not null,
+						opens_at         timestamp not null,
+						closes_at        timestamp not null,
+						templates        jsonb,
+						allocation_start timestamp,
+						allocation_end   timestamp
+					);
+				`,
+				`
+					create index calls_grant_giver on "grant".calls(grant_giver);
+				`,
+				`
+					create table "grant".call_budget_caps(
+						call_id  bigint not null references "grant".calls on delete cascade,
+						category text not null,
+						provider text not null,
+						cap      int8 not null,
+						primary key (call_id, category, provider)
+					);
+				`,
+				`
+					alter table "grant".applications
+					add column call_id bigint references "grant".calls;
+				`,
+				`
+					create index applications_call_id on "grant".applications(call_id);
+				`,
+			}
+			for _, statement := range statements {
+				db.Exec(tx, statement, db.Params{})
+			}
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import db "ucloud.dk/shared/pkg/database"
+
+func ingressesV1() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "ingressesV1",
+		Execute: func(tx *db.Transaction) {
+			db.Exec(
+				tx,
+				`
+					alter table app_orchestrator.ingresses add column custom_domain jsonb default null
+			    `,
+				db.Params{},
+			)
+		},
+	}
+}
+
+func ingressesV2() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "ingressesV2",
+		Execute: func(tx *db.Transaction) {
+			db.Exec(
+				tx,
+				`
+					alter table app_orchestrator.ingresses add column access jsonb default null
+			    `,
+				db.Params{},
+			)
+		},
+	}
+}
@@ -16,3 +16,33 @@ func jobsV1() db.MigrationScript {
 		},
 	}
 }
+
+func jobsV2() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "jobsV2",
+		Execute: func(tx *db.Transaction) {
+			db.Exec(
+				tx,
+				`
+					alter table app_orchestrator.jobs add column sweep jsonb default null
+			    `,
+				db.Params{},
+			)
+		},
+	}
+}
+
+func jobsV3() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "jobsV3",
+		Execute: func(tx *db.Transaction) {
+			db.Exec(
+				tx,
+				`
+					alter table app_orchestrator.jobs add column dependencies jsonb default null
+			    `,
+				db.Params{},
+			)
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import db "ucloud.dk/shared/pkg/database"
+
+func notificationsV1() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "notificationsV1",
+		Execute: func(tx *db.Transaction) {
+			statements := []string{
+				`
+					create table notification.notification_preferences(
+						username    text not null primary key references auth.principals,
+						preferences jsonb not null
+					);
+				`,
+				`
+					create table notification.digest_entries(
+						id                bigserial primary key,
+						username          text not null references auth.principals,
+						category          text not null,
+						notification_type text not null,
+						summary           text not null,
+						created_at        timestamp not null default now()
+					);
+				`,
+				`
+					create index digest_entries_username on notification.digest_entries(username, created_at);
+				`,
+			}
+			for _, statement := range statements {
+				db.Exec(tx, statement, db.Params{})
+			}
+		},
+	}
+}
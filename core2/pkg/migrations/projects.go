@@ -224,3 +224,16 @@ func projectsV5() db.MigrationScript {
 		},
 	}
 }
+
+func projectsV6() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "projectsV6",
+		Execute: func(tx *db.Transaction) {
+			db.Exec(
+				tx,
+				`alter table project.projects add column cost_centre text default null`,
+				db.Params{},
+			)
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import db "ucloud.dk/shared/pkg/database"
+
+func webhooksV1() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "webhooksV1",
+		Execute: func(tx *db.Transaction) {
+			statements := []string{
+				`
+					create table notification.webhooks(
+						id          bigserial primary key,
+						created_by  text not null references auth.principals,
+						created_at  timestamp not null default now(),
+						project_id  text references project.projects(id) on delete cascade,
+						url         text not null,
+						events      text[] not null,
+						description text not null default '',
+						secret      text not null
+					);
+				`,
+				`
+					create index webhooks_created_by on notification.webhooks(created_by);
+				`,
+				`
+					create index webhooks_project on notification.webhooks(project_id);
+				`,
+				`
+					create table notification.webhook_deliveries(
+						id              bigserial primary key,
+						webhook_id      int8 not null references notification.webhooks(id) on delete cascade,
+						event_type      text not null,
+						created_at      timestamp not null default now(),
+						state           text not null default 'PENDING',
+						attempts        int not null default 0,
+						last_attempt_at timestamp,
+						next_attempt_at timestamp default now(),
+						response_status int,
+						error           text,
+						payload         jsonb not null
+					);
+				`,
+				`
+					create index webhook_deliveries_webhook on notification.webhook_deliveries(webhook_id, id);
+				`,
+				`
+					create index webhook_deliveries_pending on notification.webhook_deliveries(next_attempt_at)
+					where state = 'PENDING';
+				`,
+			}
+			for _, statement := range statements {
+				db.Exec(tx, statement, db.Params{})
+			}
+		},
+	}
+}
@@ -77,6 +77,9 @@ func Init() {
 	initJobUcx()
 	times["JobUcx"] = t.Mark()
 
+	initJobSnapshots()
+	times["JobSnapshots"] = t.Mark()
+
 	initLicenses()
 	times["Licenses"] = t.Mark()
 
@@ -1,12 +1,15 @@
 package orchestrator
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 
+	"ucloud.dk/core/pkg/coreutil"
 	db "ucloud.dk/shared/pkg/database"
 	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
 	orcapi "ucloud.dk/shared/pkg/orchestrators"
 	"ucloud.dk/shared/pkg/rpc"
 	"ucloud.dk/shared/pkg/util"
@@ -167,6 +170,119 @@ func initDrives() {
 
 		return util.Empty{}, nil
 	})
+
+	orcapi.DrivesControlNotifyRetention.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[orcapi.DrivesControlNotifyRetentionRequest]) (util.Empty, *util.HttpError) {
+		return util.Empty{}, DriveNotifyRetention(info.Actor, request.Items)
+	})
+}
+
+// DriveNotifyRetention warns the owners of drives that files will soon be deleted by the retention policy of the
+// provider. Personal drives notify their creator while project drives notify the administrators of the project. An
+// error is returned if the warning could not be delivered, since the provider must not delete files without one.
+func DriveNotifyRetention(actor rpc.Actor, items []orcapi.DrivesControlNotifyRetentionRequest) *util.HttpError {
+	var drives []orcapi.Drive
+	for _, reqItem := range items {
+		drive, _, _, err := ResourceRetrieveEx[orcapi.Drive](actor, driveType, ResourceParseId(reqItem.Id),
+			orcapi.PermissionProvider, orcapi.ResourceFlags{})
+		if err != nil {
+			return err
+		}
+		drives = append(drives, drive)
+	}
+
+	type recipients struct {
+		Users          []string
+		WorkspaceTitle string
+	}
+
+	recipientsByDrive := make([]recipients, len(drives))
+	for i, drive := range drives {
+		if !drive.Owner.Project.Present {
+			recipientsByDrive[i] = recipients{
+				Users:          []string{drive.Owner.CreatedBy},
+				WorkspaceTitle: fmt.Sprintf("Personal workspace of %s", drive.Owner.CreatedBy),
+			}
+		} else {
+			db.NewTx0(func(tx *db.Transaction) {
+				project, ok := coreutil.ProjectRetrieveFromDatabase(tx, drive.Owner.Project.Value)
+				if ok {
+					rcpt := recipients{WorkspaceTitle: project.Specification.Title}
+					for _, member := range project.Status.Members {
+						if member.Role.Satisfies(fndapi.ProjectRoleAdmin) {
+							rcpt.Users = append(rcpt.Users, member.Username)
+						}
+					}
+					recipientsByDrive[i] = rcpt
+				}
+			})
+		}
+
+		if len(recipientsByDrive[i].Users) == 0 {
+			return util.ServerHttpError("unable to find the owners of drive %v", drive.Id)
+		}
+	}
+
+	var mails []fndapi.MailSendToUserRequest
+	for i, reqItem := range items {
+		drive := drives[i]
+		rcpt := recipientsByDrive[i]
+
+		size := util.SizeToHumanReadableWithUnit(float64(reqItem.SizeInBytes))
+		sizeText := fmt.Sprintf("%.2f %s", size.Size, size.Unit)
+		deletesAt := reqItem.DeletesAt.Time().Format("2006-01-02")
+
+		meta, _ := json.Marshal(map[string]any{
+			"title":      fmt.Sprintf("Files in %s will soon be deleted", drive.Specification.Title),
+			"driveId":    drive.Id,
+			"fileCount":  reqItem.FileCount,
+			"deletesAt":  reqItem.DeletesAt,
+			"maxAgeDays": reqItem.MaxAgeDays,
+		})
+
+		mailData, _ := json.Marshal(map[string]any{
+			"type":           fndapi.MailTypeFilesRetention,
+			"driveId":        drive.Id,
+			"driveTitle":     drive.Specification.Title,
+			"workspaceTitle": rcpt.WorkspaceTitle,
+			"fileCount":      reqItem.FileCount,
+			"size":           sizeText,
+			"maxAgeDays":     reqItem.MaxAgeDays,
+			"deletesAt":      deletesAt,
+		})
+
+		for _, username := range rcpt.Users {
+			notification := fndapi.Notification{
+				Type: "FILES_RETENTION_WARNING",
+				Message: fmt.Sprintf("%d files (%s) in %s have not been used for a while and will be deleted from %s",
+					reqItem.FileCount, sizeText, drive.Specification.Title, deletesAt),
+			}
+			notification.Meta.Set(meta)
+
+			_, err := fndapi.NotificationsCreate.Invoke(fndapi.NotificationsCreateRequest{
+				User:         username,
+				Notification: notification,
+			})
+			if err != nil {
+				log.Warn("Failed to send retention notification to %s: %s", username, err)
+			}
+
+			mails = append(mails, fndapi.MailSendToUserRequest{
+				Receiver:  username,
+				Mail:      mailData,
+				Mandatory: util.OptValue(true),
+			})
+		}
+	}
+
+	if len(mails) > 0 {
+		_, err := fndapi.MailSendToUser.Invoke(fndapi.BulkRequest[fndapi.MailSendToUserRequest]{Items: mails})
+		if err != nil {
+			log.Warn("Failed to send retention emails: %s", err)
+			return util.ServerHttpError("failed to send retention emails")
+		}
+	}
+
+	return nil
 }
 
 func DriveRename(actor rpc.Actor, id string, title string) *util.HttpError {
@@ -14,6 +14,8 @@ const (
 	driveOpsStreamingSearch SupportFeatureKey = "drive.ops.streamingSearch"
 	driveOpsShares          SupportFeatureKey = "drive.ops.shares"
 	driveOpsTerminal        SupportFeatureKey = "drive.ops.terminal"
+	driveOpsArchives        SupportFeatureKey = "drive.ops.archives"
+	driveOpsRetention       SupportFeatureKey = "drive.ops.retention"
 
 	driveAcl        SupportFeatureKey = "drive.acl"
 	driveManagement SupportFeatureKey = "drive.management" // create & rename
@@ -77,6 +79,16 @@ var driveFeatureMapper = []featureMapper{
 		Key:  driveOpsTerminal,
 		Path: "files.openInTerminal",
 	},
+	{
+		Type: driveType,
+		Key:  driveOpsArchives,
+		Path: "files.archivesSupported",
+	},
+	{
+		Type: driveType,
+		Key:  driveOpsRetention,
+		Path: "files.retentionPolicy",
+	},
 
 	{
 		Type: driveType,
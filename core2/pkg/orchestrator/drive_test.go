@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ucloud.dk/shared/pkg/assert"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	orcapi "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+func TestDriveNotifyRetentionReportsFailedDelivery(t *testing.T) {
+	initResourceTest(t)
+	InitResourceType(driveType, 0, driveLoad, drivePersist, driveTransform, nil)
+
+	id, _, err := ResourceCreateEx[orcapi.Drive](
+		driveType,
+		orcapi.ResourceOwner{CreatedBy: "alice"},
+		nil,
+		orcapi.ResourceSpecification{},
+		util.OptNone[string](),
+		&driveInfo{Title: "Home"},
+		0,
+	)
+	assert.Nil(t, err)
+
+	var mu sync.Mutex
+	var receivers []string
+	mailFails := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sendToUser") {
+			_, _ = w.Write([]byte("{}"))
+			return
+		}
+
+		var req fndapi.BulkRequest[fndapi.MailSendToUserRequest]
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if mailFails {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		for _, item := range req.Items {
+			receivers = append(receivers, item.Receiver)
+		}
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	previousClient := rpc.DefaultClient
+	t.Cleanup(func() { rpc.DefaultClient = previousClient })
+	rpc.DefaultClient = &rpc.Client{BasePath: server.URL, Client: server.Client()}
+
+	items := []orcapi.DrivesControlNotifyRetentionRequest{{
+		Id:          fmt.Sprint(id),
+		FileCount:   3,
+		SizeInBytes: 1024,
+		MaxAgeDays:  30,
+		DeletesAt:   fndapi.Timestamp(time.Now().Add(7 * 24 * time.Hour)),
+	}}
+
+	// The provider retries the warning, and keeps the files, if the email could not be sent
+	assert.NotNil(t, DriveNotifyRetention(rpc.ActorSystem, items))
+
+	mu.Lock()
+	mailFails = false
+	mu.Unlock()
+
+	assert.Nil(t, DriveNotifyRetention(rpc.ActorSystem, items))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Equal(t, 1, len(receivers)) {
+		assert.Equal(t, "alice", receivers[0])
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -32,6 +33,10 @@ func initFiles() {
 		return FilesVisualize(info.Actor, request)
 	})
 
+	orcapi.FilesRetention.Handler(func(info rpc.RequestInfo, request orcapi.FilesRetentionRequest) (orcapi.FilesRetentionResponse, *util.HttpError) {
+		return FilesRetention(info.Actor, request)
+	})
+
 	orcapi.FilesMove.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[orcapi.FilesSourceAndDestination]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
 		return FilesMove(info.Actor, request)
 	})
@@ -69,6 +74,14 @@ func initFiles() {
 		return util.Empty{}, nil
 	})
 
+	orcapi.FilesCompress.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[orcapi.FilesCompressRequest]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+		return FilesCompress(info.Actor, request)
+	})
+
+	orcapi.FilesExtract.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[orcapi.FilesExtractRequest]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+		return FilesExtract(info.Actor, request)
+	})
+
 	orcapi.FilesTransfer.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[orcapi.FilesTransferRequest]) (util.Empty, *util.HttpError) {
 		for _, reqItem := range request.Items {
 			err := FilesTransfer(info.Actor, reqItem)
@@ -544,6 +557,122 @@ func filesCopyOrMove(
 	return result, nil
 }
 
+func FilesCompress(actor rpc.Actor, request fndapi.BulkRequest[orcapi.FilesCompressRequest]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+	var items []orcapi.FilesProviderArchiveRequest
+	for _, reqItem := range request.Items {
+		if !slices.Contains(orcapi.ArchiveFormats, reqItem.Format) {
+			return fndapi.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusBadRequest, "unknown archive format")
+		}
+
+		items = append(items, orcapi.FilesProviderArchiveRequest{
+			SourcePath:      reqItem.SourcePath,
+			DestinationPath: reqItem.DestinationPath,
+			Format:          reqItem.Format,
+			ConflictPolicy:  reqItem.ConflictPolicy,
+		})
+	}
+
+	return filesArchiveOperation(actor, items, orcapi.FilesProviderCompress, "user initiated compression")
+}
+
+func FilesExtract(actor rpc.Actor, request fndapi.BulkRequest[orcapi.FilesExtractRequest]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+	var items []orcapi.FilesProviderArchiveRequest
+	for _, reqItem := range request.Items {
+		format, ok := orcapi.ArchiveFormatFromFileName(util.FileName(reqItem.SourcePath))
+		if !ok {
+			return fndapi.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusBadRequest, "unsupported archive format")
+		}
+
+		items = append(items, orcapi.FilesProviderArchiveRequest{
+			SourcePath:      reqItem.SourcePath,
+			DestinationPath: reqItem.DestinationPath,
+			Format:          format,
+			ConflictPolicy:  reqItem.ConflictPolicy,
+		})
+	}
+
+	return filesArchiveOperation(actor, items, orcapi.FilesProviderExtract, "user initiated extraction")
+}
+
+func filesArchiveOperation(
+	actor rpc.Actor,
+	items []orcapi.FilesProviderArchiveRequest,
+	call rpc.Call[fndapi.BulkRequest[orcapi.FilesProviderArchiveRequest], fndapi.BulkResponse[util.Empty]],
+	reason string,
+) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+	var result fndapi.BulkResponse[util.Empty]
+	var sourcePaths []string
+	var destPaths []string
+	for _, reqItem := range items {
+		sourcePaths = append(sourcePaths, reqItem.SourcePath)
+		destPaths = append(destPaths, reqItem.DestinationPath)
+
+		result.Responses = append(result.Responses, util.Empty{})
+	}
+
+	drives := map[string]orcapi.Drive{}
+	{
+		sourceDrives, err := filesFetchDrives(actor, sourcePaths, orcapi.PermissionRead)
+		if err != nil {
+			return result, err
+		}
+
+		destDrives, err := filesFetchDrives(actor, destPaths, orcapi.PermissionEdit)
+		if err != nil {
+			return result, err
+		}
+
+		for _, drive := range sourceDrives {
+			drives[drive.Id] = drive
+		}
+
+		for _, drive := range destDrives {
+			drives[drive.Id] = drive
+		}
+	}
+
+	requestsByProvider := map[string][]orcapi.FilesProviderArchiveRequest{}
+
+	for _, reqItem := range items {
+		sourceDriveId, _ := orcapi.DriveIdFromUCloudPath(reqItem.SourcePath)
+		destinationDriveId, _ := orcapi.DriveIdFromUCloudPath(reqItem.DestinationPath)
+
+		sourceDrive, ok1 := drives[sourceDriveId]
+		destinationDrive, ok2 := drives[destinationDriveId]
+
+		if !ok1 || !ok2 || sourceDrive.Specification.Product.Provider != destinationDrive.Specification.Product.Provider {
+			return result, util.HttpErr(http.StatusBadRequest, "archives cannot span multiple providers")
+		}
+
+		if !featureSupported(driveType, destinationDrive.Specification.Product, driveOpsArchives) {
+			return result, util.HttpErr(http.StatusBadRequest, "archives are not supported by this drive")
+		}
+
+		if featureSupported(driveType, destinationDrive.Specification.Product, driveOpsReadOnly) {
+			return result, util.HttpErr(http.StatusForbidden, "destination drive is read only")
+		}
+
+		reqItem.ResolvedSourceCollection = sourceDrive
+		reqItem.ResolvedDestinationCollection = destinationDrive
+
+		providerId := sourceDrive.Specification.Product.Provider
+		requestsByProvider[providerId] = append(requestsByProvider[providerId], reqItem)
+	}
+
+	for provider, requests := range requestsByProvider {
+		_, err := InvokeProvider(provider, call, fndapi.BulkRequestOf(requests...), ProviderCallOpts{
+			Username: util.OptValue(actor.Username),
+			Reason:   util.OptValue(reason),
+		})
+
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
 func filesFetchDrives(actor rpc.Actor, paths []string, permission orcapi.Permission) (map[string]orcapi.Drive, *util.HttpError) {
 	driveIds := map[string]util.Empty{}
 	for _, path := range paths {
@@ -668,6 +797,28 @@ func FilesVisualize(actor rpc.Actor, request orcapi.FilesVisualizeRequest) (orca
 	)
 }
 
+func FilesRetention(actor rpc.Actor, request orcapi.FilesRetentionRequest) (orcapi.FilesRetentionResponse, *util.HttpError) {
+	drives, err := filesFetchDrives(actor, []string{request.Path}, orcapi.PermissionRead)
+	if err != nil {
+		return orcapi.FilesRetentionResponse{}, err
+	}
+	driveID, _ := orcapi.DriveIdFromUCloudPath(request.Path)
+	drive := drives[driveID]
+	if !featureSupported(driveType, drive.Specification.Product, driveOpsRetention) {
+		return orcapi.FilesRetentionResponse{}, util.HttpErr(http.StatusNotFound, "this drive has no retention policy")
+	}
+
+	return InvokeProvider(
+		drive.Specification.Product.Provider,
+		orcapi.FilesProviderRetention,
+		orcapi.FilesProviderRetentionRequest{Path: request.Path, ResolvedCollection: drive},
+		ProviderCallOpts{
+			Username: util.OptValue(actor.Username),
+			Reason:   util.OptValue("list files expiring from retention policy"),
+		},
+	)
+}
+
 func fileTransform(actor rpc.Actor, driveInfo orcapi.Drive, files []orcapi.ProviderFile) []orcapi.UFile {
 	var result []orcapi.UFile
 
@@ -2,15 +2,20 @@ package orchestrator
 
 import (
 	"cmp"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	db "ucloud.dk/shared/pkg/database"
 	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
 	orcapi "ucloud.dk/shared/pkg/orchestrators"
 	"ucloud.dk/shared/pkg/rpc"
 	"ucloud.dk/shared/pkg/util"
@@ -115,6 +120,18 @@ func initIngresses() {
 		return util.Empty{}, IngressUpdateLabels(info.Actor, request)
 	})
 
+	orcapi.IngressesVerifyDomain.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[fndapi.FindByStringId]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+		return IngressVerifyDomain(info.Actor, request)
+	})
+
+	orcapi.IngressesUpdateAccess.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[orcapi.IngressesUpdateAccessRequest]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+		return IngressUpdateAccess(info.Actor, request)
+	})
+
+	orcapi.IngressesAuthorizeAccess.Handler(func(info rpc.RequestInfo, request orcapi.IngressesAuthorizeAccessRequest) (orcapi.IngressesAuthorizeAccessResponse, *util.HttpError) {
+		return IngressAuthorizeAccess(info.Actor, request)
+	})
+
 	orcapi.IngressesRetrieveProducts.Handler(func(info rpc.RequestInfo, request util.Empty) (orcapi.SupportByProvider[orcapi.IngressSupport], *util.HttpError) {
 		return SupportRetrieveProducts[orcapi.IngressSupport](ingressType), nil
 	})
@@ -181,7 +198,7 @@ func initIngresses() {
 				ResourceParseId(item.Id),
 				orcapi.PermissionProvider,
 				func(r *resource, mapped orcapi.Ingress) {
-					// Do nothing
+					ingressApplyUpdate(r.Extra.(*internalIngress), item.Update)
 				},
 			)
 
@@ -223,58 +240,91 @@ func IngressCreate(actor rpc.Actor, request fndapi.BulkRequest[orcapi.IngressSpe
 		userToken, okSuffix := strings.CutSuffix(withoutPrefix, suffix)
 		userToken = strings.ToLower(userToken)
 
-		if !okPrefix || !okSuffix {
-			return nil, util.HttpErr(
-				http.StatusBadRequest,
-				"domain must start with '%s' and end with '%s'",
-				prefix,
-				suffix,
-			)
+		internal := &internalIngress{
+			Domain: item.Domain,
+			State:  orcapi.IngressStateReady,
 		}
 
-		if len(userToken) < 4 {
-			return nil, util.HttpErr(
-				http.StatusBadRequest,
-				"your domain name must be at least 4 characters long (not including prefix and suffix)",
-			)
-		}
+		if item.Access.Present {
+			if !supp.Has(ingressFeatureAccessControl) {
+				return nil, featureNotSupportedError
+			}
 
-		if len(item.Domain) > 253 {
-			return nil, util.HttpErr(
-				http.StatusBadRequest,
-				"your domain name is too long",
-			)
-		}
+			if item.Access.Value.Mode == orcapi.IngressAccessModePassword {
+				return nil, util.HttpErr(
+					http.StatusBadRequest,
+					"a password can only be set once the link has been created",
+				)
+			}
 
-		if strings.Contains(userToken, ".") {
-			return nil, util.HttpErr(
-				http.StatusBadRequest,
-				"you cannot create further sub-domains in the URL",
-			)
-		}
+			err := ingressValidateAccess(&item.Access.Value, actor.Project.Present, false)
+			if err != nil {
+				return nil, err
+			}
 
-		userTokFirst := []rune(userToken)[0]
-		if userTokFirst >= '0' && userTokFirst <= '9' {
-			return nil, util.HttpErr(
-				http.StatusBadRequest,
-				"your domain must not start with a digit",
-			)
+			internal.Access = item.Access
 		}
 
-		if !hostnamePartRegex.MatchString(userToken) {
-			return nil, util.HttpErr(
-				http.StatusBadRequest,
-				"your domain name must not contain special characters",
-			)
-		}
+		if !okPrefix || !okSuffix {
+			if !supp.Has(ingressFeatureCustomDomains) {
+				return nil, util.HttpErr(
+					http.StatusBadRequest,
+					"domain must start with '%s' and end with '%s'",
+					prefix,
+					suffix,
+				)
+			}
 
-		ingressesByDomain.Mu.Lock()
-		_, exists := ingressesByDomain.Domains[item.Domain]
-		if !exists {
-			ingressesByDomain.Domains[item.Domain] = ResourceId(0) // placeholder to ensure that the spot is reserved
+			item.Domain = strings.TrimSuffix(item.Domain, ".")
+			if err := ingressValidateCustomDomain(item.Domain, suffix); err != nil {
+				return nil, err
+			}
+
+			internal.Domain = item.Domain
+			internal.State = orcapi.IngressStatePreparing
+			internal.CustomDomain.Set(orcapi.IngressCustomDomainStatus{
+				ChallengeRecord: orcapi.IngressCustomDomainChallengePrefix + item.Domain,
+				ChallengeToken:  util.SecureToken(),
+			})
+		} else {
+			if len(userToken) < 4 {
+				return nil, util.HttpErr(
+					http.StatusBadRequest,
+					"your domain name must be at least 4 characters long (not including prefix and suffix)",
+				)
+			}
+
+			if len(item.Domain) > 253 {
+				return nil, util.HttpErr(
+					http.StatusBadRequest,
+					"your domain name is too long",
+				)
+			}
+
+			if strings.Contains(userToken, ".") {
+				return nil, util.HttpErr(
+					http.StatusBadRequest,
+					"you cannot create further sub-domains in the URL",
+				)
+			}
+
+			userTokFirst := []rune(userToken)[0]
+			if userTokFirst >= '0' && userTokFirst <= '9' {
+				return nil, util.HttpErr(
+					http.StatusBadRequest,
+					"your domain must not start with a digit",
+				)
+			}
+
+			if !hostnamePartRegex.MatchString(userToken) {
+				return nil, util.HttpErr(
+					http.StatusBadRequest,
+					"your domain name must not contain special characters",
+				)
+			}
 		}
-		ingressesByDomain.Mu.Unlock()
-		if exists {
+
+		if !ingressClaimDomain(item.Domain) {
 			return nil, util.HttpErr(
 				http.StatusBadRequest,
 				"your domain name is not unique, try a different one",
@@ -285,9 +335,7 @@ func IngressCreate(actor rpc.Actor, request fndapi.BulkRequest[orcapi.IngressSpe
 			actor,
 			ingressType,
 			item.ResourceSpecification,
-			&internalIngress{
-				Domain: item.Domain,
-			},
+			internal,
 			orcapi.IngressesProviderCreate,
 		)
 
@@ -364,6 +412,310 @@ func IngressUpdateLabels(actor rpc.Actor, request fndapi.BulkRequest[orcapi.Ingr
 	return nil
 }
 
+// ingressCustomDomainClaimDuration is how long a custom domain stays reserved for a link without the ownership of the
+// domain being verified. This prevents users from blocking a domain which they do not own.
+const ingressCustomDomainClaimDuration = 48 * time.Hour
+
+// ingressClaimDomain reserves a domain for a new link. If the domain is held by a custom domain link whose claim has
+// expired, then that link is deleted and the domain is given to the caller instead.
+func ingressClaimDomain(domain string) bool {
+	for attempt := 0; attempt < 2; attempt++ {
+		ingressesByDomain.Mu.Lock()
+		existing, exists := ingressesByDomain.Domains[domain]
+		if !exists {
+			ingressesByDomain.Domains[domain] = ResourceId(0) // placeholder to ensure that the spot is reserved
+		}
+		ingressesByDomain.Mu.Unlock()
+
+		if !exists {
+			return true
+		} else if existing == 0 || attempt > 0 {
+			return false
+		}
+
+		ing, err := ResourceRetrieve[orcapi.Ingress](rpc.ActorSystem, ingressType, existing, orcapi.ResourceFlags{})
+		if err != nil || !ingressClaimExpired(ing, time.Now()) {
+			return false
+		}
+
+		err = ResourceDeleteThroughProvider[orcapi.Ingress](rpc.ActorSystem, ingressType, ing.Id, orcapi.IngressesProviderDelete)
+		if err != nil {
+			log.Info("Failed to delete unverified link %v for %v: %s", ing.Id, domain, err)
+			return false
+		}
+	}
+	return false
+}
+
+// ingressClaimExpired determines if a link holds a custom domain which has not been verified within the deadline.
+func ingressClaimExpired(ing orcapi.Ingress, now time.Time) bool {
+	custom := ing.Status.CustomDomain
+	if !custom.Present || custom.Value.Verified {
+		return false
+	}
+	return now.Sub(ing.CreatedAt.Time()) > ingressCustomDomainClaimDuration
+}
+
+// ingressValidateCustomDomain checks that domain is a fully qualified name which the user could plausibly own. Domains
+// below the provider's own suffix are rejected since they would otherwise bypass the naming rules of regular links.
+func ingressValidateCustomDomain(domain string, suffix string) *util.HttpError {
+	if len(domain) > 253 {
+		return util.HttpErr(http.StatusBadRequest, "your domain name is too long")
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return util.HttpErr(http.StatusBadRequest, "custom domains must be fully qualified (e.g. app.example.com)")
+	}
+
+	for _, label := range labels {
+		if !hostnamePartRegex.MatchString(label) {
+			return util.HttpErr(http.StatusBadRequest, "your domain name must not contain special characters")
+		}
+	}
+
+	tld := labels[len(labels)-1]
+	if _, err := strconv.Atoi(tld); err == nil {
+		return util.HttpErr(http.StatusBadRequest, "custom domains cannot be IP addresses")
+	}
+
+	providerDomain := strings.TrimPrefix(suffix, ".")
+	if providerDomain != "" && (domain == providerDomain || strings.HasSuffix(domain, "."+providerDomain)) {
+		return util.HttpErr(http.StatusBadRequest, "custom domains cannot use the domain of the provider")
+	}
+
+	return nil
+}
+
+// ingressApplyUpdate applies a status update received from the provider. The ownership challenge is chosen by UCloud
+// and is never replaced by the provider.
+func ingressApplyUpdate(ing *internalIngress, update orcapi.IngressUpdate) {
+	if update.State.Present {
+		ing.State = update.State.Value
+	}
+
+	if update.CustomDomain.Present && ing.CustomDomain.Present {
+		status := &ing.CustomDomain.Value
+		status.Verified = update.CustomDomain.Value.Verified
+		status.CertificateExpiresAt = update.CustomDomain.Value.CertificateExpiresAt
+		status.Error = update.CustomDomain.Value.Error
+	}
+}
+
+func IngressVerifyDomain(actor rpc.Actor, request fndapi.BulkRequest[fndapi.FindByStringId]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+	for _, item := range request.Items {
+		ing, _, _, err := ResourceRetrieveEx[orcapi.Ingress](
+			actor,
+			ingressType,
+			ResourceParseId(item.Id),
+			orcapi.PermissionEdit,
+			orcapi.ResourceFlags{},
+		)
+
+		if err != nil {
+			return fndapi.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusNotFound, "permission denied or link not found (%v)", item.Id)
+		}
+
+		if !ing.Status.CustomDomain.Present {
+			return fndapi.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusBadRequest, "%v does not use a custom domain", ing.Specification.Domain)
+		}
+
+		_, err = InvokeProvider(
+			ing.Specification.Product.Provider,
+			orcapi.IngressesProviderVerifyDomain,
+			fndapi.BulkRequestOf(ing),
+			ProviderCallOpts{
+				Username: util.OptValue(actor.Username),
+				Reason:   util.OptValue("user initiated domain verification"),
+			},
+		)
+
+		if err != nil {
+			return fndapi.BulkResponse[util.Empty]{}, err
+		}
+	}
+
+	return fndapi.BulkResponse[util.Empty]{Responses: make([]util.Empty, len(request.Items))}, nil
+}
+
+// ingressValidateAccess validates and normalizes an access policy. Passwords are required when switching to the
+// password mode, but a link which is already protected by a password can keep its existing password.
+func ingressValidateAccess(policy *orcapi.IngressAccessPolicy, ownedByProject bool, hasPassword bool) *util.HttpError {
+	var err *util.HttpError
+	util.ValidateEnum(&policy.Mode, orcapi.IngressAccessModeOptions, "access.mode", &err)
+	if err != nil {
+		return err
+	}
+
+	var users []string
+	if policy.Mode == orcapi.IngressAccessModeUsers {
+		for _, user := range policy.Users {
+			user = strings.TrimSpace(user)
+			if user != "" && !slices.Contains(users, user) {
+				users = append(users, user)
+			}
+		}
+
+		if len(users) == 0 {
+			return util.HttpErr(http.StatusBadRequest, "you must specify at least one user who can access the link")
+		}
+
+		if len(users) > 256 {
+			return util.HttpErr(http.StatusBadRequest, "too many users, consider restricting the link to the project instead")
+		}
+	}
+	policy.Users = users
+
+	if policy.Mode == orcapi.IngressAccessModeProject && !ownedByProject {
+		return util.HttpErr(http.StatusBadRequest, "only links which belong to a project can be restricted to project members")
+	}
+
+	if policy.Mode == orcapi.IngressAccessModePassword {
+		if policy.Password.Present {
+			if len(policy.Password.Value) < 8 {
+				return util.HttpErr(http.StatusBadRequest, "the password must be at least 8 characters long")
+			}
+
+			if len(policy.Password.Value) > 1024 {
+				return util.HttpErr(http.StatusBadRequest, "the password is too long")
+			}
+		} else if !hasPassword {
+			return util.HttpErr(http.StatusBadRequest, "you must specify a password")
+		}
+	} else {
+		policy.Password.Clear()
+	}
+
+	return nil
+}
+
+func IngressUpdateAccess(actor rpc.Actor, request fndapi.BulkRequest[orcapi.IngressesUpdateAccessRequest]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+	byProvider := map[string][]orcapi.IngressesProviderUpdateAccessRequest{}
+	for _, item := range request.Items {
+		ing, _, _, err := ResourceRetrieveEx[orcapi.Ingress](
+			actor,
+			ingressType,
+			ResourceParseId(item.Id),
+			orcapi.PermissionEdit,
+			orcapi.ResourceFlags{},
+		)
+
+		if err != nil {
+			return fndapi.BulkResponse[util.Empty]{}, err
+		}
+
+		supp, ok := SupportByProduct[orcapi.IngressSupport](ingressType, ing.Specification.Product)
+		if !ok || !supp.Has(ingressFeatureAccessControl) {
+			return fndapi.BulkResponse[util.Empty]{}, featureNotSupportedError
+		}
+
+		hasPassword := orcapi.IngressAccessModeOrDefault(ing.Specification.Access) == orcapi.IngressAccessModePassword
+		err = ingressValidateAccess(&item.Access, ing.Owner.Project.Present, hasPassword)
+		if err != nil {
+			return fndapi.BulkResponse[util.Empty]{}, err
+		}
+
+		for _, user := range item.Access.Users {
+			if _, ok := rpc.LookupActor(user); !ok {
+				return fndapi.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusBadRequest, "unknown user: %v", user)
+			}
+		}
+
+		provider := ing.Specification.Product.Provider
+		byProvider[provider] = append(byProvider[provider], orcapi.IngressesProviderUpdateAccessRequest{
+			Ingress: ing,
+			Access:  item.Access,
+		})
+	}
+
+	for providerId, items := range byProvider {
+		_, err := InvokeProvider(
+			providerId,
+			orcapi.IngressesProviderUpdateAccess,
+			fndapi.BulkRequestOf(items...),
+			ProviderCallOpts{
+				Username: util.OptValue(actor.Username),
+				Reason:   util.OptValue("user initiated access update"),
+			},
+		)
+
+		if err != nil {
+			return fndapi.BulkResponse[util.Empty]{}, err
+		}
+
+		for _, item := range items {
+			access := item.Access
+			access.Password.Clear()
+
+			_ = ResourceUpdate(
+				actor,
+				ingressType,
+				ResourceParseId(item.Ingress.Id),
+				orcapi.PermissionEdit,
+				func(r *resource, mapped orcapi.Ingress) {
+					ing := r.Extra.(*internalIngress)
+					ing.Access.Set(access)
+				},
+			)
+		}
+	}
+
+	return fndapi.BulkResponse[util.Empty]{Responses: make([]util.Empty, len(request.Items))}, nil
+}
+
+// ingressAccessAllowed determines if an actor can pass the access policy of a link. Users who can read the link
+// resource are always allowed through. Passwords are checked by the provider and never allow access through UCloud.
+func ingressAccessAllowed(actor rpc.Actor, owner orcapi.ResourceOwner, policy util.Option[orcapi.IngressAccessPolicy], canRead bool) bool {
+	if canRead {
+		return true
+	}
+
+	switch orcapi.IngressAccessModeOrDefault(policy) {
+	case orcapi.IngressAccessModePublic:
+		return true
+
+	case orcapi.IngressAccessModeProject:
+		if !owner.Project.Present {
+			return false
+		}
+		_, isMember := actor.Membership[rpc.ProjectId(owner.Project.Value)]
+		return isMember
+
+	case orcapi.IngressAccessModeUsers:
+		return slices.Contains(policy.Value.Users, actor.Username)
+	}
+
+	return false
+}
+
+func IngressAuthorizeAccess(actor rpc.Actor, request orcapi.IngressesAuthorizeAccessRequest) (orcapi.IngressesAuthorizeAccessResponse, *util.HttpError) {
+	notFound := util.HttpErr(http.StatusNotFound, "this link does not exist or you do not have access to it")
+
+	ing, err := ResourceRetrieve[orcapi.Ingress](rpc.ActorSystem, ingressType, ResourceParseId(request.Id), orcapi.ResourceFlags{})
+	if err != nil {
+		return orcapi.IngressesAuthorizeAccessResponse{}, notFound
+	}
+
+	_, readErr := ResourceRetrieve[orcapi.Ingress](actor, ingressType, ResourceParseId(request.Id), orcapi.ResourceFlags{})
+	if !ingressAccessAllowed(actor, ing.Owner, ing.Specification.Access, readErr == nil) {
+		return orcapi.IngressesAuthorizeAccessResponse{}, notFound
+	}
+
+	return InvokeProvider(
+		ing.Specification.Product.Provider,
+		orcapi.IngressesProviderAuthorizeAccess,
+		orcapi.IngressesProviderAuthorizeAccessRequest{
+			Ingress:  ing,
+			Username: actor.Username,
+			Redirect: request.Redirect,
+		},
+		ProviderCallOpts{
+			Username: util.OptValue(actor.Username),
+			Reason:   util.OptValue("user is accessing a public link"),
+		},
+	)
+}
+
 var ingressesByDomain struct {
 	Mu      sync.RWMutex
 	Domains map[string]ResourceId
@@ -419,8 +771,18 @@ func ingressesFillIndex() {
 }
 
 type internalIngress struct {
-	Domain  string
-	BoundTo []string
+	Domain       string
+	BoundTo      []string
+	State        orcapi.IngressState
+	CustomDomain util.Option[orcapi.IngressCustomDomainStatus]
+	Access       util.Option[orcapi.IngressAccessPolicy]
+}
+
+func (ing *internalIngress) StateOrDefault() orcapi.IngressState {
+	if ing.State == "" {
+		return orcapi.IngressStateReady
+	}
+	return ing.State
 }
 
 func ingressLoad(tx *db.Transaction, ids []int64, resources map[ResourceId]*resource) {
@@ -428,10 +790,13 @@ func ingressLoad(tx *db.Transaction, ids []int64, resources map[ResourceId]*reso
 		Domain        string
 		Resource      int
 		StatusBoundTo []int
+		CurrentState  sql.NullString
+		CustomDomain  sql.NullString
+		Access        sql.NullString
 	}](
 		tx,
 		`
-			select domain, resource, status_bound_to
+			select domain, resource, status_bound_to, current_state, custom_domain, access
 			from app_orchestrator.ingresses
 			where resource = some(:ids::int8[])
 	    `,
@@ -446,10 +811,27 @@ func ingressLoad(tx *db.Transaction, ids []int64, resources map[ResourceId]*reso
 			boundTo = append(boundTo, fmt.Sprint(jobId))
 		}
 
-		resources[ResourceId(row.Resource)].Extra = &internalIngress{
+		ing := &internalIngress{
 			Domain:  row.Domain,
 			BoundTo: boundTo,
+			State:   orcapi.IngressState(row.CurrentState.String),
+		}
+
+		if row.CustomDomain.Valid {
+			var customDomain orcapi.IngressCustomDomainStatus
+			if err := json.Unmarshal([]byte(row.CustomDomain.String), &customDomain); err == nil {
+				ing.CustomDomain.Set(customDomain)
+			}
+		}
+
+		if row.Access.Valid {
+			var access orcapi.IngressAccessPolicy
+			if err := json.Unmarshal([]byte(row.Access.String), &access); err == nil {
+				ing.Access.Set(access)
+			}
 		}
+
+		resources[ResourceId(row.Resource)].Extra = ing
 	}
 }
 
@@ -471,17 +853,40 @@ func ingressPersist(b *db.Batch, r *resource) {
 			boundTo = append(boundTo, id)
 		}
 
+		customDomain := sql.NullString{}
+		if ing.CustomDomain.Present {
+			customDomainJson, _ := json.Marshal(ing.CustomDomain.Value)
+			customDomain.Valid = true
+			customDomain.String = string(customDomainJson)
+		}
+
+		access := sql.NullString{}
+		if ing.Access.Present {
+			accessJson, _ := json.Marshal(ing.Access.Value)
+			access.Valid = true
+			access.String = string(accessJson)
+		}
+
 		db.BatchExec(
 			b,
 			`
-				insert into app_orchestrator.ingresses(domain, current_state, resource, status_bound_to)
-				values (:domain, 'READY', :id, :bound_to)
-				on conflict (resource) do update set domain = excluded.domain, status_bound_to = excluded.status_bound_to
+				insert into app_orchestrator.ingresses(domain, current_state, resource, status_bound_to, custom_domain,
+					access)
+				values (:domain, :state, :id, :bound_to, cast(:custom_domain as jsonb), cast(:access as jsonb))
+				on conflict (resource) do update set
+					domain = excluded.domain,
+					status_bound_to = excluded.status_bound_to,
+					current_state = excluded.current_state,
+					custom_domain = excluded.custom_domain,
+					access = excluded.access
 			`,
 			db.Params{
-				"domain":   ing.Domain,
-				"id":       r.Id,
-				"bound_to": boundTo,
+				"domain":        ing.Domain,
+				"state":         ing.StateOrDefault(),
+				"id":            r.Id,
+				"bound_to":      boundTo,
+				"custom_domain": customDomain,
+				"access":        access,
 			},
 		)
 	}
@@ -493,11 +898,13 @@ func ingressTransform(r orcapi.Resource, specification orcapi.ResourceSpecificat
 		Resource: r,
 		Specification: orcapi.IngressSpecification{
 			Domain:                ing.Domain,
+			Access:                ing.Access,
 			ResourceSpecification: specification,
 		},
 		Status: orcapi.IngressStatus{
-			BoundTo: util.NonNilSlice(ing.BoundTo),
-			State:   "READY",
+			BoundTo:      util.NonNilSlice(ing.BoundTo),
+			State:        ing.StateOrDefault(),
+			CustomDomain: ing.CustomDomain,
 		},
 	}
 
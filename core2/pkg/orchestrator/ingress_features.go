@@ -1,8 +1,10 @@
 package orchestrator
 
 const (
-	ingressFeaturePrefix SupportFeatureKey = "ingress.prefix"
-	ingressFeatureSuffix SupportFeatureKey = "ingress.suffix"
+	ingressFeaturePrefix        SupportFeatureKey = "ingress.prefix"
+	ingressFeatureSuffix        SupportFeatureKey = "ingress.suffix"
+	ingressFeatureCustomDomains SupportFeatureKey = "ingress.customDomains"
+	ingressFeatureAccessControl SupportFeatureKey = "ingress.accessControl"
 )
 
 var ingressFeatureMapper = []featureMapper{
@@ -16,4 +18,14 @@ var ingressFeatureMapper = []featureMapper{
 		Key:  ingressFeatureSuffix,
 		Path: "domainSuffix",
 	},
+	{
+		Type: ingressType,
+		Key:  ingressFeatureCustomDomains,
+		Path: "customDomains",
+	},
+	{
+		Type: ingressType,
+		Key:  ingressFeatureAccessControl,
+		Path: "accessControl",
+	},
 }
@@ -0,0 +1,150 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"ucloud.dk/shared/pkg/assert"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	orcapi "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+func TestIngressValidateCustomDomain(t *testing.T) {
+	suffix := ".cloud.example.com"
+
+	assert.Nil(t, ingressValidateCustomDomain("lab.example.org", suffix))
+	assert.Nil(t, ingressValidateCustomDomain("www.my-group.university.edu", suffix))
+
+	invalid := []string{
+		"localhost",
+		"under_score.example.org",
+		"-dash.example.org",
+		"192.168.1.1",
+		"cloud.example.com",
+		"app-mine.cloud.example.com",
+		"double..dot.org",
+	}
+
+	for _, domain := range invalid {
+		assert.NotNil(t, ingressValidateCustomDomain(domain, suffix))
+	}
+}
+
+func TestIngressApplyUpdate(t *testing.T) {
+	ing := &internalIngress{
+		Domain: "lab.example.org",
+		State:  orcapi.IngressStatePreparing,
+		CustomDomain: util.OptValue(orcapi.IngressCustomDomainStatus{
+			ChallengeRecord: "_ucloud-challenge.lab.example.org",
+			ChallengeToken:  "token",
+		}),
+	}
+
+	ingressApplyUpdate(ing, orcapi.IngressUpdate{
+		State: util.OptValue(orcapi.IngressStateReady),
+		CustomDomain: util.OptValue(orcapi.IngressCustomDomainStatus{
+			ChallengeToken:       "replaced",
+			Verified:             true,
+			CertificateExpiresAt: util.OptValue(fndapi.TimeFromUnixMilli(1000)),
+		}),
+	})
+
+	assert.Equal(t, orcapi.IngressStateReady, ing.State)
+	assert.Equal(t, "token", ing.CustomDomain.Value.ChallengeToken)
+	assert.True(t, ing.CustomDomain.Value.Verified)
+	assert.True(t, ing.CustomDomain.Value.CertificateExpiresAt.Present)
+
+	// Links without a custom domain only track their state
+	regular := &internalIngress{Domain: "app-mine.cloud.example.com"}
+	assert.Equal(t, orcapi.IngressStateReady, regular.StateOrDefault())
+	ingressApplyUpdate(regular, orcapi.IngressUpdate{CustomDomain: util.OptValue(orcapi.IngressCustomDomainStatus{Verified: true})})
+	assert.False(t, regular.CustomDomain.Present)
+}
+
+func TestIngressValidateAccess(t *testing.T) {
+	users := orcapi.IngressAccessPolicy{
+		Mode:     orcapi.IngressAccessModeUsers,
+		Users:    []string{" alice ", "bob", "alice", ""},
+		Password: util.OptValue("ignored-password"),
+	}
+	assert.Nil(t, ingressValidateAccess(&users, false, false))
+	assert.Equal(t, 2, len(users.Users))
+	assert.False(t, users.Password.Present)
+
+	assert.NotNil(t, ingressValidateAccess(&orcapi.IngressAccessPolicy{Mode: orcapi.IngressAccessModeUsers}, false, false))
+	assert.NotNil(t, ingressValidateAccess(&orcapi.IngressAccessPolicy{Mode: "EVERYONE"}, false, false))
+
+	// Only project links can be restricted to the project
+	assert.NotNil(t, ingressValidateAccess(&orcapi.IngressAccessPolicy{Mode: orcapi.IngressAccessModeProject}, false, false))
+	assert.Nil(t, ingressValidateAccess(&orcapi.IngressAccessPolicy{Mode: orcapi.IngressAccessModeProject}, true, false))
+
+	// Passwords are required unless one has already been set
+	assert.NotNil(t, ingressValidateAccess(&orcapi.IngressAccessPolicy{Mode: orcapi.IngressAccessModePassword}, false, false))
+	assert.Nil(t, ingressValidateAccess(&orcapi.IngressAccessPolicy{Mode: orcapi.IngressAccessModePassword}, false, true))
+	assert.NotNil(t, ingressValidateAccess(&orcapi.IngressAccessPolicy{
+		Mode:     orcapi.IngressAccessModePassword,
+		Password: util.OptValue("short"),
+	}, false, false))
+	assert.Nil(t, ingressValidateAccess(&orcapi.IngressAccessPolicy{
+		Mode:     orcapi.IngressAccessModePassword,
+		Password: util.OptValue("long enough"),
+	}, false, false))
+}
+
+func TestIngressAccessAllowed(t *testing.T) {
+	owner := orcapi.ResourceOwner{CreatedBy: "owner", Project: util.OptValue("project")}
+	member := rpc.Actor{Username: "member", Membership: rpc.ProjectMembership{"project": rpc.ProjectRoleUser}}
+	outsider := rpc.Actor{Username: "outsider"}
+
+	project := util.OptValue(orcapi.IngressAccessPolicy{Mode: orcapi.IngressAccessModeProject})
+	assert.True(t, ingressAccessAllowed(member, owner, project, false))
+	assert.False(t, ingressAccessAllowed(outsider, owner, project, false))
+
+	users := util.OptValue(orcapi.IngressAccessPolicy{Mode: orcapi.IngressAccessModeUsers, Users: []string{"outsider"}})
+	assert.True(t, ingressAccessAllowed(outsider, owner, users, false))
+	assert.False(t, ingressAccessAllowed(member, owner, users, false))
+
+	password := util.OptValue(orcapi.IngressAccessPolicy{Mode: orcapi.IngressAccessModePassword})
+	assert.False(t, ingressAccessAllowed(member, owner, password, false))
+	assert.True(t, ingressAccessAllowed(member, owner, password, true))
+
+	assert.True(t, ingressAccessAllowed(outsider, owner, util.Option[orcapi.IngressAccessPolicy]{}, false))
+}
+
+func TestIngressClaimExpired(t *testing.T) {
+	now := time.Now()
+	ing := orcapi.Ingress{}
+	ing.CreatedAt = fndapi.Timestamp(now.Add(-ingressCustomDomainClaimDuration - time.Minute))
+
+	// Regular links never expire
+	assert.False(t, ingressClaimExpired(ing, now))
+
+	ing.Status.CustomDomain.Set(orcapi.IngressCustomDomainStatus{ChallengeToken: "token"})
+	assert.True(t, ingressClaimExpired(ing, now))
+
+	ing.Status.CustomDomain.Value.Verified = true
+	assert.False(t, ingressClaimExpired(ing, now))
+
+	ing.Status.CustomDomain.Value.Verified = false
+	ing.CreatedAt = fndapi.Timestamp(now.Add(-time.Hour))
+	assert.False(t, ingressClaimExpired(ing, now))
+}
+
+func TestIngressClaimDomain(t *testing.T) {
+	initResourceTest(t)
+	InitResourceType(ingressType, resourceTypeCreateWithoutAdmin, ingressLoad, ingressPersist, ingressTransform, nil)
+
+	ingressesByDomain.Mu.Lock()
+	ingressesByDomain.Domains = map[string]ResourceId{"taken.example.org": ResourceId(999999)}
+	ingressesByDomain.Mu.Unlock()
+
+	assert.True(t, ingressClaimDomain("lab.example.org"))
+
+	// The first claim is still being created
+	assert.False(t, ingressClaimDomain("lab.example.org"))
+
+	// The holder cannot be inspected, so the claim is kept
+	assert.False(t, ingressClaimDomain("taken.example.org"))
+}
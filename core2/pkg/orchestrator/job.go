@@ -68,6 +68,7 @@ func initJobs() {
 	jobNotificationsPending.EntriesByUser = map[string]map[string]orcapi.Job{}
 
 	go jobNotificationsLoopSendPending()
+	go initJobDependencies()
 
 	orcapi.JobsCreate.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[orcapi.JobSpecification]) (fndapi.BulkResponse[fndapi.FindByStringId], *util.HttpError) {
 		for _, reqItem := range request.Items {
@@ -105,6 +106,17 @@ func initJobs() {
 			return util.Empty{}, err
 		}
 
+		var finishedJobs []string
+		defer func() {
+			if len(finishedJobs) > 0 {
+				go func() {
+					for _, jobId := range finishedJobs {
+						jobDependenciesOnFinished(jobId)
+					}
+				}()
+			}
+		}()
+
 		for jobId, updates := range updatesById {
 			validatedResources := util.OptNone[[]orcapi.AppParameterValue]()
 			{
@@ -171,6 +183,8 @@ func initJobs() {
 							jobNotifyStateChange(mapped)
 
 							if job.State.IsFinal() {
+								finishedJobs = append(finishedJobs, jobId)
+
 								for _, param := range job.Parameters {
 									jobUnbindResource(jobId, param)
 								}
@@ -189,6 +203,10 @@ func initJobs() {
 							job.TimeAllocation.Set(orcapi.SimpleDurationFromMillis(update.NewTimeAllocation.Value))
 						}
 
+						if a := update.ArrayTasks; a.Present {
+							job.ArrayTasks.Set(a.Value)
+						}
+
 						if validatedResources.Present {
 							oldResources := job.Resources
 							job.Resources = jobPersistableResources(validatedResources.Value)
@@ -312,6 +330,7 @@ func initJobs() {
 					TimeAllocation: spec.TimeAllocation,
 					OpenedFile:     spec.OpenedFile,
 					SshEnabled:     spec.SshEnabled,
+					Sweep:          spec.Sweep,
 					State:          orcapi.JobStateInQueue,
 					JobParametersJson: orcapi.ExportedParameters{
 						SiteVersion: 3,
@@ -743,7 +762,8 @@ func initJobs() {
 					},
 					Usage: int64(balanceUsed),
 					Description: accapi.ChargeDescription{
-						Scope: util.OptValue(item.ChargeId),
+						Scope:   util.OptValue(item.ChargeId),
+						Product: util.OptValue(job.Specification.Product.Id),
 					},
 				}))
 
@@ -878,6 +898,8 @@ func JobCreate(actor rpc.Actor, request fndapi.BulkRequest[orcapi.JobSpecificati
 			TimeAllocation: spec.TimeAllocation,
 			OpenedFile:     spec.OpenedFile,
 			SshEnabled:     spec.SshEnabled,
+			Sweep:          spec.Sweep,
+			Dependencies:   spec.Dependencies,
 			State:          orcapi.JobStateInQueue,
 			JobParametersJson: orcapi.ExportedParameters{
 				SiteVersion: 3,
@@ -903,11 +925,40 @@ func JobCreate(actor rpc.Actor, request fndapi.BulkRequest[orcapi.JobSpecificati
 			Updates:   nil,
 		}
 
-		job, err := jobCreateThroughProvider(actor, spec, extra)
+		if taskCount := spec.ArrayTaskCount(); taskCount > 0 {
+			extra.ArrayTasks.Set(orcapi.JobArrayStatus{Total: taskCount, InQueue: taskCount})
+		}
+
+		waiting := false
+		if len(spec.Dependencies) > 0 {
+			status, reason, pending := jobDependenciesResolve(spec.Dependencies)
+			if status == jobDependenciesFailed {
+				return nil, util.HttpErr(http.StatusBadRequest, "%s", reason)
+			} else if status == jobDependenciesPending {
+				app, _ := AppRetrieve(actor, spec.Application.Name, spec.Application.Version, AppDiscoveryAll, 0)
+				backend := app.Invocation.Tool.Tool.Value.Description.Backend
+				waiting = !jobDependenciesCanPassThrough(&spec, backend, pending)
+			}
+		}
+
+		var job orcapi.Job
+		if waiting {
+			extra.State = orcapi.JobStateWaiting
+			job, err = jobCreateWaiting(actor, spec, extra)
+		} else {
+			job, err = jobCreateThroughProvider(actor, spec, extra)
+		}
 		if err != nil {
 			return nil, err
 		}
 
+		if len(spec.Dependencies) > 0 {
+			jobDependenciesTrack(job.Id, spec.Dependencies)
+
+			// An upstream job might have finished before the job was tracked
+			go jobDependenciesReevaluate(job.Id)
+		}
+
 		for _, param := range spec.Parameters {
 			jobBindResource(job.Id, param)
 		}
@@ -964,6 +1015,28 @@ func jobCreateThroughProvider(actor rpc.Actor, spec orcapi.JobSpecification, ext
 	return job, nil
 }
 
+// jobCreateWaiting creates a job which is held by UCloud until its dependencies are satisfied. The job is submitted to
+// the provider by jobDependenciesSubmit.
+func jobCreateWaiting(actor rpc.Actor, spec orcapi.JobSpecification, extra *internalJob) (orcapi.Job, *util.HttpError) {
+	var empty orcapi.Job
+
+	if !resourceSpecificationHasProduct(spec.ResourceSpecification) {
+		return empty, util.HttpErr(http.StatusBadRequest, "resource does not specify a product")
+	}
+
+	if err := ResourceValidateAllocation(actor, spec.ResourceSpecification.Product); err != nil {
+		return empty, err
+	}
+
+	id, job, err := ResourceCreate[orcapi.Job](actor, jobType, spec.ResourceSpecification, extra)
+	if err != nil {
+		return empty, err
+	}
+
+	ResourceConfirm(jobType, id)
+	return job, nil
+}
+
 func jobPersistableResources(resources []orcapi.AppParameterValue) []orcapi.AppParameterValue {
 	result := make([]orcapi.AppParameterValue, 0, len(resources))
 	for _, resource := range resources {
@@ -1032,8 +1105,8 @@ func JobsExtendBulk(actor rpc.Actor, request fndapi.BulkRequest[orcapi.JobsExten
 		appBackend := app.Invocation.Tool.Tool.Value.Description.Backend
 
 		support, ok := SupportByProduct[orcapi.JobSupport](jobType, resc.Specification.Product)
-		if !ok || !support.Has(jobFeatureExtensionByBackend[appBackend]) {
-			return fndapi.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusBadRequest, "time extension is not supported by this provider")
+		if err := jobValidateExtension(resc, appBackend, support, ok); err != nil {
+			return fndapi.BulkResponse[util.Empty]{}, err
 		}
 
 		provider := resc.Specification.Product.Provider
@@ -1062,6 +1135,35 @@ func JobsExtendBulk(actor rpc.Actor, request fndapi.BulkRequest[orcapi.JobsExten
 	return fndapi.BulkResponse[util.Empty]{Responses: make([]util.Empty, len(request.Items))}, nil
 }
 
+func jobValidateExtension(job orcapi.Job, backend orcapi.ToolBackend, support ProductSupport[orcapi.JobSupport], supportOk bool) *util.HttpError {
+	if job.Status.State == orcapi.JobStateWaiting {
+		return util.HttpErr(http.StatusBadRequest, "job is waiting for its dependencies (%v)", job.Id)
+	}
+
+	if !supportOk || !jobHasFeature(support, jobFeatureExtensionByBackend, backend) {
+		return util.HttpErr(http.StatusBadRequest, "time extension is not supported by this provider")
+	}
+	return nil
+}
+
+func jobValidateSuspension(job orcapi.Job, backend orcapi.ToolBackend, support ProductSupport[orcapi.JobSupport], supportOk bool) *util.HttpError {
+	if job.Status.State == orcapi.JobStateWaiting {
+		return util.HttpErr(http.StatusBadRequest, "job is waiting for its dependencies (%v)", job.Id)
+	}
+
+	if !supportOk || !jobHasFeature(support, jobFeatureSuspensionByBackend, backend) {
+		return util.HttpErr(http.StatusBadRequest, "suspension is not supported by this provider")
+	}
+	return nil
+}
+
+// jobHasFeature checks a feature which depends on the backend of the application. Backends without a key for the
+// feature never support it.
+func jobHasFeature(support ProductSupport[orcapi.JobSupport], features map[orcapi.ToolBackend]SupportFeatureKey, backend orcapi.ToolBackend) bool {
+	key, ok := features[backend]
+	return ok && support.Has(key)
+}
+
 func JobsTerminateBulk(actor rpc.Actor, request fndapi.BulkRequest[fndapi.FindByStringId]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
 	updatesByProvider := map[string][]orcapi.Job{}
 
@@ -1078,6 +1180,15 @@ func JobsTerminateBulk(actor rpc.Actor, request fndapi.BulkRequest[fndapi.FindBy
 			return fndapi.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusNotFound, "permission denied or job not found (%v)", item.Id)
 		}
 
+		if resc.Status.State == orcapi.JobStateWaiting {
+			// The provider does not know about the job yet. A job which never ran is not considered successful, this
+			// ensures that jobs depending on it are not started.
+			if jobUpdateStateLocally(resc.Id, orcapi.JobStateWaiting, orcapi.JobStateFailure, "Job was cancelled before its dependencies were satisfied.") {
+				go jobDependenciesOnFinished(resc.Id)
+			}
+			continue
+		}
+
 		provider := resc.Specification.Product.Provider
 		updatesByProvider[provider] = append(updatesByProvider[provider], resc)
 	}
@@ -1118,6 +1229,15 @@ func JobsSuspendBulk(actor rpc.Actor, request fndapi.BulkRequest[fndapi.FindBySt
 			return fndapi.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusNotFound, "permission denied or job not found (%v)", item.Id)
 		}
 
+		app, _ := AppRetrieve(actor, resc.Specification.Application.Name,
+			resc.Specification.Application.Version, AppDiscoveryAll, 0)
+		appBackend := app.Invocation.Tool.Tool.Value.Description.Backend
+
+		support, ok := SupportByProduct[orcapi.JobSupport](jobType, resc.Specification.Product)
+		if err := jobValidateSuspension(resc, appBackend, support, ok); err != nil {
+			return fndapi.BulkResponse[util.Empty]{}, err
+		}
+
 		provider := resc.Specification.Product.Provider
 		updatesByProvider[provider] = append(updatesByProvider[provider], orcapi.JobsProviderSuspendRequestItem{Job: resc})
 	}
@@ -1157,6 +1277,10 @@ func JobsUnsuspendBulk(actor rpc.Actor, request fndapi.BulkRequest[fndapi.FindBy
 			return fndapi.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusNotFound, "permission denied or job not found (%v)", item.Id)
 		}
 
+		if resc.Status.State == orcapi.JobStateWaiting {
+			return fndapi.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusBadRequest, "job is waiting for its dependencies (%v)", item.Id)
+		}
+
 		provider := resc.Specification.Product.Provider
 		updatesByProvider[provider] = append(updatesByProvider[provider], orcapi.JobsProviderUnsuspendRequestItem{Job: resc})
 	}
@@ -1507,6 +1631,12 @@ func jobsValidateForSubmission(actor rpc.Actor, spec *orcapi.JobSpecification) *
 			needDynamicParameters = true
 		}
 	}
+	for _, name := range jobSweepParameterNames(spec) {
+		_, ok := appParamsByName[name]
+		if !ok {
+			needDynamicParameters = true
+		}
+	}
 
 	if needDynamicParameters {
 		resp, err := InvokeProvider(
@@ -1535,98 +1665,205 @@ func jobsValidateForSubmission(actor rpc.Actor, spec *orcapi.JobSpecification) *
 	}
 
 	for name, value := range spec.Parameters {
-		if value.Type == orcapi.AppParameterValueTypeApiServer {
-			return util.HttpErr(http.StatusBadRequest, "api_server values must be supplied as resources")
-		}
-
 		newValue := value
-		err := jobValidateValue(actor, &newValue, tool.Backend, support, util.OptNone[string]())
+		err := jobValidateParameter(actor, name, &newValue, appParamsByName, tool.Backend, support)
 		if err != nil {
 			return err
 		} else {
 			spec.Parameters[name] = newValue
 		}
+	}
 
-		if !strings.HasPrefix(name, "_injected_") {
-			param, ok := appParamsByName[name]
-			if !ok {
-				return util.HttpErr(http.StatusBadRequest, "unknown parameter supplied: '%s'", name)
+	if spec.Sweep.Present {
+		err := jobValidateSweep(actor, spec, appParamsByName, tool.Backend, support)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(spec.Dependencies) > 0 {
+		err := jobValidateDependencies(actor, spec)
+		if err != nil {
+			return err
+		}
+	}
+
+	for name, param := range appParamsByName {
+		if !param.Optional && (param.DefaultValue == nil || string(param.DefaultValue) == "null") {
+			_, ok := spec.Parameters[name]
+			if !ok && !jobSweepProvidesParameter(spec, name) {
+				return util.HttpErr(http.StatusBadRequest, "missing value for '%s'", name)
 			}
-			switch param.Type {
-			case orcapi.ApplicationParameterTypeInputFile, orcapi.ApplicationParameterTypeInputDirectory:
-				if value.Type != orcapi.AppParameterValueTypeFile {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
+		}
+	}
 
-			case orcapi.ApplicationParameterTypeText, orcapi.ApplicationParameterTypeTextArea, orcapi.ApplicationParameterTypeEnumeration:
-				if value.Type != orcapi.AppParameterValueTypeText {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
+	return nil
+}
 
-			case orcapi.ApplicationParameterTypeInteger:
-				if value.Type != orcapi.AppParameterValueTypeInteger {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
+// jobValidateParameter validates a single named parameter against the parameters of the application. The value might
+// be rewritten in the process.
+func jobValidateParameter(
+	actor rpc.Actor,
+	name string,
+	value *orcapi.AppParameterValue,
+	appParamsByName map[string]orcapi.ApplicationParameter,
+	backend orcapi.ToolBackend,
+	support ProductSupport[orcapi.JobSupport],
+) *util.HttpError {
+	if value.Type == orcapi.AppParameterValueTypeApiServer {
+		return util.HttpErr(http.StatusBadRequest, "api_server values must be supplied as resources")
+	}
 
-			case orcapi.ApplicationParameterTypeBoolean:
-				if value.Type != orcapi.AppParameterValueTypeBoolean {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
+	err := jobValidateValue(actor, value, backend, support, util.OptNone[string]())
+	if err != nil {
+		return err
+	}
 
-			case orcapi.ApplicationParameterTypeFloatingPoint:
-				if value.Type != orcapi.AppParameterValueTypeFloatingPoint {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
+	if !strings.HasPrefix(name, "_injected_") {
+		param, ok := appParamsByName[name]
+		if !ok {
+			return util.HttpErr(http.StatusBadRequest, "unknown parameter supplied: '%s'", name)
+		}
+		switch param.Type {
+		case orcapi.ApplicationParameterTypeInputFile, orcapi.ApplicationParameterTypeInputDirectory:
+			if value.Type != orcapi.AppParameterValueTypeFile {
+				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+			}
 
-			case orcapi.ApplicationParameterTypePeer:
-				if value.Type != orcapi.AppParameterValueTypePeer {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
+		case orcapi.ApplicationParameterTypeText, orcapi.ApplicationParameterTypeTextArea, orcapi.ApplicationParameterTypeEnumeration:
+			if value.Type != orcapi.AppParameterValueTypeText {
+				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+			}
 
-			case orcapi.ApplicationParameterTypeLicenseServer:
-				if value.Type != orcapi.AppParameterValueTypeLicense {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
+		case orcapi.ApplicationParameterTypeInteger:
+			if value.Type != orcapi.AppParameterValueTypeInteger {
+				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+			}
 
-			case orcapi.ApplicationParameterTypeIngress:
-				if value.Type != orcapi.AppParameterValueTypeIngress {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
+		case orcapi.ApplicationParameterTypeBoolean:
+			if value.Type != orcapi.AppParameterValueTypeBoolean {
+				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+			}
 
-				if value.Port != 0 && !support.Has(jobFeatureBindLinkToPortByBackend[tool.Backend]) {
-					return util.HttpErr(http.StatusBadRequest, "'%s' has a port specified, but this is not supported by the provider", name)
-				}
+		case orcapi.ApplicationParameterTypeFloatingPoint:
+			if value.Type != orcapi.AppParameterValueTypeFloatingPoint {
+				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+			}
 
-			case orcapi.ApplicationParameterTypeNetworkIp:
-				if value.Type != orcapi.AppParameterValueTypeNetwork {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
+		case orcapi.ApplicationParameterTypePeer:
+			if value.Type != orcapi.AppParameterValueTypePeer {
+				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+			}
 
-			case orcapi.ApplicationParameterTypeWorkflow:
-				if value.Type != orcapi.AppParameterValueTypeWorkflow {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
-			case orcapi.ApplicationParameterTypeReadme:
+		case orcapi.ApplicationParameterTypeLicenseServer:
+			if value.Type != orcapi.AppParameterValueTypeLicense {
 				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+			}
 
-			case orcapi.ApplicationParameterTypeModuleList:
-				if value.Type != orcapi.AppParameterValueTypeModuleList {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
+		case orcapi.ApplicationParameterTypeIngress:
+			if value.Type != orcapi.AppParameterValueTypeIngress {
+				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+			}
 
-			case orcapi.ApplicationParameterTypePrivateNetwork:
-				if value.Type != orcapi.AppParameterValueTypePrivateNetwork {
-					return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
-				}
+			if value.Port != 0 && !support.Has(jobFeatureBindLinkToPortByBackend[backend]) {
+				return util.HttpErr(http.StatusBadRequest, "'%s' has a port specified, but this is not supported by the provider", name)
+			}
+
+		case orcapi.ApplicationParameterTypeNetworkIp:
+			if value.Type != orcapi.AppParameterValueTypeNetwork {
+				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+			}
+
+		case orcapi.ApplicationParameterTypeWorkflow:
+			if value.Type != orcapi.AppParameterValueTypeWorkflow {
+				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+			}
+		case orcapi.ApplicationParameterTypeReadme:
+			return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+
+		case orcapi.ApplicationParameterTypeModuleList:
+			if value.Type != orcapi.AppParameterValueTypeModuleList {
+				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
+			}
+
+		case orcapi.ApplicationParameterTypePrivateNetwork:
+			if value.Type != orcapi.AppParameterValueTypePrivateNetwork {
+				return util.HttpErr(http.StatusBadRequest, "incorrect parameter type for '%s'", name)
 			}
 		}
 	}
 
-	for name, param := range appParamsByName {
-		if !param.Optional && (param.DefaultValue == nil || string(param.DefaultValue) == "null") {
-			_, ok := spec.Parameters[name]
-			if !ok {
-				return util.HttpErr(http.StatusBadRequest, "missing value for '%s'", name)
+	return nil
+}
+
+const jobSweepMaxTasks = 1000
+
+func jobValidateSweep(
+	actor rpc.Actor,
+	spec *orcapi.JobSpecification,
+	appParamsByName map[string]orcapi.ApplicationParameter,
+	backend orcapi.ToolBackend,
+	support ProductSupport[orcapi.JobSupport],
+) *util.HttpError {
+	sweep := &spec.Sweep.Value
+
+	if backend == orcapi.ToolBackendVirtualMachine || !support.Has(jobFeatureArraysByBackend[backend]) {
+		return util.HttpErr(http.StatusBadRequest, "job arrays are not supported on this machine type")
+	}
+
+	if spec.Replicas != 1 {
+		return util.HttpErr(http.StatusBadRequest, "job arrays cannot be combined with multiple nodes")
+	}
+
+	if len(sweep.Tasks) == 0 && len(sweep.Matrix) == 0 {
+		return util.HttpErr(http.StatusBadRequest, "a job array must contain at least one task")
+	}
+
+	for name, values := range sweep.Matrix {
+		if len(values) == 0 {
+			return util.HttpErr(http.StatusBadRequest, "no values supplied for '%s'", name)
+		}
+	}
+
+	size := sweep.Size()
+	if size > jobSweepMaxTasks {
+		return util.HttpErr(http.StatusBadRequest, "a job array cannot contain more than %d tasks", jobSweepMaxTasks)
+	}
+
+	if sweep.MaxConcurrency < 0 {
+		return util.HttpErr(http.StatusBadRequest, "maxConcurrency cannot be negative")
+	} else if sweep.MaxConcurrency > size {
+		sweep.MaxConcurrency = size
+	}
+
+	for _, task := range sweep.Tasks {
+		for name, value := range task {
+			if jobSweepValueIsExclusive(value) {
+				return util.HttpErr(http.StatusBadRequest, "'%s' cannot vary between the tasks of a job array", name)
+			}
+
+			newValue := value
+			err := jobValidateParameter(actor, name, &newValue, appParamsByName, backend, support)
+			if err != nil {
+				return err
+			} else {
+				task[name] = newValue
+			}
+		}
+	}
+
+	for name, values := range sweep.Matrix {
+		for i, value := range values {
+			if jobSweepValueIsExclusive(value) {
+				return util.HttpErr(http.StatusBadRequest, "'%s' cannot vary between the tasks of a job array", name)
+			}
+
+			newValue := value
+			err := jobValidateParameter(actor, name, &newValue, appParamsByName, backend, support)
+			if err != nil {
+				return err
+			} else {
+				values[i] = newValue
 			}
 		}
 	}
@@ -1634,6 +1871,57 @@ func jobsValidateForSubmission(actor rpc.Actor, spec *orcapi.JobSpecification) *
 	return nil
 }
 
+// jobSweepValueIsExclusive returns true for values which are bound to a job and which can therefore not be given to
+// individual tasks of an array.
+func jobSweepValueIsExclusive(value orcapi.AppParameterValue) bool {
+	switch value.Type {
+	case orcapi.AppParameterValueTypeNetwork, orcapi.AppParameterValueTypeIngress, orcapi.AppParameterValueTypeLicense:
+		return true
+	default:
+		return false
+	}
+}
+
+func jobSweepParameterNames(spec *orcapi.JobSpecification) []string {
+	if !spec.Sweep.Present {
+		return nil
+	}
+
+	var result []string
+	for _, task := range spec.Sweep.Value.Tasks {
+		for name := range task {
+			result = append(result, name)
+		}
+	}
+	for name := range spec.Sweep.Value.Matrix {
+		result = append(result, name)
+	}
+	return result
+}
+
+// jobSweepProvidesParameter returns true if every task of the sweep receives a value for the parameter.
+func jobSweepProvidesParameter(spec *orcapi.JobSpecification, name string) bool {
+	if !spec.Sweep.Present {
+		return false
+	}
+
+	sweep := &spec.Sweep.Value
+	if _, ok := sweep.Matrix[name]; ok {
+		return true
+	}
+
+	if len(sweep.Tasks) == 0 {
+		return false
+	}
+
+	for _, task := range sweep.Tasks {
+		if _, ok := task[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func jobValidateValue(
 	actor rpc.Actor,
 	value *orcapi.AppParameterValue,
@@ -1913,10 +2201,13 @@ type internalJob struct {
 	OpenedFile     string
 	SshEnabled     bool
 	OutputFolder   util.Option[string]
+	Sweep          util.Option[orcapi.JobSweep]
+	Dependencies   []orcapi.JobDependency
 
 	State             orcapi.JobState
 	JobParametersJson orcapi.ExportedParameters
 	StartedAt         util.Option[fndapi.Timestamp]
+	ArrayTasks        util.Option[orcapi.JobArrayStatus]
 	Updates           []orcapi.JobUpdate
 
 	ChangeFlags       internalJobChangeFlag
@@ -1937,6 +2228,8 @@ type jobLoadRow struct {
 	ExportedParameters   sql.NullString
 	OpenedFile           sql.NullString
 	SshEnabled           bool
+	Sweep                sql.NullString
+	Dependencies         sql.NullString
 	Parameters           string
 	MountedResources     string
 	Updates              string
@@ -2073,6 +2366,8 @@ func jobLoadCachePopulateBucket(bucket int, rank int) []jobLoadRow {
 					j.job_parameters as exported_parameters,
 					j.opened_file,
 					j.ssh_enabled,
+					j.sweep,
+					j.dependencies,
 					i.parameters,
 					m.mounted_resources,
 					u.updates
@@ -2156,6 +2451,23 @@ func jobLoadRowsToMap(rows []jobLoadRow) map[ResourceId]*internalJob {
 			info.OutputFolder.Set(row.OutputFolder.String)
 		}
 
+		if row.Sweep.Valid {
+			var sweep orcapi.JobSweep
+			if err := json.Unmarshal([]byte(row.Sweep.String), &sweep); err == nil {
+				info.Sweep.Set(sweep)
+			}
+		}
+
+		if row.Dependencies.Valid {
+			_ = json.Unmarshal([]byte(row.Dependencies.String), &info.Dependencies)
+		}
+
+		for _, update := range info.Updates {
+			if update.ArrayTasks.Present {
+				info.ArrayTasks = update.ArrayTasks
+			}
+		}
+
 		info.LastFlushedUpdate.Store(uint64(len(info.Updates)))
 		result[ResourceId(row.Resource)] = info
 	}
@@ -2170,6 +2482,7 @@ func (j *internalJob) Clone() *internalJob {
 		result.Parameters[key] = value
 	}
 	result.Resources = append([]orcapi.AppParameterValue(nil), j.Resources...)
+	result.Dependencies = append([]orcapi.JobDependency(nil), j.Dependencies...)
 	result.Updates = append([]orcapi.JobUpdate(nil), j.Updates...)
 	result.JobParametersJson.MachineType = append([]byte(nil), j.JobParametersJson.MachineType...)
 	if j.JobParametersJson.ResolvedResources.Ingress != nil {
@@ -2249,6 +2562,20 @@ func jobPersist(b *db.Batch, r *resource) {
 
 		exportedParams, _ := json.Marshal(info.JobParametersJson)
 
+		sweep := sql.NullString{}
+		if info.Sweep.Present {
+			sweepJson, _ := json.Marshal(info.Sweep.Value)
+			sweep.Valid = true
+			sweep.String = string(sweepJson)
+		}
+
+		dependencies := sql.NullString{}
+		if len(info.Dependencies) > 0 {
+			dependenciesJson, _ := json.Marshal(info.Dependencies)
+			dependencies.Valid = true
+			dependencies.String = string(dependenciesJson)
+		}
+
 		isPartial := info.ChangeFlags&internalJobPartialChange != 0
 
 		if !isPartial || info.ChangeFlags&internalJobChangeMetadata != 0 {
@@ -2257,9 +2584,10 @@ func jobPersist(b *db.Batch, r *resource) {
 				`
 					insert into app_orchestrator.jobs (application_name, application_version, time_allocation_millis, 
 						replicas, name, output_folder, current_state, started_at, resource, job_parameters, 
-						opened_file, ssh_enabled, hostname)
+						opened_file, ssh_enabled, hostname, sweep, dependencies)
 					values (:app_name, :app_version, :time_alloc, :replicas, :name, :output_folder, :state, :started_at,
-						:resource, :exported_params, :opened_file, :ssh_enabled, :hostname)
+						:resource, :exported_params, :opened_file, :ssh_enabled, :hostname, cast(:sweep as jsonb),
+						cast(:dependencies as jsonb))
 					on conflict (resource) do update set
 						application_name = excluded.application_name,
 						application_version = excluded.application_version,
@@ -2274,6 +2602,8 @@ func jobPersist(b *db.Batch, r *resource) {
 						opened_file = excluded.opened_file,
 						ssh_enabled = excluded.ssh_enabled,
 						hostname = excluded.hostname,
+						sweep = excluded.sweep,
+						dependencies = excluded.dependencies,
 						last_update = now()
 				`,
 				db.Params{
@@ -2290,6 +2620,8 @@ func jobPersist(b *db.Batch, r *resource) {
 					"opened_file":     util.OptSqlStringIfNotEmpty(info.OpenedFile),
 					"ssh_enabled":     info.SshEnabled,
 					"hostname":        info.Hostname.Sql(),
+					"sweep":           sweep,
+					"dependencies":    dependencies,
 				},
 			)
 		}
@@ -2421,12 +2753,15 @@ func jobTransform(
 			TimeAllocation:        info.TimeAllocation,
 			OpenedFile:            info.OpenedFile,
 			SshEnabled:            info.SshEnabled,
+			Sweep:                 info.Sweep,
+			Dependencies:          info.Dependencies,
 			ResourceSpecification: specification,
 		},
 		Status: orcapi.JobStatus{
 			State:             info.State,
 			JobParametersJson: util.OptValue(info.JobParametersJson),
 			StartedAt:         info.StartedAt,
+			ArrayTasks:        info.ArrayTasks,
 		},
 		Output: orcapi.JobOutput{
 			OutputFolder: info.OutputFolder,
@@ -2455,6 +2790,7 @@ func jobTransform(
 var jobNotificationsPending struct {
 	Mu            sync.RWMutex
 	EntriesByUser map[string]map[string]orcapi.Job // user -> job id -> job
+	Webhooks      []fndapi.WebhookEvent            // every state change, in order
 }
 
 func jobNotifyStateChange(job orcapi.Job) {
@@ -2463,6 +2799,24 @@ func jobNotifyStateChange(job orcapi.Job) {
 		return
 	}
 
+	data, _ := json.Marshal(map[string]any{
+		"jobId":       job.Id,
+		"name":        job.Specification.Name,
+		"state":       job.Status.State,
+		"application": job.Specification.Application,
+		"createdBy":   job.Owner.CreatedBy,
+		"project":     job.Owner.Project,
+	})
+
+	jobNotificationsPending.Mu.Lock()
+	jobNotificationsPending.Webhooks = append(jobNotificationsPending.Webhooks, fndapi.WebhookEvent{
+		Type:     fndapi.WebhookEventJobStateChanged,
+		Username: util.OptValue(job.Owner.CreatedBy),
+		Project:  job.Owner.Project,
+		Data:     data,
+	})
+	jobNotificationsPending.Mu.Unlock()
+
 	if job.Status.State != orcapi.JobStateInQueue {
 		jobNotificationsPending.Mu.Lock()
 		username := job.Owner.CreatedBy
@@ -2484,10 +2838,19 @@ func jobNotificationsLoopSendPending() {
 			copiedEntriesByUser[username] = jobs
 		}
 		jobNotificationsPending.EntriesByUser = map[string]map[string]orcapi.Job{}
+		webhookEvents := jobNotificationsPending.Webhooks
+		jobNotificationsPending.Webhooks = nil
 		jobNotificationsPending.Mu.Unlock()
 		for username, jobs := range copiedEntriesByUser {
 			jobSendNotifications(username, jobs)
 		}
+
+		if len(webhookEvents) > 0 {
+			_, err := fndapi.WebhooksEmit.Invoke(fndapi.BulkRequest[fndapi.WebhookEvent]{Items: webhookEvents})
+			if err != nil {
+				log.Warn("Could not emit job webhook events: %s", err)
+			}
+		}
 		time.Sleep(30 * time.Second)
 	}
 }
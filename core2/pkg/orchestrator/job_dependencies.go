@@ -0,0 +1,331 @@
+package orchestrator
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
+	orcapi "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Job dependencies
+// =====================================================================================================================
+// A job can depend on other jobs (called upstream jobs). A job with unsatisfied dependencies is created in the
+// JobStateWaiting state and is not submitted to the provider until all of its dependencies are satisfied. If one of
+// its dependencies can no longer be satisfied, then the job fails without ever being submitted.
+//
+// Providers which support dependencies natively can receive the job immediately, as long as all upstream jobs are
+// submitted to the same provider. In this case, the provider is responsible for holding the job. UCloud still tracks
+// the dependencies and terminates the job if a dependency can no longer be satisfied.
+//
+// The dependencies of a job cannot change after it has been created, and a job can only depend on jobs which already
+// exist. As a result, it is not possible to create cycles.
+
+const jobMaxDependencies = 32
+
+type jobDependencyStatus int
+
+const (
+	jobDependenciesPending jobDependencyStatus = iota
+	jobDependenciesSatisfied
+	jobDependenciesFailed
+)
+
+var jobDependents = struct {
+	Mu         sync.Mutex
+	ByUpstream map[string][]string // upstream job id -> dependent job ids
+}{
+	ByUpstream: map[string][]string{},
+}
+
+func initJobDependencies() {
+	jobLoadCache.Mu.Lock()
+	for !jobLoadCache.Ready {
+		jobLoadCache.Cond.Wait()
+	}
+
+	var waiting []string
+	for id, job := range jobLoadCache.Jobs {
+		if len(job.Dependencies) > 0 && !job.State.IsFinal() {
+			jobDependenciesTrack(fmt.Sprint(id), job.Dependencies)
+			if job.State == orcapi.JobStateWaiting {
+				waiting = append(waiting, fmt.Sprint(id))
+			}
+		}
+	}
+	jobLoadCache.Mu.Unlock()
+
+	// Upstream jobs might have finished while we were not running
+	for _, id := range waiting {
+		jobDependenciesReevaluate(id)
+	}
+}
+
+func jobValidateDependencies(actor rpc.Actor, spec *orcapi.JobSpecification) *util.HttpError {
+	if len(spec.Dependencies) > jobMaxDependencies {
+		return util.HttpErr(http.StatusBadRequest, "a job cannot have more than %d dependencies", jobMaxDependencies)
+	}
+
+	for _, resc := range spec.Resources {
+		if resc.Type == orcapi.AppParameterValueTypeApiServer {
+			return util.HttpErr(http.StatusBadRequest, "jobs with dependencies cannot use api_server resources")
+		}
+	}
+
+	var deduplicated []orcapi.JobDependency
+	for _, dep := range spec.Dependencies {
+		if !slices.Contains(orcapi.JobDependencyTypeOptions, dep.Type) {
+			return util.HttpErr(http.StatusBadRequest, "invalid dependency type for job %s", dep.JobId)
+		}
+
+		upstream, err := ResourceRetrieve[orcapi.Job](actor, jobType, ResourceParseId(dep.JobId), orcapi.ResourceFlags{})
+		if err != nil {
+			return util.HttpErr(http.StatusBadRequest, "unknown job in dependencies (%s)", dep.JobId)
+		}
+
+		if dep.Unsatisfiable(upstream.Status.State) {
+			return util.HttpErr(
+				http.StatusBadRequest,
+				"job %s has already ended in state %s and the dependency can never be satisfied",
+				dep.JobId,
+				upstream.Status.State,
+			)
+		}
+
+		if !slices.Contains(deduplicated, dep) {
+			deduplicated = append(deduplicated, dep)
+		}
+	}
+
+	spec.Dependencies = deduplicated
+	return nil
+}
+
+// jobDependenciesResolve determines the combined status of a list of dependencies. The upstream jobs which are still
+// pending are returned along with the status. If the dependencies have failed, then a reason is returned which can be
+// shown to the end-user.
+func jobDependenciesResolve(deps []orcapi.JobDependency) (jobDependencyStatus, string, []orcapi.Job) {
+	var pending []orcapi.Job
+	for _, dep := range deps {
+		upstream, err := ResourceRetrieve[orcapi.Job](
+			rpc.ActorSystem,
+			jobType,
+			ResourceParseId(dep.JobId),
+			orcapi.ResourceFlags{},
+		)
+
+		if err != nil {
+			return jobDependenciesFailed, "Job " + dep.JobId + " which this job depends on no longer exists.", nil
+		}
+
+		state := upstream.Status.State
+		if dep.Unsatisfiable(state) {
+			return jobDependenciesFailed, "Job " + dep.JobId + " which this job depends on ended in state " +
+				string(state) + ".", nil
+		} else if !dep.Satisfied(state) {
+			pending = append(pending, upstream)
+		}
+	}
+
+	if len(pending) > 0 {
+		return jobDependenciesPending, "", pending
+	} else {
+		return jobDependenciesSatisfied, "", nil
+	}
+}
+
+// jobDependenciesCanPassThrough returns true if the provider can hold the job until the pending upstream jobs have
+// finished.
+func jobDependenciesCanPassThrough(
+	spec *orcapi.JobSpecification,
+	backend orcapi.ToolBackend,
+	pending []orcapi.Job,
+) bool {
+	if backend != orcapi.ToolBackendNative {
+		return false
+	}
+
+	support, ok := SupportByProduct[orcapi.JobSupport](jobType, spec.Product)
+	if !ok || !support.Has(jobNativeDependencies) {
+		return false
+	}
+
+	for _, upstream := range pending {
+		if upstream.Specification.Product.Provider != spec.Product.Provider {
+			return false
+		}
+
+		if upstream.Status.State == orcapi.JobStateWaiting {
+			return false
+		}
+	}
+
+	return true
+}
+
+func jobDependenciesTrack(jobId string, deps []orcapi.JobDependency) {
+	jobDependents.Mu.Lock()
+	for _, dep := range deps {
+		current := jobDependents.ByUpstream[dep.JobId]
+		if !slices.Contains(current, jobId) {
+			jobDependents.ByUpstream[dep.JobId] = append(current, jobId)
+		}
+	}
+	jobDependents.Mu.Unlock()
+}
+
+// jobDependenciesOnFinished must be called when a job has reached a final state. The dependents of the job are
+// re-evaluated, this might cause them to be submitted or to fail.
+func jobDependenciesOnFinished(upstreamId string) {
+	jobDependents.Mu.Lock()
+	dependents := jobDependents.ByUpstream[upstreamId]
+	delete(jobDependents.ByUpstream, upstreamId)
+	jobDependents.Mu.Unlock()
+
+	for _, jobId := range dependents {
+		jobDependenciesReevaluate(jobId)
+	}
+}
+
+func jobDependenciesReevaluate(jobId string) {
+	job, err := ResourceRetrieve[orcapi.Job](rpc.ActorSystem, jobType, ResourceParseId(jobId), orcapi.ResourceFlags{
+		IncludeOthers:  true,
+		IncludeUpdates: true,
+		IncludeSupport: true,
+		IncludeProduct: true,
+	})
+
+	if err != nil || job.Status.State.IsFinal() || len(job.Specification.Dependencies) == 0 {
+		return
+	}
+
+	status, reason, _ := jobDependenciesResolve(job.Specification.Dependencies)
+	switch status {
+	case jobDependenciesPending:
+		// Nothing to do yet
+
+	case jobDependenciesFailed:
+		if job.Status.State == orcapi.JobStateWaiting {
+			if jobUpdateStateLocally(jobId, orcapi.JobStateWaiting, orcapi.JobStateFailure, reason) {
+				jobDependenciesOnFinished(jobId)
+			}
+		} else {
+			// The job is held by the provider, which must terminate it for us.
+			_, err := InvokeProvider(
+				job.Specification.Product.Provider,
+				orcapi.JobsProviderTerminate,
+				fndapi.BulkRequestOf(job),
+				ProviderCallOpts{
+					Username: util.OptValue(job.Owner.CreatedBy),
+					Reason:   util.OptValue("job dependency failed"),
+				},
+			)
+
+			if err != nil {
+				log.Info("Failed to terminate job %v after its dependencies failed: %s", jobId, err)
+			}
+		}
+
+	case jobDependenciesSatisfied:
+		if job.Status.State == orcapi.JobStateWaiting {
+			jobDependenciesSubmit(job)
+		}
+	}
+}
+
+// jobDependenciesSubmit submits a waiting job to its provider. This is done on behalf of the owner of the job, whose
+// access to the product is checked again since it might have changed while the job was waiting.
+func jobDependenciesSubmit(job orcapi.Job) {
+	fail := func(reason string) {
+		if jobUpdateStateLocally(job.Id, orcapi.JobStateWaiting, orcapi.JobStateFailure, reason) {
+			jobDependenciesOnFinished(job.Id)
+		}
+	}
+
+	owner, ok := rpc.LookupActor(job.Owner.CreatedBy)
+	if !ok {
+		fail("The owner of this job is no longer known to UCloud.")
+		return
+	}
+
+	if job.Owner.Project.Present {
+		_, isMember := owner.Membership[rpc.ProjectId(job.Owner.Project.Value)]
+		if !isMember {
+			fail("The owner of this job is no longer a member of the project.")
+			return
+		}
+		owner.Project.Set(rpc.ProjectId(job.Owner.Project.Value))
+	}
+
+	if err := ResourceValidateAllocation(owner, job.Specification.Product); err != nil {
+		fail(err.Why)
+		return
+	}
+
+	// The state transition guarantees that only one caller will submit the job to the provider
+	if !jobUpdateStateLocally(job.Id, orcapi.JobStateWaiting, orcapi.JobStateInQueue, "All dependencies have been satisfied.") {
+		return
+	}
+
+	job.Status.State = orcapi.JobStateInQueue
+	resp, err := InvokeProvider(job.Specification.Product.Provider, orcapi.JobsProviderCreate, fndapi.BulkRequestOf(job), ProviderCallOpts{
+		Username: util.OptValue(owner.Username),
+		Reason:   util.OptValue("Creating resource: " + jobType),
+	})
+
+	if err != nil {
+		if jobUpdateStateLocally(job.Id, orcapi.JobStateInQueue, orcapi.JobStateFailure, "Failed to submit job: "+err.Why) {
+			jobDependenciesOnFinished(job.Id)
+		}
+		return
+	}
+
+	if len(resp.Responses) > 0 && resp.Responses[0].Id != "" {
+		providerId := resp.Responses[0].Id
+		ResourceSystemUpdate(jobType, ResourceParseId(job.Id), func(r *resource, mapped orcapi.Job) {
+			r.ProviderId.Set(providerId)
+		})
+	}
+}
+
+// jobUpdateStateLocally changes the state of a job which UCloud is responsible for, i.e. one that has not been
+// submitted to a provider. The state is only changed if the job is in the expected state. Returns true if the state
+// was changed.
+func jobUpdateStateLocally(jobId string, expected orcapi.JobState, state orcapi.JobState, status string) bool {
+	changed := false
+	ResourceSystemUpdate(jobType, ResourceParseId(jobId), func(r *resource, mapped orcapi.Job) {
+		job := r.Extra.(*internalJob)
+		if job.State != expected {
+			return
+		}
+
+		changed = true
+		job.ChangeFlags |= internalJobPartialChange | internalJobChangeUpdates | internalJobChangeMetadata
+		job.State = state
+		job.Updates = append(job.Updates, orcapi.JobUpdate{
+			State:     util.OptValue(state),
+			Status:    util.OptValue(status),
+			Timestamp: fndapi.Timestamp(time.Now()),
+		})
+
+		mapped.Status.State = state
+		jobNotifyStateChange(mapped)
+
+		if state.IsFinal() {
+			for _, param := range job.Parameters {
+				jobUnbindResource(jobId, param)
+			}
+
+			for _, resc := range job.Resources {
+				jobUnbindResource(jobId, resc)
+			}
+		}
+	})
+	return changed
+}
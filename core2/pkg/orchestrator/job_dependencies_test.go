@@ -0,0 +1,183 @@
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"ucloud.dk/shared/pkg/assert"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	orcapi "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+)
+
+func initJobDependencyTest(t *testing.T) *rpc.Actor {
+	initResourceTest(t)
+	InitResourceType(jobType, resourceTypeCreateWithoutAdmin, nil, nil, jobTransform, nil)
+
+	providerSupportGlobals.Ready.Store(true)
+	if len(appCatalogGlobals.Buckets) == 0 {
+		appCatalogGlobals.Buckets = make([]appCatalogBucket, 1)
+	}
+
+	jobNotificationsPending.Mu.Lock()
+	jobNotificationsPending.EntriesByUser = map[string]map[string]orcapi.Job{}
+	jobNotificationsPending.Mu.Unlock()
+
+	jobDependents.Mu.Lock()
+	jobDependents.ByUpstream = map[string][]string{}
+	jobDependents.Mu.Unlock()
+
+	return actor("user", "")
+}
+
+func jobDependencyTestCreate(t *testing.T, u *rpc.Actor, state orcapi.JobState, deps ...orcapi.JobDependency) string {
+	id, _, err := ResourceCreate[orcapi.Job](
+		*u,
+		jobType,
+		orcapi.ResourceSpecification{},
+		&internalJob{
+			Application:  orcapi.NameAndVersion{Name: "unknown", Version: "unknown"},
+			State:        state,
+			Dependencies: deps,
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("failed to create job: %s", err)
+	}
+
+	jobId := fmt.Sprint(id)
+	if len(deps) > 0 {
+		jobDependenciesTrack(jobId, deps)
+	}
+	return jobId
+}
+
+func jobDependencyTestState(t *testing.T, jobId string) orcapi.JobState {
+	job, err := ResourceRetrieve[orcapi.Job](rpc.ActorSystem, jobType, ResourceParseId(jobId), orcapi.ResourceFlags{})
+	if err != nil {
+		t.Fatalf("failed to retrieve job %s: %s", jobId, err)
+	}
+	return job.Status.State
+}
+
+func afterOk(jobId string) orcapi.JobDependency {
+	return orcapi.JobDependency{JobId: jobId, Type: orcapi.JobDependencyAfterOk}
+}
+
+func afterAny(jobId string) orcapi.JobDependency {
+	return orcapi.JobDependency{JobId: jobId, Type: orcapi.JobDependencyAfterAny}
+}
+
+func TestJobDependenciesResolve(t *testing.T) {
+	u := initJobDependencyTest(t)
+
+	running := jobDependencyTestCreate(t, u, orcapi.JobStateRunning)
+	success := jobDependencyTestCreate(t, u, orcapi.JobStateSuccess)
+	failure := jobDependencyTestCreate(t, u, orcapi.JobStateFailure)
+
+	status, _, pending := jobDependenciesResolve([]orcapi.JobDependency{afterOk(running), afterOk(success)})
+	assert.Equal(t, jobDependenciesPending, status)
+	if assert.Equal(t, 1, len(pending)) {
+		assert.Equal(t, running, pending[0].Id)
+	}
+
+	status, _, _ = jobDependenciesResolve([]orcapi.JobDependency{afterOk(success), afterAny(failure)})
+	assert.Equal(t, jobDependenciesSatisfied, status)
+
+	status, reason, _ := jobDependenciesResolve([]orcapi.JobDependency{afterOk(running), afterOk(failure)})
+	assert.Equal(t, jobDependenciesFailed, status)
+	assert.Equal(t, "Job "+failure+" which this job depends on ended in state FAILURE.", reason)
+
+	status, reason, _ = jobDependenciesResolve([]orcapi.JobDependency{afterAny("999999")})
+	assert.Equal(t, jobDependenciesFailed, status)
+	assert.Equal(t, "Job 999999 which this job depends on no longer exists.", reason)
+}
+
+func TestJobValidateDependencies(t *testing.T) {
+	u := initJobDependencyTest(t)
+
+	running := jobDependencyTestCreate(t, u, orcapi.JobStateRunning)
+	failure := jobDependencyTestCreate(t, u, orcapi.JobStateFailure)
+
+	spec := &orcapi.JobSpecification{Dependencies: []orcapi.JobDependency{afterOk(running), afterOk(running), afterAny(failure)}}
+	assert.Nil(t, jobValidateDependencies(*u, spec))
+	assert.Equal(t, 2, len(spec.Dependencies))
+
+	spec = &orcapi.JobSpecification{Dependencies: []orcapi.JobDependency{afterOk(failure)}}
+	assert.NotNil(t, jobValidateDependencies(*u, spec))
+
+	spec = &orcapi.JobSpecification{Dependencies: []orcapi.JobDependency{{JobId: running, Type: "AFTER_NOT_OK"}}}
+	assert.NotNil(t, jobValidateDependencies(*u, spec))
+
+	// A job can only depend on jobs which already exist, this includes itself. As a result, cycles cannot be created.
+	spec = &orcapi.JobSpecification{Dependencies: []orcapi.JobDependency{afterOk("999999")}}
+	assert.NotNil(t, jobValidateDependencies(*u, spec))
+
+	// Jobs owned by other users are not visible
+	other := actor("other", "")
+	spec = &orcapi.JobSpecification{Dependencies: []orcapi.JobDependency{afterOk(running)}}
+	assert.NotNil(t, jobValidateDependencies(*other, spec))
+
+	spec = &orcapi.JobSpecification{}
+	for i := 0; i <= jobMaxDependencies; i++ {
+		spec.Dependencies = append(spec.Dependencies, afterOk(running))
+	}
+	assert.NotNil(t, jobValidateDependencies(*u, spec))
+}
+
+func TestJobDependenciesFailureIsPropagated(t *testing.T) {
+	u := initJobDependencyTest(t)
+
+	upstream := jobDependencyTestCreate(t, u, orcapi.JobStateRunning)
+	dependent := jobDependencyTestCreate(t, u, orcapi.JobStateWaiting, afterOk(upstream))
+	transitive := jobDependencyTestCreate(t, u, orcapi.JobStateWaiting, afterOk(dependent))
+	tolerant := jobDependencyTestCreate(t, u, orcapi.JobStateWaiting, afterOk(upstream), afterAny(dependent))
+
+	// Nothing happens while the upstream job is still running
+	jobDependenciesReevaluate(dependent)
+	assert.Equal(t, orcapi.JobStateWaiting, jobDependencyTestState(t, dependent))
+
+	assert.True(t, jobUpdateStateLocally(upstream, orcapi.JobStateRunning, orcapi.JobStateFailure, "Failed"))
+	jobDependenciesOnFinished(upstream)
+
+	assert.Equal(t, orcapi.JobStateFailure, jobDependencyTestState(t, dependent))
+	assert.Equal(t, orcapi.JobStateFailure, jobDependencyTestState(t, transitive))
+	assert.Equal(t, orcapi.JobStateFailure, jobDependencyTestState(t, tolerant))
+
+	jobDependents.Mu.Lock()
+	assert.Equal(t, 0, len(jobDependents.ByUpstream))
+	jobDependents.Mu.Unlock()
+}
+
+func TestJobDependenciesCancelWaitingJob(t *testing.T) {
+	u := initJobDependencyTest(t)
+
+	upstream := jobDependencyTestCreate(t, u, orcapi.JobStateRunning)
+	dependent := jobDependencyTestCreate(t, u, orcapi.JobStateWaiting, afterOk(upstream))
+	transitive := jobDependencyTestCreate(t, u, orcapi.JobStateWaiting, afterOk(upstream), afterAny(dependent))
+
+	_, err := JobsTerminateBulk(*u, fndapi.BulkRequestOf(fndapi.FindByStringId{Id: dependent}))
+	assert.Nil(t, err)
+	assert.Equal(t, orcapi.JobStateFailure, jobDependencyTestState(t, dependent))
+
+	// The dependents of the cancelled job are re-evaluated in the background. An afterany dependency is satisfied by
+	// the cancellation, but the job is still waiting for the upstream job which is why it must not be submitted.
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		jobDependents.Mu.Lock()
+		_, tracked := jobDependents.ByUpstream[dependent]
+		jobDependents.Mu.Unlock()
+		if !tracked {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Equal(t, orcapi.JobStateWaiting, jobDependencyTestState(t, transitive))
+
+	// Waiting jobs cannot be extended or suspended since the provider does not know about them yet
+	_, err = JobsSuspendBulk(*u, fndapi.BulkRequestOf(fndapi.FindByStringId{Id: transitive}))
+	assert.NotNil(t, err)
+}
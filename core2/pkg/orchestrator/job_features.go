@@ -11,6 +11,7 @@ const (
 	jobDockerPeers          SupportFeatureKey = "jobs.docker.peers"
 	jobDockerExtension      SupportFeatureKey = "jobs.docker.extension"
 	jobDockerBindLinkToPort SupportFeatureKey = "jobs.docker.bindLinkToPort"
+	jobDockerArrays         SupportFeatureKey = "jobs.docker.arrays"
 
 	jobNativeEnabled        SupportFeatureKey = "jobs.native.enabled"
 	jobNativeWeb            SupportFeatureKey = "jobs.native.web"
@@ -19,7 +20,10 @@ const (
 	jobNativeTerminal       SupportFeatureKey = "jobs.native.terminal"
 	jobNativePeers          SupportFeatureKey = "jobs.native.peers"
 	jobNativeExtension      SupportFeatureKey = "jobs.native.extension"
+	jobNativeSuspension     SupportFeatureKey = "jobs.native.suspension"
 	jobNativeBindLinkToPort SupportFeatureKey = "jobs.native.bindLinkToPort"
+	jobNativeArrays         SupportFeatureKey = "jobs.native.arrays"
+	jobNativeDependencies   SupportFeatureKey = "jobs.native.dependencies"
 
 	jobVmEnabled        SupportFeatureKey = "jobs.vm.enabled"
 	jobVmWeb            SupportFeatureKey = "jobs.vm.web"
@@ -30,6 +34,7 @@ const (
 	jobVmExtension      SupportFeatureKey = "jobs.vm.extension"
 	jobVmSuspension     SupportFeatureKey = "jobs.vm.suspension"
 	jobVmBindLinkToPort SupportFeatureKey = "jobs.vm.bindLinkToPort"
+	jobVmSnapshots      SupportFeatureKey = "jobs.vm.snapshots"
 )
 
 var jobFeatureTerminalByBackend = map[orcapi.ToolBackend]SupportFeatureKey{
@@ -74,6 +79,16 @@ var jobFeatureBindLinkToPortByBackend = map[orcapi.ToolBackend]SupportFeatureKey
 	orcapi.ToolBackendVirtualMachine: jobVmBindLinkToPort,
 }
 
+var jobFeatureSuspensionByBackend = map[orcapi.ToolBackend]SupportFeatureKey{
+	orcapi.ToolBackendNative:         jobNativeSuspension,
+	orcapi.ToolBackendVirtualMachine: jobVmSuspension,
+}
+
+var jobFeatureArraysByBackend = map[orcapi.ToolBackend]SupportFeatureKey{
+	orcapi.ToolBackendDocker: jobDockerArrays,
+	orcapi.ToolBackendNative: jobNativeArrays,
+}
+
 var jobFeatureMapper = []featureMapper{
 	{
 		Type: jobType,
@@ -115,6 +130,11 @@ var jobFeatureMapper = []featureMapper{
 		Key:  jobDockerBindLinkToPort,
 		Path: "docker.bindLinkToPort",
 	},
+	{
+		Type: jobType,
+		Key:  jobDockerArrays,
+		Path: "docker.arrays",
+	},
 
 	{
 		Type: jobType,
@@ -151,11 +171,26 @@ var jobFeatureMapper = []featureMapper{
 		Key:  jobNativeExtension,
 		Path: "native.timeExtension",
 	},
+	{
+		Type: jobType,
+		Key:  jobNativeSuspension,
+		Path: "native.suspension",
+	},
 	{
 		Type: jobType,
 		Key:  jobNativeBindLinkToPort,
 		Path: "native.bindLinkToPort",
 	},
+	{
+		Type: jobType,
+		Key:  jobNativeArrays,
+		Path: "native.arrays",
+	},
+	{
+		Type: jobType,
+		Key:  jobNativeDependencies,
+		Path: "native.dependencies",
+	},
 
 	{
 		Type: jobType,
@@ -202,4 +237,9 @@ var jobFeatureMapper = []featureMapper{
 		Key:  jobVmBindLinkToPort,
 		Path: "virtualMachine.bindLinkToPort",
 	},
+	{
+		Type: jobType,
+		Key:  jobVmSnapshots,
+		Path: "virtualMachine.snapshots",
+	},
 }
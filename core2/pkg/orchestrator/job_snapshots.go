@@ -0,0 +1,186 @@
+package orchestrator
+
+import (
+	"net/http"
+	"strings"
+
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	orcapi "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Job snapshots
+// =====================================================================================================================
+// Snapshots capture the disks of a virtual machine. The orchestrator does not store anything about snapshots, all
+// requests are validated and forwarded to the provider which owns the job. Providers advertise support through
+// JobSupport.VirtualMachine.Snapshots.
+
+func initJobSnapshots() {
+	orcapi.JobsCreateSnapshot.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[orcapi.JobsCreateSnapshotRequestItem]) (fndapi.BulkResponse[fndapi.FindByStringId], *util.HttpError) {
+		return JobsCreateSnapshotBulk(info.Actor, request)
+	})
+
+	orcapi.JobsBrowseSnapshots.Handler(func(info rpc.RequestInfo, request orcapi.JobsBrowseSnapshotsRequest) (orcapi.JobsBrowseSnapshotsResponse, *util.HttpError) {
+		return JobsBrowseSnapshots(info.Actor, request)
+	})
+
+	orcapi.JobsRestoreSnapshot.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[orcapi.JobsSnapshotRequestItem]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+		return jobsForwardSnapshotOperation(info.Actor, request, orcapi.JobsProviderRestoreSnapshot, "user initiated snapshot restore")
+	})
+
+	orcapi.JobsDeleteSnapshot.Handler(func(info rpc.RequestInfo, request fndapi.BulkRequest[orcapi.JobsSnapshotRequestItem]) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+		return jobsForwardSnapshotOperation(info.Actor, request, orcapi.JobsProviderDeleteSnapshot, "user initiated snapshot deletion")
+	})
+}
+
+func jobRetrieveForSnapshots(actor rpc.Actor, jobId string, permission orcapi.Permission) (orcapi.Job, *util.HttpError) {
+	resc, _, _, err := ResourceRetrieveEx[orcapi.Job](
+		actor,
+		jobType,
+		ResourceParseId(jobId),
+		permission,
+		orcapi.ResourceFlagsIncludeAll(),
+	)
+
+	if err != nil {
+		return orcapi.Job{}, util.HttpErr(http.StatusNotFound, "permission denied or job not found (%v)", jobId)
+	}
+
+	support, ok := SupportByProduct[orcapi.JobSupport](jobType, resc.Specification.Product)
+	if err := jobValidateSnapshots(resc, support, ok); err != nil {
+		return orcapi.Job{}, err
+	}
+
+	return resc, nil
+}
+
+func jobValidateSnapshots(job orcapi.Job, support ProductSupport[orcapi.JobSupport], supportOk bool) *util.HttpError {
+	app := job.Status.ResolvedApplication
+	isVm := app.Present && app.Value.Invocation.Tool.Tool.Present &&
+		app.Value.Invocation.Tool.Tool.Value.Description.Backend == orcapi.ToolBackendVirtualMachine
+
+	if !isVm || !supportOk || !support.Has(jobVmSnapshots) {
+		return util.HttpErr(http.StatusBadRequest, "snapshots are not supported by this job (%v)", job.Id)
+	}
+
+	if job.Status.State.IsFinal() {
+		return util.HttpErr(http.StatusBadRequest, "job is no longer running (%v)", job.Id)
+	}
+	return nil
+}
+
+func jobValidateSnapshotTitle(title string) (string, *util.HttpError) {
+	title = strings.TrimSpace(title)
+	if title == "" || len(title) > 128 || strings.Contains(title, "\n") {
+		return "", util.HttpErr(http.StatusBadRequest, "invalid snapshot title")
+	}
+	return title, nil
+}
+
+func JobsCreateSnapshotBulk(actor rpc.Actor, request fndapi.BulkRequest[orcapi.JobsCreateSnapshotRequestItem]) (fndapi.BulkResponse[fndapi.FindByStringId], *util.HttpError) {
+	var result fndapi.BulkResponse[fndapi.FindByStringId]
+
+	for _, item := range request.Items {
+		title, err := jobValidateSnapshotTitle(item.Title)
+		if err != nil {
+			return fndapi.BulkResponse[fndapi.FindByStringId]{}, err
+		}
+
+		resc, err := jobRetrieveForSnapshots(actor, item.JobId, orcapi.PermissionEdit)
+		if err != nil {
+			return fndapi.BulkResponse[fndapi.FindByStringId]{}, err
+		}
+
+		resp, err := InvokeProvider(
+			resc.Specification.Product.Provider,
+			orcapi.JobsProviderCreateSnapshot,
+			fndapi.BulkRequestOf(orcapi.JobsProviderCreateSnapshotRequestItem{Job: resc, Title: title}),
+			ProviderCallOpts{
+				Username: util.OptValue(actor.Username),
+				Reason:   util.OptValue("user initiated snapshot"),
+			},
+		)
+
+		if err != nil {
+			return fndapi.BulkResponse[fndapi.FindByStringId]{}, err
+		}
+
+		if len(resp.Responses) != 1 {
+			return fndapi.BulkResponse[fndapi.FindByStringId]{}, util.HttpErr(http.StatusBadGateway, "provider returned an invalid response")
+		}
+
+		result.Responses = append(result.Responses, resp.Responses[0])
+	}
+
+	return result, nil
+}
+
+func JobsBrowseSnapshots(actor rpc.Actor, request orcapi.JobsBrowseSnapshotsRequest) (orcapi.JobsBrowseSnapshotsResponse, *util.HttpError) {
+	resc, err := jobRetrieveForSnapshots(actor, request.JobId, orcapi.PermissionRead)
+	if err != nil {
+		return orcapi.JobsBrowseSnapshotsResponse{}, err
+	}
+
+	resp, err := InvokeProvider(
+		resc.Specification.Product.Provider,
+		orcapi.JobsProviderBrowseSnapshots,
+		orcapi.JobsProviderBrowseSnapshotsRequest{Job: resc},
+		ProviderCallOpts{
+			Username: util.OptValue(actor.Username),
+		},
+	)
+
+	if err != nil {
+		return orcapi.JobsBrowseSnapshotsResponse{}, err
+	}
+
+	if resp.Snapshots == nil {
+		resp.Snapshots = []orcapi.JobSnapshot{}
+	}
+	return resp, nil
+}
+
+func jobsForwardSnapshotOperation(
+	actor rpc.Actor,
+	request fndapi.BulkRequest[orcapi.JobsSnapshotRequestItem],
+	call rpc.Call[fndapi.BulkRequest[orcapi.JobsProviderSnapshotRequestItem], fndapi.BulkResponse[util.Empty]],
+	reason string,
+) (fndapi.BulkResponse[util.Empty], *util.HttpError) {
+	updatesByProvider := map[string][]orcapi.JobsProviderSnapshotRequestItem{}
+
+	for _, item := range request.Items {
+		if item.SnapshotId == "" {
+			return fndapi.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusBadRequest, "missing snapshot id")
+		}
+
+		resc, err := jobRetrieveForSnapshots(actor, item.JobId, orcapi.PermissionEdit)
+		if err != nil {
+			return fndapi.BulkResponse[util.Empty]{}, err
+		}
+
+		provider := resc.Specification.Product.Provider
+		updatesByProvider[provider] = append(updatesByProvider[provider], orcapi.JobsProviderSnapshotRequestItem{
+			Job:        resc,
+			SnapshotId: item.SnapshotId,
+		})
+	}
+
+	for provider, requests := range updatesByProvider {
+		_, err := InvokeProvider(
+			provider,
+			call,
+			fndapi.BulkRequestOf(requests...),
+			ProviderCallOpts{
+				Username: util.OptValue(actor.Username),
+				Reason:   util.OptValue(reason),
+			},
+		)
+
+		if err != nil {
+			return fndapi.BulkResponse[util.Empty]{}, err
+		}
+	}
+
+	return fndapi.BulkResponse[util.Empty]{Responses: make([]util.Empty, len(request.Items))}, nil
+}
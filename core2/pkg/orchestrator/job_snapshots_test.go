@@ -0,0 +1,68 @@
+package orchestrator
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"ucloud.dk/shared/pkg/assert"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	orcapi "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+)
+
+func jobSnapshotTestJob(state orcapi.JobState, backend orcapi.ToolBackend) orcapi.Job {
+	job := jobTestWithState(state)
+
+	app := orcapi.Application{}
+	app.Invocation.Tool.Tool.Set(orcapi.Tool{})
+	app.Invocation.Tool.Tool.Value.Description.Backend = backend
+	job.Status.ResolvedApplication.Set(app)
+	return job
+}
+
+func TestJobValidateSnapshots(t *testing.T) {
+	vm := jobSnapshotTestJob(orcapi.JobStateRunning, orcapi.ToolBackendVirtualMachine)
+	assert.Nil(t, jobValidateSnapshots(vm, jobTestSupport(jobVmSnapshots), true))
+
+	suspended := jobSnapshotTestJob(orcapi.JobStateSuspended, orcapi.ToolBackendVirtualMachine)
+	assert.Nil(t, jobValidateSnapshots(suspended, jobTestSupport(jobVmSnapshots), true))
+
+	// The provider must advertise snapshots for the product
+	assert.NotNil(t, jobValidateSnapshots(vm, jobTestSupport(jobVmSuspension), true))
+	assert.NotNil(t, jobValidateSnapshots(vm, jobTestSupport(jobVmSnapshots), false))
+
+	// Only virtual machines have snapshots
+	docker := jobSnapshotTestJob(orcapi.JobStateRunning, orcapi.ToolBackendDocker)
+	assert.NotNil(t, jobValidateSnapshots(docker, jobTestSupport(jobVmSnapshots), true))
+
+	unresolved := jobTestWithState(orcapi.JobStateRunning)
+	assert.NotNil(t, jobValidateSnapshots(unresolved, jobTestSupport(jobVmSnapshots), true))
+
+	// Snapshots of a job are deleted along with the job
+	stopped := jobSnapshotTestJob(orcapi.JobStateSuccess, orcapi.ToolBackendVirtualMachine)
+	assert.NotNil(t, jobValidateSnapshots(stopped, jobTestSupport(jobVmSnapshots), true))
+}
+
+func TestJobValidateSnapshotTitle(t *testing.T) {
+	title, err := jobValidateSnapshotTitle("  Before upgrade ")
+	assert.Nil(t, err)
+	assert.Equal(t, "Before upgrade", title)
+
+	for _, invalid := range []string{"", "   ", "first\nsecond", strings.Repeat("a", 129)} {
+		_, err = jobValidateSnapshotTitle(invalid)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestJobSnapshotOperationRequiresSnapshotId(t *testing.T) {
+	request := fndapi.BulkRequestOf(orcapi.JobsSnapshotRequestItem{JobId: "42"})
+
+	// The request is rejected before the job is looked up or the provider is contacted
+	_, err := jobsForwardSnapshotOperation(rpc.ActorSystem, request, orcapi.JobsProviderDeleteSnapshot, "test")
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+
+	_, err = jobsForwardSnapshotOperation(rpc.ActorSystem, request, orcapi.JobsProviderRestoreSnapshot, "test")
+	assert.NotNil(t, err)
+}
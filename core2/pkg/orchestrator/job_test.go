@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"ucloud.dk/shared/pkg/assert"
+	orcapi "ucloud.dk/shared/pkg/orchestrators"
+)
+
+func jobTestSupport(features ...SupportFeatureKey) ProductSupport[orcapi.JobSupport] {
+	result := ProductSupport[orcapi.JobSupport]{}
+	for _, feature := range features {
+		result.Features = append(result.Features, string(feature))
+	}
+	return result
+}
+
+func jobTestWithState(state orcapi.JobState) orcapi.Job {
+	job := orcapi.Job{}
+	job.Id = "42"
+	job.Status.State = state
+	return job
+}
+
+func TestJobValidateSuspension(t *testing.T) {
+	running := jobTestWithState(orcapi.JobStateRunning)
+
+	assert.Nil(t, jobValidateSuspension(running, orcapi.ToolBackendNative, jobTestSupport(jobNativeSuspension), true))
+	assert.Nil(t, jobValidateSuspension(running, orcapi.ToolBackendVirtualMachine, jobTestSupport(jobVmSuspension), true))
+
+	// The provider must advertise suspension for the backend of the application
+	assert.NotNil(t, jobValidateSuspension(running, orcapi.ToolBackendNative, jobTestSupport(), true))
+	assert.NotNil(t, jobValidateSuspension(running, orcapi.ToolBackendNative, jobTestSupport(jobVmSuspension), true))
+	assert.NotNil(t, jobValidateSuspension(running, orcapi.ToolBackendDocker, jobTestSupport(jobNativeSuspension, jobVmSuspension), true))
+	assert.NotNil(t, jobValidateSuspension(running, orcapi.ToolBackendNative, jobTestSupport(jobNativeSuspension), false))
+
+	waiting := jobTestWithState(orcapi.JobStateWaiting)
+	assert.NotNil(t, jobValidateSuspension(waiting, orcapi.ToolBackendNative, jobTestSupport(jobNativeSuspension), true))
+}
+
+func TestJobValidateExtension(t *testing.T) {
+	running := jobTestWithState(orcapi.JobStateRunning)
+
+	assert.Nil(t, jobValidateExtension(running, orcapi.ToolBackendDocker, jobTestSupport(jobDockerExtension), true))
+	assert.Nil(t, jobValidateExtension(running, orcapi.ToolBackendNative, jobTestSupport(jobNativeExtension), true))
+
+	assert.NotNil(t, jobValidateExtension(running, orcapi.ToolBackendNative, jobTestSupport(jobDockerExtension), true))
+	assert.NotNil(t, jobValidateExtension(running, orcapi.ToolBackendNative, jobTestSupport(jobNativeExtension), false))
+
+	waiting := jobTestWithState(orcapi.JobStateWaiting)
+	assert.NotNil(t, jobValidateExtension(waiting, orcapi.ToolBackendNative, jobTestSupport(jobNativeExtension), true))
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"strconv"
 	"strings"
 
@@ -252,7 +253,7 @@ func PublicIpUpdateFirewall(actor rpc.Actor, request fndapi.BulkRequest[orcapi.P
 			return featureNotSupportedError
 		}
 
-		err = publicIpValidateFirewall(item.Firewall)
+		err = publicIpValidateFirewall(&item.Firewall, supp.Has(publicIpFeatureFirewallSources))
 		if err != nil {
 			return err
 		}
@@ -312,7 +313,7 @@ func PublicIpCreate(actor rpc.Actor, request fndapi.BulkRequest[orcapi.PublicIPS
 				return nil, featureNotSupportedError
 			}
 
-			err := publicIpValidateFirewall(item.Firewall.Value)
+			err := publicIpValidateFirewall(&item.Firewall.Value, supp.Has(publicIpFeatureFirewallSources))
 			if err != nil {
 				return nil, err
 			}
@@ -433,15 +434,24 @@ func PublicIpUnbind(id string, jobId string) {
 	)
 }
 
-func publicIpValidateFirewall(firewall orcapi.Firewall) *util.HttpError {
-	for i, port := range firewall.OpenPorts {
+const publicIpMaxCidrsPerRule = 64
+
+// publicIpValidateFirewall validates the firewall and normalizes the CIDRs of all rules. Single addresses are accepted
+// and converted into a network containing only that address.
+func publicIpValidateFirewall(firewall *orcapi.Firewall, supportsSourceRestrictions bool) *util.HttpError {
+	for i := range firewall.OpenPorts {
 		var err *util.HttpError
+		port := &firewall.OpenPorts[i]
 		field := fmt.Sprintf("firewall.openPorts[%d]", i)
 
 		util.ValidateEnum(&port.Protocol, orcapi.IpProtocolOptions, field+".protocol", &err)
 		util.ValidateInteger(port.Start, field+".start", util.OptValue(0), util.OptValue(1024*64-1), &err)
 		util.ValidateInteger(port.End, field+".end", util.OptValue(0), util.OptValue(1024*64-1), &err)
 
+		if port.Direction != "" {
+			util.ValidateEnum(&port.Direction, orcapi.FirewallDirectionOptions, field+".direction", &err)
+		}
+
 		if err == nil && port.End < port.Start {
 			err = util.HttpErr(http.StatusBadRequest, "end must be larger than start")
 		}
@@ -449,10 +459,53 @@ func publicIpValidateFirewall(firewall orcapi.Firewall) *util.HttpError {
 		if err != nil {
 			return err
 		}
+
+		if len(port.AllowedCidrs) > 0 || port.IsEgress() {
+			if !supportsSourceRestrictions {
+				return featureNotSupportedError
+			}
+		}
+
+		if len(port.AllowedCidrs) > publicIpMaxCidrsPerRule {
+			return util.HttpErr(http.StatusBadRequest, "%s.allowedCidrs has too many entries (max %d)", field, publicIpMaxCidrsPerRule)
+		}
+
+		for j, rawCidr := range port.AllowedCidrs {
+			cidr, ok := publicIpParseCidr(rawCidr)
+			if !ok {
+				return util.HttpErr(http.StatusBadRequest, "%s.allowedCidrs[%d] is not a valid network: %s", field, j, rawCidr)
+			}
+
+			if cidr.Masked() != cidr {
+				return util.HttpErr(
+					http.StatusBadRequest,
+					"%s.allowedCidrs[%d] is not a valid network (did you mean %s?)",
+					field,
+					j,
+					cidr.Masked().String(),
+				)
+			}
+
+			port.AllowedCidrs[j] = cidr.String()
+		}
 	}
 	return nil
 }
 
+func publicIpParseCidr(value string) (netip.Prefix, bool) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		return prefix, err == nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil || addr.Zone() != "" {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), true
+}
+
 type internalPublicIp struct {
 	Firewall  orcapi.Firewall
 	BoundTo   []string
@@ -1,7 +1,8 @@
 package orchestrator
 
 const (
-	publicIpFeatureFirewall SupportFeatureKey = "publicIp.firewall"
+	publicIpFeatureFirewall        SupportFeatureKey = "publicIp.firewall"
+	publicIpFeatureFirewallSources SupportFeatureKey = "publicIp.firewall.sources"
 )
 
 var publicIpFeatureMapper = []featureMapper{
@@ -10,4 +11,9 @@ var publicIpFeatureMapper = []featureMapper{
 		Key:  publicIpFeatureFirewall,
 		Path: "firewall.enabled",
 	},
+	{
+		Type: publicIpType,
+		Key:  publicIpFeatureFirewallSources,
+		Path: "firewall.sourceRestrictions",
+	},
 }
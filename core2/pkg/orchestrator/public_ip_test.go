@@ -0,0 +1,48 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"ucloud.dk/shared/pkg/assert"
+	orcapi "ucloud.dk/shared/pkg/orchestrators"
+)
+
+func TestPublicIpValidateFirewall(t *testing.T) {
+	firewall := orcapi.Firewall{
+		OpenPorts: []orcapi.PortRangeAndProto{
+			{Start: 22, End: 22, Protocol: orcapi.IpProtocolTcp, AllowedCidrs: []string{" 192.168.1.0/24", "10.0.0.7"}},
+			{Start: 5432, End: 5432, Protocol: orcapi.IpProtocolTcp, AllowedCidrs: []string{"2001:db8::/32", "2001:db8::1"}},
+			{Start: 443, End: 443, Protocol: orcapi.IpProtocolTcp, Direction: orcapi.FirewallDirectionEgress},
+		},
+	}
+
+	assert.Nil(t, publicIpValidateFirewall(&firewall, true))
+	assert.Equal(t, "192.168.1.0/24", firewall.OpenPorts[0].AllowedCidrs[0])
+	assert.Equal(t, "10.0.0.7/32", firewall.OpenPorts[0].AllowedCidrs[1])
+	assert.Equal(t, "2001:db8::1/128", firewall.OpenPorts[1].AllowedCidrs[1])
+
+	// Restrictions require support from the provider while plain rules do not
+	assert.Equal(t, featureNotSupportedError, publicIpValidateFirewall(&firewall, false))
+	assert.Nil(t, publicIpValidateFirewall(&orcapi.Firewall{
+		OpenPorts: []orcapi.PortRangeAndProto{{Start: 80, End: 80, Protocol: orcapi.IpProtocolTcp}},
+	}, false))
+
+	invalid := [][]string{
+		{"192.168.1.1/24"},
+		{"not-an-ip"},
+		{"10.0.0.0/33"},
+		{"fe80::1%eth0"},
+	}
+
+	for _, cidrs := range invalid {
+		fw := orcapi.Firewall{
+			OpenPorts: []orcapi.PortRangeAndProto{{Start: 22, End: 22, Protocol: orcapi.IpProtocolTcp, AllowedCidrs: cidrs}},
+		}
+		assert.NotNil(t, publicIpValidateFirewall(&fw, true))
+	}
+
+	badDirection := orcapi.Firewall{
+		OpenPorts: []orcapi.PortRangeAndProto{{Start: 22, End: 22, Protocol: orcapi.IpProtocolTcp, Direction: "SIDEWAYS"}},
+	}
+	assert.NotNil(t, publicIpValidateFirewall(&badDirection, true))
+}
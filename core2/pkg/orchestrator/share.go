@@ -2,6 +2,7 @@ package orchestrator
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"path/filepath"
@@ -486,6 +487,23 @@ func ShareApprove(actor rpc.Actor, id string) *util.HttpError {
 			ishare.State = orcapi.ShareStateApproved
 		},
 	)
+
+	data, _ := json.Marshal(map[string]any{
+		"shareId":        share.Id,
+		"sharedWith":     share.Specification.SharedWith,
+		"sourceFilePath": share.Specification.SourceFilePath,
+		"permissions":    share.Specification.Permissions,
+	})
+
+	_, webhookErr := fndapi.WebhooksEmit.Invoke(fndapi.BulkRequestOf(fndapi.WebhookEvent{
+		Type:     fndapi.WebhookEventShareAccepted,
+		Username: util.OptValue(share.Owner.CreatedBy),
+		Project:  share.Owner.Project,
+		Data:     data,
+	}))
+	if webhookErr != nil {
+		log.Warn("Could not emit share webhook event: %s", webhookErr)
+	}
 	return nil
 }
 
@@ -121,6 +121,7 @@ const Allocations: React.FunctionComponent = () => {
         description: "No description",
         allowRequestsFrom: [],
         excludeRequestsFrom: [],
+        reviewQuorum: 0,
         templates: {
             type: "structured",
             structured: {
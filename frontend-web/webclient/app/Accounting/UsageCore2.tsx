@@ -95,6 +95,25 @@ export interface UsageReport {
             utilizationPercent100: number;
         }[];
     };
+
+    forecast?: UsageReportForecast | null;
+}
+
+export interface UsageReportForecast {
+    sampleCount: number;
+
+    usagePerDay: number;
+    usagePerDayLow: number;
+    usagePerDayHigh: number;
+
+    projectedDepletion?: number | null;
+    projectedDepletionEarliest?: number | null;
+    projectedDepletionLatest?: number | null;
+
+    allocationEnd?: number | null;
+    projectedUsageAtEnd?: number | null;
+    projectedUsageAtEndLow?: number | null;
+    projectedUsageAtEndHigh?: number | null;
 }
 
 export type UsageReportKpis = UsageReport["kpis"];
@@ -467,8 +486,12 @@ const UsagePage: React.FunctionComponent = () => {
             Math.max(0, Math.floor((r.kpis.nextMeaningfulExpiration - period.end) / (1000 * 60 * 60 * 24)));
 
         const combinedUsage = r.kpis.totalUsageAtEnd - r.kpis.totalUsageAtStart;
-        const burnRate = (combinedUsage) / periodLengthInDays;
-        const daysUntilDepletion = burnRate <= 0 ? null : Math.floor((r.kpis.quotaAtEnd - r.kpis.totalUsageAtEnd) / burnRate);
+        const forecast = r.forecast ?? null;
+        const burnRate = forecast ? forecast.usagePerDay : (combinedUsage) / periodLengthInDays;
+        const daysUntilDepletion = forecast ?
+            (forecast.projectedDepletion == null ? null :
+                Math.floor((forecast.projectedDepletion - period.end) / (1000 * 60 * 60 * 24))) :
+            burnRate <= 0 ? null : Math.floor((r.kpis.quotaAtEnd - r.kpis.totalUsageAtEnd) / burnRate);
 
         const overCommitRatio = r.kpis.quotaAtEnd != 0 ? r.kpis.totalAllocatedAtEnd / r.kpis.quotaAtEnd : 0;
         const childBurnRate = childConsumption / periodLengthInDays;
@@ -551,7 +574,7 @@ const UsagePage: React.FunctionComponent = () => {
                             <tbody>
                                 <tr>
                                     <th align={"left"}>
-                                        <TooltipV2 tooltip={"Average daily rate of use."}>
+                                        <TooltipV2 tooltip={"Average daily rate of use. When enough samples are available, this is the trend of the last 30 days."}>
                                             Burn-rate:
                                         </TooltipV2>
                                     </th>
@@ -589,6 +612,25 @@ const UsagePage: React.FunctionComponent = () => {
                                     </th>
                                     <td align={"right"}>{daysUntilExpiration} days</td>
                                 </tr>
+                                {forecast?.projectedUsageAtEnd == null ? null :
+                                    <tr>
+                                        <th align={"left"}>
+                                            <TooltipV2 tooltip={<>
+                                                Projected use when the next allocation expires, based on the rate of
+                                                use during the last 30 days. The range shows the lowest and highest
+                                                likely projection.
+                                            </>}>
+                                                Use at alloc exp.:
+                                            </TooltipV2>
+                                        </th>
+                                        <td align={"right"}>
+                                            <BalanceDisplay value={forecast.projectedUsageAtEnd}>
+                                                {" "}({balanceToString(forecast.projectedUsageAtEndLow ?? 0, true)}
+                                                {" "}- {balanceToString(forecast.projectedUsageAtEndHigh ?? 0, true)})
+                                            </BalanceDisplay>
+                                        </td>
+                                    </tr>
+                                }
                             </tbody>
                         </table>
                     </Flex>
@@ -1,6 +1,6 @@
 import {IconName} from "@/ui-components/Icon";
-import {apiBrowse, apiRetrieve, apiUpdate} from "@/Authentication/DataHook";
-import {BulkRequest, PageV2, PaginationRequestV2} from "@/UCloud";
+import {apiBrowse, apiCreate, apiDelete, apiRetrieve, apiUpdate} from "@/Authentication/DataHook";
+import {BulkRequest, BulkResponse, FindByStringId, PageV2, PaginationRequestV2} from "@/UCloud";
 import {getProviderTitle} from "@/Providers/ProviderTitle";
 import {ThemeColor} from "@/ui-components/theme";
 import {timestampUnixMs} from "@/UtilityFunctions";
@@ -1441,6 +1441,81 @@ export function updateAllocationV2(request: BulkRequest<{
     return apiUpdate(request, baseContextV2, "updateAllocation");
 }
 
+export function transferQuota(request: BulkRequest<{
+    sourceAllocationId: number,
+    targetAllocationId: number,
+    amount: number,
+    reason: string
+}>): APICallParameters {
+    return apiUpdate(request, baseContextV2, "transferQuota");
+}
+
+export type UsageExportFormat = "CSV" | "JSONL";
+export type UsageExportGranularity = "DAY" | "MONTH" | "PERIOD";
+
+export function exportUsage(request: {
+    start: number,
+    end: number,
+    granularity: UsageExportGranularity,
+    format: UsageExportFormat,
+}): APICallParameters<unknown, {fileName: string, data: string}> {
+    return apiUpdate(request, baseContextV2, "exportUsage");
+}
+
+export type AllocationHistoryChangeType = "TRANSFER_IN" | "TRANSFER_OUT";
+
+export interface AllocationHistoryEntry {
+    allocationId: number;
+    timestamp: number;
+    changedBy: string;
+    type: AllocationHistoryChangeType;
+    quotaBefore: number;
+    quotaAfter: number;
+    relatedAllocationId?: number | null;
+    reason: string;
+}
+
+export function retrieveAllocationHistory(request: {allocationId: number}): APICallParameters {
+    return apiRetrieve(request, baseContextV2, "allocationHistory");
+}
+
+export type BudgetAlertType = "USAGE_PERCENT" | "DAILY_BURN_RATE";
+
+export interface BudgetAlertSpecification {
+    category: ProductCategoryId;
+    type: BudgetAlertType;
+    // Percentage of the active quota for USAGE_PERCENT, usage within 24 hours for DAILY_BURN_RATE
+    threshold: number;
+}
+
+export interface BudgetAlert {
+    id: string;
+    owner: WalletOwner;
+    createdBy: string;
+    createdAt: number;
+    specification: BudgetAlertSpecification;
+    status: {
+        triggered: boolean;
+        lastTriggeredAt?: number | null;
+    };
+}
+
+const budgetAlertsContext = "/api/budgetAlerts";
+
+export function browseBudgetAlerts(request: PaginationRequestV2): APICallParameters<unknown, PageV2<BudgetAlert>> {
+    return apiBrowse(request, budgetAlertsContext);
+}
+
+export function createBudgetAlerts(
+    request: BulkRequest<BudgetAlertSpecification>
+): APICallParameters<unknown, BulkResponse<FindByStringId>> {
+    return apiCreate(request, budgetAlertsContext);
+}
+
+export function deleteBudgetAlerts(request: BulkRequest<FindByStringId>): APICallParameters {
+    return apiDelete(request, budgetAlertsContext);
+}
+
 export interface UsageOverTimeDatePointAPI {
     usage: number;
     quota: number;
@@ -20,6 +20,7 @@ const defaultSetting: Grants.RequestSettings = {
     description: "No description",
     allowRequestsFrom: [],
     excludeRequestsFrom: [],
+    reviewQuorum: 0,
     templates: {
         type: "structured",
         structured: {
@@ -184,6 +184,7 @@ function JobBrowse({opts}: {opts?: ResourceBrowserOpts<Job> & {omitBreadcrumbs?:
                         key: "filterState",
                         options: [
                             filterOption("In queue", "IN_QUEUE", "hashtag", "textPrimary"),
+                            filterOption("Waiting", "WAITING", "hashtag", "textPrimary"),
                             filterOption("Running", "RUNNING", "hashtag", "textPrimary",),
                             filterOption("Success", "SUCCESS", "check", "textPrimary"),
                             filterOption("Failure", "FAILURE", "close", "textPrimary"),
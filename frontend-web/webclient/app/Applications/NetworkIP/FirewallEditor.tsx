@@ -76,6 +76,11 @@ export function parseAndValidatePorts(first: string, last: string) {
     return {firstPort, lastPort, valid}
 }
 
+function firewallRuleScope(rule: compute.PortRangeAndProto): string {
+    const networks = rule.allowedCidrs?.length ? rule.allowedCidrs.join(", ") : "Anywhere";
+    return rule.direction === "EGRESS" ? `Outgoing to ${networks}` : networks;
+}
+
 interface FirewallTableProps {
     openPorts: compute.PortRangeAndProto[];
     isCreating?: boolean;
@@ -106,6 +111,7 @@ export function FirewallTable({isCreating, didChange, onAddRow, onRemoveRow, ope
                         <TableHeaderCell textAlign={"left"}>Port (First)<MandatoryField /></TableHeaderCell>
                         <TableHeaderCell textAlign={"left"}>Port (Last)</TableHeaderCell>
                         <TableHeaderCell textAlign={"left"}>Protocol</TableHeaderCell>
+                        <TableHeaderCell textAlign={"left"}>Allowed from</TableHeaderCell>
                         <TableHeaderCell width={"48px"} />
                     </TableRow>
                 </TableHeader>
@@ -115,6 +121,7 @@ export function FirewallTable({isCreating, didChange, onAddRow, onRemoveRow, ope
                             <TableCell>{row.start}</TableCell>
                             <TableCell>{row.end}</TableCell>
                             <TableCell>{row.protocol}</TableCell>
+                            <TableCell>{firewallRuleScope(row)}</TableCell>
                             <TableCell>
                                 <Button
                                     width="100%"
@@ -136,6 +143,7 @@ export function FirewallTable({isCreating, didChange, onAddRow, onRemoveRow, ope
                                 <option>UDP</option>
                             </Select>
                         </TableCell>
+                        <TableCell>Anywhere</TableCell>
                         <TableCell>
                             <Button type={"submit"} fullWidth><Icon name={"heroPlus"} /></Button>
                         </TableCell>
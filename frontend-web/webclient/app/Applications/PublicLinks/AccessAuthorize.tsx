@@ -0,0 +1,47 @@
+import * as React from "react";
+import {useEffect, useState} from "react";
+import {useLocation, useParams} from "react-router-dom";
+import PublicLinkApi from "@/UCloud/PublicLinkApi";
+import {Box, Heading, MainContainer, Text} from "@/ui-components";
+import {callAPI} from "@/Authentication/DataHook";
+import {getQueryParamOrElse} from "@/Utilities/URIUtilities";
+import {extractErrorMessage} from "@/UtilityFunctions";
+
+// Landing page for links which are restricted to project members or specific users. The gateway of the provider
+// sends users here when they do not have a session with the link.
+const AccessAuthorize: React.FunctionComponent = () => {
+    const id = useParams<{id: string}>().id ?? "";
+    const location = useLocation();
+    const redirect = getQueryParamOrElse(location.search, "redirect", "/");
+    const [error, setError] = useState<string | null>(null);
+
+    useEffect(() => {
+        let didCancel = false;
+        (async () => {
+            try {
+                const response = await callAPI(PublicLinkApi.authorizeAccess({id, redirect}));
+                if (!didCancel) window.location.href = response.redirectTo;
+            } catch (e) {
+                if (!didCancel) setError(extractErrorMessage(e));
+            }
+        })();
+
+        return () => {
+            didCancel = true;
+        };
+    }, [id, redirect]);
+
+    return <MainContainer main={
+        <Box mt={"32px"}>
+            {error == null ?
+                <Text>Checking your access to the application...</Text> :
+                <>
+                    <Heading.h3>You do not have access to this application</Heading.h3>
+                    <Text mt={"16px"}>{error}</Text>
+                </>
+            }
+        </Box>
+    } />;
+};
+
+export default AccessAuthorize;
@@ -0,0 +1,79 @@
+import * as React from "react";
+import {useCallback, useState} from "react";
+import PublicLinkApi, {accessModeTitle, PublicLink, PublicLinkAccessMode} from "@/UCloud/PublicLinkApi";
+import {Box, Button, Input, Label, Select, Text, TextArea} from "@/ui-components";
+import TabbedCard, {TabbedCardTab} from "@/ui-components/TabbedCard";
+import {useCloudCommand} from "@/Authentication/DataHook";
+import {bulkRequestOf} from "@/UtilityFunctions";
+import {sendSuccessNotification} from "@/Notifications";
+
+export const AccessEditor: React.FunctionComponent<{
+    inspecting: PublicLink;
+    reload: () => void;
+}> = ({inspecting, reload}) => {
+    const [commandLoading, invokeCommand] = useCloudCommand();
+    const current = inspecting.specification.access;
+    const [mode, setMode] = useState<PublicLinkAccessMode>(current?.mode ?? "PUBLIC");
+    const [users, setUsers] = useState((current?.users ?? []).join("\n"));
+    const [password, setPassword] = useState("");
+
+    const modes: PublicLinkAccessMode[] = ["PUBLIC", "USERS", "PASSWORD"];
+    if (inspecting.owner.project) modes.splice(1, 0, "PROJECT");
+
+    const onSave = useCallback(async (e: React.FormEvent) => {
+        e.preventDefault();
+        const result = await invokeCommand(PublicLinkApi.updateAccess(bulkRequestOf({
+            id: inspecting.id,
+            access: {
+                mode,
+                users: mode === "USERS" ? users.split(/[\s,]+/).filter(it => it.length > 0) : undefined,
+                password: mode === "PASSWORD" && password.length > 0 ? password : undefined,
+            }
+        })));
+
+        if (result != null) {
+            setPassword("");
+            sendSuccessNotification("Access to " + inspecting.specification.domain + " has been updated");
+            reload();
+        }
+    }, [inspecting, mode, users, password, reload]);
+
+    return <TabbedCard>
+        <TabbedCardTab name={"Access"} icon={"heroLockClosed"}>
+            <form onSubmit={onSave}>
+                <Box mb={"16px"}>
+                    <Label>Who can access this link?</Label>
+                    <Select value={mode} onChange={e => setMode(e.target.value as PublicLinkAccessMode)}>
+                        {modes.map(it => <option key={it} value={it}>{accessModeTitle(it)}</option>)}
+                    </Select>
+                </Box>
+
+                {mode !== "USERS" ? null :
+                    <Box mb={"16px"}>
+                        <Label>Usernames (one per line)</Label>
+                        <TextArea rows={4} width={"100%"} value={users} onChange={e => setUsers(e.currentTarget.value)} />
+                    </Box>
+                }
+
+                {mode !== "PASSWORD" ? null :
+                    <Box mb={"16px"}>
+                        <Label>Password</Label>
+                        <Input
+                            type={"password"}
+                            value={password}
+                            placeholder={current?.mode === "PASSWORD" ? "Leave empty to keep the current password" : ""}
+                            onChange={e => setPassword(e.currentTarget.value)}
+                        />
+                    </Box>
+                }
+
+                <Text mb={"16px"} color={"textSecondary"}>
+                    Users who can view this link in UCloud can always open it, unless it is protected by a password.
+                    Changing the access will sign out everyone who is currently using the link.
+                </Text>
+
+                <Button type={"submit"} disabled={commandLoading}>Save</Button>
+            </form>
+        </TabbedCardTab>
+    </TabbedCard>;
+};
@@ -40,7 +40,7 @@ import Flex from "@/ui-components/Flex";
 import Button from "@/ui-components/Button";
 import Input from "@/ui-components/Input";
 import Text from "@/ui-components/Text";
-import {Box, ExternalLink, Label} from "@/ui-components";
+import {Box, Checkbox, ExternalLink, Label} from "@/ui-components";
 import {FindByStringId} from "@/UCloud";
 import {addProjectListener, removeProjectListener} from "@/Project/ReduxState";
 import {LicenseSupport} from "@/UCloud/LicenseApi";
@@ -431,6 +431,7 @@ export function ProductSelectorWithPermissions<T extends Resource>({onCreate, du
     const [product, setSelectedProduct] = React.useState<ProductV2 | null>(null);
     const [support, setSupport] = React.useState<PublicLinkSupport | LicenseSupport>();
     const [entryId, setEntryId] = React.useState("");
+    const [useCustomDomain, setUseCustomDomain] = React.useState(false);
     const [acls, setAcls] = React.useState<ResourceAclEntry[]>([]);
     const project = useProject().fetch();
     const projectId = useProjectId();
@@ -458,8 +459,10 @@ export function ProductSelectorWithPermissions<T extends Resource>({onCreate, du
         }
     }, [products]);
 
-    const domainPrefix = !isPublicLink ? "" : (support?.["domainPrefix"] ?? "app-");
-    const domainSuffix = !isPublicLink ? "" : (support?.["domainSuffix"] ?? ".example.com");
+    const supportsCustomDomains = isPublicLink && support?.["customDomains"] === true;
+    const isCustomDomain = supportsCustomDomains && useCustomDomain;
+    const domainPrefix = !isPublicLink || isCustomDomain ? "" : (support?.["domainPrefix"] ?? "app-");
+    const domainSuffix = !isPublicLink || isCustomDomain ? "" : (support?.["domainSuffix"] ?? ".example.com");
     let explanation: React.ReactNode;
     if (isPublicLink) {
         explanation = <Box mt={"8px"}>
@@ -500,11 +503,22 @@ export function ProductSelectorWithPermissions<T extends Resource>({onCreate, du
             Choose a link<MandatoryField />
             <Flex alignItems={"center"} gap={"8px"}>
                 <Box flexShrink={0}>{domainPrefix}</Box>
-                <Input placeholder={"my-link"} onChange={e => setEntryId(e.target.value)} autoFocus />
+                <Input placeholder={isCustomDomain ? "app.example.org" : "my-link"} onChange={e => setEntryId(e.target.value)} autoFocus />
                 <Box flexShrink={0}>{domainSuffix}</Box>
             </Flex>
         </Label> : null}
 
+        {supportsCustomDomains ? <Label>
+            <Checkbox checked={useCustomDomain} onChange={() => setUseCustomDomain(!useCustomDomain)} />
+            Use a domain I own
+        </Label> : null}
+
+        {isCustomDomain ? <Text color={"textSecondary"}>
+            After creating the link you must prove that you own the domain by adding a TXT record to it. The record is
+            shown next to the link once it has been created. The domain must also point to {shortProviderId}. Links
+            which are not verified within 48 hours are deleted.
+        </Text> : null}
+
         <Box>
             <Label>Choose a {title.toLowerCase()} type<MandatoryField /></Label>
             <ProductSelector slim onSelect={setProductAndSupport} products={products} selected={product} />
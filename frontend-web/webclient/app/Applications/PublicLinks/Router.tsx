@@ -1,10 +1,15 @@
 import * as React from "react";
+import {Route, Routes} from "react-router-dom";
 import {ResourceRouter} from "@/Resource/Router";
 import {PublicLinkBrowse} from "./PublicLinkBrowse";
 import PublicLinkApi from "@/UCloud/PublicLinkApi";
+import AccessAuthorize from "./AccessAuthorize";
 
 const Router: React.FunctionComponent = () => {
-    return <ResourceRouter api={PublicLinkApi} Browser={PublicLinkBrowse}/>;
+    return <Routes>
+        <Route path={"/access/:id"} element={<AccessAuthorize />} />
+        <Route path={"/*"} element={<ResourceRouter api={PublicLinkApi} Browser={PublicLinkBrowse}/>} />
+    </Routes>;
 };
 
 export default Router;
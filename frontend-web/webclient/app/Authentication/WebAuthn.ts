@@ -0,0 +1,80 @@
+// Helpers for the WebAuthn ceremonies. The backend encodes all binary values as base64url strings, while the browser
+// API works with ArrayBuffers.
+
+export function isWebAuthnSupported(): boolean {
+    return typeof window !== "undefined" && window.PublicKeyCredential !== undefined &&
+        navigator.credentials !== undefined;
+}
+
+function base64UrlToBuffer(value: string): ArrayBuffer {
+    const base64 = value.replace(/-/g, "+").replace(/_/g, "/");
+    const padded = base64 + "=".repeat((4 - base64.length % 4) % 4);
+    const binary = atob(padded);
+    const bytes = new Uint8Array(binary.length);
+    for (let i = 0; i < binary.length; i++) bytes[i] = binary.charCodeAt(i);
+    return bytes.buffer;
+}
+
+function bufferToBase64Url(buffer: ArrayBuffer | null | undefined): string | undefined {
+    if (!buffer) return undefined;
+    const bytes = new Uint8Array(buffer);
+    let binary = "";
+    for (let i = 0; i < bytes.length; i++) binary += String.fromCharCode(bytes[i]);
+    return btoa(binary).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+}
+
+function decodeDescriptors(descriptors?: {id: string}[]): PublicKeyCredentialDescriptor[] | undefined {
+    return descriptors?.map(it => ({...it, id: base64UrlToBuffer(it.id)}) as PublicKeyCredentialDescriptor);
+}
+
+function serializeCredential(credential: PublicKeyCredential): unknown {
+    const response = credential.response as AuthenticatorAttestationResponse & AuthenticatorAssertionResponse;
+    return {
+        id: credential.id,
+        rawId: bufferToBase64Url(credential.rawId),
+        type: credential.type,
+        authenticatorAttachment: credential.authenticatorAttachment ?? undefined,
+        clientExtensionResults: credential.getClientExtensionResults(),
+        response: {
+            clientDataJSON: bufferToBase64Url(response.clientDataJSON),
+            attestationObject: bufferToBase64Url(response.attestationObject),
+            transports: response.getTransports?.(),
+            authenticatorData: bufferToBase64Url(response.authenticatorData),
+            signature: bufferToBase64Url(response.signature),
+            userHandle: bufferToBase64Url(response.userHandle),
+        },
+    };
+}
+
+// Performs the registration ceremony using the options returned by auth/2fa/beginWebAuthnRegistration. The result
+// should be sent to auth/2fa/finishWebAuthnRegistration.
+export async function webAuthnCreate(options: any): Promise<unknown> {
+    const publicKey = options.publicKey;
+    const credential = await navigator.credentials.create({
+        publicKey: {
+            ...publicKey,
+            challenge: base64UrlToBuffer(publicKey.challenge),
+            user: {...publicKey.user, id: base64UrlToBuffer(publicKey.user.id)},
+            excludeCredentials: decodeDescriptors(publicKey.excludeCredentials),
+        }
+    });
+
+    if (credential === null) throw new Error("No credential was created");
+    return serializeCredential(credential as PublicKeyCredential);
+}
+
+// Performs the authentication ceremony using the options returned by auth/2fa/webAuthnChallenge. The result should be
+// sent as the webAuthnResponse of the challenge answer.
+export async function webAuthnGet(options: any): Promise<unknown> {
+    const publicKey = options.publicKey;
+    const credential = await navigator.credentials.get({
+        publicKey: {
+            ...publicKey,
+            challenge: base64UrlToBuffer(publicKey.challenge),
+            allowCredentials: decodeDescriptors(publicKey.allowCredentials),
+        }
+    });
+
+    if (credential === null) throw new Error("No credential was selected");
+    return serializeCredential(credential as PublicKeyCredential);
+}
@@ -87,6 +87,7 @@ import {IngoingSharesBrowse} from "@/Files/Shares";
 import {JobsRouter} from "@/Applications/Jobs/Router";
 import {DrivesRouter, FilesRouter} from "@/Files/Router";
 import FilesVisualization from "@/Files/Visualization";
+import FilesRetention from "@/Files/Retention";
 import LicenseRouter from "./Applications/Licenses";
 import PublicLinksRouter from "@/Applications/PublicLinks/Router";
 import SharesApi from "@/UCloud/SharesApi";
@@ -125,6 +126,7 @@ const Core = (): React.ReactNode => (
                            element={React.createElement(requireAuth(Dashboard))} />
                     <Route path={"/drives/*"} element={React.createElement(requireAuth(DrivesRouter))} />
                     <Route path={AppRoutes.files.visualize()} element={React.createElement(requireAuth(FilesVisualization))} />
+                    <Route path={AppRoutes.files.retention()} element={React.createElement(requireAuth(FilesRetention))} />
                     <Route path="/files/*" element={React.createElement(requireAuth(FilesRouter))} />
 
                     <Route path={AppRoutes.users.registration()} element={<Registration />} />
@@ -0,0 +1,107 @@
+import * as React from "react";
+import {useLocation} from "react-router-dom";
+import {useCloudAPI} from "@/Authentication/DataHook";
+import {usePage} from "@/Navigation/Redux";
+import {useProjectId} from "@/Project/Api";
+import AppRoutes from "@/Routes";
+import FilesApi from "@/UCloud/FilesApi";
+import {FilesRetentionResponse} from "@/UCloud/UFile";
+import {Box, Card, Error, Link, Text} from "@/ui-components";
+import * as Heading from "@/ui-components/Heading";
+import MainContainer from "@/ui-components/MainContainer";
+import {SidebarTabId} from "@/ui-components/SidebarComponents";
+import Table, {TableCell, TableHeader, TableHeaderCell, TableRow} from "@/ui-components/Table";
+import Warning from "@/ui-components/Warning";
+import {usePrettyFilePath} from "@/Files/FilePath";
+import {getParentPath, sizeToString} from "@/Utilities/FileUtilities";
+import {dateToString, dateToStringNoTime} from "@/Utilities/DateUtilities";
+import {pluralize} from "@/Utilities/TextUtilities";
+import {getQueryParam} from "@/Utilities/URIUtilities";
+import {UcxSpinner} from "@/UCX/UcxView";
+
+const emptyResponse: FilesRetentionResponse = {
+    maxAgeDays: 0,
+    warningDays: 0,
+    entries: [],
+    lastUpdatedAt: null,
+    fileCount: 0,
+    sizeInBytes: 0,
+    complete: true,
+};
+
+// Dry-run of the retention policy. Lists the files which will be deleted unless they are used before they expire.
+export default function FilesRetention(): React.ReactNode {
+    usePage("Expiring files", SidebarTabId.FILES);
+    const projectId = useProjectId();
+    const location = useLocation();
+    const path = getQueryParam(location.search, "path") ?? "";
+    const prettyPath = usePrettyFilePath(path);
+    const [retention, fetchRetention] = useCloudAPI<FilesRetentionResponse>({noop: true}, emptyResponse);
+
+    React.useEffect(() => {
+        if (path === "") return;
+        fetchRetention({...FilesApi.retention({path}), projectOverride: projectId});
+    }, [path, projectId]);
+
+    const data = retention.data;
+    return <MainContainer
+        main={<Box>
+            <Heading.h2>Expiring files in {prettyPath}</Heading.h2>
+            {retention.loading ? <UcxSpinner /> :
+                retention.error ? <Error error={retention.error.why} /> :
+                    <>
+                        <Text my="16px">
+                            Files which have not been used for {data.maxAgeDays} days are deleted automatically.
+                            {" "}{data.fileCount.toLocaleString()} {pluralize(data.fileCount, "file")}
+                            {" "}({sizeToString(data.sizeInBytes)}) will be deleted within the
+                            next {data.warningDays} days unless they are used. Move any files you wish to keep to
+                            a different drive.
+                        </Text>
+
+                        {data.lastUpdatedAt == null ? null :
+                            <Text mb="16px" color="textSecondary">
+                                Based on the storage index from {dateToString(data.lastUpdatedAt)}.
+                            </Text>
+                        }
+
+                        {data.complete ? null :
+                            <Box mb="16px">
+                                <Warning warning={`Only the first ${data.entries.length.toLocaleString()} files are shown.`} />
+                            </Box>
+                        }
+
+                        {data.entries.length === 0 ? null :
+                            <Card>
+                                <Table tableType="presentation">
+                                    <TableHeader>
+                                        <TableRow>
+                                            <TableHeaderCell>File</TableHeaderCell>
+                                            <TableHeaderCell width="120px">Size</TableHeaderCell>
+                                            <TableHeaderCell width="150px">Last used</TableHeaderCell>
+                                            <TableHeaderCell width="150px">Expires</TableHeaderCell>
+                                        </TableRow>
+                                    </TableHeader>
+                                    <tbody>
+                                        {data.entries.map(entry => <TableRow key={entry.path}>
+                                            <TableCell>
+                                                <Link to={AppRoutes.files.path(getParentPath(entry.path))}>
+                                                    <RetentionPath path={entry.path} />
+                                                </Link>
+                                            </TableCell>
+                                            <TableCell>{sizeToString(entry.sizeInBytes)}</TableCell>
+                                            <TableCell>{dateToStringNoTime(entry.lastAccessedAt)}</TableCell>
+                                            <TableCell>{dateToStringNoTime(entry.expiresAt)}</TableCell>
+                                        </TableRow>)}
+                                    </tbody>
+                                </Table>
+                            </Card>
+                        }
+                    </>
+            }
+        </Box>}
+    />;
+}
+
+function RetentionPath({path}: {path: string}): React.ReactNode {
+    return usePrettyFilePath(path);
+}
@@ -15,6 +15,7 @@ export function browse(
         filter?: ApplicationFilter,
         includeIngoingApplications?: boolean,
         includeOutgoingApplications?: boolean,
+        callId?: string,
     }
 ): APICallParameters<unknown, PageV2<Application>> {
     return apiBrowse(request, baseContext);
@@ -79,6 +80,7 @@ interface GrantsExportLine {
     grantGiver: string;
     latestUpdatedAt: number;
     resources: Record<string, number>;
+    reviews: GrantReviewAssignment[];
 }
 
 export function exportGrants(): APICallParameters<{}, GrantsExportLine[]> {
@@ -109,6 +111,101 @@ export function deleteComment(
     return apiUpdate(request, baseContext, "deleteComment");
 }
 
+// Reviews
+// ====================================================================================================================
+export function updateReviewers(
+    request: {
+        applicationId: string,
+        reviewers: string[],
+    }
+): APICallParameters<unknown, {}> {
+    return apiUpdate(request, baseContext, "updateReviewers");
+}
+
+export function submitReview(
+    request: {
+        applicationId: string,
+        scores: GrantReviewScore[],
+        comment: string,
+    }
+): APICallParameters<unknown, {}> {
+    return apiUpdate(request, baseContext, "submitReview");
+}
+
+// Calls
+// ====================================================================================================================
+export interface GrantCall {
+    id: string;
+    grantGiver: string;
+    createdBy: string;
+    createdAt: number;
+    specification: GrantCallSpecification;
+    status: {
+        isOpen: boolean;
+    };
+}
+
+export interface GrantCallSpecification {
+    title: string;
+    description: string;
+    opensAt: number;
+    closesAt: number;
+    templates?: Templates | null;
+    budgetCaps: GrantCallBudgetCap[];
+    allocationPeriod?: Period | null;
+}
+
+export interface GrantCallBudgetCap {
+    category: Accounting.ProductCategoryId;
+    cap: number;
+}
+
+export interface GrantCallSummary {
+    call: GrantCall;
+    applications: number;
+    categories: {
+        category: Accounting.ProductCategoryId;
+        requested: number;
+        approved: number;
+        available: number;
+        hasCap: boolean;
+    }[];
+}
+
+export function browseCalls(
+    request: PaginationRequestV2 & {
+        grantGiver?: string;
+        includeClosed?: boolean;
+    }
+): APICallParameters<unknown, PageV2<GrantCall>> {
+    return apiBrowse(request, baseContext, "calls");
+}
+
+export function retrieveCall(request: FindByStringId): APICallParameters<unknown, GrantCall> {
+    return apiRetrieve(request, baseContext, "call");
+}
+
+export function createCall(request: GrantCallSpecification): APICallParameters<unknown, FindByStringId> {
+    return apiUpdate(request, baseContext, "createCall");
+}
+
+export function updateCall(
+    request: {
+        id: string;
+        specification: GrantCallSpecification;
+    }
+): APICallParameters<unknown, {}> {
+    return apiUpdate(request, baseContext, "updateCall");
+}
+
+export function deleteCall(request: FindByStringId): APICallParameters<unknown, {}> {
+    return apiUpdate(request, baseContext, "deleteCall");
+}
+
+export function retrieveCallSummary(request: FindByStringId): APICallParameters<unknown, GrantCallSummary> {
+    return apiRetrieve(request, baseContext, "callSummary");
+}
+
 // Request settings
 // ====================================================================================================================
 export function updateRequestSettings(
@@ -212,6 +309,14 @@ export interface Doc {
         should start and end
      */
     allocationPeriod?: Period | null
+
+    /*
+        The call which the application was submitted to
+
+        Updateable by: Original creator
+        Immutable after creation: Yes
+    */
+    callId?: string | null;
 }
 
 type Form = GrantGiverInitiatedForm | StructuredForm;
@@ -300,6 +405,29 @@ export interface Status {
     hasUnreadComments: boolean;
     applicationHistory: Application[];
     optionalUserInfo?: OptionalInfo | null;
+    reviews: GrantReviewAssignment[];
+}
+
+export const GRANT_REVIEW_OVERALL_FIELD = "overall";
+
+export interface GrantReviewAssignment {
+    grantGiver: string;
+    reviewer: string;
+    assignedBy: string;
+    assignedAt: number;
+    review?: GrantReview | null;
+}
+
+export interface GrantReview {
+    submittedAt: number;
+    revisionNumber: number;
+    scores: GrantReviewScore[];
+    comment: string;
+}
+
+export interface GrantReviewScore {
+    field: string;
+    score: number;
 }
 
 export interface GrantGiverApprovalState {
@@ -368,6 +496,7 @@ export interface RequestSettings {
     allowRequestsFrom: UserCriteria[];
     excludeRequestsFrom: UserCriteria[];
     templates: Templates;
+    reviewQuorum: number;
 }
 
 export enum ApplicationFilter {
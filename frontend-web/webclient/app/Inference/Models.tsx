@@ -17,7 +17,7 @@ import HeroImage from "@/Assets/Images/inference/ucloud-ai-logo.png";
 import {RichSelect} from "@/ui-components/RichSelect";
 import {useIsLightThemeStored} from "@/ui-components/theme";
 
-const capabilities: InferenceCapability[] = ["TextGeneration", "TextToImage", "SpeechToText"];
+const capabilities: InferenceCapability[] = ["TextGeneration", "TextToImage", "SpeechToText", "Embedding"];
 
 const pageStyle = injectStyle("inference-models-page", k => `
     ${k} {
@@ -766,7 +766,7 @@ function formatMultiplier(value: number): string {
 }
 
 function primaryCapability(model: InferenceModel): InferenceCapability | string {
-    const priority: InferenceCapability[] = ["SpeechToText", "TextGeneration", "TextToImage"];
+    const priority: InferenceCapability[] = ["SpeechToText", "TextGeneration", "TextToImage", "Embedding"];
     return priority.find(capability => model.capabilities.includes(capability)) ?? model.capabilities[0] ?? "Unknown";
 }
 
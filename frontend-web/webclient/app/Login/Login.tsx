@@ -23,6 +23,7 @@ import AppRoutes from "@/Routes";
 import {addOrgInfoModalIfNotFilled} from "@/UserSettings/ChangeUserDetails";
 import {sendFailureNotification, sendSuccessNotification} from "@/Notifications";
 import {BrandingLoginPageType, BrandingResponse} from "@/UCloud/BrandingApi";
+import {isWebAuthnSupported, webAuthnGet} from "@/Authentication/WebAuthn";
 
 const IS_SANDBOX = onSandbox();
 
@@ -274,6 +275,50 @@ export const LoginPage: React.FC<LoginPageProps> = props => {
         }
     }
 
+    async function submitWebAuthn(): Promise<void> {
+        try {
+            setLoading(true);
+            const optionsResponse = await fetch(`/auth/2fa/webAuthnChallenge`, {
+                method: "POST",
+                headers: {
+                    "Accept": "application/json",
+                    "Content-Type": "application/json"
+                },
+                body: JSON.stringify({challengeId})
+            });
+            if (!optionsResponse.ok) throw optionsResponse;
+            const {options} = await optionsResponse.json();
+
+            const webAuthnResponse = await webAuthnGet(options);
+            const response = await fetch(`/auth/2fa/challenge`, {
+                method: "POST",
+                headers: {
+                    "Accept": "application/json",
+                    "Content-Type": "application/json"
+                },
+                body: JSON.stringify({
+                    challengeId,
+                    webAuthnResponse
+                })
+            });
+            if (!response.ok) throw response;
+            const result = await response.json();
+            handleCompleteLogin(result);
+        } catch (e: any) {
+            setLoading(false);
+            if (e instanceof Response) {
+                sendFailureNotification(
+                    errorMessageOrDefault({
+                        request: e,
+                        response: await e.json()
+                    }, "Could not verify your security key. Try again later")
+                );
+            } else {
+                sendFailureNotification("Could not verify your security key. Try again.");
+            }
+        }
+    }
+
     async function submitResetPassword(e: {preventDefault(): void}): Promise<void> {
         e.preventDefault();
         setLoading(true);
@@ -420,6 +465,13 @@ export const LoginPage: React.FC<LoginPageProps> = props => {
                             >
                                 Submit
                             </LoginButton>
+                            {isWebAuthnSupported() ?
+                                <Box mt={20} textAlign="center">
+                                    <BlackLoginText textColor={textColor} fontSize={1} cursor="pointer" onClick={submitWebAuthn}>
+                                        Use a security key instead
+                                    </BlackLoginText>
+                                </Box> : null
+                            }
                         </form>
                     </DropdownLike>
                 )}
@@ -667,6 +719,7 @@ const IdpList: React.FunctionComponent<IsGenericProps & {service: string}> = ({i
 
             return <a href={buildQueryString("/auth/startLogin", {id: idp.id, service})} key={idp.id}>
                 <Button borderRadius="16px" fullWidth color={color}>
+                    {idp.logoUrl ? <Image alt={title} height="24px" mr="8px" src={idp.logoUrl} /> : null}
                     <Text color="fixedWhite">Sign in with {title}</Text>
                 </Button>
             </a>
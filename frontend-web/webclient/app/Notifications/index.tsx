@@ -79,7 +79,8 @@ function resolveNotification(event: Notification): {
             return {icon, modifiedTitle: event.meta["title"] as string | undefined};
         }
 
-        case "GRANT_APPLICATION_UPDATED": {
+        case "GRANT_APPLICATION_UPDATED":
+        case "GRANT_REVIEW_ASSIGNED": {
             const icon = "heroPaperAirplane";
             const avatar = event.meta["avatar"] as string | undefined;
             return {icon, modifiedTitle: event.meta["title"] as string | undefined, avatar};
@@ -233,7 +234,8 @@ function onNotificationAction(notification: Notification, navigate: NavigateFunc
         case "COMMENT_GRANT_APPLICATION":
         case "GRANT_APPLICATION_RESPONSE":
         case "UPDATED_GRANT_APPLICATION":
-        case "GRANT_APPLICATION_UPDATED": {
+        case "GRANT_APPLICATION_UPDATED":
+        case "GRANT_REVIEW_ASSIGNED": {
             const {meta} = notification;
             navigate(`/grants?id=${meta.appId}`);
             break;
@@ -92,6 +92,10 @@ class ProjectApi {
         return apiUpdate(request, this.baseContext, "renameProject");
     }
 
+    public updateCostCentre(request: BulkRequest<{projectId: string, costCentre?: string | null}>): APICallParameters {
+        return apiUpdate(request, this.baseContext, "updateCostCentre");
+    }
+
     public updateSettings(request: ProjectSettings): APICallParameters {
         return apiUpdate(request, this.baseContext, "updateSettings");
     }
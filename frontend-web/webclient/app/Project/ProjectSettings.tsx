@@ -399,6 +399,7 @@ export const ProjectSettings: React.FunctionComponent = () => {
         description: "No description",
         allowRequestsFrom: [],
         excludeRequestsFrom: [],
+        reviewQuorum: 0,
         templates: {
             type: "structured",
             structured: {
@@ -47,6 +47,7 @@ export interface ProjectSpecification {
 
 export interface ProjectSettings {
     subprojects?: ProjectSettingsSubProjects | null;
+    costCentre?: string | null;
 }
 
 export interface ProjectSettingsSubProjects {
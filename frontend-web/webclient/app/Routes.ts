@@ -143,6 +143,7 @@ const files = {
     drive: (driveId: string) => buildQueryString("/files", {path: "/" + driveId}),
     path: (path: string) => buildQueryString("/files", {path}),
     visualize: (path?: string) => buildQueryString("/files/visualize", {path}),
+    retention: (path?: string) => buildQueryString("/files/retention", {path}),
     preview: (path: string) => "/files/properties/" + encodeURIComponent(path)
 }
 
@@ -71,6 +71,8 @@ export interface FileCollectionSupport extends ProductSupport {
         isReadOnly?: boolean;
 
         openInTerminal?: boolean | null;
+        archivesSupported?: boolean;
+        retentionPolicy?: boolean;
     }
 }
 
@@ -70,13 +70,17 @@ import {filetypeinfo as fileType} from "magic-bytes.js";
 import {PREVIEW_MAX_SIZE} from "../../site.config.json";
 import {CSSVarCurrentSidebarStickyWidth} from "@/ui-components/List";
 import {
+    FilesCompressRequestItem,
     FilesCopyRequestItem,
     FilesCreateDownloadRequestItem,
     FilesCreateDownloadResponseItem,
     FilesCreateFolderRequestItem,
     FilesCreateUploadRequestItem,
     FilesEmptyTrashRequestItem,
+    FilesExtractRequestItem,
     FilesMoveRequestItem,
+    FilesRetentionRequest,
+    FilesRetentionResponse,
     FilesTransferRequestItem,
     FilesTrashRequestItem,
     FilesVisualizeRequest,
@@ -364,6 +368,10 @@ class FilesApi extends ResourceApi<UFile, ProductStorage, UFileSpecification,
         return apiUpdate(request, "/api/files", "visualize");
     }
 
+    retention(request: FilesRetentionRequest): APICallParameters<FilesRetentionRequest, FilesRetentionResponse> {
+        return apiUpdate(request, "/api/files", "retention");
+    }
+
     renderer: ItemRenderer<UFile, FileBrowseCallbacks> = {
     };
 
@@ -1117,6 +1125,14 @@ class FilesApi extends ResourceApi<UFile, ProductStorage, UFileSpecification,
         return apiUpdate(request, this.baseContext, "move");
     }
 
+    public compress(request: BulkRequest<FilesCompressRequestItem>): APICallParameters<BulkRequest<FilesCompressRequestItem>> {
+        return apiUpdate(request, this.baseContext, "compress");
+    }
+
+    public extract(request: BulkRequest<FilesExtractRequestItem>): APICallParameters<BulkRequest<FilesExtractRequestItem>> {
+        return apiUpdate(request, this.baseContext, "extract");
+    }
+
     public createUpload(
         request: BulkRequest<FilesCreateUploadRequestItem>
     ): APICallParameters<BulkRequest<FilesCreateUploadRequestItem>> {
@@ -48,12 +48,39 @@ export interface JobSpecification extends ResourceSpecification {
     timeAllocation?: SimpleDuration;
     openedFile?: string;
     sshEnabled?: boolean;
+    sweep?: JobSweep;
+    dependencies?: JobDependency[];
 }
 
-// IN_QUEUE: accepted but not executing. RUNNING: execution started. SUCCESS: exit code 0.
+// AFTER_OK waits for the upstream job to succeed, AFTER_ANY waits for it to end in any state.
+export type JobDependencyType = "AFTER_OK" | "AFTER_ANY";
+
+export interface JobDependency {
+    jobId: string;
+    type: JobDependencyType;
+}
+
+// A sweep turns a job into a job array. Every entry of tasks is combined with every element of the cartesian product
+// of matrix. Task parameters override the parameters of the specification.
+export interface JobSweep {
+    tasks?: Record<string, AppParameterValue>[];
+    matrix?: Record<string, AppParameterValue[]>;
+    maxConcurrency?: number;
+}
+
+export interface JobArrayStatus {
+    total: number;
+    inQueue: number;
+    running: number;
+    success: number;
+    failure: number;
+}
+
+// IN_QUEUE: accepted but not executing. WAITING: held by UCloud until its dependencies are satisfied.
+// RUNNING: execution started. SUCCESS: exit code 0.
 // FAILURE: non-zero exit, signal, OOM, workload, or infrastructure failure. EXPIRED:
 // allocation elapsed. SUSPENDED: intentionally paused and may resume. CANCELING is transient.
-export type JobState = "IN_QUEUE" | "RUNNING" | "CANCELING" | "SUCCESS" | "FAILURE" | "EXPIRED" | "SUSPENDED";
+export type JobState = "IN_QUEUE" | "WAITING" | "RUNNING" | "CANCELING" | "SUCCESS" | "FAILURE" | "EXPIRED" | "SUSPENDED";
 export function isJobStateFinal(state: JobState): boolean {
     switch (state) {
         case "SUCCESS":
@@ -93,6 +120,7 @@ export interface JobStatus extends ResourceStatus {
     expiresAt?: number;
     resolvedApplication?: Application;
     jobParametersJson: any;
+    arrayTasks?: JobArrayStatus;
 }
 
 export enum JobQueueStatus {
@@ -119,8 +147,10 @@ export interface NativeSupport {
     logs?: boolean;
     terminal?: boolean;
     timeExtension?: boolean;
+    suspension?: boolean;
     utilization?: boolean;
     bindLinkToPort?: boolean;
+    arrays?: boolean;
 }
 
 export interface DockerSupport {
@@ -133,6 +163,7 @@ export interface DockerSupport {
     timeExtension?: boolean;
     utilization?: boolean;
     bindLinkToPort?: boolean;
+    arrays?: boolean;
 }
 
 export interface VirtualMachineSupport {
@@ -144,6 +175,7 @@ export interface VirtualMachineSupport {
     suspension?: boolean;
     utilization?: boolean;
     bindLinkToPort?: boolean;
+    snapshots?: boolean;
 }
 
 export interface CpuAndMemory {
@@ -169,6 +201,28 @@ export interface ExtendRequest {
 
 export type ResumeRequest = FindByStringId;
 export type SuspendRequest = FindByStringId;
+
+export type JobSnapshotState = "PENDING" | "READY" | "FAILED";
+
+export interface JobSnapshot {
+    id: string;
+    jobId: string;
+    title: string;
+    createdAt: number;
+    state: JobSnapshotState;
+    sizeInGb: number;
+    error?: string | null;
+}
+
+export interface CreateSnapshotRequest {
+    jobId: string;
+    title: string;
+}
+
+export interface SnapshotRequest {
+    jobId: string;
+    snapshotId: string;
+}
 export interface OpenInteractiveSessionRequest {
     id: string;
     rank: number;
@@ -423,6 +477,22 @@ class JobApi extends ResourceApi<Job, ProductCompute, JobSpecification, JobUpdat
     detachResource(request: DetachResourceRequest): APICallParameters<DetachResourceRequest, any | null> {
         return apiUpdate(request, this.baseContext, "detachResource");
     }
+
+    createSnapshot(request: BulkRequest<CreateSnapshotRequest>): APICallParameters<BulkRequest<CreateSnapshotRequest>, BulkResponse<FindByStringId>> {
+        return apiUpdate(request, this.baseContext, "createSnapshot");
+    }
+
+    browseSnapshots(request: {jobId: string}): APICallParameters<{jobId: string}, {snapshots: JobSnapshot[]}> {
+        return apiRetrieve(request, this.baseContext, "snapshots");
+    }
+
+    restoreSnapshot(request: BulkRequest<SnapshotRequest>): APICallParameters<BulkRequest<SnapshotRequest>, BulkResponse<any | null>> {
+        return apiUpdate(request, this.baseContext, "restoreSnapshot");
+    }
+
+    deleteSnapshot(request: BulkRequest<SnapshotRequest>): APICallParameters<BulkRequest<SnapshotRequest>, BulkResponse<any | null>> {
+        return apiUpdate(request, this.baseContext, "deleteSnapshot");
+    }
 }
 
 export function isSyncthingApp(app?: Job) {
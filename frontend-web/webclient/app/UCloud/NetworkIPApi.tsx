@@ -35,7 +35,7 @@ export interface NetworkIPStatus extends ResourceStatus {
 }
 
 export interface NetworkIPSupport extends ProductSupport {
-    firewall?: {enabled?: boolean | null} | null;
+    firewall?: {enabled?: boolean | null; sourceRestrictions?: boolean | null} | null;
 }
 
 export interface NetworkIPUpdate extends ResourceUpdate {
@@ -12,9 +12,39 @@ import {Icon} from "@/ui-components";
 import {EnumFilter} from "@/Resource/Filter";
 import {ItemRenderer} from "@/ui-components/Browse";
 import {ProductIngress as ProductPublicLink, productTypeToIcon} from "@/Accounting";
+import {BulkRequest, FindByStringId} from "@/UCloud/index";
+import {apiUpdate} from "@/Authentication/DataHook";
+import {ListRowStat} from "@/ui-components/List";
+import {dateToString} from "@/Utilities/DateUtilities";
+import {ResourceProperties} from "@/Resource/Properties";
+import {AccessEditor} from "@/Applications/PublicLinks/AccessEditor";
 
 export interface PublicLinkSpecification extends ResourceSpecification {
     domain: string;
+    access?: PublicLinkAccessPolicy;
+}
+
+export type PublicLinkAccessMode = "PUBLIC" | "PROJECT" | "USERS" | "PASSWORD";
+
+export interface PublicLinkAccessPolicy {
+    mode: PublicLinkAccessMode;
+    users?: string[];
+    // Only used when updating the policy, it is never returned by UCloud
+    password?: string;
+}
+
+export interface PublicLinkAccessAndId {
+    id: string;
+    access: PublicLinkAccessPolicy;
+}
+
+export interface PublicLinkAuthorizeAccessRequest {
+    id: string;
+    redirect: string;
+}
+
+export interface PublicLinkAuthorizeAccessResponse {
+    redirectTo: string;
 }
 
 export type PublicLinkState = "READY" | "PREPARING" | "UNAVAILABLE";
@@ -22,11 +52,22 @@ export type PublicLinkState = "READY" | "PREPARING" | "UNAVAILABLE";
 export interface PublicLinkStatus extends ResourceStatus {
     boundTo: string[];
     state: PublicLinkState;
+    customDomain?: PublicLinkCustomDomainStatus;
+}
+
+export interface PublicLinkCustomDomainStatus {
+    challengeRecord: string;
+    challengeToken: string;
+    verified: boolean;
+    certificateExpiresAt?: number;
+    error?: string;
 }
 
 export interface PublicLinkSupport extends ProductSupport {
     domainPrefix: string;
     domainSuffix: string;
+    customDomains?: boolean;
+    accessControl?: boolean;
 }
 
 export interface PublicLinkUpdate extends ResourceUpdate {
@@ -49,7 +90,48 @@ class PublicLinkApi extends ResourceApi<PublicLink, ProductPublicLink, PublicLin
 
     renderer: ItemRenderer<PublicLink> = {
         Icon({resource, size}) {return <Icon name={productTypeToIcon("INGRESS")} size={size} />},
-        MainTitle({resource}) {return <>{resource?.specification?.domain ?? ""}</>}
+        MainTitle({resource}) {return <>{resource?.specification?.domain ?? ""}</>},
+        Stats({resource}) {
+            const access = resource?.specification?.access;
+            const accessStat = access && access.mode !== "PUBLIC" ?
+                <ListRowStat icon={"heroLockClosed"}>{accessModeTitle(access.mode)}</ListRowStat> : null;
+
+            const customDomain = resource?.status?.customDomain;
+            if (!customDomain) return accessStat;
+
+            if (!customDomain.verified) {
+                return <>
+                    <ListRowStat icon={"globeEuropeSolid"}>
+                        Add a TXT record named <code>{customDomain.challengeRecord}</code> with the
+                        value <code>{customDomain.challengeToken}</code>
+                    </ListRowStat>
+                    {customDomain.error ? <ListRowStat icon={"warning"}>{customDomain.error}</ListRowStat> : null}
+                </>;
+            }
+
+            return <>
+                {accessStat}
+                {customDomain.certificateExpiresAt ?
+                    <ListRowStat icon={"key"}>Certificate valid until {dateToString(customDomain.certificateExpiresAt)}</ListRowStat> :
+                    <ListRowStat icon={"key"}>Waiting for certificate</ListRowStat>
+                }
+                {customDomain.error ? <ListRowStat icon={"warning"}>{customDomain.error}</ListRowStat> : null}
+            </>;
+        }
+    };
+
+    Properties = (props) => {
+        return <ResourceProperties
+            api={this}
+            {...props}
+            ContentChildren={(p) => {
+                const support = (p.resource as PublicLink).status.resolvedSupport
+                    ?.support as PublicLinkSupport | undefined;
+
+                if (support?.accessControl !== true) return null;
+                return <AccessEditor inspecting={p.resource as PublicLink} reload={p.reload} />;
+            }}
+        />;
     };
 
     constructor() {
@@ -84,6 +166,27 @@ class PublicLinkApi extends ResourceApi<PublicLink, ProductPublicLink, PublicLin
             column: "domain"
         });
     }
+
+    verifyDomain(request: BulkRequest<FindByStringId>): APICallParameters<BulkRequest<FindByStringId>> {
+        return apiUpdate(request, this.baseContext, "verifyDomain");
+    }
+
+    updateAccess(request: BulkRequest<PublicLinkAccessAndId>): APICallParameters<BulkRequest<PublicLinkAccessAndId>> {
+        return apiUpdate(request, this.baseContext, "updateAccess");
+    }
+
+    authorizeAccess(request: PublicLinkAuthorizeAccessRequest): APICallParameters<PublicLinkAuthorizeAccessRequest, PublicLinkAuthorizeAccessResponse> {
+        return apiUpdate(request, this.baseContext, "authorizeAccess");
+    }
+}
+
+export function accessModeTitle(mode: PublicLinkAccessMode): string {
+    switch (mode) {
+        case "PUBLIC": return "Anyone with the link";
+        case "PROJECT": return "Project members";
+        case "USERS": return "Specific users";
+        case "PASSWORD": return "Anyone with the password";
+    }
 }
 
 export default new PublicLinkApi();
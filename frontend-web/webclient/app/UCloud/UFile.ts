@@ -47,6 +47,21 @@ export interface FilesTransferRequestItem {
     destinationPath: string;
 }
 
+export type ArchiveFormat = "ZIP" | "TAR" | "TAR_GZ" | "TAR_ZST";
+
+export interface FilesCompressRequestItem {
+    sourcePath: string;
+    destinationPath: string;
+    format: ArchiveFormat;
+    conflictPolicy: WriteConflictPolicy;
+}
+
+export interface FilesExtractRequestItem {
+    sourcePath: string;
+    destinationPath: string;
+    conflictPolicy: WriteConflictPolicy;
+}
+
 export interface FilesCreateFolderRequestItem {
     id: string;
     conflictPolicy: WriteConflictPolicy;
@@ -98,3 +113,24 @@ export interface FilesVisualizeResponse {
     directoryCount: number;
     complete: boolean;
 }
+
+export interface FilesRetentionRequest {
+    path: string;
+}
+
+export interface FilesRetentionEntry {
+    path: string;
+    sizeInBytes: number;
+    lastAccessedAt: number;
+    expiresAt: number;
+}
+
+export interface FilesRetentionResponse {
+    maxAgeDays: number;
+    warningDays: number;
+    entries: FilesRetentionEntry[];
+    lastUpdatedAt: number | null;
+    fileCount: number;
+    sizeInBytes: number;
+    complete: boolean;
+}
@@ -2253,6 +2253,8 @@ export interface PortRangeAndProto {
     start: number /* int32 */,
     end: number /* int32 */,
     protocol: ("TCP" | "UDP"),
+    allowedCidrs?: string[],
+    direction?: ("INGRESS" | "EGRESS"),
 }
 /**
  * The status of an `NetworkIP`
@@ -3847,6 +3849,7 @@ export interface EmailSettings {
     userRoleChange: boolean,
     userLeft: boolean,
     lowFunds: boolean,
+    budgetAlerts: boolean,
     jobStarted: boolean,
     jobStopped: boolean,
 }
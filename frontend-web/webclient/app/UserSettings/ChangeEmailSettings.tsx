@@ -32,6 +32,7 @@ export const defaultEmailSettings: EmailSettings = {
     userRoleChange: true,
     userLeft: true,
     lowFunds: true,
+    budgetAlerts: true,
     // Jobs
     jobStarted: false,
     jobStopped: false,
@@ -102,6 +103,11 @@ const emailSettingGroups: SettingGroup[] = [
                 title: "Low on funds",
                 description: "Sends an email when project balances are running low"
             },
+            {
+                key: "budgetAlerts",
+                title: "Budget alerts",
+                description: "Sends an email when one of your budget alerts is triggered"
+            },
             {
                 key: "projectUserInvite",
                 title: "User invited to project",
@@ -0,0 +1,104 @@
+import {useCloudCommand} from "@/Authentication/DataHook";
+import * as React from "react";
+import {useCallback, useEffect, useState} from "react";
+import {Box} from "@/ui-components";
+import * as Heading from "@/ui-components/Heading";
+
+import {
+    NotificationCategory,
+    NotificationChannelPreferences,
+    NotificationPreferences,
+    retrieveNotificationPreferences,
+    updateNotificationPreferences
+} from "./settingsApi";
+import {SettingsCheckboxRow, SettingsSection} from "@/ui-components/SettingsComponents";
+import {sendFailureNotification} from "@/Notifications";
+
+const categories: {key: NotificationCategory; title: string}[] = [
+    {key: "JOBS", title: "Jobs"},
+    {key: "GRANTS", title: "Grant applications"},
+    {key: "PROJECTS", title: "Projects"},
+    {key: "ACCOUNTING", title: "Accounting"},
+    {key: "SHARES", title: "Shares"},
+    {key: "SUPPORT", title: "Support"},
+];
+
+const channels: {key: keyof NotificationChannelPreferences; title: string; description: string}[] = [
+    {key: "inApp", title: "In-app", description: "Show notifications in UCloud"},
+    {key: "email", title: "Email", description: "Send an email when the event happens"},
+    {key: "digest", title: "Daily digest", description: "Include the notifications in a daily summary email"},
+];
+
+function defaultChannelPreferences(): NotificationChannelPreferences {
+    return {inApp: true, email: true, digest: false};
+}
+
+interface ChangeNotificationPreferencesProps {
+    setLoading: (loading: boolean) => void;
+}
+
+export const ChangeNotificationPreferences: React.FunctionComponent<ChangeNotificationPreferencesProps> = ({setLoading}) => {
+    const [commandLoading, invokeCommand] = useCloudCommand();
+    const [preferences, setPreferences] = useState<NotificationPreferences>({categories: {}});
+
+    useEffect(() => {
+        void (async () => {
+            const result = await invokeCommand(retrieveNotificationPreferences(), {defaultErrorHandler: false});
+            if (result) setPreferences(result);
+        })();
+    }, []);
+
+    useEffect(() => {
+        setLoading(commandLoading);
+    }, [commandLoading, setLoading]);
+
+    const toggle = useCallback((category: NotificationCategory, channel: keyof NotificationChannelPreferences) => {
+        if (commandLoading) return;
+
+        const previous = preferences;
+        const current = preferences.categories[category] ?? defaultChannelPreferences();
+        const next: NotificationPreferences = {
+            categories: {
+                ...preferences.categories,
+                [category]: {...current, [channel]: !current[channel]},
+            }
+        };
+
+        setPreferences(next);
+
+        void (async () => {
+            const wasSuccessful = await invokeCommand(updateNotificationPreferences(next)) !== null;
+            if (!wasSuccessful) {
+                setPreferences(previous);
+                sendFailureNotification("Failed to update notification preferences");
+            }
+        })();
+    }, [commandLoading, preferences, invokeCommand]);
+
+    return (
+        <SettingsSection
+            id="notification-preferences"
+            title="Notification channels"
+            description="Choose how you want to be notified for each type of event."
+        >
+            {categories.map(category => {
+                const current = preferences.categories[category.key] ?? defaultChannelPreferences();
+                return <Box key={category.key} mb={24}>
+                    <Heading.h5>{category.title}</Heading.h5>
+                    <Box mt={6}>
+                        {channels.map(channel => (
+                            <SettingsCheckboxRow
+                                key={channel.key}
+                                title={channel.title}
+                                description={channel.description}
+                                onClick={() => toggle(category.key, channel.key)}
+                                checked={current[channel.key]}
+                                disabled={commandLoading}
+                            />
+                        ))}
+                    </Box>
+                </Box>;
+            })}
+        </SettingsSection>
+    );
+};
@@ -0,0 +1,111 @@
+import {callAPI, useCloudAPI, useCloudCommand} from "@/Authentication/DataHook";
+import {Client} from "@/Authentication/HttpClientInstance";
+import {isWebAuthnSupported, webAuthnCreate} from "@/Authentication/WebAuthn";
+import * as React from "react";
+import {useCallback, useEffect, useRef} from "react";
+import {Box, Button, Flex, Input, Label} from "@/ui-components";
+import {SettingsAction, SettingsSection} from "@/ui-components/SettingsComponents";
+import {dateToString} from "@/Utilities/DateUtilities";
+import {addStandardDialog} from "@/UtilityComponents";
+import {sendFailureNotification, sendSuccessNotification} from "@/Notifications";
+import {errorMessageOrDefault} from "@/UtilityFunctions";
+import {
+    beginWebAuthnRegistration,
+    browseWebAuthnCredentials,
+    deleteWebAuthnCredential,
+    finishWebAuthnRegistration,
+    WebAuthnCredential
+} from "./settingsApi";
+import {BulkResponse} from "@/UCloud";
+
+export const SecurityKeys: React.FunctionComponent<{setLoading: (loading: boolean) => void}> = props => {
+    const [commandLoading, invokeCommand] = useCloudCommand();
+    const [credentials, fetchCredentials] = useCloudAPI<BulkResponse<WebAuthnCredential>>(
+        browseWebAuthnCredentials(),
+        {responses: []}
+    );
+    const nameInput = useRef<HTMLInputElement>(null);
+    const [registering, setRegistering] = React.useState(false);
+
+    useEffect(() => {
+        props.setLoading(credentials.loading || commandLoading || registering);
+    }, [props.setLoading, credentials.loading, commandLoading, registering]);
+
+    const reload = useCallback(() => {
+        fetchCredentials(browseWebAuthnCredentials());
+    }, []);
+
+    const onRegister = useCallback(async (e: React.SyntheticEvent) => {
+        e.preventDefault();
+        const name = nameInput.current?.value?.trim() ?? "";
+        if (!name) {
+            sendFailureNotification("Give the security key a name before adding it.");
+            return;
+        }
+
+        try {
+            setRegistering(true);
+            const {sessionId, options} = await callAPI(beginWebAuthnRegistration({name}));
+            const credential = await webAuthnCreate(options);
+            await callAPI(finishWebAuthnRegistration({sessionId, credential}));
+            await Client.invalidateAccessToken();
+            if (nameInput.current) nameInput.current.value = "";
+            sendSuccessNotification("The security key has been added to your account.");
+            reload();
+        } catch (err) {
+            sendFailureNotification(errorMessageOrDefault(err, "Could not add the security key. Try again."));
+        } finally {
+            setRegistering(false);
+        }
+    }, [reload]);
+
+    const onDelete = useCallback((credential: WebAuthnCredential) => {
+        addStandardDialog({
+            title: "Remove security key",
+            message: `Are you sure you want to remove '${credential.name}'? It can no longer be used to sign in.`,
+            confirmText: "Remove",
+            onConfirm: async () => {
+                await invokeCommand(deleteWebAuthnCredential({id: credential.id}));
+                await Client.invalidateAccessToken();
+                reload();
+            },
+            onCancel: () => {
+                // Empty
+            }
+        });
+    }, [reload]);
+
+    if (!isWebAuthnSupported()) return null;
+
+    return (
+        <SettingsSection id="security-keys" title="Security keys and passkeys">
+            {credentials.data.responses.length === 0 ?
+                <p>No security keys have been added to your account.</p> : null
+            }
+
+            {credentials.data.responses.map(credential =>
+                <SettingsAction
+                    key={credential.id}
+                    title={credential.name}
+                    description={`Added ${dateToString(credential.createdAt)}. ` + (credential.lastUsedAt ?
+                        `Last used ${dateToString(credential.lastUsedAt)}.` : "Never used.")}
+                    action={<Button color="errorMain" onClick={() => onDelete(credential)} disabled={commandLoading}>
+                        Remove
+                    </Button>}
+                />
+            )}
+
+            <form onSubmit={onRegister}>
+                <Box mt="0.5em" pt="0.5em">
+                    <Label>
+                        Name of the new security key
+                        <Flex gap="8px">
+                            <Input inputRef={nameInput} placeholder="My security key" maxLength={64} />
+                            <Button type="submit" disabled={registering}>Add</Button>
+                        </Flex>
+                    </Label>
+                </Box>
+            </form>
+        </SettingsSection>
+    );
+};
@@ -4,12 +4,14 @@ import * as React from "react";
 import {useDispatch, useSelector} from "react-redux";
 import {ChangePassword} from "@/UserSettings/ChangePassword";
 import {Sessions} from "@/UserSettings/Sessions";
+import {SecurityKeys} from "@/UserSettings/SecurityKeys";
 import {TwoFactorSetup} from "./TwoFactorSetup";
 import {ChangeOrganizationDetails, ChangeUserDetails} from "@/UserSettings/ChangeUserDetails";
 import {ChangeEmailSettings} from "@/UserSettings/ChangeEmailSettings";
 import {CustomTheming} from "./CustomTheme";
 import {refreshFunctionCache} from "@/Utilities/ReduxUtilities";
 import {ChangeNotificationSettings} from "./ChangeNotificationSettings";
+import {ChangeNotificationPreferences} from "./ChangeNotificationPreferences";
 import {ChangeJobReportSettings} from "./ChangeJobReportSettings";
 import {SidebarTabId} from "@/ui-components/SidebarComponents";
 import {SettingsNavSection, SettingsPage} from "@/ui-components/SettingsComponents";
@@ -30,16 +32,19 @@ function UserSettings(): React.ReactNode {
         Client.userInfo?.principalType === "password";
 
     const sections: SettingsNavSection[] = mustActivate2fa ? [
-        {id: "two-factor", label: "Two factor authentication"}
+        {id: "two-factor", label: "Two factor authentication"},
+        {id: "security-keys", label: "Security keys"},
     ] : [
         {id: "profile", label: "User information"},
         {id: "organization", label: "Additional user information"},
         {id: "email", label: "Email settings"},
         {id: "notifications", label: "Notification settings"},
+        {id: "notification-preferences", label: "Notification channels"},
         {id: "job-report", label: "Job report settings"},
         {id: "two-factor", label: "Two factor authentication"},
         ...(Client.userInfo?.principalType === "password" ? [{id: "password", label: "Change password"}] : []),
         {id: "sessions", label: "Active sessions"},
+        {id: "security-keys", label: "Security keys"},
     ];
 
     const twoFactorSetup = <TwoFactorSetup
@@ -48,11 +53,15 @@ function UserSettings(): React.ReactNode {
         setLoading={setHeaderLoading}
     />;
     return <SettingsPage title="User settings" sections={sections}>
-        {mustActivate2fa ? twoFactorSetup : <>
+        {mustActivate2fa ? <>
+            {twoFactorSetup}
+            <SecurityKeys setLoading={setHeaderLoading} />
+        </> : <>
             <ChangeUserDetails />
             <ChangeOrganizationDetails />
             <ChangeEmailSettings setLoading={setHeaderLoading} />
             <ChangeNotificationSettings setLoading={setHeaderLoading} />
+            <ChangeNotificationPreferences setLoading={setHeaderLoading} />
             <ChangeJobReportSettings setLoading={setHeaderLoading} />
             {twoFactorSetup}
             <ChangePassword setLoading={setHeaderLoading} />
@@ -60,6 +69,7 @@ function UserSettings(): React.ReactNode {
                 setLoading={setHeaderLoading}
                 setRefresh={fn => refreshFunctionCache.setRefreshFunction(fn ?? (() => undefined))}
             />
+            <SecurityKeys setLoading={setHeaderLoading} />
             <CustomTheming />
         </>}
     </SettingsPage>;
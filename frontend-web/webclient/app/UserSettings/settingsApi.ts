@@ -1,4 +1,5 @@
 import {buildQueryString} from "@/Utilities/URIUtilities";
+import {BulkResponse, FindByStringId} from "@/UCloud";
 
 export interface TwoFactorSetupState {
     challengeId?: string;
@@ -118,3 +119,90 @@ export function invalidateAllSessions(): APICallParameters {
         withCredentials: true
     };
 }
+
+export interface WebAuthnCredential {
+    id: string;
+    name: string;
+    createdAt: number;
+    lastUsedAt?: number | null;
+}
+
+export function browseWebAuthnCredentials(): APICallParameters<unknown, BulkResponse<WebAuthnCredential>> {
+    return {
+        reloadId: Math.random(),
+        method: "GET",
+        path: "/auth/2fa/browseWebAuthn",
+        context: ""
+    };
+}
+
+export function deleteWebAuthnCredential(request: FindByStringId): APICallParameters<FindByStringId> {
+    return {
+        reloadId: Math.random(),
+        method: "DELETE",
+        path: "/auth/2fa/webAuthn",
+        parameters: request,
+        payload: request,
+        context: ""
+    };
+}
+
+export function beginWebAuthnRegistration(
+    request: {name: string}
+): APICallParameters<{name: string}, {sessionId: string; options: any}> {
+    return {
+        reloadId: Math.random(),
+        method: "POST",
+        path: "/auth/2fa/beginWebAuthnRegistration",
+        parameters: request,
+        payload: request,
+        context: ""
+    };
+}
+
+export function finishWebAuthnRegistration(
+    request: {sessionId: string; credential: unknown}
+): APICallParameters<{sessionId: string; credential: unknown}, FindByStringId> {
+    return {
+        reloadId: Math.random(),
+        method: "POST",
+        path: "/auth/2fa/finishWebAuthnRegistration",
+        parameters: request,
+        payload: request,
+        context: ""
+    };
+}
+
+export type NotificationCategory = "JOBS" | "GRANTS" | "PROJECTS" | "ACCOUNTING" | "SHARES" | "SUPPORT";
+
+export interface NotificationChannelPreferences {
+    inApp: boolean;
+    email: boolean;
+    digest: boolean;
+}
+
+export interface NotificationPreferences {
+    categories: Partial<Record<NotificationCategory, NotificationChannelPreferences>>;
+}
+
+export function retrieveNotificationPreferences(): APICallParameters<unknown, NotificationPreferences> {
+    return {
+        context: "",
+        method: "GET",
+        path: "/api/notifications/retrievePreferences",
+        reloadId: Math.random(),
+    };
+}
+
+export function updateNotificationPreferences(
+    request: NotificationPreferences
+): APICallParameters<NotificationPreferences, unknown> {
+    return {
+        context: "",
+        method: "POST",
+        path: "/api/notifications/preferences",
+        parameters: request,
+        reloadId: Math.random(),
+        payload: request,
+    };
+}
@@ -0,0 +1,63 @@
+import {apiBrowse, apiCreate, apiDelete, apiUpdate} from "@/Authentication/DataHook";
+import {BulkRequest, BulkResponse, FindByStringId, PageV2} from "@/UCloud";
+
+const baseContext = "/api/webhooks";
+
+export type WebhookEventType =
+    | "JOB_STATE_CHANGED"
+    | "GRANT_SUBMITTED"
+    | "GRANT_APPROVED"
+    | "GRANT_REJECTED"
+    | "ALLOCATION_LOW_FUNDS"
+    | "SHARE_ACCEPTED"
+    | "NOTIFICATION";
+
+export interface WebhookSpecification {
+    url: string;
+    events: WebhookEventType[];
+    description: string;
+}
+
+export interface Webhook {
+    id: string;
+    createdBy: string;
+    createdAt: number;
+    project?: string | null;
+    specification: WebhookSpecification;
+}
+
+export type WebhookDeliveryState = "PENDING" | "SUCCEEDED" | "FAILED";
+
+export interface WebhookDelivery {
+    id: string;
+    webhookId: string;
+    eventType: WebhookEventType;
+    createdAt: number;
+    state: WebhookDeliveryState;
+    attempts: number;
+    lastAttemptAt?: number | null;
+    nextAttemptAt?: number | null;
+    responseStatus?: number | null;
+    error?: string | null;
+    payload: unknown;
+}
+
+export function create(request: BulkRequest<WebhookSpecification>): APICallParameters<unknown, BulkResponse<{id: string, secret: string}>> {
+    return apiCreate(request, baseContext);
+}
+
+export function browse(): APICallParameters<unknown, PageV2<Webhook>> {
+    return apiBrowse({}, baseContext);
+}
+
+export function remove(request: BulkRequest<FindByStringId>): APICallParameters {
+    return apiDelete(request, baseContext);
+}
+
+export function browseDeliveries(request: {webhookId: string, itemsPerPage?: number, next?: string}): APICallParameters<unknown, PageV2<WebhookDelivery>> {
+    return apiBrowse(request, baseContext, "deliveries");
+}
+
+export function redeliver(request: BulkRequest<FindByStringId>): APICallParameters {
+    return apiUpdate(request, baseContext, "redeliver");
+}
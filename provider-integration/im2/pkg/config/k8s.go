@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 	apm "ucloud.dk/shared/pkg/accounting"
@@ -14,7 +16,14 @@ import (
 )
 
 type ServicesConfigurationKubernetes struct {
-	FileSystem        KubernetesFileSystem
+	// FileSystem is the default file system. It stores home drives, member files, job folders and other data owned by
+	// the integration module. The default file system is also present in FileSystems.
+	FileSystem KubernetesFileSystem
+
+	// FileSystems contains every configured file system, keyed by name. The name of a file system is also the name of
+	// its product category.
+	FileSystems map[string]KubernetesFileSystem
+
 	Compute           KubernetesCompute
 	SensitiveProjects []string
 }
@@ -26,6 +35,21 @@ type KubernetesFileSystem struct {
 	ClaimName        string
 	ScanMethod       KubernetesFileSystemScanMethod
 	MetadataCatalog  KubernetesMetadataCatalog
+	Retention        KubernetesRetentionPolicy
+	Products         []KubernetesStorageProduct
+}
+
+// KubernetesRetentionPolicy describes when files which have not been accessed are automatically deleted from a file
+// system. Owners are warned WarningDays before their files are deleted.
+type KubernetesRetentionPolicy struct {
+	Enabled     bool
+	MaxAgeDays  int
+	WarningDays int
+}
+
+type KubernetesStorageProduct struct {
+	Name        string
+	Description string
 }
 
 type KubernetesMetadataCatalog struct {
@@ -62,17 +86,27 @@ type KubernetesWebConfiguration struct {
 }
 
 type KubernetesIpConfiguration struct {
-	Enabled bool
-	Name    string
+	Enabled            bool
+	Name               string
+	SourceRestrictions bool
 }
 
 type KubernetesPublicLinkConfiguration struct {
-	Enabled bool
-	Name    string
-	Prefix  string
-	Suffix  string
+	Enabled       bool
+	Name          string
+	Prefix        string
+	Suffix        string
+	CustomDomains KubernetesCustomDomainConfiguration
 }
 
+type KubernetesCustomDomainConfiguration struct {
+	Enabled       bool
+	AcmeDirectory string
+	Email         string
+}
+
+const defaultAcmeDirectory = "https://acme-v02.api.letsencrypt.org/directory"
+
 type KubernetesVirtualMachines struct {
 	Enabled           bool
 	PodLevelResources bool
@@ -81,6 +115,10 @@ type KubernetesVirtualMachines struct {
 		HostPath string
 		Csi      KubernetesVmVolCsiConfig
 	}
+	Snapshots struct {
+		Enabled   bool
+		MaxPerJob int
+	}
 }
 
 type KubernetesVmVolCsiConfig struct {
@@ -177,8 +215,16 @@ type KubernetesUcxConfiguration struct {
 	Publish     map[string][]string           `json:"publish" yaml:"publish"`
 }
 
+type KubernetesSchedulerConfiguration struct {
+	WeightAge         float64
+	WeightFairShare   float64
+	WeightJobSize     float64
+	FairShareHalfLife time.Duration
+}
+
 type KubernetesCompute struct {
 	Machines                        map[string]K8sMachineCategory
+	Scheduler                       KubernetesSchedulerConfiguration
 	MachineImpersonation            map[string]string
 	EstimatedContainerDownloadSpeed float64 // MB/s
 	Namespace                       string
@@ -305,60 +351,83 @@ func parseKubernetesServices(unmanaged bool, mode ServerMode, filePath string, s
 		cfgutil.Decode(filePath, sensitiveProjects, &cfg.SensitiveProjects, &success)
 	}
 
-	fsNode := cfgutil.RequireChild(filePath, services, "fileSystem", &success)
-	{
-		cfg.FileSystem.Name = cfgutil.RequireChildText(filePath, fsNode, "name", &success)
-		cfg.FileSystem.MountPoint = cfgutil.RequireChildFolder(filePath, fsNode, "mountPoint", cfgutil.FileCheckReadWrite, &success)
-		cfg.FileSystem.TrashStagingArea = cfgutil.RequireChildFolder(filePath, fsNode, "trashStagingArea", cfgutil.FileCheckReadWrite, &success)
-		cfg.FileSystem.ClaimName = cfgutil.RequireChildText(filePath, fsNode, "claimName", &success)
+	cfg.FileSystems = map[string]KubernetesFileSystem{}
+	if cfgutil.HasChild(services, "fileSystems") {
+		if cfgutil.HasChild(services, "fileSystem") {
+			cfgutil.ReportError(filePath, services, "fileSystem and fileSystems cannot both be specified")
+			success = false
+		}
+
+		fsNode := cfgutil.RequireChild(filePath, services, "fileSystems", &success)
+		if fsNode.Kind != yaml.MappingNode || len(fsNode.Content) == 0 {
+			cfgutil.ReportError(filePath, fsNode, "expected fileSystems to be a non-empty dictionary")
+			return false, cfg
+		}
 
-		scanMethodNode, _ := cfgutil.GetChildOrNil(filePath, fsNode, "scanMethod")
-		if scanMethodNode != nil {
-			cfg.FileSystem.ScanMethod.Type = cfgutil.RequireChildEnum(filePath, scanMethodNode, "type",
-				K8sScanMethodTypeValues, &success)
+		var defaultNames []string
+		for i := 0; i < len(fsNode.Content); i += 2 {
+			fsNameNode := fsNode.Content[i]
+			fsValueNode := fsNode.Content[i+1]
 
-			switch cfg.FileSystem.ScanMethod.Type {
-			case K8sScanMethodTypeWalk:
-				// Do nothing
+			var name string
+			cfgutil.Decode(filePath, fsNameNode, &name, &success)
+			if strings.TrimSpace(name) == "" {
+				cfgutil.ReportError(filePath, fsNameNode, "file system names cannot be empty")
+				success = false
+				continue
+			}
 
-			case K8sScanMethodTypeExtendedAttribute:
-				cfg.FileSystem.ScanMethod.ExtendedAttribute = cfgutil.RequireChildText(filePath, scanMethodNode,
-					"xattr", &success)
+			fs := parseKubernetesFileSystem(filePath, fsValueNode, name, &success)
+			cfg.FileSystems[name] = fs
 
-			case K8sScanMethodTypeDevFile:
-				// Do nothing
+			if isDefault, ok := cfgutil.OptionalChildBool(filePath, fsValueNode, "default"); ok && isDefault {
+				defaultNames = append(defaultNames, name)
 			}
-		} else {
-			cfg.FileSystem.ScanMethod.Type = K8sScanMethodTypeWalk
-		}
-
-		metadataNode, _ := cfgutil.GetChildOrNil(filePath, fsNode, "metadataCatalog")
-		cfg.FileSystem.MetadataCatalog.Enabled = util.DevelopmentModeEnabled()
-		cfg.FileSystem.MetadataCatalog.EnableIntegration = util.DevelopmentModeEnabled()
-		cfg.FileSystem.MetadataCatalog.IOPS = 45_000
-		cfg.FileSystem.MetadataCatalog.ParallelScans = 8
-		cfg.FileSystem.MetadataCatalog.EntriesPerSSTable = 1024 * 16
-		if metadataNode != nil {
-			if enabled, ok := cfgutil.OptionalChildBool(filePath, metadataNode, "enabled"); ok {
-				cfg.FileSystem.MetadataCatalog.Enabled = enabled
-			}
-			if enabled, ok := cfgutil.OptionalChildBool(filePath, metadataNode, "enableIntegration"); ok {
-				cfg.FileSystem.MetadataCatalog.EnableIntegration = enabled
-			}
-			cfg.FileSystem.MetadataCatalog.IOPS = int(cfgutil.OptionalChildInt(
-				filePath, metadataNode, "iops", &success,
-			).GetOrDefault(int64(cfg.FileSystem.MetadataCatalog.IOPS)))
-			cfg.FileSystem.MetadataCatalog.ParallelScans = int(cfgutil.OptionalChildInt(
-				filePath, metadataNode, "parallelScans", &success,
-			).GetOrDefault(int64(cfg.FileSystem.MetadataCatalog.ParallelScans)))
-			cfg.FileSystem.MetadataCatalog.EntriesPerSSTable = int(cfgutil.OptionalChildInt(
-				filePath, metadataNode, "entriesPerSSTable", &success,
-			).GetOrDefault(int64(cfg.FileSystem.MetadataCatalog.EntriesPerSSTable)))
-		}
-		if cfg.FileSystem.MetadataCatalog.IOPS <= 0 || cfg.FileSystem.MetadataCatalog.ParallelScans < 2 ||
-			cfg.FileSystem.MetadataCatalog.EntriesPerSSTable < 10_000 {
-			cfgutil.ReportError(filePath, fsNode, "metadataCatalog requires positive iops, at least 2 parallelScans, and at least 10000 entriesPerSSTable")
+		}
+
+		if len(cfg.FileSystems) == 1 && len(defaultNames) == 0 {
+			for name := range cfg.FileSystems {
+				defaultNames = append(defaultNames, name)
+			}
+		}
+
+		if len(defaultNames) != 1 {
+			cfgutil.ReportError(filePath, fsNode, "exactly one file system must be marked with 'default: true'")
 			success = false
+		} else {
+			cfg.FileSystem = cfg.FileSystems[defaultNames[0]]
+		}
+
+		for name, fs := range cfg.FileSystems {
+			for otherName, other := range cfg.FileSystems {
+				if name >= otherName {
+					continue
+				}
+
+				if fs.ClaimName == other.ClaimName {
+					cfgutil.ReportError(filePath, fsNode, "file systems '%s' and '%s' use the same claimName", name, otherName)
+					success = false
+				}
+
+				if kubernetesPathContains(fs.MountPoint, other.MountPoint) || kubernetesPathContains(other.MountPoint, fs.MountPoint) {
+					cfgutil.ReportError(filePath, fsNode, "file systems '%s' and '%s' have overlapping mount points", name, otherName)
+					success = false
+				}
+			}
+		}
+	} else {
+		fsNode := cfgutil.RequireChild(filePath, services, "fileSystem", &success)
+		name := cfgutil.RequireChildText(filePath, fsNode, "name", &success)
+		cfg.FileSystem = parseKubernetesFileSystem(filePath, fsNode, name, &success)
+		cfg.FileSystems[name] = cfg.FileSystem
+	}
+
+	for _, fs := range cfg.FileSystems {
+		for _, product := range fs.Products {
+			if product.Name == "share" || product.Name == "project-home" {
+				cfgutil.ReportError(filePath, services, "storage products named 'share' or 'project-home' are reserved")
+				success = false
+			}
 		}
 	}
 
@@ -399,6 +468,38 @@ func parseKubernetesServices(unmanaged bool, mode ServerMode, filePath string, s
 		&success,
 	).GetOrDefault(14.5)
 
+	cfg.Compute.Scheduler.WeightAge = 1
+	cfg.Compute.Scheduler.WeightFairShare = 1
+	cfg.Compute.Scheduler.WeightJobSize = 1
+	cfg.Compute.Scheduler.FairShareHalfLife = 7 * 24 * time.Hour
+
+	schedulerNode, _ := cfgutil.GetChildOrNil(filePath, computeNode, "scheduler")
+	if schedulerNode != nil {
+		scheduler := &cfg.Compute.Scheduler
+		scheduler.WeightAge = cfgutil.OptionalChildFloat(filePath, schedulerNode, "weightAge", &success).
+			GetOrDefault(scheduler.WeightAge)
+		scheduler.WeightFairShare = cfgutil.OptionalChildFloat(filePath, schedulerNode, "weightFairShare", &success).
+			GetOrDefault(scheduler.WeightFairShare)
+		scheduler.WeightJobSize = cfgutil.OptionalChildFloat(filePath, schedulerNode, "weightJobSize", &success).
+			GetOrDefault(scheduler.WeightJobSize)
+
+		if scheduler.WeightAge < 0 || scheduler.WeightFairShare < 0 || scheduler.WeightJobSize < 0 {
+			cfgutil.ReportError(filePath, schedulerNode, "scheduler weights must not be negative")
+			success = false
+		}
+
+		// A half-life of 0 disables the decay of usage entirely.
+		halfLifeHours := cfgutil.OptionalChildFloat(filePath, schedulerNode, "fairShareHalfLifeHours", &success)
+		if halfLifeHours.Present {
+			if halfLifeHours.Value < 0 {
+				cfgutil.ReportError(filePath, schedulerNode, "fairShareHalfLifeHours must not be negative")
+				success = false
+			} else {
+				scheduler.FairShareHalfLife = time.Duration(halfLifeHours.Value * float64(time.Hour))
+			}
+		}
+	}
+
 	ucxNode, _ := cfgutil.GetChildOrNil(filePath, computeNode, "ucx")
 	if ucxNode != nil {
 		developmentNode, _ := cfgutil.GetChildOrNil(filePath, ucxNode, "development")
@@ -655,6 +756,9 @@ func parseKubernetesServices(unmanaged bool, mode ServerMode, filePath string, s
 			} else {
 				cfg.Compute.PublicIps.Name = "public-ip"
 			}
+
+			sourceRestrictions, ok := cfgutil.OptionalChildBool(filePath, ipNode, "sourceRestrictions")
+			cfg.Compute.PublicIps.SourceRestrictions = sourceRestrictions && ok
 		}
 	}
 
@@ -676,6 +780,22 @@ func parseKubernetesServices(unmanaged bool, mode ServerMode, filePath string, s
 			if cfg.Compute.PublicLinks.Name == "" {
 				cfg.Compute.PublicLinks.Name = "public-links"
 			}
+
+			customDomainsNode, _ := cfgutil.GetChildOrNil(filePath, ingressNode, "customDomains")
+			if customDomainsNode != nil {
+				customEnabled, ok := cfgutil.OptionalChildBool(filePath, customDomainsNode, "enabled")
+				customDomains := &cfg.Compute.PublicLinks.CustomDomains
+				customDomains.Enabled = customEnabled && ok
+
+				if customDomains.Enabled {
+					customDomains.AcmeDirectory = cfgutil.OptionalChildText(filePath, customDomainsNode, "acmeDirectory", &success)
+					if customDomains.AcmeDirectory == "" {
+						customDomains.AcmeDirectory = defaultAcmeDirectory
+					}
+
+					customDomains.Email = cfgutil.OptionalChildText(filePath, customDomainsNode, "email", &success)
+				}
+			}
 		}
 	}
 
@@ -838,6 +958,23 @@ func parseKubernetesServices(unmanaged bool, mode ServerMode, filePath string, s
 					}
 				}
 			}
+
+			snapshotsNode, _ := cfgutil.GetChildOrNil(filePath, vmNode, "snapshots")
+			if snapshotsNode != nil {
+				snapshotsEnabled, ok := cfgutil.OptionalChildBool(filePath, snapshotsNode, "enabled")
+				vms.Snapshots.Enabled = snapshotsEnabled && ok
+				vms.Snapshots.MaxPerJob = int(cfgutil.OptionalChildInt(filePath, snapshotsNode, "maxPerJob", &success).GetOrDefault(10))
+
+				if vms.Snapshots.Enabled && vms.Storage.Type != KubernetesVmVolCsi {
+					cfgutil.ReportError(filePath, snapshotsNode, "snapshots require storage type %s", KubernetesVmVolCsi)
+					success = false
+				}
+
+				if vms.Snapshots.MaxPerJob <= 0 {
+					cfgutil.ReportError(filePath, snapshotsNode, "maxPerJob must be positive")
+					success = false
+				}
+			}
 		}
 	}
 
@@ -975,3 +1112,120 @@ func parseK8sMachineGroup(filePath string, node *yaml.Node, success *bool) K8sMa
 
 	return result
 }
+
+func parseKubernetesFileSystem(filePath string, fsNode *yaml.Node, name string, success *bool) KubernetesFileSystem {
+	fs := KubernetesFileSystem{Name: name}
+	fs.MountPoint = cfgutil.RequireChildFolder(filePath, fsNode, "mountPoint", cfgutil.FileCheckReadWrite, success)
+	fs.TrashStagingArea = cfgutil.RequireChildFolder(filePath, fsNode, "trashStagingArea", cfgutil.FileCheckReadWrite, success)
+	fs.ClaimName = cfgutil.RequireChildText(filePath, fsNode, "claimName", success)
+
+	scanMethodNode, _ := cfgutil.GetChildOrNil(filePath, fsNode, "scanMethod")
+	if scanMethodNode != nil {
+		fs.ScanMethod.Type = cfgutil.RequireChildEnum(filePath, scanMethodNode, "type",
+			K8sScanMethodTypeValues, success)
+
+		switch fs.ScanMethod.Type {
+		case K8sScanMethodTypeWalk:
+			// Do nothing
+
+		case K8sScanMethodTypeExtendedAttribute:
+			fs.ScanMethod.ExtendedAttribute = cfgutil.RequireChildText(filePath, scanMethodNode,
+				"xattr", success)
+
+		case K8sScanMethodTypeDevFile:
+			// Do nothing
+		}
+	} else {
+		fs.ScanMethod.Type = K8sScanMethodTypeWalk
+	}
+
+	metadataNode, _ := cfgutil.GetChildOrNil(filePath, fsNode, "metadataCatalog")
+	fs.MetadataCatalog.Enabled = util.DevelopmentModeEnabled()
+	fs.MetadataCatalog.EnableIntegration = util.DevelopmentModeEnabled()
+	fs.MetadataCatalog.IOPS = 45_000
+	fs.MetadataCatalog.ParallelScans = 8
+	fs.MetadataCatalog.EntriesPerSSTable = 1024 * 16
+	if metadataNode != nil {
+		if enabled, ok := cfgutil.OptionalChildBool(filePath, metadataNode, "enabled"); ok {
+			fs.MetadataCatalog.Enabled = enabled
+		}
+		if enabled, ok := cfgutil.OptionalChildBool(filePath, metadataNode, "enableIntegration"); ok {
+			fs.MetadataCatalog.EnableIntegration = enabled
+		}
+		fs.MetadataCatalog.IOPS = int(cfgutil.OptionalChildInt(
+			filePath, metadataNode, "iops", success,
+		).GetOrDefault(int64(fs.MetadataCatalog.IOPS)))
+		fs.MetadataCatalog.ParallelScans = int(cfgutil.OptionalChildInt(
+			filePath, metadataNode, "parallelScans", success,
+		).GetOrDefault(int64(fs.MetadataCatalog.ParallelScans)))
+		fs.MetadataCatalog.EntriesPerSSTable = int(cfgutil.OptionalChildInt(
+			filePath, metadataNode, "entriesPerSSTable", success,
+		).GetOrDefault(int64(fs.MetadataCatalog.EntriesPerSSTable)))
+	}
+	if fs.MetadataCatalog.IOPS <= 0 || fs.MetadataCatalog.ParallelScans < 2 ||
+		fs.MetadataCatalog.EntriesPerSSTable < 10_000 {
+		cfgutil.ReportError(filePath, fsNode, "metadataCatalog requires positive iops, at least 2 parallelScans, and at least 10000 entriesPerSSTable")
+		*success = false
+	}
+
+	retentionNode, _ := cfgutil.GetChildOrNil(filePath, fsNode, "retention")
+	if retentionNode != nil {
+		fs.Retention.Enabled = true
+		if enabled, ok := cfgutil.OptionalChildBool(filePath, retentionNode, "enabled"); ok {
+			fs.Retention.Enabled = enabled
+		}
+
+		fs.Retention.MaxAgeDays = int(cfgutil.RequireChildInt(filePath, retentionNode, "maxAgeDays", success))
+		fs.Retention.WarningDays = int(cfgutil.OptionalChildInt(
+			filePath, retentionNode, "warningDays", success,
+		).GetOrDefault(7))
+
+		if fs.Retention.MaxAgeDays <= 0 {
+			cfgutil.ReportError(filePath, retentionNode, "retention requires a positive maxAgeDays")
+			*success = false
+		} else if fs.Retention.WarningDays <= 0 || fs.Retention.WarningDays >= fs.Retention.MaxAgeDays {
+			cfgutil.ReportError(filePath, retentionNode, "retention requires warningDays to be positive and less than maxAgeDays")
+			*success = false
+		}
+
+		if fs.Retention.Enabled && !fs.MetadataCatalog.Enabled {
+			cfgutil.ReportError(filePath, retentionNode, "retention requires the metadataCatalog to be enabled")
+			*success = false
+		}
+	}
+
+	productsNode, _ := cfgutil.GetChildOrNil(filePath, fsNode, "products")
+	if productsNode != nil {
+		if productsNode.Kind != yaml.SequenceNode || len(productsNode.Content) == 0 {
+			cfgutil.ReportError(filePath, productsNode, "expected products to be a non-empty list")
+			*success = false
+		} else {
+			seen := map[string]bool{}
+			for _, productNode := range productsNode.Content {
+				product := KubernetesStorageProduct{}
+				product.Name = cfgutil.RequireChildText(filePath, productNode, "name", success)
+				product.Description = cfgutil.OptionalChildText(filePath, productNode, "description", success)
+				if product.Description == "" {
+					product.Description = "A storage product"
+				}
+
+				if seen[product.Name] {
+					cfgutil.ReportError(filePath, productNode, "product '%s' is specified more than once", product.Name)
+					*success = false
+				}
+				seen[product.Name] = true
+				fs.Products = append(fs.Products, product)
+			}
+		}
+	} else {
+		fs.Products = []KubernetesStorageProduct{{Name: name, Description: "A storage product"}}
+	}
+
+	return fs
+}
+
+func kubernetesPathContains(parent string, child string) bool {
+	parent = filepath.Clean(parent)
+	child = filepath.Clean(child)
+	return parent == child || strings.HasPrefix(child, parent+"/")
+}
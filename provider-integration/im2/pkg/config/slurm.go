@@ -33,6 +33,65 @@ type SlurmCompute struct {
 	Srun                   util.Option[SrunConfiguration]
 	ModulesFile            util.Option[string]
 	JobFolderName          string
+	TimeExtension          SlurmTimeExtensionConfiguration
+	Suspension             SlurmSuspensionConfiguration
+	Client                 SlurmClientConfiguration
+}
+
+type SlurmClientConfiguration struct {
+	Type          SlurmClientType
+	Configuration any
+}
+
+func (c *SlurmClientConfiguration) Rest() *SlurmClientRest {
+	if c.Type == SlurmClientTypeRest {
+		return c.Configuration.(*SlurmClientRest)
+	}
+	return nil
+}
+
+type SlurmClientType string
+
+const (
+	SlurmClientTypeCli  SlurmClientType = "Cli"
+	SlurmClientTypeRest SlurmClientType = "Rest"
+)
+
+var SlurmClientTypeOptions = []SlurmClientType{
+	SlurmClientTypeCli,
+	SlurmClientTypeRest,
+}
+
+type SlurmClientRest struct {
+	Endpoint   string
+	ApiVersion string
+	TokenFile  util.Option[string]
+}
+
+type SlurmTimeExtensionConfiguration struct {
+	Enabled  bool
+	MaxHours util.Option[int]
+}
+
+type SlurmSuspensionConfiguration struct {
+	Enabled bool
+	Method  SlurmSuspensionMethod
+}
+
+type SlurmSuspensionMethod string
+
+const (
+	// SlurmSuspensionMethodSuspend uses `scontrol suspend`. The job keeps its allocation while suspended.
+	SlurmSuspensionMethodSuspend SlurmSuspensionMethod = "Suspend"
+
+	// SlurmSuspensionMethodHold uses `scontrol requeuehold`. The job releases its allocation and is placed back in
+	// the queue until it is released.
+	SlurmSuspensionMethodHold SlurmSuspensionMethod = "Hold"
+)
+
+var SlurmSuspensionMethodOptions = []SlurmSuspensionMethod{
+	SlurmSuspensionMethodSuspend,
+	SlurmSuspensionMethodHold,
 }
 
 type SrunConfiguration struct {
@@ -532,6 +591,50 @@ func parseSlurmServices(unmanaged bool, serverMode ServerMode, filePath string,
 			}
 		}
 
+		clientNode, _ := cfgutil.GetChildOrNil(filePath, slurmNode, "client")
+		cfg.Compute.Client.Type = SlurmClientTypeCli
+		if clientNode != nil {
+			cfg.Compute.Client.Type = cfgutil.RequireChildEnum(filePath, clientNode, "type", SlurmClientTypeOptions, &success)
+			if cfg.Compute.Client.Type == SlurmClientTypeRest {
+				res := parseSlurmClientRest(filePath, clientNode, &success)
+				cfg.Compute.Client.Configuration = &res
+			}
+		}
+
+		timeExtensionNode, _ := cfgutil.GetChildOrNil(filePath, slurmNode, "timeExtension")
+		if timeExtensionNode != nil {
+			enabled, ok := cfgutil.OptionalChildBool(filePath, timeExtensionNode, "enabled")
+			cfg.Compute.TimeExtension.Enabled = enabled && ok
+
+			maxHours := cfgutil.OptionalChildInt(filePath, timeExtensionNode, "maxHours", &success)
+			if maxHours.Present {
+				if maxHours.Value <= 0 {
+					cfgutil.ReportError(filePath, timeExtensionNode, "maxHours must be a positive number")
+					success = false
+				} else {
+					cfg.Compute.TimeExtension.MaxHours.Set(int(maxHours.Value))
+				}
+			}
+		}
+
+		suspensionNode, _ := cfgutil.GetChildOrNil(filePath, slurmNode, "suspension")
+		if suspensionNode != nil {
+			enabled, ok := cfgutil.OptionalChildBool(filePath, suspensionNode, "enabled")
+			cfg.Compute.Suspension.Enabled = enabled && ok
+
+			method, ok := cfgutil.OptionalChildEnum(filePath, suspensionNode, "method", SlurmSuspensionMethodOptions, &success)
+			if ok {
+				cfg.Compute.Suspension.Method = method
+			} else {
+				cfg.Compute.Suspension.Method = SlurmSuspensionMethodSuspend
+			}
+
+			if cfg.Compute.Suspension.Enabled && cfg.Compute.Client.Type == SlurmClientTypeRest {
+				cfgutil.ReportError(filePath, suspensionNode, "suspension is not supported when using the Rest client")
+				success = false
+			}
+		}
+
 		srunChild, _ := cfgutil.GetChildOrNil(filePath, slurmNode, "srun")
 		if srunChild != nil {
 			cfg.Compute.Srun.Set(parseSrunConfiguration(filePath, srunChild, &success))
@@ -780,6 +883,24 @@ func parseAccountMapperPattern(filePath string, node *yaml.Node, success *bool)
 	return result
 }
 
+func parseSlurmClientRest(filePath string, node *yaml.Node, success *bool) SlurmClientRest {
+	result := SlurmClientRest{}
+	result.Endpoint = strings.TrimSuffix(cfgutil.RequireChildText(filePath, node, "endpoint", success), "/")
+
+	result.ApiVersion = cfgutil.OptionalChildText(filePath, node, "apiVersion", success)
+	if result.ApiVersion == "" {
+		result.ApiVersion = "v0.0.40"
+	}
+
+	// The token file is only readable by the server. User instances request their own token from Slurm, as
+	// such we do not check if the file is readable here.
+	tokenFile := cfgutil.OptionalChildText(filePath, node, "tokenFile", success)
+	if tokenFile != "" {
+		result.TokenFile.Set(tokenFile)
+	}
+	return result
+}
+
 func parseAccountMapperScripted(filePath string, node *yaml.Node, success *bool) SlurmAccountMapperScripted {
 	result := SlurmAccountMapperScripted{}
 	result.Script = cfgutil.RequireChildFile(filePath, node, "script", cfgutil.FileCheckRead, success)
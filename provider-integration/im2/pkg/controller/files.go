@@ -35,9 +35,12 @@ type FileService struct {
 	BrowseFiles                 func(request orcapi.FilesProviderBrowseRequest) (fnd.PageV2[orcapi.ProviderFile], *util.HttpError)
 	RetrieveFile                func(request orcapi.FilesProviderRetrieveRequest) (orcapi.ProviderFile, *util.HttpError)
 	Visualize                   func(request orcapi.FilesProviderVisualizeRequest) (orcapi.FilesVisualizeResponse, *util.HttpError)
+	Retention                   func(request orcapi.FilesProviderRetentionRequest) (orcapi.FilesRetentionResponse, *util.HttpError)
 	CreateFolder                func(actor rpc.Actor, request orcapi.FilesProviderCreateFolderRequest) *util.HttpError
 	Move                        func(actor rpc.Actor, request orcapi.FilesProviderMoveOrCopyRequest) *util.HttpError
 	Copy                        func(actor rpc.Actor, request orcapi.FilesProviderMoveOrCopyRequest) *util.HttpError
+	Compress                    func(actor rpc.Actor, request orcapi.FilesProviderArchiveRequest) *util.HttpError
+	Extract                     func(actor rpc.Actor, request orcapi.FilesProviderArchiveRequest) *util.HttpError
 	MoveToTrash                 func(actor rpc.Actor, request orcapi.FilesProviderTrashRequest) *util.HttpError
 	EmptyTrash                  func(actor rpc.Actor, request orcapi.FilesProviderTrashRequest) *util.HttpError
 	CreateDownloadSession       func(actor rpc.Actor, request FileDownloadSession) *util.HttpError
@@ -176,6 +179,14 @@ func initFiles() {
 			return Files.Visualize(request)
 		})
 
+		orcapi.FilesProviderRetention.Handler(func(info rpc.RequestInfo, request orcapi.FilesProviderRetentionRequest) (orcapi.FilesRetentionResponse, *util.HttpError) {
+			DriveTrack(&request.ResolvedCollection)
+			if Files.Retention == nil {
+				return orcapi.FilesRetentionResponse{}, util.HttpErr(http.StatusNotFound, "this drive has no retention policy")
+			}
+			return Files.Retention(request)
+		})
+
 		orcapi.FilesProviderMove.Handler(func(info rpc.RequestInfo, request fnd.BulkRequest[orcapi.FilesProviderMoveOrCopyRequest]) (fnd.BulkResponse[util.Empty], *util.HttpError) {
 			var errors []*util.HttpError
 			for _, item := range request.Items {
@@ -291,6 +302,14 @@ func initFiles() {
 			}
 		})
 
+		orcapi.FilesProviderCompress.Handler(func(info rpc.RequestInfo, request fnd.BulkRequest[orcapi.FilesProviderArchiveRequest]) (fnd.BulkResponse[util.Empty], *util.HttpError) {
+			return filesHandleArchiveRequest(info.Actor, request, Files.Compress)
+		})
+
+		orcapi.FilesProviderExtract.Handler(func(info rpc.RequestInfo, request fnd.BulkRequest[orcapi.FilesProviderArchiveRequest]) (fnd.BulkResponse[util.Empty], *util.HttpError) {
+			return filesHandleArchiveRequest(info.Actor, request, Files.Extract)
+		})
+
 		orcapi.FilesProviderTrash.Handler(func(info rpc.RequestInfo, request fnd.BulkRequest[orcapi.FilesProviderTrashRequest]) (fnd.BulkResponse[util.Empty], *util.HttpError) {
 			var errors []*util.HttpError
 			for _, item := range request.Items {
@@ -1091,3 +1110,31 @@ func FileRetrieveTransferSession(id string) (FileTransferSession, bool) {
 	}
 	return session, true
 }
+
+func filesHandleArchiveRequest(
+	actor rpc.Actor,
+	request fnd.BulkRequest[orcapi.FilesProviderArchiveRequest],
+	fn func(actor rpc.Actor, request orcapi.FilesProviderArchiveRequest) *util.HttpError,
+) (fnd.BulkResponse[util.Empty], *util.HttpError) {
+	if fn == nil {
+		return fnd.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusBadRequest, "archives are not supported")
+	}
+
+	var errors []*util.HttpError
+	for _, item := range request.Items {
+		DriveTrack(&item.ResolvedSourceCollection)
+		DriveTrack(&item.ResolvedDestinationCollection)
+
+		err := fn(actor, item)
+
+		if err != nil {
+			errors = append(errors, err)
+		}
+	}
+
+	if len(errors) > 0 {
+		return fnd.BulkResponse[util.Empty]{}, errors[0]
+	} else {
+		return fnd.BulkResponse[util.Empty]{}, nil
+	}
+}
@@ -0,0 +1,460 @@
+package controller
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	cfg "ucloud.dk/pkg/config"
+	gw "ucloud.dk/pkg/gateway"
+	db "ucloud.dk/shared/pkg/database"
+	orc "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Public links with access control
+// =====================================================================================================================
+// Links which are not public are marked as protected in the gateway. The gateway asks this server about every request
+// made to a protected link (see linkAccessHandleCheck). Requests carrying a valid session cookie are let through, all
+// others are redirected to a login flow:
+//
+// - Links protected by a password are redirected to a form served by this server on the domain of the link.
+// - Links restricted to project members or specific users are redirected to UCloud. UCloud validates the session of
+//   the user, checks the policy and then asks the provider for a one-time grant which is exchanged for a session on
+//   the domain of the link (see LinkAccessAuthorize).
+//
+// Sessions are stored in the database, but all checks are served from memory since they run for every request.
+
+//go:embed link-login.html
+var linkLoginHtml string
+
+var linkLoginTemplate = template.Must(template.New("link-login").Parse(linkLoginHtml))
+
+const (
+	linkAccessCookieName      = "ucloud-link-session"
+	linkAccessSessionDuration = 12 * time.Hour
+	linkAccessGrantDuration   = 1 * time.Minute
+)
+
+type linkAccessDomain struct {
+	LinkId string
+	Mode   orc.IngressAccessMode
+}
+
+type linkAccessSession struct {
+	LinkId    string
+	Username  string
+	ExpiresAt time.Time
+}
+
+var linkAccess = struct {
+	Mu       sync.RWMutex
+	Domains  map[string]linkAccessDomain
+	Sessions map[string]linkAccessSession
+	Grants   map[string]linkAccessSession
+}{
+	Domains:  map[string]linkAccessDomain{},
+	Sessions: map[string]linkAccessSession{},
+	Grants:   map[string]linkAccessSession{},
+}
+
+// initLinkAccess must be called after all links have been fetched and with the ingressesMutex held.
+func initLinkAccess() {
+	rows := db.NewTx(func(tx *db.Transaction) []struct {
+		Token      string
+		ResourceId string
+		Username   string
+		ExpiresAt  time.Time
+	} {
+		db.Exec(tx, `delete from ingress_access_sessions where expires_at < now()`, db.Params{})
+
+		return db.Select[struct {
+			Token      string
+			ResourceId string
+			Username   string
+			ExpiresAt  time.Time
+		}](
+			tx,
+			`
+				select token, resource_id, username, expires_at
+				from ingress_access_sessions
+		    `,
+			db.Params{},
+		)
+	})
+
+	linkAccess.Mu.Lock()
+	for _, row := range rows {
+		linkAccess.Sessions[row.Token] = linkAccessSession{
+			LinkId:    row.ResourceId,
+			Username:  row.Username,
+			ExpiresAt: row.ExpiresAt,
+		}
+	}
+	linkAccess.Mu.Unlock()
+
+	for _, link := range ingresses {
+		linkAccessSync(link)
+	}
+
+	rpc.DefaultServer.Mux.HandleFunc(gw.LinkAccessCheckPath()+"/", linkAccessHandleCheck)
+	rpc.DefaultServer.Mux.HandleFunc(gw.LinkAccessPath()+"/login", linkAccessHandleLogin)
+	rpc.DefaultServer.Mux.HandleFunc(gw.LinkAccessPath()+"/authorize", linkAccessHandleAuthorize)
+
+	go func() {
+		for util.IsAlive {
+			time.Sleep(1 * time.Hour)
+			linkAccessExpireSessions()
+		}
+	}()
+}
+
+// linkAccessSync tells the gateway if the domain of a link should be protected. It is called every time a link is
+// tracked.
+func linkAccessSync(link *orc.Ingress) {
+	domain := link.Specification.Domain
+	mode := orc.IngressAccessModeOrDefault(link.Specification.Access)
+	protected := mode != orc.IngressAccessModePublic
+
+	linkAccess.Mu.Lock()
+	_, wasProtected := linkAccess.Domains[domain]
+	if protected {
+		linkAccess.Domains[domain] = linkAccessDomain{LinkId: link.Id, Mode: mode}
+	} else {
+		delete(linkAccess.Domains, domain)
+	}
+	linkAccess.Mu.Unlock()
+
+	if protected && !wasProtected {
+		gw.SendMessage(gw.ConfigurationMessage{AccessControlUp: &gw.EnvoyAccessControl{Domain: domain}})
+	} else if !protected && wasProtected {
+		gw.SendMessage(gw.ConfigurationMessage{AccessControlDown: &gw.EnvoyAccessControl{Domain: domain}})
+	}
+}
+
+// linkAccessForget removes all access control state of a link which is being deleted.
+func linkAccessForget(tx *db.Transaction, link *orc.Ingress) {
+	linkAccessDropSessions(tx, link.Id)
+
+	db.Exec(
+		tx,
+		`delete from ingress_access_passwords where resource_id = :id`,
+		db.Params{"id": link.Id},
+	)
+
+	linkAccess.Mu.Lock()
+	_, wasProtected := linkAccess.Domains[link.Specification.Domain]
+	delete(linkAccess.Domains, link.Specification.Domain)
+	linkAccess.Mu.Unlock()
+
+	if wasProtected {
+		gw.SendMessage(gw.ConfigurationMessage{
+			AccessControlDown: &gw.EnvoyAccessControl{Domain: link.Specification.Domain},
+		})
+	}
+}
+
+// LinkAccessUpdate stores a new access policy for a link. Existing sessions are dropped since they might no longer
+// satisfy the policy.
+func LinkAccessUpdate(link orc.Ingress, access orc.IngressAccessPolicy) *util.HttpError {
+	err := db.NewTx(func(tx *db.Transaction) *util.HttpError {
+		if access.Mode == orc.IngressAccessModePassword {
+			if access.Password.Present {
+				hashed := util.HashPassword(access.Password.Value, nil)
+				db.Exec(
+					tx,
+					`
+						insert into ingress_access_passwords(resource_id, hashed_password, salt)
+						values (:id, :hashed_password, :salt)
+						on conflict (resource_id) do update set
+							hashed_password = excluded.hashed_password,
+							salt = excluded.salt
+					`,
+					db.Params{
+						"id":              link.Id,
+						"hashed_password": hashed.HashedPassword,
+						"salt":            hashed.Salt,
+					},
+				)
+			} else {
+				_, ok := linkAccessRetrievePassword(tx, link.Id)
+				if !ok {
+					return util.UserHttpError("You must specify a password")
+				}
+			}
+		} else {
+			db.Exec(
+				tx,
+				`delete from ingress_access_passwords where resource_id = :id`,
+				db.Params{"id": link.Id},
+			)
+		}
+
+		linkAccessDropSessions(tx, link.Id)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	access.Password.Clear()
+	link.Specification.Access.Set(access)
+	LinkTrack(link)
+	return nil
+}
+
+// LinkAccessAuthorize is invoked by UCloud once it has verified that a user is allowed through the policy of a link.
+// The returned URL exchanges a short-lived grant for a session on the domain of the link.
+func LinkAccessAuthorize(request orc.IngressesProviderAuthorizeAccessRequest) (orc.IngressesAuthorizeAccessResponse, *util.HttpError) {
+	link := request.Ingress
+	if orc.IngressAccessModeOrDefault(link.Specification.Access) == orc.IngressAccessModePassword {
+		return orc.IngressesAuthorizeAccessResponse{}, util.UserHttpError("This link is protected by a password")
+	}
+
+	token := util.SecureToken()
+	linkAccess.Mu.Lock()
+	linkAccess.Grants[token] = linkAccessSession{
+		LinkId:    link.Id,
+		Username:  request.Username,
+		ExpiresAt: time.Now().Add(linkAccessGrantDuration),
+	}
+	linkAccess.Mu.Unlock()
+
+	return orc.IngressesAuthorizeAccessResponse{
+		RedirectTo: fmt.Sprintf(
+			"https://%v%v/authorize?token=%v&redirect=%v",
+			link.Specification.Domain,
+			gw.LinkAccessPath(),
+			token,
+			url.QueryEscape(linkAccessSanitizeRedirect(request.Redirect)),
+		),
+	}, nil
+}
+
+func linkAccessHandleCheck(w http.ResponseWriter, r *http.Request) {
+	domain := linkAccessDomainOf(r)
+
+	linkAccess.Mu.RLock()
+	entry, protected := linkAccess.Domains[domain]
+	allowed := !protected
+	if protected {
+		if cookie, err := r.Cookie(linkAccessCookieName); err == nil {
+			session, ok := linkAccess.Sessions[cookie.Value]
+			allowed = ok && session.LinkId == entry.LinkId && time.Now().Before(session.ExpiresAt)
+		}
+	}
+	linkAccess.Mu.RUnlock()
+
+	if allowed {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	original, _ := strings.CutPrefix(r.URL.RequestURI(), gw.LinkAccessCheckPath())
+	original = linkAccessSanitizeRedirect(original)
+
+	var location string
+	if entry.Mode == orc.IngressAccessModePassword {
+		location = fmt.Sprintf("%v/login?redirect=%v", gw.LinkAccessPath(), url.QueryEscape(original))
+	} else {
+		location = fmt.Sprintf(
+			"%v/app/public-links/access/%v?redirect=%v",
+			cfg.Provider.Hosts.UCloudPublic.ToURL(),
+			entry.LinkId,
+			url.QueryEscape(original),
+		)
+	}
+
+	w.Header().Set("Location", location)
+	w.WriteHeader(http.StatusFound)
+}
+
+func linkAccessHandleLogin(w http.ResponseWriter, r *http.Request) {
+	domain := linkAccessDomainOf(r)
+
+	linkAccess.Mu.RLock()
+	entry, protected := linkAccess.Domains[domain]
+	linkAccess.Mu.RUnlock()
+
+	if !protected || entry.Mode != orc.IngressAccessModePassword {
+		w.Header().Set("Location", "/")
+		w.WriteHeader(http.StatusFound)
+		return
+	}
+
+	page := struct {
+		Domain   string
+		Redirect string
+		Error    string
+	}{
+		Domain:   domain,
+		Redirect: linkAccessSanitizeRedirect(r.URL.Query().Get("redirect")),
+	}
+
+	status := http.StatusOK
+	if r.Method == http.MethodPost {
+		_ = r.ParseForm()
+		page.Redirect = linkAccessSanitizeRedirect(r.PostForm.Get("redirect"))
+
+		password := r.PostForm.Get("password")
+		stored, ok := db.NewTx2(func(tx *db.Transaction) (util.HashedPasswordAndSalt, bool) {
+			return linkAccessRetrievePassword(tx, entry.LinkId)
+		})
+
+		if ok && util.CheckPassword(stored.HashedPassword, stored.Salt, password) {
+			linkAccessStartSession(w, entry.LinkId, "")
+			w.Header().Set("Location", page.Redirect)
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+
+		// Slow down attempts to guess the password
+		time.Sleep(1 * time.Second)
+		page.Error = "Incorrect password, please try again."
+		status = http.StatusUnauthorized
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	w.WriteHeader(status)
+	_ = linkLoginTemplate.Execute(w, page)
+}
+
+func linkAccessHandleAuthorize(w http.ResponseWriter, r *http.Request) {
+	domain := linkAccessDomainOf(r)
+	token := r.URL.Query().Get("token")
+
+	linkAccess.Mu.Lock()
+	grant, ok := linkAccess.Grants[token]
+	delete(linkAccess.Grants, token)
+	entry, protected := linkAccess.Domains[domain]
+	linkAccess.Mu.Unlock()
+
+	if !ok || !protected || grant.LinkId != entry.LinkId || time.Now().After(grant.ExpiresAt) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("This login attempt has expired. Please try again!"))
+		return
+	}
+
+	linkAccessStartSession(w, grant.LinkId, grant.Username)
+	w.Header().Set("Location", linkAccessSanitizeRedirect(r.URL.Query().Get("redirect")))
+	w.WriteHeader(http.StatusFound)
+}
+
+func linkAccessStartSession(w http.ResponseWriter, linkId string, username string) {
+	token := util.SecureToken()
+	session := linkAccessSession{
+		LinkId:    linkId,
+		Username:  username,
+		ExpiresAt: time.Now().Add(linkAccessSessionDuration),
+	}
+
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				insert into ingress_access_sessions(token, resource_id, username, expires_at)
+				values (:token, :resource_id, :username, :expires_at)
+			`,
+			db.Params{
+				"token":       token,
+				"resource_id": session.LinkId,
+				"username":    session.Username,
+				"expires_at":  session.ExpiresAt,
+			},
+		)
+	})
+
+	linkAccess.Mu.Lock()
+	linkAccess.Sessions[token] = session
+	linkAccess.Mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     linkAccessCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(linkAccessSessionDuration.Seconds()),
+		Secure:   !util.DevelopmentModeEnabled(),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func linkAccessDropSessions(tx *db.Transaction, linkId string) {
+	db.Exec(
+		tx,
+		`delete from ingress_access_sessions where resource_id = :id`,
+		db.Params{"id": linkId},
+	)
+
+	linkAccess.Mu.Lock()
+	for token, session := range linkAccess.Sessions {
+		if session.LinkId == linkId {
+			delete(linkAccess.Sessions, token)
+		}
+	}
+	linkAccess.Mu.Unlock()
+}
+
+func linkAccessExpireSessions() {
+	now := time.Now()
+
+	linkAccess.Mu.Lock()
+	for token, session := range linkAccess.Sessions {
+		if now.After(session.ExpiresAt) {
+			delete(linkAccess.Sessions, token)
+		}
+	}
+
+	for token, grant := range linkAccess.Grants {
+		if now.After(grant.ExpiresAt) {
+			delete(linkAccess.Grants, token)
+		}
+	}
+	linkAccess.Mu.Unlock()
+
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(tx, `delete from ingress_access_sessions where expires_at < now()`, db.Params{})
+	})
+}
+
+func linkAccessRetrievePassword(tx *db.Transaction, linkId string) (util.HashedPasswordAndSalt, bool) {
+	row, ok := db.Get[struct {
+		HashedPassword []byte
+		Salt           []byte
+	}](
+		tx,
+		`
+			select hashed_password, salt
+			from ingress_access_passwords
+			where resource_id = :id
+		`,
+		db.Params{"id": linkId},
+	)
+
+	return util.HashedPasswordAndSalt{HashedPassword: row.HashedPassword, Salt: row.Salt}, ok
+}
+
+func linkAccessDomainOf(r *http.Request) string {
+	host := r.Host
+	if withoutPort, _, err := net.SplitHostPort(host); err == nil {
+		host = withoutPort
+	}
+	return strings.ToLower(host)
+}
+
+// linkAccessSanitizeRedirect only allows redirects to a path on the same domain. This prevents the login flow from
+// being used to send users to other sites.
+func linkAccessSanitizeRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return "/"
+	}
+	return redirect
+}
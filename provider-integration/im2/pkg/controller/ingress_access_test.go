@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	cfg "ucloud.dk/pkg/config"
+	gw "ucloud.dk/pkg/gateway"
+	orc "ucloud.dk/shared/pkg/orchestrators"
+)
+
+func TestLinkAccessSanitizeRedirect(t *testing.T) {
+	testCases := map[string]string{
+		"/":                    "/",
+		"/dashboard?tab=1":     "/dashboard?tab=1",
+		"":                     "/",
+		"https://evil.example": "/",
+		"//evil.example/path":  "/",
+		"/\\evil.example":      "/",
+		"dashboard":            "/",
+	}
+
+	for input, expected := range testCases {
+		if actual := linkAccessSanitizeRedirect(input); actual != expected {
+			t.Fatalf("linkAccessSanitizeRedirect(%q) = %q, expected %q", input, actual, expected)
+		}
+	}
+}
+
+func TestLinkAccessHandleCheck(t *testing.T) {
+	previousProvider := cfg.Provider
+	t.Cleanup(func() {
+		cfg.Provider = previousProvider
+	})
+
+	cfg.Provider = &cfg.ProviderConfiguration{Id: "k8s"}
+	cfg.Provider.Hosts.UCloudPublic = cfg.HostInfo{Address: "cloud.example.org", Port: 443}
+
+	linkAccess.Mu.Lock()
+	linkAccess.Domains["password.example.org"] = linkAccessDomain{LinkId: "1", Mode: orc.IngressAccessModePassword}
+	linkAccess.Domains["project.example.org"] = linkAccessDomain{LinkId: "2", Mode: orc.IngressAccessModeProject}
+	linkAccess.Sessions["valid"] = linkAccessSession{LinkId: "1", ExpiresAt: time.Now().Add(time.Hour)}
+	linkAccess.Sessions["expired"] = linkAccessSession{LinkId: "1", ExpiresAt: time.Now().Add(-time.Hour)}
+	linkAccess.Sessions["other-link"] = linkAccessSession{LinkId: "2", ExpiresAt: time.Now().Add(time.Hour)}
+	linkAccess.Mu.Unlock()
+
+	t.Cleanup(func() {
+		linkAccess.Mu.Lock()
+		linkAccess.Domains = map[string]linkAccessDomain{}
+		linkAccess.Sessions = map[string]linkAccessSession{}
+		linkAccess.Mu.Unlock()
+	})
+
+	check := func(host string, path string, session string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, gw.LinkAccessCheckPath()+path, nil)
+		r.Host = host
+		if session != "" {
+			r.AddCookie(&http.Cookie{Name: linkAccessCookieName, Value: session})
+		}
+
+		w := httptest.NewRecorder()
+		linkAccessHandleCheck(w, r)
+		return w
+	}
+
+	if w := check("public.example.org", "/", ""); w.Code != http.StatusOK {
+		t.Fatalf("links without access control must be let through, got %v", w.Code)
+	}
+
+	if w := check("password.example.org:443", "/app", "valid"); w.Code != http.StatusOK {
+		t.Fatalf("a valid session must be let through, got %v", w.Code)
+	}
+
+	for _, session := range []string{"", "expired", "other-link", "unknown"} {
+		w := check("password.example.org", "/app?x=1", session)
+		if w.Code != http.StatusFound {
+			t.Fatalf("session %q must be redirected, got %v", session, w.Code)
+		}
+
+		location := w.Header().Get("Location")
+		if !strings.HasPrefix(location, gw.LinkAccessPath()+"/login?redirect=%2Fapp%3Fx%3D1") {
+			t.Fatalf("unexpected redirect for session %q: %v", session, location)
+		}
+	}
+
+	w := check("project.example.org", "/", "")
+	location := w.Header().Get("Location")
+	if w.Code != http.StatusFound || location != "https://cloud.example.org/app/public-links/access/2?redirect=%2F" {
+		t.Fatalf("links restricted to users must redirect to UCloud, got %v %v", w.Code, location)
+	}
+}
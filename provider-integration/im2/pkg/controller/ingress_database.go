@@ -24,6 +24,7 @@ func initIngressDatabase() {
 	ingressesMutex.Lock()
 	defer ingressesMutex.Unlock()
 	linksFetchAll()
+	initLinkAccess()
 }
 
 func linksFetchAll() {
@@ -65,6 +66,8 @@ func LinkTrack(ingress orc.Ingress) {
 	ingresses[ingress.Id] = &ingress
 	ingressesMutex.Unlock()
 
+	linkAccessSync(&ingress)
+
 	jsonified, _ := json.Marshal(ingress)
 
 	db.NewTx0(func(tx *db.Transaction) {
@@ -113,6 +116,7 @@ func LinkDeleteTracked(target *orc.Ingress, dbFn func(tx *db.Transaction)) *util
 			},
 		)
 
+		linkAccessForget(tx, target)
 		dbFn(tx)
 	})
 
@@ -389,6 +389,28 @@ func (b *JobUpdateBatch) TrackState(jobId string, state orc.JobState, status uti
 	return false
 }
 
+// TrackArrayTasks sends the aggregated status of the tasks in a job array if it has changed since the last update.
+func (b *JobUpdateBatch) TrackArrayTasks(jobId string, status orc.JobArrayStatus) bool {
+	activeJobsMutex.RLock()
+	currentJob, ok := activeJobs[jobId]
+	var current util.Option[orc.JobArrayStatus]
+	if ok {
+		current = currentJob.Status.ArrayTasks
+	}
+	activeJobsMutex.RUnlock()
+
+	if ok && (!current.Present || current.Value != status) {
+		b.AddUpdate(orc.ResourceUpdateAndId[orc.JobUpdate]{
+			Id: jobId,
+			Update: orc.JobUpdate{
+				ArrayTasks: util.OptValue(status),
+			},
+		})
+		return true
+	}
+	return false
+}
+
 func (b *JobUpdateBatch) PreserveState(jobId string) {
 	activeJobsMutex.RLock()
 	job, ok := activeJobs[jobId]
@@ -538,6 +560,10 @@ func (b *JobUpdateBatch) flush() {
 				job.Status.AllowRestart = u.AllowRestart.Get()
 			}
 
+			if u.ArrayTasks.IsSet() {
+				job.Status.ArrayTasks.Set(u.ArrayTasks.Get())
+			}
+
 			if u.OutputFolder.IsSet() {
 				job.Output.OutputFolder.Set(u.OutputFolder.Get())
 			}
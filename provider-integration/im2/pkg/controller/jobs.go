@@ -45,6 +45,10 @@ type JobsService struct {
 	HandleBuiltInVnc         func(job *orcapi.Job, rank int, conn *ws.Conn)
 	AttachResource           func(job *orcapi.Job, resource orcapi.AppParameterValue) *util.HttpError
 	DetachResource           func(job *orcapi.Job, resource orcapi.AppParameterValue) *util.HttpError
+	CreateSnapshot           func(job *orcapi.Job, title string) (string, *util.HttpError)
+	BrowseSnapshots          func(job *orcapi.Job) ([]orcapi.JobSnapshot, *util.HttpError)
+	RestoreSnapshot          func(job *orcapi.Job, snapshotId string) *util.HttpError
+	DeleteSnapshot           func(job *orcapi.Job, snapshotId string) *util.HttpError
 
 	PublicIPs       PublicIPService
 	Ingresses       IngressService
@@ -71,6 +75,7 @@ type IngressService struct {
 	Delete           func(ingress *orcapi.Ingress) *util.HttpError
 	OnUpdatedLabels  func(ingress *orcapi.Ingress) *util.HttpError
 	RetrieveProducts func() []orcapi.IngressSupport
+	VerifyDomain     func(ingress *orcapi.Ingress) *util.HttpError
 }
 
 type PrivateNetworkService struct {
@@ -778,6 +783,47 @@ func initJobs() {
 			},
 		)
 
+		orcapi.JobsProviderCreateSnapshot.Handler(func(info rpc.RequestInfo, request fnd.BulkRequest[orcapi.JobsProviderCreateSnapshotRequestItem]) (fnd.BulkResponse[fnd.FindByStringId], *util.HttpError) {
+			fn := Jobs.CreateSnapshot
+			if fn == nil {
+				return fnd.BulkResponse[fnd.FindByStringId]{}, util.HttpErr(http.StatusBadRequest, "operation not supported")
+			}
+
+			var response fnd.BulkResponse[fnd.FindByStringId]
+			for _, item := range request.Items {
+				id, err := fn(&item.Job, item.Title)
+				if err != nil {
+					return fnd.BulkResponse[fnd.FindByStringId]{}, err
+				}
+
+				response.Responses = append(response.Responses, fnd.FindByStringId{Id: id})
+			}
+
+			return response, nil
+		})
+
+		orcapi.JobsProviderBrowseSnapshots.Handler(func(info rpc.RequestInfo, request orcapi.JobsProviderBrowseSnapshotsRequest) (orcapi.JobsBrowseSnapshotsResponse, *util.HttpError) {
+			fn := Jobs.BrowseSnapshots
+			if fn == nil {
+				return orcapi.JobsBrowseSnapshotsResponse{}, util.HttpErr(http.StatusBadRequest, "operation not supported")
+			}
+
+			snapshots, err := fn(&request.Job)
+			if snapshots == nil {
+				snapshots = []orcapi.JobSnapshot{}
+			}
+
+			return orcapi.JobsBrowseSnapshotsResponse{Snapshots: snapshots}, err
+		})
+
+		orcapi.JobsProviderRestoreSnapshot.Handler(func(info rpc.RequestInfo, request fnd.BulkRequest[orcapi.JobsProviderSnapshotRequestItem]) (fnd.BulkResponse[util.Empty], *util.HttpError) {
+			return jobHandleSnapshotOperation(Jobs.RestoreSnapshot, request)
+		})
+
+		orcapi.JobsProviderDeleteSnapshot.Handler(func(info rpc.RequestInfo, request fnd.BulkRequest[orcapi.JobsProviderSnapshotRequestItem]) (fnd.BulkResponse[util.Empty], *util.HttpError) {
+			return jobHandleSnapshotOperation(Jobs.DeleteSnapshot, request)
+		})
+
 		orcapi.JobsProviderRequestDynamicParameters.Handler(func(info rpc.RequestInfo, request orcapi.JobsProviderRequestDynamicParametersRequest) (orcapi.JobsProviderRequestDynamicParametersResponse, *util.HttpError) {
 			fn := Jobs.RequestDynamicParameters
 
@@ -1121,6 +1167,45 @@ func initJobs() {
 			return resp, nil
 		})
 
+		orcapi.IngressesProviderVerifyDomain.Handler(func(info rpc.RequestInfo, request fnd.BulkRequest[orcapi.Ingress]) (fnd.BulkResponse[util.Empty], *util.HttpError) {
+			var resp fnd.BulkResponse[util.Empty]
+
+			for _, item := range request.Items {
+				fn := Jobs.Ingresses.VerifyDomain
+				if fn == nil {
+					return fnd.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusBadRequest, "Custom domains are not supported")
+				}
+
+				err := fn(&item)
+				if err != nil {
+					return fnd.BulkResponse[util.Empty]{}, err
+				}
+
+				resp.Responses = append(resp.Responses, util.Empty{})
+			}
+
+			return resp, nil
+		})
+
+		orcapi.IngressesProviderUpdateAccess.Handler(func(info rpc.RequestInfo, request fnd.BulkRequest[orcapi.IngressesProviderUpdateAccessRequest]) (fnd.BulkResponse[util.Empty], *util.HttpError) {
+			var resp fnd.BulkResponse[util.Empty]
+
+			for _, item := range request.Items {
+				err := LinkAccessUpdate(item.Ingress, item.Access)
+				if err != nil {
+					return fnd.BulkResponse[util.Empty]{}, err
+				}
+
+				resp.Responses = append(resp.Responses, util.Empty{})
+			}
+
+			return resp, nil
+		})
+
+		orcapi.IngressesProviderAuthorizeAccess.Handler(func(info rpc.RequestInfo, request orcapi.IngressesProviderAuthorizeAccessRequest) (orcapi.IngressesAuthorizeAccessResponse, *util.HttpError) {
+			return LinkAccessAuthorize(request)
+		})
+
 		orcapi.IngressesProviderOnUpdatedLabels.Handler(func(info rpc.RequestInfo, request fnd.BulkRequest[orcapi.Ingress]) (util.Empty, *util.HttpError) {
 			for _, item := range request.Items {
 				fn := Jobs.Ingresses.OnUpdatedLabels
@@ -1553,6 +1638,23 @@ const (
 	jobsProviderFollowRequestTypeCancel jobsProviderFollowRequestType = "cancel"
 )
 
+func jobHandleSnapshotOperation(
+	fn func(job *orcapi.Job, snapshotId string) *util.HttpError,
+	request fnd.BulkRequest[orcapi.JobsProviderSnapshotRequestItem],
+) (fnd.BulkResponse[util.Empty], *util.HttpError) {
+	if fn == nil {
+		return fnd.BulkResponse[util.Empty]{}, util.HttpErr(http.StatusBadRequest, "operation not supported")
+	}
+
+	for _, item := range request.Items {
+		if err := fn(&item.Job, item.SnapshotId); err != nil {
+			return fnd.BulkResponse[util.Empty]{}, err
+		}
+	}
+
+	return fnd.BulkResponse[util.Empty]{Responses: make([]util.Empty, len(request.Items))}, nil
+}
+
 type jobsProviderFollowRequest struct {
 	Type     jobsProviderFollowRequestType `json:"type"`
 	StreamId string                        `json:"streamId,omitempty"` // cancel only
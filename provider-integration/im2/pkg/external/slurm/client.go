@@ -11,11 +11,44 @@ import (
 	"ucloud.dk/shared/pkg/util"
 )
 
-type Client struct {
+// Client is used by the integration to communicate with Slurm. The default implementation invokes the Slurm
+// command-line tools (see NewClient). An alternative implementation communicates with slurmrestd (see NewRestClient).
+type Client interface {
+	AccountExists(name string) bool
+	AccountQuery(name string) *Account
+	AccountCreate(a *Account) bool
+	AccountModify(a *Account) bool
+	AccountDelete(name string) bool
+	AccountAddUser(user, account string) bool
+	AccountRemoveUser(user, account string) bool
+	AccountBillingList() map[string]int64
+
+	UserExists(name string) bool
+	UserQuery(name string) *User
+	UserCreate(u *User) bool
+	UserModify(u *User) bool
+	UserDelete(name string) bool
+	UserListAccounts(user string) []string
+
+	JobQuery(id int) (*Job, bool)
+	JobList() []Job
+	JobSubmit(pathToScript string) (int, error)
+	JobComment(jobId int) (string, bool)
+	JobCancel(id int) bool
+	JobExtendTimeLimit(id int, minutes int) bool
+	JobSuspend(id int) bool
+	JobResume(id int) bool
+	JobHold(id int) bool
+	JobRequeueHold(id int) bool
+	JobRelease(id int) bool
+	JobGetNodeList(id int) []string
+}
+
+type cliClient struct {
 	hasJSON bool
 }
 
-func NewClient() *Client {
+func NewClient() Client {
 	cmd := []string{"squeue", "--version"}
 	data, _, ok := util.RunCommand(cmd)
 	if !ok {
@@ -26,12 +59,12 @@ func NewClient() *Client {
 	match := re.FindStringSubmatch(data)
 	major, _ := strconv.Atoi(match[1])
 
-	return &Client{
+	return &cliClient{
 		hasJSON: (major >= 23),
 	}
 }
 
-func (c *Client) AccountExists(name string) bool {
+func (c *cliClient) AccountExists(name string) bool {
 	if len(name) == 0 {
 		return false
 	}
@@ -41,7 +74,7 @@ func (c *Client) AccountExists(name string) bool {
 	return (len(stdout) > 0)
 }
 
-func (c *Client) AccountQuery(name string) *Account {
+func (c *cliClient) AccountQuery(name string) *Account {
 	if len(name) == 0 {
 		return nil
 	}
@@ -68,7 +101,7 @@ func (c *Client) AccountQuery(name string) *Account {
 	return account
 }
 
-func (c *Client) AccountCreate(a *Account) bool {
+func (c *cliClient) AccountCreate(a *Account) bool {
 	if a == nil {
 		return false
 	}
@@ -87,7 +120,7 @@ func (c *Client) AccountCreate(a *Account) bool {
 	return ok
 }
 
-func (c *Client) AccountModify(a *Account) bool {
+func (c *cliClient) AccountModify(a *Account) bool {
 	if a == nil {
 		return false
 	}
@@ -102,7 +135,7 @@ func (c *Client) AccountModify(a *Account) bool {
 	return ok
 }
 
-func (c *Client) AccountDelete(name string) bool {
+func (c *cliClient) AccountDelete(name string) bool {
 	if len(name) == 0 {
 		return false
 	}
@@ -112,7 +145,7 @@ func (c *Client) AccountDelete(name string) bool {
 	return ok
 }
 
-func (c *Client) AccountAddUser(user, account string) bool {
+func (c *cliClient) AccountAddUser(user, account string) bool {
 	if len(user) == 0 {
 		return false
 	}
@@ -126,7 +159,7 @@ func (c *Client) AccountAddUser(user, account string) bool {
 	return ok
 }
 
-func (c *Client) AccountRemoveUser(user, account string) bool {
+func (c *cliClient) AccountRemoveUser(user, account string) bool {
 	if len(user) == 0 {
 		return false
 	}
@@ -140,7 +173,7 @@ func (c *Client) AccountRemoveUser(user, account string) bool {
 	return ok
 }
 
-func (c *Client) UserExists(name string) bool {
+func (c *cliClient) UserExists(name string) bool {
 	if len(name) == 0 {
 		return false
 	}
@@ -150,7 +183,7 @@ func (c *Client) UserExists(name string) bool {
 	return (len(stdout) > 0)
 }
 
-func (c *Client) UserQuery(name string) *User {
+func (c *cliClient) UserQuery(name string) *User {
 	if len(name) == 0 {
 		return nil
 	}
@@ -170,7 +203,7 @@ func (c *Client) UserQuery(name string) *User {
 	return nil
 }
 
-func (c *Client) UserCreate(u *User) bool {
+func (c *cliClient) UserCreate(u *User) bool {
 	if u == nil {
 		return false
 	}
@@ -193,7 +226,7 @@ func (c *Client) UserCreate(u *User) bool {
 	return ok
 }
 
-func (c *Client) UserModify(u *User) bool {
+func (c *cliClient) UserModify(u *User) bool {
 	if u == nil {
 		return false
 	}
@@ -208,7 +241,7 @@ func (c *Client) UserModify(u *User) bool {
 	return ok
 }
 
-func (c *Client) UserDelete(name string) bool {
+func (c *cliClient) UserDelete(name string) bool {
 	if len(name) == 0 {
 		return false
 	}
@@ -218,7 +251,7 @@ func (c *Client) UserDelete(name string) bool {
 	return ok
 }
 
-func (c *Client) JobQuery(id int) (*Job, bool) {
+func (c *cliClient) JobQuery(id int) (*Job, bool) {
 	if id <= 0 {
 		return nil, false
 	}
@@ -238,7 +271,7 @@ func (c *Client) JobQuery(id int) (*Job, bool) {
 	return nil, true
 }
 
-func (c *Client) JobList() []Job {
+func (c *cliClient) JobList() []Job {
 	cmd := []string{"sacct", "-XPa", "-o", "jobid,state,user,account,jobname,partition,elapsed,timelimit,alloctres,qos,nodelist,comment"}
 	stdout, _, ok := util.RunCommand(cmd)
 	if !ok {
@@ -250,38 +283,11 @@ func (c *Client) JobList() []Job {
 	return jobs
 }
 
-func (c *Client) JobSubmit(pathToScript string) (int, error) {
+func (c *cliClient) JobSubmit(pathToScript string) (int, error) {
 	cmd := []string{"sbatch", pathToScript}
 	stdout, stderr, ok := util.RunCommand(cmd)
 	if !ok {
-		errorMessage := stdout + stderr
-		if strings.Contains(errorMessage, "Requested time limit is invalid") {
-			return -1, (&util.HttpError{
-				StatusCode: http.StatusBadRequest,
-				Why: "The requested time limit is larger than what the system allows. " +
-					"Try with a smaller time allocation.",
-			}).AsError()
-		} else if strings.Contains(errorMessage, "Node count specification invalid") {
-			return -1, (&util.HttpError{
-				StatusCode: http.StatusBadRequest,
-				Why:        "Too many nodes requested. Try with a smaller amount of nodes.",
-			}).AsError()
-		} else if strings.Contains(errorMessage, "More processors") {
-			return -1, (&util.HttpError{
-				StatusCode: http.StatusBadRequest,
-				Why:        "Too many nodes requested. Try with a smaller amount of nodes.",
-			}).AsError()
-		} else if strings.Contains(errorMessage, "Requested node config") {
-			return -1, (&util.HttpError{
-				StatusCode: http.StatusBadRequest,
-				Why:        "Too many nodes requested. Try with a smaller amount of nodes.",
-			}).AsError()
-		} else {
-			return -1, (&util.HttpError{
-				StatusCode: http.StatusBadRequest,
-				Why:        fmt.Sprintf("%s", errorMessage),
-			}).AsError()
-		}
+		return -1, jobSubmitError(stdout + stderr)
 	}
 
 	jobId, err := strconv.Atoi(strings.TrimSpace(stdout))
@@ -295,7 +301,38 @@ func (c *Client) JobSubmit(pathToScript string) (int, error) {
 	return jobId, nil
 }
 
-func (c *Client) JobComment(jobId int) (string, bool) {
+// jobSubmitError translates the error message produced by Slurm during submission into a user-friendly error.
+func jobSubmitError(errorMessage string) error {
+	if strings.Contains(errorMessage, "Requested time limit is invalid") {
+		return (&util.HttpError{
+			StatusCode: http.StatusBadRequest,
+			Why: "The requested time limit is larger than what the system allows. " +
+				"Try with a smaller time allocation.",
+		}).AsError()
+	} else if strings.Contains(errorMessage, "Node count specification invalid") {
+		return (&util.HttpError{
+			StatusCode: http.StatusBadRequest,
+			Why:        "Too many nodes requested. Try with a smaller amount of nodes.",
+		}).AsError()
+	} else if strings.Contains(errorMessage, "More processors") {
+		return (&util.HttpError{
+			StatusCode: http.StatusBadRequest,
+			Why:        "Too many nodes requested. Try with a smaller amount of nodes.",
+		}).AsError()
+	} else if strings.Contains(errorMessage, "Requested node config") {
+		return (&util.HttpError{
+			StatusCode: http.StatusBadRequest,
+			Why:        "Too many nodes requested. Try with a smaller amount of nodes.",
+		}).AsError()
+	} else {
+		return (&util.HttpError{
+			StatusCode: http.StatusBadRequest,
+			Why:        fmt.Sprintf("%s", errorMessage),
+		}).AsError()
+	}
+}
+
+func (c *cliClient) JobComment(jobId int) (string, bool) {
 	cmd := []string{"squeue", fmt.Sprintf("--job=%d", jobId), "--format=%k"}
 	stdout, _, ok := util.RunCommand(cmd)
 	if !ok {
@@ -310,7 +347,7 @@ func (c *Client) JobComment(jobId int) (string, bool) {
 	}
 }
 
-func (c *Client) JobCancel(id int) bool {
+func (c *cliClient) JobCancel(id int) bool {
 	if id <= 0 {
 		return false
 	}
@@ -320,7 +357,76 @@ func (c *Client) JobCancel(id int) bool {
 	return ok
 }
 
-func (c *Client) JobGetNodeList(id int) []string {
+// JobExtendTimeLimit increases the time limit of a job by the requested amount of minutes. Note that Slurm only
+// allows operators and administrators to increase the time limit of a job.
+func (c *cliClient) JobExtendTimeLimit(id int, minutes int) bool {
+	if id <= 0 || minutes <= 0 {
+		return false
+	}
+
+	cmd := []string{"scontrol", "update", fmt.Sprintf("JobId=%d", id), fmt.Sprintf("TimeLimit=+%d", minutes)}
+	_, _, ok := util.RunCommand(cmd)
+	return ok
+}
+
+// JobSuspend suspends a running job. The job keeps its allocation while suspended. Note that Slurm only allows
+// operators and administrators to suspend jobs.
+func (c *cliClient) JobSuspend(id int) bool {
+	if id <= 0 {
+		return false
+	}
+
+	cmd := []string{"scontrol", "suspend", fmt.Sprint(id)}
+	_, _, ok := util.RunCommand(cmd)
+	return ok
+}
+
+// JobResume resumes a job previously suspended with JobSuspend.
+func (c *cliClient) JobResume(id int) bool {
+	if id <= 0 {
+		return false
+	}
+
+	cmd := []string{"scontrol", "resume", fmt.Sprint(id)}
+	_, _, ok := util.RunCommand(cmd)
+	return ok
+}
+
+// JobHold prevents a pending job from being started.
+func (c *cliClient) JobHold(id int) bool {
+	if id <= 0 {
+		return false
+	}
+
+	cmd := []string{"scontrol", "hold", fmt.Sprint(id)}
+	_, _, ok := util.RunCommand(cmd)
+	return ok
+}
+
+// JobRequeueHold stops a running job and places it back in the queue in a held state. The job will not start again
+// until it is released with JobRelease.
+func (c *cliClient) JobRequeueHold(id int) bool {
+	if id <= 0 {
+		return false
+	}
+
+	cmd := []string{"scontrol", "requeuehold", fmt.Sprint(id)}
+	_, _, ok := util.RunCommand(cmd)
+	return ok
+}
+
+// JobRelease releases a job previously held with JobHold or JobRequeueHold.
+func (c *cliClient) JobRelease(id int) bool {
+	if id <= 0 {
+		return false
+	}
+
+	cmd := []string{"scontrol", "release", fmt.Sprint(id)}
+	_, _, ok := util.RunCommand(cmd)
+	return ok
+}
+
+func (c *cliClient) JobGetNodeList(id int) []string {
 	stdout, _, ok := util.RunCommand([]string{"sacct", "--jobs", fmt.Sprint(id), "--format", "nodelist",
 		"--parsable2", "--allusers", "--allocations", "--noheader"})
 	if !ok {
@@ -349,7 +455,7 @@ func expandNodeList(str string) []string {
 	return result
 }
 
-func (c *Client) AccountBillingList() map[string]int64 {
+func (c *cliClient) AccountBillingList() map[string]int64 {
 	var result = map[string]int64{}
 	stdout, _, ok := util.RunCommand([]string{"sshare", "-Pho", "account,user,grptresraw"})
 	if !ok {
@@ -385,7 +491,7 @@ func (c *Client) AccountBillingList() map[string]int64 {
 	return result
 }
 
-func (c *Client) UserListAccounts(user string) []string {
+func (c *cliClient) UserListAccounts(user string) []string {
 	stdout, _, ok := util.RunCommand([]string{"sacctmgr", "show", "user", user, "--associations", "format=account", "--parsable2", "--noheader"})
 	if !ok {
 		return nil
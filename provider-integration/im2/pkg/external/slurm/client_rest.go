@@ -0,0 +1,1296 @@
+package slurm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ucloud.dk/shared/pkg/log"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// restClient implements Client using slurmrestd. Compared to the command-line client, this avoids forking a new
+// process for every single operation.
+//
+// Authentication uses JSON web tokens. If a token file is configured and readable, then the token in it is used.
+// Otherwise, a token is requested for the current user through `scontrol token`. Tokens requested this way are cached
+// until shortly before they expire.
+type restClient struct {
+	endpoint   string
+	apiVersion string
+	tokenFile  util.Option[string]
+	username   string
+	http       *http.Client
+
+	tokenMutex     sync.Mutex
+	cachedToken    string
+	cachedTokenExp time.Time
+}
+
+func NewRestClient(endpoint string, apiVersion string, tokenFile util.Option[string]) Client {
+	username := ""
+	current, err := user.Current()
+	if err == nil {
+		username = current.Username
+	}
+
+	return &restClient{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		apiVersion: apiVersion,
+		tokenFile:  tokenFile,
+		username:   username,
+		http:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// REST models
+// =====================================================================================================================
+// These only contain the subset of the slurmrestd data model used by the integration.
+
+type restNumber struct {
+	Set      bool  `json:"set"`
+	Infinite bool  `json:"infinite"`
+	Number   int64 `json:"number"`
+}
+
+func restNumberOf(value int) restNumber {
+	if value < 0 {
+		return restNumber{Set: true, Infinite: true}
+	}
+	return restNumber{Set: true, Number: int64(value)}
+}
+
+func (n restNumber) toInt() int {
+	if !n.Set || n.Infinite {
+		return -1
+	}
+	return int(n.Number)
+}
+
+type restTres struct {
+	Type  string `json:"type"`
+	Name  string `json:"name,omitempty"`
+	Count int64  `json:"count"`
+}
+
+func (t restTres) key() string {
+	if t.Name != "" {
+		return t.Type + "/" + t.Name
+	}
+	return t.Type
+}
+
+func restTresFromMap(values map[string]int) []restTres {
+	var result []restTres
+	for key, value := range values {
+		tresType, tresName, _ := strings.Cut(key, "/")
+		result = append(result, restTres{Type: tresType, Name: tresName, Count: int64(value)})
+	}
+	slices.SortFunc(result, func(a, b restTres) int { return strings.Compare(a.key(), b.key()) })
+	return result
+}
+
+func restTresToMap(values []restTres, memoryInBytes bool) map[string]int {
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := map[string]int{}
+	for _, tres := range values {
+		if tres.Type == "mem" && memoryInBytes {
+			// slurmrestd reports memory in megabytes while sacct output is parsed into bytes.
+			result[tres.key()] = int(tres.Count) * (1 << 20)
+		} else {
+			result[tres.key()] = int(tres.Count)
+		}
+	}
+	return result
+}
+
+type restAccount struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Organization string `json:"organization"`
+}
+
+type restAssociation struct {
+	Account       string   `json:"account"`
+	User          string   `json:"user"`
+	Cluster       string   `json:"cluster,omitempty"`
+	Partition     string   `json:"partition,omitempty"`
+	ParentAccount string   `json:"parent_account,omitempty"`
+	Qos           []string `json:"qos,omitempty"`
+	Default       struct {
+		Qos string `json:"qos,omitempty"`
+	} `json:"default"`
+	SharesRaw int `json:"shares_raw"`
+	Max       struct {
+		Jobs struct {
+			Per struct {
+				Count     restNumber `json:"count"`
+				Submitted restNumber `json:"submitted"`
+			} `json:"per"`
+		} `json:"jobs"`
+		Tres struct {
+			Group struct {
+				Minutes []restTres `json:"minutes"`
+			} `json:"group"`
+		} `json:"tres"`
+	} `json:"max"`
+}
+
+type restAssociationCondition struct {
+	Accounts    []string         `json:"accounts"`
+	Users       []string         `json:"users,omitempty"`
+	Association *restAssociation `json:"association,omitempty"`
+}
+
+type restUser struct {
+	Name    string `json:"name"`
+	Default struct {
+		Account string `json:"account,omitempty"`
+	} `json:"default"`
+	AdministratorLevel []string          `json:"administrator_level,omitempty"`
+	Associations       []restAssociation `json:"associations,omitempty"`
+}
+
+type restShare struct {
+	Name   string   `json:"name"`
+	Parent string   `json:"parent"`
+	Type   []string `json:"type"`
+	Shares struct {
+		Number int64 `json:"number"`
+	} `json:"shares"`
+	Usage int64 `json:"usage"`
+	Tres  struct {
+		GroupMinutes []restShareTres `json:"group_minutes"`
+		Usage        []restShareTres `json:"usage"`
+	} `json:"tres"`
+}
+
+type restShareTres struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+func restShareTresToMap(values []restShareTres) map[string]int {
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := map[string]int{}
+	for _, tres := range values {
+		result[tres.Name] = int(tres.Value)
+	}
+	return result
+}
+
+// restDbJob is a job as returned by slurmdbd (i.e. the equivalent of sacct).
+type restDbJob struct {
+	JobId     int    `json:"job_id"`
+	Name      string `json:"name"`
+	User      string `json:"user"`
+	Account   string `json:"account"`
+	Partition string `json:"partition"`
+	Qos       string `json:"qos"`
+	Nodes     string `json:"nodes"`
+	Comment   struct {
+		Job string `json:"job"`
+	} `json:"comment"`
+	State struct {
+		Current []string `json:"current"`
+	} `json:"state"`
+	Time struct {
+		Elapsed int        `json:"elapsed"`
+		Limit   restNumber `json:"limit"`
+	} `json:"time"`
+	Tres struct {
+		Allocated []restTres `json:"allocated"`
+	} `json:"tres"`
+	Array struct {
+		JobId  int        `json:"job_id"`
+		TaskId restNumber `json:"task_id"`
+		Task   string     `json:"task"`
+	} `json:"array"`
+}
+
+func (j *restDbJob) toJob() Job {
+	state := ""
+	if len(j.State.Current) > 0 {
+		state = j.State.Current[0]
+	}
+
+	timeLimit := j.Time.Limit.toInt()
+	if timeLimit > 0 {
+		timeLimit *= 60
+	}
+
+	result := Job{
+		JobID:     j.JobId,
+		Name:      j.Name,
+		User:      j.User,
+		Account:   j.Account,
+		Partition: j.Partition,
+		State:     state,
+		Elapsed:   j.Time.Elapsed,
+		TimeLimit: timeLimit,
+		AllocTRES: restTresToMap(j.Tres.Allocated, true),
+		QoS:       j.Qos,
+		NodeList:  j.Nodes,
+	}
+
+	// Every task of an array has its own job ID. Tasks are reported using the ID of the array instead, which is the
+	// ID returned at submission.
+	if j.Array.JobId > 0 {
+		result.JobID = j.Array.JobId
+		if taskId := j.Array.TaskId.toInt(); taskId >= 0 {
+			result.ArrayTask = fmt.Sprint(taskId)
+		} else {
+			result.ArrayTask = j.Array.Task
+		}
+	}
+
+	return result
+}
+
+// restCtldJob is a job as returned by slurmctld (i.e. the equivalent of squeue).
+type restCtldJob struct {
+	JobId     int        `json:"job_id"`
+	Comment   string     `json:"comment"`
+	TimeLimit restNumber `json:"time_limit"`
+}
+
+// restJobDescription is the job description used for submission and updates.
+type restJobDescription struct {
+	Name                    string      `json:"name,omitempty"`
+	Account                 string      `json:"account,omitempty"`
+	Partition               string      `json:"partition,omitempty"`
+	Qos                     string      `json:"qos,omitempty"`
+	Comment                 string      `json:"comment,omitempty"`
+	Constraints             string      `json:"constraints,omitempty"`
+	CurrentWorkingDirectory string      `json:"current_working_directory,omitempty"`
+	StandardOutput          string      `json:"standard_output,omitempty"`
+	StandardError           string      `json:"standard_error,omitempty"`
+	Environment             []string    `json:"environment,omitempty"`
+	CpusPerTask             int         `json:"cpus_per_task,omitempty"`
+	TresPerTask             string      `json:"tres_per_task,omitempty"`
+	MinimumNodes            int         `json:"minimum_nodes,omitempty"`
+	MaximumNodes            int         `json:"maximum_nodes,omitempty"`
+	MemoryPerNode           *restNumber `json:"memory_per_node,omitempty"`
+	TimeLimit               *restNumber `json:"time_limit,omitempty"`
+	Hold                    *bool       `json:"hold,omitempty"`
+	Array                   string      `json:"array,omitempty"`
+	Dependency              string      `json:"dependency,omitempty"`
+}
+
+type restJobSubmitRequest struct {
+	Script string             `json:"script"`
+	Job    restJobDescription `json:"job"`
+}
+
+type restJobSubmitResponse struct {
+	JobId int `json:"job_id"`
+}
+
+type restError struct {
+	Error       string `json:"error"`
+	Description string `json:"description"`
+	ErrorNumber int    `json:"error_number"`
+}
+
+type restResponse struct {
+	Errors []restError `json:"errors"`
+}
+
+// Transport
+// =====================================================================================================================
+
+func (c *restClient) token() (string, bool) {
+	if c.tokenFile.Present {
+		data, err := os.ReadFile(c.tokenFile.Value)
+		if err == nil {
+			return strings.TrimSpace(string(data)), true
+		}
+	}
+
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	if c.cachedToken != "" && time.Now().Before(c.cachedTokenExp) {
+		return c.cachedToken, true
+	}
+
+	lifespan := 3600
+	stdout, _, ok := util.RunCommand([]string{"scontrol", "token", fmt.Sprintf("lifespan=%d", lifespan)})
+	if !ok {
+		return "", false
+	}
+
+	token, found := strings.CutPrefix(strings.TrimSpace(stdout), "SLURM_JWT=")
+	if !found || token == "" {
+		return "", false
+	}
+
+	c.cachedToken = token
+	c.cachedTokenExp = time.Now().Add(time.Duration(lifespan-300) * time.Second)
+	return token, true
+}
+
+func (c *restClient) path(api string, path string, args ...any) string {
+	return fmt.Sprintf("/%s/%s/", api, c.apiVersion) + fmt.Sprintf(path, args...)
+}
+
+// call performs a single request against slurmrestd. The response is decoded into out, if out is not nil. The error
+// message reported by slurmrestd is returned on failure.
+func (c *restClient) call(method string, path string, query url.Values, body any, out any) (bool, string) {
+	token, ok := c.token()
+	if !ok {
+		return false, "unable to retrieve token for slurmrestd"
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return false, err.Error()
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	fullUrl := c.endpoint + path
+	if len(query) > 0 {
+		fullUrl += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, fullUrl, reqBody)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	req.Header.Set("X-SLURM-USER-NAME", c.username)
+	req.Header.Set("X-SLURM-USER-TOKEN", token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		log.Warn("slurmrestd request failed: %s %s: %v", method, path, err)
+		return false, err.Error()
+	}
+
+	defer util.SilentClose(resp.Body)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	var status restResponse
+	_ = json.Unmarshal(data, &status)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || len(status.Errors) > 0 {
+		message := fmt.Sprintf("slurmrestd responded with status %d", resp.StatusCode)
+		if len(status.Errors) > 0 {
+			message = status.Errors[0].Description
+			if message == "" {
+				message = status.Errors[0].Error
+			}
+		}
+
+		if resp.StatusCode != http.StatusNotFound {
+			log.Warn("slurmrestd request failed: %s %s: %s", method, path, message)
+		}
+		return false, message
+	}
+
+	if out != nil {
+		err = json.Unmarshal(data, out)
+		if err != nil {
+			log.Warn("slurmrestd returned malformed data: %s %s: %v", method, path, err)
+			return false, err.Error()
+		}
+	}
+	return true, ""
+}
+
+// Accounts
+// =====================================================================================================================
+
+func (c *restClient) associations(account string, user util.Option[string]) ([]restAssociation, bool) {
+	query := url.Values{}
+	query.Set("account", account)
+	if user.Present {
+		query.Set("user", user.Value)
+	}
+
+	var resp struct {
+		Associations []restAssociation `json:"associations"`
+	}
+
+	ok, _ := c.call(http.MethodGet, c.path("slurmdb", "associations"), query, nil, &resp)
+	return resp.Associations, ok
+}
+
+func (c *restClient) AccountExists(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+
+	var resp struct {
+		Accounts []restAccount `json:"accounts"`
+	}
+
+	ok, _ := c.call(http.MethodGet, c.path("slurmdb", "account/%s", url.PathEscape(name)), nil, nil, &resp)
+	return ok && len(resp.Accounts) > 0
+}
+
+func (c *restClient) AccountQuery(name string) *Account {
+	if len(name) == 0 {
+		return nil
+	}
+
+	var accResp struct {
+		Accounts []restAccount `json:"accounts"`
+	}
+
+	ok, _ := c.call(http.MethodGet, c.path("slurmdb", "account/%s", url.PathEscape(name)), nil, nil, &accResp)
+	if !ok || len(accResp.Accounts) == 0 {
+		return nil
+	}
+
+	account := NewAccount()
+	account.Name = accResp.Accounts[0].Name
+	account.Description = accResp.Accounts[0].Description
+	account.Organization = accResp.Accounts[0].Organization
+
+	associations, ok := c.associations(name, util.OptNone[string]())
+	if !ok {
+		return nil
+	}
+
+	for _, assoc := range associations {
+		if assoc.User != "" {
+			if !slices.Contains(account.Users, assoc.User) {
+				account.Users = append(account.Users, assoc.User)
+			}
+			continue
+		}
+
+		if assoc.ParentAccount != "" {
+			account.Parent.Set(assoc.ParentAccount)
+		}
+		account.DefaultQoS = assoc.Default.Qos
+		account.QoS = assoc.Qos
+		account.MaxJobs = assoc.Max.Jobs.Per.Count.toInt()
+		account.MaxSubmitJobs = assoc.Max.Jobs.Per.Submitted.toInt()
+		account.QuotaTRES = restTresToMap(assoc.Max.Tres.Group.Minutes, false)
+	}
+
+	var shareResp struct {
+		Shares struct {
+			Shares []restShare `json:"shares"`
+		} `json:"shares"`
+	}
+
+	query := url.Values{}
+	query.Set("accounts", name)
+	ok, _ = c.call(http.MethodGet, c.path("slurm", "shares"), query, nil, &shareResp)
+	if !ok {
+		return nil
+	}
+
+	for _, share := range shareResp.Shares.Shares {
+		if share.Name != name || !slices.Contains(share.Type, "ASSOCIATION") {
+			continue
+		}
+
+		account.RawShares = int(share.Shares.Number)
+		account.RawUsage = int(share.Usage)
+		account.UsageTRES = restShareTresToMap(share.Tres.Usage)
+		break
+	}
+
+	return account
+}
+
+func restAssociationFromAccount(a *Account, assoc *restAssociation) {
+	assoc.Account = a.Name
+	if a.Parent.Present {
+		assoc.ParentAccount = a.Parent.Value
+	}
+	if len(a.QoS) > 0 {
+		assoc.Qos = a.QoS
+	}
+	if a.DefaultQoS != "" {
+		assoc.Default.Qos = a.DefaultQoS
+	}
+	assoc.SharesRaw = a.RawShares
+	assoc.Max.Jobs.Per.Count = restNumberOf(a.MaxJobs)
+	assoc.Max.Jobs.Per.Submitted = restNumberOf(a.MaxSubmitJobs)
+	if len(a.QuotaTRES) > 0 {
+		assoc.Max.Tres.Group.Minutes = restTresFromMap(a.QuotaTRES)
+	}
+}
+
+func (c *restClient) AccountCreate(a *Account) bool {
+	if a == nil {
+		return false
+	}
+
+	if !validateName(a.Name) {
+		return false
+	}
+
+	if c.AccountExists(a.Name) {
+		return false
+	}
+
+	assoc := &restAssociation{}
+	restAssociationFromAccount(a, assoc)
+
+	body := map[string]any{
+		"association_condition": restAssociationCondition{
+			Accounts:    []string{a.Name},
+			Association: assoc,
+		},
+		"account": restAccount{
+			Name:         a.Name,
+			Description:  a.Description,
+			Organization: a.Organization,
+		},
+	}
+
+	ok, _ := c.call(http.MethodPost, c.path("slurmdb", "accounts_association"), nil, body, nil)
+	return ok
+}
+
+func (c *restClient) AccountModify(a *Account) bool {
+	if a == nil {
+		return false
+	}
+
+	if !c.AccountExists(a.Name) {
+		return false
+	}
+
+	accountBody := map[string]any{
+		"accounts": []restAccount{{Name: a.Name, Description: a.Description, Organization: a.Organization}},
+	}
+
+	ok, _ := c.call(http.MethodPost, c.path("slurmdb", "accounts"), nil, accountBody, nil)
+	if !ok {
+		return false
+	}
+
+	associations, ok := c.associations(a.Name, util.OptNone[string]())
+	if !ok {
+		return false
+	}
+
+	var updated []restAssociation
+	for _, assoc := range associations {
+		if assoc.User != "" {
+			continue
+		}
+
+		restAssociationFromAccount(a, &assoc)
+		updated = append(updated, assoc)
+	}
+
+	if len(updated) == 0 {
+		return false
+	}
+
+	ok, _ = c.call(http.MethodPost, c.path("slurmdb", "associations"), nil, map[string]any{"associations": updated}, nil)
+	return ok
+}
+
+func (c *restClient) AccountDelete(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+
+	ok, _ := c.call(http.MethodDelete, c.path("slurmdb", "account/%s", url.PathEscape(name)), nil, nil, nil)
+	return ok
+}
+
+func (c *restClient) AccountAddUser(user, account string) bool {
+	if len(user) == 0 {
+		return false
+	}
+
+	if len(account) == 0 {
+		return false
+	}
+
+	body := map[string]any{
+		"association_condition": restAssociationCondition{
+			Accounts: []string{account},
+			Users:    []string{user},
+		},
+		"user": map[string]any{},
+	}
+
+	ok, _ := c.call(http.MethodPost, c.path("slurmdb", "users_association"), nil, body, nil)
+	return ok
+}
+
+func (c *restClient) AccountRemoveUser(user, account string) bool {
+	if len(user) == 0 {
+		return false
+	}
+
+	if len(account) == 0 {
+		return false
+	}
+
+	query := url.Values{}
+	query.Set("account", account)
+	query.Set("user", user)
+	ok, _ := c.call(http.MethodDelete, c.path("slurmdb", "associations"), query, nil, nil)
+	return ok
+}
+
+func (c *restClient) AccountBillingList() map[string]int64 {
+	var result = map[string]int64{}
+
+	var resp struct {
+		Shares struct {
+			Shares []restShare `json:"shares"`
+		} `json:"shares"`
+	}
+
+	ok, _ := c.call(http.MethodGet, c.path("slurm", "shares"), nil, nil, &resp)
+	if !ok {
+		return result
+	}
+
+	for _, share := range resp.Shares.Shares {
+		if share.Name == "" || !slices.Contains(share.Type, "ASSOCIATION") {
+			continue
+		}
+
+		for _, tres := range share.Tres.Usage {
+			if tres.Name == "billing" {
+				result[share.Name] = int64(tres.Value)
+			}
+		}
+	}
+	return result
+}
+
+// Users
+// =====================================================================================================================
+
+func (c *restClient) user(name string) (*restUser, bool) {
+	var resp struct {
+		Users []restUser `json:"users"`
+	}
+
+	query := url.Values{}
+	query.Set("with_assocs", "true")
+	ok, _ := c.call(http.MethodGet, c.path("slurmdb", "user/%s", url.PathEscape(name)), query, nil, &resp)
+	if !ok || len(resp.Users) == 0 {
+		return nil, ok
+	}
+	return &resp.Users[0], true
+}
+
+func (c *restClient) UserExists(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+
+	u, _ := c.user(name)
+	return u != nil
+}
+
+func (c *restClient) UserQuery(name string) *User {
+	if len(name) == 0 {
+		return nil
+	}
+
+	u, _ := c.user(name)
+	if u == nil {
+		return nil
+	}
+
+	result := &User{
+		Name:           u.Name,
+		DefaultAccount: u.Default.Account,
+	}
+
+	if len(u.AdministratorLevel) > 0 {
+		result.Privilege = u.AdministratorLevel[0]
+	}
+
+	for _, assoc := range u.Associations {
+		result.Accounts = append(result.Accounts, assoc.Account)
+	}
+	return result
+}
+
+func (c *restClient) UserCreate(u *User) bool {
+	if u == nil {
+		return false
+	}
+
+	if !validateName(u.Name) {
+		return false
+	}
+
+	if len(u.DefaultAccount) == 0 {
+		return false
+	}
+
+	if c.UserExists(u.Name) {
+		return false
+	}
+
+	user := restUser{Name: u.Name}
+	user.Default.Account = u.DefaultAccount
+	if u.Privilege != "" {
+		user.AdministratorLevel = []string{u.Privilege}
+	}
+
+	body := map[string]any{
+		"association_condition": restAssociationCondition{
+			Accounts: []string{u.DefaultAccount},
+			Users:    []string{u.Name},
+		},
+		"user": user,
+	}
+
+	ok, _ := c.call(http.MethodPost, c.path("slurmdb", "users_association"), nil, body, nil)
+	return ok
+}
+
+func (c *restClient) UserModify(u *User) bool {
+	if u == nil {
+		return false
+	}
+
+	if !c.UserExists(u.Name) {
+		return false
+	}
+
+	user := restUser{Name: u.Name}
+	user.Default.Account = u.DefaultAccount
+	if u.Privilege != "" {
+		user.AdministratorLevel = []string{u.Privilege}
+	}
+
+	ok, _ := c.call(http.MethodPost, c.path("slurmdb", "users"), nil, map[string]any{"users": []restUser{user}}, nil)
+	return ok
+}
+
+func (c *restClient) UserDelete(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+
+	ok, _ := c.call(http.MethodDelete, c.path("slurmdb", "user/%s", url.PathEscape(name)), nil, nil, nil)
+	return ok
+}
+
+func (c *restClient) UserListAccounts(user string) []string {
+	u, _ := c.user(user)
+	if u == nil {
+		return nil
+	}
+
+	var deduped []string
+	for _, assoc := range u.Associations {
+		if !slices.Contains(deduped, assoc.Account) {
+			deduped = append(deduped, assoc.Account)
+		}
+	}
+	return deduped
+}
+
+// Jobs
+// =====================================================================================================================
+
+func (c *restClient) dbJob(id int) (*restDbJob, bool) {
+	var resp struct {
+		Jobs []restDbJob `json:"jobs"`
+	}
+
+	ok, _ := c.call(http.MethodGet, c.path("slurmdb", "job/%d", id), nil, nil, &resp)
+	if !ok || len(resp.Jobs) == 0 {
+		return nil, ok
+	}
+	return &resp.Jobs[0], true
+}
+
+func (c *restClient) ctldJob(id int) (*restCtldJob, bool) {
+	var resp struct {
+		Jobs []restCtldJob `json:"jobs"`
+	}
+
+	ok, _ := c.call(http.MethodGet, c.path("slurm", "job/%d", id), nil, nil, &resp)
+	if !ok || len(resp.Jobs) == 0 {
+		return nil, false
+	}
+	return &resp.Jobs[0], true
+}
+
+func (c *restClient) updateJob(id int, desc restJobDescription) bool {
+	ok, _ := c.call(http.MethodPost, c.path("slurm", "job/%d", id), nil, desc, nil)
+	return ok
+}
+
+func (c *restClient) JobQuery(id int) (*Job, bool) {
+	if id <= 0 {
+		return nil, false
+	}
+
+	job, ok := c.dbJob(id)
+	if !ok {
+		return nil, false
+	}
+
+	if job == nil {
+		return nil, true
+	}
+
+	result := job.toJob()
+	return &result, true
+}
+
+func (c *restClient) JobList() []Job {
+	// This matches the default time window of sacct, which only includes jobs from the current day.
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	query := url.Values{}
+	query.Set("start_time", fmt.Sprint(midnight.Unix()))
+
+	var resp struct {
+		Jobs []restDbJob `json:"jobs"`
+	}
+
+	ok, _ := c.call(http.MethodGet, c.path("slurmdb", "jobs"), query, nil, &resp)
+	if !ok {
+		return nil
+	}
+
+	var jobs []Job
+	for _, job := range resp.Jobs {
+		jobs = append(jobs, job.toJob())
+	}
+	return jobs
+}
+
+func (c *restClient) JobSubmit(pathToScript string) (int, error) {
+	script, err := os.ReadFile(pathToScript)
+	if err != nil {
+		return -1, (&util.HttpError{
+			StatusCode: http.StatusInternalServerError,
+			Why:        "Failed to read job script",
+		}).AsError()
+	}
+
+	desc, err := restJobDescriptionFromScript(string(script))
+	if err != nil {
+		return -1, (&util.HttpError{
+			StatusCode: http.StatusBadRequest,
+			Why:        err.Error(),
+		}).AsError()
+	}
+
+	// slurmrestd requires an environment to be set. sbatch would normally forward the environment of the
+	// caller, we do the same here.
+	desc.Environment = os.Environ()
+
+	var resp restJobSubmitResponse
+	ok, message := c.call(http.MethodPost, c.path("slurm", "job/submit"), nil,
+		restJobSubmitRequest{Script: string(script), Job: desc}, &resp)
+
+	if !ok {
+		return -1, jobSubmitError(message)
+	}
+
+	if resp.JobId <= 0 {
+		return -1, (&util.HttpError{
+			StatusCode: http.StatusBadRequest,
+			Why:        "Failed to understand output of slurmrestd. Expected a job ID.",
+		}).AsError()
+	}
+
+	return resp.JobId, nil
+}
+
+func (c *restClient) JobComment(jobId int) (string, bool) {
+	job, ok := c.ctldJob(jobId)
+	if !ok {
+		return "", false
+	}
+	return job.Comment, true
+}
+
+func (c *restClient) JobCancel(id int) bool {
+	if id <= 0 {
+		return false
+	}
+
+	ok, _ := c.call(http.MethodDelete, c.path("slurm", "job/%d", id), nil, nil, nil)
+	return ok
+}
+
+func (c *restClient) JobExtendTimeLimit(id int, minutes int) bool {
+	if id <= 0 || minutes <= 0 {
+		return false
+	}
+
+	job, ok := c.ctldJob(id)
+	if !ok {
+		return false
+	}
+
+	current := job.TimeLimit.toInt()
+	if current < 0 {
+		// Already unlimited
+		return true
+	}
+
+	newLimit := restNumberOf(current + minutes)
+	return c.updateJob(id, restJobDescription{TimeLimit: &newLimit})
+}
+
+// slurmrestd has no equivalent of scontrol suspend, resume and requeuehold. The configuration rejects suspension when
+// the Rest client is used, such that these are never called.
+
+func (c *restClient) JobSuspend(id int) bool {
+	log.Warn("Unable to suspend job %d: suspension is not supported by slurmrestd", id)
+	return false
+}
+
+func (c *restClient) JobResume(id int) bool {
+	log.Warn("Unable to resume job %d: suspension is not supported by slurmrestd", id)
+	return false
+}
+
+func (c *restClient) JobHold(id int) bool {
+	if id <= 0 {
+		return false
+	}
+
+	hold := true
+	return c.updateJob(id, restJobDescription{Hold: &hold})
+}
+
+func (c *restClient) JobRequeueHold(id int) bool {
+	log.Warn("Unable to requeue job %d: requeue is not supported by slurmrestd", id)
+	return false
+}
+
+func (c *restClient) JobRelease(id int) bool {
+	if id <= 0 {
+		return false
+	}
+
+	hold := false
+	return c.updateJob(id, restJobDescription{Hold: &hold})
+}
+
+func (c *restClient) JobGetNodeList(id int) []string {
+	job, _ := c.dbJob(id)
+	if job == nil {
+		return nil
+	}
+
+	return expandHostList(job.Nodes)
+}
+
+// Job scripts
+// =====================================================================================================================
+
+// restJobDescriptionFromScript converts the #SBATCH directives of a job script into a job description. Only the
+// directives used by the integration are converted. Other directives, such as those supplied by applications, are left
+// in the script which is submitted as-is and parsed by slurmrestd.
+func restJobDescriptionFromScript(script string) (restJobDescription, error) {
+	result := restJobDescription{}
+
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#!") {
+			continue
+		}
+
+		directive, isDirective := strings.CutPrefix(line, "#SBATCH")
+		if !isDirective {
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			// sbatch stops reading directives at the first command
+			break
+		}
+
+		directive = strings.TrimSpace(directive)
+		directive, isLong := strings.CutPrefix(directive, "--")
+		if !isLong {
+			continue
+		}
+
+		key, value, _ := strings.Cut(directive, " ")
+		if k, v, hasEquals := strings.Cut(key, "="); hasEquals {
+			key = k
+			value = v
+		}
+
+		value = unescapeBash(strings.TrimSpace(value))
+
+		switch key {
+		case "parsable":
+			// Not relevant for slurmrestd
+
+		case "job-name":
+			result.Name = value
+
+		case "account":
+			result.Account = value
+
+		case "partition":
+			result.Partition = value
+
+		case "qos":
+			result.Qos = value
+
+		case "comment":
+			result.Comment = value
+
+		case "constraint":
+			result.Constraints = value
+
+		case "chdir":
+			result.CurrentWorkingDirectory = value
+
+		case "output":
+			result.StandardOutput = value
+
+		case "error":
+			result.StandardError = value
+
+		case "array":
+			result.Array = value
+
+		case "dependency":
+			result.Dependency = value
+
+		case "cpus-per-task":
+			cpus, err := strconv.Atoi(value)
+			if err != nil {
+				return result, fmt.Errorf("invalid value for cpus-per-task: %s", value)
+			}
+			result.CpusPerTask = cpus
+
+		case "gpus-per-task":
+			gpus, err := strconv.Atoi(value)
+			if err != nil {
+				return result, fmt.Errorf("invalid value for gpus-per-task: %s", value)
+			}
+			if gpus > 0 {
+				result.TresPerTask = fmt.Sprintf("gres/gpu:%d", gpus)
+			}
+
+		case "nodes":
+			nodes, err := strconv.Atoi(value)
+			if err != nil {
+				return result, fmt.Errorf("invalid value for nodes: %s", value)
+			}
+			result.MinimumNodes = nodes
+			result.MaximumNodes = nodes
+
+		case "mem":
+			megabytes, ok := parseSbatchMemory(value)
+			if !ok {
+				return result, fmt.Errorf("invalid value for mem: %s", value)
+			}
+			mem := restNumberOf(megabytes)
+			result.MemoryPerNode = &mem
+
+		case "time":
+			minutes, ok := parseSbatchTime(value)
+			if !ok {
+				return result, fmt.Errorf("invalid value for time: %s", value)
+			}
+			limit := restNumberOf(minutes)
+			result.TimeLimit = &limit
+
+		default:
+			// Passed through in the script
+		}
+	}
+
+	return result, nil
+}
+
+// unescapeBash reverses orchestrators.EscapeBash for values consisting of single-quoted strings.
+func unescapeBash(value string) string {
+	if !strings.ContainsAny(value, "'\"") {
+		return value
+	}
+
+	builder := &strings.Builder{}
+	var quote rune = 0
+	for _, c := range value {
+		switch {
+		case quote == 0 && (c == '\'' || c == '"'):
+			quote = c
+		case quote != 0 && c == quote:
+			quote = 0
+		default:
+			builder.WriteRune(c)
+		}
+	}
+	return builder.String()
+}
+
+// parseSbatchMemory parses a memory specification from sbatch into megabytes. Values without a unit are in megabytes.
+func parseSbatchMemory(value string) (int, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	multiplier := 1
+	switch strings.ToUpper(value[len(value)-1:]) {
+	case "M":
+		value = value[:len(value)-1]
+	case "G":
+		multiplier = 1024
+		value = value[:len(value)-1]
+	case "T":
+		multiplier = 1024 * 1024
+		value = value[:len(value)-1]
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, false
+	}
+	return number * multiplier, true
+}
+
+// parseSbatchTime parses a time specification from sbatch into minutes. Accepted formats are "minutes",
+// "minutes:seconds", "hours:minutes:seconds", "days-hours", "days-hours:minutes" and "days-hours:minutes:seconds".
+func parseSbatchTime(value string) (int, bool) {
+	days := 0
+	if d, rest, hasDays := strings.Cut(value, "-"); hasDays {
+		parsed, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, false
+		}
+		days = parsed
+		value = rest
+	}
+
+	var parts []int
+	for _, part := range strings.Split(value, ":") {
+		parsed, err := strconv.Atoi(part)
+		if err != nil || parsed < 0 {
+			return 0, false
+		}
+		parts = append(parts, parsed)
+	}
+
+	seconds := 0
+	if days > 0 {
+		switch len(parts) {
+		case 1:
+			seconds = parts[0] * 3600
+		case 2:
+			seconds = parts[0]*3600 + parts[1]*60
+		case 3:
+			seconds = parts[0]*3600 + parts[1]*60 + parts[2]
+		default:
+			return 0, false
+		}
+	} else {
+		switch len(parts) {
+		case 1:
+			seconds = parts[0] * 60
+		case 2:
+			seconds = parts[0]*60 + parts[1]
+		case 3:
+			seconds = parts[0]*3600 + parts[1]*60 + parts[2]
+		default:
+			return 0, false
+		}
+	}
+
+	seconds += days * 86400
+	return (seconds + 59) / 60, true
+}
+
+// expandHostList expands a compact Slurm host list (e.g. "node[01-03,07],gpu1") into individual host names. This is
+// the equivalent of `scontrol show hostname`.
+func expandHostList(hostList string) []string {
+	var result []string
+
+	// Split on commas which are not inside brackets
+	var entries []string
+	depth := 0
+	start := 0
+	for i, c := range hostList {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				entries = append(entries, hostList[start:i])
+				start = i + 1
+			}
+		}
+	}
+	entries = append(entries, hostList[start:])
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || entry == "None assigned" {
+			continue
+		}
+
+		open := strings.Index(entry, "[")
+		end := strings.LastIndex(entry, "]")
+		if open == -1 || end < open {
+			result = append(result, entry)
+			continue
+		}
+
+		prefix := entry[:open]
+		suffix := entry[end+1:]
+		for _, rangeSpec := range strings.Split(entry[open+1:end], ",") {
+			from, to, isRange := strings.Cut(rangeSpec, "-")
+			if !isRange {
+				result = append(result, prefix+from+suffix)
+				continue
+			}
+
+			fromNumber, err1 := strconv.Atoi(from)
+			toNumber, err2 := strconv.Atoi(to)
+			if err1 != nil || err2 != nil || toNumber < fromNumber {
+				continue
+			}
+
+			for i := fromNumber; i <= toNumber; i++ {
+				result = append(result, fmt.Sprintf("%s%0*d%s", prefix, len(from), i, suffix))
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,584 @@
+package slurm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FakeRestServer is an in-memory implementation of the subset of slurmrestd used by the REST client. It is intended
+// for tests which need to exercise the Slurm integration without a real cluster. Jobs never progress on their own,
+// tests must drive them using SetJobState.
+type FakeRestServer struct {
+	URL        string
+	ApiVersion string
+	Token      string
+
+	server *httptest.Server
+
+	mu           sync.Mutex
+	accounts     map[string]restAccount
+	associations []restAssociation
+	users        map[string]restUser
+	jobs         map[int]*fakeRestJob
+	nextJobId    int
+	usage        map[string]int64
+}
+
+type fakeRestJob struct {
+	Job    restDbJob
+	Script string
+	Hold   bool
+}
+
+func NewFakeRestServer(token string) *FakeRestServer {
+	s := &FakeRestServer{
+		ApiVersion: "v0.0.40",
+		Token:      token,
+		accounts:   map[string]restAccount{},
+		users:      map[string]restUser{},
+		jobs:       map[int]*fakeRestJob{},
+		nextJobId:  1000,
+		usage:      map[string]int64{},
+	}
+
+	mux := http.NewServeMux()
+	db := "/slurmdb/" + s.ApiVersion + "/"
+	ctld := "/slurm/" + s.ApiVersion + "/"
+
+	mux.HandleFunc("GET "+db+"account/{name}", s.handleAccountRetrieve)
+	mux.HandleFunc("DELETE "+db+"account/{name}", s.handleAccountDelete)
+	mux.HandleFunc("POST "+db+"accounts", s.handleAccountsUpdate)
+	mux.HandleFunc("POST "+db+"accounts_association", s.handleAccountsAssociation)
+	mux.HandleFunc("GET "+db+"associations", s.handleAssociationsBrowse)
+	mux.HandleFunc("POST "+db+"associations", s.handleAssociationsUpdate)
+	mux.HandleFunc("DELETE "+db+"associations", s.handleAssociationsDelete)
+	mux.HandleFunc("GET "+db+"user/{name}", s.handleUserRetrieve)
+	mux.HandleFunc("DELETE "+db+"user/{name}", s.handleUserDelete)
+	mux.HandleFunc("POST "+db+"users", s.handleUsersUpdate)
+	mux.HandleFunc("POST "+db+"users_association", s.handleUsersAssociation)
+	mux.HandleFunc("GET "+db+"job/{id}", s.handleDbJobRetrieve)
+	mux.HandleFunc("GET "+db+"jobs", s.handleDbJobsBrowse)
+	mux.HandleFunc("GET "+ctld+"shares", s.handleShares)
+	mux.HandleFunc("POST "+ctld+"job/submit", s.handleJobSubmit)
+	mux.HandleFunc("GET "+ctld+"job/{id}", s.handleCtldJobRetrieve)
+	mux.HandleFunc("POST "+ctld+"job/{id}", s.handleCtldJobUpdate)
+	mux.HandleFunc("DELETE "+ctld+"job/{id}", s.handleCtldJobCancel)
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-SLURM-USER-TOKEN") != s.Token {
+			s.fail(w, http.StatusUnauthorized, "authentication failure")
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+
+	s.URL = s.server.URL
+	return s
+}
+
+func (s *FakeRestServer) Close() {
+	s.server.Close()
+}
+
+// SetJobState changes the state of a job as reported by slurmdbd and slurmctld.
+func (s *FakeRestServer) SetJobState(id int, state string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if ok {
+		job.Job.State.Current = []string{state}
+	}
+	return ok
+}
+
+// SetJobNodes changes the compact node list of a job.
+func (s *FakeRestServer) SetJobNodes(id int, nodes string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if ok {
+		job.Job.Nodes = nodes
+	}
+	return ok
+}
+
+// SetBillingUsage sets the raw billing usage (in TRES-minutes) of an account.
+func (s *FakeRestServer) SetBillingUsage(account string, usage int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage[account] = usage
+}
+
+// JobScript returns the script submitted for a job.
+func (s *FakeRestServer) JobScript(id int) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return "", false
+	}
+	return job.Script, true
+}
+
+// JobIsHeld returns true if the job is currently held.
+func (s *FakeRestServer) JobIsHeld(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	return ok && job.Hold
+}
+
+func (s *FakeRestServer) reply(w http.ResponseWriter, payload any) {
+	data, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func (s *FakeRestServer) fail(w http.ResponseWriter, status int, description string) {
+	data, _ := json.Marshal(restResponse{Errors: []restError{{Error: http.StatusText(status), Description: description}}})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+func (s *FakeRestServer) decode(w http.ResponseWriter, r *http.Request, out any) bool {
+	err := json.NewDecoder(r.Body).Decode(out)
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, fmt.Sprintf("malformed request: %v", err))
+		return false
+	}
+	return true
+}
+
+func (s *FakeRestServer) findAssociation(account, user string) int {
+	return slices.IndexFunc(s.associations, func(a restAssociation) bool {
+		return a.Account == account && a.User == user
+	})
+}
+
+func (s *FakeRestServer) upsertAssociation(assoc restAssociation) {
+	idx := s.findAssociation(assoc.Account, assoc.User)
+	if idx == -1 {
+		s.associations = append(s.associations, assoc)
+	} else {
+		s.associations[idx] = assoc
+	}
+}
+
+// Accounts
+// =====================================================================================================================
+
+func (s *FakeRestServer) handleAccountRetrieve(w http.ResponseWriter, r *http.Request) {
+	account, ok := s.accounts[r.PathValue("name")]
+	if !ok {
+		s.reply(w, map[string]any{"accounts": []restAccount{}})
+	} else {
+		s.reply(w, map[string]any{"accounts": []restAccount{account}})
+	}
+}
+
+func (s *FakeRestServer) handleAccountDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, ok := s.accounts[name]; !ok {
+		s.fail(w, http.StatusNotFound, "no such account")
+		return
+	}
+
+	delete(s.accounts, name)
+	s.associations = slices.DeleteFunc(s.associations, func(a restAssociation) bool { return a.Account == name })
+	s.reply(w, map[string]any{})
+}
+
+func (s *FakeRestServer) handleAccountsUpdate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Accounts []restAccount `json:"accounts"`
+	}
+
+	if !s.decode(w, r, &req) {
+		return
+	}
+
+	for _, account := range req.Accounts {
+		s.accounts[account.Name] = account
+	}
+	s.reply(w, map[string]any{})
+}
+
+func (s *FakeRestServer) handleAccountsAssociation(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Condition restAssociationCondition `json:"association_condition"`
+		Account   restAccount              `json:"account"`
+	}
+
+	if !s.decode(w, r, &req) {
+		return
+	}
+
+	for _, name := range req.Condition.Accounts {
+		account := req.Account
+		account.Name = name
+		s.accounts[name] = account
+
+		assoc := restAssociation{}
+		if req.Condition.Association != nil {
+			assoc = *req.Condition.Association
+		}
+		assoc.Account = name
+		assoc.User = ""
+		if assoc.ParentAccount == "" {
+			assoc.ParentAccount = "root"
+		}
+		s.upsertAssociation(assoc)
+	}
+	s.reply(w, map[string]any{})
+}
+
+// Associations
+// =====================================================================================================================
+
+func (s *FakeRestServer) handleAssociationsBrowse(w http.ResponseWriter, r *http.Request) {
+	account := r.URL.Query().Get("account")
+	user := r.URL.Query().Get("user")
+
+	result := []restAssociation{}
+	for _, assoc := range s.associations {
+		if account != "" && assoc.Account != account {
+			continue
+		}
+		if user != "" && assoc.User != user {
+			continue
+		}
+		result = append(result, assoc)
+	}
+	s.reply(w, map[string]any{"associations": result})
+}
+
+func (s *FakeRestServer) handleAssociationsUpdate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Associations []restAssociation `json:"associations"`
+	}
+
+	if !s.decode(w, r, &req) {
+		return
+	}
+
+	for _, assoc := range req.Associations {
+		if _, ok := s.accounts[assoc.Account]; !ok {
+			s.fail(w, http.StatusBadRequest, "no such account")
+			return
+		}
+		s.upsertAssociation(assoc)
+	}
+	s.reply(w, map[string]any{})
+}
+
+func (s *FakeRestServer) handleAssociationsDelete(w http.ResponseWriter, r *http.Request) {
+	account := r.URL.Query().Get("account")
+	user := r.URL.Query().Get("user")
+
+	idx := s.findAssociation(account, user)
+	if idx == -1 {
+		s.fail(w, http.StatusNotFound, "no such association")
+		return
+	}
+
+	s.associations = slices.Delete(s.associations, idx, idx+1)
+	s.reply(w, map[string]any{})
+}
+
+// Users
+// =====================================================================================================================
+
+func (s *FakeRestServer) handleUserRetrieve(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	user, ok := s.users[name]
+	if !ok {
+		s.reply(w, map[string]any{"users": []restUser{}})
+		return
+	}
+
+	if r.URL.Query().Get("with_assocs") == "true" {
+		for _, assoc := range s.associations {
+			if assoc.User == name {
+				user.Associations = append(user.Associations, assoc)
+			}
+		}
+	}
+
+	s.reply(w, map[string]any{"users": []restUser{user}})
+}
+
+func (s *FakeRestServer) handleUserDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, ok := s.users[name]; !ok {
+		s.fail(w, http.StatusNotFound, "no such user")
+		return
+	}
+
+	delete(s.users, name)
+	s.associations = slices.DeleteFunc(s.associations, func(a restAssociation) bool { return a.User == name })
+	s.reply(w, map[string]any{})
+}
+
+func (s *FakeRestServer) handleUsersUpdate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Users []restUser `json:"users"`
+	}
+
+	if !s.decode(w, r, &req) {
+		return
+	}
+
+	for _, user := range req.Users {
+		user.Associations = nil
+		s.users[user.Name] = user
+	}
+	s.reply(w, map[string]any{})
+}
+
+func (s *FakeRestServer) handleUsersAssociation(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Condition restAssociationCondition `json:"association_condition"`
+		User      restUser                 `json:"user"`
+	}
+
+	if !s.decode(w, r, &req) {
+		return
+	}
+
+	for _, account := range req.Condition.Accounts {
+		if _, ok := s.accounts[account]; !ok {
+			s.fail(w, http.StatusBadRequest, "no such account")
+			return
+		}
+	}
+
+	for _, name := range req.Condition.Users {
+		user, exists := s.users[name]
+		if !exists {
+			user = req.User
+			user.Name = name
+			if user.Default.Account == "" && len(req.Condition.Accounts) > 0 {
+				user.Default.Account = req.Condition.Accounts[0]
+			}
+			if len(user.AdministratorLevel) == 0 {
+				user.AdministratorLevel = []string{"None"}
+			}
+			user.Associations = nil
+			s.users[name] = user
+		}
+
+		for _, account := range req.Condition.Accounts {
+			if s.findAssociation(account, name) == -1 {
+				s.associations = append(s.associations, restAssociation{Account: account, User: name})
+			}
+		}
+	}
+	s.reply(w, map[string]any{})
+}
+
+func (s *FakeRestServer) handleShares(w http.ResponseWriter, r *http.Request) {
+	var filter []string
+	if accounts := r.URL.Query().Get("accounts"); accounts != "" {
+		filter = strings.Split(accounts, ",")
+	}
+
+	shares := []restShare{}
+	for _, assoc := range s.associations {
+		if filter != nil && !slices.Contains(filter, assoc.Account) {
+			continue
+		}
+
+		share := restShare{}
+		if assoc.User == "" {
+			share.Name = assoc.Account
+			share.Parent = assoc.ParentAccount
+			share.Type = []string{"ASSOCIATION"}
+
+			usage := s.usage[assoc.Account]
+			share.Usage = usage * 60
+			share.Tres.Usage = []restShareTres{{Name: "billing", Value: float64(usage)}}
+			for _, tres := range assoc.Max.Tres.Group.Minutes {
+				share.Tres.GroupMinutes = append(share.Tres.GroupMinutes, restShareTres{Name: tres.key(), Value: float64(tres.Count)})
+			}
+		} else {
+			share.Name = assoc.User
+			share.Parent = assoc.Account
+			share.Type = []string{"USER"}
+		}
+		share.Shares.Number = int64(assoc.SharesRaw)
+		shares = append(shares, share)
+	}
+
+	result := map[string]any{}
+	result["shares"] = map[string]any{"shares": shares}
+	s.reply(w, result)
+}
+
+// Jobs
+// =====================================================================================================================
+
+func (s *FakeRestServer) jobFromPath(w http.ResponseWriter, r *http.Request) (*fakeRestJob, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, "invalid job id")
+		return nil, false
+	}
+
+	job, ok := s.jobs[id]
+	if !ok {
+		s.fail(w, http.StatusNotFound, "Invalid job id specified")
+		return nil, false
+	}
+	return job, true
+}
+
+func (s *FakeRestServer) handleDbJobRetrieve(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, "invalid job id")
+		return
+	}
+
+	job, ok := s.jobs[id]
+	if !ok {
+		s.reply(w, map[string]any{"jobs": []restDbJob{}})
+	} else {
+		s.reply(w, map[string]any{"jobs": []restDbJob{job.Job}})
+	}
+}
+
+func (s *FakeRestServer) handleDbJobsBrowse(w http.ResponseWriter, _ *http.Request) {
+	var ids []int
+	for id := range s.jobs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	jobs := []restDbJob{}
+	for _, id := range ids {
+		jobs = append(jobs, s.jobs[id].Job)
+	}
+	s.reply(w, map[string]any{"jobs": jobs})
+}
+
+func (s *FakeRestServer) handleJobSubmit(w http.ResponseWriter, r *http.Request) {
+	var req restJobSubmitRequest
+	if !s.decode(w, r, &req) {
+		return
+	}
+
+	username := r.Header.Get("X-SLURM-USER-NAME")
+	desc := req.Job
+
+	if len(desc.Environment) == 0 {
+		s.fail(w, http.StatusBadRequest, "environment must be set")
+		return
+	}
+
+	if s.findAssociation(desc.Account, username) == -1 {
+		s.fail(w, http.StatusBadRequest, "Invalid account or account/partition combination specified")
+		return
+	}
+
+	nodes := max(1, desc.MinimumNodes)
+	cpus := max(1, desc.CpusPerTask) * nodes
+
+	job := &fakeRestJob{Script: req.Script}
+	job.Job.JobId = s.nextJobId
+	job.Job.Name = desc.Name
+	job.Job.User = username
+	job.Job.Account = desc.Account
+	job.Job.Partition = desc.Partition
+	job.Job.Qos = desc.Qos
+	job.Job.Nodes = "None assigned"
+	job.Job.Comment.Job = desc.Comment
+	job.Job.State.Current = []string{"PENDING"}
+	if desc.TimeLimit != nil {
+		job.Job.Time.Limit = *desc.TimeLimit
+	}
+
+	job.Job.Tres.Allocated = []restTres{
+		{Type: "billing", Count: int64(cpus)},
+		{Type: "cpu", Count: int64(cpus)},
+		{Type: "node", Count: int64(nodes)},
+	}
+
+	if desc.MemoryPerNode != nil {
+		job.Job.Tres.Allocated = append(job.Job.Tres.Allocated,
+			restTres{Type: "mem", Count: desc.MemoryPerNode.Number * int64(nodes)})
+	}
+
+	s.jobs[job.Job.JobId] = job
+	s.nextJobId++
+
+	s.reply(w, restJobSubmitResponse{JobId: job.Job.JobId})
+}
+
+func (s *FakeRestServer) handleCtldJobRetrieve(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	state := job.Job.State.Current
+	if len(state) > 0 && (state[0] == "COMPLETED" || state[0] == "CANCELLED" || state[0] == "FAILED") {
+		s.fail(w, http.StatusNotFound, "Invalid job id specified")
+		return
+	}
+
+	s.reply(w, map[string]any{
+		"jobs": []restCtldJob{{JobId: job.Job.JobId, Comment: job.Job.Comment.Job, TimeLimit: job.Job.Time.Limit}},
+	})
+}
+
+func (s *FakeRestServer) handleCtldJobUpdate(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var desc restJobDescription
+	if !s.decode(w, r, &desc) {
+		return
+	}
+
+	if desc.TimeLimit != nil {
+		job.Job.Time.Limit = *desc.TimeLimit
+	}
+
+	if desc.Hold != nil {
+		state := job.Job.State.Current
+		if *desc.Hold && (len(state) == 0 || state[0] != "PENDING") {
+			s.fail(w, http.StatusBadRequest, "Job is no longer pending execution")
+			return
+		}
+		job.Hold = *desc.Hold
+	}
+
+	s.reply(w, map[string]any{})
+}
+
+func (s *FakeRestServer) handleCtldJobCancel(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	job.Job.State.Current = []string{"CANCELLED"}
+	s.reply(w, map[string]any{})
+}
@@ -0,0 +1,336 @@
+package slurm
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"ucloud.dk/shared/pkg/util"
+)
+
+func newTestRestClient(t *testing.T) (Client, *FakeRestServer) {
+	server := NewFakeRestServer("test-token")
+	t.Cleanup(server.Close)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("test-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewRestClient(server.URL, server.ApiVersion, util.OptValue(tokenFile)), server
+}
+
+func currentUsername(t *testing.T) string {
+	current, err := user.Current()
+	if err != nil {
+		t.Skip("unable to determine current user")
+	}
+	return current.Username
+}
+
+func TestRestAccountLifecycle(t *testing.T) {
+	client, server := newTestRestClient(t)
+
+	account := NewAccount()
+	account.Name = "proj_cpu"
+	account.Description = "Test project"
+	account.QuotaTRES = map[string]int{"billing": 6000}
+	account.RawShares = 4
+
+	if client.AccountExists("proj_cpu") {
+		t.Fatalf("account should not exist yet")
+	}
+
+	if !client.AccountCreate(account) {
+		t.Fatalf("failed to create account")
+	}
+
+	if client.AccountCreate(account) {
+		t.Fatalf("creating the same account twice should fail")
+	}
+
+	if !client.AccountAddUser("alice", "proj_cpu") {
+		t.Fatalf("failed to add user")
+	}
+
+	server.SetBillingUsage("proj_cpu", 1500)
+
+	queried := client.AccountQuery("proj_cpu")
+	if queried == nil {
+		t.Fatalf("failed to query account")
+	}
+
+	if queried.Description != "Test project" {
+		t.Errorf("expected description to be preserved, got %q", queried.Description)
+	}
+	if queried.QuotaTRES["billing"] != 6000 {
+		t.Errorf("expected billing quota of 6000, got %d", queried.QuotaTRES["billing"])
+	}
+	if queried.RawShares != 4 {
+		t.Errorf("expected 4 shares, got %d", queried.RawShares)
+	}
+	if queried.MaxJobs != -1 {
+		t.Errorf("expected no job limit, got %d", queried.MaxJobs)
+	}
+	if queried.UsageTRES["billing"] != 1500 {
+		t.Errorf("expected billing usage of 1500, got %d", queried.UsageTRES["billing"])
+	}
+	if !slices.Contains(queried.Users, "alice") {
+		t.Errorf("expected alice to be a member, got %v", queried.Users)
+	}
+
+	queried.QuotaTRES["billing"] = 12000
+	if !client.AccountModify(queried) {
+		t.Fatalf("failed to modify account")
+	}
+
+	if quota := client.AccountQuery("proj_cpu").QuotaTRES["billing"]; quota != 12000 {
+		t.Errorf("expected billing quota of 12000 after modification, got %d", quota)
+	}
+
+	billing := client.AccountBillingList()
+	if billing["proj_cpu"] != 1500 {
+		t.Errorf("expected billing list to contain usage of 1500, got %v", billing)
+	}
+
+	if !client.AccountRemoveUser("alice", "proj_cpu") {
+		t.Fatalf("failed to remove user")
+	}
+
+	if slices.Contains(client.AccountQuery("proj_cpu").Users, "alice") {
+		t.Errorf("alice should no longer be a member")
+	}
+
+	if !client.AccountDelete("proj_cpu") || client.AccountExists("proj_cpu") {
+		t.Errorf("account should be deleted")
+	}
+}
+
+func TestRestUsers(t *testing.T) {
+	client, _ := newTestRestClient(t)
+
+	for _, name := range []string{"first_cpu", "second_cpu"} {
+		account := NewAccount()
+		account.Name = name
+		if !client.AccountCreate(account) {
+			t.Fatalf("failed to create account %s", name)
+		}
+	}
+
+	if client.UserQuery("bob") != nil {
+		t.Fatalf("user should not exist yet")
+	}
+
+	if !client.UserCreate(&User{Name: "bob", DefaultAccount: "first_cpu"}) {
+		t.Fatalf("failed to create user")
+	}
+
+	if !client.AccountAddUser("bob", "second_cpu") {
+		t.Fatalf("failed to add user to second account")
+	}
+
+	queried := client.UserQuery("bob")
+	if queried == nil {
+		t.Fatalf("failed to query user")
+	}
+
+	if queried.DefaultAccount != "first_cpu" {
+		t.Errorf("unexpected default account %q", queried.DefaultAccount)
+	}
+
+	accounts := client.UserListAccounts("bob")
+	slices.Sort(accounts)
+	if !slices.Equal(accounts, []string{"first_cpu", "second_cpu"}) {
+		t.Errorf("unexpected accounts %v", accounts)
+	}
+
+	if !client.UserDelete("bob") || client.UserExists("bob") {
+		t.Errorf("user should be deleted")
+	}
+}
+
+func TestRestJobs(t *testing.T) {
+	client, server := newTestRestClient(t)
+	username := currentUsername(t)
+
+	account := NewAccount()
+	account.Name = "proj_cpu"
+	if !client.AccountCreate(account) || !client.AccountAddUser(username, "proj_cpu") {
+		t.Fatalf("failed to create account")
+	}
+
+	script := "#!/usr/bin/env -S bash --login\n" +
+		"#SBATCH --account 'proj_cpu'\n" +
+		"#SBATCH --partition 'normal'\n" +
+		"#SBATCH --job-name '4242'\n" +
+		"#SBATCH --cpus-per-task 4\n" +
+		"#SBATCH --mem 4000\n" +
+		"#SBATCH --nodes 2\n" +
+		"#SBATCH --time 01:30:00\n" +
+		"#SBATCH --comment 'ucloud'\n" +
+		"#SBATCH --parsable \n" +
+		"#SBATCH --exclusive\n" +
+		"\n" +
+		"echo 'Hello'\n"
+
+	scriptPath := filepath.Join(t.TempDir(), "job.sh")
+	if err := os.WriteFile(scriptPath, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	jobId, err := client.JobSubmit(scriptPath)
+	if err != nil {
+		t.Fatalf("failed to submit job: %v", err)
+	}
+
+	job, ok := client.JobQuery(jobId)
+	if !ok || job == nil {
+		t.Fatalf("failed to query job")
+	}
+
+	if job.Name != "4242" || job.Account != "proj_cpu" || job.Partition != "normal" || job.State != "PENDING" {
+		t.Errorf("unexpected job %+v", job)
+	}
+	if job.TimeLimit != 90*60 {
+		t.Errorf("expected time limit of 90 minutes, got %d seconds", job.TimeLimit)
+	}
+	if job.AllocTRES["cpu"] != 8 || job.AllocTRES["node"] != 2 || job.AllocTRES["mem"] != 8000*(1<<20) {
+		t.Errorf("unexpected TRES %v", job.AllocTRES)
+	}
+
+	if submitted, _ := server.JobScript(jobId); submitted != script {
+		t.Errorf("expected the script to be submitted unchanged, got %q", submitted)
+	}
+
+	if comment, ok := client.JobComment(jobId); !ok || comment != "ucloud" {
+		t.Errorf("unexpected comment %q", comment)
+	}
+
+	if !client.JobHold(jobId) || !server.JobIsHeld(jobId) {
+		t.Errorf("job should be held")
+	}
+	if !client.JobRelease(jobId) || server.JobIsHeld(jobId) {
+		t.Errorf("job should be released")
+	}
+
+	server.SetJobState(jobId, "RUNNING")
+	server.SetJobNodes(jobId, "node[01-02]")
+
+	if !client.JobExtendTimeLimit(jobId, 30) {
+		t.Fatalf("failed to extend job")
+	}
+
+	job, _ = client.JobQuery(jobId)
+	if job.TimeLimit != 120*60 {
+		t.Errorf("expected time limit of 120 minutes, got %d seconds", job.TimeLimit)
+	}
+
+	if nodes := client.JobGetNodeList(jobId); !slices.Equal(nodes, []string{"node01", "node02"}) {
+		t.Errorf("unexpected node list %v", nodes)
+	}
+
+	jobs := client.JobList()
+	if len(jobs) != 1 || jobs[0].JobID != jobId || jobs[0].State != "RUNNING" {
+		t.Errorf("unexpected job list %+v", jobs)
+	}
+
+	if !client.JobCancel(jobId) {
+		t.Fatalf("failed to cancel job")
+	}
+
+	job, _ = client.JobQuery(jobId)
+	if job.State != "CANCELLED" {
+		t.Errorf("expected job to be cancelled, got %s", job.State)
+	}
+}
+
+func TestRestJobSubmitRejectsUnknownAccount(t *testing.T) {
+	client, _ := newTestRestClient(t)
+
+	scriptPath := filepath.Join(t.TempDir(), "job.sh")
+	script := "#!/bin/bash\n#SBATCH --account 'missing'\necho hi\n"
+	if err := os.WriteFile(scriptPath, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.JobSubmit(scriptPath); err == nil {
+		t.Errorf("expected submission to fail")
+	}
+}
+
+func TestRestRejectsInvalidToken(t *testing.T) {
+	server := NewFakeRestServer("correct")
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("wrong"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewRestClient(server.URL, server.ApiVersion, util.OptValue(tokenFile))
+	account := NewAccount()
+	account.Name = "proj_cpu"
+	if client.AccountCreate(account) {
+		t.Errorf("expected request to be rejected")
+	}
+}
+
+func TestSbatchDirectiveParsing(t *testing.T) {
+	desc, err := restJobDescriptionFromScript("#!/bin/bash\n" +
+		"#SBATCH --chdir '/home/user/it'\"'\"'s here'\n" +
+		"#SBATCH --time=1-00:00:00\n" +
+		"#SBATCH --mem 2G\n" +
+		"#SBATCH --gpus-per-task 1\n" +
+		"echo hi\n" +
+		"#SBATCH --this-is-ignored\n")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if desc.CurrentWorkingDirectory != "/home/user/it's here" {
+		t.Errorf("unexpected working directory %q", desc.CurrentWorkingDirectory)
+	}
+	if desc.TimeLimit == nil || desc.TimeLimit.Number != 24*60 {
+		t.Errorf("unexpected time limit %v", desc.TimeLimit)
+	}
+	if desc.MemoryPerNode == nil || desc.MemoryPerNode.Number != 2048 {
+		t.Errorf("unexpected memory %v", desc.MemoryPerNode)
+	}
+	if desc.TresPerTask != "gres/gpu:1" {
+		t.Errorf("unexpected tres %q", desc.TresPerTask)
+	}
+
+	desc, err = restJobDescriptionFromScript("#!/bin/bash\n" +
+		"#SBATCH --exclusive\n" +
+		"#SBATCH --mail-type=END\n" +
+		"#SBATCH -N 2\n" +
+		"#SBATCH --account 'proj_cpu'\n" +
+		"echo hi\n")
+
+	if err != nil {
+		t.Fatalf("expected unknown directives to be passed through: %v", err)
+	}
+	if desc.Account != "proj_cpu" {
+		t.Errorf("unexpected account %q", desc.Account)
+	}
+
+	_, err = restJobDescriptionFromScript("#SBATCH --nodes many\n")
+	if err == nil {
+		t.Errorf("expected invalid value to be rejected")
+	}
+}
+
+func TestExpandHostList(t *testing.T) {
+	result := expandHostList("node[08-10,12],gpu1,fat[1-2]x")
+	expected := []string{"node08", "node09", "node10", "node12", "gpu1", "fat1x", "fat2x"}
+	if !slices.Equal(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	if len(expandHostList("None assigned")) != 0 {
+		t.Errorf("expected no hosts")
+	}
+}
@@ -0,0 +1,955 @@
+package slurm
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeCluster is an in-memory simulation of a Slurm cluster which implements Client. It keeps track of accounts,
+// users, associations and a job queue and is intended for tests which need to exercise the Slurm integration without
+// any Slurm binaries or daemons.
+//
+// Time does not move on its own. Tests drive the cluster by calling Advance, which schedules pending jobs onto free
+// nodes, progresses running jobs and charges the billing TRES of every running job to its account (and all of its
+// parents). Jobs which reach their time limit end in TIMEOUT, jobs which have been given a duration through
+// SetJobDuration end in COMPLETED once they have run for that long.
+//
+// Job arrays are stored as one job per task. All tasks share the ID of the array and every operation on that ID
+// applies to all of them.
+type FakeCluster struct {
+	// SubmittingUser is the user which JobSubmit runs as. sbatch always submits as the invoking user, and jobs are
+	// only accepted if this user has an association with the requested account.
+	SubmittingUser string
+
+	mu           sync.Mutex
+	nodes        []string
+	nodeOwners   map[string]int
+	accounts     map[string]*Account
+	users        map[string]*User
+	associations map[fakeAssociation]bool
+	jobs         map[int]*fakeJob
+	nextJobId    int
+	usage        map[string]int64 // billing-seconds charged directly to an account
+}
+
+type fakeAssociation struct {
+	Account string
+	User    string
+}
+
+type fakeJob struct {
+	Job      Job
+	RawId    int // Unique for every task of an array, Job.JobID refers to the array
+	MaxTasks int // Maximum number of running tasks in the array, 0 means no limit
+	Depends  []fakeDependency
+	Comment  string
+	Script   string
+	Held     bool
+	Duration time.Duration // 0 means the job runs until it hits its time limit
+	Elapsed  time.Duration
+}
+
+// fakeDependency is a single condition from the dependency directive (e.g. "afterok:1000").
+type fakeDependency struct {
+	Kind  string
+	JobId int
+}
+
+// NewFakeCluster creates an empty cluster with nodeCount nodes. The submitting user defaults to the user running the
+// process.
+func NewFakeCluster(nodeCount int) *FakeCluster {
+	c := &FakeCluster{
+		nodeOwners:   map[string]int{},
+		accounts:     map[string]*Account{},
+		users:        map[string]*User{},
+		associations: map[fakeAssociation]bool{},
+		jobs:         map[int]*fakeJob{},
+		nextJobId:    1000,
+		usage:        map[string]int64{},
+	}
+
+	for i := 1; i <= nodeCount; i++ {
+		c.nodes = append(c.nodes, fmt.Sprintf("node%02d", i))
+	}
+
+	if current, err := user.Current(); err == nil {
+		c.SubmittingUser = current.Username
+	}
+
+	return c
+}
+
+// Simulation
+// =====================================================================================================================
+
+// Advance moves the clock of the cluster forward by d.
+func (c *FakeCluster) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.schedule()
+
+	for _, id := range c.sortedJobIds() {
+		job := c.jobs[id]
+		if job.Job.State != "RUNNING" {
+			continue
+		}
+
+		step := d
+		remaining := job.remaining()
+		if remaining > 0 && remaining < step {
+			step = remaining
+		}
+
+		job.Elapsed += step
+		job.Job.Elapsed = int(job.Elapsed / time.Second)
+		c.usage[job.Job.Account] += int64(job.Job.AllocTRES["billing"]) * int64(step/time.Second)
+
+		if job.Duration > 0 && job.Elapsed >= job.Duration {
+			c.finish(job, "COMPLETED")
+		} else if job.Job.TimeLimit > 0 && job.Elapsed >= time.Duration(job.Job.TimeLimit)*time.Second {
+			c.finish(job, "TIMEOUT")
+		}
+	}
+
+	// Nodes freed up by jobs which just finished are immediately given to the next jobs in the queue
+	c.schedule()
+}
+
+// SetJobDuration sets how long a job will run before it completes successfully.
+func (c *FakeCluster) SetJobDuration(id int, d time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	jobs := c.lookup(id)
+	for _, job := range jobs {
+		job.Duration = d
+	}
+	return len(jobs) > 0
+}
+
+// SetJobState forces a job into a specific state. Nodes are released if the state is a final state.
+func (c *FakeCluster) SetJobState(id int, state string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	jobs := c.lookup(id)
+	for _, job := range jobs {
+		if fakeStateIsFinal(state) {
+			c.finish(job, state)
+		} else {
+			job.Job.State = state
+		}
+	}
+	return len(jobs) > 0
+}
+
+// SetBillingUsage overrides the billing usage (in billing-minutes) charged directly to an account.
+func (c *FakeCluster) SetBillingUsage(account string, minutes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.usage[account] = minutes * 60
+}
+
+// JobScript returns the script which was submitted for a job.
+func (c *FakeCluster) JobScript(id int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	jobs := c.lookup(id)
+	if len(jobs) == 0 {
+		return "", false
+	}
+	return jobs[0].Script, true
+}
+
+// JobIsHeld returns true if a job is currently held.
+func (c *FakeCluster) JobIsHeld(id int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	jobs := c.lookup(id)
+	return len(jobs) > 0 && jobs[0].Held
+}
+
+func (j *fakeJob) remaining() time.Duration {
+	var result time.Duration
+	if j.Duration > 0 {
+		result = j.Duration - j.Elapsed
+	}
+
+	if j.Job.TimeLimit > 0 {
+		untilLimit := time.Duration(j.Job.TimeLimit)*time.Second - j.Elapsed
+		if result == 0 || untilLimit < result {
+			result = untilLimit
+		}
+	}
+	return result
+}
+
+func fakeStateIsFinal(state string) bool {
+	switch state {
+	case "PENDING", "RUNNING", "SUSPENDED", "REQUEUE_HOLD", "CONFIGURING", "COMPLETING":
+		return false
+	default:
+		return true
+	}
+}
+
+// dependenciesSatisfied checks the dependencies of a job. Like Slurm, a job whose dependencies can never be satisfied
+// remains pending.
+func (c *FakeCluster) dependenciesSatisfied(job *fakeJob) bool {
+	for _, dep := range job.Depends {
+		for _, upstream := range c.lookup(dep.JobId) {
+			if !fakeStateIsFinal(upstream.Job.State) {
+				return false
+			}
+
+			if dep.Kind == "afterok" && upstream.Job.State != "COMPLETED" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c *FakeCluster) sortedJobIds() []int {
+	return slices.Sorted(maps.Keys(c.jobs))
+}
+
+// lookup returns the job with the given ID. For job arrays, all tasks of the array are returned.
+func (c *FakeCluster) lookup(id int) []*fakeJob {
+	var result []*fakeJob
+	for _, rawId := range c.sortedJobIds() {
+		job := c.jobs[rawId]
+		if job.Job.JobID == id {
+			result = append(result, job)
+		}
+	}
+	return result
+}
+
+func (c *FakeCluster) finish(job *fakeJob, state string) {
+	job.Job.State = state
+	c.releaseNodes(job.RawId)
+}
+
+func (c *FakeCluster) releaseNodes(id int) {
+	for node, owner := range c.nodeOwners {
+		if owner == id {
+			delete(c.nodeOwners, node)
+		}
+	}
+}
+
+// schedule starts pending jobs in order of submission as long as there are enough free nodes and the limits of the
+// account allow it. Like Slurm, a job which cannot start does not block smaller jobs behind it (backfill).
+func (c *FakeCluster) schedule() {
+	for _, id := range c.sortedJobIds() {
+		job := c.jobs[id]
+		if job.Job.State != "PENDING" || job.Held {
+			continue
+		}
+
+		if !c.accountAllowsStart(job.Job.Account) {
+			continue
+		}
+
+		if !c.dependenciesSatisfied(job) {
+			continue
+		}
+
+		if job.MaxTasks > 0 {
+			running := 0
+			for _, other := range c.jobs {
+				if other.Job.JobID == job.Job.JobID && other.Job.State == "RUNNING" {
+					running++
+				}
+			}
+
+			if running >= job.MaxTasks {
+				continue
+			}
+		}
+
+		nodeCount := max(1, job.Job.AllocTRES["node"])
+		var free []string
+		for _, node := range c.nodes {
+			if _, taken := c.nodeOwners[node]; !taken {
+				free = append(free, node)
+			}
+		}
+
+		if len(free) < nodeCount {
+			continue
+		}
+
+		for _, node := range free[:nodeCount] {
+			c.nodeOwners[node] = id
+		}
+
+		job.Job.State = "RUNNING"
+		job.Job.NodeList = compressHostList(free[:nodeCount])
+	}
+}
+
+// accountAllowsStart checks the GrpTRESMins and MaxJobs limits of an account and all of its parents.
+func (c *FakeCluster) accountAllowsStart(name string) bool {
+	for name != "" {
+		account, ok := c.accounts[name]
+		if !ok {
+			return false
+		}
+
+		if quota, hasQuota := account.QuotaTRES["billing"]; hasQuota && quota >= 0 {
+			if c.billingMinutes(name) >= int64(quota) {
+				return false
+			}
+		}
+
+		if account.MaxJobs > 0 {
+			running := 0
+			for _, job := range c.jobs {
+				if job.Job.Account == name && job.Job.State == "RUNNING" {
+					running++
+				}
+			}
+
+			if running >= account.MaxJobs {
+				return false
+			}
+		}
+
+		name = account.Parent.GetOrDefault("")
+	}
+	return true
+}
+
+// billingMinutes returns the usage of an account including the usage of all its sub-accounts, matching GrpTRESRaw.
+func (c *FakeCluster) billingMinutes(name string) int64 {
+	return c.billingSeconds(name) / 60
+}
+
+func (c *FakeCluster) billingSeconds(name string) int64 {
+	seconds := c.usage[name]
+	for child, account := range c.accounts {
+		if account.Parent.GetOrDefault("") == name && child != name {
+			seconds += c.billingSeconds(child)
+		}
+	}
+	return seconds
+}
+
+func compressHostList(nodes []string) string {
+	// The node names are all of the form nodeXX which allows for a simple range when the nodes are contiguous
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+
+	var first, last int
+	_, err1 := fmt.Sscanf(nodes[0], "node%d", &first)
+	_, err2 := fmt.Sscanf(nodes[len(nodes)-1], "node%d", &last)
+	if err1 == nil && err2 == nil && last-first == len(nodes)-1 {
+		return fmt.Sprintf("node[%02d-%02d]", first, last)
+	}
+
+	result := ""
+	for i, node := range nodes {
+		if i > 0 {
+			result += ","
+		}
+		result += node
+	}
+	return result
+}
+
+// Accounts
+// =====================================================================================================================
+
+func (c *FakeCluster) AccountExists(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.accounts[name]
+	return ok
+}
+
+func (c *FakeCluster) AccountQuery(name string) *Account {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored, ok := c.accounts[name]
+	if !ok {
+		return nil
+	}
+
+	result := *stored
+	result.QoS = slices.Clone(stored.QoS)
+	result.QuotaTRES = maps.Clone(stored.QuotaTRES)
+	result.Users = nil
+	for assoc := range c.associations {
+		if assoc.Account == name {
+			result.Users = append(result.Users, assoc.User)
+		}
+	}
+	slices.Sort(result.Users)
+
+	usage := c.billingMinutes(name)
+	result.RawUsage = int(c.billingSeconds(name))
+	result.UsageTRES = map[string]int{"billing": int(usage)}
+	return &result
+}
+
+func (c *FakeCluster) AccountCreate(a *Account) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if a == nil || !validateName(a.Name) {
+		return false
+	}
+
+	if _, exists := c.accounts[a.Name]; exists {
+		return false
+	}
+
+	if a.Parent.Present {
+		if _, parentExists := c.accounts[a.Parent.Value]; !parentExists {
+			return false
+		}
+	}
+
+	c.storeAccount(a)
+	return true
+}
+
+func (c *FakeCluster) AccountModify(a *Account) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if a == nil {
+		return false
+	}
+
+	if _, exists := c.accounts[a.Name]; !exists {
+		return false
+	}
+
+	c.storeAccount(a)
+	return true
+}
+
+func (c *FakeCluster) storeAccount(a *Account) {
+	stored := *a
+	stored.QoS = slices.Clone(a.QoS)
+	stored.QuotaTRES = maps.Clone(a.QuotaTRES)
+	stored.Users = nil
+	stored.UsageTRES = nil
+	stored.RawUsage = 0
+	c.accounts[a.Name] = &stored
+}
+
+func (c *FakeCluster) AccountDelete(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.accounts[name]; !exists {
+		return false
+	}
+
+	for _, job := range c.jobs {
+		if job.Job.Account == name && !fakeStateIsFinal(job.Job.State) {
+			// sacctmgr refuses to delete accounts with active jobs
+			return false
+		}
+	}
+
+	for _, account := range c.accounts {
+		if account.Parent.GetOrDefault("") == name {
+			return false
+		}
+	}
+
+	for assoc := range c.associations {
+		if assoc.Account == name {
+			delete(c.associations, assoc)
+		}
+	}
+
+	delete(c.accounts, name)
+	delete(c.usage, name)
+	return true
+}
+
+func (c *FakeCluster) AccountAddUser(user, account string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if user == "" || account == "" {
+		return false
+	}
+
+	if _, exists := c.accounts[account]; !exists {
+		return false
+	}
+
+	// Like sacctmgr, adding a user to an account implicitly creates the user
+	if _, exists := c.users[user]; !exists {
+		c.users[user] = &User{Name: user, DefaultAccount: account, Privilege: "None"}
+	}
+
+	c.associations[fakeAssociation{Account: account, User: user}] = true
+	return true
+}
+
+func (c *FakeCluster) AccountRemoveUser(user, account string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	assoc := fakeAssociation{Account: account, User: user}
+	if !c.associations[assoc] {
+		return false
+	}
+
+	delete(c.associations, assoc)
+	return true
+}
+
+func (c *FakeCluster) AccountBillingList() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := map[string]int64{}
+	for name := range c.accounts {
+		result[name] = c.billingMinutes(name)
+	}
+	return result
+}
+
+// Users
+// =====================================================================================================================
+
+func (c *FakeCluster) UserExists(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.users[name]
+	return ok
+}
+
+func (c *FakeCluster) UserQuery(name string) *User {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored, ok := c.users[name]
+	if !ok {
+		return nil
+	}
+
+	result := *stored
+	result.Accounts = c.userAccounts(name)
+	return &result
+}
+
+func (c *FakeCluster) UserCreate(u *User) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if u == nil || !validateName(u.Name) || u.DefaultAccount == "" {
+		return false
+	}
+
+	if _, exists := c.users[u.Name]; exists {
+		return false
+	}
+
+	accounts := append([]string{u.DefaultAccount}, u.Accounts...)
+	for _, account := range accounts {
+		if _, exists := c.accounts[account]; !exists {
+			return false
+		}
+	}
+
+	stored := *u
+	stored.Accounts = nil
+	c.users[u.Name] = &stored
+
+	for _, account := range accounts {
+		c.associations[fakeAssociation{Account: account, User: u.Name}] = true
+	}
+	return true
+}
+
+func (c *FakeCluster) UserModify(u *User) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if u == nil {
+		return false
+	}
+
+	stored, exists := c.users[u.Name]
+	if !exists {
+		return false
+	}
+
+	if u.DefaultAccount != "" {
+		if !c.associations[fakeAssociation{Account: u.DefaultAccount, User: u.Name}] {
+			return false
+		}
+		stored.DefaultAccount = u.DefaultAccount
+	}
+
+	if u.Privilege != "" {
+		stored.Privilege = u.Privilege
+	}
+	return true
+}
+
+func (c *FakeCluster) UserDelete(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.users[name]; !exists {
+		return false
+	}
+
+	for assoc := range c.associations {
+		if assoc.User == name {
+			delete(c.associations, assoc)
+		}
+	}
+
+	delete(c.users, name)
+	return true
+}
+
+func (c *FakeCluster) UserListAccounts(user string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.userAccounts(user)
+}
+
+func (c *FakeCluster) userAccounts(user string) []string {
+	var result []string
+	for assoc := range c.associations {
+		if assoc.User == user {
+			result = append(result, assoc.Account)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+// Jobs
+// =====================================================================================================================
+
+func (c *FakeCluster) JobQuery(id int) (*Job, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	jobs := c.lookup(id)
+	if len(jobs) == 0 {
+		return nil, true
+	}
+
+	result := jobs[0].Job
+	result.AllocTRES = maps.Clone(jobs[0].Job.AllocTRES)
+	return &result, true
+}
+
+func (c *FakeCluster) JobList() []Job {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var result []Job
+	for _, id := range c.sortedJobIds() {
+		job := c.jobs[id].Job
+		job.AllocTRES = maps.Clone(job.AllocTRES)
+		result = append(result, job)
+	}
+	return result
+}
+
+func (c *FakeCluster) JobSubmit(pathToScript string) (int, error) {
+	data, err := os.ReadFile(pathToScript)
+	if err != nil {
+		return -1, jobSubmitError(fmt.Sprintf("Unable to open file %s", pathToScript))
+	}
+
+	desc, err := restJobDescriptionFromScript(string(data))
+	if err != nil {
+		return -1, jobSubmitError(err.Error())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	account := desc.Account
+	if account == "" {
+		if u, ok := c.users[c.SubmittingUser]; ok {
+			account = u.DefaultAccount
+		}
+	}
+
+	if !c.associations[fakeAssociation{Account: account, User: c.SubmittingUser}] {
+		return -1, jobSubmitError("Invalid account or account/partition combination specified")
+	}
+
+	if stored := c.accounts[account]; len(stored.QoS) > 0 && desc.Qos != "" && !slices.Contains(stored.QoS, desc.Qos) {
+		return -1, jobSubmitError("Invalid qos specification")
+	}
+
+	nodes := max(1, desc.MinimumNodes)
+	if nodes > len(c.nodes) {
+		return -1, jobSubmitError("Node count specification invalid")
+	}
+
+	if stored := c.accounts[account]; stored.MaxSubmitJobs > 0 {
+		submitted := 0
+		for _, job := range c.jobs {
+			if job.Job.Account == account && !fakeStateIsFinal(job.Job.State) {
+				submitted++
+			}
+		}
+
+		if submitted >= stored.MaxSubmitJobs {
+			return -1, jobSubmitError("Job violates accounting/QOS policy (job submit limit, user's size and/or time limits)")
+		}
+	}
+
+	tasks, maxTasks, ok := parseFakeArray(desc.Array)
+	if !ok {
+		return -1, jobSubmitError("Invalid job array specification")
+	}
+
+	depends, ok := parseFakeDependencies(desc.Dependency)
+	if !ok {
+		return -1, jobSubmitError("Job dependency problem")
+	}
+	for _, dep := range depends {
+		if len(c.lookup(dep.JobId)) == 0 {
+			return -1, jobSubmitError("Job dependency problem")
+		}
+	}
+
+	cpus := max(1, desc.CpusPerTask) * nodes
+	arrayId := c.nextJobId
+
+	for _, task := range tasks {
+		job := &fakeJob{Script: string(data), Comment: desc.Comment, RawId: c.nextJobId, MaxTasks: maxTasks, Depends: depends}
+		job.Job = Job{
+			JobID:     arrayId,
+			Name:      desc.Name,
+			User:      c.SubmittingUser,
+			Account:   account,
+			Partition: desc.Partition,
+			State:     "PENDING",
+			QoS:       desc.Qos,
+			NodeList:  "None assigned",
+			AllocTRES: map[string]int{
+				"billing": cpus,
+				"cpu":     cpus,
+				"node":    nodes,
+			},
+		}
+
+		if task >= 0 {
+			job.Job.ArrayTask = fmt.Sprint(task)
+		}
+
+		if desc.TimeLimit != nil {
+			job.Job.TimeLimit = int(desc.TimeLimit.Number) * 60
+		}
+
+		if desc.MemoryPerNode != nil {
+			job.Job.AllocTRES["mem"] = int(desc.MemoryPerNode.Number) * nodes * (1 << 20)
+		}
+
+		c.jobs[job.RawId] = job
+		c.nextJobId++
+	}
+
+	return arrayId, nil
+}
+
+// parseFakeArray parses the value of the array directive (e.g. "0-9%2"). Jobs which are not arrays consist of a
+// single task with ID -1.
+func parseFakeArray(spec string) (tasks []int, maxTasks int, ok bool) {
+	if spec == "" {
+		return []int{-1}, 0, true
+	}
+
+	rangeSpec, limit, hasLimit := strings.Cut(spec, "%")
+	if hasLimit {
+		var err error
+		maxTasks, err = strconv.Atoi(limit)
+		if err != nil || maxTasks <= 0 {
+			return nil, 0, false
+		}
+	}
+
+	probe := Job{ArrayTask: rangeSpec}
+	tasks = probe.ArrayTaskIds()
+	return tasks, maxTasks, len(tasks) > 0
+}
+
+// parseFakeDependencies parses the value of the dependency directive (e.g. "afterok:1000:1001,afterany:1002"). Only
+// the afterok and afterany conditions are supported.
+func parseFakeDependencies(spec string) ([]fakeDependency, bool) {
+	var result []fakeDependency
+	if spec == "" {
+		return result, true
+	}
+
+	for _, condition := range strings.Split(spec, ",") {
+		parts := strings.Split(condition, ":")
+		if len(parts) < 2 || (parts[0] != "afterok" && parts[0] != "afterany") {
+			return nil, false
+		}
+
+		for _, rawId := range parts[1:] {
+			id, err := strconv.Atoi(rawId)
+			if err != nil {
+				return nil, false
+			}
+			result = append(result, fakeDependency{Kind: parts[0], JobId: id})
+		}
+	}
+	return result, true
+}
+
+func (c *FakeCluster) JobComment(jobId int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	jobs := c.lookup(jobId)
+	if len(jobs) == 0 {
+		return "", false
+	}
+	return jobs[0].Comment, true
+}
+
+func (c *FakeCluster) JobCancel(id int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := false
+	for _, job := range c.lookup(id) {
+		if !fakeStateIsFinal(job.Job.State) {
+			c.finish(job, "CANCELLED")
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (c *FakeCluster) JobExtendTimeLimit(id int, minutes int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := false
+	for _, job := range c.lookup(id) {
+		if !fakeStateIsFinal(job.Job.State) && job.Job.TimeLimit > 0 {
+			job.Job.TimeLimit += minutes * 60
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (c *FakeCluster) JobSuspend(id int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Suspended jobs keep their nodes but do not accumulate time or usage
+	changed := false
+	for _, job := range c.lookup(id) {
+		if job.Job.State == "RUNNING" {
+			job.Job.State = "SUSPENDED"
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (c *FakeCluster) JobResume(id int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := false
+	for _, job := range c.lookup(id) {
+		if job.Job.State == "SUSPENDED" {
+			job.Job.State = "RUNNING"
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (c *FakeCluster) JobHold(id int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := false
+	for _, job := range c.lookup(id) {
+		if job.Job.State == "PENDING" {
+			job.Held = true
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (c *FakeCluster) JobRequeueHold(id int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := false
+	for _, job := range c.lookup(id) {
+		if job.Job.State != "RUNNING" && job.Job.State != "SUSPENDED" {
+			continue
+		}
+
+		// A requeued job starts from scratch once it is released
+		c.releaseNodes(job.RawId)
+		job.Job.State = "PENDING"
+		job.Job.NodeList = "None assigned"
+		job.Job.Elapsed = 0
+		job.Elapsed = 0
+		job.Held = true
+		changed = true
+	}
+	return changed
+}
+
+func (c *FakeCluster) JobRelease(id int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := false
+	for _, job := range c.lookup(id) {
+		if job.Held {
+			job.Held = false
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (c *FakeCluster) JobGetNodeList(id int) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var result []string
+	for _, job := range c.lookup(id) {
+		result = append(result, expandHostList(job.Job.NodeList)...)
+	}
+	return result
+}
@@ -0,0 +1,283 @@
+package slurm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"ucloud.dk/shared/pkg/util"
+)
+
+func newFakeClusterWithAccount(t *testing.T, nodes int, account string) *FakeCluster {
+	cluster := NewFakeCluster(nodes)
+	cluster.SubmittingUser = "alice"
+
+	a := NewAccount()
+	a.Name = account
+	if !cluster.AccountCreate(a) || !cluster.AccountAddUser("alice", account) {
+		t.Fatalf("failed to create account %s", account)
+	}
+	return cluster
+}
+
+func submitFakeJob(t *testing.T, client Client, account string, nodes int, cpus int, limit string) int {
+	script := "#!/usr/bin/env -S bash --login\n" +
+		"#SBATCH --account '" + account + "'\n" +
+		"#SBATCH --partition 'normal'\n" +
+		"#SBATCH --cpus-per-task " + fmt.Sprint(cpus) + "\n" +
+		"#SBATCH --nodes " + fmt.Sprint(nodes) + "\n" +
+		"#SBATCH --time " + limit + "\n" +
+		"#SBATCH --comment 'UCloud job'\n" +
+		"\n" +
+		"echo 'Hello'\n"
+
+	scriptPath := filepath.Join(t.TempDir(), "job.sh")
+	if err := os.WriteFile(scriptPath, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	jobId, err := client.JobSubmit(scriptPath)
+	if err != nil {
+		t.Fatalf("failed to submit job: %v", err)
+	}
+	return jobId
+}
+
+func fakeJobState(t *testing.T, client Client, id int) string {
+	job, ok := client.JobQuery(id)
+	if !ok || job == nil {
+		t.Fatalf("unknown job %d", id)
+	}
+	return job.State
+}
+
+func TestFakeClusterJobLifecycle(t *testing.T) {
+	cluster := newFakeClusterWithAccount(t, 2, "proj_cpu")
+
+	first := submitFakeJob(t, cluster, "proj_cpu", 2, 4, "00:10:00")
+	second := submitFakeJob(t, cluster, "proj_cpu", 1, 4, "00:10:00")
+	cluster.SetJobDuration(first, 5*time.Minute)
+
+	if fakeJobState(t, cluster, first) != "PENDING" {
+		t.Fatalf("jobs should be pending until time advances")
+	}
+
+	cluster.Advance(time.Minute)
+	if fakeJobState(t, cluster, first) != "RUNNING" || fakeJobState(t, cluster, second) != "PENDING" {
+		t.Fatalf("only the first job should fit on the cluster")
+	}
+
+	if nodes := cluster.JobGetNodeList(first); !slices.Equal(nodes, []string{"node01", "node02"}) {
+		t.Errorf("unexpected nodes %v", nodes)
+	}
+
+	cluster.Advance(10 * time.Minute)
+	if fakeJobState(t, cluster, first) != "COMPLETED" {
+		t.Errorf("first job should have completed")
+	}
+	if fakeJobState(t, cluster, second) != "RUNNING" {
+		t.Errorf("second job should have started once nodes were freed")
+	}
+
+	cluster.Advance(time.Hour)
+	if fakeJobState(t, cluster, second) != "TIMEOUT" {
+		t.Errorf("second job should have hit its time limit")
+	}
+
+	job, _ := cluster.JobQuery(second)
+	if job.Elapsed != 10*60 {
+		t.Errorf("expected the second job to run for exactly its time limit, got %d seconds", job.Elapsed)
+	}
+
+	// 8 cpus for 5 minutes + 4 cpus for 10 minutes
+	if usage := cluster.AccountBillingList()["proj_cpu"]; usage != 8*5+4*10 {
+		t.Errorf("unexpected billing usage %d", usage)
+	}
+}
+
+func TestFakeClusterQuota(t *testing.T) {
+	cluster := newFakeClusterWithAccount(t, 4, "parent_cpu")
+
+	child := NewAccount()
+	child.Name = "child_cpu"
+	child.Parent = util.OptValue("parent_cpu")
+	if !cluster.AccountCreate(child) || !cluster.AccountAddUser("alice", "child_cpu") {
+		t.Fatalf("failed to create child account")
+	}
+
+	parent := cluster.AccountQuery("parent_cpu")
+	parent.QuotaTRES = map[string]int{"billing": 60}
+	if !cluster.AccountModify(parent) {
+		t.Fatalf("failed to set quota")
+	}
+
+	first := submitFakeJob(t, cluster, "child_cpu", 1, 2, "02:00:00")
+	cluster.SetJobDuration(first, 30*time.Minute)
+	cluster.Advance(time.Hour)
+
+	if usage := cluster.AccountBillingList(); usage["child_cpu"] != 60 || usage["parent_cpu"] != 60 {
+		t.Errorf("usage should be rolled up to the parent, got %v", usage)
+	}
+
+	if queried := cluster.AccountQuery("parent_cpu"); queried.UsageTRES["billing"] != 60 {
+		t.Errorf("unexpected usage in account query %v", queried.UsageTRES)
+	}
+
+	second := submitFakeJob(t, cluster, "child_cpu", 1, 2, "02:00:00")
+	cluster.Advance(time.Hour)
+	if fakeJobState(t, cluster, second) != "PENDING" {
+		t.Errorf("job should not start when the quota of a parent account is exhausted")
+	}
+
+	parent.QuotaTRES["billing"] = 1000
+	cluster.AccountModify(parent)
+	cluster.Advance(time.Minute)
+	if fakeJobState(t, cluster, second) != "RUNNING" {
+		t.Errorf("job should start once the quota is raised")
+	}
+}
+
+func TestFakeClusterAssociations(t *testing.T) {
+	cluster := newFakeClusterWithAccount(t, 1, "proj_cpu")
+
+	other := NewAccount()
+	other.Name = "other_cpu"
+	if !cluster.AccountCreate(other) {
+		t.Fatalf("failed to create account")
+	}
+
+	scriptPath := filepath.Join(t.TempDir(), "job.sh")
+	if err := os.WriteFile(scriptPath, []byte("#!/bin/bash\n#SBATCH --account 'other_cpu'\necho hi\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cluster.JobSubmit(scriptPath); err == nil {
+		t.Errorf("submission to an account without an association should fail")
+	}
+
+	if accounts := cluster.UserListAccounts("alice"); !slices.Equal(accounts, []string{"proj_cpu"}) {
+		t.Errorf("unexpected accounts %v", accounts)
+	}
+
+	if !cluster.AccountAddUser("alice", "other_cpu") {
+		t.Fatalf("failed to add user")
+	}
+
+	if _, err := cluster.JobSubmit(scriptPath); err != nil {
+		t.Errorf("submission should succeed after adding the association: %v", err)
+	}
+
+	if cluster.AccountDelete("other_cpu") {
+		t.Errorf("accounts with active jobs cannot be deleted")
+	}
+
+	if !cluster.AccountRemoveUser("alice", "proj_cpu") {
+		t.Fatalf("failed to remove user")
+	}
+
+	if u := cluster.UserQuery("alice"); u == nil || !slices.Equal(u.Accounts, []string{"other_cpu"}) {
+		t.Errorf("unexpected user %+v", u)
+	}
+}
+
+func TestFakeClusterSuspension(t *testing.T) {
+	cluster := newFakeClusterWithAccount(t, 1, "proj_cpu")
+
+	jobId := submitFakeJob(t, cluster, "proj_cpu", 1, 1, "01:00:00")
+	cluster.Advance(10 * time.Minute)
+
+	if !cluster.JobSuspend(jobId) || fakeJobState(t, cluster, jobId) != "SUSPENDED" {
+		t.Fatalf("job should be suspended")
+	}
+
+	cluster.Advance(time.Hour)
+	if job, _ := cluster.JobQuery(jobId); job.Elapsed != 10*60 {
+		t.Errorf("suspended jobs should not accumulate time, got %d", job.Elapsed)
+	}
+
+	if !cluster.JobResume(jobId) || !cluster.JobExtendTimeLimit(jobId, 30) {
+		t.Fatalf("failed to resume and extend job")
+	}
+
+	if !cluster.JobRequeueHold(jobId) || !cluster.JobIsHeld(jobId) {
+		t.Fatalf("job should be requeued and held")
+	}
+
+	cluster.Advance(time.Minute)
+	if fakeJobState(t, cluster, jobId) != "PENDING" {
+		t.Errorf("held jobs should not start")
+	}
+
+	cluster.JobRelease(jobId)
+	cluster.Advance(time.Minute)
+	if fakeJobState(t, cluster, jobId) != "RUNNING" {
+		t.Errorf("released job should start")
+	}
+
+	if !cluster.JobCancel(jobId) || cluster.JobCancel(jobId) {
+		t.Errorf("job should only be cancelled once")
+	}
+}
+
+func TestFakeClusterDependencies(t *testing.T) {
+	cluster := newFakeClusterWithAccount(t, 4, "proj_cpu")
+
+	upstream := submitFakeJob(t, cluster, "proj_cpu", 1, 4, "00:10:00")
+	failing := submitFakeJob(t, cluster, "proj_cpu", 1, 4, "00:10:00")
+	cluster.SetJobDuration(upstream, 5*time.Minute)
+	cluster.SetJobDuration(failing, 5*time.Minute)
+
+	submitDependent := func(dependency string) int {
+		script := "#!/usr/bin/env -S bash --login\n" +
+			"#SBATCH --account 'proj_cpu'\n" +
+			"#SBATCH --partition 'normal'\n" +
+			"#SBATCH --time 01:00:00\n" +
+			"#SBATCH --dependency '" + dependency + "'\n" +
+			"\n" +
+			"echo 'Hello'\n"
+
+		scriptPath := filepath.Join(t.TempDir(), "job.sh")
+		if err := os.WriteFile(scriptPath, []byte(script), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		jobId, err := cluster.JobSubmit(scriptPath)
+		if err != nil {
+			t.Fatalf("failed to submit job: %v", err)
+		}
+		return jobId
+	}
+
+	afterOk := submitDependent(fmt.Sprintf("afterok:%d", upstream))
+	afterAny := submitDependent(fmt.Sprintf("afterany:%d", failing))
+	never := submitDependent(fmt.Sprintf("afterok:%d:%d", upstream, failing))
+
+	cluster.Advance(time.Minute)
+	if fakeJobState(t, cluster, afterOk) != "PENDING" || fakeJobState(t, cluster, afterAny) != "PENDING" {
+		t.Fatalf("dependent jobs should wait for their upstream jobs")
+	}
+
+	cluster.SetJobState(failing, "FAILED")
+	cluster.Advance(10 * time.Minute)
+	cluster.Advance(time.Minute)
+
+	if fakeJobState(t, cluster, afterOk) != "RUNNING" {
+		t.Errorf("afterok job should start once the upstream job completed")
+	}
+	if fakeJobState(t, cluster, afterAny) != "RUNNING" {
+		t.Errorf("afterany job should start once the upstream job failed")
+	}
+	if fakeJobState(t, cluster, never) != "PENDING" {
+		t.Errorf("afterok job should remain pending when an upstream job failed")
+	}
+
+	script := "#!/usr/bin/env -S bash --login\n#SBATCH --account 'proj_cpu'\n#SBATCH --dependency 'afterok:1'\n"
+	scriptPath := filepath.Join(t.TempDir(), "job.sh")
+	_ = os.WriteFile(scriptPath, []byte(script), 0600)
+	if _, err := cluster.JobSubmit(scriptPath); err == nil {
+		t.Errorf("expected dependencies on unknown jobs to be rejected")
+	}
+}
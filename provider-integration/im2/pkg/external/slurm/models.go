@@ -1,6 +1,9 @@
 package slurm
 
 import (
+	"strconv"
+	"strings"
+
 	"ucloud.dk/shared/pkg/util"
 )
 
@@ -39,6 +42,59 @@ type Job struct {
 	AllocTRES map[string]int `slurm:"AllocTRES"`
 	QoS       string         `slurm:"QOS"`
 	NodeList  string         `slurm:"NodeList"`
+
+	// ArrayTask is set for tasks of a job array, in which case JobID refers to the array itself. Tasks which are
+	// still pending might be grouped, giving a range such as "[5-9%2]" instead of a single task ID.
+	ArrayTask string
+}
+
+// parseSlurmField splits job IDs of array tasks (e.g. "123_4") into the ID of the array and the task.
+func (j *Job) parseSlurmField(name string, value string) bool {
+	if name != "JobID" {
+		return false
+	}
+
+	arrayId, task, isArray := strings.Cut(value, "_")
+	if !isArray {
+		return false
+	}
+
+	j.JobID, _ = strconv.Atoi(arrayId)
+	j.ArrayTask = task
+	return true
+}
+
+// ArrayTaskIds returns the IDs of the array tasks represented by this job. Jobs which are not part of an array
+// return nil.
+func (j *Job) ArrayTaskIds() []int {
+	if j.ArrayTask == "" {
+		return nil
+	}
+
+	spec := strings.Trim(j.ArrayTask, "[]")
+	spec, _, _ = strings.Cut(spec, "%")
+
+	var result []int
+	for _, part := range strings.Split(spec, ",") {
+		start, end, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(start)
+		if err != nil {
+			continue
+		}
+
+		last := first
+		if isRange {
+			last, err = strconv.Atoi(end)
+			if err != nil {
+				continue
+			}
+		}
+
+		for id := first; id <= last; id++ {
+			result = append(result, id)
+		}
+	}
+	return result
 }
 
 func NewAccount() *Account {
@@ -11,6 +11,11 @@ import (
 	"ucloud.dk/shared/pkg/util"
 )
 
+// slurmFieldParser is implemented by models which need custom parsing of some fields.
+type slurmFieldParser interface {
+	parseSlurmField(name string, value string) bool
+}
+
 type Tag struct {
 	index     int
 	multiline bool
@@ -87,6 +92,10 @@ func unmarshalWithPredefinedHeader(lines []string, tagMap map[string]Tag, header
 				continue // Unless multiline, ignore all but first line
 			}
 
+			if parser, ok := output.(slurmFieldParser); ok && parser.parseSlurmField(headerMap[k], v) {
+				continue
+			}
+
 			s := []string{}
 			if strings.ContainsAny(v, ",=") {
 				s = strings.Split(v, ",")
@@ -55,3 +55,36 @@ func TestElementParsing(t *testing.T) {
 		t.Errorf("Expected 1024, got %d", result.AllocTRES["cpu"])
 	}
 }
+
+func TestJobArrayTaskParsing(t *testing.T) {
+	cliOutput := `JobID|State|User|Account|JobName|Partition|Elapsed|Timelimit|AllocTRES
+124000_0|COMPLETED|user|uniuser|sweep|fat|00:10:00|01:00:00|billing=128,cpu=128,mem=1000G,node=1
+124000_1|RUNNING|user|uniuser|sweep|fat|00:05:00|01:00:00|billing=128,cpu=128,mem=1000G,node=1
+124000_[2-4,7%2]|PENDING|user|uniuser|sweep|fat|00:00:00|01:00:00|
+124010|PENDING|user|uniuser|single|fat|00:00:00|01:00:00|`
+
+	var result []Job
+	unmarshal(cliOutput, &result)
+
+	if len(result) != 4 {
+		t.Fatalf("Expected 4 jobs, got %d", len(result))
+	}
+
+	for i := 0; i < 3; i++ {
+		if result[i].JobID != 124000 {
+			t.Errorf("Expected array tasks to use the ID of the array, got %d", result[i].JobID)
+		}
+	}
+
+	if ids := result[1].ArrayTaskIds(); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Expected task 1, got %v", ids)
+	}
+
+	if ids := result[2].ArrayTaskIds(); len(ids) != 4 || ids[0] != 2 || ids[3] != 7 {
+		t.Errorf("Expected tasks 2-4 and 7, got %v", ids)
+	}
+
+	if result[3].JobID != 124010 || result[3].ArrayTaskIds() != nil {
+		t.Errorf("Expected a regular job, got %+v", result[3])
+	}
+}
@@ -4,12 +4,14 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	accesslogv3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
 	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	extauthz "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
 	jwt "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
 	tls "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
@@ -26,33 +28,42 @@ import (
 	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	router "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
+	tlsinspector "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/listener/tls_inspector/v3"
 	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 )
 
 func createConfigurationSnapshot(
-	listenAddress string,
-	port int,
+	config Config,
 	routes []*EnvoyRoute,
 	clusters map[string]*EnvoyCluster,
+	certificates map[string]*EnvoyCertificate,
+	accessControl map[string]*EnvoyAccessControl,
 ) *cache.Snapshot {
 	// NOTE(Dan): Welcome to a file where the noise-to-signal ratio is horrible. Nothing we can do about this, this
 	// is simply how Envoy has chosen to do things.
 
-	configuration := createRoutes(routes)
+	manager := createConnectionManager()
+	listeners := []types.Resource{createListener(config.ListenAddress, config.Port, manager)}
+	if config.TlsPort != 0 && len(certificates) > 0 {
+		listeners = append(listeners, createTlsListener(config.ListenAddress, config.TlsPort, manager, certificates))
+	}
+
+	configuration := createRoutes(routes, accessControl)
 	snap, _ := cache.NewSnapshot(
 		util.RandomToken(16),
 		map[resource.Type][]types.Resource{
 			resource.ClusterType:  createClusters(clusters),
 			resource.RouteType:    {configuration},
-			resource.ListenerType: {createListener(listenAddress, port)},
+			resource.ListenerType: listeners,
 		},
 	)
 	return snap
 }
 
 const jwtFilterName = "envoy.filters.http.jwt"
+const accessFilterName = "envoy.filters.http.ext_authz"
 
-func createListener(listenAddress string, port int) *listener.Listener {
+func createConnectionManager() *anypb.Any {
 	serializedJwks, err := json.Marshal(cfg.Jwks)
 	checkCfg(err)
 	jwtAuth := &jwt.JwtAuthentication{
@@ -102,6 +113,35 @@ func createListener(listenAddress string, port int) *listener.Listener {
 	jwtAuthPb, err := anypb.New(jwtAuth)
 	checkCfg(err)
 
+	// Links with access control are checked by the server before any traffic reaches the job. The filter is disabled
+	// on all routes except the ingress routes of protected domains (see disableAccessFilter). Denied requests receive
+	// the response of the server, which is usually a redirect to a login page.
+	accessAuth := &extauthz.ExtAuthz{
+		TransportApiVersion: core.ApiVersion_V3,
+		Services: &extauthz.ExtAuthz_HttpService{
+			HttpService: &extauthz.HttpService{
+				ServerUri: &core.HttpUri{
+					Uri: "http://ucloud",
+					HttpUpstreamType: &core.HttpUri_Cluster{
+						Cluster: ServerClusterName,
+					},
+					Timeout: &duration.Duration{
+						Seconds: 5,
+					},
+				},
+				PathPrefix: LinkAccessCheckPath(),
+				AuthorizationRequest: &extauthz.AuthorizationRequest{
+					AllowedHeaders: &matcher.ListStringMatcher{
+						Patterns: []*matcher.StringMatcher{exactString("cookie")},
+					},
+				},
+			},
+		},
+	}
+
+	accessAuthPb, err := anypb.New(accessAuth)
+	checkCfg(err)
+
 	routerConfig, err := anypb.New(&router.Router{})
 	checkCfg(err)
 
@@ -152,6 +192,12 @@ func createListener(listenAddress string, port int) *listener.Listener {
 					TypedConfig: jwtAuthPb,
 				},
 			},
+			{
+				Name: accessFilterName,
+				ConfigType: &hcm.HttpFilter_TypedConfig{
+					TypedConfig: accessAuthPb,
+				},
+			},
 			{
 				Name: "envoy.filters.http.router",
 				ConfigType: &hcm.HttpFilter_TypedConfig{
@@ -188,7 +234,10 @@ func createListener(listenAddress string, port int) *listener.Listener {
 
 	managerPb, err := anypb.New(manager)
 	checkCfg(err)
+	return managerPb
+}
 
+func createListener(listenAddress string, port int, managerPb *anypb.Any) *listener.Listener {
 	// The last bit here will tell Envoy to:
 	// - Listen on the correct network interface and on the correct port
 	// - Use the connection manager that we created earlier
@@ -221,18 +270,105 @@ func createListener(listenAddress string, port int) *listener.Listener {
 	}
 }
 
-func createRoutes(routesToAdd []*EnvoyRoute) *route.RouteConfiguration {
+// createTlsListener creates a listener which terminates TLS for custom domains. Every certificate gets its own filter
+// chain which is selected through SNI, the HTTP traffic itself is handled by the same connection manager (and thus the
+// same routes) as the plain listener.
+func createTlsListener(
+	listenAddress string,
+	port int,
+	managerPb *anypb.Any,
+	certificates map[string]*EnvoyCertificate,
+) *listener.Listener {
+	inspectorPb, err := anypb.New(&tlsinspector.TlsInspector{})
+	checkCfg(err)
+
+	var domains []string
+	for domain := range certificates {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	var chains []*listener.FilterChain
+	for _, domain := range domains {
+		certificate := certificates[domain]
+
+		tlsConfig, err := anypb.New(&tls.DownstreamTlsContext{
+			CommonTlsContext: &tls.CommonTlsContext{
+				TlsCertificates: []*tls.TlsCertificate{{
+					CertificateChain: &core.DataSource{
+						Specifier: &core.DataSource_InlineString{
+							InlineString: certificate.CertificateChain,
+						},
+					},
+					PrivateKey: &core.DataSource{
+						Specifier: &core.DataSource_InlineString{
+							InlineString: certificate.PrivateKey,
+						},
+					},
+				}},
+				AlpnProtocols: []string{"h2", "http/1.1"},
+			},
+		})
+		checkCfg(err)
+
+		chains = append(chains, &listener.FilterChain{
+			FilterChainMatch: &listener.FilterChainMatch{
+				ServerNames: []string{domain},
+			},
+			TransportSocket: &core.TransportSocket{
+				Name: "envoy.transport_sockets.tls",
+				ConfigType: &core.TransportSocket_TypedConfig{
+					TypedConfig: tlsConfig,
+				},
+			},
+			Filters: []*listener.Filter{
+				{
+					Name: "http-connection-manager",
+					ConfigType: &listener.Filter_TypedConfig{
+						TypedConfig: managerPb,
+					},
+				},
+			},
+		})
+	}
+
+	return &listener.Listener{
+		Name: "listener_tls",
+		Address: &core.Address{
+			Address: &core.Address_SocketAddress{
+				SocketAddress: &core.SocketAddress{
+					Protocol: core.SocketAddress_TCP,
+					Address:  listenAddress,
+					PortSpecifier: &core.SocketAddress_PortValue{
+						PortValue: uint32(port),
+					},
+				},
+			},
+		},
+		ListenerFilters: []*listener.ListenerFilter{
+			{
+				Name: "envoy.filters.listener.tls_inspector",
+				ConfigType: &listener.ListenerFilter_TypedConfig{
+					TypedConfig: inspectorPb,
+				},
+			},
+		},
+		FilterChains: chains,
+	}
+}
+
+func createRoutes(routesToAdd []*EnvoyRoute, accessControl map[string]*EnvoyAccessControl) *route.RouteConfiguration {
 	var routes []*route.Route
 
 	for _, r := range routesToAdd {
-		newRoutes := formatRoute(r)
+		newRoutes := formatRoute(r, accessControl)
 		for _, newRoute := range newRoutes {
 			routes = append(routes, newRoute)
 		}
 	}
 
 	// Default route to let UCloud/Core know that the user instance (which was requested) does not exist.
-	routes = append(routes, &route.Route{
+	defaultRoute := &route.Route{
 		Match: &route.RouteMatch{
 			PathSpecifier: &route.RouteMatch_Prefix{Prefix: ""},
 		},
@@ -241,7 +377,9 @@ func createRoutes(routesToAdd []*EnvoyRoute) *route.RouteConfiguration {
 				Status: 449,
 			},
 		},
-	})
+	}
+	disableAccessFilter(defaultRoute)
+	routes = append(routes, defaultRoute)
 
 	return &route.RouteConfiguration{
 		Name: "local_route",
@@ -318,7 +456,27 @@ func createClusters(clusters map[string]*EnvoyCluster) []types.Resource {
 	return result
 }
 
-func formatRoute(r *EnvoyRoute) []*route.Route {
+func disableAccessFilter(r *route.Route) {
+	m := r.TypedPerFilterConfig
+	if m == nil {
+		m = make(map[string]*any.Any)
+	}
+
+	perRouteFilter := &extauthz.ExtAuthzPerRoute{
+		Override: &extauthz.ExtAuthzPerRoute_Disabled{
+			Disabled: true,
+		},
+	}
+
+	routeFilterPb, err := anypb.New(perRouteFilter)
+	checkCfg(err)
+
+	m[accessFilterName] = routeFilterPb
+
+	r.TypedPerFilterConfig = m
+}
+
+func formatRoute(r *EnvoyRoute, accessControl map[string]*EnvoyAccessControl) []*route.Route {
 	createBaseRoute := func() *route.Route {
 		result := &route.Route{
 			RequestHeadersToRemove: []string{
@@ -500,6 +658,21 @@ func formatRoute(r *EnvoyRoute) []*route.Route {
 			}},
 		}
 
+	case RouteTypeAcmeChallenge:
+		result.RequestHeadersToRemove = nil
+		disableJwtFilter(result)
+		result.Match = &route.RouteMatch{
+			PathSpecifier: &route.RouteMatch_Prefix{
+				Prefix: AcmeChallengePath,
+			},
+			Headers: []*route.HeaderMatcher{{
+				Name: ":authority",
+				HeaderMatchSpecifier: &route.HeaderMatcher_StringMatch{
+					StringMatch: exactString(r.CustomDomain),
+				},
+			}},
+		}
+
 	case RouteTypeAuthorize:
 		result.RequestHeadersToRemove = nil
 		disableJwtFilter(result)
@@ -509,6 +682,15 @@ func formatRoute(r *EnvoyRoute) []*route.Route {
 			},
 		}
 
+	case RouteTypeLinkAccess:
+		result.RequestHeadersToRemove = nil
+		disableJwtFilter(result)
+		result.Match = &route.RouteMatch{
+			PathSpecifier: &route.RouteMatch_Prefix{
+				Prefix: LinkAccessPath() + "/",
+			},
+		}
+
 	case RouteTypeIngress:
 		result.RequestHeadersToRemove = nil
 		disableJwtFilter(result)
@@ -551,6 +733,14 @@ func formatRoute(r *EnvoyRoute) []*route.Route {
 			},
 			Headers: matchers,
 		}
+
+		if _, protected := accessControl[r.CustomDomain]; protected {
+			return routes
+		}
+	}
+
+	for _, newRoute := range routes {
+		disableAccessFilter(newRoute)
 	}
 
 	return routes
@@ -40,12 +40,20 @@ type ConfigurationMessage struct {
 	ClusterDown            *EnvoyCluster
 	RouteUp                *EnvoyRoute
 	RouteDown              *EnvoyRoute
+	CertificateUp          *EnvoyCertificate
+	CertificateDown        *EnvoyCertificate
+	AccessControlUp        *EnvoyAccessControl
+	AccessControlDown      *EnvoyAccessControl
 	LaunchingUserInstances *bool
 }
 
 type Config struct {
 	ListenAddress string
 	Port          int
+
+	// TlsPort is the port used for TLS terminated by the gateway. The listener is only created once at least one
+	// certificate has been installed.
+	TlsPort int
 }
 
 var configChannel chan []byte
@@ -56,6 +64,8 @@ func Initialize(config Config, channel chan []byte) {
 
 	var routes = make(map[*EnvoyRoute]bool)
 	var clusters = make(map[string]*EnvoyCluster)
+	var certificates = make(map[string]*EnvoyCertificate)
+	var accessControl = make(map[string]*EnvoyAccessControl)
 
 	configChannel = channel
 
@@ -114,6 +124,12 @@ func Initialize(config Config, channel chan []byte) {
 		EnvoySecretKey: cfg.OwnEnvoySecret,
 	}] = true
 
+	routes[&EnvoyRoute{
+		Type:           RouteTypeLinkAccess,
+		Cluster:        ServerClusterName,
+		EnvoySecretKey: cfg.OwnEnvoySecret,
+	}] = true
+
 	clusters[ServerClusterName] = &EnvoyCluster{
 		Name:    ServerClusterName,
 		Address: internalAddress,
@@ -163,12 +179,28 @@ func Initialize(config Config, channel chan []byte) {
 				clusters[message.ClusterUp.Name] = message.ClusterUp
 			}
 
+			if message.CertificateDown != nil {
+				delete(certificates, message.CertificateDown.Domain)
+			}
+
+			if message.CertificateUp != nil {
+				certificates[message.CertificateUp.Domain] = message.CertificateUp
+			}
+
+			if message.AccessControlDown != nil {
+				delete(accessControl, message.AccessControlDown.Domain)
+			}
+
+			if message.AccessControlUp != nil {
+				accessControl[message.AccessControlUp.Domain] = message.AccessControlUp
+			}
+
 			// NOTE(Dan): We configure even if no changes are made. This allows to resume the system and
 			// resynchronizing by simply sending an empty configuration message.
 
 			if !paused.Load() {
 				sortedRoutes := sortRoutes(routes)
-				snapshot := createConfigurationSnapshot(config.ListenAddress, config.Port, sortedRoutes, clusters)
+				snapshot := createConfigurationSnapshot(config, sortedRoutes, clusters, certificates, accessControl)
 				setActiveSnapshot(snapshot)
 			}
 		}
@@ -393,6 +425,19 @@ type EnvoyCluster struct {
 	TLS     bool
 }
 
+// EnvoyCertificate is a PEM encoded certificate chain and private key served for Domain over SNI.
+type EnvoyCertificate struct {
+	Domain           string
+	CertificateChain string
+	PrivateKey       string
+}
+
+// EnvoyAccessControl marks the ingress routes of Domain as protected. Every request to such a route is checked by
+// the server at LinkAccessCheckPath before it is forwarded to the cluster of the route.
+type EnvoyAccessControl struct {
+	Domain string
+}
+
 type RouteType int
 
 const (
@@ -400,8 +445,26 @@ const (
 	RouteTypeIngress
 	RouteTypeAuthorize
 	RouteTypeVnc
+	RouteTypeAcmeChallenge
+	RouteTypeLinkAccess
 )
 
+// AcmeChallengePath is the prefix used by ACME servers when validating HTTP-01 challenges. Requests for the CustomDomain
+// of the route are sent to the cluster of the route.
+const AcmeChallengePath = "/.well-known/acme-challenge/"
+
+// LinkAccessPath returns the prefix of the endpoints used to log in to links with access control. These are served by
+// the server on every domain.
+func LinkAccessPath() string {
+	return fmt.Sprintf("/ucloud/%v/link-access", cfg.Provider.Id)
+}
+
+// LinkAccessCheckPath returns the prefix used when the gateway asks the server if a request to a protected link should
+// be let through. The original path of the request is appended to the prefix.
+func LinkAccessCheckPath() string {
+	return LinkAccessPath() + "/check"
+}
+
 type EnvoyRoute struct {
 	Cluster        string
 	Identifier     string
@@ -424,6 +487,10 @@ func (r *EnvoyRoute) weight() int {
 		return 5
 	case RouteTypeVnc:
 		return 5
+	case RouteTypeAcmeChallenge:
+		return 4
+	case RouteTypeLinkAccess:
+		return 5
 	}
 
 	return 1000
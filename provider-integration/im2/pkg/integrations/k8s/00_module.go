@@ -76,16 +76,28 @@ func Init(config *cfg.ServicesConfigurationKubernetes) {
 	}
 
 	initStorageScanCli()
-	if config.FileSystem.MetadataCatalog.Enabled || config.FileSystem.MetadataCatalog.EnableIntegration {
-		metadataConfig := config.FileSystem.MetadataCatalog
-		if err := filesystem.MetadataConfigureCatalog(filesystem.MetadataCatalogConfig{
-			IOPS:              metadataConfig.IOPS,
-			ParallelScans:     metadataConfig.ParallelScans,
-			EntriesPerSSTable: metadataConfig.EntriesPerSSTable,
-		}); err != nil {
-			log.Warn("Unable to configure metadata catalog: %s", err)
-		} else {
-			filesystem.MetadataStartScanner()
+	metadataCatalogConfigured := false
+	for name, fs := range config.FileSystems {
+		if fs.MetadataCatalog.Enabled || fs.MetadataCatalog.EnableIntegration {
+			metadataConfig := fs.MetadataCatalog
+			if err := filesystem.MetadataConfigureCatalog(name, filesystem.MetadataCatalogConfig{
+				IOPS:              metadataConfig.IOPS,
+				ParallelScans:     metadataConfig.ParallelScans,
+				EntriesPerSSTable: metadataConfig.EntriesPerSSTable,
+			}); err != nil {
+				log.Warn("Unable to configure metadata catalog for %s: %s", name, err)
+			} else {
+				metadataCatalogConfigured = true
+			}
+		}
+	}
+	if metadataCatalogConfigured {
+		filesystem.MetadataStartScanner()
+	}
+	for _, fs := range config.FileSystems {
+		if fs.Retention.Enabled {
+			filesystem.RetentionStart()
+			break
 		}
 	}
 	filesystem.InitMetadataCli()
@@ -50,6 +50,10 @@ func InitCompute() controller.JobsService {
 		HandleBuiltInVnc:         handleBuiltInVnc,
 		AttachResource:           attachResource,
 		DetachResource:           detachResource,
+		CreateSnapshot:           createSnapshot,
+		BrowseSnapshots:          browseSnapshots,
+		RestoreSnapshot:          restoreSnapshot,
+		DeleteSnapshot:           deleteSnapshot,
 		PublicIPs: controller.PublicIPService{
 			Create:           createPublicIp,
 			Delete:           deletePublicIp,
@@ -61,6 +65,7 @@ func InitCompute() controller.JobsService {
 			Delete:           deleteIngress,
 			OnUpdatedLabels:  nil,
 			RetrieveProducts: retrieveIngressProducts,
+			VerifyDomain:     customDomainVerify,
 		},
 		Licenses: controller.LicenseService{
 			Create:           activateLicense,
@@ -87,6 +92,10 @@ func InitComputeLater() {
 
 	initJobQueue()
 
+	if shared.ServiceConfig.Compute.PublicLinks.CustomDomains.Enabled {
+		initCustomDomains()
+	}
+
 	go func() {
 		nodes = shared.NewResourceTracker[*k8score.Node](
 			"",
@@ -175,6 +184,10 @@ func createIngress(ingress *orc.Ingress) *util.HttpError {
 		owner = ingress.Owner.Project.Value
 	}
 
+	if ingress.Status.CustomDomain.Present {
+		return customDomainCreate(ingress, owner)
+	}
+
 	domain := ingress.Specification.Domain
 	prefix := shared.ServiceConfig.Compute.PublicLinks.Prefix
 	suffix := shared.ServiceConfig.Compute.PublicLinks.Suffix
@@ -252,8 +265,16 @@ func deleteIngress(ingress *orc.Ingress) *util.HttpError {
 				"domain": ingress.Specification.Domain,
 			},
 		)
+
+		if ingress.Status.CustomDomain.Present {
+			customDomainDelete(tx, ingress)
+		}
 	})
 
+	if result == nil && ingress.Status.CustomDomain.Present {
+		customDomainRemoveCertificate(ingress.Specification.Domain)
+	}
+
 	accountPublicLinks(ingress.Owner)
 	return result
 }
@@ -462,3 +483,39 @@ func detachResource(job *orc.Job, resource orc.AppParameterValue) *util.HttpErro
 		return util.HttpErr(http.StatusBadRequest, "unsupported operation")
 	}
 }
+
+func createSnapshot(job *orc.Job, title string) (string, *util.HttpError) {
+	fn := backend(job).CreateSnapshot
+	if fn != nil {
+		return fn(job, title)
+	} else {
+		return "", util.HttpErr(http.StatusBadRequest, "unsupported operation")
+	}
+}
+
+func browseSnapshots(job *orc.Job) ([]orc.JobSnapshot, *util.HttpError) {
+	fn := backend(job).BrowseSnapshots
+	if fn != nil {
+		return fn(job)
+	} else {
+		return nil, util.HttpErr(http.StatusBadRequest, "unsupported operation")
+	}
+}
+
+func restoreSnapshot(job *orc.Job, snapshotId string) *util.HttpError {
+	fn := backend(job).RestoreSnapshot
+	if fn != nil {
+		return fn(job, snapshotId)
+	} else {
+		return util.HttpErr(http.StatusBadRequest, "unsupported operation")
+	}
+}
+
+func deleteSnapshot(job *orc.Job, snapshotId string) *util.HttpError {
+	fn := backend(job).DeleteSnapshot
+	if fn != nil {
+		return fn(job, snapshotId)
+	} else {
+		return util.HttpErr(http.StatusBadRequest, "unsupported operation")
+	}
+}
@@ -224,7 +224,7 @@ func follow(session *controller.FollowJobSession) {
 	}
 
 	trackAllFiles := func() {
-		for rank := 0; rank < job.Specification.Replicas; rank++ {
+		for rank := 0; rank < shared.JobPodCount(job); rank++ {
 			baseName := fmt.Sprintf("stdout-%d.log", rank)
 			trackFile(baseName, trackedLogFile{
 				Rank:   rank,
@@ -369,7 +369,7 @@ func terminate(request controller.JobTerminateRequest) *util.HttpError {
 				}
 			}
 		} else {
-			for rank := 0; rank < request.Job.Specification.Replicas; rank++ {
+			for rank := 0; rank < shared.JobPodCount(request.Job); rank++ {
 				pod, ok := shared.JobPods.Retrieve(idAndRankToPodName(request.Job.Id, rank))
 				if ok {
 					pods = append(pods, pod)
@@ -270,6 +270,10 @@ func Monitor(tracker shared.JobTracker, jobs map[string]*orc.Job) {
 		if _, isIApp := activeIApps[job.Id]; isIApp {
 			continue
 		}
+		if job.Specification.Sweep.Present {
+			// Tasks of a job array are started gradually by the scheduler. A missing pod is expected here.
+			continue
+		}
 
 		confirmedRank := -1
 		for rank := 0; rank < job.Specification.Replicas; rank++ {
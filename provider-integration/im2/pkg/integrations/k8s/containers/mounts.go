@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	core "k8s.io/api/core/v1"
+	cfg "ucloud.dk/pkg/config"
 	"ucloud.dk/pkg/integrations/k8s/filesystem"
 	"ucloud.dk/pkg/integrations/k8s/shared"
 	orc "ucloud.dk/shared/pkg/orchestrators"
@@ -16,6 +17,7 @@ import (
 
 type mountedFolder struct {
 	InternalPath string
+	FileSystem   cfg.KubernetesFileSystem
 	SubPath      string
 	PodPath      string
 	ReadOnly     bool
@@ -28,6 +30,7 @@ type mountResult struct {
 }
 
 type resolvedMount struct {
+	FileSystem cfg.KubernetesFileSystem
 	SubPath    string
 	ReadOnly   bool
 	IsExplicit bool
@@ -65,25 +68,16 @@ func calculateMounts(job *orc.Job, internalJobFolder string) (mountResult, bool)
 	resolvedMounts := map[string][]resolvedMount{}
 	hasMountPathConflict := false
 
-	internalToSubpath := func(internalPath string) (string, bool) {
-		subpath, ok := strings.CutPrefix(internalPath, filepath.Clean(ServiceConfig.FileSystem.MountPoint)+"/")
-		if !ok {
-			return "", false
-		}
-
-		return subpath, true
-	}
-
-	ucloudToSubpath := func(ucloudPath string) (string, bool) {
+	ucloudToSubpath := func(ucloudPath string) (cfg.KubernetesFileSystem, string, bool) {
 		path, ok, _ := filesystem.UCloudToInternal(ucloudPath)
 		if !ok {
-			return "", false
+			return cfg.KubernetesFileSystem{}, "", false
 		}
 
-		return internalToSubpath(path)
+		return shared.FileSystemSubPath(path)
 	}
 
-	addMount := func(containerPath, subpath string, readOnly bool, isExplicit bool, ucloudPath string) bool {
+	addMount := func(containerPath string, fs cfg.KubernetesFileSystem, subpath string, readOnly bool, isExplicit bool, ucloudPath string) bool {
 		existing, _ := resolvedMounts[containerPath]
 
 		hasExistingExplicit := false
@@ -100,6 +94,7 @@ func calculateMounts(job *orc.Job, internalJobFolder string) (mountResult, bool)
 		}
 
 		existing = append(existing, resolvedMount{
+			FileSystem: fs,
 			SubPath:    subpath,
 			ReadOnly:   readOnly,
 			IsExplicit: isExplicit,
@@ -110,9 +105,9 @@ func calculateMounts(job *orc.Job, internalJobFolder string) (mountResult, bool)
 	}
 
 	addInternalMount := func(containerPath, internalPath string, readOnly bool, isExplicit bool) {
-		sub, ok := internalToSubpath(internalPath)
+		fs, sub, ok := shared.FileSystemSubPath(internalPath)
 		if ok {
-			addMount(containerPath, sub, readOnly, isExplicit, "")
+			addMount(containerPath, fs, sub, readOnly, isExplicit, "")
 		}
 	}
 
@@ -121,9 +116,9 @@ func calculateMounts(job *orc.Job, internalJobFolder string) (mountResult, bool)
 	addUCloudMount := func(containerPath, ucloudPath string, readOnly bool, isExplicit bool) {
 		allUCloudPaths = append(allUCloudPaths, ucloudPath)
 
-		sub, ok := ucloudToSubpath(ucloudPath)
+		fs, sub, ok := ucloudToSubpath(ucloudPath)
 		if ok {
-			addMount(containerPath, sub, readOnly, isExplicit, ucloudPath)
+			addMount(containerPath, fs, sub, readOnly, isExplicit, ucloudPath)
 		}
 	}
 
@@ -182,9 +177,10 @@ func calculateMounts(job *orc.Job, internalJobFolder string) (mountResult, bool)
 		if len(mounts) == 1 {
 			mount := mounts[0]
 
-			internalPath := ServiceConfig.FileSystem.MountPoint + "/" + mount.SubPath
+			internalPath := mount.FileSystem.MountPoint + "/" + mount.SubPath
 			mountPaths[internalPath] = mountedFolder{
 				InternalPath: internalPath,
+				FileSystem:   mount.FileSystem,
 				SubPath:      mount.SubPath,
 				PodPath:      containerPath,
 				ReadOnly:     mount.ReadOnly,
@@ -200,15 +196,16 @@ func calculateMounts(job *orc.Job, internalJobFolder string) (mountResult, bool)
 		} else {
 			// NOTE(Dan): Must remain consistent with VM mount logic for overall consistency
 			slices.SortFunc(mounts, func(a, b resolvedMount) int {
-				return strings.Compare(a.SubPath, b.SubPath)
+				return strings.Compare(a.FileSystem.MountPoint+"/"+a.SubPath, b.FileSystem.MountPoint+"/"+b.SubPath)
 			})
 
 			for i, mount := range mounts {
 				resolvedContainerPath := fmt.Sprintf("%s-%d", containerPath, i)
 
-				internalPath := ServiceConfig.FileSystem.MountPoint + "/" + mount.SubPath
+				internalPath := mount.FileSystem.MountPoint + "/" + mount.SubPath
 				mountPaths[internalPath] = mountedFolder{
 					InternalPath: internalPath,
+					FileSystem:   mount.FileSystem,
 					SubPath:      mount.SubPath,
 					PodPath:      resolvedContainerPath,
 					ReadOnly:     mount.ReadOnly,
@@ -246,16 +243,29 @@ func prepareMountsOnJobCreate(
 ) (map[string]string, []activityMount, bool) {
 	spec := &pod.Spec
 
-	fsVolume := "ucloud-filesystem"
-	spec.Volumes = append(spec.Volumes, core.Volume{
-		Name: fsVolume,
-		VolumeSource: core.VolumeSource{
-			PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{
-				ClaimName: ServiceConfig.FileSystem.ClaimName,
-				ReadOnly:  false,
+	// The default file system is always mounted since it is also used for the job folder and internal files. Other
+	// file systems are only added when a folder from them is mounted.
+	addFileSystemVolume := func(fs cfg.KubernetesFileSystem) string {
+		name := shared.FileSystemVolumeName(fs)
+		for _, volume := range spec.Volumes {
+			if volume.Name == name {
+				return name
+			}
+		}
+
+		spec.Volumes = append(spec.Volumes, core.Volume{
+			Name: name,
+			VolumeSource: core.VolumeSource{
+				PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{
+					ClaimName: fs.ClaimName,
+					ReadOnly:  false,
+				},
 			},
-		},
-	})
+		})
+		return name
+	}
+
+	addFileSystemVolume(ServiceConfig.FileSystem)
 
 	mounts, ok := calculateMounts(job, jobFolder)
 	if !ok {
@@ -268,7 +278,7 @@ func prepareMountsOnJobCreate(
 
 	for internalPath, folder := range folders {
 		userContainer.VolumeMounts = append(userContainer.VolumeMounts, core.VolumeMount{
-			Name:      fsVolume,
+			Name:      addFileSystemVolume(folder.FileSystem),
 			ReadOnly:  folder.ReadOnly,
 			MountPath: folder.PodPath,
 			SubPath:   folder.SubPath,
@@ -0,0 +1,640 @@
+package k8s
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"ucloud.dk/pkg/controller"
+	gw "ucloud.dk/pkg/gateway"
+	"ucloud.dk/pkg/integrations/k8s/shared"
+	db "ucloud.dk/shared/pkg/database"
+	fnd "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
+	orc "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Custom domains
+// =====================================================================================================================
+// Public links normally use a domain built from the prefix and suffix of the provider. A custom domain is instead owned
+// by the user. UCloud/Core generates a challenge token which the user must publish in a TXT record. Once the record is
+// found, a certificate is obtained over ACME (using the HTTP-01 challenge, answered by this server through the gateway)
+// and installed in the gateway which serves the domain over SNI. Certificates are renewed automatically.
+
+const (
+	customDomainCheckInterval = 5 * time.Minute
+	customDomainRenewBefore   = 30 * 24 * time.Hour
+	customDomainRetryAfter    = 1 * time.Hour
+)
+
+// customDomainLookupTxt is replaced in tests
+var customDomainLookupTxt = net.LookupTXT
+
+var customDomainGlobals struct {
+	Mu sync.Mutex
+
+	// Key authorizations of pending HTTP-01 challenges indexed by their token
+	Challenges map[string]string
+
+	// Earliest time at which a failed certificate request is attempted again, indexed by domain
+	NextAttempt map[string]time.Time
+
+	Client *acme.Client
+	Wake   chan util.Empty
+}
+
+type customDomain struct {
+	IngressId      string
+	Domain         string
+	ChallengeToken string
+	Verified       bool
+	Certificate    string
+	PrivateKey     string
+	ExpiresAt      sql.NullTime
+	LastError      string
+}
+
+func initCustomDomains() {
+	customDomainGlobals.Challenges = map[string]string{}
+	customDomainGlobals.NextAttempt = map[string]time.Time{}
+	customDomainGlobals.Wake = make(chan util.Empty, 1)
+
+	controller.Mux.HandleFunc(gw.AcmeChallengePath, func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.URL.Path, gw.AcmeChallengePath)
+
+		customDomainGlobals.Mu.Lock()
+		keyAuth, ok := customDomainGlobals.Challenges[token]
+		customDomainGlobals.Mu.Unlock()
+
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(keyAuth))
+	})
+
+	for _, domain := range customDomainsFetchAll() {
+		if domain.Certificate != "" {
+			customDomainInstallCertificate(domain)
+		}
+	}
+
+	go func() {
+		for util.IsAlive {
+			customDomainsProcess()
+
+			select {
+			case <-customDomainGlobals.Wake:
+			case <-time.After(customDomainCheckInterval):
+			}
+		}
+	}()
+}
+
+func customDomainsWake() {
+	select {
+	case customDomainGlobals.Wake <- util.Empty{}:
+	default:
+	}
+}
+
+func customDomainCreate(ingress *orc.Ingress, owner string) *util.HttpError {
+	if !shared.ServiceConfig.Compute.PublicLinks.CustomDomains.Enabled {
+		return util.UserHttpError("Custom domains are not supported by this provider.")
+	}
+
+	domain := ingress.Specification.Domain
+	challenge := ingress.Status.CustomDomain.Value
+
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				insert into ingresses(domain, owner)
+				values (:domain, :owner) on conflict do nothing
+			`,
+			db.Params{
+				"domain": domain,
+				"owner":  owner,
+			},
+		)
+
+		db.Exec(
+			tx,
+			`
+				insert into k8s.custom_domains(ingress_id, domain, challenge_token)
+				values (:id, :domain, :token)
+				on conflict (ingress_id) do nothing
+			`,
+			db.Params{
+				"id":     ingress.Id,
+				"domain": domain,
+				"token":  challenge.ChallengeToken,
+			},
+		)
+	})
+
+	ingress.Status.State = orc.IngressStatePreparing
+	controller.LinkTrack(*ingress)
+	accountPublicLinks(ingress.Owner)
+	customDomainsWake()
+	return nil
+}
+
+func customDomainDelete(tx *db.Transaction, ingress *orc.Ingress) {
+	db.Exec(
+		tx,
+		`
+			delete from k8s.custom_domains
+			where ingress_id = :id
+		`,
+		db.Params{
+			"id": ingress.Id,
+		},
+	)
+}
+
+func customDomainRemoveCertificate(domain string) {
+	gw.SendMessage(gw.ConfigurationMessage{
+		CertificateDown: &gw.EnvoyCertificate{Domain: domain},
+	})
+}
+
+// customDomainVerify checks the ownership challenge immediately. This allows the user to get feedback on their DNS
+// records without waiting for the periodic check.
+func customDomainVerify(ingress *orc.Ingress) *util.HttpError {
+	domain, ok := customDomainRetrieve(ingress.Id)
+	if !ok {
+		return util.UserHttpError("This public link does not use a custom domain.")
+	}
+
+	if !domain.Verified {
+		if err := customDomainCheckChallenge(domain.Domain, domain.ChallengeToken); err != nil {
+			return util.UserHttpError("%s", err)
+		}
+	}
+
+	customDomainGlobals.Mu.Lock()
+	delete(customDomainGlobals.NextAttempt, domain.Domain)
+	customDomainGlobals.Mu.Unlock()
+
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				update k8s.custom_domains
+				set verified = true, last_error = ''
+				where ingress_id = :id
+			`,
+			db.Params{
+				"id": domain.IngressId,
+			},
+		)
+	})
+
+	customDomainsWake()
+	return nil
+}
+
+func customDomainCheckChallenge(domain string, token string) error {
+	record := orc.IngressCustomDomainChallengePrefix + domain
+	values, err := customDomainLookupTxt(record)
+	if err != nil {
+		return fmt.Errorf("could not find the TXT record %s: %w", record, err)
+	}
+
+	for _, value := range values {
+		if strings.TrimSpace(value) == token {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("the TXT record %s does not contain the expected token", record)
+}
+
+func customDomainsProcess() {
+	for _, domain := range customDomainsFetchAll() {
+		before := domain
+
+		if !domain.Verified {
+			if err := customDomainCheckChallenge(domain.Domain, domain.ChallengeToken); err != nil {
+				domain.LastError = err.Error()
+			} else {
+				domain.Verified = true
+				domain.LastError = ""
+			}
+		}
+
+		needsCertificate := domain.Certificate == "" || !domain.ExpiresAt.Valid ||
+			time.Until(domain.ExpiresAt.Time) < customDomainRenewBefore
+
+		if domain.Verified && needsCertificate {
+			customDomainGlobals.Mu.Lock()
+			nextAttempt := customDomainGlobals.NextAttempt[domain.Domain]
+			customDomainGlobals.Mu.Unlock()
+
+			if time.Now().After(nextAttempt) {
+				err := customDomainIssueCertificate(&domain)
+
+				customDomainGlobals.Mu.Lock()
+				if err != nil {
+					customDomainGlobals.NextAttempt[domain.Domain] = time.Now().Add(customDomainRetryAfter)
+				} else {
+					delete(customDomainGlobals.NextAttempt, domain.Domain)
+				}
+				customDomainGlobals.Mu.Unlock()
+
+				if err != nil {
+					log.Warn("Failed to obtain certificate for %s: %s", domain.Domain, err)
+					domain.LastError = fmt.Sprintf("Failed to obtain a certificate: %s", err)
+				} else {
+					domain.LastError = ""
+					customDomainInstallCertificate(domain)
+				}
+			}
+		}
+
+		if domain != before {
+			customDomainSave(domain)
+			customDomainReport(domain)
+		}
+	}
+}
+
+func customDomainIssueCertificate(domain *customDomain) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	client, err := customDomainAcmeClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The challenge route only exists for domains with a pending order. Other domains keep serving their links on
+	// the challenge path.
+	challengeRoute := &gw.EnvoyRoute{
+		Cluster:      gw.ServerClusterName,
+		CustomDomain: domain.Domain,
+		Type:         gw.RouteTypeAcmeChallenge,
+	}
+	gw.SendMessage(gw.ConfigurationMessage{RouteUp: challengeRoute})
+	defer gw.SendMessage(gw.ConfigurationMessage{RouteDown: challengeRoute})
+
+	chain, key, expiresAt, err := acmeObtainCertificate(ctx, client, domain.Domain, customDomainPresentChallenge)
+	if err != nil {
+		return err
+	}
+
+	domain.Certificate = string(chain)
+	domain.PrivateKey = string(key)
+	domain.ExpiresAt = sql.NullTime{Time: expiresAt, Valid: true}
+	return nil
+}
+
+func customDomainPresentChallenge(token string, keyAuth string) func() {
+	customDomainGlobals.Mu.Lock()
+	customDomainGlobals.Challenges[token] = keyAuth
+	customDomainGlobals.Mu.Unlock()
+
+	return func() {
+		customDomainGlobals.Mu.Lock()
+		delete(customDomainGlobals.Challenges, token)
+		customDomainGlobals.Mu.Unlock()
+	}
+}
+
+func customDomainInstallCertificate(domain customDomain) {
+	gw.SendMessage(gw.ConfigurationMessage{
+		CertificateUp: &gw.EnvoyCertificate{
+			Domain:           domain.Domain,
+			CertificateChain: domain.Certificate,
+			PrivateKey:       domain.PrivateKey,
+		},
+	})
+}
+
+func customDomainReport(domain customDomain) {
+	status := orc.IngressCustomDomainStatus{
+		ChallengeRecord: orc.IngressCustomDomainChallengePrefix + domain.Domain,
+		ChallengeToken:  domain.ChallengeToken,
+		Verified:        domain.Verified,
+	}
+
+	if domain.ExpiresAt.Valid {
+		status.CertificateExpiresAt.Set(fnd.Timestamp(domain.ExpiresAt.Time))
+	}
+
+	if domain.LastError != "" {
+		status.Error.Set(domain.LastError)
+	}
+
+	state := orc.IngressStatePreparing
+	message := "Waiting for the ownership of the domain to be verified"
+	if domain.Certificate != "" {
+		state = orc.IngressStateReady
+		message = "Public link is ready for use"
+	} else if domain.Verified {
+		message = "Waiting for a certificate to be issued"
+	}
+
+	update := orc.IngressUpdate{
+		State:        util.OptValue(state),
+		Timestamp:    fnd.Timestamp(time.Now()),
+		Status:       util.OptValue(message),
+		CustomDomain: util.OptValue(status),
+	}
+
+	_, err := orc.IngressesControlAddUpdate.Invoke(fnd.BulkRequestOf(orc.ResourceUpdateAndId[orc.IngressUpdate]{
+		Id:     domain.IngressId,
+		Update: update,
+	}))
+
+	if err != nil {
+		log.Warn("Failed to report status of custom domain %s: %s", domain.Domain, err)
+		return
+	}
+
+	ingress := controller.LinkRetrieve(domain.IngressId)
+	if ingress.Id != "" {
+		ingress.Status.State = state
+		ingress.Status.CustomDomain.Set(status)
+		ingress.Updates = append(ingress.Updates, update)
+		controller.LinkTrack(ingress)
+	}
+}
+
+// ACME
+// =====================================================================================================================
+
+func customDomainAcmeClient(ctx context.Context) (*acme.Client, error) {
+	customDomainGlobals.Mu.Lock()
+	existing := customDomainGlobals.Client
+	customDomainGlobals.Mu.Unlock()
+	if existing != nil {
+		return existing, nil
+	}
+
+	config := shared.ServiceConfig.Compute.PublicLinks.CustomDomains
+	key, err := customDomainAccountKey(config.AcmeDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &acme.Client{Key: key, DirectoryURL: config.AcmeDirectory}
+	if err = acmeRegister(ctx, client, config.Email); err != nil {
+		return nil, err
+	}
+
+	customDomainGlobals.Mu.Lock()
+	customDomainGlobals.Client = client
+	customDomainGlobals.Mu.Unlock()
+	return client, nil
+}
+
+func customDomainAccountKey(directory string) (crypto.Signer, error) {
+	row, ok := db.NewTx2(func(tx *db.Transaction) (struct{ PrivateKey string }, bool) {
+		return db.Get[struct{ PrivateKey string }](
+			tx,
+			`
+				select private_key
+				from k8s.acme_accounts
+				where directory = :directory
+			`,
+			db.Params{
+				"directory": directory,
+			},
+		)
+	})
+
+	if ok {
+		block, _ := pem.Decode([]byte(row.PrivateKey))
+		if block == nil {
+			return nil, fmt.Errorf("invalid ACME account key")
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPem, err := encodeEcKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				insert into k8s.acme_accounts(directory, private_key)
+				values (:directory, :key)
+				on conflict (directory) do nothing
+			`,
+			db.Params{
+				"directory": directory,
+				"key":       string(keyPem),
+			},
+		)
+	})
+
+	return key, nil
+}
+
+func acmeRegister(ctx context.Context, client *acme.Client, email string) error {
+	account := &acme.Account{}
+	if email != "" {
+		account.Contact = []string{"mailto:" + email}
+	}
+
+	_, err := client.Register(ctx, account, acme.AcceptTOS)
+	if err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+// acmeObtainCertificate orders a certificate for domain using the HTTP-01 challenge. The present function must make
+// the key authorization available at the challenge path and returns a function which removes it again. The result is
+// a PEM encoded certificate chain, a PEM encoded private key and the expiration time of the certificate.
+func acmeObtainCertificate(
+	ctx context.Context,
+	client *acme.Client,
+	domain string,
+	present func(token string, keyAuth string) func(),
+) ([]byte, []byte, time.Time, error) {
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(domain))
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	for _, authzUrl := range order.AuthzURLs {
+		authz, err := client.GetAuthorization(ctx, authzUrl)
+		if err != nil {
+			return nil, nil, time.Time{}, err
+		}
+
+		if authz.Status == acme.StatusValid {
+			continue
+		}
+
+		var challenge *acme.Challenge
+		for _, c := range authz.Challenges {
+			if c.Type == "http-01" {
+				challenge = c
+				break
+			}
+		}
+
+		if challenge == nil {
+			return nil, nil, time.Time{}, fmt.Errorf("the ACME server did not offer an http-01 challenge")
+		}
+
+		keyAuth, err := client.HTTP01ChallengeResponse(challenge.Token)
+		if err != nil {
+			return nil, nil, time.Time{}, err
+		}
+
+		cleanup := present(challenge.Token, keyAuth)
+		_, err = client.Accept(ctx, challenge)
+		if err == nil {
+			_, err = client.WaitAuthorization(ctx, authz.URI)
+		}
+		cleanup()
+
+		if err != nil {
+			return nil, nil, time.Time{}, err
+		}
+	}
+
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domain},
+		DNSNames: []string{domain},
+	}, key)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	der, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	leaf, err := x509.ParseCertificate(der[0])
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	if err = leaf.VerifyHostname(domain); err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	var chain []byte
+	for _, cert := range der {
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})...)
+	}
+
+	keyPem, err := encodeEcKey(key)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	return chain, keyPem, leaf.NotAfter, nil
+}
+
+func encodeEcKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+// Database
+// =====================================================================================================================
+
+func customDomainsFetchAll() []customDomain {
+	return db.NewTx(func(tx *db.Transaction) []customDomain {
+		return db.Select[customDomain](
+			tx,
+			`
+				select
+					ingress_id, domain, challenge_token, verified, certificate, private_key, expires_at, last_error
+				from k8s.custom_domains
+				order by domain
+			`,
+			db.Params{},
+		)
+	})
+}
+
+func customDomainRetrieve(ingressId string) (customDomain, bool) {
+	return db.NewTx2(func(tx *db.Transaction) (customDomain, bool) {
+		return db.Get[customDomain](
+			tx,
+			`
+				select
+					ingress_id, domain, challenge_token, verified, certificate, private_key, expires_at, last_error
+				from k8s.custom_domains
+				where ingress_id = :id
+			`,
+			db.Params{
+				"id": ingressId,
+			},
+		)
+	})
+}
+
+func customDomainSave(domain customDomain) {
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				update k8s.custom_domains
+				set
+					verified = :verified,
+					certificate = :certificate,
+					private_key = :private_key,
+					expires_at = :expires_at,
+					last_error = :last_error
+				where ingress_id = :id
+			`,
+			db.Params{
+				"id":          domain.IngressId,
+				"verified":    domain.Verified,
+				"certificate": domain.Certificate,
+				"private_key": domain.PrivateKey,
+				"expires_at":  domain.ExpiresAt,
+				"last_error":  domain.LastError,
+			},
+		)
+	})
+}
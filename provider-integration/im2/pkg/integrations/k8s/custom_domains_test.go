@@ -0,0 +1,300 @@
+package k8s
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+func TestCustomDomainCheckChallenge(t *testing.T) {
+	original := customDomainLookupTxt
+	t.Cleanup(func() { customDomainLookupTxt = original })
+
+	records := map[string][]string{
+		"_ucloud-challenge.lab.example.org": {"unrelated", " token-value "},
+		"_ucloud-challenge.other.org":       {"wrong-token"},
+	}
+
+	customDomainLookupTxt = func(name string) ([]string, error) {
+		values, ok := records[name]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		return values, nil
+	}
+
+	if err := customDomainCheckChallenge("lab.example.org", "token-value"); err != nil {
+		t.Fatalf("expected the challenge to succeed: %v", err)
+	}
+
+	if err := customDomainCheckChallenge("other.org", "token-value"); err == nil {
+		t.Fatalf("a record with the wrong token must not verify the domain")
+	}
+
+	if err := customDomainCheckChallenge("missing.org", "token-value"); err == nil {
+		t.Fatalf("a missing record must not verify the domain")
+	}
+}
+
+// acmeStandIn is a minimal ACME server in the style of Pebble. Unlike a real server it does not verify signatures, but
+// it does check the HTTP-01 key authorization presented by the client before issuing a certificate from a local CA.
+type acmeStandIn struct {
+	t          *testing.T
+	server     *httptest.Server
+	caCert     *x509.Certificate
+	caKey      *ecdsa.PrivateKey
+	thumbprint string
+	presented  func(token string) (string, bool)
+
+	mu          sync.Mutex
+	domain      string
+	authzValid  bool
+	certificate []byte
+}
+
+const acmeStandInToken = "standin-token"
+
+func newAcmeStandIn(t *testing.T, presented func(token string) (string, bool)) *acmeStandIn {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Stand-in ACME CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	caDer, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caCert, _ := x509.ParseCertificate(caDer)
+	s := &acmeStandIn{t: t, caCert: caCert, caKey: caKey, presented: presented}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *acmeStandIn) url(path string) string {
+	return s.server.URL + path
+}
+
+func (s *acmeStandIn) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Replay-Nonce", base64.RawURLEncoding.EncodeToString([]byte(time.Now().String())))
+
+	var payload []byte
+	if r.Method == http.MethodPost {
+		var jws struct {
+			Payload string `json:"payload"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&jws)
+		payload, _ = base64.RawURLEncoding.DecodeString(jws.Payload)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJson := func(status int, location string, body any) {
+		if location != "" {
+			w.Header().Set("Location", location)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+
+	order := func() map[string]any {
+		status := "pending"
+		if s.certificate != nil {
+			status = "valid"
+		} else if s.authzValid {
+			status = "ready"
+		}
+
+		result := map[string]any{
+			"status":         status,
+			"identifiers":    []map[string]string{{"type": "dns", "value": s.domain}},
+			"authorizations": []string{s.url("/authz/1")},
+			"finalize":       s.url("/finalize/1"),
+		}
+		if s.certificate != nil {
+			result["certificate"] = s.url("/cert/1")
+		}
+		return result
+	}
+
+	challenge := func() map[string]any {
+		status := "pending"
+		if s.authzValid {
+			status = "valid"
+		}
+		return map[string]any{"type": "http-01", "url": s.url("/challenge/1"), "token": acmeStandInToken, "status": status}
+	}
+
+	switch r.URL.Path {
+	case "/directory":
+		writeJson(http.StatusOK, "", map[string]string{
+			"newNonce":   s.url("/nonce"),
+			"newAccount": s.url("/account"),
+			"newOrder":   s.url("/order"),
+		})
+
+	case "/nonce":
+		w.WriteHeader(http.StatusOK)
+
+	case "/account":
+		writeJson(http.StatusCreated, s.url("/account/1"), map[string]any{"status": "valid"})
+
+	case "/order":
+		var req struct {
+			Identifiers []struct{ Value string } `json:"identifiers"`
+		}
+		_ = json.Unmarshal(payload, &req)
+		s.domain = req.Identifiers[0].Value
+		writeJson(http.StatusCreated, s.url("/order/1"), order())
+
+	case "/order/1":
+		writeJson(http.StatusOK, s.url("/order/1"), order())
+
+	case "/authz/1":
+		status := "pending"
+		if s.authzValid {
+			status = "valid"
+		}
+		writeJson(http.StatusOK, "", map[string]any{
+			"status":     status,
+			"identifier": map[string]string{"type": "dns", "value": s.domain},
+			"challenges": []map[string]any{challenge()},
+		})
+
+	case "/challenge/1":
+		keyAuth, ok := s.presented(acmeStandInToken)
+		if ok && keyAuth == acmeStandInToken+"."+s.thumbprint {
+			s.authzValid = true
+		}
+		writeJson(http.StatusOK, "", challenge())
+
+	case "/finalize/1":
+		var req struct {
+			Csr string `json:"csr"`
+		}
+		_ = json.Unmarshal(payload, &req)
+		csrDer, _ := base64.RawURLEncoding.DecodeString(req.Csr)
+		csr, err := x509.ParseCertificateRequest(csrDer)
+		if err != nil || !s.authzValid {
+			writeJson(http.StatusForbidden, "", map[string]any{"type": "urn:ietf:params:acme:error:unauthorized"})
+			return
+		}
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: s.domain},
+			DNSNames:     csr.DNSNames,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}
+		s.certificate, _ = x509.CreateCertificate(rand.Reader, template, s.caCert, csr.PublicKey, s.caKey)
+		writeJson(http.StatusOK, s.url("/order/1"), order())
+
+	case "/cert/1":
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		_ = pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: s.certificate})
+		_ = pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: s.caCert.Raw})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestAcmeObtainCertificate(t *testing.T) {
+	var mu sync.Mutex
+	challenges := map[string]string{}
+
+	standIn := newAcmeStandIn(t, func(token string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		keyAuth, ok := challenges[token]
+		return keyAuth, ok
+	})
+
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	standIn.thumbprint, err = acme.JWKThumbprint(accountKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client := &acme.Client{Key: accountKey, DirectoryURL: standIn.url("/directory")}
+	if err := acmeRegister(ctx, client, "hostmaster@example.org"); err != nil {
+		t.Fatalf("failed to register account: %v", err)
+	}
+
+	chain, key, expiresAt, err := acmeObtainCertificate(ctx, client, "lab.example.org", func(token string, keyAuth string) func() {
+		mu.Lock()
+		challenges[token] = keyAuth
+		mu.Unlock()
+
+		return func() {
+			mu.Lock()
+			delete(challenges, token)
+			mu.Unlock()
+		}
+	})
+
+	if err != nil {
+		t.Fatalf("failed to obtain certificate: %v", err)
+	}
+
+	pair, err := tls.X509KeyPair(chain, key)
+	if err != nil {
+		t.Fatalf("certificate and key do not match: %v", err)
+	}
+
+	if len(pair.Certificate) != 2 {
+		t.Fatalf("expected the full chain to be returned, got %d certificates", len(pair.Certificate))
+	}
+
+	leaf, _ := x509.ParseCertificate(pair.Certificate[0])
+	if err := leaf.VerifyHostname("lab.example.org"); err != nil {
+		t.Fatalf("certificate is not valid for the domain: %v", err)
+	}
+
+	if time.Until(expiresAt) < 89*24*time.Hour {
+		t.Fatalf("unexpected expiration time: %v", expiresAt)
+	}
+
+	if len(challenges) != 0 {
+		t.Fatalf("challenges must be removed once the authorization has completed")
+	}
+}
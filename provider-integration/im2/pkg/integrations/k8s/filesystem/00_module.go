@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"math"
 	"net/http"
 	"os"
@@ -52,7 +53,15 @@ func InitFiles() controller.FileService {
 	activityDeleteExpiredEvents()
 
 	initTasks()
-	if !shared.ServiceConfig.FileSystem.MetadataCatalog.EnableIntegration {
+
+	needsScanQueue := false
+	for _, fs := range shared.ServiceConfig.FileSystems {
+		if !fs.MetadataCatalog.EnableIntegration {
+			needsScanQueue = true
+		}
+	}
+
+	if needsScanQueue {
 		initScanQueue()
 		go func() {
 			for util.IsAlive {
@@ -65,9 +74,12 @@ func InitFiles() controller.FileService {
 		BrowseFiles:                 browseFiles,
 		RetrieveFile:                retrieveFile,
 		Visualize:                   visualize,
+		Retention:                   retention,
 		CreateFolder:                createFolder,
 		Move:                        move,
 		Copy:                        copyFiles,
+		Compress:                    compressFiles,
+		Extract:                     extractFiles,
 		MoveToTrash:                 moveToTrash,
 		EmptyTrash:                  emptyTrash,
 		CreateDownloadSession:       createDownload,
@@ -545,7 +557,7 @@ func nativeStat(drive *orc.Drive, internalPath string, info os.FileInfo, recursi
 }
 
 func metadataRecursiveSize(drive *orc.Drive, internalPath string) util.Option[int64] {
-	if !shared.ServiceConfig.FileSystem.MetadataCatalog.EnableIntegration {
+	if !shared.FileSystemForDrive(drive).MetadataCatalog.EnableIntegration {
 		return util.OptNone[int64]()
 	}
 	sizes, err := metadataLookupRecursiveSizes(drive, []string{internalPath})
@@ -568,7 +580,7 @@ func populateRecursiveSizes(drive *orc.Drive, entries []cachedDirEntry) {
 }
 
 func populateRecursiveSizesForEntries(drive *orc.Drive, entries []*cachedDirEntry) {
-	if !shared.ServiceConfig.FileSystem.MetadataCatalog.EnableIntegration {
+	if !shared.FileSystemForDrive(drive).MetadataCatalog.EnableIntegration {
 		return
 	}
 	paths := make([]string, 0, len(entries))
@@ -684,8 +696,20 @@ func retrieveProducts() []orc.FSSupport {
 }
 
 func loadStorageProducts() {
+	shared.StorageProducts = nil
+	storageSupport = nil
+
+	loadStorageProductsForFileSystem(shared.ServiceConfig.FileSystem)
+	for _, name := range slices.Sorted(maps.Keys(shared.ServiceConfig.FileSystems)) {
+		if name != shared.ServiceConfig.FileSystem.Name {
+			loadStorageProductsForFileSystem(shared.ServiceConfig.FileSystems[name])
+		}
+	}
+}
+
+func loadStorageProductsForFileSystem(fs cfg.KubernetesFileSystem) {
 	pc := apm.ProductCategory{
-		Name:        shared.ServiceConfig.FileSystem.Name,
+		Name:        fs.Name,
 		Provider:    cfg.Provider.Id,
 		ProductType: apm.ProductTypeStorage,
 		AccountingUnit: apm.AccountingUnit{
@@ -699,16 +723,6 @@ func loadStorageProducts() {
 		AllowSubAllocations: true,
 	}
 
-	defaultProduct := apm.ProductV2{
-		Type:                      apm.ProductTypeCStorage,
-		Category:                  pc,
-		Name:                      pc.Name,
-		Description:               "A storage product",
-		ProductType:               apm.ProductTypeStorage,
-		Price:                     1,
-		HiddenInGrantApplications: false,
-	}
-
 	defaultSupport := orc.FSSupport{
 		Product: apm.ProductReference{
 			Id:       pc.Name,
@@ -719,8 +733,8 @@ func loadStorageProducts() {
 
 	{
 		defaultSupport.Stats.SizeInBytes = true
-		defaultSupport.Stats.SizeIncludingChildrenInBytes = shared.ServiceConfig.FileSystem.MetadataCatalog.EnableIntegration
-		defaultSupport.Stats.Visualization = shared.ServiceConfig.FileSystem.MetadataCatalog.Enabled && shared.ServiceConfig.FileSystem.MetadataCatalog.EnableIntegration
+		defaultSupport.Stats.SizeIncludingChildrenInBytes = fs.MetadataCatalog.EnableIntegration
+		defaultSupport.Stats.Visualization = fs.MetadataCatalog.Enabled && fs.MetadataCatalog.EnableIntegration
 		defaultSupport.Stats.ModifiedAt = true
 		defaultSupport.Stats.CreatedAt = true
 		defaultSupport.Stats.AccessedAt = true
@@ -740,6 +754,30 @@ func loadStorageProducts() {
 		defaultSupport.Files.StreamingSearchSupported = true
 		defaultSupport.Files.SharesSupported = true
 		defaultSupport.Files.OpenInTerminal = shared.ServiceConfig.Compute.IntegratedTerminal.Enabled
+		defaultSupport.Files.ArchivesSupported = true
+		defaultSupport.Files.RetentionPolicy = fs.Retention.Enabled
+	}
+
+	for _, productConfig := range fs.Products {
+		product := apm.ProductV2{
+			Type:                      apm.ProductTypeCStorage,
+			Category:                  pc,
+			Name:                      productConfig.Name,
+			Description:               productConfig.Description,
+			ProductType:               apm.ProductTypeStorage,
+			Price:                     1,
+			HiddenInGrantApplications: false,
+		}
+
+		support := defaultSupport
+		support.Product.Id = product.Name
+
+		shared.StorageProducts = append(shared.StorageProducts, product)
+		storageSupport = append(storageSupport, support)
+	}
+
+	if fs.Name != shared.ServiceConfig.FileSystem.Name {
+		return
 	}
 
 	shareProduct := apm.ProductV2{
@@ -758,6 +796,7 @@ func loadStorageProducts() {
 	shareSupport.Collection.UsersCanCreate = false
 	shareSupport.Collection.UsersCanCreate = false
 	shareSupport.Collection.UsersCanRename = false
+	shareSupport.Files.RetentionPolicy = false
 
 	projectHomeProduct := apm.ProductV2{
 		Type:                      apm.ProductTypeCStorage,
@@ -772,8 +811,8 @@ func loadStorageProducts() {
 	projectHomeSupport := shareSupport
 	projectHomeSupport.Product.Id = projectHomeProduct.Name
 
-	shared.StorageProducts = []apm.ProductV2{defaultProduct, shareProduct, projectHomeProduct}
-	storageSupport = []orc.FSSupport{defaultSupport, shareSupport, projectHomeSupport}
+	shared.StorageProducts = append(shared.StorageProducts, shareProduct, projectHomeProduct)
+	storageSupport = append(storageSupport, shareSupport, projectHomeSupport)
 }
 
 func createUpload(actor rpc.Actor, request orc.FilesProviderCreateUploadRequest) (string, *util.HttpError) {
@@ -1053,8 +1092,14 @@ func DoStageFileForDeletion(internalPath string, stagingName string) *util.HttpE
 		return util.ServerHttpError("Invalid trash staging name")
 	}
 
+	// The staging area must be on the same file system as the file, otherwise the rename below will fail.
+	fs, _, ok := shared.FileSystemSubPath(internalPath)
+	if !ok {
+		return util.UserHttpError("Unable to resolve trash folder")
+	}
+
 	parentDir, ok1 := OpenFile(filepath.Dir(internalPath), unix.O_RDONLY, 0)
-	stagingArea, ok2 := OpenFile(shared.ServiceConfig.FileSystem.TrashStagingArea, unix.O_RDONLY, 0)
+	stagingArea, ok2 := OpenFile(fs.TrashStagingArea, unix.O_RDONLY, 0)
 	defer util.SilentClose(parentDir)
 	defer util.SilentClose(stagingArea)
 	if !ok1 || !ok2 {
@@ -1157,12 +1202,12 @@ func transferSourceBegin(actor rpc.Actor, request orc.FilesProviderTransferReque
 }
 
 func search(ctx context.Context, query, folder string, flags orc.FileFlags, output chan orc.ProviderFile) {
-	if shared.ServiceConfig.FileSystem.MetadataCatalog.EnableIntegration {
+	initialFolder, ok, folderDrive := UCloudToInternal(folder)
+	if ok && shared.FileSystemForDrive(folderDrive).MetadataCatalog.EnableIntegration {
 		metadataCatalogSearch(ctx, query, folder, flags, output)
 		return
 	}
 
-	initialFolder, ok, _ := UCloudToInternal(folder)
 	driveId, ok2 := DriveIdFromUCloudPath(folder)
 	defer close(output)
 
@@ -1291,7 +1336,7 @@ func deleteDrive(actor rpc.Actor, drive orc.Drive) *util.HttpError {
 		return util.ServerHttpError("unknown drive")
 	}
 	reportUsedStorage(drive, 0)
-	MetadataDeleteCatalog(drive.Id)
+	MetadataDeleteCatalog(&drive)
 	err := DoDeleteFile(path)
 	if err == nil {
 		ActivityRecord(actor, ActivityEvent{
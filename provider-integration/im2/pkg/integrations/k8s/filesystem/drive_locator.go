@@ -43,7 +43,7 @@ func (d DriveDescriptor) ProductName() string {
 	case DriveDescriptorTypeCollection:
 		fallthrough
 	default:
-		return shared.ServiceConfig.FileSystem.Name
+		return shared.ServiceConfig.FileSystem.Products[0].Name
 	}
 }
 
@@ -142,7 +142,7 @@ var shareCache = util.NewCache[string, orc.Share](1 * time.Minute)
 
 func DriveToLocalPath(drive *orc.Drive) (string, bool, *orc.Drive) {
 	descriptor, ok := ParseDriveDescriptor(util.OptValue(drive.ProviderGeneratedId))
-	mnt := shared.ServiceConfig.FileSystem.MountPoint
+	mnt := shared.FileSystemForDrive(drive).MountPoint
 
 	if !ok {
 		return "/dev/null", false, drive
@@ -246,13 +246,19 @@ type MetadataCatalogConfig struct {
 	EntriesPerSSTable int
 }
 
+// metadataRuntime tracks the scan runtime of every file system. Each file system has its own scan budget, such that a
+// busy file system cannot starve the scans of another.
 var metadataRuntime = struct {
 	sync.Mutex
-	started  bool
+	configs map[string]MetadataCatalogConfig
+	tiers   map[string]*metadataTierRuntime
+}{configs: map[string]MetadataCatalogConfig{}, tiers: map[string]*metadataTierRuntime{}}
+
+type metadataTierRuntime struct {
 	config   MetadataCatalogConfig
 	limiter  *rate.Limiter
 	requests chan metadataScanRequest
-}{config: MetadataCatalogConfig{IOPS: 30_000, ParallelScans: 8, EntriesPerSSTable: 100_000}}
+}
 
 var metadataCompleteCoverage sync.Map
 var metadataDeletedDrives sync.Map
@@ -274,17 +280,18 @@ type metadataScanRequest struct {
 	completions  []func(error)
 }
 
-// MetadataConfigureCatalog configures the aggregate scan budget. Call it before the first request.
-func MetadataConfigureCatalog(config MetadataCatalogConfig) error {
+// MetadataConfigureCatalog enables the catalog for a file system and configures its aggregate scan budget. Call it
+// before the first request. Drives on file systems which have not been configured are never scanned.
+func MetadataConfigureCatalog(fileSystem string, config MetadataCatalogConfig) error {
 	if config.IOPS <= 0 || config.ParallelScans <= 1 || config.EntriesPerSSTable < 10_000 {
 		return errors.New("metadata catalog requires positive IOPS, at least 10,000 entries per SSTable, and 2 parallel scans")
 	}
 	metadataRuntime.Lock()
 	defer metadataRuntime.Unlock()
-	if metadataRuntime.started {
+	if _, started := metadataRuntime.tiers[fileSystem]; started {
 		return errors.New("metadata catalog is already running")
 	}
-	metadataRuntime.config = config
+	metadataRuntime.configs[fileSystem] = config
 	return nil
 }
 
@@ -306,38 +313,50 @@ func metadataSubmitScanRequest(ucloudPath string, completion func(error)) {
 		}
 		return
 	}
-	if !metadataHasCompleteCoverage(drive.Id) {
+	tier, ok := metadataStartRuntime(shared.FileSystemForDrive(drive).Name)
+	if !ok {
+		if completion != nil {
+			completion(errors.New("metadata catalog is not enabled for this drive"))
+		}
+		return
+	}
+	if !metadataHasCompleteCoverage(drive) {
 		internalPath, _, _ = UCloudToInternal(fmt.Sprintf("/%v", drive.Id))
 	}
 
-	metadataStartRuntime()
 	metadataRecordScanSubmitted(drive.Id)
 	request := metadataScanRequest{internalPath: internalPath, drive: drive}
 	if completion != nil {
 		request.completions = append(request.completions, completion)
 	}
-	metadataRuntime.requests <- request
+	tier.requests <- request
 }
 
-func metadataStartRuntime() {
+func metadataStartRuntime(fileSystem string) (*metadataTierRuntime, bool) {
 	metadataRuntime.Lock()
 	defer metadataRuntime.Unlock()
-	if metadataRuntime.started {
-		return
+	if tier, started := metadataRuntime.tiers[fileSystem]; started {
+		return tier, true
+	}
+	config, ok := metadataRuntime.configs[fileSystem]
+	if !ok {
+		return nil, false
+	}
+	tier := &metadataTierRuntime{
+		config:   config,
+		limiter:  rate.NewLimiter(rate.Limit(config.IOPS), max(1, config.IOPS/10)),
+		requests: make(chan metadataScanRequest, 1024),
 	}
-	config := metadataRuntime.config
-	metadataRuntime.limiter = rate.NewLimiter(rate.Limit(config.IOPS), max(1, config.IOPS/10))
-	metadataRuntime.requests = make(chan metadataScanRequest, 1024)
-	metadataRuntime.started = true
+	metadataRuntime.tiers[fileSystem] = tier
 
 	ready := make(chan metadataScanRequest, 1024)
 	done := make(chan string, config.ParallelScans)
-	go metadataDispatchScans(metadataRuntime.requests, ready, done)
+	go metadataDispatchScans(tier.requests, ready, done)
 	for range config.ParallelScans {
 		go func() {
 			for request := range ready {
 				log.Info("Beginning scan of: %v", request.internalPath)
-				err := metadataDoScan(request.internalPath, request.drive)
+				err := metadataDoScan(tier, request.internalPath, request.drive)
 				for _, completion := range request.completions {
 					completion(err)
 				}
@@ -345,6 +364,7 @@ func metadataStartRuntime() {
 			}
 		}()
 	}
+	return tier, true
 }
 
 func metadataDispatchScans(requests <-chan metadataScanRequest, ready chan<- metadataScanRequest, done <-chan string) {
@@ -406,11 +426,12 @@ func metadataPathContains(parent, child string) bool {
 }
 
 // metadataHasCompleteCoverage returns true if a complete drive-root scan has been published.
-func metadataHasCompleteCoverage(driveID string) bool {
+func metadataHasCompleteCoverage(drive *orc.Drive) bool {
+	driveID := drive.Id
 	if _, ok := metadataCompleteCoverage.Load(driveID); ok {
 		return true
 	}
-	databasePath := metadataDatabasePath(driveID)
+	databasePath := metadataDatabasePath(drive)
 	db, release, err := metadataAcquireDatabase(databasePath, false)
 	if err != nil {
 		return false
@@ -436,7 +457,7 @@ func metadataHasCompleteCoverage(driveID string) bool {
 	return true
 }
 
-func metadataDoScan(internalPath string, drive *orc.Drive) error {
+func metadataDoScan(tier *metadataTierRuntime, internalPath string, drive *orc.Drive) error {
 	if _, deleted := metadataDeletedDrives.Load(drive.Id); deleted {
 		return errors.New("metadata scan drive has been deleted")
 	}
@@ -444,13 +465,9 @@ func metadataDoScan(internalPath string, drive *orc.Drive) error {
 	if !ok {
 		return errors.New("unable to resolve metadata scan drive")
 	}
-	metadataRuntime.Lock()
-	limiter := metadataRuntime.limiter
-	entriesPerTable := metadataRuntime.config.EntriesPerSSTable
-	metadataRuntime.Unlock()
 	startedAt := time.Now()
 	metadataRecordScanStarted(drive.Id)
-	err := metadataScanAndPublish(context.Background(), internalPath, basePath, metadataDatabasePath(drive.Id), limiter, entriesPerTable)
+	err := metadataScanAndPublish(context.Background(), internalPath, basePath, metadataDatabasePath(drive), tier.limiter, tier.config.EntriesPerSSTable)
 	metadataRecordScanFinished(drive.Id, time.Since(startedAt), err)
 	if err != nil {
 		if !metadataExpectedLiveTreeError(err) && !errors.Is(err, errMetadataScanInvalidated) {
@@ -459,7 +476,7 @@ func metadataDoScan(internalPath string, drive *orc.Drive) error {
 	} else if filepath.Clean(internalPath) == filepath.Clean(basePath) {
 		metadataCompleteCoverage.Store(drive.Id, true)
 	}
-	if err == nil && shared.ServiceConfig.FileSystem.MetadataCatalog.EnableIntegration {
+	if err == nil && shared.FileSystemForDrive(drive).MetadataCatalog.EnableIntegration {
 		metadataReportAccounting(drive)
 	}
 	return err
@@ -478,10 +495,11 @@ func metadataReportAccounting(drive *orc.Drive) {
 	reportUsedStorage(*drive, int64(min(sizeInGb, uint64(math.MaxInt64))))
 }
 
-func MetadataDeleteCatalog(driveID string) {
+func MetadataDeleteCatalog(drive *orc.Drive) {
+	driveID := drive.Id
 	metadataDeletedDrives.Store(driveID, true)
 	metadataCompleteCoverage.Delete(driveID)
-	databasePath := metadataDatabasePath(driveID)
+	databasePath := metadataDatabasePath(drive)
 	go func() {
 		metadataWaitForNameRefresh(databasePath)
 		for util.IsAlive {
@@ -497,9 +515,11 @@ func MetadataDeleteCatalog(driveID string) {
 	}()
 }
 
-func metadataDatabasePath(driveID string) string {
-	mnt := shared.ServiceConfig.FileSystem.MountPoint
-	return filepath.Join(mnt, "metadata-catalog", driveID)
+// metadataDatabasePath returns the location of the catalog of a drive. Catalogs are stored on the file system of the
+// drive, such that every tier holds the catalogs of its own drives.
+func metadataDatabasePath(drive *orc.Drive) string {
+	mnt := shared.FileSystemForDrive(drive).MountPoint
+	return filepath.Join(mnt, "metadata-catalog", drive.Id)
 }
 
 type metadataAggregate struct {
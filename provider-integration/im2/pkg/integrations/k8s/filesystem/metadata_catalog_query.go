@@ -169,7 +169,7 @@ func MetadataLookupDirectoryStats(ucloudPath string) (result MetadataDirectorySt
 		return result, false, err
 	}
 
-	db, closeDB, err := metadataOpenDatabaseForQuery(drive.Id)
+	db, closeDB, err := metadataOpenDatabaseForQuery(drive)
 	if err != nil {
 		if errors.Is(err, errMetadataCatalogNotFound) {
 			return result, false, nil
@@ -227,7 +227,7 @@ func metadataLookupRecursiveSizes(drive *orc.Drive, internalPaths []string) (res
 	if !ok {
 		return result, fmt.Errorf("drive %q is not available on this provider", drive.Id)
 	}
-	db, closeDB, err := metadataOpenDatabaseForQuery(drive.Id)
+	db, closeDB, err := metadataOpenDatabaseForQuery(drive)
 	if err != nil {
 		if errors.Is(err, errMetadataCatalogNotFound) {
 			return result, nil
@@ -275,7 +275,7 @@ func MetadataSearchByNamePrefix(ctx context.Context, folder, prefix string, limi
 		return false, err
 	}
 
-	db, closeDB, err := metadataOpenDatabaseForQuery(driveID)
+	db, closeDB, err := metadataOpenDatabaseForQuery(drive)
 	if err != nil {
 		if errors.Is(err, errMetadataCatalogNotFound) {
 			return false, nil
@@ -406,6 +406,82 @@ func metadataSearchByNamePrefixInDB(ctx context.Context, db *pebble.DB, driveID
 	})
 }
 
+// MetadataFindStaleFiles streams regular files below a UCloud folder which have not been used since the cutoff, in path
+// order. The catalog is only as fresh as the latest scan, so callers must check the file again before acting on it.
+func MetadataFindStaleFiles(ctx context.Context, folder string, cutoff time.Time, emit func(MetadataSearchResult) bool) (observedAt int64, found bool, err error) {
+	startedAt := time.Now()
+	driveID := ""
+	defer func() { metadataRecordQuery(driveID, "stale_files", time.Since(startedAt), err) }()
+	internalPath, ok, drive := UCloudToInternal(folder)
+	if !ok {
+		return 0, false, fmt.Errorf("unknown UCloud path %q", folder)
+	}
+	driveID = drive.Id
+	driveRoot, ok, _ := DriveToLocalPath(drive)
+	if !ok {
+		return 0, false, fmt.Errorf("drive %q is not available on this provider", drive.Id)
+	}
+	folderComponents, err := metadataComponentsBelowDrive(internalPath, driveRoot)
+	if err != nil {
+		return 0, false, err
+	}
+
+	db, closeDB, err := metadataOpenDatabaseForQuery(drive)
+	if err != nil {
+		if errors.Is(err, errMetadataCatalogNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	defer closeDB()
+	root, found, err := metadataReadEntry(db, metadataPathKey(nil))
+	if err != nil || !found {
+		return 0, found, err
+	}
+	return root.AggregateObservedAt, true, metadataFindStaleFilesInDB(ctx, db, driveID, folderComponents, cutoff.UnixNano(), emit)
+}
+
+func metadataFindStaleFilesInDB(ctx context.Context, db *pebble.DB, driveID string, folderComponents []string, cutoff int64, emit func(MetadataSearchResult) bool) error {
+	lower := metadataPathKey(folderComponents)
+	iter, err := db.NewIter(&pebble.IterOptions{LowerBound: lower, UpperBound: metadataPrefixSuccessor(lower)})
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	for valid := iter.First(); valid; valid = iter.Next() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		var entry MetadataEntry
+		if err = entry.Decode(iter.Value()); err != nil {
+			return fmt.Errorf("decode metadata at %x: %w", iter.Key(), err)
+		}
+		if entry.EntryType != MetaEntryRegular || metadataLastUsed(entry) >= cutoff {
+			continue
+		}
+		components, keyErr := metadataPathComponents(iter.Key())
+		if keyErr != nil {
+			return keyErr
+		}
+		path := "/" + driveID + "/" + strings.Join(components, "/")
+		if !emit(MetadataSearchResult{Path: path, Entry: entry}) {
+			break
+		}
+	}
+	return iter.Error()
+}
+
+// metadataLastUsed returns the latest of the access and modification time. File systems mounted with relatime or
+// noatime can report an access time which is older than the last write.
+func metadataLastUsed(entry MetadataEntry) int64 {
+	if entry.AccessTime.Present && entry.AccessTime.Value > entry.ModificationTime {
+		return entry.AccessTime.Value
+	}
+	return entry.ModificationTime
+}
+
 func metadataComponentsContain(parent, child []string) bool {
 	if len(parent) > len(child) {
 		return false
@@ -421,10 +497,11 @@ func metadataComponentsContain(parent, child []string) bool {
 func MetadataCatalogMetricsForDrive(driveID string) (result MetadataCatalogMetrics, err error) {
 	startedAt := time.Now()
 	defer func() { metadataRecordQuery(driveID, "metrics", time.Since(startedAt), err) }()
-	if _, ok := ResolveDrive(driveID); !ok {
+	drive, ok := ResolveDrive(driveID)
+	if !ok {
 		return result, fmt.Errorf("unknown drive %q", driveID)
 	}
-	db, closeDB, err := metadataOpenDatabaseForQuery(driveID)
+	db, closeDB, err := metadataOpenDatabaseForQuery(drive)
 	if err != nil {
 		if errors.Is(err, errMetadataCatalogNotFound) {
 			metadataCollectedMetrics.Lock()
@@ -476,8 +553,8 @@ func metadataPathKeyFromSearchIndexKey(key []byte, keyspace byte) ([]byte, error
 	return append([]byte{MetaKeyspacePath}, pathSuffix...), nil
 }
 
-func metadataOpenDatabaseForQuery(driveID string) (*pebble.DB, func(), error) {
-	databasePath := metadataDatabasePath(driveID)
+func metadataOpenDatabaseForQuery(drive *orc.Drive) (*pebble.DB, func(), error) {
+	databasePath := metadataDatabasePath(drive)
 	return metadataAcquireDatabase(databasePath, false)
 }
 
@@ -7,7 +7,9 @@ import (
 	"sort"
 	"time"
 
+	"ucloud.dk/pkg/integrations/k8s/shared"
 	db "ucloud.dk/shared/pkg/database"
+	orc "ucloud.dk/shared/pkg/orchestrators"
 	"ucloud.dk/shared/pkg/util"
 )
 
@@ -130,7 +132,7 @@ func metadataColdScanCandidates() []metadataScanCandidate {
 	result := make([]metadataScanCandidate, 0, len(driveIDs))
 	for _, driveID := range driveIDs {
 		drive, ok := ResolveDrive(driveID)
-		if !ok {
+		if !ok || !metadataCatalogEnabled(drive) {
 			continue
 		}
 		if _, ok, resolved := DriveToLocalPath(drive); !ok || resolved.Id != drive.Id {
@@ -151,7 +153,7 @@ func metadataResolveScanCandidate(ucloudPath string, kind ActivityKind, interval
 		return metadataScanCandidate{}, false
 	}
 	driveRoot, ok, resolvedDrive := DriveToLocalPath(drive)
-	if !ok || resolvedDrive == nil {
+	if !ok || resolvedDrive == nil || !metadataCatalogEnabled(resolvedDrive) {
 		return metadataScanCandidate{}, false
 	}
 
@@ -175,6 +177,11 @@ func metadataResolveScanCandidate(ucloudPath string, kind ActivityKind, interval
 	return metadataScanCandidate{driveID: resolvedDrive.Id, ucloudPath: canonicalPath, interval: interval}, true
 }
 
+func metadataCatalogEnabled(drive *orc.Drive) bool {
+	catalog := shared.FileSystemForDrive(drive).MetadataCatalog
+	return catalog.Enabled || catalog.EnableIntegration
+}
+
 func metadataClampScanRoot(driveRoot, scanRoot string) string {
 	driveRoot = filepath.Clean(driveRoot)
 	scanRoot = filepath.Clean(scanRoot)
@@ -12,6 +12,10 @@ import (
 
 	"github.com/cockroachdb/pebble/v2"
 	"golang.org/x/time/rate"
+	cfg "ucloud.dk/pkg/config"
+	"ucloud.dk/pkg/integrations/k8s/shared"
+	apm "ucloud.dk/shared/pkg/accounting"
+	orc "ucloud.dk/shared/pkg/orchestrators"
 	"ucloud.dk/shared/pkg/util"
 )
 
@@ -45,6 +49,29 @@ func TestMetadataEntryRoundTrip(t *testing.T) {
 	}
 }
 
+func TestMetadataDatabasePathFollowsTier(t *testing.T) {
+	old := shared.ServiceConfig
+	t.Cleanup(func() { shared.ServiceConfig = old })
+
+	storage := cfg.KubernetesFileSystem{Name: "storage", MountPoint: "/mnt/storage"}
+	scratch := cfg.KubernetesFileSystem{Name: "scratch", MountPoint: "/mnt/scratch"}
+	shared.ServiceConfig = &cfg.ServicesConfigurationKubernetes{}
+	shared.ServiceConfig.FileSystem = storage
+	shared.ServiceConfig.FileSystems = map[string]cfg.KubernetesFileSystem{storage.Name: storage, scratch.Name: scratch}
+
+	drive := &orc.Drive{}
+	drive.Id = "42"
+	drive.Specification.Product = apm.ProductReference{Id: "scratch", Category: "scratch"}
+	if path := metadataDatabasePath(drive); path != "/mnt/scratch/metadata-catalog/42" {
+		t.Fatalf("expected catalog on the scratch tier, got %s", path)
+	}
+
+	drive.Specification.Product.Category = "storage"
+	if path := metadataDatabasePath(drive); path != "/mnt/storage/metadata-catalog/42" {
+		t.Fatalf("expected catalog on the default tier, got %s", path)
+	}
+}
+
 func TestMetadataEntryDecodeRejectsMalformedValues(t *testing.T) {
 	entry := MetadataEntry{EntryType: MetaEntryRegular}
 	encoded := entry.Encode()
@@ -51,7 +51,7 @@ func (q *filesVisualizationQueue) Pop() any {
 }
 
 func visualize(request orc.FilesProviderVisualizeRequest) (orc.FilesVisualizeResponse, *util.HttpError) {
-	config := shared.ServiceConfig.FileSystem.MetadataCatalog
+	config := shared.FileSystemForDrive(&request.ResolvedCollection).MetadataCatalog
 	if !config.Enabled || !config.EnableIntegration {
 		return orc.FilesVisualizeResponse{}, util.HttpErr(http.StatusNotFound, "storage visualization is not supported")
 	}
@@ -110,7 +110,7 @@ func metadataVisualize(ucloudPath string, limit int, maxDuration time.Duration)
 	if err != nil {
 		return nil, 0, 0, 0, false, false, err
 	}
-	db, closeDB, err := metadataOpenDatabaseForQuery(drive.Id)
+	db, closeDB, err := metadataOpenDatabaseForQuery(drive)
 	if err != nil {
 		if errors.Is(err, errMetadataCatalogNotFound) {
 			return []orc.FilesVisualizeEntry{}, 0, 0, 0, false, false, nil
@@ -75,6 +75,11 @@ func loopMonitoring() time.Duration {
 				continue
 			}
 
+			if shared.FileSystemForDrive(&drive).MetadataCatalog.EnableIntegration {
+				// Accounting for these drives is driven by the metadata catalog
+				continue
+			}
+
 			driveScanQueue <- drive
 		}
 	}
@@ -120,7 +125,7 @@ func scanDrive(drive orc.Drive) {
 
 	sizeToReport := int64(0)
 
-	method := shared.ServiceConfig.FileSystem.ScanMethod
+	method := shared.FileSystemForDrive(&drive).ScanMethod
 	switch method.Type {
 	case config.K8sScanMethodTypeExtendedAttribute:
 		fd, ok := OpenFile(internalPath, unix.O_RDONLY, 0)
@@ -218,17 +223,19 @@ func reportUsedStorage(drive orc.Drive, sizeInGb int64) {
 }
 
 func RequestScan(driveId string) {
-	if shared.ServiceConfig.FileSystem.MetadataCatalog.EnableIntegration {
+	drive, ok := ctrl.DriveRetrieve(driveId)
+	if !ok {
+		return
+	}
+
+	if shared.FileSystemForDrive(drive).MetadataCatalog.EnableIntegration {
 		MetadataSubmitScanRequest("/" + driveId)
 		return
 	}
 
 	// NOTE(Dan): This doesn't currently set the submitted_at timestamp on purpose. If it turns out that this is a bad
 	// idea then it should be changed.
-	drive, ok := ctrl.DriveRetrieve(driveId)
-	if ok {
-		go func() {
-			driveScanQueue <- *drive
-		}()
-	}
+	go func() {
+		driveScanQueue <- *drive
+	}()
 }
@@ -0,0 +1,314 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	cfg "ucloud.dk/pkg/config"
+	"ucloud.dk/pkg/integrations/k8s/shared"
+	db "ucloud.dk/shared/pkg/database"
+	fnd "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
+	orc "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Retention policies delete files which have not been used for a configurable number of days. Candidates are found
+// through the metadata catalog, which avoids walking the file system. Deletion happens in cycles: owners are warned
+// about every file which is about to expire, and a file is only deleted once a warning covering it is at least
+// WarningDays old. As a result, files are deleted between MaxAgeDays and MaxAgeDays + WarningDays after they were last
+// used.
+
+const (
+	retentionPollInterval       = time.Hour
+	retentionListingMaxEntries  = 1000
+	retentionListingMaxDuration = 10 * time.Second
+	retentionMaxPurgesPerPass   = 10_000
+)
+
+func RetentionStart() {
+	go func() {
+		for util.IsAlive {
+			retentionProcessDrives(time.Now())
+			time.Sleep(retentionPollInterval)
+		}
+	}()
+}
+
+func retentionPolicyForDrive(drive *orc.Drive) (cfg.KubernetesRetentionPolicy, bool) {
+	fs := shared.FileSystemForDrive(drive)
+	return fs.Retention, fs.Retention.Enabled && fs.MetadataCatalog.Enabled
+}
+
+func retentionDays(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// retentionWarningCutoff returns the point in time before which files must have been used last to be covered by a
+// warning sent at the given time.
+func retentionWarningCutoff(policy cfg.KubernetesRetentionPolicy, warnedAt time.Time) time.Time {
+	return warnedAt.Add(-retentionDays(policy.MaxAgeDays - policy.WarningDays))
+}
+
+// retentionPurgeCutoff returns the cutoff for files which can be deleted now. Only files covered by a warning which is
+// at least WarningDays old can be deleted. Warnings must be sorted in ascending order.
+func retentionPurgeCutoff(policy cfg.KubernetesRetentionPolicy, warnings []time.Time, now time.Time) (time.Time, bool) {
+	for i := len(warnings) - 1; i >= 0; i-- {
+		if !warnings[i].After(now.Add(-retentionDays(policy.WarningDays))) {
+			return retentionWarningCutoff(policy, warnings[i]), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// retentionShouldWarn limits warnings to one per warning period. Warnings must be sorted in ascending order.
+func retentionShouldWarn(policy cfg.KubernetesRetentionPolicy, warnings []time.Time, now time.Time) bool {
+	if len(warnings) == 0 {
+		return true
+	}
+	return !warnings[len(warnings)-1].After(now.Add(-retentionDays(policy.WarningDays)))
+}
+
+func retentionProcessDrives(now time.Time) {
+	driveIds := db.NewTx[[]string](func(tx *db.Transaction) []string {
+		rows := db.Select[struct{ DriveId string }](tx, `
+			select drive_id
+			from tracked_drives
+			where product_id != 'share'
+		`, db.Params{})
+		result := make([]string, 0, len(rows))
+		for _, row := range rows {
+			result = append(result, row.DriveId)
+		}
+		return result
+	})
+
+	for _, driveId := range driveIds {
+		drive, ok := ResolveDrive(driveId)
+		if !ok {
+			continue
+		}
+		policy, ok := retentionPolicyForDrive(drive)
+		if !ok {
+			continue
+		}
+		if _, ok, resolved := DriveToLocalPath(drive); !ok || resolved.Id != drive.Id {
+			continue
+		}
+		retentionProcessDrive(drive, policy, now)
+	}
+}
+
+func retentionProcessDrive(drive *orc.Drive, policy cfg.KubernetesRetentionPolicy, now time.Time) {
+	warnings := retentionRetrieveWarnings(drive.Id)
+
+	purged := map[string]util.Empty{}
+	if cutoff, ok := retentionPurgeCutoff(policy, warnings, now); ok {
+		purged = retentionPurge(drive, cutoff)
+		retentionDeleteWarningsBefore(drive.Id, now.Add(-retentionDays(policy.WarningDays)))
+	}
+
+	if !retentionShouldWarn(policy, warnings, now) {
+		return
+	}
+
+	fileCount := uint64(0)
+	sizeInBytes := uint64(0)
+	_, _, err := MetadataFindStaleFiles(context.Background(), "/"+drive.Id, retentionWarningCutoff(policy, now), func(result MetadataSearchResult) bool {
+		if _, deleted := purged[result.Path]; !deleted {
+			fileCount++
+			sizeInBytes += result.Entry.LogicalSize
+		}
+		return true
+	})
+	if err != nil {
+		log.Warn("Retention: unable to find expiring files in %s: %s", drive.Id, err)
+		return
+	}
+	if fileCount == 0 {
+		return
+	}
+
+	_, herr := orc.DrivesControlNotifyRetention.Invoke(fnd.BulkRequestOf(orc.DrivesControlNotifyRetentionRequest{
+		Id:          drive.Id,
+		FileCount:   fileCount,
+		SizeInBytes: sizeInBytes,
+		MaxAgeDays:  policy.MaxAgeDays,
+		DeletesAt:   fnd.Timestamp(now.Add(retentionDays(policy.WarningDays))),
+	}))
+	if herr != nil {
+		// Files are not deleted until a warning has been delivered. We try again on the next pass.
+		log.Warn("Retention: unable to notify owners of %s: %s", drive.Id, herr)
+		return
+	}
+
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				insert into fs_retention_warnings(drive_id, warned_at, file_count, size_in_bytes)
+				select :drive_id, :warned_at, :file_count, :size_in_bytes
+				from tracked_drives
+				where drive_id = :drive_id
+				on conflict do nothing
+			`,
+			db.Params{
+				"drive_id":      drive.Id,
+				"warned_at":     now,
+				"file_count":    fileCount,
+				"size_in_bytes": sizeInBytes,
+			},
+		)
+	})
+}
+
+// retentionPurge deletes the files which were last used before the cutoff. Every candidate is checked against the file
+// system and the activity log before it is deleted, since the catalog might be older than the latest use of the file.
+func retentionPurge(drive *orc.Drive, cutoff time.Time) map[string]util.Empty {
+	var candidates []string
+	_, _, err := MetadataFindStaleFiles(context.Background(), "/"+drive.Id, cutoff, func(result MetadataSearchResult) bool {
+		candidates = append(candidates, result.Path)
+		return len(candidates) < retentionMaxPurgesPerPass
+	})
+	if err != nil {
+		log.Warn("Retention: unable to find expired files in %s: %s", drive.Id, err)
+		return nil
+	}
+
+	purged := map[string]util.Empty{}
+	var targets []ActivityTarget
+	for _, ucloudPath := range candidates {
+		internalPath, ok, _ := UCloudToInternal(ucloudPath)
+		if !ok {
+			continue
+		}
+
+		info, err := os.Lstat(internalPath)
+		if err != nil {
+			continue
+		}
+		entry := metadataEntryFromFileInfo(info, 0)
+		if entry.EntryType != MetaEntryRegular || metadataLastUsed(entry) >= cutoff.UnixNano() {
+			continue
+		}
+		if ActivityClassifyHeat(ucloudPath) != ActivityHeatCold {
+			continue
+		}
+
+		if herr := DoDeleteFile(internalPath); herr != nil {
+			log.Warn("Retention: unable to delete %s: %s", ucloudPath, herr)
+			continue
+		}
+		purged[ucloudPath] = util.Empty{}
+		targets = append(targets, ActivityTarget{UCloudPath: ucloudPath})
+	}
+
+	if len(targets) > 0 {
+		log.Info("Retention: deleted %d expired files from %s", len(targets), drive.Id)
+		ActivityRecord(rpc.ActorSystem, ActivityEvent{
+			Kind:      ActivityDirect,
+			Operation: ActivityOperationDelete,
+			Targets:   targets,
+		})
+		RequestScan(drive.Id)
+	}
+	return purged
+}
+
+func retentionRetrieveWarnings(driveId string) []time.Time {
+	return db.NewTx(func(tx *db.Transaction) []time.Time {
+		rows := db.Select[struct{ WarnedAt time.Time }](
+			tx,
+			`
+				select warned_at
+				from fs_retention_warnings
+				where drive_id = :drive_id
+				order by warned_at
+			`,
+			db.Params{
+				"drive_id": driveId,
+			},
+		)
+
+		result := make([]time.Time, 0, len(rows))
+		for _, row := range rows {
+			result = append(result, row.WarnedAt)
+		}
+		return result
+	})
+}
+
+// retentionDeleteWarningsBefore removes warnings which have been superseded by a newer warning that can be acted upon.
+func retentionDeleteWarningsBefore(driveId string, before time.Time) {
+	db.NewTx0(func(tx *db.Transaction) {
+		db.Exec(
+			tx,
+			`
+				delete from fs_retention_warnings
+				where
+					drive_id = :drive_id
+					and warned_at < (
+						select max(warned_at)
+						from fs_retention_warnings
+						where drive_id = :drive_id and warned_at <= :before
+					)
+			`,
+			db.Params{
+				"drive_id": driveId,
+				"before":   before,
+			},
+		)
+	})
+}
+
+// retention implements the dry-run listing of files which will be deleted within the warning period.
+func retention(request orc.FilesProviderRetentionRequest) (orc.FilesRetentionResponse, *util.HttpError) {
+	policy, ok := retentionPolicyForDrive(&request.ResolvedCollection)
+	if !ok {
+		return orc.FilesRetentionResponse{}, util.HttpErr(http.StatusNotFound, "this drive has no retention policy")
+	}
+
+	response := orc.FilesRetentionResponse{
+		MaxAgeDays:  policy.MaxAgeDays,
+		WarningDays: policy.WarningDays,
+		Entries:     []orc.FilesRetentionEntry{},
+		Complete:    true,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), retentionListingMaxDuration)
+	defer cancel()
+
+	cutoff := retentionWarningCutoff(policy, time.Now())
+	observedAt, found, err := MetadataFindStaleFiles(ctx, request.Path, cutoff, func(result MetadataSearchResult) bool {
+		response.FileCount++
+		response.SizeInBytes += result.Entry.LogicalSize
+		if len(response.Entries) >= retentionListingMaxEntries {
+			response.Complete = false
+			return true
+		}
+
+		lastUsed := time.Unix(0, metadataLastUsed(result.Entry))
+		response.Entries = append(response.Entries, orc.FilesRetentionEntry{
+			Path:           result.Path,
+			SizeInBytes:    result.Entry.LogicalSize,
+			LastAccessedAt: fnd.Timestamp(lastUsed),
+			ExpiresAt:      fnd.Timestamp(lastUsed.Add(retentionDays(policy.MaxAgeDays))),
+		})
+		return true
+	})
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		response.Complete = false
+	} else if err != nil {
+		return orc.FilesRetentionResponse{}, util.ServerHttpError("failed to query storage metadata")
+	}
+
+	if found {
+		response.LastUpdatedAt.Set(fnd.Timestamp(time.Unix(0, observedAt)))
+	}
+	return response, nil
+}
@@ -0,0 +1,98 @@
+package filesystem
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/pebble/v2"
+	cfg "ucloud.dk/pkg/config"
+	"ucloud.dk/shared/pkg/util"
+)
+
+func TestMetadataFindStaleFilesUsesLatestOfAccessAndModification(t *testing.T) {
+	db, err := pebble.Open(filepath.Join(t.TempDir(), "catalog"), &pebble.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	entries := map[string]MetadataEntry{
+		"":                      {EntryType: MetaEntryDirectory, ModificationTime: 10},
+		"old":                   {EntryType: MetaEntryDirectory, ModificationTime: 10},
+		"old/data.bin":          {EntryType: MetaEntryRegular, LogicalSize: 10, ModificationTime: 10, AccessTime: util.OptValue[int64](20)},
+		"old/recently-read.bin": {EntryType: MetaEntryRegular, LogicalSize: 20, ModificationTime: 10, AccessTime: util.OptValue[int64](500)},
+		"old/no-atime.bin":      {EntryType: MetaEntryRegular, LogicalSize: 30, ModificationTime: 30},
+		"new/written.bin":       {EntryType: MetaEntryRegular, LogicalSize: 40, ModificationTime: 500, AccessTime: util.OptValue[int64](20)},
+		"old/link":              {EntryType: MetaEntrySymlink, ModificationTime: 10},
+	}
+	for path, entry := range entries {
+		if err = db.Set(metadataPathKey(metadataRelativeComponents(path)), entry.Encode(), pebble.NoSync); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var paths []string
+	err = metadataFindStaleFilesInDB(context.Background(), db, "drive-1", nil, 100, func(result MetadataSearchResult) bool {
+		paths = append(paths, result.Path)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPaths := []string{"/drive-1/old/data.bin", "/drive-1/old/no-atime.bin"}
+	if len(paths) != len(wantPaths) {
+		t.Fatalf("expected %v, got %v", wantPaths, paths)
+	}
+	for i := range wantPaths {
+		if paths[i] != wantPaths[i] {
+			t.Fatalf("expected %v, got %v", wantPaths, paths)
+		}
+	}
+
+	paths = nil
+	err = metadataFindStaleFilesInDB(context.Background(), db, "drive-1", []string{"new"}, 100, func(result MetadataSearchResult) bool {
+		paths = append(paths, result.Path)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 0 {
+		t.Fatalf("expected no stale files below new, got %v", paths)
+	}
+}
+
+func TestRetentionOnlyPurgesFilesCoveredByOldWarnings(t *testing.T) {
+	policy := cfg.KubernetesRetentionPolicy{Enabled: true, MaxAgeDays: 30, WarningDays: 7}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	if _, ok := retentionPurgeCutoff(policy, nil, now); ok {
+		t.Fatalf("nothing should be purged before a warning has been sent")
+	}
+	if !retentionShouldWarn(policy, nil, now) {
+		t.Fatalf("expected a warning when none have been sent")
+	}
+
+	recent := []time.Time{now.Add(-6 * day)}
+	if _, ok := retentionPurgeCutoff(policy, recent, now); ok {
+		t.Fatalf("nothing should be purged while the warning period is running")
+	}
+	if retentionShouldWarn(policy, recent, now) {
+		t.Fatalf("expected at most one warning per warning period")
+	}
+
+	warnings := []time.Time{now.Add(-8 * day), now.Add(-day)}
+	cutoff, ok := retentionPurgeCutoff(policy, warnings, now)
+	if !ok {
+		t.Fatalf("expected files covered by the first warning to be purged")
+	}
+	if want := now.Add(-8 * day).Add(-23 * day); !cutoff.Equal(want) {
+		t.Fatalf("expected cutoff %v, got %v", want, cutoff)
+	}
+	if cutoff.After(now.Add(-30 * day)) {
+		t.Fatalf("files must not be purged before they are older than the maximum age")
+	}
+}
@@ -14,6 +14,7 @@ import (
 	k8score "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	k8smeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cfg "ucloud.dk/pkg/config"
 	ctrl "ucloud.dk/pkg/controller"
 	"ucloud.dk/pkg/integrations/k8s/shared"
 	db "ucloud.dk/shared/pkg/database"
@@ -29,6 +30,9 @@ const (
 	TaskTypeCopy       TaskType = "copy"
 	TaskTypeTransfer   TaskType = "file_transfer"
 	TaskTypeMarkItDown TaskType = "markitdown"
+
+	TaskTypeArchiveCompress TaskType = "archive_compress"
+	TaskTypeArchiveExtract  TaskType = "archive_extract"
 )
 
 type TaskMount struct {
@@ -47,6 +51,10 @@ type TaskSpec struct {
 	TransferEndpoint string
 	ConflictPolicy   string
 
+	ArchiveFormat string
+	ArchiveRoot   string // Name of the top-level entry when creating an archive
+	SizeLimit     int64  // Maximum number of bytes the task may write, zero if unlimited
+
 	CreationState struct {
 		Username string
 		Icon     string
@@ -370,7 +378,7 @@ func TaskSubmitWithInfo(spec TaskSpec) (TaskSubmission, *util.HttpError) {
 
 	taskSelector := shared.ServiceConfig.Compute.TaskNodeSelector
 
-	storageVolumeMounts, internalToPod := resolveTaskMounts(spec)
+	storageVolumes, storageVolumeMounts, internalToPod := resolveTaskMounts(spec)
 	sourcePath := mapUCloudPathToTaskPath(spec.Source, internalToPod)
 	destinationPath := mapUCloudPathToTaskPath(spec.Destination, internalToPod)
 
@@ -387,22 +395,12 @@ func TaskSubmitWithInfo(spec TaskSpec) (TaskSubmission, *util.HttpError) {
 				Spec: k8score.PodSpec{
 					AutomountServiceAccountToken: util.Pointer(false),
 					EnableServiceLinks:           util.Pointer(false),
-					Volumes: []k8score.Volume{
-						{
-							Name: "ucloud-filesystem",
-							VolumeSource: k8score.VolumeSource{
-								PersistentVolumeClaim: &k8score.PersistentVolumeClaimVolumeSource{
-									ClaimName: shared.ServiceConfig.FileSystem.ClaimName,
-								},
-							},
-						},
-						{
-							Name: "ucloud-opt",
-							VolumeSource: k8score.VolumeSource{
-								EmptyDir: &k8score.EmptyDirVolumeSource{},
-							},
+					Volumes: append(storageVolumes, k8score.Volume{
+						Name: "ucloud-opt",
+						VolumeSource: k8score.VolumeSource{
+							EmptyDir: &k8score.EmptyDirVolumeSource{},
 						},
-					},
+					}),
 					InitContainers: []k8score.Container{
 						{
 							Name:            "ucloud-executables",
@@ -442,6 +440,9 @@ func TaskSubmitWithInfo(spec TaskSpec) (TaskSubmission, *util.HttpError) {
 								{Name: taskEnvTransferEndpoint, Value: spec.TransferEndpoint},
 								{Name: taskEnvTaskToken, Value: spec.TaskToken},
 								{Name: taskEnvConflictPolicy, Value: spec.ConflictPolicy},
+								{Name: taskEnvArchiveFormat, Value: spec.ArchiveFormat},
+								{Name: taskEnvArchiveRoot, Value: spec.ArchiveRoot},
+								{Name: taskEnvSizeLimit, Value: fmt.Sprint(spec.SizeLimit)},
 							},
 							Resources: k8score.ResourceRequirements{
 								Limits: map[k8score.ResourceName]resource.Quantity{
@@ -564,29 +565,27 @@ func taskJobDone(job *k8sbatch.Job) (bool, bool) {
 	return false, false
 }
 
-func resolveTaskMounts(spec TaskSpec) ([]k8score.VolumeMount, map[string]string) {
+func resolveTaskMounts(spec TaskSpec) ([]k8score.Volume, []k8score.VolumeMount, map[string]string) {
 	type candidateMount struct {
 		InternalPath string
+		FileSystem   cfg.KubernetesFileSystem
 		SubPath      string
 		PodPath      string
 		ReadOnly     bool
 	}
 
-	resolvedMounts := map[string][]util.Tuple2[string, bool]{}
-	internalToPod := map[string]string{}
-
-	internalToSubpath := func(internalPath string) (string, bool) {
-		subpath, ok := strings.CutPrefix(internalPath, filepath.Clean(shared.ServiceConfig.FileSystem.MountPoint)+"/")
-		if !ok {
-			return "", false
-		}
-
-		return subpath, true
+	type resolvedMount struct {
+		FileSystem cfg.KubernetesFileSystem
+		SubPath    string
+		ReadOnly   bool
 	}
 
-	addMount := func(containerPath, subpath string, readOnly bool) {
+	resolvedMounts := map[string][]resolvedMount{}
+	internalToPod := map[string]string{}
+
+	addMount := func(containerPath string, fs cfg.KubernetesFileSystem, subpath string, readOnly bool) {
 		existing, _ := resolvedMounts[containerPath]
-		existing = append(existing, util.Tuple2[string, bool]{subpath, readOnly})
+		existing = append(existing, resolvedMount{FileSystem: fs, SubPath: subpath, ReadOnly: readOnly})
 		resolvedMounts[containerPath] = existing
 	}
 
@@ -596,12 +595,12 @@ func resolveTaskMounts(spec TaskSpec) ([]k8score.VolumeMount, map[string]string)
 			return
 		}
 
-		subpath, ok := internalToSubpath(internalPath)
+		fs, subpath, ok := shared.FileSystemSubPath(internalPath)
 		if !ok {
 			return
 		}
 
-		addMount(containerPath, subpath, readOnly)
+		addMount(containerPath, fs, subpath, readOnly)
 	}
 
 	for _, mount := range spec.Mounts {
@@ -630,35 +629,44 @@ func resolveTaskMounts(spec TaskSpec) ([]k8score.VolumeMount, map[string]string)
 	for containerPath, mounts := range resolvedMounts {
 		if len(mounts) == 1 {
 			mount := mounts[0]
-			internalPath := shared.ServiceConfig.FileSystem.MountPoint + "/" + mount.First
+			internalPath := mount.FileSystem.MountPoint + "/" + mount.SubPath
 			folders = append(folders, candidateMount{
 				InternalPath: internalPath,
-				SubPath:      mount.First,
+				FileSystem:   mount.FileSystem,
+				SubPath:      mount.SubPath,
 				PodPath:      containerPath,
-				ReadOnly:     mount.Second,
+				ReadOnly:     mount.ReadOnly,
 			})
 		} else {
-			slices.SortFunc(mounts, func(a, b util.Tuple2[string, bool]) int {
-				return strings.Compare(a.First, b.First)
+			slices.SortFunc(mounts, func(a, b resolvedMount) int {
+				return strings.Compare(a.FileSystem.MountPoint+"/"+a.SubPath, b.FileSystem.MountPoint+"/"+b.SubPath)
 			})
 
 			for i, mount := range mounts {
 				resolvedContainerPath := fmt.Sprintf("%s-%d", containerPath, i)
-				internalPath := shared.ServiceConfig.FileSystem.MountPoint + "/" + mount.First
+				internalPath := mount.FileSystem.MountPoint + "/" + mount.SubPath
 				folders = append(folders, candidateMount{
 					InternalPath: internalPath,
-					SubPath:      mount.First,
+					FileSystem:   mount.FileSystem,
+					SubPath:      mount.SubPath,
 					PodPath:      resolvedContainerPath,
-					ReadOnly:     mount.Second,
+					ReadOnly:     mount.ReadOnly,
 				})
 			}
 		}
 	}
 
+	// The default file system is always present since the executables are copied from it
+	volumes := []k8score.Volume{taskFileSystemVolume(shared.ServiceConfig.FileSystem)}
 	volumeMounts := make([]k8score.VolumeMount, 0, len(folders))
 	for _, folder := range folders {
+		volumeName := shared.FileSystemVolumeName(folder.FileSystem)
+		if !slices.ContainsFunc(volumes, func(v k8score.Volume) bool { return v.Name == volumeName }) {
+			volumes = append(volumes, taskFileSystemVolume(folder.FileSystem))
+		}
+
 		volumeMounts = append(volumeMounts, k8score.VolumeMount{
-			Name:      "ucloud-filesystem",
+			Name:      volumeName,
 			ReadOnly:  folder.ReadOnly,
 			MountPath: folder.PodPath,
 			SubPath:   folder.SubPath,
@@ -667,7 +675,18 @@ func resolveTaskMounts(spec TaskSpec) ([]k8score.VolumeMount, map[string]string)
 		internalToPod[folder.InternalPath] = folder.PodPath
 	}
 
-	return volumeMounts, internalToPod
+	return volumes, volumeMounts, internalToPod
+}
+
+func taskFileSystemVolume(fs cfg.KubernetesFileSystem) k8score.Volume {
+	return k8score.Volume{
+		Name: shared.FileSystemVolumeName(fs),
+		VolumeSource: k8score.VolumeSource{
+			PersistentVolumeClaim: &k8score.PersistentVolumeClaimVolumeSource{
+				ClaimName: fs.ClaimName,
+			},
+		},
+	}
 }
 
 func mapUCloudPathToTaskPath(ucloudPath string, internalToPod map[string]string) string {
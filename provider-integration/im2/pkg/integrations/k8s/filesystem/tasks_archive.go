@@ -0,0 +1,781 @@
+package filesystem
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/sys/unix"
+	ctrl "ucloud.dk/pkg/controller"
+	fnd "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
+	orc "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Archives
+// =====================================================================================================================
+// Archives are created and extracted by background tasks in the same way as copies. The submission functions in this
+// file resolve the conflict policy and the remaining storage quota. The task processor then reads or writes the archive
+// and reports progress through the task API.
+//
+// Extraction never trusts the content of the archive. Entries with absolute paths or ".." components cause the task to
+// fail, while symbolic links, hard links and special files are skipped. All files are opened relative to the
+// destination folder without following symbolic links. The task also stops once it has written more than the remaining
+// quota of the drive. The quota is only updated periodically, as a result this is not a precise limit, but it prevents
+// small archives from filling up the file system.
+
+func compressFiles(actor rpc.Actor, request orc.FilesProviderArchiveRequest) *util.HttpError {
+	sourceInfo, task, err := archivePrepareTask(request, TaskTypeArchiveCompress)
+	if err != nil {
+		return err
+	}
+
+	if util.Parent(request.SourcePath) != "/" {
+		task.Mounts = append(task.Mounts, TaskMount{UCloudPath: util.Parent(request.SourcePath)})
+		task.ArchiveRoot = util.FileName(request.SourcePath)
+	} else {
+		// Drive roots are mounted directly, which means that they do not have a meaningful name. We use the name of
+		// the archive instead.
+		task.Mounts = append(task.Mounts, TaskMount{UCloudPath: request.SourcePath})
+		task.ArchiveRoot = strings.TrimSuffix(util.FileName(task.Destination), request.Format.Extension())
+	}
+
+	if !sourceInfo.IsDir() && !sourceInfo.Mode().IsRegular() {
+		return util.UserHttpError("Only files and folders can be compressed")
+	}
+
+	task.CreationState.Icon = "heroArchiveBox"
+	return archiveSubmitTask(actor, request, task)
+}
+
+func extractFiles(actor rpc.Actor, request orc.FilesProviderArchiveRequest) *util.HttpError {
+	sourceInfo, task, err := archivePrepareTask(request, TaskTypeArchiveExtract)
+	if err != nil {
+		return err
+	}
+
+	if !sourceInfo.Mode().IsRegular() {
+		return util.UserHttpError("Only files can be extracted")
+	}
+
+	task.Mounts = append(task.Mounts, TaskMount{UCloudPath: util.Parent(request.SourcePath)})
+	task.CreationState.Icon = "heroArchiveBoxArrowDown"
+	return archiveSubmitTask(actor, request, task)
+}
+
+func archivePrepareTask(request orc.FilesProviderArchiveRequest, taskType TaskType) (os.FileInfo, TaskSpec, *util.HttpError) {
+	if !AllowUCloudPathsTogether([]string{request.SourcePath, request.DestinationPath}) {
+		return nil, TaskSpec{}, util.ServerHttpError("Some of these files cannot be used together. One or more are sensitive.")
+	}
+
+	sourceInternalPath, ok1, _ := UCloudToInternal(request.SourcePath)
+	destPath, ok2, destDrive := UCloudToInternal(request.DestinationPath)
+	if !ok1 || !ok2 {
+		return nil, TaskSpec{}, util.HttpErr(http.StatusNotFound, "Unable to process archive. Source or destination is unknown.")
+	}
+
+	sourceInfo, herr := Stat(sourceInternalPath)
+	if herr != nil {
+		return nil, TaskSpec{}, util.HttpErr(http.StatusNotFound, "unknown source file")
+	}
+
+	lockInfo := ctrl.RetrieveResourceLockInfo(destDrive.Resource, request.ResolvedDestinationCollection.Specification.Product)
+	if lockInfo.Locked {
+		return nil, TaskSpec{}, util.PaymentError()
+	}
+
+	// Conflicts are resolved for the top-level destination only. For extraction, REPLACE merges the content into an
+	// existing folder.
+	destInfo, herr := Stat(destPath)
+	if herr == nil {
+		switch request.ConflictPolicy {
+		case orc.WriteConflictPolicyRename, orc.WriteConflictPolicyMergeRename:
+			newPath, err := findAvailableNameOnRename(destPath)
+			if err != nil {
+				return nil, TaskSpec{}, util.UserHttpError("Unable to create archive (too many duplicates?)")
+			}
+			destPath = newPath
+
+		case orc.WriteConflictPolicyReplace:
+			if taskType == TaskTypeArchiveCompress && destInfo.IsDir() {
+				return nil, TaskSpec{}, util.UserHttpError("A folder already exists at the destination")
+			} else if taskType == TaskTypeArchiveExtract && !destInfo.IsDir() {
+				return nil, TaskSpec{}, util.UserHttpError("A file already exists at the destination")
+			}
+
+		default:
+			return nil, TaskSpec{}, util.HttpErr(http.StatusConflict, "The destination already exists")
+		}
+	}
+
+	destination, ok := InternalToUCloudWithDrive(&request.ResolvedDestinationCollection, destPath)
+	if !ok {
+		return nil, TaskSpec{}, util.UserHttpError("Unable to process archive. Unknown drive")
+	}
+
+	destMount := destination
+	if util.Parent(destination) != "/" {
+		destMount = util.Parent(destination)
+	}
+
+	task := TaskSpec{
+		Type:           taskType,
+		Source:         request.SourcePath,
+		Destination:    destination,
+		ConflictPolicy: string(request.ConflictPolicy),
+		ArchiveFormat:  string(request.Format),
+		Mounts:         []TaskMount{{UCloudPath: destMount}},
+	}
+
+	if lockInfo.CombinedQuota > lockInfo.TotalUsage {
+		task.SizeLimit = int64(lockInfo.CombinedQuota-lockInfo.TotalUsage) * 1000 * 1000 * 1000
+	}
+
+	return sourceInfo, task, nil
+}
+
+func archiveSubmitTask(actor rpc.Actor, request orc.FilesProviderArchiveRequest, task TaskSpec) *util.HttpError {
+	task.CreationState.Username = actor.Username
+
+	taskErr := TaskSubmit(task)
+	if taskErr == nil {
+		ActivityRecord(actor, ActivityEvent{
+			Kind:      ActivityDirect,
+			Operation: ActivityOperationCreate,
+			Targets: []ActivityTarget{
+				{UCloudPath: request.SourcePath, Role: "source"},
+				{UCloudPath: task.Destination, Role: "destination"},
+			},
+		})
+	}
+	return taskErr
+}
+
+// Task processing
+// =====================================================================================================================
+
+var errArchiveSizeLimit = errors.New("size limit exceeded")
+
+type archiveProgress struct {
+	Title          util.Option[string]
+	TotalBytes     int64
+	ProcessedBytes int64
+	Files          int64
+	LastUpdate     time.Time
+}
+
+func (p *archiveProgress) Report(force bool) {
+	now := time.Now()
+	if !force && now.Sub(p.LastUpdate) < 500*time.Millisecond {
+		return
+	}
+	p.LastUpdate = now
+
+	percentage := float64(0)
+	if p.TotalBytes > 0 {
+		percentage = min(100, (float64(p.ProcessedBytes)/float64(p.TotalBytes))*100)
+	}
+
+	processed := util.SizeToHumanReadableWithUnit(float64(p.ProcessedBytes))
+	total := util.SizeToHumanReadableWithUnit(float64(p.TotalBytes))
+
+	taskProcessorPostUpdate(fnd.TaskStatus{
+		State: fnd.TaskStateRunning,
+		Title: p.Title,
+		Body: util.OptValue(fmt.Sprintf(
+			"%.2f %v/%.2f %v | %v files",
+			processed.Size,
+			processed.Unit,
+			total.Size,
+			total.Unit,
+			p.Files,
+		)),
+		Progress:           util.OptValue(""),
+		ProgressPercentage: util.OptValue(percentage),
+	})
+}
+
+// archiveLimitWriter counts the bytes written to the underlying writer and fails once the limit is exceeded. A limit of
+// zero disables the check.
+type archiveLimitWriter struct {
+	W       io.Writer
+	Written int64
+	Limit   int64
+}
+
+func (w *archiveLimitWriter) Write(p []byte) (int, error) {
+	if w.Limit > 0 && w.Written+int64(len(p)) > w.Limit {
+		return 0, errArchiveSizeLimit
+	}
+
+	n, err := w.W.Write(p)
+	w.Written += int64(n)
+	return n, err
+}
+
+type archiveCountingReader struct {
+	R        io.Reader
+	Progress *archiveProgress
+}
+
+func (r *archiveCountingReader) Read(p []byte) (int, error) {
+	n, err := r.R.Read(p)
+	r.Progress.ProcessedBytes += int64(n)
+	r.Progress.Report(false)
+	return n, err
+}
+
+type archiveWriter interface {
+	WriteDir(name string, info os.FileInfo) error
+	WriteFile(name string, info os.FileInfo, data io.Reader) error
+	Close() error
+}
+
+type archiveZipWriter struct {
+	W *zip.Writer
+}
+
+func (w *archiveZipWriter) WriteDir(name string, info os.FileInfo) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+
+	header.Name = name + "/"
+	_, err = w.W.CreateHeader(header)
+	return err
+}
+
+func (w *archiveZipWriter) WriteFile(name string, info os.FileInfo, data io.Reader) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+
+	header.Name = name
+	header.Method = zip.Deflate
+	out, err := w.W.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, data)
+	return err
+}
+
+func (w *archiveZipWriter) Close() error {
+	return w.W.Close()
+}
+
+type archiveTarWriter struct {
+	W          *tar.Writer
+	Compressor io.WriteCloser
+}
+
+func (w *archiveTarWriter) writeHeader(name string, info os.FileInfo) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+
+	header.Name = name
+	header.Uname = ""
+	header.Gname = ""
+	return w.W.WriteHeader(header)
+}
+
+func (w *archiveTarWriter) WriteDir(name string, info os.FileInfo) error {
+	return w.writeHeader(name+"/", info)
+}
+
+func (w *archiveTarWriter) WriteFile(name string, info os.FileInfo, data io.Reader) error {
+	err := w.writeHeader(name, info)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(w.W, data, info.Size())
+	return err
+}
+
+func (w *archiveTarWriter) Close() error {
+	err := w.W.Close()
+	if w.Compressor != nil {
+		err = errors.Join(err, w.Compressor.Close())
+	}
+	return err
+}
+
+func archiveNewWriter(format orc.ArchiveFormat, output io.Writer) (archiveWriter, error) {
+	switch format {
+	case orc.ArchiveFormatZip:
+		return &archiveZipWriter{W: zip.NewWriter(output)}, nil
+
+	case orc.ArchiveFormatTar:
+		return &archiveTarWriter{W: tar.NewWriter(output)}, nil
+
+	case orc.ArchiveFormatTarGz:
+		compressor := gzip.NewWriter(output)
+		return &archiveTarWriter{W: tar.NewWriter(compressor), Compressor: compressor}, nil
+
+	case orc.ArchiveFormatTarZst:
+		compressor, err := zstd.NewWriter(output)
+		if err != nil {
+			return nil, err
+		}
+		return &archiveTarWriter{W: tar.NewWriter(compressor), Compressor: compressor}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown archive format: %s", format)
+	}
+}
+
+func taskProcessArchiveCompress(spec TaskSpec) *util.HttpError {
+	if os.Getenv(taskEnvId) == "" {
+		log.Fatal("This code can only run inside of a task.")
+		return util.HttpErr(http.StatusInternalServerError, "internal error")
+	}
+
+	sourceInfo, herr := Stat(spec.Source)
+	if herr != nil {
+		return util.HttpErr(http.StatusBadRequest, "invalid source file supplied - it no longer exists")
+	}
+
+	progress := &archiveProgress{Title: util.OptValue(fmt.Sprintf("Compressing %s", spec.ArchiveRoot))}
+	progress.Report(true)
+
+	// The first pass only determines the size of the input, which allows us to report meaningful progress.
+	if sourceInfo.IsDir() {
+		_ = filepath.WalkDir(spec.Source, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.Type().IsRegular() {
+				if info, err := d.Info(); err == nil {
+					progress.TotalBytes += info.Size()
+				}
+			}
+			return nil
+		})
+	} else {
+		progress.TotalBytes = sourceInfo.Size()
+	}
+
+	archiveFile, ok := OpenFile(spec.Destination, unix.O_WRONLY|unix.O_CREAT|unix.O_TRUNC, 0660)
+	if !ok {
+		return util.HttpErr(http.StatusBadRequest, "unable to create archive")
+	}
+	defer util.SilentClose(archiveFile)
+
+	archiveInfo, err := archiveFile.Stat()
+	if err != nil {
+		return util.HttpErr(http.StatusBadRequest, "unable to create archive")
+	}
+
+	output := &archiveLimitWriter{W: archiveFile, Limit: spec.SizeLimit}
+	writer, err := archiveNewWriter(orc.ArchiveFormat(spec.ArchiveFormat), output)
+	if err != nil {
+		archiveDiscard(spec.Destination)
+		return util.HttpErr(http.StatusBadRequest, "unknown archive format")
+	}
+
+	skipped := 0
+	writeErr := filepath.WalkDir(spec.Source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			skipped++
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			skipped++
+			return nil
+		}
+
+		rel, err := filepath.Rel(spec.Source, path)
+		if err != nil {
+			return err
+		}
+
+		name := spec.ArchiveRoot
+		if rel != "." {
+			name = filepath.ToSlash(filepath.Join(spec.ArchiveRoot, rel))
+		}
+
+		switch {
+		case d.IsDir():
+			return writer.WriteDir(name, info)
+
+		case d.Type().IsRegular():
+			if os.SameFile(info, archiveInfo) {
+				return nil
+			}
+
+			file, ok := OpenFile(path, unix.O_RDONLY, 0)
+			if !ok {
+				skipped++
+				return nil
+			}
+			defer util.SilentClose(file)
+
+			err = writer.WriteFile(name, info, &archiveCountingReader{R: file, Progress: progress})
+			progress.Files++
+			return err
+
+		default:
+			// Symbolic links and special files are not included in archives
+			skipped++
+			return nil
+		}
+	})
+
+	writeErr = errors.Join(writeErr, writer.Close())
+	progress.Report(true)
+
+	if writeErr != nil {
+		archiveDiscard(spec.Destination)
+		if errors.Is(writeErr, errArchiveSizeLimit) {
+			return util.HttpErr(http.StatusPaymentRequired, "the archive does not fit within the remaining storage quota")
+		}
+
+		log.Info("Failed to create archive: %s", writeErr)
+		return util.HttpErr(http.StatusInternalServerError, "failed to create archive")
+	}
+
+	_ = unix.Fchmod(int(archiveFile.Fd()), 0660)
+
+	if skipped > 0 {
+		return util.HttpErr(http.StatusInternalServerError, "archive was created but %d entries could not be included", skipped)
+	}
+	return nil
+}
+
+// archiveDiscard removes a partially written archive.
+func archiveDiscard(path string) {
+	parent, ok := OpenFile(filepath.Dir(path), unix.O_RDONLY, 0)
+	if !ok {
+		return
+	}
+	defer util.SilentClose(parent)
+
+	_ = unix.Unlinkat(int(parent.Fd()), filepath.Base(path), 0)
+}
+
+func taskProcessArchiveExtract(spec TaskSpec) *util.HttpError {
+	if os.Getenv(taskEnvId) == "" {
+		log.Fatal("This code can only run inside of a task.")
+		return util.HttpErr(http.StatusInternalServerError, "internal error")
+	}
+
+	archiveFile, ok := OpenFile(spec.Source, unix.O_RDONLY, 0)
+	if !ok {
+		return util.HttpErr(http.StatusBadRequest, "invalid source file supplied - it no longer exists")
+	}
+	defer util.SilentClose(archiveFile)
+
+	archiveInfo, err := archiveFile.Stat()
+	if err != nil || !archiveInfo.Mode().IsRegular() {
+		return util.HttpErr(http.StatusBadRequest, "invalid source file supplied - it no longer exists")
+	}
+
+	if herr := DoCreateFolder(spec.Destination); herr != nil {
+		return util.HttpErr(http.StatusBadRequest, "unable to create destination folder")
+	}
+
+	destDir, ok := OpenFile(spec.Destination, unix.O_RDONLY|unix.O_DIRECTORY, 0)
+	if !ok {
+		return util.HttpErr(http.StatusBadRequest, "unable to open destination folder")
+	}
+	defer util.SilentClose(destDir)
+
+	progress := &archiveProgress{
+		Title:      util.OptValue(fmt.Sprintf("Extracting %s", util.FileName(spec.Source))),
+		TotalBytes: archiveInfo.Size(),
+	}
+	progress.Report(true)
+
+	extractor := &archiveExtractor{
+		Root:     destDir,
+		Progress: progress,
+		Output:   archiveLimitWriter{W: io.Discard, Limit: spec.SizeLimit},
+	}
+
+	switch format := orc.ArchiveFormat(spec.ArchiveFormat); format {
+	case orc.ArchiveFormatZip:
+		err = extractor.ExtractZip(archiveFile, archiveInfo.Size())
+
+	case orc.ArchiveFormatTar, orc.ArchiveFormatTarGz, orc.ArchiveFormatTarZst:
+		err = extractor.ExtractTar(format, &archiveCountingReader{R: archiveFile, Progress: progress})
+
+	default:
+		return util.HttpErr(http.StatusBadRequest, "unknown archive format")
+	}
+
+	progress.Report(true)
+
+	if err != nil {
+		var pathErr *archivePathError
+		if errors.As(err, &pathErr) {
+			return util.HttpErr(http.StatusBadRequest, "archive contains an unsafe path: %s", pathErr.Name)
+		} else if errors.Is(err, errArchiveSizeLimit) {
+			return util.HttpErr(http.StatusPaymentRequired, "the extracted files do not fit within the remaining storage quota")
+		}
+
+		log.Info("Failed to extract archive: %s", err)
+		return util.HttpErr(http.StatusBadRequest, "failed to extract archive - it might be corrupt")
+	}
+
+	if extractor.Skipped > 0 {
+		return util.HttpErr(
+			http.StatusInternalServerError,
+			"archive was extracted but %d links or special files were skipped",
+			extractor.Skipped,
+		)
+	}
+	return nil
+}
+
+type archivePathError struct {
+	Name string
+}
+
+func (e *archivePathError) Error() string {
+	return fmt.Sprintf("unsafe path in archive: %s", e.Name)
+}
+
+// archiveEntryPath validates the name of an archive entry and returns it as a path relative to the destination folder.
+// An empty path refers to the destination folder itself.
+func archiveEntryPath(name string) (string, bool) {
+	if name == "" || strings.ContainsRune(name, 0) {
+		return "", false
+	}
+
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") {
+		return "", false
+	}
+
+	var components []string
+	for _, component := range strings.Split(name, "/") {
+		switch component {
+		case "", ".":
+			continue
+		case "..":
+			return "", false
+		default:
+			components = append(components, component)
+		}
+	}
+
+	return strings.Join(components, "/"), true
+}
+
+type archiveExtractor struct {
+	Root     *os.File
+	Progress *archiveProgress
+	Output   archiveLimitWriter
+	Skipped  int
+}
+
+func (e *archiveExtractor) ExtractZip(archive io.ReaderAt, size int64) error {
+	reader, err := zip.NewReader(archive, size)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return err
+	}
+
+	// Validate all names before writing anything
+	for _, f := range reader.File {
+		if _, ok := archiveEntryPath(f.Name); !ok {
+			return &archivePathError{Name: f.Name}
+		}
+	}
+
+	for _, f := range reader.File {
+		name, _ := archiveEntryPath(f.Name)
+		mode := f.Mode()
+
+		switch {
+		case mode.IsDir():
+			err = e.createDir(name)
+
+		case mode.IsRegular():
+			err = e.extractZipFile(f, name, mode)
+
+		default:
+			e.Skipped++
+		}
+
+		if err != nil {
+			return err
+		}
+
+		e.Progress.ProcessedBytes += int64(f.CompressedSize64)
+		e.Progress.Report(false)
+	}
+
+	return nil
+}
+
+func (e *archiveExtractor) extractZipFile(f *zip.File, name string, mode os.FileMode) error {
+	data, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer util.SilentClose(data)
+
+	return e.createFile(name, mode, data)
+}
+
+func (e *archiveExtractor) ExtractTar(format orc.ArchiveFormat, archive io.Reader) error {
+	var input io.Reader = archive
+	switch format {
+	case orc.ArchiveFormatTarGz:
+		decompressor, err := gzip.NewReader(archive)
+		if err != nil {
+			return err
+		}
+		defer util.SilentClose(decompressor)
+		input = decompressor
+
+	case orc.ArchiveFormatTarZst:
+		decompressor, err := zstd.NewReader(archive)
+		if err != nil {
+			return err
+		}
+		defer decompressor.Close()
+		input = decompressor
+	}
+
+	reader := tar.NewReader(input)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil && !errors.Is(err, tar.ErrInsecurePath) {
+			return err
+		}
+
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		name, ok := archiveEntryPath(header.Name)
+		if !ok {
+			return &archivePathError{Name: header.Name}
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = e.createDir(name)
+
+		case tar.TypeReg:
+			err = e.createFile(name, header.FileInfo().Mode(), reader)
+
+		default:
+			e.Skipped++
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// openParent opens the folder which should contain the entry at path, creating missing folders along the way. Every
+// component is opened without following symbolic links, which prevents the extraction from escaping the destination
+// through a link already present in the destination folder.
+func (e *archiveExtractor) openParent(path string) (int, string, error) {
+	components := strings.Split(path, "/")
+
+	fd, err := unix.Dup(int(e.Root.Fd()))
+	if err != nil {
+		return -1, "", err
+	}
+
+	for _, component := range components[:len(components)-1] {
+		newFd, err := e.openOrCreateDir(fd, component)
+		_ = unix.Close(fd)
+		if err != nil {
+			return -1, "", err
+		}
+		fd = newFd
+	}
+
+	return fd, components[len(components)-1], nil
+}
+
+func (e *archiveExtractor) openOrCreateDir(parentFd int, name string) (int, error) {
+	err := unix.Mkdirat(parentFd, name, 0770)
+	didCreate := err == nil
+	if err != nil && !errors.Is(err, unix.EEXIST) {
+		return -1, err
+	}
+
+	fd, err := unix.Openat(parentFd, name, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_NOFOLLOW, 0)
+	if err != nil {
+		return -1, err
+	}
+
+	if didCreate {
+		_ = unix.Fchown(fd, DefaultUid, DefaultUid)
+	}
+	return fd, nil
+}
+
+func (e *archiveExtractor) createDir(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	parentFd, name, err := e.openParent(path)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(parentFd)
+
+	fd, err := e.openOrCreateDir(parentFd, name)
+	if err != nil {
+		return err
+	}
+	return unix.Close(fd)
+}
+
+func (e *archiveExtractor) createFile(path string, mode os.FileMode, data io.Reader) error {
+	if path == "" {
+		return &archivePathError{Name: path}
+	}
+
+	parentFd, name, err := e.openParent(path)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(parentFd)
+
+	perm := uint32(0660)
+	if mode.Perm()&0100 != 0 {
+		perm = 0770
+	}
+
+	fd, err := unix.Openat(parentFd, name, unix.O_WRONLY|unix.O_CREAT|unix.O_TRUNC|unix.O_NOFOLLOW, perm)
+	if err != nil {
+		return err
+	}
+
+	_ = unix.Fchown(fd, DefaultUid, DefaultUid)
+	file := os.NewFile(uintptr(fd), name)
+	defer util.SilentClose(file)
+
+	e.Output.W = file
+	_, err = io.Copy(&e.Output, data)
+	e.Progress.Files++
+	return err
+}
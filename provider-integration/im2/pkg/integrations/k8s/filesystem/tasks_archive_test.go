@@ -0,0 +1,169 @@
+package filesystem
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	orc "ucloud.dk/shared/pkg/orchestrators"
+)
+
+func TestArchiveEntryPath(t *testing.T) {
+	valid := map[string]string{
+		"file.txt":          "file.txt",
+		"folder/":           "folder",
+		"./folder/./a.txt":  "folder/a.txt",
+		"folder//b.txt":     "folder/b.txt",
+		"windows\\file.txt": "windows/file.txt",
+		".":                 "",
+	}
+
+	for name, expected := range valid {
+		actual, ok := archiveEntryPath(name)
+		if !ok || actual != expected {
+			t.Errorf("archiveEntryPath(%q) = %q, %v (expected %q)", name, actual, ok, expected)
+		}
+	}
+
+	invalid := []string{"", "/etc/passwd", "../escape", "folder/../../escape", "\\windows\\root", "a\x00b", "a/.."}
+	for _, name := range invalid {
+		if actual, ok := archiveEntryPath(name); ok {
+			t.Errorf("archiveEntryPath(%q) should be rejected but returned %q", name, actual)
+		}
+	}
+}
+
+func TestArchiveFormatFromFileName(t *testing.T) {
+	formats := map[string]orc.ArchiveFormat{
+		"data.zip":     orc.ArchiveFormatZip,
+		"data.tar":     orc.ArchiveFormatTar,
+		"data.TAR.GZ":  orc.ArchiveFormatTarGz,
+		"data.tgz":     orc.ArchiveFormatTarGz,
+		"data.tar.zst": orc.ArchiveFormatTarZst,
+	}
+
+	for name, expected := range formats {
+		actual, ok := orc.ArchiveFormatFromFileName(name)
+		if !ok || actual != expected {
+			t.Errorf("ArchiveFormatFromFileName(%q) = %q, %v (expected %q)", name, actual, ok, expected)
+		}
+	}
+
+	if _, ok := orc.ArchiveFormatFromFileName("data.gz"); ok {
+		t.Errorf("data.gz should not be recognized as an archive")
+	}
+}
+
+func newTestExtractor(t *testing.T, limit int64) (*archiveExtractor, string) {
+	dir := t.TempDir()
+	root, err := os.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = root.Close() })
+
+	// A recent update prevents the extractor from posting progress during the test
+	extractor := &archiveExtractor{
+		Root:     root,
+		Progress: &archiveProgress{LastUpdate: time.Now().Add(time.Hour)},
+		Output:   archiveLimitWriter{Limit: limit},
+	}
+	return extractor, dir
+}
+
+func buildTestZip(t *testing.T, files map[string]string) *bytes.Reader {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestArchiveExtractZip(t *testing.T) {
+	extractor, dir := newTestExtractor(t, 0)
+	archive := buildTestZip(t, map[string]string{
+		"folder/":             "",
+		"folder/a.txt":        "hello",
+		"folder/nested/b.txt": "world",
+	})
+
+	if err := extractor.ExtractZip(archive, archive.Size()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "folder/nested/b.txt"))
+	if err != nil || string(data) != "world" {
+		t.Fatalf("unexpected content: %q %v", data, err)
+	}
+}
+
+func TestArchiveExtractRejectsTraversal(t *testing.T) {
+	extractor, dir := newTestExtractor(t, 0)
+	archive := buildTestZip(t, map[string]string{
+		"ok.txt":        "fine",
+		"../escape.txt": "evil",
+	})
+
+	err := extractor.ExtractZip(archive, archive.Size())
+	var pathErr *archivePathError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("expected a path error, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "ok.txt")); err == nil {
+		t.Fatalf("no files should be written when the archive contains an unsafe path")
+	}
+}
+
+func TestArchiveExtractDoesNotFollowLinks(t *testing.T) {
+	extractor, dir := newTestExtractor(t, 0)
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := buildTestZip(t, map[string]string{"link/escape.txt": "evil"})
+	if err := extractor.ExtractZip(archive, archive.Size()); err == nil {
+		t.Fatalf("extraction through a symbolic link should fail")
+	}
+
+	if _, err := os.Stat(filepath.Join(outside, "escape.txt")); err == nil {
+		t.Fatalf("file was written outside of the destination")
+	}
+}
+
+func TestArchiveExtractTarSkipsLinksAndEnforcesLimit(t *testing.T) {
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	_ = w.WriteHeader(&tar.Header{Name: "passwd", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"})
+	_ = w.WriteHeader(&tar.Header{Name: "big.bin", Typeflag: tar.TypeReg, Size: 1024, Mode: 0644})
+	_, _ = w.Write(make([]byte, 1024))
+	_ = w.Close()
+
+	extractor, dir := newTestExtractor(t, 100)
+	err := extractor.ExtractTar(orc.ArchiveFormatTar, bytes.NewReader(buf.Bytes()))
+	if !errors.Is(err, errArchiveSizeLimit) {
+		t.Fatalf("expected the size limit to be enforced, got %v", err)
+	}
+
+	if extractor.Skipped != 1 {
+		t.Fatalf("expected the symbolic link to be skipped, skipped = %v", extractor.Skipped)
+	}
+
+	if _, err := os.Lstat(filepath.Join(dir, "passwd")); err == nil {
+		t.Fatalf("symbolic link should not be extracted")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"sync/atomic"
 	"time"
 
@@ -46,8 +47,12 @@ func TaskProcessor() {
 		Destination:      os.Getenv(taskEnvDestination),
 		TransferEndpoint: os.Getenv(taskEnvTransferEndpoint),
 		TaskToken:        os.Getenv(taskEnvTaskToken),
+		ArchiveFormat:    os.Getenv(taskEnvArchiveFormat),
+		ArchiveRoot:      os.Getenv(taskEnvArchiveRoot),
 	}
 
+	spec.SizeLimit, _ = strconv.ParseInt(os.Getenv(taskEnvSizeLimit), 10, 64)
+
 	if spec.Type == "" {
 		log.Fatal("Failed to understand task environment")
 		return
@@ -81,6 +86,10 @@ func TaskProcessor() {
 		err = task2ProcessTransfer(spec)
 	case TaskTypeMarkItDown:
 		err = taskMarkItDown(spec)
+	case TaskTypeArchiveCompress:
+		err = taskProcessArchiveCompress(spec)
+	case TaskTypeArchiveExtract:
+		err = taskProcessArchiveExtract(spec)
 	}
 
 	last := taskProcessorState.LastStatus.Load()
@@ -120,4 +129,7 @@ const (
 	taskEnvTransferEndpoint = "UCLOUD_JOB_TRANSFER_ENDPOINT"
 	taskEnvTaskToken        = "UCLOUD_JOB_TASK_TOKEN"
 	taskEnvConflictPolicy   = "UCLOUD_JOB_CONFLICT_POLICY"
+	taskEnvArchiveFormat    = "UCLOUD_JOB_ARCHIVE_FORMAT"
+	taskEnvArchiveRoot      = "UCLOUD_JOB_ARCHIVE_ROOT"
+	taskEnvSizeLimit        = "UCLOUD_JOB_SIZE_LIMIT"
 )
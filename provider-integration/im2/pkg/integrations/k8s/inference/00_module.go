@@ -788,6 +788,45 @@ func Init() {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write(respData)
 	})
+
+	controller.Mux.HandleFunc(authority+"/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.ContentLength > inferenceMaxJSONRequestBytes {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		apiKeyOwner, httpErr := inferenceAuthenticateRequest(r)
+		if httpErr != nil {
+			http.Error(w, httpErr.Why, httpErr.StatusCode)
+			return
+		}
+
+		var request InferenceEmbeddingRequest
+		if !inferenceDecodeJSON(w, r, inferenceMaxJSONRequestBytes, &request) {
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), inferenceRequestTimeout)
+		defer cancel()
+
+		resp, httpErr := InferenceEmbed(ctx, apiKeyOwner, request)
+		if httpErr != nil {
+			http.Error(w, httpErr.Why, httpErr.StatusCode)
+			return
+		}
+
+		respData, err := json.Marshal(resp)
+		if err != nil {
+			http.Error(w, "invalid response", http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(respData)
+	})
 	inferenceGlobals.Ready.Store(true)
 }
 
@@ -934,11 +973,28 @@ func inferenceDiscoverModelsFromEndpoint(base string, availableTo []string, disa
 			continue
 		}
 
+		modelGlobals.Mu.RLock()
+		known := false
+		for existingName, existing := range modelGlobals.Models {
+			if existingName == name || existing.Endpoint.BackendModelName == name {
+				known = true
+				break
+			}
+		}
+		modelGlobals.Mu.RUnlock()
+
+		// Capabilities are only determined for new models. This avoids probing every backend on each discovery
+		// pass, and it allows an administrator to change the capabilities of a discovered model.
+		capabilities := []InferenceCapability{InferenceTextGeneration}
+		if !known && inferenceBackendSupportsEmbeddings(&client, base, name) {
+			capabilities = []InferenceCapability{InferenceEmbedding}
+		}
+
 		catalogModel := inferenceModelNormalize(InferenceModel{
 			Name:           name,
 			Title:          name,
 			TitleModelName: name,
-			Capabilities:   []InferenceCapability{InferenceTextGeneration},
+			Capabilities:   capabilities,
 			PriceMultiplier: InferencePricing{
 				CachedInput: 1000,
 				Input:       1000,
@@ -998,6 +1054,30 @@ func inferenceDiscoverModelsFromEndpoint(base string, availableTo []string, disa
 	}
 }
 
+// inferenceBackendSupportsEmbeddings probes a backend for an embeddings endpoint serving the model. Backends such as
+// vLLM serve a model through either the generative or the embedding API, so a successful probe means that the model
+// is an embedding model.
+func inferenceBackendSupportsEmbeddings(client *http.Client, base string, model string) bool {
+	body, _ := json.Marshal(map[string]string{"model": model, "input": "ping"})
+	resp, err := client.Post(base+"/embeddings", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	defer util.SilentClose(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false
+	}
+
+	var parsed struct {
+		Data []InferenceEmbeddingData `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, inferenceMaxJSONRequestBytes)).Decode(&parsed); err != nil {
+		return false
+	}
+	return len(parsed.Data) > 0 && len(parsed.Data[0].Embedding) > 0
+}
+
 func inferenceDiscoverDynamoModels() {
 	inferenceCfg := &shared.ServiceConfig.Compute.Inference
 	namespace := strings.TrimSpace(inferenceCfg.Dynamo.Namespace)
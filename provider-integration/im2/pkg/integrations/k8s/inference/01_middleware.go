@@ -1678,6 +1678,162 @@ func inferenceImageRequestSize(request InferenceImageGenerationRequest) (int, in
 	return inferenceParseImageSize("")
 }
 
+// Embeddings
+// =====================================================================================================================
+
+const inferenceMaxEmbeddingInputs = 2048
+
+type InferenceEmbeddingRequest struct {
+	Model          string              `json:"model"`
+	Input          json.RawMessage     `json:"input"`
+	EncodingFormat util.Option[string] `json:"encoding_format,omitempty"`
+	Dimensions     util.Option[int]    `json:"dimensions,omitempty"`
+	User           util.Option[string] `json:"user,omitempty"`
+}
+
+type InferenceEmbeddingResponse struct {
+	Object string                   `json:"object"`
+	Data   []InferenceEmbeddingData `json:"data"`
+	Model  string                   `json:"model"`
+	Usage  InferenceEmbeddingUsage  `json:"usage"`
+}
+
+type InferenceEmbeddingData struct {
+	Object string `json:"object"`
+	Index  int    `json:"index"`
+
+	// Embedding is either an array of floats or a base64 string depending on the requested encoding format. It is
+	// forwarded as-is from the backend.
+	Embedding json.RawMessage `json:"embedding"`
+}
+
+type InferenceEmbeddingUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+func InferenceEmbed(ctx context.Context, owner apm.WalletOwner, request InferenceEmbeddingRequest) (InferenceEmbeddingResponse, *util.HttpError) {
+	if inferenceIsLocked(owner) {
+		return InferenceEmbeddingResponse{}, util.HttpErr(http.StatusPaymentRequired, "payment required")
+	}
+
+	model, httpErr := inferenceResolveModelForOwner(owner, request.Model)
+	if httpErr != nil {
+		return InferenceEmbeddingResponse{}, httpErr
+	}
+	if httpErr := inferenceValidateEmbeddingRequest(request, model); httpErr != nil {
+		return InferenceEmbeddingResponse{}, httpErr
+	}
+	release, httpErr := inferenceAcquire(owner)
+	if httpErr != nil {
+		return InferenceEmbeddingResponse{}, httpErr
+	}
+	defer release()
+
+	resp, usagePresent, httpErr := inferenceEmbedWithModel(ctx, model, request)
+	if httpErr != nil {
+		return InferenceEmbeddingResponse{}, httpErr
+	}
+	if usagePresent {
+		inferenceReportUsage(owner, model, 0, resp.Usage.PromptTokens, 0)
+	}
+	return resp, nil
+}
+
+// inferenceEmbedWithModel forwards an already validated request to the backend of model. The returned response uses
+// the catalog name of the model. The boolean is true if the backend reported usage.
+func inferenceEmbedWithModel(ctx context.Context, model InferenceModel, request InferenceEmbeddingRequest) (InferenceEmbeddingResponse, bool, *util.HttpError) {
+	request.Model = model.Endpoint.BackendModelName
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return InferenceEmbeddingResponse{}, false, util.HttpErr(http.StatusBadRequest, "invalid request")
+	}
+	if len(body) > inferenceMaxJSONRequestBytes {
+		return InferenceEmbeddingResponse{}, false, util.HttpErr(http.StatusRequestEntityTooLarge, "request body too large")
+	}
+
+	respBody, httpErr := inferenceBackendJSONRequest(ctx, model.Endpoint.BasePath, http.MethodPost, "/embeddings", body, "application/json")
+	if httpErr != nil {
+		return InferenceEmbeddingResponse{}, false, httpErr
+	}
+
+	var resp struct {
+		Object string                               `json:"object"`
+		Data   []InferenceEmbeddingData             `json:"data"`
+		Usage  util.Option[InferenceEmbeddingUsage] `json:"usage"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return InferenceEmbeddingResponse{}, false, util.HttpErr(http.StatusBadGateway, "invalid response")
+	}
+	if resp.Usage.Present && !inferenceEmbeddingUsageValid(resp.Usage.Value) {
+		return InferenceEmbeddingResponse{}, false, util.HttpErr(http.StatusBadGateway, "invalid usage from upstream")
+	}
+	if !resp.Usage.Present {
+		inferenceWarnMissingUsage("embedding", model.Name)
+	}
+
+	object := resp.Object
+	if object == "" {
+		object = "list"
+	}
+	data := resp.Data
+	if data == nil {
+		data = []InferenceEmbeddingData{}
+	}
+
+	return InferenceEmbeddingResponse{
+		Object: object,
+		Data:   data,
+		Model:  model.Name,
+		Usage:  inferenceEmbeddingUsage(resp.Usage),
+	}, resp.Usage.Present, nil
+}
+
+// inferenceEmbeddingInputCount returns the number of inputs in an embedding request. The OpenAI API accepts a string,
+// an array of strings, an array of tokens or an array of token arrays. A negative count is returned if the input is
+// not any of these.
+func inferenceEmbeddingInputCount(input json.RawMessage) int {
+	var text string
+	if err := json.Unmarshal(input, &text); err == nil {
+		if text == "" {
+			return -1
+		}
+		return 1
+	}
+
+	var texts []string
+	if err := json.Unmarshal(input, &texts); err == nil {
+		if len(texts) == 0 || slices.Contains(texts, "") {
+			return -1
+		}
+		return len(texts)
+	}
+
+	var tokens []int
+	if err := json.Unmarshal(input, &tokens); err == nil {
+		if len(tokens) == 0 {
+			return -1
+		}
+		return 1
+	}
+
+	var tokenArrays [][]int
+	if err := json.Unmarshal(input, &tokenArrays); err == nil {
+		if len(tokenArrays) == 0 {
+			return -1
+		}
+		for _, arr := range tokenArrays {
+			if len(arr) == 0 {
+				return -1
+			}
+		}
+		return len(tokenArrays)
+	}
+
+	return -1
+}
+
 // Helpers
 // =====================================================================================================================
 
@@ -1840,6 +1996,26 @@ func inferenceValidateImageRequest(request InferenceImageGenerationRequest) *uti
 	return nil
 }
 
+func inferenceValidateEmbeddingRequest(request InferenceEmbeddingRequest, model InferenceModel) *util.HttpError {
+	if !slices.Contains(model.Capabilities, InferenceEmbedding) {
+		return util.HttpErr(http.StatusBadRequest, "model does not support embeddings")
+	}
+	count := inferenceEmbeddingInputCount(request.Input)
+	if count <= 0 {
+		return util.HttpErr(http.StatusBadRequest, "input must be a non-empty string, array of strings or array of tokens")
+	}
+	if count > inferenceMaxEmbeddingInputs {
+		return util.HttpErr(http.StatusBadRequest, "request contains too many items")
+	}
+	if request.EncodingFormat.Present && request.EncodingFormat.Value != "float" && request.EncodingFormat.Value != "base64" {
+		return util.HttpErr(http.StatusBadRequest, "invalid encoding format")
+	}
+	if request.Dimensions.Present && request.Dimensions.Value <= 0 {
+		return util.HttpErr(http.StatusBadRequest, "invalid number of dimensions")
+	}
+	return nil
+}
+
 // Cost estimation
 // =====================================================================================================================
 
@@ -1953,3 +2129,18 @@ func inferenceImageUsageValid(usage InferenceImageGenerationUsage) bool {
 	}
 	return true
 }
+
+func inferenceEmbeddingUsage(usage util.Option[InferenceEmbeddingUsage]) InferenceEmbeddingUsage {
+	if usage.Present {
+		result := usage.Value
+		if result.TotalTokens == 0 {
+			result.TotalTokens = result.PromptTokens
+		}
+		return result
+	}
+	return InferenceEmbeddingUsage{}
+}
+
+func inferenceEmbeddingUsageValid(usage InferenceEmbeddingUsage) bool {
+	return usage.PromptTokens >= 0 && usage.TotalTokens >= 0
+}
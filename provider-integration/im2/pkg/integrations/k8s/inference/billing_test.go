@@ -30,6 +30,14 @@ func TestInferenceImageUsageMissingIsNotEstimated(t *testing.T) {
 	}
 }
 
+func TestInferenceEmbeddingUsageMissingIsNotEstimated(t *testing.T) {
+	usage := inferenceEmbeddingUsage(util.OptNone[InferenceEmbeddingUsage]())
+
+	if usage != (InferenceEmbeddingUsage{}) {
+		t.Fatalf("expected zero usage, got %+v", usage)
+	}
+}
+
 func TestInferenceMockImageUsageIsEstimated(t *testing.T) {
 	usage := inferenceEstimateImageUsage(InferenceImageGenerationRequest{N: util.OptValue(2), Size: util.OptValue("1024x1024")}, 0)
 	if usage.OutputTokens <= 0 || usage.TotalTokens != usage.OutputTokens {
@@ -47,6 +55,9 @@ func TestInferenceRejectsNegativeUsage(t *testing.T) {
 	if inferenceImageUsageValid(InferenceImageGenerationUsage{TotalTokens: -1}) {
 		t.Fatal("negative image usage was accepted")
 	}
+	if inferenceEmbeddingUsageValid(InferenceEmbeddingUsage{PromptTokens: -1}) {
+		t.Fatal("negative embedding usage was accepted")
+	}
 }
 
 func TestInferenceUsesReportedUsage(t *testing.T) {
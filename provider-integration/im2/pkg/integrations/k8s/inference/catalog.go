@@ -25,6 +25,7 @@ const (
 	InferenceVision         InferenceCapability = "Vision"
 	InferenceVideoVision    InferenceCapability = "VideoVision"
 	InferenceAudio          InferenceCapability = "Audio"
+	InferenceEmbedding      InferenceCapability = "Embedding"
 )
 
 type InferenceModel struct {
@@ -573,7 +574,7 @@ func inferenceModelValidate(model InferenceModel) *util.HttpError {
 	}
 	for _, capability := range model.Capabilities {
 		switch capability {
-		case InferenceTextGeneration, InferenceTextToImage, InferenceSpeechToText, InferenceVision, InferenceVideoVision, InferenceAudio:
+		case InferenceTextGeneration, InferenceTextToImage, InferenceSpeechToText, InferenceVision, InferenceVideoVision, InferenceAudio, InferenceEmbedding:
 		default:
 			return util.HttpErr(http.StatusBadRequest, "invalid model capability")
 		}
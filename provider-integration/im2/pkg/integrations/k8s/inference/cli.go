@@ -57,7 +57,7 @@ func inferenceCliHelp() {
 	f.AppendField("  --name <name>", "Rename the model. Only allowed for non-public models.")
 	f.AppendField("  --title <title>", "Set the display title")
 	f.AppendField("  --title-model-name <name>", "Set model used for chat thread title generation")
-	f.AppendField("  --capabilities <list>", "Comma-separated list: TextGeneration, TextToImage, SpeechToText")
+	f.AppendField("  --capabilities <list>", "Comma-separated list: TextGeneration, TextToImage, SpeechToText, Embedding")
 	f.AppendField("  --price-cached <n>", "Cached input multiplier in fixed-point thousandths")
 	f.AppendField("  --price-input <n>", "Input multiplier in fixed-point thousandths")
 	f.AppendField("  --price-output <n>", "Output multiplier in fixed-point thousandths")
@@ -319,7 +319,7 @@ func inferenceCliParseCapabilities(raw string) ([]InferenceCapability, error) {
 	for _, item := range items {
 		capability := InferenceCapability(item)
 		switch capability {
-		case InferenceTextGeneration, InferenceTextToImage, InferenceSpeechToText:
+		case InferenceTextGeneration, InferenceTextToImage, InferenceSpeechToText, InferenceEmbedding:
 			capabilities = append(capabilities, capability)
 		default:
 			return nil, fmt.Errorf("invalid capability %q", item)
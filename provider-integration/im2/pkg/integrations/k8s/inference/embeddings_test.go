@@ -0,0 +1,180 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cfg "ucloud.dk/pkg/config"
+	"ucloud.dk/pkg/integrations/k8s/shared"
+	"ucloud.dk/shared/pkg/util"
+)
+
+func inferenceTestEmbeddingBackend(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	old := shared.ServiceConfig
+	shared.ServiceConfig = &cfg.ServicesConfigurationKubernetes{}
+	t.Cleanup(func() { shared.ServiceConfig = old })
+	shared.ServiceConfig.Compute.Inference.Provider = cfg.KubernetesInferenceProviderDevelopment
+	shared.ServiceConfig.Compute.Inference.BackendServer = server.URL
+
+	return server
+}
+
+func inferenceTestEmbeddingModel(base string) InferenceModel {
+	return InferenceModel{
+		Name:         "embed",
+		Capabilities: []InferenceCapability{InferenceEmbedding},
+		Endpoint: InferenceEndpoint{
+			BasePath:         base,
+			BackendModelName: "backend/embed",
+		},
+	}
+}
+
+func TestInferenceEmbedForwardsToBackend(t *testing.T) {
+	var received map[string]any
+	server := inferenceTestEmbeddingBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		_, _ = w.Write([]byte(`{"object":"list","model":"backend/embed","data":[{"object":"embedding","index":0,"embedding":[0.5,-0.25]},{"object":"embedding","index":1,"embedding":[1,0]}],"usage":{"prompt_tokens":7,"total_tokens":7}}`))
+	})
+
+	request := InferenceEmbeddingRequest{Model: "embed", Input: json.RawMessage(`["hello","world"]`)}
+	resp, usagePresent, httpErr := inferenceEmbedWithModel(context.Background(), inferenceTestEmbeddingModel(server.URL), request)
+	if httpErr != nil {
+		t.Fatalf("embedding request failed: %v", httpErr)
+	}
+
+	if received["model"] != "backend/embed" {
+		t.Fatalf("expected backend model name to be forwarded, got %v", received["model"])
+	}
+	if !usagePresent || resp.Usage.PromptTokens != 7 || resp.Usage.TotalTokens != 7 {
+		t.Fatalf("unexpected usage: present=%v %+v", usagePresent, resp.Usage)
+	}
+	if resp.Model != "embed" {
+		t.Fatalf("expected catalog model name in response, got %s", resp.Model)
+	}
+	if len(resp.Data) != 2 || string(resp.Data[0].Embedding) != "[0.5,-0.25]" {
+		t.Fatalf("unexpected embedding data: %+v", resp.Data)
+	}
+}
+
+func TestInferenceEmbedMissingUsageIsNotReported(t *testing.T) {
+	server := inferenceTestEmbeddingBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[{"object":"embedding","index":0,"embedding":"AAAAPw=="}]}`))
+	})
+
+	request := InferenceEmbeddingRequest{Model: "embed", Input: json.RawMessage(`"hello"`), EncodingFormat: util.OptValue("base64")}
+	resp, usagePresent, httpErr := inferenceEmbedWithModel(context.Background(), inferenceTestEmbeddingModel(server.URL), request)
+	if httpErr != nil {
+		t.Fatalf("embedding request failed: %v", httpErr)
+	}
+	if usagePresent || resp.Usage != (InferenceEmbeddingUsage{}) {
+		t.Fatalf("expected no usage, got present=%v %+v", usagePresent, resp.Usage)
+	}
+	if resp.Object != "list" {
+		t.Fatalf("expected object to default to list, got %s", resp.Object)
+	}
+}
+
+func TestInferenceEmbedRejectsNegativeUsage(t *testing.T) {
+	server := inferenceTestEmbeddingBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[],"usage":{"prompt_tokens":-1,"total_tokens":-1}}`))
+	})
+
+	request := InferenceEmbeddingRequest{Model: "embed", Input: json.RawMessage(`"hello"`)}
+	_, _, httpErr := inferenceEmbedWithModel(context.Background(), inferenceTestEmbeddingModel(server.URL), request)
+	if httpErr == nil || httpErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %v", httpErr)
+	}
+}
+
+func TestInferenceEmbedPropagatesBackendErrors(t *testing.T) {
+	server := inferenceTestEmbeddingBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model does not support embeddings", http.StatusBadRequest)
+	})
+
+	request := InferenceEmbeddingRequest{Model: "embed", Input: json.RawMessage(`"hello"`)}
+	_, _, httpErr := inferenceEmbedWithModel(context.Background(), inferenceTestEmbeddingModel(server.URL), request)
+	if httpErr == nil {
+		t.Fatal("backend error was not propagated")
+	}
+}
+
+func TestInferenceEmbeddingRequestValidation(t *testing.T) {
+	model := inferenceTestEmbeddingModel("http://127.0.0.1")
+
+	valid := []string{`"hello"`, `["a","b"]`, `[1,2,3]`, `[[1,2],[3]]`}
+	for _, input := range valid {
+		if err := inferenceValidateEmbeddingRequest(InferenceEmbeddingRequest{Input: json.RawMessage(input)}, model); err != nil {
+			t.Fatalf("valid input %s was rejected: %v", input, err)
+		}
+	}
+
+	invalid := []string{`""`, `[]`, `["a",""]`, `[[]]`, `{"text":"a"}`, `null`, `[1.5]`}
+	for _, input := range invalid {
+		if err := inferenceValidateEmbeddingRequest(InferenceEmbeddingRequest{Input: json.RawMessage(input)}, model); err == nil {
+			t.Fatalf("invalid input %s was accepted", input)
+		}
+	}
+
+	texts := make([]string, inferenceMaxEmbeddingInputs+1)
+	for i := range texts {
+		texts[i] = "x"
+	}
+	tooMany, _ := json.Marshal(texts)
+	if err := inferenceValidateEmbeddingRequest(InferenceEmbeddingRequest{Input: tooMany}, model); err == nil {
+		t.Fatal("request with too many inputs was accepted")
+	}
+
+	request := InferenceEmbeddingRequest{Input: json.RawMessage(`"hello"`), EncodingFormat: util.OptValue("int8")}
+	if err := inferenceValidateEmbeddingRequest(request, model); err == nil {
+		t.Fatal("unsupported encoding format was accepted")
+	}
+
+	request = InferenceEmbeddingRequest{Input: json.RawMessage(`"hello"`), Dimensions: util.OptValue(0)}
+	if err := inferenceValidateEmbeddingRequest(request, model); err == nil {
+		t.Fatal("zero dimensions was accepted")
+	}
+}
+
+func TestInferenceEmbeddingRequiresCapability(t *testing.T) {
+	model := inferenceTestEmbeddingModel("http://127.0.0.1")
+	model.Capabilities = []InferenceCapability{InferenceTextGeneration}
+
+	err := inferenceValidateEmbeddingRequest(InferenceEmbeddingRequest{Input: json.RawMessage(`"hello"`)}, model)
+	if err == nil || err.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a model without the embedding capability, got %v", err)
+	}
+}
+
+func TestInferenceBackendEmbeddingProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request InferenceEmbeddingRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		if r.URL.Path != "/embeddings" || request.Model != "embedder" {
+			http.Error(w, "not an embedding model", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"object":"embedding","index":0,"embedding":[0.1]}]}`))
+	}))
+	defer server.Close()
+
+	client := server.Client()
+	if !inferenceBackendSupportsEmbeddings(client, server.URL, "embedder") {
+		t.Fatal("embedding model was not detected")
+	}
+	if inferenceBackendSupportsEmbeddings(client, server.URL, "chat") {
+		t.Fatal("chat model was detected as an embedding model")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 	k8smeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kvcore "kubevirt.io/api/core/v1"
+	kvsnapshot "kubevirt.io/api/snapshot/v1beta1"
 	kvclient "kubevirt.io/client-go/kubecli"
 	kvapi "kubevirt.io/client-go/kubevirt/typed/core/v1"
 	cfg "ucloud.dk/pkg/config"
@@ -102,6 +103,10 @@ func Init() ctrl.JobsService {
 		HandleBuiltInVnc:         handleVnc,
 		AttachResource:           attachResource,
 		DetachResource:           detachResource,
+		CreateSnapshot:           createSnapshot,
+		BrowseSnapshots:          browseSnapshots,
+		RestoreSnapshot:          restoreSnapshot,
+		DeleteSnapshot:           deleteSnapshot,
 	}
 }
 
@@ -232,7 +237,15 @@ func vmiFsMutator() {
 			var ops []jsonPatchOp
 			allowed := true
 			managedVolumeNames := map[string]bool{}
-			sharedVolumeName := ""
+
+			// Mounts are grouped by the claim backing them, each file system is served by a single shared volume.
+			sharedVolumeNames := map[string]string{}
+			volumeClaims := map[string]string{}
+			for _, volume := range pod.Spec.Volumes {
+				if volume.PersistentVolumeClaim != nil {
+					volumeClaims[volume.Name] = volume.PersistentVolumeClaim.ClaimName
+				}
+			}
 
 			if strings.HasPrefix(pod.Name, "hp-volume-") || strings.HasPrefix(pod.GenerateName, "hp-volume-") {
 				replacementSpec := pod.Spec
@@ -417,8 +430,10 @@ func vmiFsMutator() {
 						continue
 					}
 
-					if sharedVolumeName == "" {
+					sharedVolumeName, ok := sharedVolumeNames[volumeClaims[mount.Name]]
+					if !ok {
 						sharedVolumeName = mount.Name
+						sharedVolumeNames[volumeClaims[mount.Name]] = sharedVolumeName
 					}
 					managedVolumeNames[mount.Name] = true
 
@@ -435,6 +450,7 @@ func vmiFsMutator() {
 					})
 
 					// Point all managed volumes to a single backing Volume since the Volume is shared amongst all UCloud mounts
+					// using the same file system
 					ops = append(ops, jsonPatchOp{
 						Op:    "add",
 						Path:  fmt.Sprintf("/spec/containers/%d/volumeMounts/%d/name", cIdx, mountIdx),
@@ -448,10 +464,10 @@ func vmiFsMutator() {
 			// NOTE(Dan): This snippet ensures that we do not have duplicate volume definitions (which are not
 			// allowed). We do this by simply keeping the first one, we have already remapped all mounts to point
 			// to this volume.
-			if sharedVolumeName != "" {
+			if len(sharedVolumeNames) > 0 {
 				volumesRemoved := 0
 				for volIdx, volume := range pod.Spec.Volumes {
-					if managedVolumeNames[volume.Name] && volume.Name != sharedVolumeName {
+					if managedVolumeNames[volume.Name] && volume.Name != sharedVolumeNames[volumeClaims[volume.Name]] {
 						ops = append(ops, jsonPatchOp{
 							Op:   "remove",
 							Path: fmt.Sprintf("/spec/volumes/%d", volIdx-volumesRemoved),
@@ -997,12 +1013,32 @@ func terminate(request ctrl.JobTerminateRequest) *util.HttpError {
 	shared.RemoveFromQueue(request.Job.Id)
 
 	if !request.SkipResourceDeletion {
+		var snapshots []kvsnapshot.VirtualMachineSnapshot
+		var restoredClaims []string
+		if snapshotsEnabled() {
+			snapshots, _ = snapshotListForJob(context.Background(), request.Job.Id)
+
+			var err error
+			restoredClaims, err = snapshotRestoredClaims(context.Background(), request.Job.Id)
+			if err != nil {
+				log.Info("Failed to list VM restores: %v", err)
+				return util.ServerHttpError("Failed to delete VM")
+			}
+		}
+
 		name := vmName(request.Job.Id, 0)
 		err := KubevirtClient.VirtualMachine(Namespace).Delete(context.Background(), name, k8smeta.DeleteOptions{})
 		if err != nil && !k8serrors.IsNotFound(err) {
 			log.Info("Failed to delete VM: %v", err)
 			return util.ServerHttpError("Failed to delete VM")
 		}
+
+		if len(snapshots) > 0 {
+			// The snapshots are owned by the VM and are deleted along with it
+			snapshotReportUsage(request.Job, nil)
+		}
+
+		snapshotDeleteRestoredClaims(context.Background(), restoredClaims)
 	}
 	if !request.IsCleanup {
 		diskCleanup(request.Job)
@@ -1359,23 +1395,14 @@ func StartScheduledJob(job *orc.Job, rank int, node string) *util.HttpError {
 	vm.Spec.RunStrategy = &strategy
 
 	primaryDiskClaimName := nameOfVm
-
-	app := &job.Status.ResolvedApplication.Value
-	parametersAndValues := ctrl.JobFindParamAndValues(
-		job,
-		&app.Invocation,
-		requestDynamicParameters(job.Owner, app),
-	)
-	diskSizeParam, ok := parametersAndValues[vmDiskSizeParameter]
-	diskSize := 50
-	if ok {
-		intVal, ok := diskSizeParam.Value.Value.(float64)
-		if ok {
-			diskSize = int(intVal)
+	if hasExistingVm {
+		// Restoring a snapshot moves the VM to a new claim. This claim must be kept when the VM starts again.
+		if claimName, ok := vmPrimaryDiskClaim(existingVm); ok {
+			primaryDiskClaimName = claimName
 		}
 	}
 
-	diskSize = min(max(15, diskSize), 2000)
+	diskSize := vmDiskSize(job)
 
 	resources := kvcore.ResourceRequirements{Limits: map[k8score.ResourceName]k8sresource.Quantity{}, Requests: map[k8score.ResourceName]k8sresource.Quantity{}}
 	addResource := func(name k8score.ResourceName, value int64, scale k8sresource.Scale) {
@@ -1470,6 +1497,7 @@ func StartScheduledJob(job *orc.Job, rank int, node string) *util.HttpError {
 	mountsByName := map[string][]mountEntry{}
 
 	type unpreparedMount struct {
+		ClaimName     string
 		SubPath       string
 		ReadOnly      bool
 		UCloudPath    string
@@ -1487,7 +1515,7 @@ func StartScheduledJob(job *orc.Job, rank int, node string) *util.HttpError {
 				continue
 			}
 
-			subpath, ok := strings.CutPrefix(internalPath, filepath.Clean(ServiceConfig.FileSystem.MountPoint)+"/")
+			fs, subpath, ok := shared.FileSystemSubPath(internalPath)
 			if !ok {
 				continue
 			}
@@ -1497,6 +1525,7 @@ func StartScheduledJob(job *orc.Job, rank int, node string) *util.HttpError {
 			})
 
 			unpreparedMounts = append(unpreparedMounts, unpreparedMount{
+				ClaimName:  fs.ClaimName,
 				SubPath:    subpath,
 				ReadOnly:   param.ReadOnly,
 				UCloudPath: param.Path,
@@ -1656,12 +1685,17 @@ func StartScheduledJob(job *orc.Job, rank int, node string) *util.HttpError {
 			volName = param.PersistentTag
 		}
 
+		claimName := param.ClaimName
+		if claimName == "" {
+			claimName = shared.ServiceConfig.FileSystem.ClaimName
+		}
+
 		tplSpec.Volumes = append(tplSpec.Volumes, kvcore.Volume{
 			Name: volName,
 			VolumeSource: kvcore.VolumeSource{
 				PersistentVolumeClaim: &kvcore.PersistentVolumeClaimVolumeSource{
 					PersistentVolumeClaimVolumeSource: k8score.PersistentVolumeClaimVolumeSource{
-						ClaimName: shared.ServiceConfig.FileSystem.ClaimName,
+						ClaimName: claimName,
 					},
 				},
 			},
@@ -2007,6 +2041,26 @@ func updateExistingVmWithRetry(ctx context.Context, name string, desired *kvcore
 
 const vmDiskSizeParameter = "diskSize"
 
+// vmDiskSize returns the size of the primary disk in GiB as requested by the user.
+func vmDiskSize(job *orc.Job) int {
+	app := &job.Status.ResolvedApplication.Value
+	parametersAndValues := ctrl.JobFindParamAndValues(
+		job,
+		&app.Invocation,
+		requestDynamicParameters(job.Owner, app),
+	)
+	diskSizeParam, ok := parametersAndValues[vmDiskSizeParameter]
+	diskSize := 50
+	if ok {
+		intVal, ok := diskSizeParam.Value.Value.(float64)
+		if ok {
+			diskSize = int(intVal)
+		}
+	}
+
+	return min(max(15, diskSize), 2000)
+}
+
 func JobFolder(job *orc.Job) (string, *util.HttpError) {
 	internalMemberFiles, _, herr := filesystem.InitializeMemberFiles(job.Owner.CreatedBy, job.Owner.Project)
 	if herr != nil {
@@ -47,6 +47,7 @@ func Monitor(tracker shared.JobTracker, jobs map[string]*orc.Job) {
 	}
 
 	cleanupReleasedPVs()
+	snapshotRefreshEstimatedUsage(jobs)
 
 	activeInstances, err := KubevirtClient.VirtualMachineInstance(Namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
@@ -0,0 +1,562 @@
+package kubevirt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	k8score "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kvcore "kubevirt.io/api/core/v1"
+	kvsnapshot "kubevirt.io/api/snapshot/v1beta1"
+	cfg "ucloud.dk/pkg/config"
+	ctrl "ucloud.dk/pkg/controller"
+	"ucloud.dk/pkg/integrations/k8s/filesystem"
+	"ucloud.dk/pkg/integrations/k8s/shared"
+	apm "ucloud.dk/shared/pkg/accounting"
+	fndapi "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/log"
+	orc "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// Snapshots of a VM are stored as VirtualMachineSnapshot objects which, through KubeVirt, create a VolumeSnapshot of
+// the primary disk. This requires the CSI driver to support snapshots. The snapshots are owned by the VM and are
+// garbage collected by Kubernetes when the VM is deleted.
+//
+// Snapshots are charged against the storage wallet of the job owner. A snapshot is charged by the restore size of its
+// volume snapshots, which is the size reported by the CSI driver. Until the driver has reported a size, the requested
+// size of the disk is used instead. This is stored in an annotation when the snapshot is created. Usage which was
+// reported from the annotation is reported again by the monitor once the actual size is known. The monitor does not
+// remember which jobs this applies to across restarts, these are instead found from the snapshots on its first pass.
+//
+// Restoring a snapshot creates a new claim for the primary disk. Unlike the original claim, these are not owned by the
+// VM and are deleted explicitly when the job terminates.
+
+const (
+	snapshotTitleAnnotation = "ucloud.dk/snapshotTitle"
+	snapshotSizeAnnotation  = "ucloud.dk/snapshotSizeGb"
+	snapshotPrimaryVolume   = "base"
+)
+
+var snapshotUsageEstimated = struct {
+	Mu     sync.Mutex
+	Seeded bool
+	Jobs   map[string]util.Empty
+}{
+	Jobs: map[string]util.Empty{},
+}
+
+func snapshotsEnabled() bool {
+	return ServiceConfig.Compute.VirtualMachines.Snapshots.Enabled
+}
+
+func snapshotListForJob(ctx context.Context, jobId string) ([]kvsnapshot.VirtualMachineSnapshot, error) {
+	label := shared.JobIdLabel(jobId)
+	list, err := KubevirtClient.VirtualMachineSnapshot(Namespace).List(ctx, k8smeta.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", label.First, label.Second),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := list.Items
+	slices.SortFunc(result, func(a, b kvsnapshot.VirtualMachineSnapshot) int {
+		return a.CreationTimestamp.Time.Compare(b.CreationTimestamp.Time)
+	})
+	return result, nil
+}
+
+func snapshotRetrieve(ctx context.Context, job *orc.Job, snapshotId string) (*kvsnapshot.VirtualMachineSnapshot, *util.HttpError) {
+	snapshot, err := KubevirtClient.VirtualMachineSnapshot(Namespace).Get(ctx, snapshotId, k8smeta.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Info("Failed to retrieve VM snapshot %s: %s", snapshotId, err)
+		}
+		return nil, util.HttpErr(http.StatusNotFound, "unknown snapshot")
+	}
+
+	label := shared.JobIdLabel(job.Id)
+	if snapshot.Labels[label.First] != label.Second {
+		return nil, util.HttpErr(http.StatusNotFound, "unknown snapshot")
+	}
+
+	return snapshot, nil
+}
+
+func snapshotToApi(jobId string, snapshot *kvsnapshot.VirtualMachineSnapshot) orc.JobSnapshot {
+	result := orc.JobSnapshot{
+		Id:        snapshot.Name,
+		JobId:     jobId,
+		Title:     snapshot.Annotations[snapshotTitleAnnotation],
+		CreatedAt: fndapi.Timestamp(snapshot.CreationTimestamp.Time),
+		State:     orc.JobSnapshotStatePending,
+		SizeInGb:  snapshotSize(snapshot),
+	}
+
+	status := snapshot.Status
+	if status != nil {
+		switch status.Phase {
+		case kvsnapshot.Succeeded:
+			if status.ReadyToUse != nil && *status.ReadyToUse {
+				result.State = orc.JobSnapshotStateReady
+			}
+
+		case kvsnapshot.Failed:
+			result.State = orc.JobSnapshotStateFailed
+		}
+
+		if status.Error != nil && status.Error.Message != nil {
+			result.Error.Set(*status.Error.Message)
+		}
+	}
+
+	return result
+}
+
+func snapshotSize(snapshot *kvsnapshot.VirtualMachineSnapshot) int64 {
+	size, err := strconv.ParseInt(snapshot.Annotations[snapshotSizeAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+func createSnapshot(job *orc.Job, title string) (string, *util.HttpError) {
+	if !snapshotsEnabled() {
+		return "", util.HttpErr(http.StatusBadRequest, "snapshots are not supported")
+	}
+
+	_, drive, herr := filesystem.InitializeMemberFiles(job.Owner.CreatedBy, job.Owner.Project)
+	if herr != nil {
+		return "", herr
+	}
+
+	lockInfo := ctrl.RetrieveResourceLockInfo(drive.Resource, drive.Specification.Product)
+	if lockInfo.Locked {
+		return "", util.HttpErr(http.StatusPaymentRequired, "%s", ctrl.MakeInsufficientFundsMessage(lockInfo, "snapshot"))
+	}
+
+	ctx := context.Background()
+	existing, err := snapshotListForJob(ctx, job.Id)
+	if err != nil {
+		log.Info("Failed to list VM snapshots: %s", err)
+		return "", util.ServerHttpError("Failed to create snapshot")
+	}
+
+	maxPerJob := ServiceConfig.Compute.VirtualMachines.Snapshots.MaxPerJob
+	if len(existing) >= maxPerJob {
+		return "", util.UserHttpError("This machine already has %d snapshots. Delete one before creating a new one.", maxPerJob)
+	}
+
+	name := vmName(job.Id, 0)
+	vm, err := KubevirtClient.VirtualMachine(Namespace).Get(ctx, name, k8smeta.GetOptions{})
+	if err != nil {
+		return "", util.HttpErr(http.StatusNotFound, "The machine is not ready yet. Try again later.")
+	}
+
+	jobIdLabel := shared.JobIdLabel(job.Id)
+	snapshot := &kvsnapshot.VirtualMachineSnapshot{
+		ObjectMeta: k8smeta.ObjectMeta{
+			Name:      fmt.Sprintf("snap-%s-%s", job.Id, util.RandomTokenNoTs(6)),
+			Namespace: Namespace,
+			Labels: map[string]string{
+				jobIdLabel.First: jobIdLabel.Second,
+			},
+			Annotations: map[string]string{
+				snapshotTitleAnnotation: title,
+				snapshotSizeAnnotation:  fmt.Sprint(vmDiskSize(job)),
+			},
+			OwnerReferences: []k8smeta.OwnerReference{
+				{
+					APIVersion: "kubevirt.io/v1",
+					Kind:       "VirtualMachine",
+					Name:       vm.Name,
+					UID:        vm.UID,
+				},
+			},
+		},
+		Spec: kvsnapshot.VirtualMachineSnapshotSpec{
+			Source: k8score.TypedLocalObjectReference{
+				APIGroup: util.Pointer("kubevirt.io"),
+				Kind:     "VirtualMachine",
+				Name:     vm.Name,
+			},
+		},
+	}
+
+	created, err := KubevirtClient.VirtualMachineSnapshot(Namespace).Create(ctx, snapshot, k8smeta.CreateOptions{})
+	if err != nil {
+		log.Info("Failed to create VM snapshot: %s", err)
+		return "", util.ServerHttpError("Failed to create snapshot")
+	}
+
+	snapshotReportUsage(job, append(existing, *created))
+	return created.Name, nil
+}
+
+func browseSnapshots(job *orc.Job) ([]orc.JobSnapshot, *util.HttpError) {
+	if !snapshotsEnabled() {
+		return nil, util.HttpErr(http.StatusBadRequest, "snapshots are not supported")
+	}
+
+	snapshots, err := snapshotListForJob(context.Background(), job.Id)
+	if err != nil {
+		log.Info("Failed to list VM snapshots: %s", err)
+		return nil, util.ServerHttpError("Failed to list snapshots")
+	}
+
+	result := []orc.JobSnapshot{}
+	for i := range snapshots {
+		result = append(result, snapshotToApi(job.Id, &snapshots[i]))
+	}
+	return result, nil
+}
+
+func restoreSnapshot(job *orc.Job, snapshotId string) *util.HttpError {
+	if !snapshotsEnabled() {
+		return util.HttpErr(http.StatusBadRequest, "snapshots are not supported")
+	}
+
+	if job.Status.State != orc.JobStateSuspended {
+		return util.UserHttpError("The machine must be suspended before a snapshot can be restored.")
+	}
+
+	ctx := context.Background()
+	snapshot, herr := snapshotRetrieve(ctx, job, snapshotId)
+	if herr != nil {
+		return herr
+	}
+
+	if snapshotToApi(job.Id, snapshot).State != orc.JobSnapshotStateReady {
+		return util.UserHttpError("This snapshot is not ready to be restored.")
+	}
+
+	name := vmName(job.Id, 0)
+	_, err := KubevirtClient.VirtualMachineInstance(Namespace).Get(ctx, name, k8smeta.GetOptions{})
+	if err == nil {
+		return util.UserHttpError("The machine is still shutting down. Try again in a moment.")
+	}
+
+	vm, err := KubevirtClient.VirtualMachine(Namespace).Get(ctx, name, k8smeta.GetOptions{})
+	if err != nil {
+		return util.HttpErr(http.StatusNotFound, "The machine could not be found.")
+	}
+
+	restore := &kvsnapshot.VirtualMachineRestore{
+		ObjectMeta: k8smeta.ObjectMeta{
+			Name:      fmt.Sprintf("restore-%s-%d", snapshot.Name, time.Now().Unix()),
+			Namespace: Namespace,
+			Labels:    snapshot.Labels,
+			OwnerReferences: []k8smeta.OwnerReference{
+				{
+					APIVersion: "kubevirt.io/v1",
+					Kind:       "VirtualMachine",
+					Name:       vm.Name,
+					UID:        vm.UID,
+				},
+			},
+		},
+		Spec: kvsnapshot.VirtualMachineRestoreSpec{
+			Target: k8score.TypedLocalObjectReference{
+				APIGroup: util.Pointer("kubevirt.io"),
+				Kind:     "VirtualMachine",
+				Name:     vm.Name,
+			},
+			VirtualMachineSnapshotName: snapshot.Name,
+		},
+	}
+
+	_, err = KubevirtClient.VirtualMachineRestore(Namespace).Create(ctx, restore, k8smeta.CreateOptions{})
+	if err != nil {
+		log.Info("Failed to restore VM snapshot: %s", err)
+		return util.ServerHttpError("Failed to restore snapshot")
+	}
+
+	return nil
+}
+
+func deleteSnapshot(job *orc.Job, snapshotId string) *util.HttpError {
+	if !snapshotsEnabled() {
+		return util.HttpErr(http.StatusBadRequest, "snapshots are not supported")
+	}
+
+	ctx := context.Background()
+	snapshot, herr := snapshotRetrieve(ctx, job, snapshotId)
+	if herr != nil {
+		return herr
+	}
+
+	err := KubevirtClient.VirtualMachineSnapshot(Namespace).Delete(ctx, snapshot.Name, k8smeta.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		log.Info("Failed to delete VM snapshot: %s", err)
+		return util.ServerHttpError("Failed to delete snapshot")
+	}
+
+	remaining, err := snapshotListForJob(ctx, job.Id)
+	if err != nil {
+		log.Info("Failed to list VM snapshots: %s", err)
+		return nil
+	}
+
+	remaining = slices.DeleteFunc(remaining, func(s kvsnapshot.VirtualMachineSnapshot) bool {
+		return s.Name == snapshot.Name
+	})
+	snapshotReportUsage(job, remaining)
+	return nil
+}
+
+// snapshotReportUsage reports the combined size of the snapshots of a job. The usage is reported with a scope unique
+// to the job, which allows it to be replaced whenever the snapshots of a job change.
+func snapshotReportUsage(job *orc.Job, snapshots []kvsnapshot.VirtualMachineSnapshot) {
+	_, drive, herr := filesystem.InitializeMemberFiles(job.Owner.CreatedBy, job.Owner.Project)
+	if herr != nil {
+		log.Warn("Unable to report snapshot usage for job %s: %s", job.Id, herr.Why)
+		return
+	}
+
+	ctx := context.Background()
+	restoreSizes := map[string]int64{}
+	for i := range snapshots {
+		if size, ok := snapshotRestoreSize(ctx, &snapshots[i]); ok {
+			restoreSizes[snapshots[i].Name] = size
+		}
+	}
+
+	usage, exact := snapshotUsageInGb(job.Id, snapshots, restoreSizes)
+
+	request := fndapi.BulkRequest[apm.ReportUsageRequest]{
+		Items: []apm.ReportUsageRequest{
+			{
+				IsDeltaCharge: false,
+				Owner:         apm.WalletOwnerFromIds(job.Owner.CreatedBy, job.Owner.Project.Value),
+				CategoryIdV2: apm.ProductCategoryIdV2{
+					Name:     drive.Specification.Product.Category,
+					Provider: cfg.Provider.Id,
+				},
+				Usage: usage,
+				Description: apm.ChargeDescription{
+					Scope: util.OptValue(fmt.Sprintf("vm-snapshots-%v-%v", cfg.Provider.Id, job.Id)),
+				},
+			},
+		},
+	}
+
+	_, err := apm.ReportUsage.Invoke(request)
+	if err != nil {
+		log.Warn("Unable to report snapshot usage for job %s: %s", job.Id, err)
+		exact = false
+	}
+
+	snapshotUsageEstimated.Mu.Lock()
+	if exact {
+		delete(snapshotUsageEstimated.Jobs, job.Id)
+	} else {
+		snapshotUsageEstimated.Jobs[job.Id] = util.Empty{}
+	}
+	snapshotUsageEstimated.Mu.Unlock()
+}
+
+// snapshotUsageInGb returns the combined size of the snapshots in GB. Snapshots without a known restore size are
+// counted with the size of the disk at the time the snapshot was taken, in which case exact is false.
+func snapshotUsageInGb(
+	jobId string,
+	snapshots []kvsnapshot.VirtualMachineSnapshot,
+	restoreSizes map[string]int64,
+) (usage int64, exact bool) {
+	exact = true
+	bytes := int64(0)
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+		state := snapshotToApi(jobId, snapshot).State
+		if state == orc.JobSnapshotStateFailed {
+			continue
+		}
+
+		size, ok := restoreSizes[snapshot.Name]
+		if !ok {
+			// Disks are sized in GiB while storage is reported in GB
+			size = snapshotSize(snapshot) * (1 << 30)
+			exact = false
+		}
+		bytes += size
+	}
+
+	return (bytes + 1000000000 - 1) / 1000000000, exact
+}
+
+// snapshotRestoreSize returns the combined restore size, in bytes, of the volume snapshots belonging to a snapshot.
+// The size is only known once the snapshot is ready.
+func snapshotRestoreSize(ctx context.Context, snapshot *kvsnapshot.VirtualMachineSnapshot) (int64, bool) {
+	status := snapshot.Status
+	if status == nil || status.VirtualMachineSnapshotContentName == nil {
+		return 0, false
+	}
+
+	if snapshotToApi("", snapshot).State != orc.JobSnapshotStateReady {
+		return 0, false
+	}
+
+	content, err := KubevirtClient.VirtualMachineSnapshotContent(Namespace).Get(
+		ctx,
+		*status.VirtualMachineSnapshotContentName,
+		k8smeta.GetOptions{},
+	)
+	if err != nil || content.Status == nil || len(content.Status.VolumeSnapshotStatus) == 0 {
+		return 0, false
+	}
+
+	size := int64(0)
+	for _, volumeStatus := range content.Status.VolumeSnapshotStatus {
+		volumeSnapshot, err := KubevirtClient.KubernetesSnapshotClient().SnapshotV1().VolumeSnapshots(Namespace).Get(
+			ctx,
+			volumeStatus.VolumeSnapshotName,
+			k8smeta.GetOptions{},
+		)
+
+		if err != nil || volumeSnapshot.Status == nil || volumeSnapshot.Status.RestoreSize == nil {
+			return 0, false
+		}
+
+		size += volumeSnapshot.Status.RestoreSize.Value()
+	}
+	return size, true
+}
+
+// snapshotRefreshEstimatedUsage reports the snapshot usage of jobs again if it was reported before the restore size
+// of every snapshot was known.
+func snapshotRefreshEstimatedUsage(jobs map[string]*orc.Job) {
+	snapshotUsageEstimated.Mu.Lock()
+	seeded := snapshotUsageEstimated.Seeded
+	snapshotUsageEstimated.Mu.Unlock()
+
+	if !seeded {
+		snapshotSeedEstimatedUsage(context.Background())
+	}
+
+	snapshotUsageEstimated.Mu.Lock()
+	var pending []*orc.Job
+	for jobId := range snapshotUsageEstimated.Jobs {
+		job, ok := jobs[jobId]
+		if !ok {
+			delete(snapshotUsageEstimated.Jobs, jobId)
+			continue
+		}
+		pending = append(pending, job)
+	}
+	snapshotUsageEstimated.Mu.Unlock()
+
+	for _, job := range pending {
+		snapshots, err := snapshotListForJob(context.Background(), job.Id)
+		if err != nil {
+			log.Info("Failed to list VM snapshots: %s", err)
+			continue
+		}
+
+		snapshotReportUsage(job, snapshots)
+	}
+}
+
+// snapshotSeedEstimatedUsage finds the jobs which have snapshots charged by the requested size of the disk. This is
+// used to recover the set of jobs which must be reported again after a restart.
+func snapshotSeedEstimatedUsage(ctx context.Context) {
+	label := shared.JobIdLabel("")
+	list, err := KubevirtClient.VirtualMachineSnapshot(Namespace).List(ctx, k8smeta.ListOptions{
+		LabelSelector: label.First,
+	})
+
+	if err != nil {
+		log.Info("Failed to list VM snapshots: %s", err)
+		return
+	}
+
+	var jobs []string
+	for i := range list.Items {
+		snapshot := &list.Items[i]
+		jobId := snapshot.Labels[label.First]
+		if jobId == "" || slices.Contains(jobs, jobId) || !snapshotUsageIsEstimated(snapshot) {
+			continue
+		}
+
+		if _, ok := snapshotRestoreSize(ctx, snapshot); !ok {
+			jobs = append(jobs, jobId)
+		}
+	}
+
+	snapshotUsageEstimated.Mu.Lock()
+	for _, jobId := range jobs {
+		snapshotUsageEstimated.Jobs[jobId] = util.Empty{}
+	}
+	snapshotUsageEstimated.Seeded = true
+	snapshotUsageEstimated.Mu.Unlock()
+}
+
+// snapshotUsageIsEstimated returns true if the snapshot is charged by the size stored in its annotation until its
+// restore size is known.
+func snapshotUsageIsEstimated(snapshot *kvsnapshot.VirtualMachineSnapshot) bool {
+	if _, ok := snapshot.Annotations[snapshotSizeAnnotation]; !ok {
+		return false
+	}
+	return snapshotToApi("", snapshot).State != orc.JobSnapshotStateFailed
+}
+
+// snapshotRestoredClaims returns the claims created by restoring snapshots of a job. These must be listed before the
+// VM is deleted, since the restores are owned by the VM.
+func snapshotRestoredClaims(ctx context.Context, jobId string) ([]string, error) {
+	label := shared.JobIdLabel(jobId)
+	list, err := KubevirtClient.VirtualMachineRestore(Namespace).List(ctx, k8smeta.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", label.First, label.Second),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return snapshotClaimsFromRestores(list.Items), nil
+}
+
+func snapshotClaimsFromRestores(restores []kvsnapshot.VirtualMachineRestore) []string {
+	var result []string
+	for i := range restores {
+		status := restores[i].Status
+		if status == nil {
+			continue
+		}
+
+		for _, volume := range status.Restores {
+			if volume.PersistentVolumeClaimName != "" && !slices.Contains(result, volume.PersistentVolumeClaimName) {
+				result = append(result, volume.PersistentVolumeClaimName)
+			}
+		}
+	}
+	return result
+}
+
+// snapshotDeleteRestoredClaims deletes the claims returned by snapshotRestoredClaims.
+func snapshotDeleteRestoredClaims(ctx context.Context, claims []string) {
+	for _, claim := range claims {
+		err := shared.K8sClient.CoreV1().PersistentVolumeClaims(Namespace).Delete(ctx, claim, k8smeta.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			log.Warn("Failed to delete restored VM disk %s: %s", claim, err)
+		}
+	}
+}
+
+// vmPrimaryDiskClaim returns the claim currently used for the primary disk of a VM.
+func vmPrimaryDiskClaim(vm *kvcore.VirtualMachine) (string, bool) {
+	if vm.Spec.Template == nil {
+		return "", false
+	}
+
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		if volume.Name == snapshotPrimaryVolume && volume.PersistentVolumeClaim != nil {
+			return volume.PersistentVolumeClaim.ClaimName, true
+		}
+	}
+	return "", false
+}
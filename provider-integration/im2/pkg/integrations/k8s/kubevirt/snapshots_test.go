@@ -0,0 +1,139 @@
+package kubevirt
+
+import (
+	"slices"
+	"testing"
+
+	k8score "k8s.io/api/core/v1"
+	k8smeta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kvcore "kubevirt.io/api/core/v1"
+	kvsnapshot "kubevirt.io/api/snapshot/v1beta1"
+	"ucloud.dk/shared/pkg/util"
+)
+
+func snapshotTestSnapshot(name string, sizeInGib string, phase kvsnapshot.VirtualMachineSnapshotPhase) kvsnapshot.VirtualMachineSnapshot {
+	return kvsnapshot.VirtualMachineSnapshot{
+		ObjectMeta: k8smeta.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{snapshotSizeAnnotation: sizeInGib},
+		},
+		Status: &kvsnapshot.VirtualMachineSnapshotStatus{
+			Phase:      phase,
+			ReadyToUse: util.Pointer(phase == kvsnapshot.Succeeded),
+		},
+	}
+}
+
+func TestSnapshotUsageUsesRestoreSize(t *testing.T) {
+	snapshots := []kvsnapshot.VirtualMachineSnapshot{
+		snapshotTestSnapshot("a", "50", kvsnapshot.Succeeded),
+		snapshotTestSnapshot("b", "50", kvsnapshot.Succeeded),
+	}
+
+	// The restore size reported by the CSI driver is charged rather than the requested size of the disk
+	usage, exact := snapshotUsageInGb("1", snapshots, map[string]int64{
+		"a": 3_000_000_000,
+		"b": 1_500_000_001,
+	})
+
+	if !exact {
+		t.Fatalf("expected usage to be exact")
+	}
+	if usage != 5 {
+		t.Fatalf("expected 5 GB, got %d", usage)
+	}
+}
+
+func TestSnapshotUsageFallsBackToRequestedSize(t *testing.T) {
+	snapshots := []kvsnapshot.VirtualMachineSnapshot{
+		snapshotTestSnapshot("ready", "50", kvsnapshot.Succeeded),
+		snapshotTestSnapshot("pending", "10", kvsnapshot.InProgress),
+		snapshotTestSnapshot("failed", "100", kvsnapshot.Failed),
+	}
+
+	usage, exact := snapshotUsageInGb("1", snapshots, map[string]int64{"ready": 2_000_000_000})
+	if exact {
+		t.Fatalf("expected usage to be estimated while a snapshot is pending")
+	}
+
+	// 10 GiB is 10.74 GB, failed snapshots are not charged
+	if usage != 13 {
+		t.Fatalf("expected 13 GB, got %d", usage)
+	}
+
+	usage, exact = snapshotUsageInGb("1", nil, nil)
+	if !exact || usage != 0 {
+		t.Fatalf("expected no usage without snapshots, got %d (exact = %v)", usage, exact)
+	}
+}
+
+func TestSnapshotUsageIsEstimated(t *testing.T) {
+	ready := snapshotTestSnapshot("ready", "50", kvsnapshot.Succeeded)
+	pending := snapshotTestSnapshot("pending", "10", kvsnapshot.InProgress)
+	failed := snapshotTestSnapshot("failed", "100", kvsnapshot.Failed)
+	unannotated := snapshotTestSnapshot("unannotated", "", kvsnapshot.Succeeded)
+	delete(unannotated.Annotations, snapshotSizeAnnotation)
+
+	if !snapshotUsageIsEstimated(&ready) || !snapshotUsageIsEstimated(&pending) {
+		t.Fatalf("expected snapshots with a requested size to be estimated")
+	}
+	if snapshotUsageIsEstimated(&failed) {
+		t.Fatalf("expected failed snapshots to not be charged")
+	}
+	if snapshotUsageIsEstimated(&unannotated) {
+		t.Fatalf("expected snapshots without a requested size to not be estimated")
+	}
+}
+
+func TestSnapshotClaimsFromRestores(t *testing.T) {
+	restore := func(claims ...string) kvsnapshot.VirtualMachineRestore {
+		result := kvsnapshot.VirtualMachineRestore{Status: &kvsnapshot.VirtualMachineRestoreStatus{}}
+		for _, claim := range claims {
+			result.Status.Restores = append(result.Status.Restores, kvsnapshot.VolumeRestore{
+				VolumeName:                snapshotPrimaryVolume,
+				PersistentVolumeClaimName: claim,
+			})
+		}
+		return result
+	}
+
+	claims := snapshotClaimsFromRestores([]kvsnapshot.VirtualMachineRestore{
+		restore("restore-1-base"),
+		{},
+		restore("restore-2-base", ""),
+		restore("restore-1-base"),
+	})
+
+	if !slices.Equal(claims, []string{"restore-1-base", "restore-2-base"}) {
+		t.Fatalf("unexpected claims: %v", claims)
+	}
+}
+
+func TestVmPrimaryDiskClaim(t *testing.T) {
+	vm := &kvcore.VirtualMachine{}
+	if _, ok := vmPrimaryDiskClaim(vm); ok {
+		t.Fatalf("expected no claim without a template")
+	}
+
+	claimVolume := func(name string, claim string) kvcore.Volume {
+		return kvcore.Volume{
+			Name: name,
+			VolumeSource: kvcore.VolumeSource{
+				PersistentVolumeClaim: &kvcore.PersistentVolumeClaimVolumeSource{
+					PersistentVolumeClaimVolumeSource: k8score.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+				},
+			},
+		}
+	}
+
+	vm.Spec.Template = &kvcore.VirtualMachineInstanceTemplateSpec{}
+	vm.Spec.Template.Spec.Volumes = []kvcore.Volume{
+		claimVolume("cloudinit", "other"),
+		claimVolume(snapshotPrimaryVolume, "restore-1-base"),
+	}
+
+	claim, ok := vmPrimaryDiskClaim(vm)
+	if !ok || claim != "restore-1-base" {
+		t.Fatalf("unexpected claim: %q (ok = %v)", claim, ok)
+	}
+}
@@ -32,6 +32,7 @@ import (
 
 var nextAccounting time.Time
 var nextMountReport time.Time
+var nextSchedulerUsagePersist time.Time
 
 // NOTE(Dan): This must only be used by code invoked from the goroutine in loopMonitoring. None of the code is
 // thread-safe.
@@ -47,12 +48,117 @@ func getScheduler(category string, group string) (*Scheduler, bool) {
 
 	existing, ok := schedulers[schedKey]
 	if !ok {
-		schedulers[schedKey] = NewScheduler(schedKey)
-		existing, ok = schedulers[schedKey]
+		existing = NewScheduler(schedKey)
+		if shared.ServiceConfig != nil {
+			schedConfig := shared.ServiceConfig.Compute.Scheduler
+			existing.WeightAge = schedConfig.WeightAge
+			existing.WeightFairShare = schedConfig.WeightFairShare
+			existing.WeightJobSize = schedConfig.WeightJobSize
+			existing.FairShareHalfLife = schedConfig.FairShareHalfLife
+		}
+		loadSchedulerUsage(existing)
+		schedulers[schedKey] = existing
+		ok = true
 	}
 	return existing, ok
 }
 
+func schedulerOwner(job *orc.Job) string {
+	owner := orc.ResourceOwnerToWalletOwner(job.Resource)
+	return owner.Reference()
+}
+
+// loadSchedulerUsage restores the fair-share usage of a scheduler from the database. This allows the fair-share factor
+// to survive a restart of the integration module.
+func loadSchedulerUsage(sched *Scheduler) {
+	type row struct {
+		Workspace string
+		Usage     float64
+		UpdatedAt time.Time
+	}
+
+	rows := db.NewTx(func(tx *db.Transaction) []row {
+		return db.Select[row](
+			tx,
+			`
+				select workspace, usage, updated_at
+				from k8s.scheduler_usage
+				where scheduler = :scheduler
+			`,
+			db.Params{
+				"scheduler": sched.Name,
+			},
+		)
+	})
+
+	now := time.Now()
+	for _, r := range rows {
+		sched.RestoreUsage(r.Workspace, r.Usage, r.UpdatedAt, now)
+	}
+}
+
+func persistSchedulerUsage() {
+	db.NewTx0(func(tx *db.Transaction) {
+		for _, sched := range schedulers {
+			var workspaces []string
+			var usage []float64
+			for workspace, value := range sched.Usage {
+				workspaces = append(workspaces, workspace)
+				usage = append(usage, value)
+			}
+
+			if len(workspaces) == 0 {
+				db.Exec(
+					tx,
+					`
+						delete from k8s.scheduler_usage
+						where scheduler = :scheduler
+					`,
+					db.Params{
+						"scheduler": sched.Name,
+					},
+				)
+				continue
+			}
+
+			db.Exec(
+				tx,
+				`
+					delete from k8s.scheduler_usage
+					where
+						scheduler = :scheduler
+						and workspace != all(cast(:workspaces as text[]))
+				`,
+				db.Params{
+					"scheduler":  sched.Name,
+					"workspaces": workspaces,
+				},
+			)
+
+			db.Exec(
+				tx,
+				`
+					with data as (
+						select unnest(cast(:workspaces as text[])) as workspace, unnest(cast(:usage as float8[])) as usage
+					)
+					insert into k8s.scheduler_usage(scheduler, workspace, usage, updated_at)
+					select :scheduler, workspace, usage, :updated_at
+					from data
+					on conflict (scheduler, workspace) do update set
+						usage = excluded.usage,
+						updated_at = excluded.updated_at
+				`,
+				db.Params{
+					"scheduler":  sched.Name,
+					"workspaces": workspaces,
+					"usage":      usage,
+					"updated_at": sched.UsageUpdatedAt,
+				},
+			)
+		}
+	})
+}
+
 func getSchedulerByJob(job *orc.Job) (*Scheduler, bool) {
 	product := job.Specification.Product
 	_, isIApp := controller.IntegratedApplications[product.Category]
@@ -118,7 +224,7 @@ func (t *jobTracker) TrackState(state shared.JobReplicaState) bool {
 	}
 
 	if state.State == orc.JobStateRunning && state.Node.Present {
-		sched.RegisterRunningReplica(state.Id, state.Rank, shared.JobDimensions(job), state.Node.Value, nil,
+		sched.RegisterRunningReplica(state.Id, schedulerOwner(job), state.Rank, shared.JobDimensions(job), state.Node.Value, nil,
 			timeAllocationOrDefault(job.Specification.TimeAllocation))
 	}
 	if state.Node.Present && state.State != orc.JobStateInQueue {
@@ -145,6 +251,16 @@ func (t *jobTracker) TrackState(state shared.JobReplicaState) bool {
 
 	gang.replicaState[state.Rank] = state
 
+	if job.Specification.Sweep.Present {
+		// The tasks of a job array run independently of each other. Pending tasks are also registered to make sure
+		// that they count towards the concurrency limit of the array. The overall state is reported by trackArrays.
+		if state.State != orc.JobStateRunning && !state.State.IsFinal() && state.Node.Present {
+			sched.RegisterRunningReplica(state.Id, schedulerOwner(job), state.Rank, shared.JobDimensions(job),
+				state.Node.Value, nil, timeAllocationOrDefault(job.Specification.TimeAllocation))
+		}
+		return true
+	}
+
 	if len(gang.replicaState) >= job.Specification.Replicas {
 		var allNodes []string
 		reportNodes := true
@@ -187,6 +303,67 @@ func (t *jobTracker) TrackState(state shared.JobReplicaState) bool {
 	return true
 }
 
+// trackArrays reports the aggregated state of all job arrays. Tasks which have not reported a state are still waiting
+// for the scheduler and are counted as being in the queue. The array is only considered done once all of its tasks
+// have reached a final state.
+func (t *jobTracker) trackArrays() {
+	for jobId, job := range t.jobs {
+		if !job.Specification.Sweep.Present || !backendIsContainers(job) {
+			continue
+		}
+
+		total := job.Specification.ArrayTaskCount()
+		status := orc.JobArrayStatus{Total: total}
+		for rank, replica := range t.gangs[jobId].replicaState {
+			if rank >= total {
+				continue
+			}
+
+			switch replica.State {
+			case orc.JobStateRunning:
+				status.Running++
+			case orc.JobStateSuccess:
+				status.Success++
+			case orc.JobStateFailure, orc.JobStateExpired:
+				status.Failure++
+			}
+		}
+		status.InQueue = total - status.Running - status.Success - status.Failure
+
+		finished := status.Success + status.Failure
+		state := orc.JobStateInQueue
+		message := util.OptNone[string]()
+		if finished == total {
+			if status.Failure > 0 {
+				state = orc.JobStateFailure
+				message.Set(fmt.Sprintf("%d of %d tasks failed.", status.Failure, total))
+			} else {
+				state = orc.JobStateSuccess
+				message.Set(fmt.Sprintf("All %d tasks have completed.", total))
+			}
+		} else if status.Running > 0 || finished > 0 {
+			state = orc.JobStateRunning
+		}
+
+		t.batch.TrackState(jobId, state, message)
+		t.batch.TrackArrayTasks(jobId, status)
+	}
+}
+
+// pendingArrayTasks returns the tasks of a job array which have not yet been started.
+func (t *jobTracker) pendingArrayTasks(job *orc.Job) []int {
+	started := t.gangs[job.Id].replicaState
+
+	var result []int
+	total := job.Specification.ArrayTaskCount()
+	for task := 0; task < total; task++ {
+		if _, ok := started[task]; !ok {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
 func (t *jobTracker) PreserveState(jobId string) {
 	t.batch.PreserveState(jobId)
 }
@@ -350,6 +527,9 @@ func initJobQueue() {
 	for _, job := range jobs {
 		if job.Status.State == orc.JobStateInQueue {
 			shared.RequestSchedule(job)
+		} else if job.Status.State == orc.JobStateRunning && job.Specification.Sweep.Present {
+			// Job arrays can have tasks which are yet to be started while other tasks are running
+			shared.RequestSchedule(job)
 		}
 	}
 }
@@ -691,19 +871,32 @@ func loopMonitoring() {
 	kubevirt.Monitor(tracker, kubevirtJobs)
 	metricMonitoring.WithLabelValues("VirtualMachineMonitor").Observe(timer.Mark().Seconds())
 	publishNodeLifecycleEvents(tracker, nodeLifecycleEvents)
+	tracker.trackArrays()
 
 	timer.Mark()
 	for _, sched := range schedulers {
 		sched.PruneReplicas()
+		sched.TrackUsage(now)
 
 		length := len(sched.Queue)
 		for i := 0; i < length; i++ {
 			queueEntry := &sched.Queue[i]
+			if queueEntry.ArrayTasks != nil {
+				// Job arrays are tracked by trackArrays
+				continue
+			}
 			tracker.batch.TrackState(queueEntry.JobId, orc.JobStateInQueue, util.OptNone[string]())
 		}
 	}
 	metricMonitoring.WithLabelValues("PruneAndTrack").Observe(timer.Mark().Seconds())
 
+	if now.After(nextSchedulerUsagePersist) {
+		timer.Mark()
+		persistSchedulerUsage()
+		nextSchedulerUsagePersist = now.Add(1 * time.Minute)
+		metricMonitoring.WithLabelValues("PersistSchedulerUsage").Observe(timer.Mark().Seconds())
+	}
+
 	timer.Mark()
 	batchResults := tracker.batch.End()
 	metricMonitoring.WithLabelValues("EndBatch").Observe(timer.Mark().Seconds())
@@ -854,7 +1047,8 @@ func loopMonitoring() {
 				},
 				Usage: accUnits,
 				Description: apm.ChargeDescription{
-					Scope: util.OptValue(fmt.Sprintf("job-%v", job.Id)),
+					Scope:   util.OptValue(fmt.Sprintf("job-%v", job.Id)),
+					Product: util.OptValue(job.Specification.Product.Id),
 				},
 			})
 		}
@@ -985,13 +1179,17 @@ func loopMonitoring() {
 		}
 
 		sched, ok := getSchedulerByJob(entry)
-		if ok {
+		if ok && entry.Specification.Sweep.Present {
+			sched.RegisterArrayInQueue(entry.Id, schedulerOwner(entry), shared.JobDimensions(entry),
+				tracker.pendingArrayTasks(entry), entry.Specification.Sweep.Value.MaxConcurrency, entry, entry.CreatedAt,
+				timeAllocationOrDefault(entry.Specification.TimeAllocation))
+		} else if ok {
 			if len(sched.JobReplicaEntries(entry.Id)) > 0 {
 				shared.RequestSchedule(entry)
 				continue
 			}
 
-			sched.RegisterJobInQueue(entry.Id, shared.JobDimensions(entry),
+			sched.RegisterJobInQueue(entry.Id, schedulerOwner(entry), shared.JobDimensions(entry),
 				entry.Specification.Replicas, entry, entry.CreatedAt, timeAllocationOrDefault(entry.Specification.TimeAllocation))
 
 			if !sched.JobInQueue(entry.Id) {
@@ -1028,7 +1226,13 @@ func loopMonitoring() {
 			sendMessages := true
 			_, isIApp := controller.IntegratedApplications[job.Specification.Product.Category]
 
-			if job.Specification.Replicas == 1 {
+			if job.Specification.Sweep.Present {
+				job = shared.JobForArrayTask(job, toSchedule.Rank)
+				localMessages = append(localMessages, controller.JobMessage{
+					JobId:   job.Id,
+					Message: fmt.Sprintf("Task %d has been scheduled and is starting soon (Assigned to %s)", toSchedule.Rank, toSchedule.Node),
+				})
+			} else if job.Specification.Replicas == 1 {
 				localMessages = append(localMessages, controller.JobMessage{
 					JobId:   job.Id,
 					Message: fmt.Sprintf("Job has been scheduled and is starting soon (Assigned to %s)", toSchedule.Node),
@@ -36,6 +36,15 @@ type Scheduler struct {
 	WeightFairShare float64
 	WeightJobSize   float64
 
+	// FairShareHalfLife controls how quickly the recorded usage of a workspace decays. A half-life of zero disables
+	// decay entirely.
+	FairShareHalfLife time.Duration
+
+	// Usage contains the decayed usage (in CPU millisecond-seconds) of each workspace which has run replicas through
+	// this scheduler. The usage is updated by TrackUsage and is used for calculating the fair-share factor.
+	Usage          map[string]float64
+	UsageUpdatedAt time.Time
+
 	Flags SchedulerFlag
 
 	DumpStateToFile util.Option[string]
@@ -94,6 +103,9 @@ func NewScheduler(name string) *Scheduler {
 		WeightAge:       1,
 		WeightFairShare: 1,
 		WeightJobSize:   1,
+
+		FairShareHalfLife: 7 * 24 * time.Hour,
+		Usage:             make(map[string]float64),
 	}
 }
 
@@ -109,6 +121,7 @@ type SchedulerNode struct {
 
 type SchedulerQueueEntry struct {
 	JobId string
+	Owner string
 	shared.SchedulerDimensions
 	Replicas    int
 	LastSeen    int
@@ -121,10 +134,19 @@ type SchedulerQueueEntry struct {
 		FairShare float64
 		JobSize   float64
 	}
+
+	// ArrayTasks contains the tasks of a job array which have not yet been placed. Each task is placed as a single
+	// replica whose rank is the index of the task. The entry remains in the queue until all tasks have been placed.
+	ArrayTasks []int
+
+	// MaxConcurrency limits the number of replicas of a job array which can be placed at the same time. A value of
+	// zero means that the job array is not throttled.
+	MaxConcurrency int
 }
 
 type SchedulerReplicaEntry struct {
 	JobId string
+	Owner string
 	Rank  int
 	shared.SchedulerDimensions
 	Node      string
@@ -174,6 +196,7 @@ func (s *Scheduler) PruneNodes() []string {
 
 func (s *Scheduler) RegisterRunningReplica(
 	jobId string,
+	owner string,
 	rank int,
 	dimensions shared.SchedulerDimensions,
 	node string,
@@ -199,6 +222,7 @@ func (s *Scheduler) RegisterRunningReplica(
 
 	s.Replicas = append(s.Replicas, SchedulerReplicaEntry{
 		JobId:               jobId,
+		Owner:               owner,
 		Rank:                rank,
 		SchedulerDimensions: dimensions,
 		Node:                node,
@@ -248,6 +272,7 @@ func (s *Scheduler) PruneReplicas() []SchedulerReplicaEntry {
 
 func (s *Scheduler) RegisterJobInQueue(
 	jobId string,
+	owner string,
 	dimensions shared.SchedulerDimensions,
 	replicas int,
 	data any,
@@ -258,6 +283,7 @@ func (s *Scheduler) RegisterJobInQueue(
 	if len(s.JobReplicaEntries(jobId)) == 0 && !s.JobInQueue(jobId) {
 		s.Queue = append(s.Queue, SchedulerQueueEntry{
 			JobId:               jobId,
+			Owner:               owner,
 			SchedulerDimensions: dimensions,
 			Replicas:            replicas,
 			LastSeen:            s.Time,
@@ -269,6 +295,47 @@ func (s *Scheduler) RegisterJobInQueue(
 	metricRegisterInQueueDuration.WithLabelValues(s.Name).Observe(timer.Mark().Seconds())
 }
 
+// RegisterArrayInQueue registers the pending tasks of a job array in the queue. Unlike RegisterJobInQueue, this will
+// also register the job if some of its tasks are already running. Only tasks which have not yet been started should
+// be passed in tasks.
+func (s *Scheduler) RegisterArrayInQueue(
+	jobId string,
+	owner string,
+	dimensions shared.SchedulerDimensions,
+	tasks []int,
+	maxConcurrency int,
+	data any,
+	submittedAt fnd.Timestamp,
+	jobLength orc.SimpleDuration,
+) {
+	timer := util.NewTimer()
+	if len(tasks) > 0 && !s.arrayInQueue(jobId) {
+		s.Queue = append(s.Queue, SchedulerQueueEntry{
+			JobId:               jobId,
+			Owner:               owner,
+			SchedulerDimensions: dimensions,
+			Replicas:            1,
+			LastSeen:            s.Time,
+			Data:                data,
+			SubmittedAt:         submittedAt,
+			JobLength:           jobLength,
+			ArrayTasks:          slices.Clone(tasks),
+			MaxConcurrency:      maxConcurrency,
+		})
+	}
+	metricRegisterInQueueDuration.WithLabelValues(s.Name).Observe(timer.Mark().Seconds())
+}
+
+func (s *Scheduler) arrayInQueue(jobId string) bool {
+	length := len(s.Queue)
+	for i := 0; i < length; i++ {
+		if s.Queue[i].JobId == jobId {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Scheduler) UpdateTimeAllocation(jobId string, newJobLength orc.SimpleDuration) {
 	length := len(s.Queue)
 	for i := 0; i < length; i++ {
@@ -384,6 +451,7 @@ func (s *Scheduler) Schedule() []SchedulerReplicaEntry {
 
 			timeInQueueSpan := maxWallTimeInQueue - minWallTimeInQueue
 			cpusUsedSpan := float64(maxCpu) - float64(minCpu)
+			fairShares := s.fairShareFactors()
 
 			for i := 0; i < queueLength; i++ {
 				entry := &s.Queue[i]
@@ -408,8 +476,12 @@ func (s *Scheduler) Schedule() []SchedulerReplicaEntry {
 					}
 				}
 
-				// TODO fairshare
-				entry.Factors.FairShare = 1.0
+				fairShare, ok := fairShares[entry.Owner]
+				if ok {
+					entry.Factors.FairShare = fairShare
+				} else {
+					entry.Factors.FairShare = 1.0
+				}
 
 				entry.Priority =
 					s.WeightAge*entry.Factors.Age +
@@ -450,6 +522,19 @@ func (s *Scheduler) Schedule() []SchedulerReplicaEntry {
 	allowBackfill := s.Flags&SchedulerDisableBackfill == 0
 	for queueIdx := 0; queueIdx < len(s.Queue); queueIdx++ {
 		entry := &s.Queue[queueIdx]
+		if entry.ArrayTasks != nil {
+			placed, throttled := s.scheduleArrayTasks(entry, allNodes)
+			result = append(result, placed...)
+
+			if len(entry.ArrayTasks) == 0 {
+				s.Queue = append(s.Queue[:queueIdx], s.Queue[queueIdx+1:]...)
+				queueIdx--
+			} else if len(placed) == 0 && !throttled && !allowBackfill {
+				break
+			}
+			continue
+		}
+
 		allocatedNodes := s.trySchedule(entry, allNodes, false)
 
 		// TODO Only backfill if the job we are scheduling can likely complete before the head of the queue
@@ -459,6 +544,7 @@ func (s *Scheduler) Schedule() []SchedulerReplicaEntry {
 			for rank := 0; rank < len(allocatedNodes); rank++ {
 				replicaEntry := SchedulerReplicaEntry{
 					JobId:               entry.JobId,
+					Owner:               entry.Owner,
 					Rank:                rank,
 					SchedulerDimensions: entry.SchedulerDimensions,
 					Node:                allocatedNodes[rank],
@@ -496,6 +582,7 @@ func (s *Scheduler) Schedule() []SchedulerReplicaEntry {
 			Replicas []SchedulerReplicaEntry
 			Nodes    map[string]*SchedulerNode
 			Time     int
+			Usage    map[string]float64
 		}{}
 
 		safeWrapper.Name = s.Name
@@ -503,6 +590,7 @@ func (s *Scheduler) Schedule() []SchedulerReplicaEntry {
 		safeWrapper.Replicas = s.Replicas
 		safeWrapper.Nodes = s.Nodes
 		safeWrapper.Time = s.Time
+		safeWrapper.Usage = s.Usage
 
 		bytes, err := json.Marshal(safeWrapper)
 		if err == nil {
@@ -518,6 +606,146 @@ func (s *Scheduler) Schedule() []SchedulerReplicaEntry {
 	return result
 }
 
+// TrackUsage decays the usage recorded for each workspace and charges the replicas currently known to the scheduler
+// for the time which has passed since the last call. This function should be called once per monitoring cycle after
+// the running replicas have been registered and pruned.
+func (s *Scheduler) TrackUsage(now time.Time) {
+	if s.Usage == nil {
+		s.Usage = make(map[string]float64)
+	}
+
+	if s.UsageUpdatedAt.IsZero() {
+		s.UsageUpdatedAt = now
+		return
+	}
+
+	elapsed := now.Sub(s.UsageUpdatedAt).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	s.UsageUpdatedAt = now
+
+	if s.FairShareHalfLife > 0 {
+		decay := math.Pow(0.5, elapsed/s.FairShareHalfLife.Seconds())
+		for owner, usage := range s.Usage {
+			usage *= decay
+
+			// Forget workspaces once their usage is negligible to keep the map from growing forever.
+			if usage < 1 {
+				delete(s.Usage, owner)
+			} else {
+				s.Usage[owner] = usage
+			}
+		}
+	}
+
+	length := len(s.Replicas)
+	for i := 0; i < length; i++ {
+		replica := &s.Replicas[i]
+		if replica.Owner == "" {
+			continue
+		}
+
+		s.Usage[replica.Owner] += float64(replica.CpuMillis) * elapsed
+	}
+}
+
+// RestoreUsage loads usage which was persisted at updatedAt. The usage is decayed for the time which has passed since
+// then, and nothing is charged for it, since the scheduler does not know what was running while it was offline.
+// Usage tracking resumes from now.
+func (s *Scheduler) RestoreUsage(workspace string, usage float64, updatedAt time.Time, now time.Time) {
+	if s.Usage == nil {
+		s.Usage = make(map[string]float64)
+	}
+
+	elapsed := now.Sub(updatedAt).Seconds()
+	if s.FairShareHalfLife > 0 && elapsed > 0 {
+		usage *= math.Pow(0.5, elapsed/s.FairShareHalfLife.Seconds())
+	}
+
+	if usage >= 1 {
+		s.Usage[workspace] = usage
+	}
+	s.UsageUpdatedAt = now
+}
+
+// fairShareFactors computes the fair-share factor of every workspace which is either present in the queue or has
+// recorded usage. The factor follows the classic Slurm formula F = 2^(-U/S), where U is the workspace's share of the
+// total (decayed) usage and S is the share each workspace is entitled to. All workspaces are entitled to the same
+// share. A workspace with no usage receives a factor of 1, a workspace which has used exactly its share receives 0.5
+// and the factor approaches 0 as the workspace consumes more of the scheduler.
+func (s *Scheduler) fairShareFactors() map[string]float64 {
+	result := map[string]float64{}
+
+	totalUsage := 0.0
+	for owner, usage := range s.Usage {
+		result[owner] = 0
+		totalUsage += usage
+	}
+
+	length := len(s.Queue)
+	for i := 0; i < length; i++ {
+		result[s.Queue[i].Owner] = 0
+	}
+	delete(result, "")
+
+	if totalUsage <= 0 || len(result) == 0 {
+		for owner := range result {
+			result[owner] = 1.0
+		}
+		return result
+	}
+
+	entitledShare := 1.0 / float64(len(result))
+	for owner := range result {
+		usageShare := s.Usage[owner] / totalUsage
+		result[owner] = math.Pow(2, -usageShare/entitledShare)
+	}
+	return result
+}
+
+// scheduleArrayTasks places as many pending tasks of a job array as the nodes and the concurrency limit of the job
+// array allows. The placed tasks are removed from entry.ArrayTasks. The throttled return value indicates that no task
+// was placed because the job array has reached its concurrency limit.
+func (s *Scheduler) scheduleArrayTasks(entry *SchedulerQueueEntry, allNodes []*SchedulerNode) ([]SchedulerReplicaEntry, bool) {
+	var result []SchedulerReplicaEntry
+
+	slots := len(entry.ArrayTasks)
+	if entry.MaxConcurrency > 0 {
+		active := len(s.JobReplicaEntries(entry.JobId))
+		slots = min(slots, entry.MaxConcurrency-active)
+		if slots <= 0 {
+			return nil, true
+		}
+	}
+
+	taskEntry := *entry
+	taskEntry.Replicas = 1
+	for i := 0; i < slots; i++ {
+		allocatedNodes := s.trySchedule(&taskEntry, allNodes, false)
+		if allocatedNodes == nil {
+			break
+		}
+
+		replicaEntry := SchedulerReplicaEntry{
+			JobId:               entry.JobId,
+			Owner:               entry.Owner,
+			Rank:                entry.ArrayTasks[0],
+			SchedulerDimensions: entry.SchedulerDimensions,
+			Node:                allocatedNodes[0],
+			LastSeen:            s.Time,
+			Data:                entry.Data,
+			JobLength:           entry.JobLength,
+		}
+
+		result = append(result, replicaEntry)
+		s.Replicas = append(s.Replicas, replicaEntry)
+		entry.ArrayTasks = entry.ArrayTasks[1:]
+	}
+
+	return result, false
+}
+
 func (s *Scheduler) trySchedule(entry *SchedulerQueueEntry, allNodes []*SchedulerNode, dry bool) []string {
 	timer := util.NewTimer()
 
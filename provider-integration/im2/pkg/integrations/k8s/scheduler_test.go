@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"fmt"
+	"math"
 	"slices"
 	"testing"
 	"time"
@@ -48,7 +49,7 @@ func TestBasicScheduling(t *testing.T) {
 	jobLength := orc.SimpleDuration{1, 0, 0}
 
 	for i := 0; i < 100; i++ {
-		scheduler.RegisterJobInQueue(fmt.Sprint(i), fullNode, 1, 0, submitAt, jobLength)
+		scheduler.RegisterJobInQueue(fmt.Sprint(i), "", fullNode, 1, 0, submitAt, jobLength)
 	}
 
 	jobsSeen := map[string]bool{}
@@ -101,6 +102,7 @@ func TestTimeInQueueAffectsPriority(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		s.RegisterJobInQueue(
 			fmt.Sprint(i),
+			"",
 			dimensions,
 			1,
 			0,
@@ -175,6 +177,7 @@ func TestJobSizeAffectsPriority(t *testing.T) {
 
 			s.RegisterJobInQueue(
 				fmt.Sprint(i),
+				"",
 				shared.SchedulerDimensions{
 					CpuMillis:     1000 + (multiplierDims * factor),
 					MemoryInBytes: 1000 + (multiplierDims * factor),
@@ -220,6 +223,7 @@ func TestJobCompaction(t *testing.T) {
 		for i := 0; i < jobCount; i++ {
 			s.RegisterJobInQueue(
 				fmt.Sprint(i),
+				"",
 				shared.SchedulerDimensions{CpuMillis: request, Resources: map[string]int{}},
 				1,
 				0,
@@ -306,6 +310,7 @@ func TestFailingGpu(t *testing.T) {
 	submit := func(jobId string, gpuCount int) {
 		s.RegisterJobInQueue(
 			jobId,
+			"",
 			shared.SchedulerDimensions{
 				CpuMillis:     1000 * coresPerGpu * gpuCount,
 				MemoryInBytes: memoryPerCore * coresPerGpu * gpuCount,
@@ -534,7 +539,7 @@ func TestCpuStandardScenarioScheduling(t *testing.T) {
 	fullScheduler := NewScheduler("cpu-standard")
 	fullScheduler.RegisterNode("node-0", node, nodeLimits, false)
 	for i := 0; i < 13; i++ {
-		fullScheduler.RegisterJobInQueue(fmt.Sprintf("full-%d", i), fullDims, 1, nil, now, jobLength)
+		fullScheduler.RegisterJobInQueue(fmt.Sprintf("full-%d", i), "", fullDims, 1, nil, now, jobLength)
 	}
 
 	fullScheduled := fullScheduler.Schedule()
@@ -548,7 +553,7 @@ func TestCpuStandardScenarioScheduling(t *testing.T) {
 	fractionalScheduler := NewScheduler("cpu-standard")
 	fractionalScheduler.RegisterNode("node-0", node, nodeLimits, false)
 	for i := 0; i < 25; i++ {
-		fractionalScheduler.RegisterJobInQueue(fmt.Sprintf("frac-%d", i), fractionalDims, 1, nil, now, jobLength)
+		fractionalScheduler.RegisterJobInQueue(fmt.Sprintf("frac-%d", i), "", fractionalDims, 1, nil, now, jobLength)
 	}
 
 	fractionalScheduled := fractionalScheduler.Schedule()
@@ -577,6 +582,7 @@ func TestMixedGpuResourceTypesCanCoexist(t *testing.T) {
 	for i := 0; i < 3; i++ {
 		s.RegisterJobInQueue(
 			fmt.Sprintf("full-%d", i),
+			"",
 			shared.SchedulerDimensions{
 				CpuMillis:     1000,
 				MemoryInBytes: 1000,
@@ -594,6 +600,7 @@ func TestMixedGpuResourceTypesCanCoexist(t *testing.T) {
 	for i := 0; i < 7; i++ {
 		s.RegisterJobInQueue(
 			fmt.Sprintf("mig-%d", i),
+			"",
 			shared.SchedulerDimensions{
 				CpuMillis:     1000,
 				MemoryInBytes: 1000,
@@ -615,6 +622,7 @@ func TestMixedGpuResourceTypesCanCoexist(t *testing.T) {
 
 	s.RegisterJobInQueue(
 		"full-extra",
+		"",
 		shared.SchedulerDimensions{
 			CpuMillis:     1000,
 			MemoryInBytes: 1000,
@@ -630,6 +638,7 @@ func TestMixedGpuResourceTypesCanCoexist(t *testing.T) {
 
 	s.RegisterJobInQueue(
 		"mig-extra",
+		"",
 		shared.SchedulerDimensions{
 			CpuMillis:     1000,
 			MemoryInBytes: 1000,
@@ -647,3 +656,210 @@ func TestMixedGpuResourceTypesCanCoexist(t *testing.T) {
 		t.Fatalf("expected no additional jobs to schedule, got %d", len(extra))
 	}
 }
+
+func TestFairShareFavorsWorkspacesWithLowUsage(t *testing.T) {
+	s := NewScheduler("sched")
+	s.WeightAge = 0
+	s.WeightJobSize = 0
+	s.WeightFairShare = 1
+
+	registerNodes(s, 1, 10000)
+	dims := shared.SchedulerDimensions{CpuMillis: 1000, MemoryInBytes: 100, Resources: map[string]int{}}
+	duration := orc.SimpleDuration{Hours: 1}
+	now := time.Now()
+
+	for i := 0; i < 100; i++ {
+		s.RegisterJobInQueue(fmt.Sprintf("heavy-%d", i), "heavy", dims, 1, nil, fnd.Timestamp(now), duration)
+	}
+
+	scheduled := s.Schedule()
+	if len(scheduled) != 10 {
+		t.Fatalf("expected the node to be filled by the heavy workspace, got %d replicas", len(scheduled))
+	}
+
+	// Let the heavy workspace run for an hour
+	s.TrackUsage(now)
+	s.TrackUsage(now.Add(1 * time.Hour))
+
+	if s.Usage["heavy"] <= 0 {
+		t.Fatalf("expected the heavy workspace to have usage, got %v", s.Usage["heavy"])
+	}
+
+	for i := 0; i < 5; i++ {
+		s.RegisterJobInQueue(fmt.Sprintf("light-%d", i), "light", dims, 1, nil, fnd.Timestamp(now), duration)
+	}
+
+	// All jobs finish, which frees up the entire node
+	s.PruneReplicas()
+
+	scheduled = s.Schedule()
+	if len(scheduled) != 10 {
+		t.Fatalf("expected 10 replicas to be scheduled, got %d", len(scheduled))
+	}
+
+	lightCount := 0
+	for _, replica := range scheduled {
+		if replica.Owner == "light" {
+			lightCount++
+		}
+	}
+
+	if lightCount != 5 {
+		t.Errorf("expected all jobs from the light workspace to be scheduled first, got %d", lightCount)
+	}
+}
+
+func TestFairShareFactorsFollowUsageShare(t *testing.T) {
+	s := NewScheduler("sched")
+	s.Usage["a"] = 300
+	s.Usage["b"] = 100
+
+	dims := shared.SchedulerDimensions{CpuMillis: 1000, MemoryInBytes: 100, Resources: map[string]int{}}
+	s.RegisterJobInQueue("c-job", "c", dims, 1, nil, fnd.Timestamp(time.Now()), orc.SimpleDuration{Hours: 1})
+
+	factors := s.fairShareFactors()
+	if len(factors) != 3 {
+		t.Fatalf("expected factors for three workspaces, got %v", factors)
+	}
+
+	// Each workspace is entitled to a third of the usage. Workspace a has used 3/4, b has used 1/4 and c has used
+	// nothing.
+	expected := map[string]float64{
+		"a": math.Pow(2, -(3.0/4.0)*3),
+		"b": math.Pow(2, -(1.0/4.0)*3),
+		"c": 1,
+	}
+
+	for owner, value := range expected {
+		if !util.FloatApproxEqual(value, factors[owner]) {
+			t.Errorf("expected factor of %v to be %v but was %v", owner, value, factors[owner])
+		}
+	}
+
+	emptyScheduler := NewScheduler("sched")
+	emptyScheduler.RegisterJobInQueue("job", "a", dims, 1, nil, fnd.Timestamp(time.Now()), orc.SimpleDuration{Hours: 1})
+	if f := emptyScheduler.fairShareFactors()["a"]; !util.FloatApproxEqual(1, f) {
+		t.Errorf("expected a factor of 1 without any usage, got %v", f)
+	}
+}
+
+func TestFairShareUsageDecays(t *testing.T) {
+	s := NewScheduler("sched")
+	s.FairShareHalfLife = 1 * time.Hour
+
+	registerNodes(s, 1, 1000)
+	dims := shared.SchedulerDimensions{CpuMillis: 1000, MemoryInBytes: 100, Resources: map[string]int{}}
+	now := time.Now()
+	s.RegisterJobInQueue("job", "workspace", dims, 1, nil, fnd.Timestamp(now), orc.SimpleDuration{Hours: 1})
+	if len(s.Schedule()) != 1 {
+		t.Fatalf("expected job to be scheduled")
+	}
+
+	s.TrackUsage(now)
+	s.TrackUsage(now.Add(10 * time.Second))
+
+	initialUsage := s.Usage["workspace"]
+	if !util.FloatApproxEqual(1000*10, initialUsage) {
+		t.Fatalf("expected usage to be 10000 but was %v", initialUsage)
+	}
+
+	// The job stops running and its usage should now decay
+	s.PruneReplicas()
+	if len(s.Replicas) != 0 {
+		t.Fatalf("expected no replicas to remain, got %v", s.Replicas)
+	}
+
+	s.TrackUsage(now.Add(10*time.Second + 1*time.Hour))
+	if !util.FloatApproxEqual(initialUsage/2, s.Usage["workspace"]) {
+		t.Errorf("expected usage to be halved after one half-life, got %v", s.Usage["workspace"])
+	}
+
+	s.TrackUsage(now.Add(10*time.Second + 100*time.Hour))
+	if _, ok := s.Usage["workspace"]; ok {
+		t.Errorf("expected usage to be forgotten after many half-lives, got %v", s.Usage["workspace"])
+	}
+}
+
+func TestFairShareRestoredUsageDoesNotChargeDowntime(t *testing.T) {
+	s := NewScheduler("sched")
+	s.FairShareHalfLife = 1 * time.Hour
+
+	// The integration module was offline for two hours after the usage was persisted
+	now := time.Now()
+	s.RestoreUsage("workspace", 40000, now.Add(-2*time.Hour), now)
+	s.RestoreUsage("idle", 2, now.Add(-2*time.Hour), now)
+
+	if !util.FloatApproxEqual(10000, s.Usage["workspace"]) {
+		t.Fatalf("expected restored usage to be decayed to 10000 but was %v", s.Usage["workspace"])
+	}
+	if _, ok := s.Usage["idle"]; ok {
+		t.Errorf("expected negligible usage to be forgotten, got %v", s.Usage["idle"])
+	}
+
+	registerNodes(s, 1, 1000)
+	dims := shared.SchedulerDimensions{CpuMillis: 1000, MemoryInBytes: 100, Resources: map[string]int{}}
+	s.RegisterJobInQueue("job", "workspace", dims, 1, nil, fnd.Timestamp(now), orc.SimpleDuration{Hours: 1})
+	if len(s.Schedule()) != 1 {
+		t.Fatalf("expected job to be scheduled")
+	}
+
+	// Only the time since the restart is charged
+	s.TrackUsage(now.Add(10 * time.Second))
+	expected := 10000*math.Pow(0.5, 10.0/3600.0) + 1000*10
+	if !util.FloatApproxEqual(expected, s.Usage["workspace"]) {
+		t.Errorf("expected usage to be %v but was %v", expected, s.Usage["workspace"])
+	}
+}
+
+func TestJobArrayRespectsMaxConcurrency(t *testing.T) {
+	s := NewScheduler("sched")
+
+	registerNodes(s, 10, 1000)
+	dims := shared.SchedulerDimensions{CpuMillis: 1000, MemoryInBytes: 100, Resources: map[string]int{}}
+	now := fnd.Timestamp(time.Now())
+	s.RegisterArrayInQueue("array", "", dims, []int{0, 1, 2, 3, 4}, 2, nil, now, orc.SimpleDuration{Hours: 1})
+
+	ranks := func(entries []SchedulerReplicaEntry) []int {
+		var result []int
+		for _, entry := range entries {
+			result = append(result, entry.Rank)
+		}
+		slices.Sort(result)
+		return result
+	}
+
+	scheduled := s.Schedule()
+	if !slices.Equal(ranks(scheduled), []int{0, 1}) {
+		t.Fatalf("expected the first two tasks to be scheduled, got %v", ranks(scheduled))
+	}
+
+	// The array is throttled but other jobs can still be scheduled
+	s.RegisterJobInQueue("other", "", dims, 1, nil, now, orc.SimpleDuration{Hours: 1})
+	scheduled = s.Schedule()
+	if len(scheduled) != 1 || scheduled[0].JobId != "other" {
+		t.Fatalf("expected only the other job to be scheduled, got %v", scheduled)
+	}
+
+	// Task 0 completes and is no longer reported as running
+	s.RegisterRunningReplica("array", "", 1, dims, scheduled[0].Node, nil, orc.SimpleDuration{Hours: 1})
+	s.RegisterRunningReplica("other", "", 0, dims, scheduled[0].Node, nil, orc.SimpleDuration{Hours: 1})
+	s.PruneReplicas()
+
+	scheduled = s.Schedule()
+	if !slices.Equal(ranks(scheduled), []int{2}) {
+		t.Fatalf("expected task 2 to be scheduled, got %v", ranks(scheduled))
+	}
+	if !s.JobInQueue("array") || len(s.Queue) != 1 {
+		t.Fatalf("expected the remaining tasks to stay in the queue: %v", s.Queue)
+	}
+
+	// Without a concurrency limit, the remaining tasks are placed immediately
+	s.Queue[0].MaxConcurrency = 0
+	scheduled = s.Schedule()
+	if !slices.Equal(ranks(scheduled), []int{3, 4}) {
+		t.Fatalf("expected the remaining tasks to be scheduled, got %v", ranks(scheduled))
+	}
+	if len(s.Queue) != 0 {
+		t.Errorf("expected the array to leave the queue once all tasks were placed: %v", s.Queue)
+	}
+}
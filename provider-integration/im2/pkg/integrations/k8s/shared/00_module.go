@@ -51,6 +51,32 @@ func JobRankLabel(rank int) util.Tuple2[string, string] {
 	return util.Tuple2[string, string]{"ucloud.dk/rank", fmt.Sprint(rank)}
 }
 
+// JobPodCount returns the number of pods which can exist for a job. This is the number of replicas for normal jobs
+// and the number of tasks for job arrays. The rank of a pod in a job array is the index of its task.
+func JobPodCount(job *orc.Job) int {
+	if job.Specification.Sweep.Present {
+		return job.Specification.ArrayTaskCount()
+	}
+	return job.Specification.Replicas
+}
+
+// JobForArrayTask returns a copy of the job where the parameters have been replaced with those of a single task in
+// a job array. The job is returned unchanged if it is not a job array.
+func JobForArrayTask(job *orc.Job, task int) *orc.Job {
+	if !job.Specification.Sweep.Present {
+		return job
+	}
+
+	tasks := job.Specification.ArrayTaskParameters()
+	if task < 0 || task >= len(tasks) {
+		return job
+	}
+
+	copied := *job
+	copied.Specification.Parameters = tasks[task]
+	return &copied
+}
+
 type JobRunningTime struct {
 	TimeRemaining util.Option[time.Duration]
 	TimeConsumed  time.Duration
@@ -0,0 +1,74 @@
+package shared
+
+import (
+	"path/filepath"
+	"strings"
+
+	cfg "ucloud.dk/pkg/config"
+	orc "ucloud.dk/shared/pkg/orchestrators"
+)
+
+// FileSystemForCategory returns the file system which stores drives of the given product category. Unknown categories
+// are routed to the default file system.
+func FileSystemForCategory(category string) cfg.KubernetesFileSystem {
+	fs, ok := ServiceConfig.FileSystems[category]
+	if !ok {
+		return ServiceConfig.FileSystem
+	}
+	return fs
+}
+
+func FileSystemForDrive(drive *orc.Drive) cfg.KubernetesFileSystem {
+	if drive == nil {
+		return ServiceConfig.FileSystem
+	}
+	return FileSystemForCategory(drive.Specification.Product.Category)
+}
+
+// FileSystemSubPath returns the file system containing the internal path along with the path relative to its mount
+// point. The mount point itself is not considered to be inside the file system.
+func FileSystemSubPath(internalPath string) (cfg.KubernetesFileSystem, string, bool) {
+	cleanPath := filepath.Clean(internalPath)
+	if fs, subpath, ok := fileSystemSubPath(ServiceConfig.FileSystem, cleanPath); ok {
+		return fs, subpath, true
+	}
+
+	for _, fs := range ServiceConfig.FileSystems {
+		if fs, subpath, ok := fileSystemSubPath(fs, cleanPath); ok {
+			return fs, subpath, true
+		}
+	}
+
+	return cfg.KubernetesFileSystem{}, "", false
+}
+
+func fileSystemSubPath(fs cfg.KubernetesFileSystem, cleanPath string) (cfg.KubernetesFileSystem, string, bool) {
+	subpath, ok := strings.CutPrefix(cleanPath, filepath.Clean(fs.MountPoint)+"/")
+	if !ok {
+		return fs, "", false
+	}
+	return fs, subpath, true
+}
+
+// FileSystemVolumeName returns the name used for the pod volume which mounts the given file system. The default file
+// system keeps the name it has always had.
+func FileSystemVolumeName(fs cfg.KubernetesFileSystem) string {
+	if fs.Name == ServiceConfig.FileSystem.Name {
+		return "ucloud-filesystem"
+	}
+
+	builder := strings.Builder{}
+	for _, c := range strings.ToLower(fs.Name) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			builder.WriteRune(c)
+		} else {
+			builder.WriteRune('-')
+		}
+	}
+
+	name := "ucloud-filesystem-" + builder.String()
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-")
+}
@@ -0,0 +1,77 @@
+package shared
+
+import (
+	"testing"
+
+	cfg "ucloud.dk/pkg/config"
+	apm "ucloud.dk/shared/pkg/accounting"
+	orc "ucloud.dk/shared/pkg/orchestrators"
+)
+
+func withTestFileSystems(t *testing.T) {
+	old := ServiceConfig
+	t.Cleanup(func() { ServiceConfig = old })
+
+	storage := cfg.KubernetesFileSystem{Name: "storage", MountPoint: "/mnt/storage", ClaimName: "ucloud-user-data"}
+	scratch := cfg.KubernetesFileSystem{Name: "Scratch_NVMe", MountPoint: "/mnt/storage-scratch", ClaimName: "ucloud-scratch"}
+
+	ServiceConfig = &cfg.ServicesConfigurationKubernetes{}
+	ServiceConfig.FileSystem = storage
+	ServiceConfig.FileSystems = map[string]cfg.KubernetesFileSystem{
+		storage.Name: storage,
+		scratch.Name: scratch,
+	}
+}
+
+func TestFileSystemForDrive(t *testing.T) {
+	withTestFileSystems(t)
+
+	drive := &orc.Drive{}
+	drive.Specification.Product = apm.ProductReference{Id: "scratch", Category: "Scratch_NVMe"}
+	if fs := FileSystemForDrive(drive); fs.ClaimName != "ucloud-scratch" {
+		t.Fatalf("expected scratch drive to use the scratch file system, got %v", fs.Name)
+	}
+
+	drive.Specification.Product.Category = "unknown"
+	if fs := FileSystemForDrive(drive); fs.Name != "storage" {
+		t.Fatalf("expected unknown category to use the default file system, got %v", fs.Name)
+	}
+
+	if fs := FileSystemForDrive(nil); fs.Name != "storage" {
+		t.Fatalf("expected nil drive to use the default file system, got %v", fs.Name)
+	}
+}
+
+func TestFileSystemSubPath(t *testing.T) {
+	withTestFileSystems(t)
+
+	fs, subpath, ok := FileSystemSubPath("/mnt/storage-scratch/collections/42/file.txt")
+	if !ok || fs.Name != "Scratch_NVMe" || subpath != "collections/42/file.txt" {
+		t.Fatalf("unexpected result: %v %q %v", fs.Name, subpath, ok)
+	}
+
+	fs, subpath, ok = FileSystemSubPath("/mnt/storage/home/donna/../alice")
+	if !ok || fs.Name != "storage" || subpath != "home/alice" {
+		t.Fatalf("unexpected result: %v %q %v", fs.Name, subpath, ok)
+	}
+
+	if _, _, ok = FileSystemSubPath("/mnt/storage"); ok {
+		t.Fatalf("the mount point itself should not resolve")
+	}
+
+	if _, _, ok = FileSystemSubPath("/etc/passwd"); ok {
+		t.Fatalf("paths outside of the file systems should not resolve")
+	}
+}
+
+func TestFileSystemVolumeName(t *testing.T) {
+	withTestFileSystems(t)
+
+	if name := FileSystemVolumeName(ServiceConfig.FileSystems["storage"]); name != "ucloud-filesystem" {
+		t.Fatalf("expected default file system to keep its volume name, got %q", name)
+	}
+
+	if name := FileSystemVolumeName(ServiceConfig.FileSystems["Scratch_NVMe"]); name != "ucloud-filesystem-scratch-nvme" {
+		t.Fatalf("unexpected volume name %q", name)
+	}
+}
@@ -1,6 +1,9 @@
 package shared
 
 import (
+	"net/netip"
+	"slices"
+
 	core "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,6 +28,13 @@ var privateNetworkCIDRBlocks = []string{
 	"240.0.0.0/4",
 }
 
+var privateNetworkCIDRBlocksV6 = []string{
+	"::1/128",
+	"::ffff:0:0/96",
+	"fc00::/7",
+	"fe80::/10",
+}
+
 func AllowNetworkFromSubnet(policy *networking.NetworkPolicy, subnet string) {
 	spec := &policy.Spec
 	spec.Ingress = append(spec.Ingress, networking.NetworkPolicyIngressRule{
@@ -118,6 +128,116 @@ func AllowNetworkFromWorld(policy *networking.NetworkPolicy, proto []orc.PortRan
 	})
 }
 
+// AllowNetworkFromFirewall adds the rules of a public IP firewall to the policy. Ingress rules without CIDRs are open to
+// the world. Egress rules can never reach private networks, such as the cluster network, even if a CIDR covers them.
+//
+// A firewall with egress rules restricts all egress of the job. The policy is explicitly marked as an egress policy,
+// such that egress is denied by default even if none of the rules produced a usable peer.
+func AllowNetworkFromFirewall(policy *networking.NetworkPolicy, firewall orc.Firewall) {
+	for _, rule := range firewall.OpenPorts {
+		if rule.IsEgress() {
+			policyAddType(policy, networking.PolicyTypeIngress)
+			policyAddType(policy, networking.PolicyTypeEgress)
+			break
+		}
+	}
+
+	for _, rule := range firewall.OpenPorts {
+		ports := []networking.NetworkPolicyPort{
+			{
+				Protocol: util.Pointer(core.Protocol(rule.Protocol)),
+				Port: &intstr.IntOrString{
+					Type:   intstr.Int,
+					IntVal: int32(rule.Start),
+				},
+				EndPort: util.Pointer(int32(rule.End)),
+			},
+		}
+
+		if rule.IsEgress() {
+			peers := firewallEgressPeers(rule.AllowedCidrs)
+			if len(peers) == 0 {
+				continue
+			}
+
+			policy.Spec.Egress = append(policy.Spec.Egress, networking.NetworkPolicyEgressRule{
+				Ports: ports,
+				To:    peers,
+			})
+		} else {
+			peers := firewallIngressPeers(rule.AllowedCidrs)
+			if len(peers) == 0 {
+				continue
+			}
+
+			policy.Spec.Ingress = append(policy.Spec.Ingress, networking.NetworkPolicyIngressRule{
+				Ports: ports,
+				From:  peers,
+			})
+		}
+	}
+}
+
+func policyAddType(policy *networking.NetworkPolicy, policyType networking.PolicyType) {
+	if !slices.Contains(policy.Spec.PolicyTypes, policyType) {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, policyType)
+	}
+}
+
+func firewallIngressPeers(cidrs []string) []networking.NetworkPolicyPeer {
+	if len(cidrs) == 0 {
+		cidrs = []string{"0.0.0.0/0", "::/0"}
+	}
+
+	var result []networking.NetworkPolicyPeer
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, networking.NetworkPolicyPeer{IPBlock: &networking.IPBlock{CIDR: prefix.Masked().String()}})
+	}
+	return result
+}
+
+func firewallEgressPeers(cidrs []string) []networking.NetworkPolicyPeer {
+	if len(cidrs) == 0 {
+		cidrs = []string{"0.0.0.0/0", "::/0"}
+	}
+
+	var result []networking.NetworkPolicyPeer
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			continue
+		}
+		prefix = prefix.Masked()
+
+		privateBlocks := privateNetworkCIDRBlocks
+		if prefix.Addr().Is6() {
+			privateBlocks = privateNetworkCIDRBlocksV6
+		}
+
+		block := &networking.IPBlock{CIDR: prefix.String()}
+		insidePrivateNetwork := false
+		for _, rawPrivate := range privateBlocks {
+			private := netip.MustParsePrefix(rawPrivate)
+			if private.Bits() <= prefix.Bits() && private.Contains(prefix.Addr()) {
+				insidePrivateNetwork = true
+				break
+			} else if prefix.Bits() < private.Bits() && prefix.Contains(private.Addr()) {
+				block.Except = append(block.Except, private.String())
+			}
+		}
+
+		if !insidePrivateNetwork {
+			result = append(result, networking.NetworkPolicyPeer{IPBlock: block})
+		}
+	}
+	return result
+}
+
 func ServiceName(jobId string) string {
 	return "j-" + jobId
 }
@@ -0,0 +1,93 @@
+package shared
+
+import (
+	"slices"
+	"testing"
+
+	networking "k8s.io/api/networking/v1"
+	orc "ucloud.dk/shared/pkg/orchestrators"
+)
+
+func TestAllowNetworkFromFirewall(t *testing.T) {
+	policy := &networking.NetworkPolicy{}
+	AllowNetworkFromFirewall(policy, orc.Firewall{
+		OpenPorts: []orc.PortRangeAndProto{
+			{Start: 80, End: 80, Protocol: orc.IpProtocolTcp},
+			{Start: 22, End: 22, Protocol: orc.IpProtocolTcp, AllowedCidrs: []string{"192.0.2.0/24", "2001:db8::/32"}},
+			{Start: 443, End: 443, Protocol: orc.IpProtocolTcp, Direction: orc.FirewallDirectionEgress},
+			{Start: 5432, End: 5432, Protocol: orc.IpProtocolTcp, Direction: orc.FirewallDirectionEgress, AllowedCidrs: []string{"10.1.0.0/16"}},
+		},
+	})
+
+	if len(policy.Spec.Ingress) != 2 {
+		t.Fatalf("expected two ingress rules, got %d", len(policy.Spec.Ingress))
+	}
+
+	world := policy.Spec.Ingress[0]
+	if len(world.From) != 2 || world.From[0].IPBlock.CIDR != "0.0.0.0/0" || world.From[1].IPBlock.CIDR != "::/0" {
+		t.Fatalf("unexpected peers for unrestricted rule: %+v", world.From)
+	}
+
+	restricted := policy.Spec.Ingress[1]
+	if len(restricted.From) != 2 || restricted.From[0].IPBlock.CIDR != "192.0.2.0/24" ||
+		restricted.From[1].IPBlock.CIDR != "2001:db8::/32" {
+		t.Fatalf("unexpected peers for restricted rule: %+v", restricted.From)
+	}
+	if *restricted.Ports[0].EndPort != 22 || restricted.Ports[0].Port.IntVal != 22 {
+		t.Fatalf("unexpected ports for restricted rule: %+v", restricted.Ports)
+	}
+
+	// The rule targeting a private network is dropped entirely
+	if len(policy.Spec.Egress) != 1 {
+		t.Fatalf("expected one egress rule, got %d", len(policy.Spec.Egress))
+	}
+
+	egress := policy.Spec.Egress[0]
+	if len(egress.To) != 2 {
+		t.Fatalf("expected IPv4 and IPv6 peers for unrestricted egress, got %+v", egress.To)
+	}
+	if !slices.Contains(egress.To[0].IPBlock.Except, "10.0.0.0/8") || !slices.Contains(egress.To[1].IPBlock.Except, "fc00::/7") {
+		t.Fatalf("private networks must be excluded from egress: %+v", egress.To)
+	}
+}
+
+func TestAllowNetworkFromFirewallDeniesEgressByDefault(t *testing.T) {
+	isEgressPolicy := func(policy *networking.NetworkPolicy) bool {
+		return slices.Contains(policy.Spec.PolicyTypes, networking.PolicyTypeIngress) &&
+			slices.Contains(policy.Spec.PolicyTypes, networking.PolicyTypeEgress)
+	}
+
+	// Every egress rule targets a private network and is dropped. Egress must still be denied.
+	policy := &networking.NetworkPolicy{}
+	AllowNetworkFromFirewall(policy, orc.Firewall{
+		OpenPorts: []orc.PortRangeAndProto{
+			{Start: 5432, End: 5432, Protocol: orc.IpProtocolTcp, Direction: orc.FirewallDirectionEgress, AllowedCidrs: []string{"10.1.0.0/16"}},
+		},
+	})
+
+	if len(policy.Spec.Egress) != 0 {
+		t.Fatalf("expected no egress rules, got %+v", policy.Spec.Egress)
+	}
+	if !isEgressPolicy(policy) {
+		t.Fatalf("expected an ingress and egress policy, got %v", policy.Spec.PolicyTypes)
+	}
+
+	// Applying the firewall again does not duplicate the policy types
+	AllowNetworkFromFirewall(policy, orc.Firewall{
+		OpenPorts: []orc.PortRangeAndProto{
+			{Start: 443, End: 443, Protocol: orc.IpProtocolTcp, Direction: orc.FirewallDirectionEgress},
+		},
+	})
+	if len(policy.Spec.PolicyTypes) != 2 {
+		t.Fatalf("unexpected policy types: %v", policy.Spec.PolicyTypes)
+	}
+
+	// Firewalls without egress rules leave egress alone
+	policy = &networking.NetworkPolicy{}
+	AllowNetworkFromFirewall(policy, orc.Firewall{
+		OpenPorts: []orc.PortRangeAndProto{{Start: 80, End: 80, Protocol: orc.IpProtocolTcp}},
+	})
+	if len(policy.Spec.PolicyTypes) != 0 {
+		t.Fatalf("unexpected policy types: %v", policy.Spec.PolicyTypes)
+	}
+}
@@ -129,6 +129,7 @@ func initProducts() {
 			support.Docker.Terminal = true
 			support.Docker.Peers = true
 			support.Docker.TimeExtension = true
+			support.Docker.Arrays = true
 		}
 
 		if allowVirtualMachine {
@@ -141,6 +142,7 @@ func initProducts() {
 			support.VirtualMachine.TimeExtension = false
 			support.VirtualMachine.Suspension = true
 			support.VirtualMachine.BindLinkToPort = true
+			support.VirtualMachine.Snapshots = ServiceConfig.Compute.VirtualMachines.Snapshots.Enabled
 		}
 
 		MachineSupport = append(MachineSupport, support)
@@ -270,7 +272,8 @@ func initProducts() {
 					Provider: config.Provider.Id,
 				},
 				Firewall: orc.FirewallSupport{
-					Enabled: true,
+					Enabled:            true,
+					SourceRestrictions: ServiceConfig.Compute.PublicIps.SourceRestrictions,
 				},
 			},
 		}
@@ -303,8 +306,10 @@ func initProducts() {
 
 		LinkSupport = []orc.IngressSupport{
 			{
-				Prefix: config.Services.Kubernetes().Compute.PublicLinks.Prefix,
-				Suffix: config.Services.Kubernetes().Compute.PublicLinks.Suffix,
+				Prefix:        config.Services.Kubernetes().Compute.PublicLinks.Prefix,
+				Suffix:        config.Services.Kubernetes().Compute.PublicLinks.Suffix,
+				CustomDomains: config.Services.Kubernetes().Compute.PublicLinks.CustomDomains.Enabled,
+				AccessControl: true,
 				Product: apm.ProductReference{
 					Id:       ingressName,
 					Category: ingressName,
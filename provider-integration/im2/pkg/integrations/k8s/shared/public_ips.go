@@ -69,6 +69,10 @@ func PublicIpPrepare(job *orc.Job, firewall *networking.NetworkPolicy) PublicIpP
 				fw := &ip.Specification.Firewall
 				if fw.Present {
 					for portIdx, portRange := range fw.Value.OpenPorts {
+						if portRange.IsEgress() {
+							continue
+						}
+
 						for port := portRange.Start; port <= portRange.End; port++ {
 							service.Spec.Ports = append(service.Spec.Ports, core.ServicePort{
 								Name:     fmt.Sprintf("p-%d-%d-%d", ipIdx, portIdx, port),
@@ -82,7 +86,7 @@ func PublicIpPrepare(job *orc.Job, firewall *networking.NetworkPolicy) PublicIpP
 						}
 					}
 
-					AllowNetworkFromWorld(firewall, fw.Value.OpenPorts)
+					AllowNetworkFromFirewall(firewall, fw.Value)
 				}
 
 				envVarName := "UCLOUD_PUBLIC_IP"
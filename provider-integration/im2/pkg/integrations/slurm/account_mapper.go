@@ -422,16 +422,7 @@ func (a *defaultAccountMapper) ServerEvaluateAccountMapper(category string, owne
 		accountMapper := &ServiceConfig.Compute.AccountManagement.AccountMapper
 		switch accountMapper.Type {
 		case cfg.SlurmAccountMapperTypePattern:
-			mapper := accountMapper.Pattern()
-			pattern := ""
-			switch owner.Type {
-			case apm.WalletOwnerTypeUser:
-				pattern = mapper.Users
-			case apm.WalletOwnerTypeProject:
-				pattern = mapper.Projects
-			}
-
-			accountName = util.InjectParametersIntoString(pattern, params)
+			accountName = slurmAccountNameFromPattern(accountMapper.Pattern(), owner.Type, params)
 
 		case cfg.SlurmAccountMapperTypeScripted:
 			mapper := accountMapper.Scripted()
@@ -511,21 +502,7 @@ func (a *defaultAccountMapper) UCloudConfigurationFindSlurmAccount(config SlurmJ
 }
 
 func (a *defaultAccountMapper) ServerSlurmJobToConfiguration(job *slurmcli.Job) (result util.Option[SlurmJobConfiguration]) {
-	machines := ServiceConfig.Compute.Machines
-	candidateCategory := ""
-	for k, v := range machines {
-		if v.Qos.IsSet() && v.Qos.Get() != job.QoS {
-			continue
-		}
-		if v.Partition != job.Partition {
-			continue
-		}
-
-		candidateCategory = k
-		break
-	}
-
-	_, ok := machines[candidateCategory]
+	candidateCategory, ok := slurmJobMachineCategory(job)
 	if !ok {
 		return
 	}
@@ -545,54 +522,109 @@ func (a *defaultAccountMapper) ServerSlurmJobToConfiguration(job *slurmcli.Job)
 		return
 	}
 
-	cpuCount := getAllocTres(job, "cpu", 1)
-	memory := getAllocTres(job, "mem", 1) / (1000 * 1000 * 1000)
-	trueNodeCount := getAllocTres(job, "node", 1)
-
-	nodeMultiplier := 1
-
 	config := SlurmJobConfiguration{}
 	config.UCloudUsername = ucloudUser
 
-	// Attempt exact match
-	for _, machine := range Machines {
-		if cpuCount == machine.Cpu && memory == machine.MemoryInGigs {
-			config.EstimatedProduct = machine.ToReference()
-			break
+	config.EstimatedProduct, config.EstimatedNodeCount, ok = slurmJobEstimateProduct(job, candidateCategory)
+	if !ok {
+		return
+	}
+
+	config.Owner, ok = slurmAccountOwnerInCategory(a.ServerLookupOwnerOfAccount(job.Account), candidateCategory)
+	if !ok {
+		return
+	}
+
+	result.Set(config)
+	return
+}
+
+func slurmAccountNameFromPattern(mapper *cfg.SlurmAccountMapperPattern, ownerType apm.WalletOwnerType, params map[string]string) string {
+	pattern := ""
+	switch ownerType {
+	case apm.WalletOwnerTypeUser:
+		pattern = mapper.Users
+	case apm.WalletOwnerTypeProject:
+		pattern = mapper.Projects
+	}
+
+	return util.InjectParametersIntoString(pattern, params)
+}
+
+// slurmAccountOwnerInCategory finds the owner of a Slurm account in the machine category of a job. If several owners
+// share the account in the category, then the last one is used.
+func slurmAccountOwnerInCategory(owners []SlurmAccountOwner, category string) (apm.WalletOwner, bool) {
+	result := apm.WalletOwner{}
+	ok := false
+	for _, acc := range owners {
+		if acc.AssociatedWithCategory == category {
+			result = acc.Owner
+			ok = true
 		}
 	}
+	return result, ok
+}
 
-	// Attempt any machine which is evenly divisible
-	if config.EstimatedProduct.Id == "" {
-		for _, machine := range Machines {
-			if cpuCount%machine.Cpu == 0 {
-				config.EstimatedProduct = machine.ToReference()
-				nodeMultiplier = cpuCount / machine.Cpu
-			}
+// slurmJobMachineCategory finds the machine category which a Slurm job belongs to based on its partition and QoS.
+func slurmJobMachineCategory(job *slurmcli.Job) (string, bool) {
+	machines := ServiceConfig.Compute.Machines
+	candidateCategory := ""
+	for k, v := range machines {
+		if v.Qos.IsSet() && v.Qos.Get() != job.QoS {
+			continue
+		}
+		if v.Partition != job.Partition {
+			continue
 		}
+
+		candidateCategory = k
+		break
 	}
 
-	if config.EstimatedProduct.Id == "" {
-		return
+	_, ok := machines[candidateCategory]
+	return candidateCategory, ok
+}
+
+// slurmJobEstimateProduct estimates which product of a machine category, and how many nodes of it, a Slurm job is
+// using based on its allocated TRES. Jobs which do not exactly match a product are mapped to the largest product which
+// evenly divides the number of CPUs allocated on each node.
+//
+// The estimate is made per node since a product describes a single node. Comparing the total allocation of a multi-node
+// job against a product, and then multiplying by the node count, counts every node twice. Only products of the
+// category are considered since the job is charged to the account of that category.
+func slurmJobEstimateProduct(job *slurmcli.Job, category string) (apm.ProductReference, int, bool) {
+	trueNodeCount := max(1, getAllocTres(job, "node", 1))
+	cpuPerNode := getAllocTres(job, "cpu", 1) / trueNodeCount
+	memoryPerNode := getAllocTres(job, "mem", 1) / (1000 * 1000 * 1000) / trueNodeCount
+
+	var candidates []apm.ProductV2
+	for _, machine := range Machines {
+		if machine.Category.Name == category && machine.Cpu > 0 {
+			candidates = append(candidates, machine)
+		}
 	}
 
-	config.EstimatedNodeCount = trueNodeCount * nodeMultiplier
+	// Attempt exact match
+	for _, machine := range candidates {
+		if cpuPerNode == machine.Cpu && memoryPerNode == machine.MemoryInGigs {
+			return machine.ToReference(), trueNodeCount, true
+		}
+	}
 
-	ok = false
-	knownAccounts := a.ServerLookupOwnerOfAccount(job.Account)
-	for _, acc := range knownAccounts {
-		if acc.AssociatedWithCategory == candidateCategory {
-			config.Owner = acc.Owner
-			ok = true
+	// Attempt any machine which is evenly divisible
+	var best *apm.ProductV2
+	for i := range candidates {
+		machine := &candidates[i]
+		if cpuPerNode%machine.Cpu == 0 && (best == nil || machine.Cpu > best.Cpu) {
+			best = machine
 		}
 	}
 
-	if !ok {
-		return
+	if best == nil {
+		return apm.ProductReference{}, 0, false
 	}
 
-	result.Set(config)
-	return
+	return best.ToReference(), trueNodeCount * (cpuPerNode / best.Cpu), true
 }
 
 func getAllocTres(job *slurmcli.Job, key string, defaultValue int) int {
@@ -0,0 +1,87 @@
+package slurm
+
+import (
+	"testing"
+
+	"ucloud.dk/pkg/config"
+	"ucloud.dk/pkg/external/slurm"
+	apm "ucloud.dk/shared/pkg/accounting"
+)
+
+func TestSlurmJobEstimateProduct(t *testing.T) {
+	setupFakeSlurm(t)
+
+	const gig = 1000 * 1000 * 1000
+
+	cases := []struct {
+		Name     string
+		Category string
+		Tres     map[string]int
+		Product  string
+		Nodes    int
+		Ok       bool
+	}{
+		{"exact match", "cpu", map[string]int{"cpu": 4, "mem": 8 * gig, "node": 1}, "cpu-4", 1, true},
+
+		// Every node of a multi-node job matches the product exactly. Matching on the total allocation would instead
+		// map this to a larger product and count the nodes twice.
+		{"exact match on every node", "cpu", map[string]int{"cpu": 32, "mem": 128 * gig, "node": 2}, "cpu-16", 2, true},
+
+		// The largest product is used, rather than whichever product happens to come last
+		{"largest divisor", "cpu", map[string]int{"cpu": 16, "mem": 4 * gig, "node": 1}, "cpu-16", 1, true},
+		{"divisor on every node", "cpu", map[string]int{"cpu": 16, "mem": 4 * gig, "node": 2}, "cpu-4", 4, true},
+
+		// fat-512 evenly divides the allocation but belongs to a different category
+		{"only products of the category", "cpu", map[string]int{"cpu": 64, "mem": 4 * gig, "node": 1}, "cpu-16", 4, true},
+		{"no divisor", "cpu", map[string]int{"cpu": 6, "mem": 4 * gig, "node": 1}, "", 0, false},
+		{"no divisor in category", "fat", map[string]int{"cpu": 8, "mem": 4 * gig, "node": 1}, "", 0, false},
+		{"missing node count", "cpu", map[string]int{"cpu": 8, "mem": 4 * gig}, "cpu-4", 2, true},
+	}
+
+	for _, c := range cases {
+		job := &slurm.Job{AllocTRES: c.Tres}
+		product, nodes, ok := slurmJobEstimateProduct(job, c.Category)
+		if ok != c.Ok || product.Id != c.Product || nodes != c.Nodes {
+			t.Errorf("%s: expected %d x %q (%v), got %d x %q (%v)", c.Name, c.Nodes, c.Product, c.Ok, nodes, product.Id, ok)
+		}
+		if ok && product.Category != c.Category {
+			t.Errorf("%s: expected a product in %s, got %s", c.Name, c.Category, product.Category)
+		}
+	}
+}
+
+func TestSlurmAccountNameFromPattern(t *testing.T) {
+	mapper := &config.SlurmAccountMapperPattern{
+		Users:    "#{localUsername}_#{productCategory}",
+		Projects: "#{localGroupName}_#{productCategory}",
+	}
+
+	params := map[string]string{"localUsername": "alice", "productCategory": "cpu"}
+	if name := slurmAccountNameFromPattern(mapper, apm.WalletOwnerTypeUser, params); name != "alice_cpu" {
+		t.Errorf("unexpected account name for user %q", name)
+	}
+
+	params = map[string]string{"localGroupName": "research", "productCategory": "fat"}
+	if name := slurmAccountNameFromPattern(mapper, apm.WalletOwnerTypeProject, params); name != "research_fat" {
+		t.Errorf("unexpected account name for project %q", name)
+	}
+}
+
+func TestSlurmAccountOwnerInCategory(t *testing.T) {
+	owners := []SlurmAccountOwner{
+		{AssociatedWithCategory: "cpu", Owner: apm.WalletOwnerProject("a")},
+		{AssociatedWithCategory: "fat", Owner: apm.WalletOwnerProject("b")},
+	}
+
+	if owner, ok := slurmAccountOwnerInCategory(owners, "fat"); !ok || owner.ProjectId != "b" {
+		t.Errorf("unexpected owner %v", owner)
+	}
+
+	if _, ok := slurmAccountOwnerInCategory(owners, "gpu"); ok {
+		t.Errorf("expected no owner in a category without accounts")
+	}
+
+	if _, ok := slurmAccountOwnerInCategory(nil, "cpu"); ok {
+		t.Errorf("expected no owner of an unknown account")
+	}
+}
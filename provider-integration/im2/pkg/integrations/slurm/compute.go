@@ -3,11 +3,13 @@ package slurm
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"math"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -38,17 +40,25 @@ var (
 
 var Machines []apm.ProductV2
 var machineSupport []orc.JobSupport
-var SlurmClient *slurm.Client
+var SlurmClient slurm.Client
 
 var jobNameUnsafeRegex = regexp.MustCompile(`[^\w ():_-]`)
 var unknownApplication = orc.NameAndVersion{Name: "unknown", Version: "unknown"}
 
 var ipcRegisterJobUpdate = ipc.NewCall[[]orc.ResourceUpdateAndId[orc.JobUpdate], util.Empty]("slurm.register_job_update")
+var ipcExtendJob = ipc.NewCall[orc.JobsProviderExtendRequestItem, util.Empty]("slurm.extend_job")
+var ipcSuspendJob = ipc.NewCall[fnd.FindByStringId, util.Empty]("slurm.suspend_job")
+var ipcUnsuspendJob = ipc.NewCall[fnd.FindByStringId, util.Empty]("slurm.unsuspend_job")
 
 func InitCompute() controller.JobsService {
 	loadComputeProducts()
 
-	SlurmClient = slurm.NewClient()
+	if rest := ServiceConfig.Compute.Client.Rest(); rest != nil {
+		SlurmClient = slurm.NewRestClient(rest.Endpoint, rest.ApiVersion, rest.TokenFile)
+	} else {
+		SlurmClient = slurm.NewClient()
+	}
+
 	if SlurmClient == nil && len(Machines) > 0 {
 		panic("Failed to initialize SlurmClient!")
 	}
@@ -86,6 +96,33 @@ func InitCompute() controller.JobsService {
 			}
 		})
 
+		ipcExtendJob.Handler(func(r *ipc.Request[orc.JobsProviderExtendRequestItem]) ipc.Response[util.Empty] {
+			job, ok := controller.JobRetrieve(r.Payload.Job.Id)
+			if !ok || !controller.BelongsToWorkspace(orc.ResourceOwnerToWalletOwner(job.Resource), r.Uid) {
+				return ipc.Response[util.Empty]{StatusCode: http.StatusForbidden}
+			}
+
+			return ipcResponseFromError(serverExtendJob(job, r.Payload.RequestedTime))
+		})
+
+		ipcSuspendJob.Handler(func(r *ipc.Request[fnd.FindByStringId]) ipc.Response[util.Empty] {
+			job, ok := controller.JobRetrieve(r.Payload.Id)
+			if !ok || !controller.BelongsToWorkspace(orc.ResourceOwnerToWalletOwner(job.Resource), r.Uid) {
+				return ipc.Response[util.Empty]{StatusCode: http.StatusForbidden}
+			}
+
+			return ipcResponseFromError(serverSuspendJob(job))
+		})
+
+		ipcUnsuspendJob.Handler(func(r *ipc.Request[fnd.FindByStringId]) ipc.Response[util.Empty] {
+			job, ok := controller.JobRetrieve(r.Payload.Id)
+			if !ok || !controller.BelongsToWorkspace(orc.ResourceOwnerToWalletOwner(job.Resource), r.Uid) {
+				return ipc.Response[util.Empty]{StatusCode: http.StatusForbidden}
+			}
+
+			return ipcResponseFromError(serverUnsuspendJob(job))
+		})
+
 		go func() {
 			if len(Machines) == 0 {
 				return
@@ -103,6 +140,8 @@ func InitCompute() controller.JobsService {
 		Submit:                   submitJob,
 		OnUpdatedLabels:          nil,
 		Terminate:                terminateJob,
+		Suspend:                  suspendJob,
+		Unsuspend:                unsuspendJob,
 		Extend:                   extendJob,
 		RetrieveProducts:         retrieveMachineSupport,
 		Follow:                   follow,
@@ -215,58 +254,57 @@ func loopAccounting() {
 	now := time.Now()
 	if now.After(nextComputeAccountingTime) {
 		billing := Accounting.FetchUsageInMinutes()
+		reportItems := usageReportsFromBilling(billing)
 
-		var reportItems []apm.ReportUsageRequest
-		for owner, seconds := range billing {
-			machineCategory := config.Services.Slurm().Compute.Machines[owner.AssociatedWithCategory]
-
-			var usageMillis float64 = float64(seconds) * 1000 * 60
-			var usage float64 = 0
-
-			if machineCategory.Payment.Type == config.PaymentTypeMoney {
-				switch machineCategory.Payment.Interval {
-				case config.PaymentIntervalMinutely:
-					usage = usageMillis / 1000.0 / 60.0
-				case config.PaymentIntervalHourly:
-					usage = usageMillis / 1000 / 60 / 60
-				case config.PaymentIntervalDaily:
-					usage = usageMillis / 1000 / 60 / 60 / 24
-				}
-
-				usage *= machineCategory.Payment.Price
-			} else {
-				usage = usageMillis / 1000.0 / 60.0
+		for _, chunk := range util.ChunkBy(reportItems, 500) {
+			_, err := apm.ReportUsage.Invoke(fnd.BulkRequest[apm.ReportUsageRequest]{Items: chunk})
+			if err != nil {
+				log.Warn("Failed to report usage: %v", err)
 			}
+		}
 
-			reportItems = append(reportItems,
-				apm.ReportUsageRequest{
-					IsDeltaCharge: false,
-					Owner:         owner.Owner,
-					CategoryIdV2: apm.ProductCategoryIdV2{
-						Name:     owner.AssociatedWithCategory,
-						Provider: config.Provider.Id,
-					},
-					Usage: int64(usage),
-				},
-			)
+		nextComputeAccountingTime = now.Add(30 * time.Second)
+	}
+}
 
-			if len(reportItems) > 500 {
-				_, err := apm.ReportUsage.Invoke(fnd.BulkRequest[apm.ReportUsageRequest]{Items: reportItems})
-				if err != nil {
-					log.Warn("Failed to report usage: %v", err)
-				}
-			}
-		}
+// usageReportsFromBilling converts the usage (in billing-minutes) of every Slurm account owner into absolute usage
+// reports according to the payment model of the associated machine category.
+func usageReportsFromBilling(billing map[SlurmAccountOwner]int64) []apm.ReportUsageRequest {
+	var reportItems []apm.ReportUsageRequest
+	for owner, minutes := range billing {
+		machineCategory := ServiceConfig.Compute.Machines[owner.AssociatedWithCategory]
 
-		if len(reportItems) > 0 {
-			_, err := apm.ReportUsage.Invoke(fnd.BulkRequest[apm.ReportUsageRequest]{Items: reportItems})
-			if err != nil {
-				log.Warn("Failed to report usage: %v", err)
+		var usageMillis float64 = float64(minutes) * 1000 * 60
+		var usage float64 = 0
+
+		if machineCategory.Payment.Type == config.PaymentTypeMoney {
+			switch machineCategory.Payment.Interval {
+			case config.PaymentIntervalMinutely:
+				usage = usageMillis / 1000.0 / 60.0
+			case config.PaymentIntervalHourly:
+				usage = usageMillis / 1000 / 60 / 60
+			case config.PaymentIntervalDaily:
+				usage = usageMillis / 1000 / 60 / 60 / 24
 			}
+
+			usage *= machineCategory.Payment.Price
+		} else {
+			usage = usageMillis / 1000.0 / 60.0
 		}
 
-		nextComputeAccountingTime = now.Add(30 * time.Second)
+		reportItems = append(reportItems,
+			apm.ReportUsageRequest{
+				IsDeltaCharge: false,
+				Owner:         owner.Owner,
+				CategoryIdV2: apm.ProductCategoryIdV2{
+					Name:     owner.AssociatedWithCategory,
+					Provider: config.Provider.Id,
+				},
+				Usage: int64(usage),
+			},
+		)
 	}
+	return reportItems
 }
 
 // Monitor running jobs, and register unknown jobs to UCloud
@@ -277,6 +315,64 @@ func loopComputeMonitoring() {
 	batch := controller.JobUpdatesBegin()
 	batch.FailOnRejectedJobs()
 
+	transitions, unknownJobs := slurmJobTransitions(jobs, activeJobs)
+	for _, transition := range transitions {
+		didUpdate := batch.TrackState(transition.UCloudId, transition.State, transition.Message)
+		if transition.ArrayTasks.Present {
+			batch.TrackArrayTasks(transition.UCloudId, transition.ArrayTasks.Value)
+		}
+
+		if didUpdate && transition.AssignNodes {
+			nodeList := SlurmClient.JobGetNodeList(transition.SlurmId)
+			if len(nodeList) > 0 {
+				batch.TrackAssignedNodes(transition.UCloudId, nodeList)
+			}
+		}
+	}
+
+	toRegister := slurmJobsToRegister(unknownJobs, AccountMapper)
+	batch.End()
+
+	for _, chunk := range util.ChunkBy(toRegister, 100) {
+		if len(chunk) > 0 {
+			response, err := orc.JobsControlRegister.Invoke(
+				fnd.BulkRequest[orc.ProviderRegisteredResource[orc.JobSpecification]]{
+					Items: chunk,
+				},
+			)
+
+			if err != nil {
+				log.Warn("Error while registering jobs: %s", err.AsError().Error())
+			} else {
+				for _, registeredJob := range response.Responses {
+					job, err := orc.JobsControlRetrieve.Invoke(orc.JobsControlRetrieveRequest{
+						Id: registeredJob.Id,
+					})
+
+					if err != nil {
+						log.Warn("Error while retrieving job: %s", err.AsError().Error())
+					}
+
+					metricSlurmUnknownJobsRegistered.Inc()
+					controller.JobTrackNew(job)
+				}
+			}
+		}
+	}
+}
+
+type slurmJobTransition struct {
+	UCloudId    string
+	SlurmId     int
+	State       orc.JobState
+	Message     util.Option[string]
+	AssignNodes bool
+	ArrayTasks  util.Option[orc.JobArrayStatus]
+}
+
+// slurmJobTransitions matches the jobs reported by Slurm against the active UCloud jobs. It returns the state which
+// each known job should be tracked as along with the list of running or queued Slurm jobs which are unknown to UCloud.
+func slurmJobTransitions(jobs []slurm.Job, activeJobs map[string]*orc.Job) ([]slurmJobTransition, []*slurm.Job) {
 	jobsBySlurmId := make(map[int]string)
 	for jobId, job := range activeJobs {
 		parsed, ok := parseJobProviderId(job.ProviderGeneratedId)
@@ -287,7 +383,10 @@ func loopComputeMonitoring() {
 		jobsBySlurmId[parsed.SlurmId] = jobId
 	}
 
+	var transitions []slurmJobTransition
 	unknownJobs := []*slurm.Job{}
+	unknownJobIds := map[int]bool{}
+	arrays := map[string]*slurmArrayTasks{}
 
 	for _, slurmJob := range jobs {
 		stateInfo, ok := slurmToUCloudState[slurmJob.State]
@@ -297,104 +396,182 @@ func loopComputeMonitoring() {
 
 		ucloudId, ok := jobsBySlurmId[slurmJob.JobID]
 		if !ok {
-			if stateInfo.State == orc.JobStateInQueue || stateInfo.State == orc.JobStateRunning {
+			// Tasks of an array share the ID of the array, only the first one is used for registration
+			if (stateInfo.State == orc.JobStateInQueue || stateInfo.State == orc.JobStateRunning) &&
+				!unknownJobIds[slurmJob.JobID] {
+				unknownJobIds[slurmJob.JobID] = true
 				unknownJobs = append(unknownJobs, &slurmJob)
 			}
 			continue
 		}
 
-		didUpdate := batch.TrackState(ucloudId, stateInfo.State, util.OptValue(stateInfo.Message))
-		if didUpdate {
-			nodeList := SlurmClient.JobGetNodeList(slurmJob.JobID)
-			if len(nodeList) > 0 {
-				batch.TrackAssignedNodes(ucloudId, nodeList)
+		if slurmJob.ArrayTask != "" {
+			tasks, ok := arrays[ucloudId]
+			if !ok {
+				tasks = &slurmArrayTasks{SlurmId: slurmJob.JobID}
+				arrays[ucloudId] = tasks
 			}
+			tasks.Add(stateInfo.State, len(slurmJob.ArrayTaskIds()))
+			continue
 		}
-	}
 
-	// Register unknown jobs
-	toRegister := []orc.ProviderRegisteredResource[orc.JobSpecification]{}
-	for _, slurmJob := range unknownJobs {
-		if slurmJob.Account == "" {
+		if stateInfo.State == orc.JobStateInQueue && activeJobs[ucloudId].Status.State == orc.JobStateSuspended {
+			// Jobs suspended through a hold are placed back in the queue by Slurm. They remain suspended from
+			// the point of view of UCloud until they are released again.
+			transitions = append(transitions, slurmJobTransition{
+				UCloudId: ucloudId,
+				SlurmId:  slurmJob.JobID,
+				State:    orc.JobStateSuspended,
+			})
 			continue
 		}
 
-		slurmCfg := AccountMapper.ServerSlurmJobToConfiguration(slurmJob)
+		transitions = append(transitions, slurmJobTransition{
+			UCloudId:    ucloudId,
+			SlurmId:     slurmJob.JobID,
+			State:       stateInfo.State,
+			Message:     util.OptValue(stateInfo.Message),
+			AssignNodes: true,
+		})
+	}
 
-		if !slurmCfg.Present {
-			continue
-		}
+	for _, ucloudId := range slices.Sorted(maps.Keys(arrays)) {
+		transitions = append(transitions, arrays[ucloudId].Transition(ucloudId, activeJobs[ucloudId]))
+	}
 
-		desiredName := fmt.Sprintf("%s (SlurmID: %d)", slurmJob.Name, slurmJob.JobID)
-		safeName := jobNameUnsafeRegex.ReplaceAllString(desiredName, "")
+	return transitions, unknownJobs
+}
 
-		timeAllocation := util.Option[orc.SimpleDuration]{}
-		timeAllocation.Set(orc.SimpleDurationFromMillis(int64(slurmJob.TimeLimit) * 1000))
+// slurmArrayTasks aggregates the state of the tasks in a Slurm job array, which are all tracked by a single job in
+// UCloud.
+type slurmArrayTasks struct {
+	SlurmId   int
+	Status    orc.JobArrayStatus
+	Suspended int
+}
 
-		createdBy := util.Option[string]{Value: slurmCfg.Value.UCloudUsername, Present: true}
-		projectId := util.Option[string]{}
+func (a *slurmArrayTasks) Add(state orc.JobState, count int) {
+	switch state {
+	case orc.JobStateInQueue:
+		a.Status.InQueue += count
+	case orc.JobStateRunning:
+		a.Status.Running += count
+	case orc.JobStateSuspended:
+		a.Suspended += count
+	case orc.JobStateSuccess:
+		a.Status.Success += count
+	default:
+		a.Status.Failure += count
+	}
+	a.Status.Total += count
+}
 
-		if slurmCfg.Value.Owner.Type == apm.WalletOwnerTypeProject {
-			projectId.Set(slurmCfg.Value.Owner.ProjectId)
+func (a *slurmArrayTasks) Transition(ucloudId string, job *orc.Job) slurmJobTransition {
+	status := a.Status
+	status.InQueue += a.Suspended
+	if total := job.Specification.ArrayTaskCount(); total > status.Total {
+		status.Total = total
+	}
+
+	result := slurmJobTransition{
+		UCloudId:   ucloudId,
+		SlurmId:    a.SlurmId,
+		ArrayTasks: util.OptValue(status),
+	}
+
+	finished := status.Success + status.Failure
+	switch {
+	case a.Status.Running > 0:
+		result.State = orc.JobStateRunning
+		result.Message.Set(fmt.Sprintf("Your job array is running (%d of %d tasks finished)", finished, status.Total))
+
+	case a.Suspended > 0:
+		result.State = orc.JobStateSuspended
+
+	case a.Status.InQueue > 0:
+		if job.Status.State == orc.JobStateSuspended {
+			// See the equivalent comment for regular jobs in slurmJobTransitions
+			result.State = orc.JobStateSuspended
+		} else {
+			result.State = orc.JobStateInQueue
+			result.Message.Set(fmt.Sprintf("Your job array is in the queue (%d of %d tasks finished)", finished, status.Total))
 		}
 
-		providerJobId := util.Option[string]{
-			Value: parsedProviderJobId{
-				BelongsToAccount: slurmJob.Account,
-				SlurmId:          slurmJob.JobID,
-			}.String(),
-			Present: true,
+	case status.Failure > 0:
+		result.State = orc.JobStateFailure
+		result.Message.Set(fmt.Sprintf("%d of %d tasks in your job array failed", status.Failure, status.Total))
+
+	default:
+		result.State = orc.JobStateSuccess
+		result.Message.Set("All tasks in your job array have completed")
+	}
+
+	return result
+}
+
+// slurmJobsToRegister finds the unknown jobs which should be registered in UCloud. Jobs submitted by UCloud are never
+// registered, even if they are not tracked, and neither are jobs which cannot be mapped to a UCloud workspace.
+func slurmJobsToRegister(unknownJobs []*slurm.Job, mapper AccountMapperService) []orc.ProviderRegisteredResource[orc.JobSpecification] {
+	toRegister := []orc.ProviderRegisteredResource[orc.JobSpecification]{}
+	for _, slurmJob := range unknownJobs {
+		if slurmJob.Account == "" {
+			continue
 		}
 
-		newJobResource := orc.ProviderRegisteredResource[orc.JobSpecification]{
-			Spec: orc.JobSpecification{
-				Name:        safeName,
-				Application: unknownApplication,
-				ResourceSpecification: orc.ResourceSpecification{
-					Product: slurmCfg.Value.EstimatedProduct,
-				},
-				Replicas:       slurmCfg.Value.EstimatedNodeCount,
-				Parameters:     make(map[string]orc.AppParameterValue),
-				Resources:      []orc.AppParameterValue{},
-				TimeAllocation: timeAllocation,
-			},
-			ProviderGeneratedId: providerJobId,
-			CreatedBy:           createdBy,
-			Project:             projectId,
+		slurmCfg := mapper.ServerSlurmJobToConfiguration(slurmJob)
+
+		if !slurmCfg.Present {
+			continue
 		}
 
+		newJobResource := slurmJobToRegistration(slurmJob, slurmCfg.Value)
+
 		comment, _ := SlurmClient.JobComment(slurmJob.JobID)
 		if comment != ucloudSlurmComment {
 			toRegister = append(toRegister, newJobResource)
 		}
 	}
-	batch.End()
+	return toRegister
+}
 
-	for _, chunk := range util.ChunkBy(toRegister, 100) {
-		if len(chunk) > 0 {
-			response, err := orc.JobsControlRegister.Invoke(
-				fnd.BulkRequest[orc.ProviderRegisteredResource[orc.JobSpecification]]{
-					Items: chunk,
-				},
-			)
+// slurmJobToRegistration builds the resource used to register a job which was submitted directly to Slurm.
+func slurmJobToRegistration(slurmJob *slurm.Job, slurmCfg SlurmJobConfiguration) orc.ProviderRegisteredResource[orc.JobSpecification] {
+	desiredName := fmt.Sprintf("%s (SlurmID: %d)", slurmJob.Name, slurmJob.JobID)
+	safeName := jobNameUnsafeRegex.ReplaceAllString(desiredName, "")
 
-			if err != nil {
-				log.Warn("Error while registering jobs: %s", err.AsError().Error())
-			} else {
-				for _, registeredJob := range response.Responses {
-					job, err := orc.JobsControlRetrieve.Invoke(orc.JobsControlRetrieveRequest{
-						Id: registeredJob.Id,
-					})
+	timeAllocation := util.Option[orc.SimpleDuration]{}
+	timeAllocation.Set(orc.SimpleDurationFromMillis(int64(slurmJob.TimeLimit) * 1000))
 
-					if err != nil {
-						log.Warn("Error while retrieving job: %s", err.AsError().Error())
-					}
+	createdBy := util.Option[string]{Value: slurmCfg.UCloudUsername, Present: true}
+	projectId := util.Option[string]{}
 
-					metricSlurmUnknownJobsRegistered.Inc()
-					controller.JobTrackNew(job)
-				}
-			}
-		}
+	if slurmCfg.Owner.Type == apm.WalletOwnerTypeProject {
+		projectId.Set(slurmCfg.Owner.ProjectId)
+	}
+
+	providerJobId := util.Option[string]{
+		Value: parsedProviderJobId{
+			BelongsToAccount: slurmJob.Account,
+			SlurmId:          slurmJob.JobID,
+		}.String(),
+		Present: true,
+	}
+
+	return orc.ProviderRegisteredResource[orc.JobSpecification]{
+		Spec: orc.JobSpecification{
+			Name:        safeName,
+			Application: unknownApplication,
+			ResourceSpecification: orc.ResourceSpecification{
+				Product: slurmCfg.EstimatedProduct,
+			},
+			Replicas:       slurmCfg.EstimatedNodeCount,
+			Parameters:     make(map[string]orc.AppParameterValue),
+			Resources:      []orc.AppParameterValue{},
+			TimeAllocation: timeAllocation,
+		},
+		ProviderGeneratedId: providerJobId,
+		CreatedBy:           createdBy,
+		Project:             projectId,
 	}
 }
 
@@ -408,7 +585,7 @@ var slurmToUCloudState = map[string]ucloudStateInfo{
 	"CONFIGURING":   {orc.JobStateInQueue, "Your job is currently in the queue (CONFIGURING)"},
 	"RESV_DEL_HOLD": {orc.JobStateInQueue, "Your job is currently in the queue (RESV_DEL_HOLD)"},
 	"REQUEUE_FED":   {orc.JobStateInQueue, "Your job is currently in the queue (REQUEUE_FED)"},
-	"SUSPENDED":     {orc.JobStateInQueue, "Your job is currently in the queue (SUSPENDED)"},
+	"SUSPENDED":     {orc.JobStateSuspended, "Your job has been suspended"},
 
 	"REQUEUE_HOLD": {orc.JobStateInQueue, "Your job is currently held for requeue"},
 	"REQUEUED":     {orc.JobStateInQueue, "Your job is currently in the queue (REQUEUED)"},
@@ -442,11 +619,174 @@ func retrieveMachineSupport() []orc.JobSupport {
 	return machineSupport
 }
 
-func extendJob(_ orc.JobsProviderExtendRequestItem) *util.HttpError {
-	return &util.HttpError{
-		StatusCode: http.StatusBadRequest,
-		Why:        "Extension of jobs are not supported by this provider",
+func extendJob(request orc.JobsProviderExtendRequestItem) *util.HttpError {
+	if !ServiceConfig.Compute.TimeExtension.Enabled {
+		return &util.HttpError{
+			StatusCode: http.StatusBadRequest,
+			Why:        "Extension of jobs are not supported by this provider",
+		}
+	}
+
+	if config.Mode != config.ServerModeServer {
+		_, err := ipcExtendJob.Invoke(request)
+		return util.HttpErrorFromErr(err)
+	} else {
+		return serverExtendJob(&request.Job, request.RequestedTime)
+	}
+}
+
+func suspendJob(job orc.Job) *util.HttpError {
+	if !ServiceConfig.Compute.Suspension.Enabled {
+		return &util.HttpError{
+			StatusCode: http.StatusBadRequest,
+			Why:        "Suspension of jobs are not supported by this provider",
+		}
+	}
+
+	if config.Mode != config.ServerModeServer {
+		_, err := ipcSuspendJob.Invoke(fnd.FindByStringId{Id: job.Id})
+		return util.HttpErrorFromErr(err)
+	} else {
+		return serverSuspendJob(&job)
+	}
+}
+
+func unsuspendJob(job orc.Job) *util.HttpError {
+	if !ServiceConfig.Compute.Suspension.Enabled {
+		return &util.HttpError{
+			StatusCode: http.StatusBadRequest,
+			Why:        "Suspension of jobs are not supported by this provider",
+		}
+	}
+
+	if config.Mode != config.ServerModeServer {
+		_, err := ipcUnsuspendJob.Invoke(fnd.FindByStringId{Id: job.Id})
+		return util.HttpErrorFromErr(err)
+	} else {
+		return serverUnsuspendJob(&job)
+	}
+}
+
+// serverFindSlurmJob finds the Slurm job backing a UCloud job. Only the server is allowed to change the time limit
+// and suspension state of a job, as these operations require operator privileges in Slurm.
+func serverFindSlurmJob(job *orc.Job) (*slurm.Job, *util.HttpError) {
+	providerId, ok := parseJobProviderId(job.ProviderGeneratedId)
+	if !ok {
+		return nil, util.UserHttpError("This job has not yet been submitted to Slurm")
+	}
+
+	slurmJob, queryOk := SlurmClient.JobQuery(providerId.SlurmId)
+	if !queryOk {
+		return nil, util.ServerHttpError("Failed to query Slurm job")
 	}
+	if slurmJob == nil {
+		return nil, util.UserHttpError("This job is no longer known by Slurm")
+	}
+	if slurmJob.Name != job.Id && (slurmJob.Account != providerId.BelongsToAccount || slurmJob.JobID != providerId.SlurmId) {
+		return nil, util.ServerHttpError("Refusing to modify a Slurm job with mismatched tracking data")
+	}
+	return slurmJob, nil
+}
+
+func serverExtendJob(job *orc.Job, requestedTime orc.SimpleDuration) *util.HttpError {
+	minutes := int(requestedTime.ToMillis() / (1000 * 60))
+	if minutes <= 0 {
+		return util.UserHttpError("The requested extension must be at least one minute")
+	}
+
+	slurmJob, err := serverFindSlurmJob(job)
+	if err != nil {
+		return err
+	}
+
+	maxHours := ServiceConfig.Compute.TimeExtension.MaxHours
+	if maxHours.Present && slurmJob.TimeLimit+minutes*60 > maxHours.Value*3600 {
+		return util.UserHttpError("Jobs cannot run for longer than %v hours at this provider", maxHours.Value)
+	}
+
+	if !SlurmClient.JobExtendTimeLimit(slurmJob.JobID, minutes) {
+		return util.ServerHttpError("Failed to extend Slurm job")
+	}
+	return nil
+}
+
+func serverSuspendJob(job *orc.Job) *util.HttpError {
+	slurmJob, err := serverFindSlurmJob(job)
+	if err != nil {
+		return err
+	}
+
+	switch ServiceConfig.Compute.Suspension.Method {
+	case config.SlurmSuspensionMethodHold:
+		state := slurmToUCloudState[slurmJob.State].State
+		ok := false
+		if state == orc.JobStateRunning {
+			ok = SlurmClient.JobRequeueHold(slurmJob.JobID)
+		} else {
+			ok = SlurmClient.JobHold(slurmJob.JobID)
+		}
+
+		if !ok {
+			return util.ServerHttpError("Failed to suspend Slurm job")
+		}
+
+		return controller.JobSendUpdates([]orc.ResourceUpdateAndId[orc.JobUpdate]{
+			{
+				Id: job.Id,
+				Update: orc.JobUpdate{
+					State:  util.OptValue(orc.JobStateSuspended),
+					Status: util.OptValue("Your job has been suspended"),
+				},
+			},
+		})
+
+	default:
+		if !SlurmClient.JobSuspend(slurmJob.JobID) {
+			return util.ServerHttpError("Failed to suspend Slurm job")
+		}
+		return nil
+	}
+}
+
+func serverUnsuspendJob(job *orc.Job) *util.HttpError {
+	slurmJob, err := serverFindSlurmJob(job)
+	if err != nil {
+		return err
+	}
+
+	switch ServiceConfig.Compute.Suspension.Method {
+	case config.SlurmSuspensionMethodHold:
+		if !SlurmClient.JobRelease(slurmJob.JobID) {
+			return util.ServerHttpError("Failed to resume Slurm job")
+		}
+
+		return controller.JobSendUpdates([]orc.ResourceUpdateAndId[orc.JobUpdate]{
+			{
+				Id: job.Id,
+				Update: orc.JobUpdate{
+					State:  util.OptValue(orc.JobStateInQueue),
+					Status: util.OptValue("Your job is currently in the queue"),
+				},
+			},
+		})
+
+	default:
+		if !SlurmClient.JobResume(slurmJob.JobID) {
+			return util.ServerHttpError("Failed to resume Slurm job")
+		}
+		return nil
+	}
+}
+
+func ipcResponseFromError(err *util.HttpError) ipc.Response[util.Empty] {
+	if err != nil {
+		return ipc.Response[util.Empty]{
+			StatusCode:   err.StatusCode,
+			ErrorMessage: err.Why,
+		}
+	}
+
+	return ipc.Response[util.Empty]{StatusCode: http.StatusOK}
 }
 
 func terminateJob(request controller.JobTerminateRequest) *util.HttpError {
@@ -738,7 +1078,10 @@ func loadComputeProducts() {
 		support.Native.Logs = true
 		support.Native.Terminal = true
 		support.Native.Peers = false
-		support.Native.TimeExtension = false
+		support.Native.TimeExtension = ServiceConfig.Compute.TimeExtension.Enabled
+		support.Native.Suspension = ServiceConfig.Compute.Suspension.Enabled
+		support.Native.Arrays = true
+		support.Native.Dependencies = true
 
 		machineSupport = append(machineSupport, support)
 	}
@@ -835,6 +1178,29 @@ func follow(session *controller.FollowJobSession) {
 			}
 		}
 
+		// The output of each task in a job array is written to a separate file
+		for task := 0; task < job.Specification.ArrayTaskCount(); task++ {
+			taskOut, e1 := os.Open(filepath.Join(outputFolder, fmt.Sprintf("stdout-%d.txt", task)))
+			if e1 == nil {
+				logFiles = append(logFiles, trackedLogFile{
+					Rank:   task,
+					Stdout: true,
+					File:   taskOut,
+				})
+				defer util.SilentClose(taskOut)
+			}
+
+			taskErr, e2 := os.Open(filepath.Join(outputFolder, fmt.Sprintf("stderr-%d.txt", task)))
+			if e2 == nil {
+				logFiles = append(logFiles, trackedLogFile{
+					Rank:   task,
+					Stdout: false,
+					File:   taskErr,
+				})
+				defer util.SilentClose(taskErr)
+			}
+		}
+
 		{
 			scriptOut, e1 := os.Open(filepath.Join(outputFolder, "stdout.txt"))
 			if e1 == nil {
@@ -990,7 +1356,7 @@ func openWebSession(job *orc.Job, sessionType orc.InteractiveSessionType, rank i
 	}
 
 	outputFolder := filepath.Join(jobFolder, job.Id)
-	portFilePath := filepath.Join(outputFolder, AllocatedPortFile)
+	portFilePath := filepath.Join(outputFolder, allocatedPortFileForJob(job))
 	portFileData, err := os.ReadFile(portFilePath)
 	if err != nil {
 		return controller.ConfiguredWebSessionResult{}, util.ServerHttpError("could not read port file: %v", err)
@@ -0,0 +1,613 @@
+package slurm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ucloud.dk/pkg/config"
+	ctrl "ucloud.dk/pkg/controller"
+	"ucloud.dk/pkg/external/slurm"
+	apm "ucloud.dk/shared/pkg/accounting"
+	fnd "ucloud.dk/shared/pkg/foundation"
+	orc "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// setupFakeSlurm configures the integration with two machine categories backed by an in-memory Slurm cluster. The
+// cluster has a single account, proj_cpu, which alice is allowed to submit to.
+func setupFakeSlurm(t *testing.T) *slurm.FakeCluster {
+	previousProvider := config.Provider
+	previousConfig := ServiceConfig
+	previousClient := SlurmClient
+	previousMachines := Machines
+	previousSupport := machineSupport
+
+	t.Cleanup(func() {
+		config.Provider = previousProvider
+		ServiceConfig = previousConfig
+		SlurmClient = previousClient
+		Machines = previousMachines
+		machineSupport = previousSupport
+	})
+
+	config.Provider = &config.ProviderConfiguration{Id: "slurm"}
+	ServiceConfig = &config.ServicesConfigurationSlurm{
+		Compute: config.SlurmCompute{
+			Machines: map[string]config.SlurmMachineCategory{
+				"cpu": {
+					Partition: "normal",
+					Payment: config.PaymentInfo{
+						Type:     config.PaymentTypeResource,
+						Unit:     config.MachineResourceTypeCpu,
+						Interval: config.PaymentIntervalMinutely,
+					},
+					Groups: map[string]config.SlurmMachineCategoryGroup{
+						"cpu": {
+							NameSuffix: config.MachineResourceTypeCpu,
+							Configs: []config.SlurmMachineConfiguration{
+								{Cpu: 4, MemoryInGigabytes: 8},
+								{Cpu: 16, MemoryInGigabytes: 64},
+							},
+						},
+					},
+				},
+				"fat": {
+					Partition: "fat",
+					Payment: config.PaymentInfo{
+						Type:     config.PaymentTypeMoney,
+						Currency: "DKK",
+						Interval: config.PaymentIntervalHourly,
+						Price:    2.5,
+					},
+					Groups: map[string]config.SlurmMachineCategoryGroup{
+						"fat": {
+							NameSuffix: config.MachineResourceTypeMemory,
+							Configs: []config.SlurmMachineConfiguration{
+								{Cpu: 32, MemoryInGigabytes: 512},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	Machines = nil
+	machineSupport = nil
+	loadComputeProducts()
+
+	cluster := slurm.NewFakeCluster(4)
+	cluster.SubmittingUser = "alice"
+	SlurmClient = cluster
+
+	account := slurm.NewAccount()
+	account.Name = "proj_cpu"
+	if !cluster.AccountCreate(account) || !cluster.AccountAddUser("alice", "proj_cpu") {
+		t.Fatalf("failed to create account")
+	}
+
+	return cluster
+}
+
+func findMachine(t *testing.T, name string) apm.ProductV2 {
+	for _, machine := range Machines {
+		if machine.Name == name {
+			return machine
+		}
+	}
+	t.Fatalf("unknown machine %s", name)
+	return apm.ProductV2{}
+}
+
+func newFakeSlurmJob(t *testing.T, id string, product string, replicas int) *orc.Job {
+	machine := findMachine(t, product)
+
+	job := &orc.Job{}
+	job.Id = id
+	job.Specification.Product = machine.ToReference()
+	job.Specification.Replicas = replicas
+	job.Specification.Parameters = map[string]orc.AppParameterValue{}
+	job.Specification.TimeAllocation.Set(orc.SimpleDuration{Hours: 1, Minutes: 30})
+	job.Status.ResolvedProduct.Set(machine)
+
+	app := orc.Application{}
+	app.Invocation.Tool.Tool.Set(orc.Tool{})
+	app.Invocation.Invocation = []orc.InvocationParameter{
+		orc.InvocationWord("echo"),
+		orc.InvocationWord("Hello, World!"),
+	}
+	job.Status.ResolvedApplication.Set(app)
+	return job
+}
+
+func submitFakeSlurmJob(t *testing.T, job *orc.Job) int {
+	jobFolder := t.TempDir()
+	result := CreateSBatchFile(job, jobFolder, "proj_cpu")
+	if result.Error != nil {
+		t.Fatalf("failed to create sbatch file: %v", result.Error)
+	}
+
+	scriptPath := filepath.Join(jobFolder, "job.sh")
+	if err := os.WriteFile(scriptPath, []byte(result.Content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	slurmId, err := SlurmClient.JobSubmit(scriptPath)
+	if err != nil {
+		t.Fatalf("failed to submit job: %v\n%s", err, result.Content)
+	}
+	return slurmId
+}
+
+func TestSBatchFileIsAcceptedBySlurm(t *testing.T) {
+	setupFakeSlurm(t)
+
+	job := newFakeSlurmJob(t, "4242", "cpu-4", 2)
+	slurmId := submitFakeSlurmJob(t, job)
+
+	slurmJob, ok := SlurmClient.JobQuery(slurmId)
+	if !ok || slurmJob == nil {
+		t.Fatalf("job was not found in slurm")
+	}
+
+	if slurmJob.Name != "4242" || slurmJob.Account != "proj_cpu" || slurmJob.Partition != "normal" {
+		t.Errorf("unexpected job %+v", slurmJob)
+	}
+	if slurmJob.TimeLimit != 90*60 {
+		t.Errorf("expected a time limit of 90 minutes, got %d seconds", slurmJob.TimeLimit)
+	}
+	if slurmJob.AllocTRES["cpu"] != 8 || slurmJob.AllocTRES["node"] != 2 {
+		t.Errorf("unexpected TRES %v", slurmJob.AllocTRES)
+	}
+
+	comment, _ := SlurmClient.JobComment(slurmId)
+	if comment != ucloudSlurmComment {
+		t.Errorf("jobs submitted by UCloud must carry the UCloud comment, got %q", comment)
+	}
+}
+
+func TestSlurmJobTransitions(t *testing.T) {
+	cluster := setupFakeSlurm(t)
+
+	tracked := newFakeSlurmJob(t, "1", "cpu-4", 4)
+	tracked.ProviderGeneratedId = parsedProviderJobId{BelongsToAccount: "proj_cpu", SlurmId: submitFakeSlurmJob(t, tracked)}.String()
+	tracked.Status.State = orc.JobStateInQueue
+
+	queued := newFakeSlurmJob(t, "2", "cpu-4", 1)
+	queuedSlurmId := submitFakeSlurmJob(t, queued)
+	queued.ProviderGeneratedId = parsedProviderJobId{BelongsToAccount: "proj_cpu", SlurmId: queuedSlurmId}.String()
+	queued.Status.State = orc.JobStateSuspended
+
+	activeJobs := map[string]*orc.Job{tracked.Id: tracked, queued.Id: queued}
+
+	// A job submitted directly to Slurm, outside of UCloud
+	scriptPath := filepath.Join(t.TempDir(), "job.sh")
+	if err := os.WriteFile(scriptPath, []byte("#!/bin/bash\n#SBATCH --account proj_cpu\n#SBATCH --partition normal\nsleep 10\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	unknownSlurmId, err := SlurmClient.JobSubmit(scriptPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cluster.Advance(time.Minute)
+
+	transitions, unknown := slurmJobTransitions(SlurmClient.JobList(), activeJobs)
+	states := map[string]orc.JobState{}
+	for _, transition := range transitions {
+		states[transition.UCloudId] = transition.State
+	}
+
+	if states[tracked.Id] != orc.JobStateRunning {
+		t.Errorf("expected tracked job to be running, got %v", states[tracked.Id])
+	}
+
+	// The tracked job occupies all nodes, leaving the second job in the queue. Since UCloud believes it to be
+	// suspended (through a hold), it must remain suspended.
+	if states[queued.Id] != orc.JobStateSuspended {
+		t.Errorf("expected held job to remain suspended, got %v", states[queued.Id])
+	}
+
+	if len(unknown) != 1 || unknown[0].JobID != unknownSlurmId {
+		t.Errorf("expected exactly one unknown job, got %v", unknown)
+	}
+
+	cluster.Advance(2 * time.Hour)
+	transitions, _ = slurmJobTransitions(SlurmClient.JobList(), activeJobs)
+	for _, transition := range transitions {
+		if transition.UCloudId == tracked.Id && transition.State != orc.JobStateExpired {
+			t.Errorf("expected tracked job to have expired, got %v", transition.State)
+		}
+	}
+}
+
+func TestUnknownSlurmJobsAreMappedToProducts(t *testing.T) {
+	setupFakeSlurm(t)
+
+	cases := []struct {
+		Script       string
+		Category     string
+		Product      string
+		NodeEstimate int
+	}{
+		{"#SBATCH --partition normal\n#SBATCH --cpus-per-task 4\n#SBATCH --mem 8000\n", "cpu", "cpu-4", 1},
+		{"#SBATCH --partition normal\n#SBATCH --cpus-per-task 16\n#SBATCH --nodes 2\n", "cpu", "cpu-16", 2},
+		{"#SBATCH --partition normal\n#SBATCH --cpus-per-task 8\n", "cpu", "cpu-4", 2},
+		{"#SBATCH --partition fat\n#SBATCH --cpus-per-task 32\n#SBATCH --mem 512000\n", "fat", "fat-512", 1},
+	}
+
+	for _, c := range cases {
+		scriptPath := filepath.Join(t.TempDir(), "job.sh")
+		script := "#!/bin/bash\n#SBATCH --account proj_cpu\n" + c.Script + "sleep 10\n"
+		if err := os.WriteFile(scriptPath, []byte(script), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		slurmId, err := SlurmClient.JobSubmit(scriptPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		slurmJob, _ := SlurmClient.JobQuery(slurmId)
+
+		category, ok := slurmJobMachineCategory(slurmJob)
+		if !ok || category != c.Category {
+			t.Errorf("expected category %s, got %s", c.Category, category)
+			continue
+		}
+
+		product, nodes, ok := slurmJobEstimateProduct(slurmJob, category)
+		if !ok || product.Id != c.Product || nodes != c.NodeEstimate {
+			t.Errorf("expected %d x %s, got %d x %s", c.NodeEstimate, c.Product, nodes, product.Id)
+		}
+
+		registration := slurmJobToRegistration(slurmJob, SlurmJobConfiguration{
+			UCloudUsername:     "alice#1234",
+			Owner:              apm.WalletOwnerProject("project"),
+			EstimatedProduct:   product,
+			EstimatedNodeCount: nodes,
+		})
+
+		parsed, ok := parseJobProviderId(registration.ProviderGeneratedId.Value)
+		if !ok || parsed.SlurmId != slurmId || parsed.BelongsToAccount != "proj_cpu" {
+			t.Errorf("unexpected provider id %s", registration.ProviderGeneratedId.Value)
+		}
+		if registration.Project.Value != "project" || registration.CreatedBy.Value != "alice#1234" {
+			t.Errorf("unexpected owner of registration %+v", registration)
+		}
+	}
+}
+
+func TestUsageReportsFromSlurmBilling(t *testing.T) {
+	cluster := setupFakeSlurm(t)
+
+	job := newFakeSlurmJob(t, "1", "cpu-4", 1)
+	slurmId := submitFakeSlurmJob(t, job)
+	cluster.SetJobDuration(slurmId, 30*time.Minute)
+	cluster.Advance(time.Hour)
+
+	billing := SlurmClient.AccountBillingList()
+	if billing["proj_cpu"] != 4*30 {
+		t.Fatalf("unexpected billing %v", billing)
+	}
+
+	cpuOwner := SlurmAccountOwner{AssociatedWithCategory: "cpu", Owner: apm.WalletOwnerProject("a")}
+	fatOwner := SlurmAccountOwner{AssociatedWithCategory: "fat", Owner: apm.WalletOwnerProject("b")}
+
+	reports := usageReportsFromBilling(map[SlurmAccountOwner]int64{
+		cpuOwner: billing["proj_cpu"],
+		fatOwner: 120,
+	})
+
+	usage := map[string]int64{}
+	for _, report := range reports {
+		if report.IsDeltaCharge {
+			t.Errorf("usage reports must be absolute")
+		}
+		usage[report.CategoryIdV2.Name] = report.Usage
+	}
+
+	// Resource based categories are charged in core-minutes
+	if usage["cpu"] != 120 {
+		t.Errorf("expected 120 core minutes, got %d", usage["cpu"])
+	}
+
+	// 120 billing minutes at 2.5 DKK per hour
+	if usage["fat"] != 5 {
+		t.Errorf("expected 5 DKK, got %d", usage["fat"])
+	}
+}
+
+func TestSlurmJobArrays(t *testing.T) {
+	cluster := setupFakeSlurm(t)
+
+	job := newFakeSlurmJob(t, "1", "cpu-4", 1)
+	app := job.Status.ResolvedApplication.Value
+	app.Invocation.Parameters = []orc.ApplicationParameter{{Name: "n", Type: orc.ApplicationParameterTypeInteger}}
+	app.Invocation.Invocation = append(app.Invocation.Invocation, orc.InvocationVar("n"))
+	job.Status.ResolvedApplication.Set(app)
+	job.Specification.Sweep.Set(orc.JobSweep{
+		Matrix: map[string][]orc.AppParameterValue{
+			"n": {orc.AppParameterValueInteger(10), orc.AppParameterValueInteger(20), orc.AppParameterValueInteger(30)},
+		},
+		MaxConcurrency: 2,
+	})
+
+	slurmId := submitFakeSlurmJob(t, job)
+	job.ProviderGeneratedId = parsedProviderJobId{BelongsToAccount: "proj_cpu", SlurmId: slurmId}.String()
+	job.Status.State = orc.JobStateInQueue
+
+	script, _ := cluster.JobScript(slurmId)
+	if !strings.Contains(script, "#SBATCH --array 0-2%2") {
+		t.Errorf("expected the job to be submitted as an array:\n%s", script)
+	}
+	if !strings.Contains(script, "'echo' 'Hello, World!' '30'\n;;\nesac") {
+		t.Errorf("expected each task to use its own parameters:\n%s", script)
+	}
+
+	cluster.SetJobDuration(slurmId, 10*time.Minute)
+	cluster.Advance(time.Minute)
+
+	activeJobs := map[string]*orc.Job{job.Id: job}
+	transitions, unknown := slurmJobTransitions(SlurmClient.JobList(), activeJobs)
+	if len(transitions) != 1 || len(unknown) != 0 {
+		t.Fatalf("expected a single transition for the array, got %v (unknown: %v)", transitions, unknown)
+	}
+
+	expected := orc.JobArrayStatus{Total: 3, InQueue: 1, Running: 2}
+	if transitions[0].State != orc.JobStateRunning || transitions[0].ArrayTasks.Value != expected {
+		t.Errorf("unexpected transition %+v", transitions[0])
+	}
+
+	// The last task can only start once one of the first two tasks has completed
+	cluster.Advance(30 * time.Minute)
+	cluster.Advance(30 * time.Minute)
+	transitions, _ = slurmJobTransitions(SlurmClient.JobList(), activeJobs)
+
+	expected = orc.JobArrayStatus{Total: 3, Success: 3}
+	if transitions[0].State != orc.JobStateSuccess || transitions[0].ArrayTasks.Value != expected {
+		t.Errorf("unexpected transition %+v", transitions[0])
+	}
+}
+
+func TestSlurmJobArraysRejectDifferentDirectives(t *testing.T) {
+	setupFakeSlurm(t)
+
+	job := newFakeSlurmJob(t, "1", "cpu-4", 1)
+	app := job.Status.ResolvedApplication.Value
+	app.Invocation.Parameters = []orc.ApplicationParameter{{Name: "n", Type: orc.ApplicationParameterTypeInteger}}
+	app.Invocation.Sbatch = map[string]orc.InvocationParameter{"gres": orc.InvocationVar("n")}
+	job.Status.ResolvedApplication.Set(app)
+	job.Specification.Sweep.Set(orc.JobSweep{
+		Matrix: map[string][]orc.AppParameterValue{
+			"n": {orc.AppParameterValueInteger(1), orc.AppParameterValueInteger(2)},
+		},
+	})
+
+	if result := CreateSBatchFile(job, t.TempDir(), "proj_cpu"); result.Error == nil {
+		t.Fatalf("expected a sweep with different directives to be rejected:\n%s", result.Content)
+	}
+
+	// Directives which are identical for all tasks are fine
+	job.Specification.Sweep.Set(orc.JobSweep{
+		Matrix: map[string][]orc.AppParameterValue{
+			"n": {orc.AppParameterValueInteger(1), orc.AppParameterValueInteger(1)},
+		},
+	})
+
+	result := CreateSBatchFile(job, t.TempDir(), "proj_cpu")
+	if result.Error != nil {
+		t.Fatalf("failed to create sbatch file: %v", result.Error)
+	}
+	if !strings.Contains(result.Content, "#SBATCH --gres '1'") {
+		t.Errorf("expected the shared directive to be present:\n%s", result.Content)
+	}
+}
+
+type fakeAccountMapper struct {
+	AccountMapperService
+	configs map[int]SlurmJobConfiguration
+}
+
+func (m *fakeAccountMapper) ServerSlurmJobToConfiguration(job *slurm.Job) util.Option[SlurmJobConfiguration] {
+	result, ok := m.configs[job.JobID]
+	if !ok {
+		return util.OptNone[SlurmJobConfiguration]()
+	}
+	return util.OptValue(result)
+}
+
+func TestSlurmJobsToRegister(t *testing.T) {
+	setupFakeSlurm(t)
+
+	submitScript := func(script string) int {
+		scriptPath := filepath.Join(t.TempDir(), "job.sh")
+		if err := os.WriteFile(scriptPath, []byte("#!/bin/bash\n#SBATCH --account proj_cpu\n"+script+"sleep 10\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		slurmId, err := SlurmClient.JobSubmit(scriptPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return slurmId
+	}
+
+	external := submitScript("#SBATCH --partition normal\n")
+	unmapped := submitScript("#SBATCH --partition normal\n")
+	fromUCloud := submitFakeSlurmJob(t, newFakeSlurmJob(t, "1", "cpu-4", 1))
+
+	cpu4Machine := findMachine(t, "cpu-4")
+	cpu4 := cpu4Machine.ToReference()
+	mapper := &fakeAccountMapper{configs: map[int]SlurmJobConfiguration{}}
+	for _, slurmId := range []int{external, fromUCloud} {
+		mapper.configs[slurmId] = SlurmJobConfiguration{
+			UCloudUsername:     "alice#1234",
+			Owner:              apm.WalletOwnerProject("project"),
+			EstimatedProduct:   cpu4,
+			EstimatedNodeCount: 1,
+		}
+	}
+
+	var unknown []*slurm.Job
+	for _, slurmId := range []int{external, unmapped, fromUCloud} {
+		job, _ := SlurmClient.JobQuery(slurmId)
+		unknown = append(unknown, job)
+	}
+	unknown = append(unknown, &slurm.Job{JobID: 9999, Name: "no account"})
+	mapper.configs[9999] = mapper.configs[external]
+
+	registrations := slurmJobsToRegister(unknown, mapper)
+	if len(registrations) != 1 {
+		t.Fatalf("expected only the external job to be registered, got %v", registrations)
+	}
+
+	parsed, ok := parseJobProviderId(registrations[0].ProviderGeneratedId.Value)
+	if !ok || parsed.SlurmId != external {
+		t.Errorf("unexpected registration %+v", registrations[0])
+	}
+	if registrations[0].Spec.Product != cpu4 || registrations[0].Spec.Application != unknownApplication {
+		t.Errorf("unexpected specification %+v", registrations[0].Spec)
+	}
+}
+
+type fakeAccounting struct {
+	usage map[SlurmAccountOwner]int64
+}
+
+func (f *fakeAccounting) OnWalletUpdated(update *ctrl.EventWalletUpdated) {}
+
+func (f *fakeAccounting) FetchUsageInMinutes() map[SlurmAccountOwner]int64 {
+	return f.usage
+}
+
+func TestLoopAccountingReportsUsageInChunks(t *testing.T) {
+	setupFakeSlurm(t)
+
+	var mu sync.Mutex
+	var requests [][]apm.ReportUsageRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/reportUsage") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var req fnd.BulkRequest[apm.ReportUsageRequest]
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		requests = append(requests, req.Items)
+		mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(fnd.BulkResponse[bool]{Responses: make([]bool, len(req.Items))})
+	}))
+	defer server.Close()
+
+	previousClient := rpc.DefaultClient
+	previousAccounting := Accounting
+	previousNextTime := nextComputeAccountingTime
+	t.Cleanup(func() {
+		rpc.DefaultClient = previousClient
+		Accounting = previousAccounting
+		nextComputeAccountingTime = previousNextTime
+	})
+
+	usage := map[SlurmAccountOwner]int64{}
+	for i := 0; i < 501; i++ {
+		owner := SlurmAccountOwner{AssociatedWithCategory: "cpu", Owner: apm.WalletOwnerProject(fmt.Sprintf("p%d", i))}
+		usage[owner] = int64(i)
+	}
+
+	rpc.DefaultClient = &rpc.Client{BasePath: server.URL, Client: server.Client()}
+	Accounting = &fakeAccounting{usage: usage}
+	nextComputeAccountingTime = time.Now().Add(-time.Second)
+
+	loopAccounting()
+
+	mu.Lock()
+	if len(requests) != 2 || len(requests[0])+len(requests[1]) != 501 || len(requests[0]) > 500 {
+		t.Errorf("expected usage to be reported in two chunks, got %d requests", len(requests))
+	}
+	reported := map[string]int64{}
+	for _, chunk := range requests {
+		for _, item := range chunk {
+			reported[item.Owner.ProjectId] = item.Usage
+			if item.CategoryIdV2.Name != "cpu" || item.CategoryIdV2.Provider != "slurm" || item.IsDeltaCharge {
+				t.Errorf("unexpected report %+v", item)
+			}
+		}
+	}
+	if reported["p42"] != 42 {
+		t.Errorf("expected 42 core minutes for p42, got %d", reported["p42"])
+	}
+	mu.Unlock()
+
+	// Usage is only reported periodically
+	loopAccounting()
+
+	mu.Lock()
+	if len(requests) != 2 {
+		t.Errorf("expected no usage to be reported before the next interval, got %d requests", len(requests))
+	}
+	mu.Unlock()
+}
+
+func TestSlurmJobArraysWithInteractivePorts(t *testing.T) {
+	setupFakeSlurm(t)
+
+	job := newFakeSlurmJob(t, "1", "cpu-4", 1)
+	app := job.Status.ResolvedApplication.Value
+	app.Invocation.ApplicationType = orc.ApplicationTypeWeb
+	app.Invocation.Parameters = []orc.ApplicationParameter{{Name: "n", Type: orc.ApplicationParameterTypeInteger}}
+	app.Invocation.Invocation = append(app.Invocation.Invocation, orc.InvocationVar("n"))
+	job.Status.ResolvedApplication.Set(app)
+	job.Specification.Sweep.Set(orc.JobSweep{
+		Matrix: map[string][]orc.AppParameterValue{
+			"n": {orc.AppParameterValueInteger(10), orc.AppParameterValueInteger(20), orc.AppParameterValueInteger(30)},
+		},
+	})
+
+	jobFolder := t.TempDir()
+	result := CreateSBatchFile(job, jobFolder, "proj_cpu")
+	if result.Error != nil {
+		t.Fatalf("failed to create sbatch file: %v", result.Error)
+	}
+
+	portFiles := map[string]bool{}
+	taskPattern := regexp.MustCompile(`(?m)^(\d+)\)\nexport UCLOUD_PORT=(\d+)\necho (\d+) > '([^']+)'$`)
+	for _, match := range taskPattern.FindAllStringSubmatch(result.Content, -1) {
+		task, exported, written, file := match[1], match[2], match[3], match[4]
+		if exported != written {
+			t.Errorf("task %s exports port %s but writes %s", task, exported, written)
+		}
+		if file != filepath.Join(jobFolder, "allocated-port-"+task+".txt") {
+			t.Errorf("task %s writes its port to %s", task, file)
+		}
+		portFiles[file] = true
+	}
+
+	if len(portFiles) != 3 {
+		t.Fatalf("expected every task to write its own port file:\n%s", result.Content)
+	}
+
+	if file := allocatedPortFileForJob(job); file != "allocated-port-0.txt" {
+		t.Errorf("expected interactive sessions to use the port of the first task, got %s", file)
+	}
+
+	job.Specification.Sweep.Clear()
+	if file := allocatedPortFileForJob(job); file != AllocatedPortFile {
+		t.Errorf("expected regular jobs to use %s, got %s", AllocatedPortFile, file)
+	}
+}
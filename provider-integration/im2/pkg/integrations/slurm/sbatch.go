@@ -3,6 +3,7 @@ package slurm
 import (
 	"bytes"
 	"fmt"
+	"maps"
 	"math/rand"
 	"net/http"
 	"path/filepath"
@@ -428,6 +429,9 @@ func prepareDefaultEnvironment(
 		directives["partition"] = orc.EscapeBash(machineConfig.Partition)
 		directives["parsable"] = ""
 		directives["comment"] = orc.EscapeBash(ucloudSlurmComment)
+		if dependency := slurmDependencyDirective(job); dependency != "" {
+			directives["dependency"] = orc.EscapeBash(dependency)
+		}
 	}
 
 	// Jinja context
@@ -626,6 +630,82 @@ type SBatchResult struct {
 }
 
 func CreateSBatchFile(job *orc.Job, jobFolder string, accountName string) SBatchResult {
+	if job.Specification.Sweep.Present {
+		return createArraySBatchFile(job, jobFolder, accountName)
+	}
+
+	directives, body, result := createSBatchParts(job, jobFolder, accountName, AllocatedPortFile)
+	if result.Error == nil {
+		result.Content = sbatchHeader(directives) + body
+	}
+	return result
+}
+
+// createArraySBatchFile creates a single sbatch file for all tasks of a job array. The directives are shared by all
+// tasks, while the body of the script is selected based on the array task ID. Sweeps which render different directives
+// for different tasks are rejected.
+func createArraySBatchFile(job *orc.Job, jobFolder string, accountName string) SBatchResult {
+	taskParameters := job.Specification.ArrayTaskParameters()
+
+	var directives map[string]string
+	var result SBatchResult
+	body := &strings.Builder{}
+	appendLine(body, "case \"$SLURM_ARRAY_TASK_ID\" in")
+
+	for task, parameters := range taskParameters {
+		taskJob := *job
+		taskJob.Specification.Parameters = parameters
+		taskJob.Specification.Sweep.Clear()
+
+		// Every task allocates its own port, tasks running on the same node must not write to the same file
+		taskDirectives, taskBody, taskResult := createSBatchParts(&taskJob, jobFolder, accountName, allocatedPortFileForTask(task))
+		if taskResult.Error != nil {
+			return taskResult
+		}
+
+		if task == 0 {
+			directives = taskDirectives
+			result = taskResult
+		} else if !maps.Equal(directives, taskDirectives) {
+			// A job array shares a single set of directives, tasks cannot request different resources.
+			return SBatchResult{
+				Error: (&util.HttpError{
+					StatusCode: http.StatusBadRequest,
+					Why:        "The parameters of this sweep change the Slurm options of the job. Submit the tasks as separate jobs instead.",
+				}).AsError(),
+			}
+		}
+
+		appendLine(body, "%d)", task)
+		body.WriteString(taskBody)
+		appendLine(body, ";;")
+	}
+
+	appendLine(body, "esac")
+
+	arraySpec := fmt.Sprintf("0-%d", len(taskParameters)-1)
+	if limit := job.Specification.Sweep.Value.MaxConcurrency; limit > 0 {
+		arraySpec += fmt.Sprintf("%%%d", limit)
+	}
+	directives["array"] = arraySpec
+	directives["output"] = orc.EscapeBash("stdout-%a.txt")
+	directives["error"] = orc.EscapeBash("stderr-%a.txt")
+
+	result.Content = sbatchHeader(directives) + body.String()
+	return result
+}
+
+func sbatchHeader(directives map[string]string) string {
+	builder := &strings.Builder{}
+	appendLine(builder, "#!/usr/bin/env -S bash --login")
+	for k, v := range directives {
+		appendLine(builder, "#SBATCH --%v %v", k, v)
+	}
+	appendLine(builder, "")
+	return builder.String()
+}
+
+func createSBatchParts(job *orc.Job, jobFolder string, accountName string, portFile string) (map[string]string, string, SBatchResult) {
 	application := &job.Status.ResolvedApplication.Value.Invocation
 	tool := &job.Status.ResolvedApplication.Value.Invocation.Tool.Tool.Value
 	jinjaTemplateFile := ""
@@ -633,7 +713,7 @@ func CreateSBatchFile(job *orc.Job, jobFolder string, accountName string) SBatch
 
 	_, ok := ServiceConfig.Compute.Machines[job.Specification.Product.Category]
 	if !ok {
-		return SBatchResult{
+		return nil, "", SBatchResult{
 			Error: (&util.HttpError{
 				StatusCode: http.StatusInternalServerError,
 				Why:        "Unknown product requested",
@@ -778,15 +858,9 @@ func CreateSBatchFile(job *orc.Job, jobFolder string, accountName string) SBatch
 
 	builder := &strings.Builder{}
 	{
-		appendLine(builder, "#!/usr/bin/env -S bash --login")
-		for k, v := range directives {
-			appendLine(builder, "#SBATCH --%v %v", k, v)
-		}
-		appendLine(builder, "")
-
 		if allocatedPort.Present {
 			appendLine(builder, "export UCLOUD_PORT=%d", allocatedPort.Get())
-			appendLine(builder, "echo %d > %v", allocatedPort.Get(), orc.EscapeBash(filepath.Join(jobFolder, AllocatedPortFile)))
+			appendLine(builder, "echo %d > %v", allocatedPort.Get(), orc.EscapeBash(filepath.Join(jobFolder, portFile)))
 			appendLine(builder, "")
 		}
 
@@ -810,8 +884,7 @@ func CreateSBatchFile(job *orc.Job, jobFolder string, accountName string) SBatch
 		appendLine(builder, "%s", cli)
 	}
 
-	return SBatchResult{
-		Content:             builder.String(),
+	return directives, builder.String(), SBatchResult{
 		DynamicTargets:      *targets,
 		JinjaTemplateFile:   jinjaTemplateFile,
 		JinjaParametersFile: jinjaParametersFile,
@@ -832,6 +905,19 @@ func bindUCloudEnvironmentVariables(prefix string, environment map[string]string
 
 const AllocatedPortFile = "allocated-port.txt"
 
+func allocatedPortFileForTask(task int) string {
+	return fmt.Sprintf("allocated-port-%d.txt", task)
+}
+
+// allocatedPortFileForJob returns the file containing the port of a job. Interactive sessions of a job array connect to
+// the first task, which is also the task used for the node list of the array.
+func allocatedPortFileForJob(job *orc.Job) string {
+	if job.Specification.Sweep.Present {
+		return allocatedPortFileForTask(0)
+	}
+	return AllocatedPortFile
+}
+
 func appendLine(builder *strings.Builder, formatString string, args ...any) {
 	builder.WriteString(fmt.Sprintf(formatString+"\n", args...))
 }
@@ -844,11 +930,39 @@ func formatTimeNumber(value int) string {
 	}
 }
 
+// slurmDependencyDirective translates the dependencies of a job into a value for the dependency directive. UCloud only
+// submits a job with unsatisfied dependencies if all the upstream jobs were submitted to this provider. Upstream jobs
+// which are no longer active have already been checked by UCloud and are left out, since Slurm might have forgotten
+// about them.
+func slurmDependencyDirective(job *orc.Job) string {
+	var conditions []string
+	for _, dep := range job.Specification.Dependencies {
+		upstream, ok := controller.JobRetrieve(dep.JobId)
+		if !ok || upstream.Status.State.IsFinal() {
+			continue
+		}
+
+		providerId, ok := parseJobProviderId(upstream.ProviderGeneratedId)
+		if !ok {
+			continue
+		}
+
+		kind := "afterany"
+		if dep.Type == orc.JobDependencyAfterOk {
+			kind = "afterok"
+		}
+		conditions = append(conditions, fmt.Sprintf("%s:%d", kind, providerId.SlurmId))
+	}
+	return strings.Join(conditions, ",")
+}
+
 var directivesWhichCannotBeChanged = []string{
 	"account",
 	"partition",
 	"parsable",
 	"comment",
+	"array",
+	"dependency",
 }
 
 const ucloudSlurmComment = "UCloud job"
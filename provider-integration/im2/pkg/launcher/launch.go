@@ -134,6 +134,7 @@ func Launch() {
 		gateway.Initialize(gateway.Config{
 			ListenAddress: "0.0.0.0",
 			Port:          8889,
+			TlsPort:       8890,
 		}, gatewayConfigChannel)
 
 		dbConfig := &cfg.Server.Database
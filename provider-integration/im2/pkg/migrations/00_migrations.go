@@ -61,4 +61,8 @@ func Init() {
 	db.AddMigration(activityCatalogV1())
 	db.AddMigration(activityCatalogV2())
 	db.AddMigration(k8sV3())
+	db.AddMigration(k8sV4())
+	db.AddMigration(k8sV5())
+	db.AddMigration(ingressDatabaseV2())
+	db.AddMigration(activityCatalogV3())
 }
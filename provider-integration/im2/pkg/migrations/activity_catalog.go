@@ -56,3 +56,20 @@ func activityCatalogV2() db.MigrationScript {
 		},
 	}
 }
+
+func activityCatalogV3() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "activityCatalogV3",
+		Execute: func(tx *db.Transaction) {
+			db.Exec(tx, `
+				create table fs_retention_warnings(
+					drive_id text not null references tracked_drives(drive_id) on delete cascade,
+					warned_at timestamptz not null,
+					file_count bigint not null,
+					size_in_bytes bigint not null,
+					primary key (drive_id, warned_at)
+				);
+			`, db.Params{})
+		},
+	}
+}
@@ -36,3 +36,41 @@ func ingressDatabaseV1() db.MigrationScript {
 		},
 	}
 }
+
+func ingressDatabaseV2() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "ingressDatabaseV2",
+		Execute: func(tx *db.Transaction) {
+			db.Exec(
+				tx,
+				`
+					create table ingress_access_passwords(
+						resource_id text not null primary key,
+						hashed_password bytea not null,
+						salt bytea not null
+					)
+			    `,
+				db.Params{},
+			)
+
+			db.Exec(
+				tx,
+				`
+					create table ingress_access_sessions(
+						token text not null primary key,
+						resource_id text not null,
+						username text not null,
+						expires_at timestamptz not null
+					)
+			    `,
+				db.Params{},
+			)
+
+			db.Exec(
+				tx,
+				`create index on ingress_access_sessions(resource_id)`,
+				db.Params{},
+			)
+		},
+	}
+}
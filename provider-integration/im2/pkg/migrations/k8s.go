@@ -90,3 +90,47 @@ func k8sV3() db.MigrationScript {
 		},
 	}
 }
+
+func k8sV4() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "k8sV4",
+		Execute: func(tx *db.Transaction) {
+			db.Exec(tx, `
+				create table k8s.scheduler_usage(
+					scheduler text not null,
+					workspace text not null,
+					usage float8 not null,
+					updated_at timestamptz not null default now(),
+					primary key(scheduler, workspace)
+				)
+			`, db.Params{})
+		},
+	}
+}
+
+func k8sV5() db.MigrationScript {
+	return db.MigrationScript{
+		Id: "k8sV5",
+		Execute: func(tx *db.Transaction) {
+			db.Exec(tx, `
+				create table k8s.custom_domains(
+					ingress_id text not null primary key,
+					domain text not null unique,
+					challenge_token text not null,
+					verified bool not null default false,
+					certificate text not null default '',
+					private_key text not null default '',
+					expires_at timestamptz default null,
+					last_error text not null default ''
+				)
+			`, db.Params{})
+
+			db.Exec(tx, `
+				create table k8s.acme_accounts(
+					directory text not null primary key,
+					private_key text not null
+				)
+			`, db.Params{})
+		},
+	}
+}
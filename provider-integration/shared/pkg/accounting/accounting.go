@@ -163,6 +163,56 @@ var UpdateAllocation = rpc.Call[fnd.BulkRequest[UpdateAllocationRequest], util.E
 	Roles:       rpc.RolesEndUser,
 }
 
+// TransferQuotaRequest moves Amount of quota from one allocation to another. Both allocations must have been
+// created by the same parent wallet, which must be owned by the workspace of the caller. Each transfer is applied
+// atomically.
+type TransferQuotaRequest struct {
+	SourceAllocationId int64  `json:"sourceAllocationId"`
+	TargetAllocationId int64  `json:"targetAllocationId"`
+	Amount             int64  `json:"amount"`
+	Reason             string `json:"reason"`
+}
+
+var TransferQuota = rpc.Call[fnd.BulkRequest[TransferQuotaRequest], util.Empty]{
+	BaseContext: AccountingNamespace,
+	Operation:   "transferQuota",
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+}
+
+type AllocationHistoryChangeType string
+
+const (
+	AllocationHistoryTransferIn  AllocationHistoryChangeType = "TRANSFER_IN"
+	AllocationHistoryTransferOut AllocationHistoryChangeType = "TRANSFER_OUT"
+)
+
+type AllocationHistoryEntry struct {
+	AllocationId        int64                       `json:"allocationId"`
+	Timestamp           fnd.Timestamp               `json:"timestamp"`
+	ChangedBy           string                      `json:"changedBy"`
+	Type                AllocationHistoryChangeType `json:"type"`
+	QuotaBefore         int64                       `json:"quotaBefore"`
+	QuotaAfter          int64                       `json:"quotaAfter"`
+	RelatedAllocationId util.Option[int64]          `json:"relatedAllocationId"`
+	Reason              string                      `json:"reason"`
+}
+
+type RetrieveAllocationHistoryRequest struct {
+	AllocationId int64 `json:"allocationId"`
+}
+
+type RetrieveAllocationHistoryResponse struct {
+	Entries []AllocationHistoryEntry `json:"entries"`
+}
+
+var RetrieveAllocationHistory = rpc.Call[RetrieveAllocationHistoryRequest, RetrieveAllocationHistoryResponse]{
+	BaseContext: AccountingNamespace,
+	Operation:   "allocationHistory",
+	Convention:  rpc.ConventionRetrieve,
+	Roles:       rpc.RolesEndUser,
+}
+
 type WalletsBrowseRequest struct {
 	ItemsPerPage                   int                      `json:"itemsPerPage"`
 	Next                           util.Option[string]      `json:"next"`
@@ -204,6 +254,10 @@ type ReportUsageRequest struct {
 type ChargeDescription struct {
 	Scope       util.Option[string] `json:"scope"`
 	Description util.Option[string] `json:"description"`
+
+	// Product is the name of the product (in the category) which was used, if the usage can be attributed to a single
+	// product. It is used to derive quantities and unit prices in the usage export.
+	Product util.Option[string] `json:"product"`
 }
 
 var ReportUsage = rpc.Call[fnd.BulkRequest[ReportUsageRequest], fnd.BulkResponse[bool]]{
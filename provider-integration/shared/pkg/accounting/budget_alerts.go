@@ -0,0 +1,67 @@
+package apm
+
+import (
+	fnd "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+type BudgetAlertType string
+
+const (
+	// BudgetAlertUsagePercent triggers when the active usage of a wallet reaches Threshold percent of its active quota.
+	BudgetAlertUsagePercent BudgetAlertType = "USAGE_PERCENT"
+
+	// BudgetAlertDailyBurnRate triggers when more than Threshold units have been used within the last 24 hours.
+	BudgetAlertDailyBurnRate BudgetAlertType = "DAILY_BURN_RATE"
+)
+
+var BudgetAlertTypeOptions = []BudgetAlertType{
+	BudgetAlertUsagePercent,
+	BudgetAlertDailyBurnRate,
+}
+
+type BudgetAlertSpecification struct {
+	Category  ProductCategoryIdV2 `json:"category"`
+	Type      BudgetAlertType     `json:"type"`
+	Threshold int64               `json:"threshold"`
+}
+
+type BudgetAlertStatus struct {
+	Triggered       bool                       `json:"triggered"`
+	LastTriggeredAt util.Option[fnd.Timestamp] `json:"lastTriggeredAt"`
+}
+
+type BudgetAlert struct {
+	Id            string                   `json:"id"`
+	Owner         WalletOwner              `json:"owner"`
+	CreatedBy     string                   `json:"createdBy"`
+	CreatedAt     fnd.Timestamp            `json:"createdAt"`
+	Specification BudgetAlertSpecification `json:"specification"`
+	Status        BudgetAlertStatus        `json:"status"`
+}
+
+const budgetAlertsBaseContext = "budgetAlerts"
+
+type BudgetAlertsBrowseRequest struct {
+	ItemsPerPage int                 `json:"itemsPerPage"`
+	Next         util.Option[string] `json:"next"`
+}
+
+var BudgetAlertsBrowse = rpc.Call[BudgetAlertsBrowseRequest, fnd.PageV2[BudgetAlert]]{
+	BaseContext: budgetAlertsBaseContext,
+	Convention:  rpc.ConventionBrowse,
+	Roles:       rpc.RolesEndUser,
+}
+
+var BudgetAlertsCreate = rpc.Call[fnd.BulkRequest[BudgetAlertSpecification], fnd.BulkResponse[fnd.FindByStringId]]{
+	BaseContext: budgetAlertsBaseContext,
+	Convention:  rpc.ConventionCreate,
+	Roles:       rpc.RolesEndUser,
+}
+
+var BudgetAlertsDelete = rpc.Call[fnd.BulkRequest[fnd.FindByStringId], util.Empty]{
+	BaseContext: budgetAlertsBaseContext,
+	Convention:  rpc.ConventionDelete,
+	Roles:       rpc.RolesEndUser,
+}
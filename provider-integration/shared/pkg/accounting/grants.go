@@ -48,6 +48,10 @@ type GrantRequestSettings struct {
 	AllowRequestsFrom   []UserCriteria `json:"allowRequestsFrom"`
 	ExcludeRequestsFrom []UserCriteria `json:"excludeRequestsFrom"`
 	Templates           Templates      `json:"templates"`
+
+	// ReviewQuorum is the number of submitted reviews required before the grant giver can approve an application. A
+	// value of 0 disables the requirement.
+	ReviewQuorum int `json:"reviewQuorum"`
 }
 
 func (s GrantRequestSettings) MarshalJSON() ([]byte, error) {
@@ -57,6 +61,7 @@ func (s GrantRequestSettings) MarshalJSON() ([]byte, error) {
 		AllowRequestsFrom   []UserCriteria `json:"allowRequestsFrom"`
 		ExcludeRequestsFrom []UserCriteria `json:"excludeRequestsFrom"`
 		Templates           Templates      `json:"templates"`
+		ReviewQuorum        int            `json:"reviewQuorum"`
 	}
 
 	if s.AllowRequestsFrom == nil {
@@ -72,6 +77,7 @@ func (s GrantRequestSettings) MarshalJSON() ([]byte, error) {
 		AllowRequestsFrom:   s.AllowRequestsFrom,
 		ExcludeRequestsFrom: s.ExcludeRequestsFrom,
 		Templates:           s.Templates,
+		ReviewQuorum:        s.ReviewQuorum,
 	}
 
 	return json.Marshal(w)
@@ -180,6 +186,10 @@ type GrantDocument struct {
 	ReferenceIds       util.Option[[]string] `json:"referenceIds"`
 	RevisionComment    util.Option[string]   `json:"revisionComment"`
 	AllocationPeriod   util.Option[Period]   `json:"allocationPeriod"`
+
+	// CallId references the GrantCall which the application was submitted to. The call of an application cannot be
+	// changed once it has been submitted.
+	CallId util.Option[string] `json:"callId"`
 }
 
 type FormType string
@@ -605,6 +615,10 @@ type GrantStatus struct {
 	ProjectPI          string                    `json:"projectPI"`
 	HasUnreadComments  bool                      `json:"hasUnreadComments"`
 	ApplicationHistory []GrantApplication        `json:"applicationHistory"`
+
+	// Reviews contains the review assignments of the application. Reviews are never shown to the applicant. A grant
+	// giver can only see the reviews of their own project, and a reviewer can only see their own review.
+	Reviews []GrantReviewAssignment `json:"reviews"`
 }
 
 type GrantGiverApprovalState struct {
@@ -637,6 +651,90 @@ type GrantComment struct {
 	Comment   string           `json:"comment"`
 }
 
+type GrantReviewAssignment struct {
+	GrantGiver string                   `json:"grantGiver"`
+	Reviewer   string                   `json:"reviewer"`
+	AssignedBy string                   `json:"assignedBy"`
+	AssignedAt fnd.Timestamp            `json:"assignedAt"`
+	Review     util.Option[GrantReview] `json:"review"`
+}
+
+type GrantReview struct {
+	SubmittedAt    fnd.Timestamp      `json:"submittedAt"`
+	RevisionNumber int                `json:"revisionNumber"`
+	Scores         []GrantReviewScore `json:"scores"`
+	Comment        string             `json:"comment"`
+}
+
+// GrantReviewScore is the score given to a single field of the grant giver's template. The field is referenced by
+// its name. The GrantReviewOverallField is always scored, regardless of the template.
+type GrantReviewScore struct {
+	Field string `json:"field"`
+	Score int    `json:"score"`
+}
+
+const (
+	GrantReviewOverallField = "overall"
+	GrantReviewMinScore     = 1
+	GrantReviewMaxScore     = 5
+)
+
+// GrantCall is a round of applications run by a grant giver. Applications can only be submitted to a call while it is
+// open, that is, between OpensAt and ClosesAt.
+type GrantCall struct {
+	Id            string                 `json:"id"`
+	GrantGiver    string                 `json:"grantGiver"`
+	CreatedBy     string                 `json:"createdBy"`
+	CreatedAt     fnd.Timestamp          `json:"createdAt"`
+	Specification GrantCallSpecification `json:"specification"`
+	Status        GrantCallStatus        `json:"status"`
+}
+
+type GrantCallSpecification struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	OpensAt     fnd.Timestamp `json:"opensAt"`
+	ClosesAt    fnd.Timestamp `json:"closesAt"`
+
+	// Templates replaces the templates of the grant giver for applications submitted to this call. The templates of
+	// the grant giver are used if no templates are specified.
+	Templates util.Option[Templates] `json:"templates"`
+
+	// BudgetCaps limits the total amount which can be approved in this call per product category. Categories without
+	// a cap are only limited by the resources of the grant giver.
+	BudgetCaps []GrantCallBudgetCap `json:"budgetCaps"`
+
+	// AllocationPeriod, if specified, is used for all applications submitted to the call. The period selected by the
+	// applicant is ignored.
+	AllocationPeriod util.Option[Period] `json:"allocationPeriod"`
+}
+
+type GrantCallBudgetCap struct {
+	Category ProductCategoryIdV2 `json:"category"`
+	Cap      int64               `json:"cap"`
+}
+
+type GrantCallStatus struct {
+	IsOpen bool `json:"isOpen"`
+}
+
+type GrantCallSummary struct {
+	Call         GrantCall                  `json:"call"`
+	Applications int                        `json:"applications"`
+	Categories   []GrantCallCategorySummary `json:"categories"`
+}
+
+// GrantCallCategorySummary summarizes the requests for a single product category in a call. Requested contains both
+// approved applications and applications which are still in progress. Available is the budget cap of the call or, if
+// the call has no cap for this category, the current quota of the grant giver.
+type GrantCallCategorySummary struct {
+	Category  ProductCategoryIdV2 `json:"category"`
+	Requested int64               `json:"requested"`
+	Approved  int64               `json:"approved"`
+	Available int64               `json:"available"`
+	HasCap    bool                `json:"hasCap"`
+}
+
 // API
 // =====================================================================================================================
 
@@ -650,6 +748,7 @@ type GrantsBrowseRequest struct {
 	Filter                      util.Option[GrantApplicationFilter] `json:"filter"`
 	IncludeIngoingApplications  util.Option[bool]                   `json:"includeIngoingApplications"`
 	IncludeOutgoingApplications util.Option[bool]                   `json:"includeOutgoingApplications"`
+	CallId                      util.Option[string]                 `json:"callId"`
 }
 
 var GrantsBrowse = rpc.Call[GrantsBrowseRequest, fnd.PageV2[GrantApplication]]{
@@ -766,6 +865,87 @@ var GrantsDeleteComment = rpc.Call[GrantsDeleteCommentRequest, util.Empty]{
 	Roles:       rpc.RolesEndUser,
 }
 
+// GrantsUpdateReviewersRequest replaces the reviewers of the grant giver in the active project. Removing a reviewer also
+// removes their review.
+type GrantsUpdateReviewersRequest struct {
+	ApplicationId string   `json:"applicationId"`
+	Reviewers     []string `json:"reviewers"`
+}
+
+var GrantsUpdateReviewers = rpc.Call[GrantsUpdateReviewersRequest, util.Empty]{
+	BaseContext: GrantsNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Operation:   "updateReviewers",
+	Roles:       rpc.RolesEndUser,
+}
+
+type GrantsSubmitReviewRequest struct {
+	ApplicationId string             `json:"applicationId"`
+	Scores        []GrantReviewScore `json:"scores"`
+	Comment       string             `json:"comment"`
+}
+
+var GrantsSubmitReview = rpc.Call[GrantsSubmitReviewRequest, util.Empty]{
+	BaseContext: GrantsNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Operation:   "submitReview",
+	Roles:       rpc.RolesEndUser,
+}
+
+type GrantCallsBrowseRequest struct {
+	ItemsPerPage  int                 `json:"itemsPerPage"`
+	Next          util.Option[string] `json:"next"`
+	GrantGiver    util.Option[string] `json:"grantGiver"`
+	IncludeClosed util.Option[bool]   `json:"includeClosed"`
+}
+
+var GrantCallsBrowse = rpc.Call[GrantCallsBrowseRequest, fnd.PageV2[GrantCall]]{
+	BaseContext: GrantsNamespace,
+	Convention:  rpc.ConventionBrowse,
+	Operation:   "calls",
+	Roles:       rpc.RolesEndUser,
+}
+
+var GrantCallsRetrieve = rpc.Call[fnd.FindByStringId, GrantCall]{
+	BaseContext: GrantsNamespace,
+	Convention:  rpc.ConventionRetrieve,
+	Operation:   "call",
+	Roles:       rpc.RolesEndUser,
+}
+
+var GrantCallsCreate = rpc.Call[GrantCallSpecification, fnd.FindByStringId]{
+	BaseContext: GrantsNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Operation:   "createCall",
+	Roles:       rpc.RolesEndUser,
+}
+
+type GrantCallsUpdateRequest struct {
+	Id            string                 `json:"id"`
+	Specification GrantCallSpecification `json:"specification"`
+}
+
+var GrantCallsUpdate = rpc.Call[GrantCallsUpdateRequest, util.Empty]{
+	BaseContext: GrantsNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Operation:   "updateCall",
+	Roles:       rpc.RolesEndUser,
+}
+
+var GrantCallsDelete = rpc.Call[fnd.FindByStringId, util.Empty]{
+	BaseContext: GrantsNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Operation:   "deleteCall",
+	Roles:       rpc.RolesEndUser,
+}
+
+var GrantCallsRetrieveSummary = rpc.Call[fnd.FindByStringId, GrantCallSummary]{
+	BaseContext: GrantsNamespace,
+	Convention:  rpc.ConventionRetrieve,
+	Operation:   "callSummary",
+	Roles:       rpc.RolesEndUser,
+}
+
 var GrantsBrowseEnabledProjects = rpc.Call[util.Empty, []ProjectToSetting]{
 	BaseContext: GrantsNamespace,
 	Convention:  rpc.ConventionBrowse,
@@ -864,6 +1044,7 @@ type GrantsExportResponse struct {
 	Resources        map[string]int             `json:"resources"`
 	OptionalUserInfo fnd.OptionalUserInfo       `json:"optionalUserInfo"`
 	AnswerFields     map[string]AnswerFieldForm `json:"AnswerFields"`
+	Reviews          []GrantReviewAssignment    `json:"reviews"`
 }
 
 var GrantsExport = rpc.Call[util.Empty, []GrantsExportResponse]{
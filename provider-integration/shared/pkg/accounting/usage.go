@@ -43,6 +43,9 @@ type UsageReport struct {
 	Kpis             UsageReportKpis             `json:"kpis"`
 	SubProjectHealth UsageReportSubProjectHealth `json:"subProjectHealth"`
 	UsageOverTime    UsageReportOverTime         `json:"usageOverTime"`
+
+	// Forecast is not present if the report does not contain enough samples to make a projection
+	Forecast util.Option[UsageReportForecast] `json:"forecast"`
 }
 
 type UsageReportDeltaDataPoint struct {
@@ -97,6 +100,28 @@ type UsageReportSubProjectHealth struct {
 	Idle int `json:"idle"`
 }
 
+// UsageReportForecast projects the usage of a report forward in time. The projection is a linear fit of the most recent
+// absolute data points and is made from the end of the report. The low and high values form a confidence band around
+// the projection.
+type UsageReportForecast struct {
+	SampleCount int `json:"sampleCount"`
+
+	UsagePerDay     float64 `json:"usagePerDay"`
+	UsagePerDayLow  float64 `json:"usagePerDayLow"`
+	UsagePerDayHigh float64 `json:"usagePerDayHigh"`
+
+	// Not present if usage is not projected to reach the quota
+	ProjectedDepletion         util.Option[fnd.Timestamp] `json:"projectedDepletion"`
+	ProjectedDepletionEarliest util.Option[fnd.Timestamp] `json:"projectedDepletionEarliest"`
+	ProjectedDepletionLatest   util.Option[fnd.Timestamp] `json:"projectedDepletionLatest"`
+
+	// Not present if the report has no meaningful allocation expiration
+	AllocationEnd           util.Option[fnd.Timestamp] `json:"allocationEnd"`
+	ProjectedUsageAtEnd     util.Option[int64]         `json:"projectedUsageAtEnd"`
+	ProjectedUsageAtEndLow  util.Option[int64]         `json:"projectedUsageAtEndLow"`
+	ProjectedUsageAtEndHigh util.Option[int64]         `json:"projectedUsageAtEndHigh"`
+}
+
 var UsageRetrieve = rpc.Call[UsageRetrieveRequest, UsageRetrieveResponse]{
 	BaseContext: usageContext,
 	Convention:  rpc.ConventionRetrieve,
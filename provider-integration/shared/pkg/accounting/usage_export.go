@@ -0,0 +1,74 @@
+package apm
+
+import (
+	fnd "ucloud.dk/shared/pkg/foundation"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+type UsageExportFormat string
+
+const (
+	UsageExportFormatCsv   UsageExportFormat = "CSV"
+	UsageExportFormatJsonl UsageExportFormat = "JSONL"
+)
+
+type UsageExportGranularity string
+
+const (
+	UsageExportGranularityDay    UsageExportGranularity = "DAY"
+	UsageExportGranularityMonth  UsageExportGranularity = "MONTH"
+	UsageExportGranularityPeriod UsageExportGranularity = "PERIOD"
+)
+
+type UsageExportRequest struct {
+	Start       fnd.Timestamp          `json:"start"`
+	End         fnd.Timestamp          `json:"end"`
+	Granularity UsageExportGranularity `json:"granularity"`
+	Format      UsageExportFormat      `json:"format"`
+}
+
+// UsageExportRow contains the usage charged to a single wallet within a period. Usage is split by the scope and
+// description of the charges (see ChargeDescription).
+//
+// For capacity-based products, Usage is the sum of the daily usage level within the period (e.g. GB-days). For all
+// other products, Usage is the sum of all charges made within the period.
+type UsageExportRow struct {
+	PeriodStart    fnd.Timestamp       `json:"periodStart"`
+	PeriodEnd      fnd.Timestamp       `json:"periodEnd"`
+	Owner          WalletOwner         `json:"owner"`
+	WorkspaceTitle string              `json:"workspaceTitle"`
+	CostCentre     util.Option[string] `json:"costCentre"`
+	Category       ProductCategoryIdV2 `json:"category"`
+	Allocations    []string            `json:"allocations"`
+	Scope          util.Option[string] `json:"scope"`
+	Description    util.Option[string] `json:"description"`
+
+	// Usage is the raw usage as reported to the accounting system
+	Usage int64 `json:"usage"`
+
+	// Amount is Usage converted to Unit, using the same conversion as product prices
+	Amount float64 `json:"amount"`
+	Unit   string  `json:"unit"`
+
+	// Product is only present if the provider attributed the usage to a single product
+	Product util.Option[string] `json:"product"`
+
+	// Quantity is the number of products used, derived from the price of the product. For periodic products it is
+	// multiplied by the duration in hours (compute) or days (other products). UnitPrice is the price of one quantity in
+	// Unit, such that Amount = Quantity * UnitPrice. Both are only present if Product is present and has a price.
+	Quantity  util.Option[float64] `json:"quantity"`
+	UnitPrice util.Option[float64] `json:"unitPrice"`
+}
+
+type UsageExportResponse struct {
+	FileName string `json:"fileName"`
+	Data     string `json:"data"`
+}
+
+var UsageExport = rpc.Call[UsageExportRequest, UsageExportResponse]{
+	BaseContext: AccountingNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Operation:   "exportUsage",
+	Roles:       rpc.RolesEndUser,
+}
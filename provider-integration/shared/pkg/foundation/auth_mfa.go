@@ -1,6 +1,7 @@
 package foundation
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"ucloud.dk/shared/pkg/rpc"
@@ -23,6 +24,10 @@ var AuthMfaCreateCredentials = rpc.Call[util.Empty, MfaCredentials]{
 type MfaChallengeAnswer struct {
 	ChallengeId      string `json:"challengeId"`
 	VerificationCode string `json:"verificationCode"`
+
+	// WebAuthnResponse is the PublicKeyCredential returned by navigator.credentials.get(). It can be used instead of
+	// the VerificationCode if the user has registered a WebAuthn credential.
+	WebAuthnResponse json.RawMessage `json:"webAuthnResponse,omitempty"`
 }
 
 var AuthMfaAnswerChallenge = rpc.Call[MfaChallengeAnswer, util.Empty]{
@@ -58,3 +63,76 @@ var AuthMfaStatus = rpc.Call[util.Empty, MfaStatus]{
 	Convention:  rpc.ConventionQueryParameters,
 	Roles:       rpc.RolesEndUser,
 }
+
+// WebAuthn
+// =====================================================================================================================
+
+type MfaWebAuthnCredential struct {
+	Id         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	CreatedAt  Timestamp              `json:"createdAt"`
+	LastUsedAt util.Option[Timestamp] `json:"lastUsedAt"`
+}
+
+var AuthMfaBrowseWebAuthnCredentials = rpc.Call[util.Empty, BulkResponse[MfaWebAuthnCredential]]{
+	BaseContext: AuthMfaContext,
+	Operation:   "webAuthn",
+	Convention:  rpc.ConventionBrowse,
+	Roles:       rpc.RolesEndUser,
+}
+
+var AuthMfaDeleteWebAuthnCredential = rpc.Call[FindByStringId, util.Empty]{
+	BaseContext: AuthMfaContext,
+	Operation:   "webAuthn",
+	Convention:  rpc.ConventionDelete,
+	Roles:       rpc.RolesEndUser,
+}
+
+type MfaWebAuthnBeginRegistrationRequest struct {
+	Name string `json:"name"`
+}
+
+type MfaWebAuthnBeginRegistrationResponse struct {
+	SessionId string `json:"sessionId"`
+
+	// Options are the PublicKeyCredentialCreationOptions which should be passed to navigator.credentials.create()
+	Options json.RawMessage `json:"options"`
+}
+
+var AuthMfaBeginWebAuthnRegistration = rpc.Call[MfaWebAuthnBeginRegistrationRequest, MfaWebAuthnBeginRegistrationResponse]{
+	BaseContext: AuthMfaContext,
+	Operation:   "beginWebAuthnRegistration",
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+}
+
+type MfaWebAuthnFinishRegistrationRequest struct {
+	SessionId string `json:"sessionId"`
+
+	// Credential is the PublicKeyCredential returned by navigator.credentials.create()
+	Credential json.RawMessage `json:"credential"`
+}
+
+var AuthMfaFinishWebAuthnRegistration = rpc.Call[MfaWebAuthnFinishRegistrationRequest, FindByStringId]{
+	BaseContext: AuthMfaContext,
+	Operation:   "finishWebAuthnRegistration",
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+}
+
+type MfaWebAuthnChallengeRequest struct {
+	ChallengeId string `json:"challengeId"`
+}
+
+type MfaWebAuthnChallengeResponse struct {
+	// Options are the PublicKeyCredentialRequestOptions which should be passed to navigator.credentials.get(). The
+	// result is sent as the WebAuthnResponse of the challenge answer.
+	Options json.RawMessage `json:"options"`
+}
+
+var AuthMfaWebAuthnChallenge = rpc.Call[MfaWebAuthnChallengeRequest, MfaWebAuthnChallengeResponse]{
+	BaseContext: AuthMfaContext,
+	Operation:   "webAuthnChallenge",
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesPublic,
+}
@@ -22,6 +22,7 @@ type EmailSettings struct {
 	UserRoleChange            bool `json:"userRoleChange"`
 	UserLeft                  bool `json:"userLeft"`
 	LowFunds                  bool `json:"lowFunds"`
+	BudgetAlerts              bool `json:"budgetAlerts"`
 	JobStarted                bool `json:"jobStarted"`
 	JobStopped                bool `json:"jobStopped"`
 }
@@ -41,6 +42,7 @@ var DefaultEmailSettings = EmailSettings{
 	UserRoleChange:            true,
 	UserLeft:                  true,
 	LowFunds:                  true,
+	BudgetAlerts:              true,
 	JobStarted:                false,
 	JobStopped:                false,
 }
@@ -55,6 +57,7 @@ const (
 	MailTypeTransferApplication         MailType = "transferApplication"
 	MailTypeLowFunds                    MailType = "lowFunds"
 	MailTypeStillLowFunds               MailType = "stillLowFunds"
+	MailTypeBudgetAlert                 MailType = "budgetAlert"
 	MailTypeUserRoleChange              MailType = "userRoleChange"
 	MailTypeUserLeft                    MailType = "userLeft"
 	MailTypeUserRemoved                 MailType = "userRemoved"
@@ -74,9 +77,38 @@ const (
 	MailTypeNotifyMailChange            MailType = "notifyEmailChange"
 	MailTypeJobEvents                   MailType = "jobEvents"
 	MailTypeSupport                     MailType = "support"
+	MailTypeNotificationDigest          MailType = "notificationDigest"
+	MailTypeFilesRetention              MailType = "filesRetention"
 	MailTypeUnknown                     MailType = "unknown"
 )
 
+// Category returns the notification category of a mail type. Mails without a category, such as password resets, are not
+// affected by the notification preferences.
+func (t MailType) Category() (NotificationCategory, bool) {
+	switch t {
+	case MailTypeTransferApplication, MailTypeNewGrantApplication, MailTypeApplicationUpdated,
+		MailTypeApplicationUpdatedToAdmins, MailTypeApplicationApproved, MailTypeApplicationApprovedToAdmins,
+		MailTypeApplicationRejected, MailTypeApplicationWithdrawn, MailTypeNewComment:
+		return NotificationCategoryGrants, true
+
+	case MailTypeUserRoleChange, MailTypeUserLeft, MailTypeUserRemoved, MailTypeUserRemovedToUser,
+		MailTypeInvitedToProject, MailTypeVerificationReminder:
+		return NotificationCategoryProjects, true
+
+	case MailTypeLowFunds, MailTypeStillLowFunds, MailTypeBudgetAlert:
+		return NotificationCategoryAccounting, true
+
+	case MailTypeJobEvents:
+		return NotificationCategoryJobs, true
+
+	case MailTypeSupport:
+		return NotificationCategorySupport, true
+
+	default:
+		return "", false
+	}
+}
+
 type Mail json.RawMessage
 
 func (m Mail) Type() MailType {
@@ -111,3 +111,120 @@ var NotificationsUpdateSettings = rpc.Call[NotificationSettings, util.Empty]{
 	Convention:  rpc.ConventionUpdate,
 	Roles:       rpc.RolesEndUser,
 }
+
+// Notification preferences
+// =====================================================================================================================
+// Preferences decide how events reach a user. They are keyed by the category of the event and the channel through
+// which it is delivered. The preferences apply on top of the fine-grained NotificationSettings and EmailSettings, an
+// event is only delivered through a channel if both allow it.
+
+type NotificationCategory string
+
+const (
+	NotificationCategoryJobs       NotificationCategory = "JOBS"
+	NotificationCategoryGrants     NotificationCategory = "GRANTS"
+	NotificationCategoryProjects   NotificationCategory = "PROJECTS"
+	NotificationCategoryAccounting NotificationCategory = "ACCOUNTING"
+	NotificationCategoryShares     NotificationCategory = "SHARES"
+	NotificationCategorySupport    NotificationCategory = "SUPPORT"
+)
+
+var NotificationCategories = []NotificationCategory{
+	NotificationCategoryJobs,
+	NotificationCategoryGrants,
+	NotificationCategoryProjects,
+	NotificationCategoryAccounting,
+	NotificationCategoryShares,
+	NotificationCategorySupport,
+}
+
+type NotificationChannel string
+
+const (
+	NotificationChannelInApp  NotificationChannel = "IN_APP"
+	NotificationChannelEmail  NotificationChannel = "EMAIL"
+	NotificationChannelDigest NotificationChannel = "DIGEST"
+)
+
+type NotificationChannelPreferences struct {
+	InApp bool `json:"inApp"`
+	Email bool `json:"email"`
+
+	// Digest includes the notifications of the category in a daily summary email
+	Digest bool `json:"digest"`
+}
+
+type NotificationPreferences struct {
+	Categories map[NotificationCategory]NotificationChannelPreferences `json:"categories"`
+}
+
+// DefaultNotificationPreferences enables in-app and email delivery of every category. Emails are still subject to the
+// EmailSettings of the user, which means that job emails are only sent to users who opted in to them.
+func DefaultNotificationPreferences() NotificationPreferences {
+	result := NotificationPreferences{Categories: map[NotificationCategory]NotificationChannelPreferences{}}
+	for _, category := range NotificationCategories {
+		result.Categories[category] = NotificationChannelPreferences{
+			InApp: true,
+			Email: true,
+		}
+	}
+	return result
+}
+
+// Wants returns true if the user wants to receive events of the category through the channel. Categories missing from
+// the preferences use the default preferences.
+func (p *NotificationPreferences) Wants(category NotificationCategory, channel NotificationChannel) bool {
+	prefs, ok := p.Categories[category]
+	if !ok {
+		prefs, ok = DefaultNotificationPreferences().Categories[category]
+		if !ok {
+			return false
+		}
+	}
+
+	switch channel {
+	case NotificationChannelInApp:
+		return prefs.InApp
+	case NotificationChannelEmail:
+		return prefs.Email
+	case NotificationChannelDigest:
+		return prefs.Digest
+	default:
+		return false
+	}
+}
+
+// NotificationCategoryOf returns the category of a notification type. Notifications without a category are always
+// delivered in-app and never included in the digest.
+func NotificationCategoryOf(notificationType string) (NotificationCategory, bool) {
+	switch {
+	case strings.HasPrefix(notificationType, "JOB_"):
+		return NotificationCategoryJobs, true
+	case strings.Contains(notificationType, "GRANT_"):
+		return NotificationCategoryGrants, true
+	case strings.HasPrefix(notificationType, "PROJECT_"):
+		return NotificationCategoryProjects, true
+	case notificationType == "BUDGET_ALERT" || strings.HasPrefix(notificationType, "ALLOCATION_"):
+		return NotificationCategoryAccounting, true
+	case strings.HasPrefix(notificationType, "SHARE_"):
+		return NotificationCategoryShares, true
+	case strings.HasPrefix(notificationType, "SUPPORT_"):
+		return NotificationCategorySupport, true
+	default:
+		return "", false
+	}
+}
+
+var NotificationsRetrievePreferences = rpc.Call[util.Empty, NotificationPreferences]{
+	BaseContext: NotificationContext,
+	Operation:   "preferences",
+	Convention:  rpc.ConventionRetrieve,
+	Roles:       rpc.RolesEndUser,
+}
+
+var NotificationsUpdatePreferences = rpc.Call[NotificationPreferences, util.Empty]{
+	BaseContext: NotificationContext,
+	Operation:   "preferences",
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+}
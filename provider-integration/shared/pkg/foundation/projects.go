@@ -43,6 +43,14 @@ type ProjectSettings struct {
 	SubProjects struct {
 		AllowRenaming bool `json:"allowRenaming"`
 	} `json:"subProjects"`
+
+	// CostCentre is an optional label used to group usage in accounting exports. It is set by the parent project.
+	CostCentre util.Option[string] `json:"costCentre"`
+}
+
+type ProjectUpdateCostCentreRequest struct {
+	ProjectId  string              `json:"projectId"`
+	CostCentre util.Option[string] `json:"costCentre"`
 }
 
 type ProjectToggleSubProjectRenamingSettingRequest struct {
@@ -267,6 +275,13 @@ var ProjectToggleSubProjectRenamingSetting = rpc.Call[ProjectToggleSubProjectRen
 	Roles:       rpc.RolesEndUser,
 }
 
+var ProjectUpdateCostCentre = rpc.Call[BulkRequest[ProjectUpdateCostCentreRequest], util.Empty]{
+	BaseContext: ProjectContext,
+	Operation:   "updateCostCentre",
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+}
+
 var ProjectRetrieveSubProjectRenamingSetting = rpc.Call[util.Empty, ProjectRetrieveSubProjectRenamingResponse]{
 	BaseContext: ProjectContextV1,
 	Convention:  rpc.ConventionQueryParameters,
@@ -0,0 +1,151 @@
+package foundation
+
+import (
+	"encoding/json"
+
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+type WebhookEventType string
+
+const (
+	WebhookEventJobStateChanged    WebhookEventType = "JOB_STATE_CHANGED"
+	WebhookEventGrantSubmitted     WebhookEventType = "GRANT_SUBMITTED"
+	WebhookEventGrantApproved      WebhookEventType = "GRANT_APPROVED"
+	WebhookEventGrantRejected      WebhookEventType = "GRANT_REJECTED"
+	WebhookEventAllocationLowFunds WebhookEventType = "ALLOCATION_LOW_FUNDS"
+	WebhookEventShareAccepted      WebhookEventType = "SHARE_ACCEPTED"
+	WebhookEventNotification       WebhookEventType = "NOTIFICATION"
+)
+
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventJobStateChanged,
+	WebhookEventGrantSubmitted,
+	WebhookEventGrantApproved,
+	WebhookEventGrantRejected,
+	WebhookEventAllocationLowFunds,
+	WebhookEventShareAccepted,
+	WebhookEventNotification,
+}
+
+type WebhookSpecification struct {
+	// Url is the HTTPS endpoint which will receive a POST request for every event
+	Url         string             `json:"url"`
+	Events      []WebhookEventType `json:"events"`
+	Description string             `json:"description"`
+}
+
+// Webhook is a subscription to events in a workspace. Webhooks created without an active project receive the events of
+// the user who created them. Webhooks created in a project receive the events of the project and can only be managed
+// by project administrators.
+type Webhook struct {
+	Id            string               `json:"id"`
+	CreatedBy     string               `json:"createdBy"`
+	CreatedAt     Timestamp            `json:"createdAt"`
+	Project       util.Option[string]  `json:"project"`
+	Specification WebhookSpecification `json:"specification"`
+}
+
+type WebhookDeliveryState string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryState = "PENDING"
+	WebhookDeliverySucceeded WebhookDeliveryState = "SUCCEEDED"
+	WebhookDeliveryFailed    WebhookDeliveryState = "FAILED"
+)
+
+type WebhookDelivery struct {
+	Id             string                 `json:"id"`
+	WebhookId      string                 `json:"webhookId"`
+	EventType      WebhookEventType       `json:"eventType"`
+	CreatedAt      Timestamp              `json:"createdAt"`
+	State          WebhookDeliveryState   `json:"state"`
+	Attempts       int                    `json:"attempts"`
+	LastAttemptAt  util.Option[Timestamp] `json:"lastAttemptAt"`
+	NextAttemptAt  util.Option[Timestamp] `json:"nextAttemptAt"`
+	ResponseStatus util.Option[int]       `json:"responseStatus"`
+	Error          util.Option[string]    `json:"error"`
+	Payload        json.RawMessage        `json:"payload"`
+}
+
+// WebhookPayload is the body of the request sent to a webhook endpoint.
+//
+// Every request carries the following headers:
+//
+// - UCloud-Webhook-Id: the ID of the webhook
+// - UCloud-Delivery-Id: the ID of the delivery, which is stable across retries
+// - UCloud-Event: the type of the event
+// - UCloud-Timestamp: the time of the attempt in seconds since the Unix epoch
+// - UCloud-Signature: "sha256=" followed by the hex-encoded HMAC-SHA256 of "<timestamp>.<body>" using the webhook secret
+type WebhookPayload struct {
+	DeliveryId string              `json:"deliveryId"`
+	Type       WebhookEventType    `json:"type"`
+	CreatedAt  Timestamp           `json:"createdAt"`
+	Username   util.Option[string] `json:"username"`
+	Project    util.Option[string] `json:"project"`
+	Data       json.RawMessage     `json:"data"`
+}
+
+const WebhooksContext = "webhooks"
+
+type WebhooksCreateResponse struct {
+	Id string `json:"id"`
+
+	// Secret is used to sign deliveries. It is only returned when the webhook is created.
+	Secret string `json:"secret"`
+}
+
+var WebhooksCreate = rpc.Call[BulkRequest[WebhookSpecification], BulkResponse[WebhooksCreateResponse]]{
+	BaseContext: WebhooksContext,
+	Convention:  rpc.ConventionCreate,
+	Roles:       rpc.RolesEndUser,
+}
+
+var WebhooksBrowse = rpc.Call[util.Empty, PageV2[Webhook]]{
+	BaseContext: WebhooksContext,
+	Convention:  rpc.ConventionBrowse,
+	Roles:       rpc.RolesEndUser,
+}
+
+var WebhooksDelete = rpc.Call[BulkRequest[FindByStringId], util.Empty]{
+	BaseContext: WebhooksContext,
+	Convention:  rpc.ConventionDelete,
+	Roles:       rpc.RolesEndUser,
+}
+
+type WebhooksBrowseDeliveriesRequest struct {
+	WebhookId    string              `json:"webhookId"`
+	ItemsPerPage int                 `json:"itemsPerPage"`
+	Next         util.Option[string] `json:"next"`
+}
+
+var WebhooksBrowseDeliveries = rpc.Call[WebhooksBrowseDeliveriesRequest, PageV2[WebhookDelivery]]{
+	BaseContext: WebhooksContext,
+	Operation:   "deliveries",
+	Convention:  rpc.ConventionBrowse,
+	Roles:       rpc.RolesEndUser,
+}
+
+var WebhooksRedeliver = rpc.Call[BulkRequest[FindByStringId], util.Empty]{
+	BaseContext: WebhooksContext,
+	Operation:   "redeliver",
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+}
+
+// WebhookEvent is emitted by other parts of UCloud. The event is delivered to all webhooks of Username (created
+// outside a project) and all webhooks of Project which subscribe to Type.
+type WebhookEvent struct {
+	Type     WebhookEventType    `json:"type"`
+	Username util.Option[string] `json:"username"`
+	Project  util.Option[string] `json:"project"`
+	Data     json.RawMessage     `json:"data"`
+}
+
+var WebhooksEmit = rpc.Call[BulkRequest[WebhookEvent], util.Empty]{
+	BaseContext: WebhooksContext,
+	Operation:   "emit",
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesService,
+}
@@ -64,6 +64,8 @@ type FSSupport struct {
 		StreamingSearchSupported bool `json:"streamingSearchSupported"`
 		SharesSupported          bool `json:"sharesSupported"`
 		OpenInTerminal           bool `json:"openInTerminal"`
+		ArchivesSupported        bool `json:"archivesSupported"`
+		RetentionPolicy          bool `json:"retentionPolicy"`
 	} `json:"files"`
 }
 
@@ -216,6 +218,23 @@ var DrivesControlUpdateLabels = rpc.Call[fnd.BulkRequest[DrivesUpdateLabelsReque
 	Operation:   "updateLabels",
 }
 
+// DrivesControlNotifyRetentionRequest informs the owners of a drive that files will soon be deleted by the retention
+// policy of the provider.
+type DrivesControlNotifyRetentionRequest struct {
+	Id          string        `json:"id"`
+	FileCount   uint64        `json:"fileCount"`
+	SizeInBytes uint64        `json:"sizeInBytes"`
+	MaxAgeDays  int           `json:"maxAgeDays"`
+	DeletesAt   fnd.Timestamp `json:"deletesAt"`
+}
+
+var DrivesControlNotifyRetention = rpc.Call[fnd.BulkRequest[DrivesControlNotifyRetentionRequest], util.Empty]{
+	BaseContext: driveControlNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesProvider,
+	Operation:   "notifyRetention",
+}
+
 // Drive Provider API
 // =====================================================================================================================
 
@@ -3,6 +3,7 @@ package orchestrators
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	apm "ucloud.dk/shared/pkg/accounting"
 	fnd "ucloud.dk/shared/pkg/foundation"
@@ -188,6 +189,36 @@ var FilesVisualize = rpc.Call[FilesVisualizeRequest, FilesVisualizeResponse]{
 	Operation:   "visualize",
 }
 
+type FilesRetentionRequest struct {
+	Path string `json:"path"`
+}
+
+type FilesRetentionEntry struct {
+	Path           string        `json:"path"`
+	SizeInBytes    uint64        `json:"sizeInBytes"`
+	LastAccessedAt fnd.Timestamp `json:"lastAccessedAt"`
+	ExpiresAt      fnd.Timestamp `json:"expiresAt"`
+}
+
+// FilesRetentionResponse is a dry-run of the retention policy. It lists the files which will be deleted within the
+// warning period unless they are accessed before they expire.
+type FilesRetentionResponse struct {
+	MaxAgeDays    int                        `json:"maxAgeDays"`
+	WarningDays   int                        `json:"warningDays"`
+	Entries       []FilesRetentionEntry      `json:"entries"`
+	LastUpdatedAt util.Option[fnd.Timestamp] `json:"lastUpdatedAt"`
+	FileCount     uint64                     `json:"fileCount"`
+	SizeInBytes   uint64                     `json:"sizeInBytes"`
+	Complete      bool                       `json:"complete"`
+}
+
+var FilesRetention = rpc.Call[FilesRetentionRequest, FilesRetentionResponse]{
+	BaseContext: filesNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+	Operation:   "retention",
+}
+
 var FilesRetrieveProducts = rpc.Call[util.Empty, SupportByProvider[FSSupport]]{
 	BaseContext: filesNamespace,
 	Convention:  rpc.ConventionRetrieve,
@@ -295,6 +326,83 @@ var FilesTransfer = rpc.Call[fnd.BulkRequest[FilesTransferRequest], util.Empty]{
 	Operation:   "transfer",
 }
 
+type ArchiveFormat string
+
+const (
+	ArchiveFormatZip    ArchiveFormat = "ZIP"
+	ArchiveFormatTar    ArchiveFormat = "TAR"
+	ArchiveFormatTarGz  ArchiveFormat = "TAR_GZ"
+	ArchiveFormatTarZst ArchiveFormat = "TAR_ZST"
+)
+
+var ArchiveFormats = []ArchiveFormat{
+	ArchiveFormatZip,
+	ArchiveFormatTar,
+	ArchiveFormatTarGz,
+	ArchiveFormatTarZst,
+}
+
+var archiveExtensions = []util.Tuple2[string, ArchiveFormat]{
+	{First: ".tar.gz", Second: ArchiveFormatTarGz},
+	{First: ".tgz", Second: ArchiveFormatTarGz},
+	{First: ".tar.zst", Second: ArchiveFormatTarZst},
+	{First: ".tzst", Second: ArchiveFormatTarZst},
+	{First: ".tar", Second: ArchiveFormatTar},
+	{First: ".zip", Second: ArchiveFormatZip},
+}
+
+// ArchiveFormatFromFileName determines the format of an archive from the extension of its name.
+func ArchiveFormatFromFileName(name string) (ArchiveFormat, bool) {
+	lowerName := strings.ToLower(name)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lowerName, ext.First) {
+			return ext.Second, true
+		}
+	}
+	return "", false
+}
+
+func (f ArchiveFormat) Extension() string {
+	for _, ext := range archiveExtensions {
+		if ext.Second == f {
+			return ext.First
+		}
+	}
+	return ""
+}
+
+// FilesCompressRequest creates an archive at DestinationPath containing SourcePath. The conflict policy applies to the
+// archive itself.
+type FilesCompressRequest struct {
+	SourcePath      string              `json:"sourcePath"`
+	DestinationPath string              `json:"destinationPath"`
+	Format          ArchiveFormat       `json:"format"`
+	ConflictPolicy  WriteConflictPolicy `json:"conflictPolicy"`
+}
+
+var FilesCompress = rpc.Call[fnd.BulkRequest[FilesCompressRequest], fnd.BulkResponse[util.Empty]]{
+	BaseContext: filesNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+	Operation:   "compress",
+}
+
+// FilesExtractRequest extracts the archive at SourcePath into the folder at DestinationPath. The format is determined
+// from the name of the archive. The conflict policy applies to the destination folder. When the policy is REPLACE, the
+// content is merged into an existing folder and existing files are overwritten.
+type FilesExtractRequest struct {
+	SourcePath      string              `json:"sourcePath"`
+	DestinationPath string              `json:"destinationPath"`
+	ConflictPolicy  WriteConflictPolicy `json:"conflictPolicy"`
+}
+
+var FilesExtract = rpc.Call[fnd.BulkRequest[FilesExtractRequest], fnd.BulkResponse[util.Empty]]{
+	BaseContext: filesNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+	Operation:   "extract",
+}
+
 // Files provider
 // =====================================================================================================================
 
@@ -356,6 +464,18 @@ var FilesProviderVisualize = rpc.Call[FilesProviderVisualizeRequest, FilesVisual
 	Operation:   "visualize",
 }
 
+type FilesProviderRetentionRequest struct {
+	Path               string `json:"path"`
+	ResolvedCollection Drive  `json:"resolvedCollection"`
+}
+
+var FilesProviderRetention = rpc.Call[FilesProviderRetentionRequest, FilesRetentionResponse]{
+	BaseContext: fileProviderNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesService,
+	Operation:   "retention",
+}
+
 var FilesProviderRetrieveProducts = rpc.Call[util.Empty, SupportByProvider[FSSupport]]{
 	BaseContext: fileProviderNamespace,
 	Convention:  rpc.ConventionRetrieve,
@@ -533,3 +653,26 @@ var FilesProviderTransfer = rpc.Call[FilesProviderTransferRequest, FilesProvider
 	Roles:       rpc.RolesService,
 	Operation:   "transfer",
 }
+
+type FilesProviderArchiveRequest struct {
+	ResolvedSourceCollection      Drive               `json:"resolvedSourceCollection"`
+	ResolvedDestinationCollection Drive               `json:"resolvedDestinationCollection"`
+	SourcePath                    string              `json:"sourcePath"`
+	DestinationPath               string              `json:"destinationPath"`
+	Format                        ArchiveFormat       `json:"format"`
+	ConflictPolicy                WriteConflictPolicy `json:"conflictPolicy"`
+}
+
+var FilesProviderCompress = rpc.Call[fnd.BulkRequest[FilesProviderArchiveRequest], fnd.BulkResponse[util.Empty]]{
+	BaseContext: fileProviderNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesService,
+	Operation:   "compress",
+}
+
+var FilesProviderExtract = rpc.Call[fnd.BulkRequest[FilesProviderArchiveRequest], fnd.BulkResponse[util.Empty]]{
+	BaseContext: fileProviderNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesService,
+	Operation:   "extract",
+}
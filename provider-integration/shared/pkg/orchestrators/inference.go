@@ -40,6 +40,7 @@ const (
 	InferenceVision         InferenceCapability = "Vision"
 	InferenceVideoVision    InferenceCapability = "VideoVision"
 	InferenceAudio          InferenceCapability = "Audio"
+	InferenceEmbedding      InferenceCapability = "Embedding"
 )
 
 type InferenceModel struct {
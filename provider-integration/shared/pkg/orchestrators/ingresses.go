@@ -15,26 +15,77 @@ type Ingress struct {
 }
 
 type IngressSupport struct {
-	Prefix  string               `json:"domainPrefix"`
-	Suffix  string               `json:"domainSuffix"`
-	Product apm.ProductReference `json:"product"`
+	Prefix        string               `json:"domainPrefix"`
+	Suffix        string               `json:"domainSuffix"`
+	CustomDomains bool                 `json:"customDomains"`
+	AccessControl bool                 `json:"accessControl"`
+	Product       apm.ProductReference `json:"product"`
 }
 
 type IngressSpecification struct {
-	Domain string `json:"domain"`
+	Domain string                           `json:"domain"`
+	Access util.Option[IngressAccessPolicy] `json:"access"`
 	ResourceSpecification
 }
 
 type IngressStatus struct {
-	BoundTo []string     `json:"boundTo"`
-	State   IngressState `json:"state"`
+	BoundTo      []string                               `json:"boundTo"`
+	State        IngressState                           `json:"state"`
+	CustomDomain util.Option[IngressCustomDomainStatus] `json:"customDomain"`
 	ResourceStatus[IngressSupport]
 }
 
 type IngressUpdate struct {
-	State     util.Option[IngressState] `json:"state,omitempty"`
-	Timestamp fnd.Timestamp             `json:"timestamp"`
-	Status    util.Option[string]       `json:"status,omitempty"`
+	State        util.Option[IngressState]              `json:"state,omitempty"`
+	Timestamp    fnd.Timestamp                          `json:"timestamp"`
+	Status       util.Option[string]                    `json:"status,omitempty"`
+	CustomDomain util.Option[IngressCustomDomainStatus] `json:"customDomain,omitempty"`
+}
+
+// IngressCustomDomainStatus is present on links which use a domain owned by the user instead of one built from the
+// provider's prefix and suffix. The user proves ownership of the domain by publishing ChallengeToken in a TXT record
+// named ChallengeRecord. Once verified, the provider obtains a certificate for the domain over ACME and keeps it
+// renewed.
+type IngressCustomDomainStatus struct {
+	ChallengeRecord      string                     `json:"challengeRecord"`
+	ChallengeToken       string                     `json:"challengeToken"`
+	Verified             bool                       `json:"verified"`
+	CertificateExpiresAt util.Option[fnd.Timestamp] `json:"certificateExpiresAt"`
+	Error                util.Option[string]        `json:"error"`
+}
+
+const IngressCustomDomainChallengePrefix = "_ucloud-challenge."
+
+// IngressAccessPolicy controls who can reach a link. The gateway of the provider enforces the policy before a request is
+// forwarded to the job. Password is only used when changing the policy and is never returned by UCloud.
+type IngressAccessPolicy struct {
+	Mode     IngressAccessMode   `json:"mode"`
+	Users    []string            `json:"users,omitempty"`
+	Password util.Option[string] `json:"password,omitempty"`
+}
+
+type IngressAccessMode string
+
+const (
+	IngressAccessModePublic   IngressAccessMode = "PUBLIC"
+	IngressAccessModeProject  IngressAccessMode = "PROJECT"
+	IngressAccessModeUsers    IngressAccessMode = "USERS"
+	IngressAccessModePassword IngressAccessMode = "PASSWORD"
+)
+
+var IngressAccessModeOptions = []IngressAccessMode{
+	IngressAccessModePublic,
+	IngressAccessModeProject,
+	IngressAccessModeUsers,
+	IngressAccessModePassword,
+}
+
+// IngressAccessModeOrDefault returns the access mode of a link. Links without a policy are public.
+func IngressAccessModeOrDefault(policy util.Option[IngressAccessPolicy]) IngressAccessMode {
+	if !policy.Present || policy.Value.Mode == "" {
+		return IngressAccessModePublic
+	}
+	return policy.Value.Mode
 }
 
 type IngressState string
@@ -130,6 +181,48 @@ var IngressesRetrieveProducts = rpc.Call[util.Empty, SupportByProvider[IngressSu
 	Operation:   "products",
 }
 
+// IngressesVerifyDomain asks the provider to check the ownership challenge of a custom domain immediately instead of
+// waiting for the next periodic check.
+var IngressesVerifyDomain = rpc.Call[fnd.BulkRequest[fnd.FindByStringId], fnd.BulkResponse[util.Empty]]{
+	BaseContext: ingressNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+	Operation:   "verifyDomain",
+}
+
+type IngressesUpdateAccessRequest struct {
+	Id     string              `json:"id"`
+	Access IngressAccessPolicy `json:"access"`
+}
+
+var IngressesUpdateAccess = rpc.Call[fnd.BulkRequest[IngressesUpdateAccessRequest], fnd.BulkResponse[util.Empty]]{
+	BaseContext: ingressNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+	Operation:   "updateAccess",
+}
+
+type IngressesAuthorizeAccessRequest struct {
+	Id string `json:"id"`
+
+	// Redirect is the path on the link which the user should be sent to once access has been granted.
+	Redirect string `json:"redirect"`
+}
+
+type IngressesAuthorizeAccessResponse struct {
+	RedirectTo string `json:"redirectTo"`
+}
+
+// IngressesAuthorizeAccess is used when a user is sent to UCloud by the gateway of a link which is restricted to
+// project members or specific users. If the user is allowed through, the provider returns a URL which establishes a
+// session with the link.
+var IngressesAuthorizeAccess = rpc.Call[IngressesAuthorizeAccessRequest, IngressesAuthorizeAccessResponse]{
+	BaseContext: ingressNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+	Operation:   "authorizeAccess",
+}
+
 // Ingress Control API
 // =====================================================================================================================
 
@@ -204,6 +297,38 @@ var IngressesProviderVerify = rpc.Call[fnd.BulkRequest[Ingress], util.Empty]{
 	Operation:   "verify",
 }
 
+var IngressesProviderVerifyDomain = rpc.Call[fnd.BulkRequest[Ingress], fnd.BulkResponse[util.Empty]]{
+	BaseContext: ingressProviderNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesPrivileged,
+	Operation:   "verifyDomain",
+}
+
+type IngressesProviderUpdateAccessRequest struct {
+	Ingress Ingress             `json:"ingress"`
+	Access  IngressAccessPolicy `json:"access"`
+}
+
+var IngressesProviderUpdateAccess = rpc.Call[fnd.BulkRequest[IngressesProviderUpdateAccessRequest], fnd.BulkResponse[util.Empty]]{
+	BaseContext: ingressProviderNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesPrivileged,
+	Operation:   "updateAccess",
+}
+
+type IngressesProviderAuthorizeAccessRequest struct {
+	Ingress  Ingress `json:"ingress"`
+	Username string  `json:"username"`
+	Redirect string  `json:"redirect"`
+}
+
+var IngressesProviderAuthorizeAccess = rpc.Call[IngressesProviderAuthorizeAccessRequest, IngressesAuthorizeAccessResponse]{
+	BaseContext: ingressProviderNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesPrivileged,
+	Operation:   "authorizeAccess",
+}
+
 var IngressesProviderRetrieveProducts = rpc.Call[util.Empty, fnd.BulkResponse[IngressSupport]]{
 	BaseContext: ingressProviderNamespace,
 	Convention:  rpc.ConventionRetrieve,
@@ -3,6 +3,9 @@ package orchestrators
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
 	"strings"
 
 	"ucloud.dk/shared/pkg/rpc"
@@ -67,6 +70,9 @@ type JobState string
 const (
 	// JobStateInQueue means the job was accepted but workload execution has not started.
 	JobStateInQueue JobState = "IN_QUEUE"
+	// JobStateWaiting means the job is held by UCloud until its dependencies are satisfied. The job has not yet
+	// been submitted to the provider.
+	JobStateWaiting JobState = "WAITING"
 	// JobStateRunning means workload execution has started.
 	JobStateRunning JobState = "RUNNING"
 	// JobStateSuccess means the workload completed with exit code 0.
@@ -113,6 +119,7 @@ type JobStatus struct {
 	ResolvedProduct     util.Option[apm.ProductV2]               `json:"resolvedProduct,omitempty"`
 	ResolvedSupport     util.Option[ResolvedSupport[JobSupport]] `json:"resolvedSupport"`
 	AllowRestart        bool                                     `json:"allowRestart"`
+	ArrayTasks          util.Option[JobArrayStatus]              `json:"arrayTasks,omitempty"`
 }
 
 type JobUpdate struct {
@@ -124,6 +131,7 @@ type JobUpdate struct {
 	NewTimeAllocation      util.Option[int64]               `json:"newTimeAllocation"`
 	AllowRestart           util.Option[bool]                `json:"allowRestart"` // deprecated
 	ResourceList           util.Option[[]AppParameterValue] `json:"resourceList"`
+	ArrayTasks             util.Option[JobArrayStatus]      `json:"arrayTasks,omitempty"`
 	Timestamp              fnd.Timestamp                    `json:"timestamp"`
 }
 
@@ -140,6 +148,144 @@ type JobSpecification struct {
 	OpenedFile        string                       `json:"openedFile,omitempty"`
 	RestartOnExit     bool                         `json:"restartOnExit,omitempty"` // deprecated
 	SshEnabled        bool                         `json:"sshEnabled,omitempty"`
+	Sweep             util.Option[JobSweep]        `json:"sweep,omitempty"`
+	Dependencies      []JobDependency              `json:"dependencies,omitempty"`
+}
+
+// JobDependencyType describes the condition which must be met by an upstream job before a dependent job can start.
+type JobDependencyType string
+
+const (
+	// JobDependencyAfterOk is satisfied when the upstream job has completed successfully. The dependent job is
+	// cancelled if the upstream job ends in any other final state.
+	JobDependencyAfterOk JobDependencyType = "AFTER_OK"
+	// JobDependencyAfterAny is satisfied when the upstream job has reached any final state.
+	JobDependencyAfterAny JobDependencyType = "AFTER_ANY"
+)
+
+var JobDependencyTypeOptions = []JobDependencyType{
+	JobDependencyAfterOk,
+	JobDependencyAfterAny,
+}
+
+type JobDependency struct {
+	JobId string            `json:"jobId"`
+	Type  JobDependencyType `json:"type"`
+}
+
+// Satisfied returns true if the dependency is satisfied by an upstream job in the given state.
+func (dep JobDependency) Satisfied(upstream JobState) bool {
+	switch dep.Type {
+	case JobDependencyAfterOk:
+		return upstream == JobStateSuccess
+	case JobDependencyAfterAny:
+		return upstream.IsFinal()
+	default:
+		return false
+	}
+}
+
+// Unsatisfiable returns true if the dependency can never be satisfied by an upstream job in the given state.
+func (dep JobDependency) Unsatisfiable(upstream JobState) bool {
+	return upstream.IsFinal() && !dep.Satisfied(upstream)
+}
+
+// JobSweep turns a job into a job array. Every task of the array runs the same specification, but with the parameters
+// of the task merged on top of JobSpecification.Parameters. Tasks are produced by taking every entry of Tasks (or a
+// single empty task if none are given) and combining it with every element of the cartesian product of Matrix.
+type JobSweep struct {
+	Tasks          []map[string]AppParameterValue `json:"tasks,omitempty"`
+	Matrix         map[string][]AppParameterValue `json:"matrix,omitempty"`
+	MaxConcurrency int                            `json:"maxConcurrency,omitempty"`
+}
+
+// Size returns the number of tasks produced by the sweep without expanding it. The result saturates at math.MaxInt
+// such that very large matrices can be rejected cheaply.
+func (sweep *JobSweep) Size() int {
+	size := max(1, len(sweep.Tasks))
+	for _, values := range sweep.Matrix {
+		count := len(values)
+		if count == 0 {
+			return 0
+		} else if size > math.MaxInt/count {
+			return math.MaxInt
+		}
+		size *= count
+	}
+	return size
+}
+
+// Expand returns the parameters of every task in the sweep. The order is deterministic: tasks are enumerated in the
+// order they were given and the matrix is enumerated with the parameter names in sorted order, with the last name
+// varying the fastest.
+func (sweep *JobSweep) Expand() []map[string]AppParameterValue {
+	tasks := sweep.Tasks
+	if len(tasks) == 0 {
+		tasks = []map[string]AppParameterValue{{}}
+	}
+
+	var names []string
+	for name := range sweep.Matrix {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var result []map[string]AppParameterValue
+	for _, task := range tasks {
+		combinations := []map[string]AppParameterValue{maps.Clone(task)}
+		for _, name := range names {
+			var next []map[string]AppParameterValue
+			for _, combination := range combinations {
+				for _, value := range sweep.Matrix[name] {
+					entry := maps.Clone(combination)
+					if entry == nil {
+						entry = map[string]AppParameterValue{}
+					}
+					entry[name] = value
+					next = append(next, entry)
+				}
+			}
+			combinations = next
+		}
+		result = append(result, combinations...)
+	}
+	return result
+}
+
+// ArrayTaskParameters returns the complete set of parameters used by every task of a job array, in the order given by
+// JobSweep.Expand. Jobs without a sweep are not arrays and return nil.
+func (spec *JobSpecification) ArrayTaskParameters() []map[string]AppParameterValue {
+	if !spec.Sweep.Present {
+		return nil
+	}
+
+	tasks := spec.Sweep.Value.Expand()
+	for i, task := range tasks {
+		merged := maps.Clone(spec.Parameters)
+		if merged == nil {
+			merged = map[string]AppParameterValue{}
+		}
+		maps.Copy(merged, task)
+		tasks[i] = merged
+	}
+	return tasks
+}
+
+// ArrayTaskCount returns the number of tasks in the job array. Jobs without a sweep are not arrays and return 0.
+func (spec *JobSpecification) ArrayTaskCount() int {
+	if !spec.Sweep.Present {
+		return 0
+	}
+	return spec.Sweep.Value.Size()
+}
+
+// JobArrayStatus contains the aggregated state of all tasks in a job array.
+type JobArrayStatus struct {
+	Total   int `json:"total"`
+	InQueue int `json:"inQueue"`
+	Running int `json:"running"`
+	Success int `json:"success"`
+	Failure int `json:"failure"`
 }
 
 type ComputeProductReference apm.ProductReference
@@ -393,13 +539,18 @@ type JobSupport struct {
 	Product apm.ProductReference `json:"product"`
 	Docker  struct {
 		UniversalBackendSupport
+		Arrays bool `json:"arrays,omitempty"`
 	} `json:"docker"`
 	VirtualMachine struct {
 		UniversalBackendSupport
 		Suspension bool `json:"suspension,omitempty"`
+		Snapshots  bool `json:"snapshots,omitempty"`
 	} `json:"virtualMachine"`
 	Native struct {
 		UniversalBackendSupport
+		Suspension   bool `json:"suspension,omitempty"`
+		Arrays       bool `json:"arrays,omitempty"`
+		Dependencies bool `json:"dependencies,omitempty"`
 	} `json:"native"`
 	QueueStatus util.Option[JobQueueStatus] `json:"queueStatus"`
 }
@@ -479,6 +630,73 @@ var JobsUnsuspend = rpc.Call[fnd.BulkRequest[fnd.FindByStringId], fnd.BulkRespon
 	Operation:   "unsuspend",
 }
 
+type JobSnapshotState string
+
+const (
+	JobSnapshotStatePending JobSnapshotState = "PENDING"
+	JobSnapshotStateReady   JobSnapshotState = "READY"
+	JobSnapshotStateFailed  JobSnapshotState = "FAILED"
+)
+
+// JobSnapshot is a point-in-time copy of the disks of a virtual machine. Snapshots are charged against the storage
+// wallet of the job owner for as long as they exist.
+type JobSnapshot struct {
+	Id        string              `json:"id"`
+	JobId     string              `json:"jobId"`
+	Title     string              `json:"title"`
+	CreatedAt fnd.Timestamp       `json:"createdAt"`
+	State     JobSnapshotState    `json:"state"`
+	SizeInGb  int64               `json:"sizeInGb"`
+	Error     util.Option[string] `json:"error"`
+}
+
+type JobsCreateSnapshotRequestItem struct {
+	JobId string `json:"jobId"`
+	Title string `json:"title"`
+}
+
+var JobsCreateSnapshot = rpc.Call[fnd.BulkRequest[JobsCreateSnapshotRequestItem], fnd.BulkResponse[fnd.FindByStringId]]{
+	BaseContext: jobNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+	Operation:   "createSnapshot",
+}
+
+type JobsBrowseSnapshotsRequest struct {
+	JobId string `json:"jobId"`
+}
+
+type JobsBrowseSnapshotsResponse struct {
+	Snapshots []JobSnapshot `json:"snapshots"`
+}
+
+var JobsBrowseSnapshots = rpc.Call[JobsBrowseSnapshotsRequest, JobsBrowseSnapshotsResponse]{
+	BaseContext: jobNamespace,
+	Convention:  rpc.ConventionRetrieve,
+	Roles:       rpc.RolesEndUser,
+	Operation:   "snapshots",
+}
+
+type JobsSnapshotRequestItem struct {
+	JobId      string `json:"jobId"`
+	SnapshotId string `json:"snapshotId"`
+}
+
+// JobsRestoreSnapshot replaces the disks of a job with the content of a snapshot. The job must be suspended.
+var JobsRestoreSnapshot = rpc.Call[fnd.BulkRequest[JobsSnapshotRequestItem], fnd.BulkResponse[util.Empty]]{
+	BaseContext: jobNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+	Operation:   "restoreSnapshot",
+}
+
+var JobsDeleteSnapshot = rpc.Call[fnd.BulkRequest[JobsSnapshotRequestItem], fnd.BulkResponse[util.Empty]]{
+	BaseContext: jobNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesEndUser,
+	Operation:   "deleteSnapshot",
+}
+
 type JobsOpenInteractiveSessionRequestItem struct {
 	Id          string                 `json:"id"`
 	Rank        int                    `json:"rank"`
@@ -787,6 +1005,48 @@ var JobsProviderUnsuspend = rpc.Call[fnd.BulkRequest[JobsProviderUnsuspendReques
 	Operation:   "unsuspend",
 }
 
+type JobsProviderCreateSnapshotRequestItem struct {
+	Job   Job    `json:"job"`
+	Title string `json:"title"`
+}
+
+var JobsProviderCreateSnapshot = rpc.Call[fnd.BulkRequest[JobsProviderCreateSnapshotRequestItem], fnd.BulkResponse[fnd.FindByStringId]]{
+	BaseContext: jobProviderNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesPrivileged,
+	Operation:   "createSnapshot",
+}
+
+type JobsProviderBrowseSnapshotsRequest struct {
+	Job Job `json:"job"`
+}
+
+var JobsProviderBrowseSnapshots = rpc.Call[JobsProviderBrowseSnapshotsRequest, JobsBrowseSnapshotsResponse]{
+	BaseContext: jobProviderNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesPrivileged,
+	Operation:   "browseSnapshots",
+}
+
+type JobsProviderSnapshotRequestItem struct {
+	Job        Job    `json:"job"`
+	SnapshotId string `json:"snapshotId"`
+}
+
+var JobsProviderRestoreSnapshot = rpc.Call[fnd.BulkRequest[JobsProviderSnapshotRequestItem], fnd.BulkResponse[util.Empty]]{
+	BaseContext: jobProviderNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesPrivileged,
+	Operation:   "restoreSnapshot",
+}
+
+var JobsProviderDeleteSnapshot = rpc.Call[fnd.BulkRequest[JobsProviderSnapshotRequestItem], fnd.BulkResponse[util.Empty]]{
+	BaseContext: jobProviderNamespace,
+	Convention:  rpc.ConventionUpdate,
+	Roles:       rpc.RolesPrivileged,
+	Operation:   "deleteSnapshot",
+}
+
 type JobsProviderOpenInteractiveSessionRequestItem struct {
 	Job         Job                    `json:"job"`
 	Rank        int                    `json:"rank"`
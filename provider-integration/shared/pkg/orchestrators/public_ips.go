@@ -25,6 +25,28 @@ type PortRangeAndProto struct {
 	Start    int        `json:"start"`
 	End      int        `json:"end"`
 	Protocol IpProtocol `json:"protocol"`
+
+	// AllowedCidrs restricts the rule to a set of networks (IPv4 or IPv6). For ingress rules these are the sources which
+	// are allowed to connect, for egress rules these are the destinations which can be reached. An empty list allows
+	// all addresses.
+	AllowedCidrs []string          `json:"allowedCidrs,omitempty"`
+	Direction    FirewallDirection `json:"direction,omitempty"`
+}
+
+func (p PortRangeAndProto) IsEgress() bool {
+	return p.Direction == FirewallDirectionEgress
+}
+
+type FirewallDirection string
+
+const (
+	FirewallDirectionIngress FirewallDirection = "INGRESS"
+	FirewallDirectionEgress  FirewallDirection = "EGRESS"
+)
+
+var FirewallDirectionOptions = []FirewallDirection{
+	FirewallDirectionIngress,
+	FirewallDirectionEgress,
 }
 
 type IpProtocol string
@@ -88,6 +110,9 @@ type PublicIpSupport struct {
 
 type FirewallSupport struct {
 	Enabled bool `json:"enabled"`
+
+	// SourceRestrictions indicates that the provider supports rules with AllowedCidrs and egress rules
+	SourceRestrictions bool `json:"sourceRestrictions"`
 }
 
 type PublicIpFlags struct {
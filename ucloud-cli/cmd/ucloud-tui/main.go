@@ -1,14 +1,16 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	cli "ucloud.dk/ucloud_cli/pkg/ucloud_cli"
 )
 
 func main() {
-	err := cli.ExecuteCommand(os.Args[1:]...)
+	err := cli.ExecuteCommand(os.Args...)
 	if err != nil {
-		panic(err)
+		_, _ = fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
 	}
 }
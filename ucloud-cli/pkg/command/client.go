@@ -0,0 +1,141 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ucloud.dk/shared/pkg/rpc"
+)
+
+// CliConfig is the configuration written by the connect command. Every field can be overridden through the
+// environment, which is useful for scripted workflows.
+type CliConfig struct {
+	Server       string `json:"server"`
+	RefreshToken string `json:"refreshToken"`
+	Project      string `json:"project,omitempty"`
+}
+
+const (
+	envServer       = "UCLOUD_SERVER"
+	envRefreshToken = "UCLOUD_REFRESH_TOKEN"
+	envProject      = "UCLOUD_PROJECT"
+)
+
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine configuration directory: %w", err)
+	}
+	return filepath.Join(dir, "ucloud", "cli.json"), nil
+}
+
+func readConfig() (CliConfig, error) {
+	var result CliConfig
+
+	path, err := configPath()
+	if err != nil {
+		return result, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return result, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &result); err != nil {
+			return result, fmt.Errorf("could not parse %s: %w", path, err)
+		}
+	}
+
+	if v := os.Getenv(envServer); v != "" {
+		result.Server = v
+	}
+	if v := os.Getenv(envRefreshToken); v != "" {
+		result.RefreshToken = v
+	}
+	if v := os.Getenv(envProject); v != "" {
+		result.Project = v
+	}
+
+	result.Server = strings.TrimSuffix(result.Server, "/")
+	return result, nil
+}
+
+func writeConfig(config CliConfig) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("could not create %s: %w", filepath.Dir(path), err)
+	}
+
+	data, _ := json.MarshalIndent(config, "", "  ")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	return nil
+}
+
+// session bundles an authenticated client with the options which must be passed to every call.
+type session struct {
+	Config CliConfig
+	Client *rpc.Client
+	Opts   rpc.InvokeOpts
+}
+
+func connectSession(config CliConfig) (*session, error) {
+	if config.Server == "" || config.RefreshToken == "" {
+		return nil, fmt.Errorf("not connected to UCloud: run 'ucloud connect --server <url> --token <refresh-token>' "+
+			"or set %s and %s", envServer, envRefreshToken)
+	}
+
+	client := &rpc.Client{
+		BasePath:     config.Server,
+		RefreshToken: config.RefreshToken,
+		Client:       http.DefaultClient,
+	}
+
+	// The client refreshes the access token lazily. We request one up front such that a bad token results in a
+	// useful error message instead of a failed call.
+	rpc.ClientAllowSilentAuthTokenRenewalErrors.Store(true)
+	if client.RetrieveAccessTokenOrRefresh() == "" {
+		return nil, fmt.Errorf("could not authenticate with %s: the refresh token was rejected", config.Server)
+	}
+
+	opts := rpc.InvokeOpts{}
+	if config.Project != "" {
+		opts.Headers = http.Header{}
+		opts.Headers.Set("Project", config.Project)
+	}
+
+	return &session{Config: config, Client: client, Opts: opts}, nil
+}
+
+func newSession() (*session, error) {
+	config, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+	return connectSession(config)
+}
+
+func invoke[Req any, Resp any](s *session, call rpc.Call[Req, Resp], request Req) (Resp, error) {
+	resp, herr := call.InvokeEx(s.Client, request, s.Opts)
+	return resp, herr.AsError()
+}
+
+func printJson(value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(data))
+	return err
+}
@@ -1,10 +1,14 @@
 package command
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type ConnectCommand struct {
-	Token  string `flag:"token" usage:"Token"`
-	Server string `flag:"server" usage:"Server"`
+	Token   string `flag:"token" usage:"Refresh token"`
+	Server  string `flag:"server" usage:"Server"`
+	Project string `flag:"project" usage:"Project ID used for all commands"`
 }
 
 var ConnectCommands = map[string]CommandFunc{
@@ -12,5 +16,29 @@ var ConnectCommands = map[string]CommandFunc{
 }
 
 func (c *ConnectCommand) Execute() error {
-	return fmt.Errorf("connect not implemented")
+	config, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	if c.Server != "" {
+		config.Server = strings.TrimSuffix(c.Server, "/")
+	}
+	if c.Token != "" {
+		config.RefreshToken = c.Token
+	}
+	if c.Project != "" {
+		config.Project = c.Project
+	}
+
+	if _, err := connectSession(config); err != nil {
+		return err
+	}
+
+	if err := writeConfig(config); err != nil {
+		return err
+	}
+
+	fmt.Printf("Connected to %s\n", config.Server)
+	return nil
 }
@@ -1,22 +1,41 @@
 package command
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	acc "ucloud.dk/shared/pkg/accounting"
+	fnd "ucloud.dk/shared/pkg/foundation"
+	orcapi "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/termio"
+	"ucloud.dk/shared/pkg/util"
 )
 
 type JobGetCommand struct {
 	JobID string `positional:"job-id" usage:"Job ID"`
+	JSON  bool   `flag:"json" usage:"Print the job as JSON"`
 }
 
 type JobCreateCommand struct {
-	Application string            `flag:"app" usage:"Application name"`
-	Product     string            `flag:"prod" usage:"Product name"`
+	Spec        string            `flag:"spec" usage:"JSON file containing a job specification (- for stdin)"`
+	Application string            `flag:"app" usage:"Application name, optionally with @version"`
+	Product     string            `flag:"prod" usage:"Product name, optionally with @provider"`
 	Name        string            `flag:"name" usage:"Job name"`
 	Time        int               `flag:"time" usage:"Time in minutes"`
 	SSH         bool              `flag:"ssh" usage:"Use SSH"`
 	Folder      string            `flag:"folder" usage:"Folder name"`
 	PublicLink  string            `flag:"public-link" usage:"Public link"`
 	Parameters  map[string]string `flag:"param" usage:"eg. image=ubuntu"`
+	Replicas    int               `flag:"replicas" usage:"Number of nodes"`
 }
 
 type JobDeleteCommand struct {
@@ -30,6 +49,7 @@ type JobRenameCommand struct {
 
 type JobSearchCommand struct {
 	JobName string `positional:"job-name" usage:"Job name"`
+	JSON    bool   `flag:"json" usage:"Print the jobs as JSON"`
 }
 
 type JobExtendCommand struct {
@@ -103,66 +123,574 @@ var JobCommands = map[string]CommandFunc{
 	"logs":  func() Command { return &JobLogsCommand{} },
 }
 
+var jobFlags = orcapi.JobFlags{
+	ResourceFlags:      orcapi.ResourceFlags{IncludeProduct: true},
+	IncludeParameters:  true,
+	IncludeApplication: true,
+}
+
 func (c JobRenameCommand) Execute() error {
-	return fmt.Errorf("job rename not implemented")
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	_, err = invoke(s, orcapi.JobsRename, fnd.BulkRequestOf(orcapi.JobRenameRequest{Id: c.JobID, NewTitle: c.NewName}))
+	return err
 }
 
 func (c JobSearchCommand) Execute() error {
-	return fmt.Errorf("job search not implemented")
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	var jobs []orcapi.Job
+	next := util.OptNone[string]()
+	for {
+		var page fnd.PageV2[orcapi.Job]
+		if c.JobName == "" {
+			page, err = invoke(s, orcapi.JobsBrowse, orcapi.JobsBrowseRequest{
+				ItemsPerPage: 250,
+				Next:         next,
+				JobFlags:     jobFlags,
+			})
+		} else {
+			page, err = invoke(s, orcapi.JobsSearch, orcapi.JobsSearchRequest{
+				ItemsPerPage: 250,
+				Next:         next,
+				Query:        c.JobName,
+				JobFlags:     jobFlags,
+			})
+		}
+
+		if err != nil {
+			return err
+		}
+
+		jobs = append(jobs, page.Items...)
+		next = page.Next
+		if !next.Present {
+			break
+		}
+	}
+
+	if c.JSON {
+		return printJson(util.NonNilSlice(jobs))
+	}
+
+	printJobTable(jobs)
+	return nil
 }
 
 func (c JobSuspendCommand) Execute() error {
-	return fmt.Errorf("job suspend not implemented")
+	return jobBulkAction(orcapi.JobsSuspend, c.JobID)
 }
 
 func (c JobExtendCommand) Execute() error {
-	return fmt.Errorf("job extend not implemented")
+	if c.Time <= 0 {
+		return fmt.Errorf("--time must be a positive number of minutes")
+	}
+
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	_, err = invoke(s, orcapi.JobsExtend, fnd.BulkRequestOf(orcapi.JobsExtendRequestItem{
+		JobId:         c.JobID,
+		RequestedTime: minutesToDuration(c.Time),
+	}))
+	return err
 }
 
 func (c JobGetCommand) Execute() error {
-	return fmt.Errorf("job get not implemented")
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	job, err := invoke(s, orcapi.JobsRetrieve, orcapi.JobsRetrieveRequest{Id: c.JobID, JobFlags: jobFlags})
+	if err != nil {
+		return err
+	}
+
+	if c.JSON {
+		return printJson(job)
+	}
+
+	printJobTable([]orcapi.Job{job})
+	return nil
 }
 
 func (c JobCreateCommand) Execute() error {
-	return fmt.Errorf("job create not implemented")
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	spec, err := c.specification(s)
+	if err != nil {
+		return err
+	}
+
+	resp, err := invoke(s, orcapi.JobsCreate, fnd.BulkRequestOf(spec))
+	if err != nil {
+		return err
+	}
+
+	for _, id := range resp.Responses {
+		fmt.Println(id.Id)
+	}
+	return nil
+}
+
+// specification builds the job specification from the spec file, if any, and then applies the flags on top of it.
+func (c JobCreateCommand) specification(s *session) (orcapi.JobSpecification, error) {
+	var spec orcapi.JobSpecification
+
+	if c.Spec != "" {
+		var data []byte
+		var err error
+		if c.Spec == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(c.Spec)
+		}
+
+		if err != nil {
+			return spec, fmt.Errorf("could not read job specification: %w", err)
+		}
+
+		if err := json.Unmarshal(data, &spec); err != nil {
+			return spec, fmt.Errorf("could not parse job specification: %w", err)
+		}
+	}
+
+	if c.Application != "" {
+		name, version, _ := strings.Cut(c.Application, "@")
+		spec.Application = orcapi.NameAndVersion{Name: name, Version: version}
+	}
+
+	if spec.Application.Name == "" {
+		return spec, fmt.Errorf("no application specified: use --app or --spec")
+	}
+
+	if spec.Application.Version == "" || len(c.Parameters) > 0 {
+		version := util.OptNone[string]()
+		if spec.Application.Version != "" {
+			version.Set(spec.Application.Version)
+		}
+
+		app, err := invoke(s, orcapi.AppsFindByNameAndVersion, orcapi.AppCatalogFindByNameAndVersionRequest{
+			AppName:    spec.Application.Name,
+			AppVersion: version,
+		})
+		if err != nil {
+			return spec, fmt.Errorf("could not find application %s: %w", spec.Application.Name, err)
+		}
+
+		spec.Application = app.Metadata.NameAndVersion
+
+		if spec.Parameters == nil {
+			spec.Parameters = map[string]orcapi.AppParameterValue{}
+		}
+
+		for name, value := range c.Parameters {
+			var param util.Option[orcapi.ApplicationParameter]
+			for _, p := range app.Invocation.Parameters {
+				if p.Name == name {
+					param.Set(p)
+				}
+			}
+
+			if !param.Present {
+				return spec, fmt.Errorf("application %s has no parameter named %q", spec.Application.Name, name)
+			}
+
+			parsed, err := parseParameterValue(param.Value, value)
+			if err != nil {
+				return spec, err
+			}
+			spec.Parameters[name] = parsed
+		}
+	}
+
+	if c.Product != "" {
+		product, err := resolveComputeProduct(s, c.Product)
+		if err != nil {
+			return spec, err
+		}
+		spec.Product = product
+	}
+
+	if spec.Product.Id == "" {
+		return spec, fmt.Errorf("no product specified: use --prod or --spec")
+	}
+
+	if c.Name != "" {
+		spec.Name = c.Name
+	}
+
+	if c.Time > 0 {
+		spec.TimeAllocation.Set(minutesToDuration(c.Time))
+	}
+
+	if c.SSH {
+		spec.SshEnabled = true
+	}
+
+	if c.Folder != "" {
+		spec.Resources = append(spec.Resources, orcapi.AppParameterValueFile(c.Folder, false))
+	}
+
+	if c.PublicLink != "" {
+		spec.Resources = append(spec.Resources, orcapi.AppParameterValueIngress(c.PublicLink))
+	}
+
+	if c.Replicas > 0 {
+		spec.Replicas = c.Replicas
+	}
+
+	if spec.Replicas == 0 {
+		spec.Replicas = 1
+	}
+
+	return spec, nil
+}
+
+// parseParameterValue converts a value given on the command-line to the value expected by the parameter.
+func parseParameterValue(param orcapi.ApplicationParameter, value string) (orcapi.AppParameterValue, error) {
+	switch param.Type {
+	case orcapi.ApplicationParameterTypeInputFile, orcapi.ApplicationParameterTypeInputDirectory:
+		return orcapi.AppParameterValueFile(value, false), nil
+
+	case orcapi.ApplicationParameterTypeText, orcapi.ApplicationParameterTypeTextArea,
+		orcapi.ApplicationParameterTypeEnumeration:
+		return orcapi.AppParameterValueText(value), nil
+
+	case orcapi.ApplicationParameterTypeInteger:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return orcapi.AppParameterValue{}, fmt.Errorf("parameter %s must be an integer", param.Name)
+		}
+		return orcapi.AppParameterValueInteger(parsed), nil
+
+	case orcapi.ApplicationParameterTypeFloatingPoint:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return orcapi.AppParameterValue{}, fmt.Errorf("parameter %s must be a number", param.Name)
+		}
+		return orcapi.AppParameterValueFloatingPoint(parsed), nil
+
+	case orcapi.ApplicationParameterTypeBoolean:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return orcapi.AppParameterValue{}, fmt.Errorf("parameter %s must be true or false", param.Name)
+		}
+		return orcapi.AppParameterValueBoolean(parsed), nil
+
+	case orcapi.ApplicationParameterTypePeer:
+		hostname, jobId, ok := strings.Cut(value, ":")
+		if !ok {
+			return orcapi.AppParameterValue{}, fmt.Errorf("parameter %s must be of the form <hostname>:<job-id>", param.Name)
+		}
+		return orcapi.AppParameterValuePeer(hostname, jobId), nil
+
+	case orcapi.ApplicationParameterTypeLicenseServer:
+		return orcapi.AppParameterValueLicense(value), nil
+
+	case orcapi.ApplicationParameterTypeIngress:
+		return orcapi.AppParameterValueIngress(value), nil
+
+	case orcapi.ApplicationParameterTypeNetworkIp:
+		return orcapi.AppParameterValueNetwork(value), nil
+
+	case orcapi.ApplicationParameterTypePrivateNetwork:
+		return orcapi.AppParameterValuePrivateNetwork(value), nil
+
+	case orcapi.ApplicationParameterTypeModuleList:
+		return orcapi.AppParameterValueModuleList(strings.Split(value, ",")), nil
+
+	default:
+		return orcapi.AppParameterValue{}, fmt.Errorf("parameter %s of type %s cannot be set from the command-line, "+
+			"use --spec instead", param.Name, param.Type)
+	}
+}
+
+// resolveComputeProduct finds a compute product by name. The name can be suffixed with @provider to pick between
+// products of the same name.
+func resolveComputeProduct(s *session, product string) (acc.ProductReference, error) {
+	name, provider, _ := strings.Cut(product, "@")
+
+	support, err := invoke(s, orcapi.JobsRetrieveProducts, util.Empty{})
+	if err != nil {
+		return acc.ProductReference{}, err
+	}
+
+	var candidates []acc.ProductReference
+	for providerId, products := range support.ProductsByProvider {
+		if provider != "" && providerId != provider {
+			continue
+		}
+
+		for _, p := range products {
+			if p.Product.Name == name {
+				candidates = append(candidates, p.Product.ToReference())
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return acc.ProductReference{}, fmt.Errorf("unknown product: %s", product)
+	case 1:
+		return candidates[0], nil
+	default:
+		return acc.ProductReference{}, fmt.Errorf("product %s is ambiguous, use %s@<provider>", product, name)
+	}
+}
+
+func minutesToDuration(minutes int) orcapi.SimpleDuration {
+	return orcapi.SimpleDuration{Hours: minutes / 60, Minutes: minutes % 60}
+}
+
+func printJobTable(jobs []orcapi.Job) {
+	t := termio.Table{}
+	t.AppendHeader("ID")
+	t.AppendHeader("Name")
+	t.AppendHeader("Application")
+	t.AppendHeader("Product")
+	t.AppendHeader("State")
+	t.AppendHeader("Created")
+	t.AppendHeader("Expires")
+
+	for _, job := range jobs {
+		expires := "-"
+		if job.Status.ExpiresAt.Present {
+			expires = job.Status.ExpiresAt.Value.Time().Local().Format(time.DateTime)
+		}
+
+		t.Cell("%s", job.Id)
+		t.Cell("%s", job.Specification.Name)
+		t.Cell("%s@%s", job.Specification.Application.Name, job.Specification.Application.Version)
+		t.Cell("%s@%s", job.Specification.Product.Id, job.Specification.Product.Provider)
+		t.Cell("%s", job.Status.State)
+		t.Cell("%s", job.CreatedAt.Time().Local().Format(time.DateTime))
+		t.Cell("%s", expires)
+	}
+
+	t.Print()
+}
+
+// jobBulkAction invokes one of the job calls which take a list of job IDs.
+func jobBulkAction(call rpc.Call[fnd.BulkRequest[fnd.FindByStringId], fnd.BulkResponse[util.Empty]], jobId string) error {
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	_, err = invoke(s, call, fnd.BulkRequestOf(fnd.FindByStringId{Id: jobId}))
+	return err
 }
 
 func (c JobDeleteCommand) Execute() error {
-	return fmt.Errorf("job delete not implemented")
+	// Jobs cannot be removed from UCloud. Deleting a job stops it and leaves it in the job history.
+	return jobBulkAction(orcapi.JobsTerminate, c.JobID)
 }
 
 func (c JobTerminateCommand) Execute() error {
-	return fmt.Errorf("job terminate not implemented")
+	return jobBulkAction(orcapi.JobsTerminate, c.JobID)
 }
 
 func (c JobResumeCommand) Execute() error {
-	return fmt.Errorf("job resume not implemented")
+	return jobBulkAction(orcapi.JobsUnsuspend, c.JobID)
 }
 
 func (c JobAttachCommand) Execute() error {
-	return fmt.Errorf("job attach not implemented")
+	resources, err := jobResources(c.PublicIp, c.PublicLink, c.PrivateNetwork)
+	if err != nil {
+		return err
+	}
+
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resources {
+		_, err = invoke(s, orcapi.JobsAttachResource, orcapi.JobsAttachResourceRequest{JobId: c.JobID, Resource: resource})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (c JobDetachCommand) Execute() error {
-	return fmt.Errorf("job detach not implemented")
+	resources, err := jobResources(c.PublicIp, "", "")
+	if err != nil {
+		return err
+	}
+
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resources {
+		_, err = invoke(s, orcapi.JobsDetachResource, orcapi.JobsDetachResourceRequest{JobId: c.JobID, Resource: resource})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jobResources(publicIp string, publicLink string, privateNetwork string) ([]orcapi.AppParameterValue, error) {
+	var resources []orcapi.AppParameterValue
+	if publicIp != "" {
+		resources = append(resources, orcapi.AppParameterValueNetwork(publicIp))
+	}
+	if publicLink != "" {
+		resources = append(resources, orcapi.AppParameterValueIngress(publicLink))
+	}
+	if privateNetwork != "" {
+		resources = append(resources, orcapi.AppParameterValuePrivateNetwork(privateNetwork))
+	}
+
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("no resource specified")
+	}
+	return resources, nil
 }
 
 func (c JobVNCCommand) Execute() error {
-	return fmt.Errorf("job vnc not implemented")
-}
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
 
-func (c JobOpenCommand) Execute() error {
-	return fmt.Errorf("job open not implemented")
+	session, err := openInteractiveSession(s, c.JobID, 0, orcapi.InteractiveSessionTypeVnc)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("URL:      %s\n", session.Session.Url)
+	fmt.Printf("Password: %s\n", session.Session.Password)
+	return nil
 }
 
 func (c JobWebCommand) Execute() error {
-	return fmt.Errorf("job web not implemented")
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	session, err := openInteractiveSession(s, c.JobID, 0, orcapi.InteractiveSessionTypeWeb)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(session.Session.RedirectClientTo)
+	return nil
+}
+
+func (c JobOpenCommand) Execute() error {
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	job, err := invoke(s, orcapi.JobsRetrieve, orcapi.JobsRetrieveRequest{Id: c.JobID, JobFlags: jobFlags})
+	if err != nil {
+		return err
+	}
+
+	var url string
+	appType := job.Status.ResolvedApplication.Value.Invocation.ApplicationType
+	switch appType {
+	case orcapi.ApplicationTypeWeb:
+		session, err := openInteractiveSession(s, c.JobID, 0, orcapi.InteractiveSessionTypeWeb)
+		if err != nil {
+			return err
+		}
+		url = session.Session.RedirectClientTo
+
+	case orcapi.ApplicationTypeVnc:
+		url = fmt.Sprintf("%s/app/applications/vnc/%s/0", s.Config.Server, c.JobID)
+
+	default:
+		return fmt.Errorf("job %s does not have an interface which can be opened, use 'job shell' instead", c.JobID)
+	}
+
+	if err := openBrowser(url); err != nil {
+		fmt.Println(url)
+	}
+	return nil
+}
+
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+func openInteractiveSession(s *session, jobId string, rank int, sessionType orcapi.InteractiveSessionType) (orcapi.OpenSessionWithProvider, error) {
+	resp, err := invoke(s, orcapi.JobsOpenInteractiveSession, fnd.BulkRequestOf(orcapi.JobsOpenInteractiveSessionRequestItem{
+		Id:          jobId,
+		Rank:        rank,
+		SessionType: sessionType,
+	}))
+
+	if err != nil {
+		return orcapi.OpenSessionWithProvider{}, err
+	}
+
+	if len(resp.Responses) != 1 {
+		return orcapi.OpenSessionWithProvider{}, fmt.Errorf("the provider did not open a session")
+	}
+	return resp.Responses[0], nil
 }
 
 func (c JobShellCommand) Execute() error {
-	return fmt.Errorf("job shell not implemented")
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	session, err := openInteractiveSession(s, c.JobID, c.Rank, orcapi.InteractiveSessionTypeShell)
+	if err != nil {
+		return err
+	}
+
+	return runShell(session)
 }
 
 func (c JobLogsCommand) Execute() error {
-	return fmt.Errorf("job logs not implemented")
+	s, err := newSession()
+	if err != nil {
+		return err
+	}
+
+	stdout := bufio.NewWriter(os.Stdout)
+	defer stdout.Flush()
+
+	return followJob(s, c.JobID, func(message orcapi.JobsFollowMessage) {
+		for _, entry := range message.Log {
+			if entry.Stdout.Present {
+				_, _ = stdout.WriteString(entry.Stdout.Value)
+			}
+			if entry.Stderr.Present {
+				_ = stdout.Flush()
+				_, _ = os.Stderr.WriteString(entry.Stderr.Value)
+			}
+		}
+		_ = stdout.Flush()
+	})
 }
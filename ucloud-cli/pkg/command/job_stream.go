@@ -0,0 +1,211 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	ws "github.com/gorilla/websocket"
+	"golang.org/x/term"
+	fnd "ucloud.dk/shared/pkg/foundation"
+	orcapi "ucloud.dk/shared/pkg/orchestrators"
+	"ucloud.dk/shared/pkg/rpc"
+	"ucloud.dk/shared/pkg/util"
+)
+
+// followGracePeriod is how long we keep reading logs after the job has reached a final state. The follow stream is
+// never closed by UCloud, and the last log messages can arrive after the final state.
+const followGracePeriod = 2 * time.Second
+
+func toWebSocketUrl(base string) string {
+	base = strings.TrimSuffix(base, "/")
+	if strings.HasPrefix(base, "https://") {
+		return "wss://" + strings.TrimPrefix(base, "https://")
+	} else if strings.HasPrefix(base, "http://") {
+		return "ws://" + strings.TrimPrefix(base, "http://")
+	} else {
+		return base
+	}
+}
+
+// followJob streams updates about a job through the jobs.follow websocket. It returns once the job has reached a
+// final state, or when the connection is closed.
+func followJob(s *session, jobId string, handler func(message orcapi.JobsFollowMessage)) error {
+	conn, _, err := ws.DefaultDialer.Dial(toWebSocketUrl(s.Config.Server)+orcapi.JobsFollowEndpoint, nil)
+	if err != nil {
+		return fmt.Errorf("could not connect to %s: %w", s.Config.Server, err)
+	}
+	defer util.SilentClose(conn)
+
+	project := util.OptNone[string]()
+	if s.Config.Project != "" {
+		project.Set(s.Config.Project)
+	}
+
+	err = conn.WriteJSON(rpc.WSRequestMessage[fnd.FindByStringId]{
+		Call:     "jobs.follow",
+		StreamId: "0",
+		Payload:  fnd.FindByStringId{Id: jobId},
+		Bearer:   s.Client.RetrieveAccessTokenOrRefresh(),
+		Project:  project,
+	})
+	if err != nil {
+		return err
+	}
+
+	receivedAnything := false
+	finished := false
+	for {
+		var message rpc.WSResponseMessage[orcapi.JobsFollowMessage]
+		if err := conn.ReadJSON(&message); err != nil {
+			var netErr net.Error
+			if finished && errors.As(err, &netErr) && netErr.Timeout() {
+				return nil
+			} else if !receivedAnything {
+				return fmt.Errorf("job %s not found or permission denied", jobId)
+			} else if ws.IsCloseError(err, ws.CloseNormalClosure, ws.CloseGoingAway) {
+				return nil
+			} else {
+				return err
+			}
+		}
+
+		receivedAnything = true
+		handler(message.Payload)
+
+		payload := message.Payload
+		if !finished {
+			if (payload.NewStatus.Present && payload.NewStatus.Value.State.IsFinal()) ||
+				(payload.InitialJob.Present && payload.InitialJob.Value.Status.State.IsFinal()) {
+				finished = true
+				_ = conn.SetReadDeadline(time.Now().Add(followGracePeriod))
+			}
+		}
+	}
+}
+
+type shellRequest struct {
+	Type              string `json:"type"`
+	SessionIdentifier string `json:"sessionIdentifier,omitempty"`
+	Cols              int    `json:"cols,omitempty"`
+	Rows              int    `json:"rows,omitempty"`
+	Data              string `json:"data,omitempty"`
+}
+
+type shellResponse struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+// runShell connects the terminal to a shell session opened by the provider. The terminal is put in raw mode for the
+// duration of the session, if it is a terminal.
+func runShell(session orcapi.OpenSessionWithProvider) error {
+	if session.Session.Type != orcapi.OpenSessionTypeShell {
+		return fmt.Errorf("the provider did not open a shell session")
+	}
+
+	endpoint := fmt.Sprintf("%s/ucloud/%s/websocket?session=%s", toWebSocketUrl(session.ProviderDomain),
+		session.ProviderId, url.QueryEscape(session.Session.SessionIdentifier))
+
+	conn, _, err := ws.DefaultDialer.Dial(endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("could not connect to the shell: %w", err)
+	}
+	defer util.SilentClose(conn)
+
+	writeMutex := sync.Mutex{}
+	send := func(request shellRequest) error {
+		writeMutex.Lock()
+		defer writeMutex.Unlock()
+		return conn.WriteJSON(rpc.WSRequestMessage[shellRequest]{
+			Call:     "jobs.compute." + session.ProviderId + ".shell.open",
+			StreamId: "0",
+			Payload:  request,
+		})
+	}
+
+	stdin := int(os.Stdin.Fd())
+	isTerminal := term.IsTerminal(stdin)
+	terminalSize := func() (int, int) {
+		cols, rows, err := term.GetSize(stdin)
+		if err != nil || cols <= 0 || rows <= 0 {
+			return 80, 24
+		}
+		return cols, rows
+	}
+
+	cols, rows := terminalSize()
+	err = send(shellRequest{
+		Type:              "initialize",
+		SessionIdentifier: session.Session.SessionIdentifier,
+		Cols:              cols,
+		Rows:              rows,
+	})
+	if err != nil {
+		return err
+	}
+
+	if isTerminal {
+		oldState, err := term.MakeRaw(stdin)
+		if err != nil {
+			return fmt.Errorf("could not configure the terminal: %w", err)
+		}
+		defer func() {
+			_ = term.Restore(stdin, oldState)
+		}()
+
+		winch := make(chan os.Signal, 1)
+		signal.Notify(winch, syscall.SIGWINCH)
+		defer signal.Stop(winch)
+
+		go func() {
+			for range winch {
+				cols, rows := terminalSize()
+				if send(shellRequest{Type: "resize", Cols: cols, Rows: rows}) != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if n > 0 {
+				if send(shellRequest{Type: "input", Data: string(buf[:n])}) != nil {
+					return
+				}
+			}
+			if err != nil {
+				_ = conn.Close()
+				return
+			}
+		}
+	}()
+
+	for {
+		var message rpc.WSResponseMessage[json.RawMessage]
+		if err := conn.ReadJSON(&message); err != nil {
+			// The provider closes the connection when the shell exits.
+			return nil
+		}
+
+		var payload shellResponse
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			continue
+		}
+
+		if payload.Type == "data" {
+			_, _ = os.Stdout.WriteString(payload.Data)
+		}
+	}
+}
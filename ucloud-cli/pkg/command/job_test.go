@@ -0,0 +1,32 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	orcapi "ucloud.dk/shared/pkg/orchestrators"
+)
+
+func TestParseParameterValue(t *testing.T) {
+	value, err := parseParameterValue(orcapi.ApplicationParameter{Name: "n", Type: orcapi.ApplicationParameterTypeInteger}, "42")
+	assert.NoError(t, err)
+	assert.Equal(t, orcapi.AppParameterValueInteger(42), value)
+
+	_, err = parseParameterValue(orcapi.ApplicationParameter{Name: "n", Type: orcapi.ApplicationParameterTypeInteger}, "forty")
+	assert.Error(t, err)
+
+	value, err = parseParameterValue(orcapi.ApplicationParameter{Name: "d", Type: orcapi.ApplicationParameterTypeInputDirectory}, "/123/data")
+	assert.NoError(t, err)
+	assert.Equal(t, orcapi.AppParameterValueFile("/123/data", false), value)
+
+	value, err = parseParameterValue(orcapi.ApplicationParameter{Name: "p", Type: orcapi.ApplicationParameterTypePeer}, "db:51")
+	assert.NoError(t, err)
+	assert.Equal(t, orcapi.AppParameterValuePeer("db", "51"), value)
+
+	_, err = parseParameterValue(orcapi.ApplicationParameter{Name: "w", Type: orcapi.ApplicationParameterTypeWorkflow}, "x")
+	assert.Error(t, err)
+}
+
+func TestMinutesToDuration(t *testing.T) {
+	assert.Equal(t, orcapi.SimpleDuration{Hours: 2, Minutes: 5}, minutesToDuration(125))
+}
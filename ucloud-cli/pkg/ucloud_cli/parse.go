@@ -75,7 +75,7 @@ func bindCommand(args []string, cmd any) error {
 		// Handling positional arguments
 		positional := field.Tag.Get("positional")
 		if positional != "" {
-			if pos >= len(args) {
+			if pos >= len(args) || strings.HasPrefix(args[pos], "-") {
 				if field.Tag.Get("required") == "true" {
 					return fmt.Errorf("missing required argument: %s", field.Name)
 				}
@@ -157,8 +157,8 @@ func bindCommand(args []string, cmd any) error {
 		bindings = append(bindings, binding)
 	}
 
-	// Parse args
-	err := fs.Parse(args)
+	// Parse args. Flags follow the positional arguments.
+	err := fs.Parse(args[pos:])
 	if err != nil {
 		return err
 	}
@@ -200,13 +200,17 @@ func Parse(commands []string) (com.Command, error) {
 	}
 
 	createFunc, ok := parserRoute[subCommand]
-	if !ok {
-		return nil, fmt.Errorf("subcommand %s not found", subCommand)
+	if ok {
+		commands, _ = Consume(commands)
+	} else {
+		// Commands such as connect do not have any subcommands. These are registered under their own name.
+		createFunc, ok = parserRoute[mainCommand]
+		if !ok {
+			return nil, fmt.Errorf("subcommand %s not found", subCommand)
+		}
 	}
 	cmd := createFunc()
 
-	commands, _ = Consume(commands)
-
 	err := bindCommand(commands, cmd)
 
 	if err != nil {
@@ -78,3 +78,30 @@ func TestEnviromentAdd(t *testing.T) {
 	assert.Equal(t, concrete.Name, "foo")
 	assert.Equal(t, concrete.Value, "bar")
 }
+
+func TestJobExtendFlagAfterPositional(t *testing.T) {
+	input := []string{"job", "extend", "4242", "--time", "90"}
+	cmd, err := Parse(input)
+	assert.NoError(t, err)
+	concrete := cmd.(*command.JobExtendCommand)
+	assert.Equal(t, "4242", concrete.JobID)
+	assert.Equal(t, 90, concrete.Time)
+}
+
+func TestJobSearchWithoutQuery(t *testing.T) {
+	input := []string{"job", "search", "--json"}
+	cmd, err := Parse(input)
+	assert.NoError(t, err)
+	concrete := cmd.(*command.JobSearchCommand)
+	assert.Empty(t, concrete.JobName)
+	assert.True(t, concrete.JSON)
+}
+
+func TestConnectWithoutSubcommand(t *testing.T) {
+	input := []string{"connect", "--server", "https://cloud.sdu.dk", "--token", "abc"}
+	cmd, err := Parse(input)
+	assert.NoError(t, err)
+	concrete := cmd.(*command.ConnectCommand)
+	assert.Equal(t, "https://cloud.sdu.dk", concrete.Server)
+	assert.Equal(t, "abc", concrete.Token)
+}